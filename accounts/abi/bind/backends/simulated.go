@@ -854,6 +854,10 @@ func (m callMsg) Value() *uint256.Int          { return m.CallMsg.Value }
 func (m callMsg) Data() []byte                 { return m.CallMsg.Data }
 func (m callMsg) AccessList() types.AccessList { return m.CallMsg.AccessList }
 
+func (m callMsg) MaxFeePerBlobGas() *uint256.Int { return new(uint256.Int) }
+func (m callMsg) BlobHashes() []common.Hash      { return nil }
+func (m callMsg) BlobGas() uint64                { return 0 }
+
 // filterBackend implements filters.Backend to support filtering for logs without
 // taking bloom-bits acceleration structures into account.
 type filterBackend struct {
@@ -0,0 +1,104 @@
+// Package accounts implements the signing accounts of the node: keys on whose behalf
+// the RPC daemon signs transactions and messages (eth_sendTransaction, eth_sign, ...).
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// ErrUnknownAccount is returned for signing requests of an address that no backend holds.
+var ErrUnknownAccount = errors.New("unknown account")
+
+// Backend is a source of signing accounts, for example an encrypted keystore or an external signer.
+type Backend interface {
+	// Accounts returns the addresses of the keys held by the backend
+	Accounts() []common.Address
+	// SignText signs the EIP-191 hash of text (see TextHash). V of the returned signature is 0 or 1.
+	SignText(addr common.Address, text []byte) ([]byte, error)
+	// SignTx signs the transaction with the key of addr for the given chain
+	SignTx(addr common.Address, tx types.Transaction, chainID *big.Int) (types.Transaction, error)
+	Close() error
+}
+
+// Manager dispatches signing requests to the backend that holds the account.
+// If several backends hold the same account, the first one wins.
+type Manager struct {
+	backends []Backend
+}
+
+func NewManager(backends ...Backend) *Manager {
+	return &Manager{backends: backends}
+}
+
+// Accounts returns the addresses of all backends, without duplicates
+func (am *Manager) Accounts() []common.Address {
+	seen := map[common.Address]struct{}{}
+	addresses := []common.Address{}
+	for _, backend := range am.backends {
+		for _, addr := range backend.Accounts() {
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+			seen[addr] = struct{}{}
+			addresses = append(addresses, addr)
+		}
+	}
+	return addresses
+}
+
+// Contains returns true if one of the backends holds the account
+func (am *Manager) Contains(addr common.Address) bool {
+	_, err := am.find(addr)
+	return err == nil
+}
+
+func (am *Manager) find(addr common.Address) (Backend, error) {
+	for _, backend := range am.backends {
+		for _, a := range backend.Accounts() {
+			if a == addr {
+				return backend, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %x", ErrUnknownAccount, addr)
+}
+
+func (am *Manager) SignText(addr common.Address, text []byte) ([]byte, error) {
+	backend, err := am.find(addr)
+	if err != nil {
+		return nil, err
+	}
+	return backend.SignText(addr, text)
+}
+
+func (am *Manager) SignTx(addr common.Address, tx types.Transaction, chainID *big.Int) (types.Transaction, error) {
+	backend, err := am.find(addr)
+	if err != nil {
+		return nil, err
+	}
+	return backend.SignTx(addr, tx, chainID)
+}
+
+func (am *Manager) Close() error {
+	var firstErr error
+	for _, backend := range am.backends {
+		if err := backend.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// TextHash calculates the hash of a message which is safe to sign, as defined by EIP-191:
+// keccak256("\x19Ethereum Signed Message:\n"${message length}${message}).
+// The prefix makes sure that the signature can't be used to sign a transaction.
+func TextHash(data []byte) []byte {
+	msg := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(data), data)
+	return crypto.Keccak256([]byte(msg))
+}
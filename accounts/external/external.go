@@ -0,0 +1,162 @@
+// Package external implements a signing backend which forwards the requests to an external
+// signer, such as clef, over its JSON-RPC API (account_list, account_signTransaction, account_signData).
+package external
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/accounts"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// requestTimeout is generous because external signers may ask a human to confirm the request
+const requestTimeout = 5 * time.Minute
+
+type Signer struct {
+	endpoint string
+	client   *rpc.Client
+
+	lock     sync.RWMutex
+	accounts []common.Address
+}
+
+var _ accounts.Backend = (*Signer)(nil)
+
+// Dial connects to the signer at endpoint, which is either an URL (http, ws) or the path of
+// a unix socket, and fetches its list of accounts.
+func Dial(endpoint string) (*Signer, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSigner(endpoint, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func NewSigner(endpoint string, client *rpc.Client) (*Signer, error) {
+	s := &Signer{endpoint: endpoint, client: client}
+	if err := s.refreshAccounts(); err != nil {
+		return nil, fmt.Errorf("list accounts of external signer %s: %w", endpoint, err)
+	}
+	return s, nil
+}
+
+func (s *Signer) refreshAccounts() error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	var res []common.Address
+	if err := s.client.CallContext(ctx, &res, "account_list"); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.accounts = res
+	return nil
+}
+
+func (s *Signer) Accounts() []common.Address {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]common.Address{}, s.accounts...)
+}
+
+func (s *Signer) SignText(addr common.Address, text []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	var res hexutil.Bytes
+	if err := s.client.CallContext(ctx, &res, "account_signData", "text/plain", addr, hexutil.Bytes(text)); err != nil {
+		return nil, err
+	}
+	if len(res) != 65 {
+		return nil, fmt.Errorf("external signer returned signature of wrong length %d", len(res))
+	}
+	// The signer returns V as 27/28, backends return it as 0/1
+	if res[64] == 27 || res[64] == 28 {
+		res[64] -= 27
+	}
+	return res, nil
+}
+
+// signTransactionArgs is the transaction in the form expected by account_signTransaction
+type signTransactionArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	Value                hexutil.Big       `json:"value"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	Data                 hexutil.Bytes     `json:"input"`
+	AccessList           *types.AccessList `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big      `json:"chainId,omitempty"`
+}
+
+type signTransactionResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+func (s *Signer) SignTx(addr common.Address, tx types.Transaction, chainID *big.Int) (types.Transaction, error) {
+	args := signTransactionArgs{
+		From:    addr,
+		To:      tx.GetTo(),
+		Gas:     hexutil.Uint64(tx.GetGas()),
+		Value:   hexutil.Big(*tx.GetValue().ToBig()),
+		Nonce:   hexutil.Uint64(tx.GetNonce()),
+		Data:    tx.GetData(),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GetPrice().ToBig())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GetFeeCap().ToBig())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GetTip().ToBig())
+	default:
+		return nil, fmt.Errorf("external signer does not support transactions of type %d", tx.Type())
+	}
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.GetAccessList()
+		args.AccessList = &accessList
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	var res signTransactionResult
+	if err := s.client.CallContext(ctx, &res, "account_signTransaction", &args); err != nil {
+		return nil, err
+	}
+	signed, err := types.UnmarshalTransactionFromBinary(res.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode transaction from external signer: %w", err)
+	}
+	// Don't trust the signer blindly: the signed transaction must be the one we asked for
+	signer := types.LatestSignerForChainID(chainID)
+	if signed.SigningHash(chainID) != tx.SigningHash(chainID) {
+		return nil, fmt.Errorf("external signer returned a different transaction")
+	}
+	from, err := signed.Sender(*signer)
+	if err != nil {
+		return nil, fmt.Errorf("recover sender of transaction from external signer: %w", err)
+	}
+	if from != addr {
+		return nil, fmt.Errorf("external signer returned a transaction signed by %x, expected %x", from, addr)
+	}
+	return signed, nil
+}
+
+func (s *Signer) Close() error {
+	s.client.Close()
+	return nil
+}
@@ -0,0 +1,99 @@
+package external
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/accounts"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// fakeSigner implements the account_ namespace of clef with a single key
+type fakeSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *fakeSigner) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *fakeSigner) SignData(contentType string, addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	sig, err := crypto.Sign(accounts.TextHash(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (s *fakeSigner) SignTransaction(args signTransactionArgs) (*signTransactionResult, error) {
+	value, _ := uint256.FromBig(args.Value.ToInt())
+	gasPrice, _ := uint256.FromBig(args.GasPrice.ToInt())
+	tx := types.NewTransaction(uint64(args.Nonce), *args.To, value, uint64(args.Gas), gasPrice, args.Data)
+	signed, err := types.SignTx(tx, *types.LatestSignerForChainID(args.ChainID.ToInt()), s.key)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = signed.MarshalBinary(&buf); err != nil {
+		return nil, err
+	}
+	return &signTransactionResult{Raw: buf.Bytes()}, nil
+}
+
+func TestExternalSigner(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	server := rpc.NewServer(50, false /* traceRequests */, true)
+	if err := server.RegisterName("account", &fakeSigner{key: key}); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	s, err := NewSigner("inproc", rpc.DialInProc(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	am := accounts.NewManager(s)
+	if accs := am.Accounts(); len(accs) != 1 || accs[0] != addr {
+		t.Fatalf("wrong accounts %x", accs)
+	}
+
+	sig, err := am.SignText(addr, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig[64] > 1 {
+		t.Errorf("V of signature is not 0/1: %d", sig[64])
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != addr {
+		t.Errorf("text signed by wrong key %x", crypto.PubkeyToAddress(*pub))
+	}
+
+	chainID := big.NewInt(1337)
+	tx := types.NewTransaction(5, common.Address{1}, uint256.NewInt(10), 21000, uint256.NewInt(7), []byte{1, 2})
+	signed, err := am.SignTx(addr, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Hash() == tx.Hash() || signed.SigningHash(chainID) != tx.SigningHash(chainID) {
+		t.Errorf("signer returned an unexpected transaction")
+	}
+
+	// The transaction must be signed by the requested account
+	if _, err = s.SignTx(common.Address{2}, tx, chainID); err == nil {
+		t.Errorf("expected error for transaction signed by another account")
+	}
+}
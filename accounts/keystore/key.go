@@ -0,0 +1,203 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	version = 3
+
+	keyHeaderKDF = "scrypt"
+	scryptDKLen  = 32
+
+	// StandardScryptN is the N parameter of scrypt encryption algorithm, using 256MB
+	// memory and taking approximately 1s CPU time on a modern processor.
+	StandardScryptN = 1 << 18
+	// StandardScryptP is the P parameter of scrypt encryption algorithm, using 256MB
+	// memory and taking approximately 1s CPU time on a modern processor.
+	StandardScryptP = 1
+	// LightScryptN is the N parameter of scrypt encryption algorithm, using 4MB
+	// memory and taking approximately 100ms CPU time on a modern processor.
+	LightScryptN = 1 << 12
+	// LightScryptP is the P parameter of scrypt encryption algorithm, using 4MB
+	// memory and taking approximately 100ms CPU time on a modern processor.
+	LightScryptP = 6
+)
+
+// ErrDecrypt is returned when the password doesn't match the key file
+var ErrDecrypt = errors.New("could not decrypt key with given password")
+
+// encryptedKeyJSON is the Web3 Secret Storage (version 3) format of a key file
+type encryptedKeyJSON struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	Id      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherparamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type cipherparamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// EncryptKey encrypts a private key with the password into the Web3 Secret Storage format,
+// using scrypt with the given parameters as key derivation function.
+func EncryptKey(key *ecdsa.PrivateKey, auth string, scryptN, scryptP int) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("reading from crypto/rand failed: %w", err)
+	}
+	derivedKey, err := scrypt.Key([]byte(auth), salt, scryptN, 8, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	encryptKey := derivedKey[:16]
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, fmt.Errorf("reading from crypto/rand failed: %w", err)
+	}
+	keyBytes := common.LeftPadBytes(crypto.FromECDSA(key), 32)
+	cipherText, err := aesCTRXOR(encryptKey, keyBytes, iv)
+	if err != nil {
+		return nil, err
+	}
+	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
+
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return nil, fmt.Errorf("reading from crypto/rand failed: %w", err)
+	}
+	id[6] = id[6]&0x0f | 0x40 // UUID version 4
+	id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+
+	return json.Marshal(encryptedKeyJSON{
+		Address: hex.EncodeToString(crypto.PubkeyToAddress(key.PublicKey).Bytes()),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherparamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          keyHeaderKDF,
+			KDFParams: map[string]interface{}{
+				"n":     scryptN,
+				"r":     8,
+				"p":     scryptP,
+				"dklen": scryptDKLen,
+				"salt":  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(mac),
+		},
+		Id:      fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: version,
+	})
+}
+
+// DecryptKey decrypts a key file in the Web3 Secret Storage format with the password.
+// Both scrypt and pbkdf2 key derivation functions are supported.
+func DecryptKey(keyjson []byte, auth string) (*ecdsa.PrivateKey, error) {
+	var k encryptedKeyJSON
+	if err := json.Unmarshal(keyjson, &k); err != nil {
+		return nil, err
+	}
+	if k.Version != version {
+		return nil, fmt.Errorf("unsupported key file version: %d", k.Version)
+	}
+	if k.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported cipher: %s", k.Crypto.Cipher)
+	}
+	mac, err := hex.DecodeString(k.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	derivedKey, err := deriveKey(k.Crypto, auth)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(crypto.Keccak256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrDecrypt
+	}
+	plainText, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.ToECDSA(plainText)
+	if err != nil {
+		return nil, err
+	}
+	if k.Address != "" {
+		if addr := crypto.PubkeyToAddress(key.PublicKey); common.HexToAddress(k.Address) != addr {
+			return nil, fmt.Errorf("key content mismatch: have account %x, want contents for account %s", addr, k.Address)
+		}
+	}
+	return key, nil
+}
+
+func deriveKey(cryptoJSON cryptoJSON, auth string) ([]byte, error) {
+	params := cryptoJSON.KDFParams
+	salt, err := hex.DecodeString(fmt.Sprint(params["salt"]))
+	if err != nil {
+		return nil, err
+	}
+	dkLen := intParam(params, "dklen")
+	if dkLen < 32 {
+		return nil, fmt.Errorf("derived key is too short: %d", dkLen)
+	}
+	switch cryptoJSON.KDF {
+	case keyHeaderKDF:
+		return scrypt.Key([]byte(auth), salt, intParam(params, "n"), intParam(params, "r"), intParam(params, "p"), dkLen)
+	case "pbkdf2":
+		if prf := fmt.Sprint(params["prf"]); prf != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported PBKDF2 PRF: %s", prf)
+		}
+		return pbkdf2.Key([]byte(auth), salt, intParam(params, "c"), dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported KDF: %s", cryptoJSON.KDF)
+	}
+}
+
+// intParam reads a numeric KDF parameter, which encoding/json decodes as float64
+func intParam(params map[string]interface{}, name string) int {
+	f, _ := params[name].(float64)
+	return int(f)
+}
+
+func aesCTRXOR(key, inText, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	outText := make([]byte, len(inText))
+	cipher.NewCTR(block, iv).XORKeyStream(outText, inText)
+	return outText, nil
+}
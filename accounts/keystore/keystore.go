@@ -0,0 +1,142 @@
+// Package keystore implements a signing backend on top of a directory of encrypted
+// JSON key files in the Web3 Secret Storage format, as written by geth and clef.
+package keystore
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon/accounts"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// KeyStore holds the decrypted keys of a keystore directory in memory
+type KeyStore struct {
+	lock      sync.RWMutex
+	keys      map[common.Address]*ecdsa.PrivateKey
+	addresses []common.Address // sorted
+}
+
+var _ accounts.Backend = (*KeyStore)(nil)
+
+// Open reads all key files of the directory and decrypts them. Every key must be decryptable
+// with one of the passwords, so that a misconfigured keystore is noticed on startup.
+func Open(dir string, passwords []string) (*KeyStore, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ks := &KeyStore{keys: map[common.Address]*ecdsa.PrivateKey{}}
+	for _, entry := range entries {
+		// Skip sub-directories, unfinished writes, editor backups, dot files and the like
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || strings.HasSuffix(name, ".tmp") || strings.EqualFold(name, "README") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		keyjson, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := decryptWithAny(keyjson, passwords)
+		if err != nil {
+			return nil, fmt.Errorf("key file %s: %w", path, err)
+		}
+		ks.add(key)
+	}
+	return ks, nil
+}
+
+func decryptWithAny(keyjson []byte, passwords []string) (key *ecdsa.PrivateKey, err error) {
+	err = ErrDecrypt
+	for _, password := range passwords {
+		if key, err = DecryptKey(keyjson, password); err != ErrDecrypt {
+			return key, err
+		}
+	}
+	return nil, err
+}
+
+func (ks *KeyStore) add(key *ecdsa.PrivateKey) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	if _, ok := ks.keys[addr]; !ok {
+		ks.addresses = append(ks.addresses, addr)
+		sort.Slice(ks.addresses, func(i, j int) bool { return bytes.Compare(ks.addresses[i][:], ks.addresses[j][:]) < 0 })
+	}
+	ks.keys[addr] = key
+}
+
+// StoreKey encrypts the key and writes it into dir under the usual name of geth key files.
+// It returns the path of the new file.
+func StoreKey(dir string, key *ecdsa.PrivateKey, auth string, scryptN, scryptP int) (string, error) {
+	keyjson, err := EncryptKey(key, auth, scryptN, scryptP)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	ts := time.Now().UTC()
+	name := fmt.Sprintf("UTC--%s--%x", strings.Replace(ts.Format("2006-01-02T15-04-05.000000000Z"), ".", "-", 1), addr[:])
+	path := filepath.Join(dir, name)
+	// Write into a temporary file first, so that a partially written key file is never picked up
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, keyjson, 0600); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+func (ks *KeyStore) Accounts() []common.Address {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	return append([]common.Address{}, ks.addresses...)
+}
+
+func (ks *KeyStore) key(addr common.Address) (*ecdsa.PrivateKey, error) {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	key, ok := ks.keys[addr]
+	if !ok {
+		return nil, fmt.Errorf("%w: %x", accounts.ErrUnknownAccount, addr)
+	}
+	return key, nil
+}
+
+func (ks *KeyStore) SignText(addr common.Address, text []byte) ([]byte, error) {
+	key, err := ks.key(addr)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(accounts.TextHash(text), key)
+}
+
+func (ks *KeyStore) SignTx(addr common.Address, tx types.Transaction, chainID *big.Int) (types.Transaction, error) {
+	key, err := ks.key(addr)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, *types.LatestSignerForChainID(chainID), key)
+}
+
+// Close drops the decrypted keys
+func (ks *KeyStore) Close() error {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+	ks.keys = map[common.Address]*ecdsa.PrivateKey{}
+	ks.addresses = nil
+	return nil
+}
@@ -0,0 +1,116 @@
+package keystore
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/accounts"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// Test vector of the Web3 Secret Storage definition
+const scryptVector = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"p":8,"r":1,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+
+func TestDecryptVector(t *testing.T) {
+	key, err := DecryptKey([]byte(scryptVector), "testpassword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := common.Bytes2Hex(crypto.FromECDSA(key)); have != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Errorf("wrong key %s", have)
+	}
+	if _, err = DecryptKey([]byte(scryptVector), "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt with wrong password, got %v", err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyjson, err := EncryptKey(key, "foo", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := DecryptKey(keyjson, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decrypted.Equal(key) {
+		t.Errorf("decrypted key differs from the original")
+	}
+	if _, err = DecryptKey(keyjson, "bar"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt with wrong password, got %v", err)
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	dir := t.TempDir()
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	if _, err := StoreKey(dir, key1, "one", LightScryptN, LightScryptP); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StoreKey(dir, key2, "two", LightScryptN, LightScryptP); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".hidden"), []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(dir, []string{"one"}); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt if a password is missing, got %v", err)
+	}
+	ks, err := Open(dir, []string{"two", "one"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ks.Close()
+	addr1, addr2 := crypto.PubkeyToAddress(key1.PublicKey), crypto.PubkeyToAddress(key2.PublicKey)
+	if len(ks.Accounts()) != 2 {
+		t.Fatalf("expected 2 accounts, got %x", ks.Accounts())
+	}
+
+	am := accounts.NewManager(ks)
+	if !am.Contains(addr1) || !am.Contains(addr2) {
+		t.Fatalf("accounts %x missing from %x", []common.Address{addr1, addr2}, am.Accounts())
+	}
+
+	// eth_sign
+	sig, err := am.SignText(addr1, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != addr1 {
+		t.Errorf("text signed by wrong key %x", crypto.PubkeyToAddress(*pub))
+	}
+
+	// eth_sendTransaction
+	chainID := big.NewInt(1337)
+	tx := types.NewTransaction(0, common.Address{1}, uint256.NewInt(1), 21000, uint256.NewInt(1), nil)
+	signed, err := am.SignTx(addr2, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, err := signed.Sender(*types.LatestSignerForChainID(chainID)); err != nil || from != addr2 {
+		t.Errorf("transaction signed by %x, expected %x (%v)", from, addr2, err)
+	}
+	if !signed.Protected() {
+		t.Errorf("signed transaction is not replay protected")
+	}
+
+	if _, err = am.SignText(common.Address{1}, []byte("hello")); !errors.Is(err, accounts.ErrUnknownAccount) {
+		t.Errorf("expected ErrUnknownAccount, got %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/anacrolix/torrent/metainfo"
 	"github.com/anacrolix/torrent/storage"
 	common2 "github.com/ledgerwatch/erigon-lib/common"
+	prototypes "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
 	"github.com/ledgerwatch/erigon/cmd/downloader/downloader/downloadercfg"
@@ -36,6 +37,8 @@ type Downloader struct {
 	stats     AggStats
 
 	folder storage.ClientImplCloser
+
+	webSeeds *WebSeeds // nil if no webseeds configured
 }
 
 type AggStats struct {
@@ -94,6 +97,11 @@ func New(cfg *downloadercfg.Cfg) (*Downloader, error) {
 
 		statsLock: &sync.RWMutex{},
 	}
+	if len(cfg.WebSeeds) > 0 {
+		if d.webSeeds, err = NewWebSeeds(cfg.DataDir, cfg.WebSeeds, cfg.DownloadSlots); err != nil {
+			return nil, err
+		}
+	}
 	if err := d.addSegments(); err != nil {
 		return nil, err
 	}
@@ -111,6 +119,11 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 	torrents := d.torrentClient.Torrents()
 	connStats := d.torrentClient.ConnStats()
 	peers := make(map[torrent.PeerID]struct{}, 16)
+	var webFiles int32
+	var webBytesCompleted, webBytesTotal, webBytesDownload uint64
+	if d.webSeeds != nil {
+		webFiles, webBytesCompleted, webBytesTotal, webBytesDownload = d.webSeeds.Stats()
+	}
 
 	d.statsLock.Lock()
 	defer d.statsLock.Unlock()
@@ -136,6 +149,12 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 
 		stats.Completed = stats.Completed && t.Complete.Bool()
 	}
+	// Files downloading from webseeds are not added to the torrent client yet
+	stats.Completed = stats.Completed && webFiles == 0
+	stats.MetadataReady += webFiles
+	stats.BytesCompleted += webBytesCompleted
+	stats.BytesTotal += webBytesTotal
+	stats.BytesDownload += webBytesDownload
 
 	stats.DownloadRate = (stats.BytesDownload - prevStats.BytesDownload) / uint64(interval.Seconds())
 	stats.UploadRate = (stats.BytesUpload - prevStats.BytesUpload) / uint64(interval.Seconds())
@@ -149,7 +168,7 @@ func (d *Downloader) ReCalcStats(interval time.Duration) {
 		}
 	}
 	stats.PeersUnique = int32(len(peers))
-	stats.FilesTotal = int32(len(torrents))
+	stats.FilesTotal = int32(len(torrents)) + webFiles
 
 	d.stats = stats
 }
@@ -261,7 +280,26 @@ func (d *Downloader) Stats() AggStats {
 	return d.stats
 }
 
+// downloadFromWebSeeds - adds the downloaded file to the torrent client to seed it,
+// falls back to BitTorrent if the file is not available on webseeds
+func (d *Downloader) downloadFromWebSeeds(name string, hash *prototypes.H160) {
+	d.webSeeds.Download(name, Proto2InfoHash(hash), func(err error) {
+		if err == nil {
+			if _, err = AddSegment(name, d.SnapDir(), d.Torrent()); err == nil {
+				return
+			}
+		}
+		log.Warn("[snapshots] webseeds download failed, using BitTorrent", "file", name, "err", err)
+		if _, err := createMagnetLinkWithInfoHash(hash, d.Torrent(), d.SnapDir()); err != nil {
+			log.Warn("[snapshots] create magnet link", "file", name, "err", err)
+		}
+	})
+}
+
 func (d *Downloader) Close() {
+	if d.webSeeds != nil {
+		d.webSeeds.Close()
+	}
 	d.torrentClient.Close()
 	if err := d.folder.Close(); err != nil {
 		log.Warn("[Snapshots] folder.close", "err", err)
@@ -56,6 +56,13 @@ func (s *GrpcServer) Download(ctx context.Context, request *proto_downloader.Dow
 			continue
 		}
 
+		if s.d.webSeeds != nil {
+			if _, ok := torrentClient.Torrent(Proto2InfoHash(it.TorrentHash)); !ok {
+				s.d.downloadFromWebSeeds(it.Path, it.TorrentHash)
+				continue
+			}
+		}
+
 		_, err := createMagnetLinkWithInfoHash(it.TorrentHash, torrentClient, snapDir)
 		if err != nil {
 			return nil, err
@@ -25,6 +25,7 @@ const DefaultNetworkChunkSize = 1 * 1024 * 1024
 type Cfg struct {
 	*torrent.ClientConfig
 	DownloadSlots int
+	WebSeeds      []string // HTTP(S) base URLs or local directories to download snapshot files from, instead of BitTorrent
 }
 
 func Default() *torrent.ClientConfig {
@@ -53,7 +54,7 @@ func Default() *torrent.ClientConfig {
 	return torrentConfig
 }
 
-func New(snapDir string, verbosity lg.Level, dbg bool, natif nat.Interface, downloadRate, uploadRate datasize.ByteSize, port, connsPerFile, downloadSlots int) (*Cfg, error) {
+func New(snapDir string, verbosity lg.Level, dbg bool, natif nat.Interface, downloadRate, uploadRate datasize.ByteSize, port, connsPerFile, downloadSlots int, webSeeds []string) (*Cfg, error) {
 	torrentConfig := Default()
 	// We would-like to reduce amount of goroutines in Erigon, so reducing next params
 	torrentConfig.EstablishedConnsPerTorrent = connsPerFile // default: 50
@@ -117,5 +118,5 @@ func New(snapDir string, verbosity lg.Level, dbg bool, natif nat.Interface, down
 	torrentConfig.Logger = lg.Default.FilterLevel(verbosity)
 	torrentConfig.Logger.Handlers = []lg.Handler{adapterHandler{}}
 
-	return &Cfg{ClientConfig: torrentConfig, DownloadSlots: downloadSlots}, nil
+	return &Cfg{ClientConfig: torrentConfig, DownloadSlots: downloadSlots, WebSeeds: webSeeds}, nil
 }
@@ -261,6 +261,7 @@ func mmapFile(name string) (mm mmap.MMap, err error) {
 
 func verifyTorrent(info *metainfo.Info, root string, consumer func(i int, good bool) error) error {
 	span := new(mmap_span.MMapSpan)
+	defer span.Close()
 	for _, file := range info.UpvertedFiles() {
 		filename := filepath.Join(append([]string{root, info.Name}, file.Path...)...)
 		mm, err := mmapFile(filename)
@@ -0,0 +1,295 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/ledgerwatch/log/v3"
+	"go.uber.org/atomic"
+	"golang.org/x/sync/semaphore"
+)
+
+// webSeed - source of snapshot files other than BitTorrent: HTTP(S) server or local directory
+type webSeed interface {
+	// open returns content of the file and its length, -1 if the length is unknown
+	open(ctx context.Context, name string) (io.ReadCloser, int64, error)
+	String() string
+}
+
+type httpSeed struct {
+	base   *url.URL
+	client *http.Client
+}
+
+func (s *httpSeed) open(ctx context.Context, name string) (io.ReadCloser, int64, error) {
+	u := *s.base
+	u.Path = path.Join(u.Path, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("GET %s: %s", u.String(), resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+func (s *httpSeed) String() string { return s.base.String() }
+
+type dirSeed struct {
+	dir string
+}
+
+func (s *dirSeed) open(_ context.Context, name string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, fi.Size(), nil
+}
+
+func (s *dirSeed) String() string { return s.dir }
+
+type webSeedProgress struct {
+	bytesCompleted, bytesTotal atomic.Int64
+}
+
+// WebSeeds - downloads snapshot files from HTTP(S) servers or copies them from local directory mirrors, for networks
+// where BitTorrent is not available. Files are verified against their .torrent metainfo and written to
+// <datadir>/snapshots/webseed first - App never sees partially-downloaded or corrupted files
+type WebSeeds struct {
+	seeds   []webSeed
+	snapDir string
+	tmpDir  string
+	sem     *semaphore.Weighted
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	lock       sync.RWMutex
+	inProgress map[string]*webSeedProgress // file name -> progress of its download
+
+	bytesDownload atomic.Uint64
+}
+
+// NewWebSeeds - each of the urls is HTTP(S) base URL or path of local directory with snapshot files and their .torrent files
+func NewWebSeeds(snapDir string, urls []string, downloadSlots int) (*WebSeeds, error) {
+	w := &WebSeeds{
+		snapDir:    snapDir,
+		tmpDir:     filepath.Join(snapDir, "webseed"),
+		sem:        semaphore.NewWeighted(int64(downloadSlots)),
+		inProgress: map[string]*webSeedProgress{},
+	}
+	for _, u := range urls {
+		if strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+			base, err := url.Parse(u)
+			if err != nil {
+				return nil, fmt.Errorf("webseed %s: %w", u, err)
+			}
+			w.seeds = append(w.seeds, &httpSeed{base: base, client: &http.Client{}})
+			continue
+		}
+		dir := strings.TrimPrefix(u, "file://")
+		if fi, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("webseed %s: %w", u, err)
+		} else if !fi.IsDir() {
+			return nil, fmt.Errorf("webseed %s: not a directory", u)
+		}
+		w.seeds = append(w.seeds, &dirSeed{dir: dir})
+	}
+	if err := os.RemoveAll(w.tmpDir); err != nil { // leftovers of interrupted downloads
+		return nil, err
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	return w, nil
+}
+
+// Download - starts the download of the file in background, unless it is being downloaded already.
+// onComplete is not called if WebSeeds was closed before the download finished
+func (w *WebSeeds) Download(name string, infoHash metainfo.Hash, onComplete func(err error)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, ok := w.inProgress[name]; ok {
+		return
+	}
+	progress := &webSeedProgress{}
+	w.inProgress[name] = progress
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		err := w.download(name, infoHash, progress)
+		w.lock.Lock()
+		delete(w.inProgress, name)
+		w.lock.Unlock()
+		if w.ctx.Err() != nil {
+			return
+		}
+		onComplete(err)
+	}()
+}
+
+func (w *WebSeeds) download(name string, infoHash metainfo.Hash, progress *webSeedProgress) error {
+	if err := w.sem.Acquire(w.ctx, 1); err != nil {
+		return err
+	}
+	defer w.sem.Release(1)
+
+	mi, err := w.metaInfo(name, infoHash)
+	if err != nil {
+		return err
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s: multi-file torrents are not supported", name)
+	}
+	progress.bytesTotal.Store(info.Length)
+	if err := os.MkdirAll(w.tmpDir, 0755); err != nil {
+		return err
+	}
+
+	err = errors.New("no webseeds")
+	for _, seed := range w.seeds {
+		if err = w.downloadFrom(seed, &info, progress); err != nil {
+			if w.ctx.Err() != nil {
+				return w.ctx.Err()
+			}
+			log.Warn("[snapshots] webseed download", "file", name, "webseed", seed, "err", err)
+			continue
+		}
+		if err = os.Rename(filepath.Join(w.tmpDir, info.Name), filepath.Join(w.snapDir, info.Name)); err != nil {
+			return err
+		}
+		return CreateTorrentFileIfNotExists(w.snapDir, &info, mi)
+	}
+	return err
+}
+
+// metaInfo - the .torrent file from the snapshots dir or from the webseeds, its info hash must be the requested one
+func (w *WebSeeds) metaInfo(name string, infoHash metainfo.Hash) (*metainfo.MetaInfo, error) {
+	if mi, err := metainfo.LoadFromFile(filepath.Join(w.snapDir, name+".torrent")); err == nil && mi.HashInfoBytes() == infoHash {
+		return mi, nil
+	}
+	for _, seed := range w.seeds {
+		mi, err := func() (*metainfo.MetaInfo, error) {
+			r, _, err := seed.open(w.ctx, name+".torrent")
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return metainfo.Load(r)
+		}()
+		if err != nil {
+			log.Debug("[snapshots] webseed metainfo", "file", name, "webseed", seed, "err", err)
+			continue
+		}
+		if mi.HashInfoBytes() != infoHash {
+			log.Warn("[snapshots] webseed metainfo has wrong info hash", "file", name, "webseed", seed, "expected", infoHash, "got", mi.HashInfoBytes())
+			continue
+		}
+		return mi, nil
+	}
+	return nil, fmt.Errorf("%s.torrent not found on webseeds", name)
+}
+
+func (w *WebSeeds) downloadFrom(seed webSeed, info *metainfo.Info, progress *webSeedProgress) error {
+	progress.bytesCompleted.Store(0)
+	r, length, err := seed.open(w.ctx, info.Name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if length >= 0 && length != info.Length {
+		return fmt.Errorf("wrong length %d, expected %d", length, info.Length)
+	}
+
+	filePath := filepath.Join(w.tmpDir, info.Name)
+	if err = w.copyFile(filePath, r, length, progress); err != nil {
+		_ = os.Remove(filePath)
+		return err
+	}
+	if err = verifyTorrent(info, w.tmpDir, func(i int, good bool) error {
+		if !good {
+			return fmt.Errorf("hash mismatch at piece %d", i)
+		}
+		return nil
+	}); err != nil {
+		_ = os.Remove(filePath)
+		return err
+	}
+	return nil
+}
+
+func (w *WebSeeds) copyFile(filePath string, r io.Reader, length int64, progress *webSeedProgress) error {
+	if length < 0 {
+		// the webseed didn't tell the length, don't read more than one byte past the expected one
+		r = io.LimitReader(r, progress.bytesTotal.Load()+1)
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := make([]byte, 1024*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, err := f.Write(buf[:n]); err != nil {
+				return err
+			}
+			w.bytesDownload.Add(uint64(n))
+			if completed := progress.bytesCompleted.Add(int64(n)); completed > progress.bytesTotal.Load() {
+				return fmt.Errorf("wrong length, more than %d bytes", progress.bytesTotal.Load())
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if progress.bytesCompleted.Load() != progress.bytesTotal.Load() {
+		return fmt.Errorf("wrong length %d, expected %d", progress.bytesCompleted.Load(), progress.bytesTotal.Load())
+	}
+	return f.Sync()
+}
+
+// Stats - amount of files being downloaded, their progress, and total amount of bytes downloaded from webseeds
+func (w *WebSeeds) Stats() (files int32, bytesCompleted, bytesTotal, bytesDownload uint64) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	for _, progress := range w.inProgress {
+		bytesCompleted += uint64(progress.bytesCompleted.Load())
+		bytesTotal += uint64(progress.bytesTotal.Load())
+	}
+	return int32(len(w.inProgress)), bytesCompleted, bytesTotal, w.bytesDownload.Load()
+}
+
+func (w *WebSeeds) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
@@ -0,0 +1,138 @@
+package downloader
+
+import (
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/stretchr/testify/require"
+)
+
+const testSegName = "v1-000000-000500-bodies.seg"
+
+// testMirror creates the snapshot file with its .torrent file in a new directory, returns the directory and the info hash
+func testMirror(t *testing.T, data []byte) (string, metainfo.Hash) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, testSegName), data, 0644))
+	info := &metainfo.Info{PieceLength: 16 * 1024}
+	require.NoError(t, info.BuildFromFilePath(filepath.Join(dir, testSegName)))
+	infoBytes, err := bencode.Marshal(info)
+	require.NoError(t, err)
+	mi := &metainfo.MetaInfo{InfoBytes: infoBytes}
+	f, err := os.Create(filepath.Join(dir, testSegName+".torrent"))
+	require.NoError(t, err)
+	require.NoError(t, mi.Write(f))
+	require.NoError(t, f.Close())
+	return dir, mi.HashInfoBytes()
+}
+
+func testDownload(t *testing.T, snapDir string, urls []string, infoHash metainfo.Hash) (*WebSeeds, error) {
+	w, err := NewWebSeeds(snapDir, urls, 1)
+	require.NoError(t, err)
+	t.Cleanup(w.Close)
+	done := make(chan error, 1)
+	w.Download(testSegName, infoHash, func(err error) { done <- err })
+	files, _, _, _ := w.Stats()
+	require.LessOrEqual(t, files, int32(1))
+	return w, <-done
+}
+
+func TestWebSeedsHTTP(t *testing.T) {
+	data := make([]byte, 100*1024+1)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	mirror, infoHash := testMirror(t, data)
+	server := httptest.NewServer(http.FileServer(http.Dir(mirror)))
+	defer server.Close()
+
+	snapDir := t.TempDir()
+	w, err := testDownload(t, snapDir, []string{server.URL}, infoHash)
+	require.NoError(t, err)
+	got, err := os.ReadFile(filepath.Join(snapDir, testSegName))
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+	mi, err := metainfo.LoadFromFile(filepath.Join(snapDir, testSegName+".torrent"))
+	require.NoError(t, err)
+	require.Equal(t, infoHash, mi.HashInfoBytes())
+
+	files, bytesCompleted, bytesTotal, bytesDownload := w.Stats()
+	require.Equal(t, int32(0), files)
+	require.Equal(t, uint64(0), bytesCompleted)
+	require.Equal(t, uint64(0), bytesTotal)
+	require.Equal(t, uint64(len(data)), bytesDownload)
+}
+
+func TestWebSeedsCorrupted(t *testing.T) {
+	data := make([]byte, 100*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	mirror, infoHash := testMirror(t, data)
+	// Same length, but one of the pieces doesn't match the .torrent file
+	data[50*1024]++
+	require.NoError(t, os.WriteFile(filepath.Join(mirror, testSegName), data, 0644))
+	server := httptest.NewServer(http.FileServer(http.Dir(mirror)))
+	defer server.Close()
+
+	snapDir := t.TempDir()
+	_, err = testDownload(t, snapDir, []string{server.URL}, infoHash)
+	require.ErrorContains(t, err, "hash mismatch at piece 3")
+	require.NoFileExists(t, filepath.Join(snapDir, testSegName))
+	require.NoFileExists(t, filepath.Join(snapDir, "webseed", testSegName))
+
+	// The .torrent file with another info hash is not accepted
+	_, err = testDownload(t, t.TempDir(), []string{server.URL}, metainfo.Hash{1})
+	require.ErrorContains(t, err, "not found on webseeds")
+}
+
+func TestWebSeedsLocalMirror(t *testing.T) {
+	data := make([]byte, 40*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	mirror, infoHash := testMirror(t, data)
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// The file is taken from the next webseed if the first one doesn't have it
+	snapDir := t.TempDir()
+	_, err = testDownload(t, snapDir, []string{server.URL, "file://" + mirror}, infoHash)
+	require.NoError(t, err)
+	got, err := os.ReadFile(filepath.Join(snapDir, testSegName))
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+}
+
+func TestWebSeedsUnknownLength(t *testing.T) {
+	data := make([]byte, 100*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	mirror, infoHash := testMirror(t, data)
+	// The file is sent without Content-Length and is longer than in the .torrent file
+	endless := make([]byte, 1024*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if filepath.Ext(r.URL.Path) != ".seg" {
+			http.FileServer(http.Dir(mirror)).ServeHTTP(rw, r)
+			return
+		}
+		for i := 0; i < 64; i++ {
+			if _, err := rw.Write(endless); err != nil {
+				return
+			}
+			rw.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	snapDir := t.TempDir()
+	w, err := testDownload(t, snapDir, []string{server.URL}, infoHash)
+	require.ErrorContains(t, err, "wrong length")
+	require.NoFileExists(t, filepath.Join(snapDir, testSegName))
+	require.NoFileExists(t, filepath.Join(snapDir, "webseed", testSegName))
+	// The download stops one byte past the expected length
+	_, _, _, bytesDownload := w.Stats()
+	require.Equal(t, uint64(len(data)+1), bytesDownload)
+}
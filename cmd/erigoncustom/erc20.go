@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb/cbor"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/log/v3"
+)
+
+const (
+	// ERC20Transfers - index of ERC-20 transfers
+	// key - address of the sender or the recipient of the tokens
+	// value - block number (8 bytes) + address of the token contract
+	ERC20Transfers = "ch.torquem.demo.tgcustom.ERC20_TRANSFERS"
+
+	// ERC20TransfersStage - fills ERC20Transfers with the logs of the executed blocks
+	ERC20TransfersStage stages.SyncStage = "ch.torquem.demo.tgcustom.ERC20Transfers"
+)
+
+// transferTopic - topic of the `Transfer(address indexed from, address indexed to, uint256 value)` event.
+// ERC-721 has the same event, but its third argument is indexed too - so it's told apart by the number of topics
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// erc20Plugin - the ERC-20 transfers index: custom table, the stage running after Execution, and the `erc20` RPC namespace
+func erc20Plugin(enabled bool) eth.Plugin {
+	return eth.Plugin{
+		Tables: kv.TableCfg{ERC20Transfers: {Flags: kv.DupSort}},
+		Stages: func(db kv.RwDB) []stagedsync.CustomStage {
+			return []stagedsync.CustomStage{{Stage: erc20TransfersStage(db, enabled), After: stages.Execution}}
+		},
+		APIs: func(db kv.RoDB) []rpc.API {
+			return []rpc.API{{Namespace: "erc20", Public: true, Service: &ERC20API{db: db}, Version: "1.0"}}
+		},
+	}
+}
+
+func erc20TransfersStage(db kv.RwDB, enabled bool) *stagedsync.Stage {
+	return &stagedsync.Stage{
+		ID:                  ERC20TransfersStage,
+		Description:         "Index ERC-20 transfers",
+		DisabledDescription: "Enable by --" + indexFlag.Name,
+		Disabled:            !enabled,
+		Forward: func(firstCycle bool, badBlockUnwind bool, s *stagedsync.StageState, u stagedsync.Unwinder, tx kv.RwTx) error {
+			return withTx(db, tx, func(tx kv.RwTx) error { return spawnERC20Transfers(s, tx) })
+		},
+		Unwind: func(firstCycle bool, u *stagedsync.UnwindState, s *stagedsync.StageState, tx kv.RwTx) error {
+			return withTx(db, tx, func(tx kv.RwTx) error { return unwindERC20Transfers(u, s, tx) })
+		},
+		// Prune is not set - the index is kept for all blocks
+	}
+}
+
+// withTx runs f in the transaction of the staged sync, or in a new one if the stage runs without it
+func withTx(db kv.RwDB, tx kv.RwTx, f func(tx kv.RwTx) error) error {
+	if tx != nil {
+		return f(tx)
+	}
+	return db.Update(context.Background(), f)
+}
+
+func spawnERC20Transfers(s *stagedsync.StageState, tx kv.RwTx) error {
+	endBlock, err := s.ExecutionAt(tx)
+	if err != nil {
+		return fmt.Errorf("getting last executed block: %w", err)
+	}
+	if endBlock <= s.BlockNumber {
+		return nil
+	}
+	logPrefix := s.LogPrefix()
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+
+	c, err := tx.RwCursorDupSort(ERC20Transfers)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := forEachTransfer(tx, s.BlockNumber+1, endBlock, func(blockNum uint64, token, from, to common.Address) error {
+		for _, account := range []common.Address{from, to} {
+			if err := c.Put(account[:], transferValue(blockNum, token)); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-logEvery.C:
+			log.Info(fmt.Sprintf("[%s] Progress", logPrefix), "block", blockNum)
+		default:
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return s.Update(tx, endBlock)
+}
+
+func unwindERC20Transfers(u *stagedsync.UnwindState, s *stagedsync.StageState, tx kv.RwTx) error {
+	c, err := tx.RwCursorDupSort(ERC20Transfers)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	// Logs of the unwound blocks are still there - the stage is unwound before Execution
+	if err := forEachTransfer(tx, u.UnwindPoint+1, s.BlockNumber, func(blockNum uint64, token, from, to common.Address) error {
+		for _, account := range []common.Address{from, to} {
+			if err := c.DeleteExact(account[:], transferValue(blockNum, token)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return u.Done(tx)
+}
+
+// forEachTransfer calls f for ERC-20 transfers in the logs of the blocks [from, to]
+func forEachTransfer(tx kv.Tx, fromBlock, toBlock uint64, f func(blockNum uint64, token, from, to common.Address) error) error {
+	logs, err := tx.Cursor(kv.Log)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	reader := bytes.NewReader(nil)
+	for k, v, err := logs.Seek(dbutils.LogKey(fromBlock, 0)); k != nil; k, v, err = logs.Next() {
+		if err != nil {
+			return err
+		}
+		blockNum := binary.BigEndian.Uint64(k[:8])
+		if blockNum > toBlock {
+			break
+		}
+		var ll types.Logs
+		reader.Reset(v)
+		if err := cbor.Unmarshal(&ll, reader); err != nil {
+			return fmt.Errorf("receipt unmarshal failed: %w, block=%d", err, blockNum)
+		}
+		for _, l := range ll {
+			if len(l.Topics) != 3 || l.Topics[0] != transferTopic {
+				continue
+			}
+			if err := f(blockNum, l.Address, common.BytesToAddress(l.Topics[1][:]), common.BytesToAddress(l.Topics[2][:])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func transferValue(blockNum uint64, token common.Address) []byte {
+	v := make([]byte, 8+common.AddressLength)
+	binary.BigEndian.PutUint64(v, blockNum)
+	copy(v[8:], token[:])
+	return v
+}
+
+// ERC20API - the `erc20` RPC namespace
+type ERC20API struct {
+	db kv.RoDB
+}
+
+// Transfer - ERC-20 transfer from or to the account
+type Transfer struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Token       common.Address `json:"token"`
+}
+
+// GetTransfers implements erc20_getTransfers. Returns the transfers from or to the account in the blocks [fromBlock, toBlock],
+// ordered by block number
+func (api *ERC20API) GetTransfers(ctx context.Context, account common.Address, fromBlock, toBlock hexutil.Uint64) ([]Transfer, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	c, err := tx.CursorDupSort(ERC20Transfers)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	transfers := []Transfer{}
+	for v, err := c.SeekBothRange(account[:], dbutils.EncodeBlockNumber(uint64(fromBlock))); v != nil; _, v, err = c.NextDup() {
+		if err != nil {
+			return nil, err
+		}
+		blockNum := binary.BigEndian.Uint64(v[:8])
+		if blockNum > uint64(toBlock) {
+			break
+		}
+		transfers = append(transfers, Transfer{BlockNumber: hexutil.Uint64(blockNum), Token: common.BytesToAddress(v[8:])})
+	}
+	return transfers, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestERC20Transfers(t *testing.T) {
+	plugin := erc20Plugin(true)
+	db := mdbx.NewMDBX(log.New()).InMem().WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		tables := kv.TableCfg{}
+		for name, cfg := range defaultBuckets {
+			tables[name] = cfg
+		}
+		for name, cfg := range plugin.Tables {
+			tables[name] = cfg
+		}
+		return tables
+	}).MustOpen()
+	defer db.Close()
+
+	alice, bob := common.Address{1}, common.Address{2}
+	token1, token2 := common.Address{10}, common.Address{20}
+	transfer := func(token, from, to common.Address) *types.Log {
+		return &types.Log{Address: token, Topics: []common.Hash{transferTopic, from.Hash(), to.Hash()}, Data: common.Hash{1}.Bytes()}
+	}
+	receipt := func(logs ...*types.Log) *types.Receipt {
+		r := types.NewReceipt(false, 21000)
+		r.Logs = logs
+		return r
+	}
+	ctx := context.Background()
+	tx, err := db.BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, rawdb.WriteReceipts(tx, 1, types.Receipts{receipt(transfer(token1, alice, bob))}))
+	// ERC-721 transfer is not indexed
+	nft := transfer(token2, bob, alice)
+	nft.Topics = append(nft.Topics, common.Hash{1})
+	require.NoError(t, rawdb.WriteReceipts(tx, 2, types.Receipts{receipt(nft), receipt(transfer(token2, bob, alice), transfer(token2, bob, alice))}))
+	require.NoError(t, rawdb.WriteReceipts(tx, 3, types.Receipts{receipt(transfer(token1, bob, bob))}))
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Execution, 3))
+
+	customStages := plugin.Stages(db)
+	require.Len(t, customStages, 1)
+	require.Equal(t, stages.Execution, customStages[0].After)
+	sync := stagedsync.New([]*stagedsync.Stage{customStages[0].Stage}, stagedsync.UnwindOrder{ERC20TransfersStage}, stagedsync.PruneOrder{})
+	require.NoError(t, sync.Run(db, tx, true))
+	progress, err := stages.GetStageProgress(tx, ERC20TransfersStage)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), progress)
+	require.NoError(t, tx.Commit())
+
+	apis := plugin.APIs(db)
+	require.Len(t, apis, 1)
+	require.Equal(t, "erc20", apis[0].Namespace)
+	api := apis[0].Service.(*ERC20API)
+	transfers, err := api.GetTransfers(ctx, alice, 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{{BlockNumber: 1, Token: token1}, {BlockNumber: 2, Token: token2}}, transfers)
+	transfers, err = api.GetTransfers(ctx, bob, 2, 3)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{{BlockNumber: 2, Token: token2}, {BlockNumber: 3, Token: token1}}, transfers)
+
+	// Unwind removes the transfers of the unwound blocks
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		sync.UnwindTo(1, common.Hash{})
+		return sync.RunUnwind(db, tx)
+	}))
+	transfers, err = api.GetTransfers(ctx, bob, 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{{BlockNumber: 1, Token: token1}}, transfers)
+	transfers, err = api.GetTransfers(ctx, alice, 2, 3)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
@@ -6,26 +6,23 @@ import (
 
 	erigonapp "github.com/ledgerwatch/erigon/turbo/app"
 	erigoncli "github.com/ledgerwatch/erigon/turbo/cli"
+	"github.com/ledgerwatch/erigon/turbo/node"
+	"github.com/ledgerwatch/log/v3"
 
 	"github.com/urfave/cli"
 )
 
-// defining a custom command-line flag, a string
-var flag = cli.StringFlag{
-	Name:  "custom-stage-greeting",
-	Value: "default-value",
+// defining a custom command-line flag, a bool
+var indexFlag = cli.BoolFlag{
+	Name:  "erc20.index",
+	Usage: "Index ERC-20 transfers, the index is served by the erc20 RPC namespace (add it to --http.api)",
 }
 
-// defining a custom bucket name
-const (
-	customBucketName = "ch.torquem.demo.tgcustom.CUSTOM_BUCKET" //nolint
-)
-
 // the regular main function
 func main() {
 	// initializing Erigon application here and providing our custom flag
 	app := erigonapp.MakeApp(runErigon,
-		append(erigoncli.DefaultFlags, flag), // always use DefaultFlags, but add a new one in the end.
+		append(erigoncli.DefaultFlags, indexFlag), // always use DefaultFlags, but add a new one in the end.
 	)
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -34,17 +31,18 @@ func main() {
 }
 
 // Erigon main function
-func runErigon(ctx *cli.Context) {
-	// running a node and initializing a custom bucket with all default settings
-	//eri := node.New(ctx, node.Params{
-	//	CustomBuckets: map[string]dbutils.BucketConfigItem{
-	//		customBucketName: {},
-	//	},
-	//})
-
-	//err := eri.Serve()
-
-	//if err != nil {
-	//	log.Error("error while serving a Erigon node", "err", err)
-	//}
+func runErigon(cliCtx *cli.Context) {
+	logger := log.New()
+	nodeCfg := node.NewNodConfigUrfave(cliCtx)
+	ethCfg := node.NewEthConfigUrfave(cliCtx, nodeCfg)
+
+	// running a node with the custom table, stage and RPC namespace
+	eri, err := node.New(nodeCfg, ethCfg, logger, erc20Plugin(cliCtx.GlobalBool(indexFlag.Name)))
+	if err != nil {
+		log.Error("Erigon startup", "err", err)
+		return
+	}
+	if err = eri.Serve(); err != nil {
+		log.Error("error while serving an Erigon node", "err", err)
+	}
 }
@@ -397,7 +397,7 @@ func getTransaction(txJson commands.RPCTransaction) (types.Transaction, error) {
 			return legacyTx, nil
 		}
 
-	case types.DynamicFeeTxType:
+	case types.DynamicFeeTxType, types.BlobTxType:
 		var tip *uint256.Int
 		var feeCap *uint256.Int
 		if txJson.Tip != nil {
@@ -432,6 +432,20 @@ func getTransaction(txJson commands.RPCTransaction) (types.Transaction, error) {
 		dynamicFeeTx.S.SetFromBig(txJson.S.ToInt())
 		dynamicFeeTx.R.SetFromBig(txJson.R.ToInt())
 
+		if txJson.Type == types.BlobTxType {
+			maxFeePerBlobGas := new(uint256.Int)
+			if txJson.MaxFeePerBlobGas != nil {
+				maxFeePerBlobGas, overflow = uint256.FromBig((*big.Int)(txJson.MaxFeePerBlobGas))
+				if overflow {
+					return nil, fmt.Errorf("maxFeePerBlobGas field caused an overflow (uint256)")
+				}
+			}
+			return &types.BlobTx{
+				DynamicFeeTransaction: dynamicFeeTx,
+				MaxFeePerBlobGas:      maxFeePerBlobGas,
+				BlobVersionedHashes:   txJson.BlobHashes,
+			}, nil
+		}
 		return &dynamicFeeTx, nil
 
 	default:
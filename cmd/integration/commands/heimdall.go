@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"path/filepath"
+
+	common2 "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/consensus/bor"
+	"github.com/ledgerwatch/erigon/consensus/db"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/spf13/cobra"
+)
+
+var heimdallDir string
+
+var cmdExportHeimdall = &cobra.Command{
+	Use:   "export_heimdall",
+	Short: "Export Heimdall spans and state sync events saved by BorHeimdall stage, to re-execute blocks with --bor.heimdall=file://<dir>",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, _ := common2.RootContext()
+		borDb := db.OpenDatabase(filepath.Join(datadirCli, "bor"), log.New(), false, true /* readonly */)
+		defer borDb.Close()
+
+		return borDb.View(ctx, func(tx kv.Tx) error {
+			spans, events, err := bor.ExportHeimdallRecords(tx, heimdallDir)
+			if err != nil {
+				return err
+			}
+			log.Info("Exported Heimdall records", "dir", heimdallDir, "spans", spans, "events", events)
+			return nil
+		})
+	},
+}
+
+func init() {
+	withDataDir(cmdExportHeimdall)
+	cmdExportHeimdall.Flags().StringVar(&heimdallDir, "dir", "", "directory to export the records to")
+	must(cmdExportHeimdall.MarkFlagRequired("dir"))
+	rootCmd.AddCommand(cmdExportHeimdall)
+}
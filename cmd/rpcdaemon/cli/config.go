@@ -29,14 +29,22 @@ import (
 	kv2 "github.com/ledgerwatch/erigon-lib/kv/mdbx"
 	"github.com/ledgerwatch/erigon-lib/kv/remotedb"
 	"github.com/ledgerwatch/erigon-lib/kv/remotedbserver"
+	"github.com/ledgerwatch/erigon/accounts"
+	"github.com/ledgerwatch/erigon/accounts/external"
+	"github.com/ledgerwatch/erigon/accounts/keystore"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli/httpcfg"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/graphql"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/health"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcservices"
 	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/cmd/verkle/verkletrie"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/common/paths"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
+	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/erigon/internal/debug"
 	"github.com/ledgerwatch/erigon/node"
 	"github.com/ledgerwatch/erigon/node/nodecfg"
@@ -92,6 +100,13 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().IntVar(&cfg.GRPCPort, "grpc.port", nodecfg.DefaultGRPCPort, "GRPC server listening port")
 	rootCmd.PersistentFlags().BoolVar(&cfg.GRPCHealthCheckEnabled, "grpc.healthcheck", false, "Enable GRPC health check")
 	rootCmd.PersistentFlags().BoolVar(&cfg.TraceRequests, utils.HTTPTraceFlag.Name, false, "Trace HTTP requests with INFO level")
+	rootCmd.PersistentFlags().BoolVar(&cfg.GraphQLEnabled, utils.GraphQLEnabledFlag.Name, false, utils.GraphQLEnabledFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&cfg.StarknetGRPCAddress, utils.StarknetGrpcAddressFlag.Name, "", utils.StarknetGrpcAddressFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&cfg.FiltersDBPath, utils.RpcFiltersDBFlag.Name, "", utils.RpcFiltersDBFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&cfg.Keystore, utils.RpcKeystoreFlag.Name, "", utils.RpcKeystoreFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&cfg.KeystorePasswordFile, utils.RpcKeystorePasswordFlag.Name, "", utils.RpcKeystorePasswordFlag.Usage)
+	rootCmd.PersistentFlags().StringVar(&cfg.ExternalSigner, utils.RpcExternalSignerFlag.Name, "", utils.RpcExternalSignerFlag.Usage)
+	rootCmd.PersistentFlags().BoolVar(&cfg.InsecureUnlockAllowed, utils.InsecureUnlockAllowedFlag.Name, false, utils.InsecureUnlockAllowedFlag.Usage)
 	rootCmd.PersistentFlags().DurationVar(&cfg.HTTPTimeouts.ReadTimeout, "http.timeouts.read", rpccfg.DefaultHTTPTimeouts.ReadTimeout, "Maximum duration for reading the entire request, including the body.")
 	rootCmd.PersistentFlags().DurationVar(&cfg.HTTPTimeouts.WriteTimeout, "http.timeouts.write", rpccfg.DefaultHTTPTimeouts.WriteTimeout, "Maximum duration before timing out writes of the response. It is reset whenever a new request's header is read")
 	rootCmd.PersistentFlags().DurationVar(&cfg.HTTPTimeouts.IdleTimeout, "http.timeouts.idle", rpccfg.DefaultHTTPTimeouts.IdleTimeout, "Maximum amount of time to wait for the next request when keep-alives are enabled. If http.timeouts.idle is zero, the value of http.timeouts.read is used")
@@ -210,7 +225,7 @@ func checkDbCompatibility(ctx context.Context, db kv.RoDB) error {
 }
 
 func EmbeddedServices(ctx context.Context,
-	erigonDB kv.RoDB, stateCacheCfg kvcache.CoherentConfig,
+	erigonDB kv.RoDB, stateCacheCfg kvcache.CoherentConfig, filtersDBPath string,
 	blockReader services.FullBlockReader, snapshots *snapshotsync.RoSnapshots,
 	ethBackendServer remote.ETHBACKENDServer, txPoolServer txpool.TxpoolServer, miningServer txpool.MiningServer,
 ) (eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient, stateCache kvcache.Cache, ff *rpchelper.Filters, txNums *exec22.TxNums, err error) {
@@ -230,10 +245,20 @@ func EmbeddedServices(ctx context.Context,
 
 	directClient := direct.NewEthBackendClientDirect(ethBackendServer)
 
-	eth = rpcservices.NewRemoteBackend(directClient, erigonDB, blockReader)
+	remoteBackend := rpcservices.NewRemoteBackend(directClient, erigonDB, blockReader)
+	if engineV2, ok := ethBackendServer.(privateapi.EngineV2); ok {
+		remoteBackend.SetEngineV2(engineV2)
+	}
+	if peerAdmin, ok := ethBackendServer.(privateapi.PeerAdmin); ok {
+		remoteBackend.SetPeerAdmin(peerAdmin)
+	}
+	eth = remoteBackend
 	txPool = direct.NewTxPoolClient(txPoolServer)
 	mining = direct.NewMiningClient(miningServer)
 	ff = rpchelper.New(ctx, eth, txPool, mining, func() {})
+	if err = openFilterStore(ctx, ff, filtersDBPath); err != nil {
+		return
+	}
 
 	if snapshots != nil && snapshots.Cfg().Enabled { // nolint: staticcheck
 		/*
@@ -257,6 +282,92 @@ func EmbeddedServices(ctx context.Context,
 	return
 }
 
+// openFilterStore - polling filters survive restarts if path is set, otherwise they are kept in memory.
+// Filters which were not polled for StoredFilterTimeout are removed.
+func openFilterStore(ctx context.Context, ff *rpchelper.Filters, path string) error {
+	if path == "" {
+		return nil
+	}
+	store, err := rpchelper.OpenFilterStore(path)
+	if err != nil {
+		return fmt.Errorf("open filters db: %w", err)
+	}
+	ff.SetStore(store)
+	go func() {
+		defer store.Close()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ids, err := store.Prune(ctx, time.Now().Add(-rpchelper.StoredFilterTimeout))
+				if err != nil {
+					log.Warn("rpc filters: pruning stale filters", "err", err)
+					continue
+				}
+				for _, id := range ids {
+					ff.UnsubscribePendingTxs(rpchelper.PendingTxsSubID(id))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// OpenAccounts loads the signing accounts of the node from the keystore and connects to the external signer, if configured.
+// The manager has no accounts otherwise.
+func OpenAccounts(cfg httpcfg.HttpCfg) (*accounts.Manager, error) {
+	// rpcdaemon only serves http and websocket, where anyone reaching the eth API could sign with the accounts
+	if (cfg.Keystore != "" || cfg.ExternalSigner != "") && !cfg.InsecureUnlockAllowed {
+		return nil, fmt.Errorf("--%s and --%s accounts sign requests over http and websocket, which needs --%s",
+			utils.RpcKeystoreFlag.Name, utils.RpcExternalSignerFlag.Name, utils.InsecureUnlockAllowedFlag.Name)
+	}
+	var backends []accounts.Backend
+	if cfg.Keystore != "" {
+		var passwords []string
+		if cfg.KeystorePasswordFile != "" {
+			content, err := os.ReadFile(cfg.KeystorePasswordFile)
+			if err != nil {
+				return nil, fmt.Errorf("read keystore passwords: %w", err)
+			}
+			passwords = strings.Split(strings.TrimRight(string(content), "\r\n"), "\n")
+			for i := range passwords {
+				passwords[i] = strings.TrimRight(passwords[i], "\r")
+			}
+		}
+		ks, err := keystore.Open(cfg.Keystore, passwords)
+		if err != nil {
+			return nil, fmt.Errorf("open keystore: %w", err)
+		}
+		log.Info("Opened keystore", "dir", cfg.Keystore, "accounts", len(ks.Accounts()))
+		backends = append(backends, ks)
+	}
+	if cfg.ExternalSigner != "" {
+		signer, err := external.Dial(cfg.ExternalSigner)
+		if err != nil {
+			return nil, fmt.Errorf("connect to external signer: %w", err)
+		}
+		log.Info("Connected to external signer", "endpoint", cfg.ExternalSigner, "accounts", len(signer.Accounts()))
+		backends = append(backends, signer)
+	}
+	return accounts.NewManager(backends...), nil
+}
+
+// DialCairoRunner connects to the Cairo runner which executes Starknet transactions, the connection is nil if
+// --starknet.grpc.address is not set
+func DialCairoRunner(ctx context.Context, cfg httpcfg.HttpCfg) (*grpc.ClientConn, error) {
+	if cfg.StarknetGRPCAddress == "" {
+		return nil, nil
+	}
+	conn, err := cairo.Dial(ctx, cfg.StarknetGRPCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("connect to the cairo runner at %s: %w", cfg.StarknetGRPCAddress, err)
+	}
+	return conn, nil
+}
+
 // RemoteServices - use when RPCDaemon run as independent process. Still it can use --datadir flag to enable
 // `cfg.WithDatadir` (mode when it on 1 machine with Erigon)
 func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger, rootCancel context.CancelFunc) (
@@ -277,7 +388,16 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 		var rwKv kv.RwDB
 		log.Trace("Creating chain db", "path", cfg.Dirs.Chaindata)
 		limiter := semaphore.NewWeighted(int64(cfg.DBReadConcurrency))
-		rwKv, err = kv2.NewMDBX(logger).RoTxsLimiter(limiter).Path(cfg.Dirs.Chaindata).Readonly().Open()
+		rwKv, err = kv2.NewMDBX(logger).RoTxsLimiter(limiter).Path(cfg.Dirs.Chaindata).Readonly().WithTableCfg(func(defaultBuckets kv.TableCfg) kv.TableCfg {
+			tables := make(kv.TableCfg, len(defaultBuckets))
+			for name, tableCfg := range defaultBuckets {
+				tables[name] = tableCfg
+			}
+			for name, tableCfg := range verkletrie.ReaderTablesCfg() {
+				tables[name] = tableCfg
+			}
+			return tables
+		}).Open()
 		if err != nil {
 			return nil, nil, nil, nil, nil, nil, nil, ff, nil, nil, err
 		}
@@ -396,6 +516,7 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 		blockReader = snapshotsync.NewRemoteBlockReader(remote.NewETHBACKENDClient(conn))
 	}
 	remoteEth := rpcservices.NewRemoteBackend(remote.NewETHBACKENDClient(conn), db, blockReader)
+	remoteEth.SetPeerAdmin(privateapi.NewPeerAdminClient(conn))
 	blockReader = remoteEth
 
 	txpoolConn := conn
@@ -430,6 +551,9 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 	}()
 
 	ff = rpchelper.New(ctx, eth, txPool, mining, onNewSnapshot)
+	if err = openFilterStore(ctx, ff, cfg.FiltersDBPath); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, ff, nil, nil, err
+	}
 	if cfg.WithDatadir {
 		e22Dir := filepath.Join(cfg.DataDir, "erigon22")
 		dir.MustExist(e22Dir)
@@ -470,9 +594,17 @@ func startRegularRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rp
 	srv.SetAllowList(allowListForRPC)
 
 	var defaultAPIList []rpc.API
+	var graphQLHandler http.Handler
 
 	for _, api := range rpcAPI {
-		if api.Namespace != "engine" {
+		switch api.Namespace {
+		case "engine":
+		case "graphql":
+			// GraphQL is served over plain HTTP next to JSON-RPC, not as an RPC namespace
+			if graphQLHandler, err = graphql.CreateHandler(api.Service.(commands.GraphQLAPI)); err != nil {
+				return fmt.Errorf("could not create GraphQL handler: %w", err)
+			}
+		default:
 			defaultAPIList = append(defaultAPIList, api)
 		}
 	}
@@ -494,7 +626,11 @@ func startRegularRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rp
 		wsHandler = srv.WebsocketHandler([]string{"*"}, nil, cfg.WebsocketCompression)
 	}
 
-	apiHandler, err := createHandler(cfg, defaultAPIList, httpHandler, wsHandler, nil)
+	if graphQLHandler != nil {
+		graphQLHandler = node.NewHTTPHandlerStack(graphQLHandler, cfg.HttpCORSDomain, cfg.HttpVirtualHost, cfg.HttpCompression)
+	}
+
+	apiHandler, err := createHandler(cfg, defaultAPIList, httpHandler, wsHandler, graphQLHandler, nil)
 	if err != nil {
 		return err
 	}
@@ -504,7 +640,7 @@ func startRegularRpcServer(ctx context.Context, cfg httpcfg.HttpCfg, rpcAPI []rp
 		return fmt.Errorf("could not start RPC api: %w", err)
 	}
 	info := []interface{}{"url", httpEndpoint, "ws", cfg.WebsocketEnabled,
-		"ws.compression", cfg.WebsocketCompression, "grpc", cfg.GRPCServerEnabled}
+		"ws.compression", cfg.WebsocketCompression, "grpc", cfg.GRPCServerEnabled, "graphql", graphQLHandler != nil}
 
 	var (
 		healthServer *grpcHealth.Server
@@ -620,7 +756,7 @@ func obtainJWTSecret(cfg httpcfg.HttpCfg) ([]byte, error) {
 	return jwtSecret, nil
 }
 
-func createHandler(cfg httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Handler, wsHandler http.Handler, jwtSecret []byte) (http.Handler, error) {
+func createHandler(cfg httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Handler, wsHandler http.Handler, graphQLHandler http.Handler, jwtSecret []byte) (http.Handler, error) {
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// adding a healthcheck here
 		if health.ProcessHealthcheckIfNeeded(w, r, apiList) {
@@ -635,6 +771,11 @@ func createHandler(cfg httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Hand
 			return
 		}
 
+		if graphQLHandler != nil && r.URL.Path == "/graphql" {
+			graphQLHandler.ServeHTTP(w, r)
+			return
+		}
+
 		httpHandler.ServeHTTP(w, r)
 	})
 
@@ -659,7 +800,7 @@ func createEngineListener(cfg httpcfg.HttpCfg, engineApi []rpc.API) (*http.Serve
 
 	engineHttpHandler := node.NewHTTPHandlerStack(engineSrv, nil /* authCors */, cfg.AuthRpcVirtualHost, cfg.HttpCompression)
 
-	engineApiHandler, err := createHandler(cfg, engineApi, engineHttpHandler, wsHandler, jwtSecret)
+	engineApiHandler, err := createHandler(cfg, engineApi, engineHttpHandler, wsHandler, nil, jwtSecret)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -25,6 +25,7 @@ type HttpCfg struct {
 	HttpVirtualHost          []string
 	AuthRpcVirtualHost       []string
 	HttpCompression          bool
+	GraphQLEnabled           bool
 	API                      []string
 	Gascap                   uint64
 	MaxTraces                uint64
@@ -44,6 +45,11 @@ type HttpCfg struct {
 	GRPCPort                 int
 	GRPCHealthCheckEnabled   bool
 	StarknetGRPCAddress      string
+	FiltersDBPath            string // eth_newFilter and friends survive restarts if set
+	Keystore                 string // directory of encrypted key files used by eth_sendTransaction and eth_sign
+	KeystorePasswordFile     string // file with the passwords of the keystore, one per line
+	ExternalSigner           string // URL or socket path of an external signer, such as clef
+	InsecureUnlockAllowed    bool   // the accounts sign requests over http and websocket, not only over IPC
 	JWTSecretPath            string // Engine API Authentication
 	TraceRequests            bool   // Always trace requests in INFO level
 	HTTPTimeouts             rpccfg.HTTPTimeouts
@@ -5,8 +5,15 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
+	"github.com/ledgerwatch/erigon/common/debug"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/enode"
+	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/log/v3"
 )
 
 // AdminAPI the interface for the admin_* RPC commands.
@@ -17,6 +24,28 @@ type AdminAPI interface {
 	// Peers returns information about the connected remote nodes.
 	// https://geth.ethereum.org/docs/rpc/ns-admin#admin_peers
 	Peers(ctx context.Context) ([]*p2p.PeerInfo, error)
+
+	// AddPeer requests connecting to a remote node, and also maintaining the new
+	// connection at all times, even reconnecting if it is lost.
+	// https://geth.ethereum.org/docs/rpc/ns-admin#admin_addpeer
+	AddPeer(ctx context.Context, url string) (bool, error)
+
+	// RemovePeer disconnects from a remote node if the connection exists.
+	// https://geth.ethereum.org/docs/rpc/ns-admin#admin_removepeer
+	RemovePeer(ctx context.Context, url string) (bool, error)
+
+	// AddTrustedPeer allows a remote node to always connect, even if slots are full.
+	// https://geth.ethereum.org/docs/rpc/ns-admin#admin_addtrustedpeer
+	AddTrustedPeer(ctx context.Context, url string) (bool, error)
+
+	// PeerEvents creates an RPC subscription which receives peer events from the sentries.
+	PeerEvents(ctx context.Context) (*rpc.Subscription, error)
+}
+
+// PeerEvent is a notification of the admin_peerEvents subscription, Peer is the enode ID of the peer.
+type PeerEvent struct {
+	Type p2p.PeerEventType `json:"type"`
+	Peer enode.ID          `json:"peer"`
 }
 
 // AdminAPIImpl data structure to store things needed for admin_* commands.
@@ -47,3 +76,55 @@ func (api *AdminAPIImpl) NodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
 func (api *AdminAPIImpl) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
 	return api.ethBackend.Peers(ctx)
 }
+
+func (api *AdminAPIImpl) AddPeer(ctx context.Context, url string) (bool, error) {
+	return api.ethBackend.AddPeer(ctx, url)
+}
+
+func (api *AdminAPIImpl) RemovePeer(ctx context.Context, url string) (bool, error) {
+	return api.ethBackend.RemovePeer(ctx, url)
+}
+
+func (api *AdminAPIImpl) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return api.ethBackend.AddTrustedPeer(ctx, url)
+}
+
+func (api *AdminAPIImpl) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer debug.LogPanic()
+		subCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-rpcSub.Err():
+				cancel()
+			case <-subCtx.Done():
+			}
+		}()
+
+		err := api.ethBackend.PeerEvents(subCtx, func(event *sentry.PeerEvent) {
+			eventType := p2p.PeerEventTypeAdd
+			if event.EventId == sentry.PeerEvent_Disconnect {
+				eventType = p2p.PeerEventTypeDrop
+			}
+			// Sentries identify peers by their public keys, enode ID is the hash of the public key
+			peerID := enode.ID(crypto.Keccak256Hash(gointerfaces.ConvertH512ToBytes(event.PeerId)))
+			if err := notifier.Notify(rpcSub.ID, &PeerEvent{Type: eventType, Peer: peerID}); err != nil {
+				log.Warn("error while notifying subscription", "err", err)
+				cancel()
+			}
+		})
+		if err != nil {
+			log.Warn("peer events subscription closed", "err", err)
+		}
+	}()
+
+	return rpcSub, nil
+}
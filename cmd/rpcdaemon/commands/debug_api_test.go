@@ -4,16 +4,27 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/serenity"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/eth/tracers"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/rpc/rpccfg"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
-	"testing"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/ledgerwatch/erigon/turbo/stateless"
 )
 
 var debugTraceTransactionTests = []struct {
@@ -183,3 +194,112 @@ func TestTraceTransactionNoRefund(t *testing.T) {
 		}
 	}
 }
+
+func TestGetBlockWitness(t *testing.T) {
+	db, _, _ := chainWithDeployedContract(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewPrivateDebugAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, 0)
+
+	tx, err := db.BeginRo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, blockNr := range []rpc.BlockNumber{1, 2} {
+		witness, err := api.GetBlockWitness(context.Background(), rpc.BlockNumberOrHashWithNumber(blockNr))
+		if err != nil {
+			t.Fatalf("block %d: %v", blockNr, err)
+		}
+		block, err := api.blockByRPCNumber(blockNr, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent, err := api.headerByRPCNumber(blockNr-1, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		preRoot, postRoot, err := stateless.ExecuteBlock(chainConfig, serenity.New(ethash.NewFaker()), block, witness)
+		if err != nil {
+			t.Fatalf("block %d: %v", blockNr, err)
+		}
+		if preRoot != parent.Root {
+			t.Errorf("block %d: witness has root %x, expected %x", blockNr, preRoot, parent.Root)
+		}
+		if postRoot != block.Root() {
+			t.Errorf("block %d: stateless execution computed root %x, expected %x", blockNr, postRoot, block.Root())
+		}
+	}
+
+	if _, err = api.GetBlockWitness(context.Background(), rpc.BlockNumberOrHashWithNumber(0)); err == nil {
+		t.Errorf("expected error for the genesis block")
+	}
+}
+
+// The witness of a block deleting a storage slot contains the neighbours of the slot, which are looked up in the
+// HashedStorage rewound to the parent block in a memory batch
+func TestGetBlockWitnessStorageDeletion(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		// clearer clears slot 1 of its storage
+		clearer = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		gspec   = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: core.GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000)},
+				clearer: {
+					Code: common.FromHex("0x600060015500"),
+					Storage: map[common.Hash]common.Hash{
+						common.HexToHash("0x01"): common.HexToHash("0x05"),
+						common.HexToHash("0x02"): common.HexToHash("0x06"),
+						common.HexToHash("0x03"): common.HexToHash("0x07"),
+					},
+					Balance: new(big.Int),
+				},
+			},
+		}
+		signer = types.LatestSignerForChainID(nil)
+	)
+	m := stages.MockWithGenesis(t, gspec, key, false)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		to := clearer
+		if i == 1 {
+			to = common.Address{1}
+		}
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(address), to, new(uint256.Int), 100000, new(uint256.Int), nil), *signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddTx(txn)
+	}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewPrivateDebugAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), m.DB, 0)
+	// The state of the genesis is rewound from the block 2
+	witness, err := api.GetBlockWitness(context.Background(), rpc.BlockNumberOrHashWithNumber(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := chain.Blocks[0]
+	preRoot, postRoot, err := stateless.ExecuteBlock(m.ChainConfig, serenity.New(ethash.NewFaker()), block, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preRoot != m.Genesis.Root() {
+		t.Errorf("witness has root %x, expected %x", preRoot, m.Genesis.Root())
+	}
+	if postRoot != block.Root() {
+		t.Errorf("stateless execution computed root %x, expected %x", postRoot, block.Root())
+	}
+}
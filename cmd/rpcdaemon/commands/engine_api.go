@@ -35,6 +35,14 @@ type ExecutionPayload struct {
 	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas" gencodec:"required"`
 	BlockHash     common.Hash     `json:"blockHash"     gencodec:"required"`
 	Transactions  []hexutil.Bytes `json:"transactions"  gencodec:"required"`
+	// Withdrawals are only in V2 payloads after Shanghai
+	Withdrawals []*types.Withdrawal `json:"withdrawals,omitempty"`
+}
+
+// GetPayloadV2Response is the result of engine_getPayloadV2
+type GetPayloadV2Response struct {
+	ExecutionPayload *ExecutionPayload `json:"executionPayload" gencodec:"required"`
+	BlockValue       *hexutil.Big      `json:"blockValue"       gencodec:"required"`
 }
 
 // PayloadAttributes represent the attributes required to start assembling a payload
@@ -49,6 +57,8 @@ type PayloadAttributes struct {
 	Timestamp             hexutil.Uint64 `json:"timestamp"             gencodec:"required"`
 	PrevRandao            common.Hash    `json:"prevRandao"            gencodec:"required"`
 	SuggestedFeeRecipient common.Address `json:"suggestedFeeRecipient" gencodec:"required"`
+	// Withdrawals are only in V2 attributes after Shanghai
+	Withdrawals []*types.Withdrawal `json:"withdrawals,omitempty"`
 }
 
 // TransitionConfiguration represents the correct configurations of the CL and the EL
@@ -64,6 +74,9 @@ type EngineAPI interface {
 	NewPayloadV1(context.Context, *ExecutionPayload) (map[string]interface{}, error)
 	GetPayloadV1(ctx context.Context, payloadID hexutil.Bytes) (*ExecutionPayload, error)
 	ExchangeTransitionConfigurationV1(ctx context.Context, transitionConfiguration TransitionConfiguration) (TransitionConfiguration, error)
+	ForkchoiceUpdatedV2(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributes) (map[string]interface{}, error)
+	NewPayloadV2(context.Context, *ExecutionPayload) (map[string]interface{}, error)
+	GetPayloadV2(ctx context.Context, payloadID hexutil.Bytes) (*GetPayloadV2Response, error)
 }
 
 // EngineImpl is implementation of the EngineAPI interface
@@ -88,6 +101,20 @@ func convertPayloadStatus(x *remote.EnginePayloadStatus) map[string]interface{}
 }
 
 func (e *EngineImpl) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributes) (map[string]interface{}, error) {
+	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, nil)
+}
+
+// ForkchoiceUpdatedV2 is ForkchoiceUpdatedV1 with withdrawals in the payload attributes after Shanghai.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_forkchoiceupdatedv2
+func (e *EngineImpl) ForkchoiceUpdatedV2(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributes) (map[string]interface{}, error) {
+	var withdrawals []*types.Withdrawal
+	if payloadAttributes != nil {
+		withdrawals = payloadAttributes.Withdrawals
+	}
+	return e.forkchoiceUpdated(ctx, forkChoiceState, payloadAttributes, withdrawals)
+}
+
+func (e *EngineImpl) forkchoiceUpdated(ctx context.Context, forkChoiceState *ForkChoiceState, payloadAttributes *PayloadAttributes, withdrawals []*types.Withdrawal) (map[string]interface{}, error) {
 	log.Debug("Received ForkchoiceUpdated", "head", forkChoiceState.HeadHash, "safe", forkChoiceState.HeadHash, "finalized", forkChoiceState.FinalizedBlockHash,
 		"build", payloadAttributes != nil)
 
@@ -99,14 +126,14 @@ func (e *EngineImpl) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *F
 			SuggestedFeeRecipient: gointerfaces.ConvertAddressToH160(payloadAttributes.SuggestedFeeRecipient),
 		}
 	}
-	reply, err := e.api.EngineForkchoiceUpdatedV1(ctx, &remote.EngineForkChoiceUpdatedRequest{
+	reply, err := e.api.EngineForkchoiceUpdatedV2(ctx, &remote.EngineForkChoiceUpdatedRequest{
 		ForkchoiceState: &remote.EngineForkChoiceState{
 			HeadBlockHash:      gointerfaces.ConvertHashToH256(forkChoiceState.HeadHash),
 			SafeBlockHash:      gointerfaces.ConvertHashToH256(forkChoiceState.SafeBlockHash),
 			FinalizedBlockHash: gointerfaces.ConvertHashToH256(forkChoiceState.FinalizedBlockHash),
 		},
 		PayloadAttributes: prepareParameters,
-	})
+	}, withdrawals)
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +170,16 @@ func (e *EngineImpl) ForkchoiceUpdatedV1(ctx context.Context, forkChoiceState *F
 // NewPayloadV1 processes new payloads (blocks) from the beacon chain.
 // See https://github.com/ethereum/execution-apis/blob/main/src/engine/specification.md#engine_newpayloadv1
 func (e *EngineImpl) NewPayloadV1(ctx context.Context, payload *ExecutionPayload) (map[string]interface{}, error) {
+	return e.newPayload(ctx, payload, nil)
+}
+
+// NewPayloadV2 is NewPayloadV1 with withdrawals after Shanghai.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_newpayloadv2
+func (e *EngineImpl) NewPayloadV2(ctx context.Context, payload *ExecutionPayload) (map[string]interface{}, error) {
+	return e.newPayload(ctx, payload, payload.Withdrawals)
+}
+
+func (e *EngineImpl) newPayload(ctx context.Context, payload *ExecutionPayload, withdrawals []*types.Withdrawal) (map[string]interface{}, error) {
 	log.Debug("Received NewPayload", "height", uint64(payload.BlockNumber), "hash", payload.BlockHash)
 
 	var baseFee *uint256.Int
@@ -160,7 +197,7 @@ func (e *EngineImpl) NewPayloadV1(ctx context.Context, payload *ExecutionPayload
 	for i, transaction := range payload.Transactions {
 		transactions[i] = transaction
 	}
-	res, err := e.api.EngineNewPayloadV1(ctx, &types2.ExecutionPayload{
+	res, err := e.api.EngineNewPayloadV2(ctx, &types2.ExecutionPayload{
 		ParentHash:    gointerfaces.ConvertHashToH256(payload.ParentHash),
 		Coinbase:      gointerfaces.ConvertAddressToH160(payload.FeeRecipient),
 		StateRoot:     gointerfaces.ConvertHashToH256(payload.StateRoot),
@@ -175,7 +212,7 @@ func (e *EngineImpl) NewPayloadV1(ctx context.Context, payload *ExecutionPayload
 		BaseFeePerGas: gointerfaces.ConvertUint256IntToH256(baseFee),
 		BlockHash:     gointerfaces.ConvertHashToH256(payload.BlockHash),
 		Transactions:  transactions,
-	})
+	}, withdrawals)
 	if err != nil {
 		log.Warn("NewPayload", "err", err)
 		return nil, err
@@ -208,6 +245,28 @@ func (e *EngineImpl) GetPayloadV1(ctx context.Context, payloadID hexutil.Bytes)
 	if err != nil {
 		return nil, err
 	}
+	return convertPayloadFromRpc(payload), nil
+}
+
+// GetPayloadV2 also returns withdrawals of the payload and the value of the block for the fee recipient.
+// See https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#engine_getpayloadv2
+func (e *EngineImpl) GetPayloadV2(ctx context.Context, payloadID hexutil.Bytes) (*GetPayloadV2Response, error) {
+	decodedPayloadId := binary.BigEndian.Uint64(payloadID)
+	log.Info("Received GetPayloadV2", "payloadId", decodedPayloadId)
+
+	payload, withdrawals, blockValue, err := e.api.EngineGetPayloadV2(ctx, decodedPayloadId)
+	if err != nil {
+		return nil, err
+	}
+	executionPayload := convertPayloadFromRpc(payload)
+	executionPayload.Withdrawals = withdrawals
+	return &GetPayloadV2Response{
+		ExecutionPayload: executionPayload,
+		BlockValue:       (*hexutil.Big)(blockValue.ToBig()),
+	}, nil
+}
+
+func convertPayloadFromRpc(payload *types2.ExecutionPayload) *ExecutionPayload {
 	var bloom types.Bloom = gointerfaces.ConvertH2048ToBloom(payload.LogsBloom)
 
 	var baseFee *big.Int
@@ -235,7 +294,7 @@ func (e *EngineImpl) GetPayloadV1(ctx context.Context, payloadID hexutil.Bytes)
 		BaseFeePerGas: (*hexutil.Big)(baseFee),
 		BlockHash:     gointerfaces.ConvertH256ToHash(payload.BlockHash),
 		Transactions:  transactions,
-	}, nil
+	}
 }
 
 // Receives consensus layer's transition configuration and checks if the execution layer has the correct configuration.
@@ -36,6 +36,9 @@ type ErigonAPI interface {
 	// CumulativeChainTraffic / related to chain traffic (see ./erigon_cumulative_index.go)
 	CumulativeChainTraffic(ctx context.Context, blockNr rpc.BlockNumber) (ChainTraffic, error)
 
+	// Verkle related (see ./erigon_verkle.go)
+	GetVerkleRoot(ctx context.Context, blockNr rpc.BlockNumber) (*common.Hash, error)
+
 	// NodeInfo returns a collection of metadata known about the host.
 	NodeInfo(ctx context.Context) ([]p2p.NodeInfo, error)
 }
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ledgerwatch/erigon/cmd/verkle/verkletrie"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+// GetVerkleRoot implements erigon_getVerkleRoot. Returns the root of the verkle tree after the given block,
+// null if the verkle trie stage is disabled or hasn't reached the block yet.
+func (api *ErigonImpl) GetVerkleRoot(ctx context.Context, blockNr rpc.BlockNumber) (*common.Hash, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, _, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(blockNr), tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	// The verkle tables don't exist before the first run of the stage
+	progress, err := stages.GetStageProgress(tx, stages.VerkleTrie)
+	if err != nil {
+		return nil, err
+	}
+	if progress == 0 || blockNum > progress {
+		return nil, nil
+	}
+	root, err := verkletrie.ReadVerkleRoot(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
@@ -15,6 +15,14 @@ import (
 	"github.com/ledgerwatch/erigon/rpc"
 )
 
+// Accounts implements eth_accounts. Returns the addresses of the signing accounts of the node (see --keystore and --signer).
+func (api *APIImpl) Accounts(_ context.Context) ([]common.Address, error) {
+	if api.AccountManager == nil {
+		return []common.Address{}, nil
+	}
+	return api.AccountManager.Accounts(), nil
+}
+
 // GetBalance implements eth_getBalance. Returns the balance of an account for a given address.
 func (api *APIImpl) GetBalance(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
 	tx, err1 := api.db.BeginRo(ctx)
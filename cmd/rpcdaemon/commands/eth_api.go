@@ -13,6 +13,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
 	libstate "github.com/ledgerwatch/erigon-lib/state"
+	"github.com/ledgerwatch/erigon/accounts"
 	"github.com/ledgerwatch/erigon/cmd/state/exec22"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
@@ -20,6 +21,8 @@ import (
 	"github.com/ledgerwatch/erigon/consensus/misc"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
 	ethFilters "github.com/ledgerwatch/erigon/eth/filters"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
@@ -78,14 +81,17 @@ type EthAPI interface {
 	GasPrice(_ context.Context) (*hexutil.Big, error)
 
 	// Sending related (see ./eth_call.go)
-	Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides) (hexutil.Bytes, error)
-	EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error)
+	Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides, blockOverrides *ethapi.BlockOverrides) (hexutil.Bytes, error)
+	EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides) (hexutil.Uint64, error)
 	SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error)
-	SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
-	Sign(ctx context.Context, _ common.Address, _ hexutil.Bytes) (hexutil.Bytes, error)
-	SignTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
-	GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*interface{}, error)
-	CreateAccessList(ctx context.Context, args ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, optimizeGas *bool) (*accessListResult, error)
+	SendTransaction(ctx context.Context, args ethapi.CallArgs) (common.Hash, error)
+	Sign(ctx context.Context, address common.Address, data hexutil.Bytes) (hexutil.Bytes, error)
+	SignTransaction(ctx context.Context, args ethapi.CallArgs) (*SignTransactionResult, error)
+	GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*ethapi.AccountResult, error)
+	CreateAccessList(ctx context.Context, args ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, optimizeGas *bool, overrides *ethapi.StateOverrides) (*accessListResult, error)
+
+	// Simulation (see ./eth_simulate.go)
+	SimulateV1(ctx context.Context, opts SimulateOptions, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimulatedBlockResult, error)
 
 	// Mining related (see ./eth_mining.go)
 	Coinbase(ctx context.Context) (common.Address, error)
@@ -113,6 +119,8 @@ type BaseAPI struct {
 	_txNums      *exec22.TxNums
 
 	evmCallTimeout time.Duration
+
+	cairoRunner cairo.Runner // executes Starknet transactions when blocks are re-executed, nil if not configured
 }
 
 func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader services.FullBlockReader, agg *libstate.Aggregator22, txNums *exec22.TxNums, singleNodeMode bool, evmCallTimeout time.Duration) *BaseAPI {
@@ -128,6 +136,11 @@ func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader serv
 	return &BaseAPI{filters: f, stateCache: stateCache, blocksLRU: blocksLRU, _blockReader: blockReader, _txnReader: blockReader, _agg: agg, _txNums: txNums, evmCallTimeout: evmCallTimeout}
 }
 
+// vmConfig is the config to re-execute the transactions of blocks with
+func (api *BaseAPI) vmConfig() vm.Config {
+	return vm.Config{CairoRunner: api.cairoRunner}
+}
+
 func (api *BaseAPI) chainConfig(tx kv.Tx) (*params.ChainConfig, error) {
 	cfg, _, err := api.chainConfigWithGenesis(tx)
 	return cfg, err
@@ -265,6 +278,10 @@ type APIImpl struct {
 	mining     txpool.MiningClient
 	db         kv.RoDB
 	GasCap     uint64
+	// AccountManager signs eth_sendTransaction and eth_sign requests, nil if the node has no accounts
+	AccountManager *accounts.Manager
+	// InsecureUnlockAllowed lets the accounts sign requests over http and websocket, not only over IPC
+	InsecureUnlockAllowed bool
 }
 
 // NewEthAPI returns APIImpl instance
@@ -301,6 +318,8 @@ type RPCTransaction struct {
 	Type             hexutil.Uint64    `json:"type"`
 	Accesses         *types.AccessList `json:"accessList,omitempty"`
 	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	MaxFeePerBlobGas *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes       []common.Hash     `json:"blobVersionedHashes,omitempty"`
 	V                *hexutil.Big      `json:"v"`
 	R                *hexutil.Big      `json:"r"`
 	S                *hexutil.Big      `json:"s"`
@@ -355,6 +374,25 @@ func newRPCTransaction(tx types.Transaction, blockHash common.Hash, blockNumber
 		} else {
 			result.GasPrice = nil
 		}
+	case *types.BlobTx:
+		chainId = t.ChainID.ToBig()
+		result.ChainID = (*hexutil.Big)(chainId)
+		result.Tip = (*hexutil.Big)(t.Tip.ToBig())
+		result.FeeCap = (*hexutil.Big)(t.FeeCap.ToBig())
+		result.MaxFeePerBlobGas = (*hexutil.Big)(t.MaxFeePerBlobGas.ToBig())
+		result.BlobHashes = t.BlobVersionedHashes
+		result.V = (*hexutil.Big)(t.V.ToBig())
+		result.R = (*hexutil.Big)(t.R.ToBig())
+		result.S = (*hexutil.Big)(t.S.ToBig())
+		result.Accesses = &t.AccessList
+		baseFee, overflow := uint256.FromBig(baseFee)
+		if baseFee != nil && !overflow && blockHash != (common.Hash{}) {
+			// price = min(tip + baseFee, gasFeeCap)
+			price := math.Min256(new(uint256.Int).Add(tx.GetTip(), baseFee), tx.GetFeeCap())
+			result.GasPrice = (*hexutil.Big)(price.ToBig())
+		} else {
+			result.GasPrice = nil
+		}
 	}
 	signer := types.LatestSignerForChainID(chainId)
 	result.From, _ = tx.Sender(*signer)
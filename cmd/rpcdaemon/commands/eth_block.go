@@ -148,11 +148,13 @@ func (api *APIImpl) CallBundle(ctx context.Context, txHashes []common.Hash, stat
 	results := []map[string]interface{}{}
 
 	bundleHash := sha3.NewLegacyKeccak256()
-	for _, txn := range txs {
+	for i, txn := range txs {
 		msg, err := txn.AsMessage(*signer, nil, rules)
 		if err != nil {
 			return nil, err
 		}
+		// Prepare clears the access list and the transient storage (EIP-1153) of the previous transaction
+		st.Prepare(txn.Hash(), common.Hash{}, i)
 		// Execute the transaction message
 		result, err := core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */)
 		if err != nil {
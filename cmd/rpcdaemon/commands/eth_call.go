@@ -5,31 +5,40 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
 
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	txpool_proto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
 	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/ledgerwatch/log/v3"
 	"google.golang.org/grpc"
 
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/eth/tracers/logger"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/node/nodecfg/datadir"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/transactions"
+	"github.com/ledgerwatch/erigon/turbo/trie"
 )
 
 // Call implements eth_call. Executes a new message call immediately without creating a transaction on the block chain.
-func (api *APIImpl) Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides) (hexutil.Bytes, error) {
+// The state and the block context of the call can be overridden.
+func (api *APIImpl) Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides, blockOverrides *ethapi.BlockOverrides) (hexutil.Bytes, error) {
 	tx, err := api.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -61,7 +70,7 @@ func (api *APIImpl) Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHas
 	if err != nil {
 		return nil, err
 	}
-	result, err := transactions.DoCall(ctx, args, tx, blockNrOrHash, block, overrides, api.GasCap, chainConfig, stateReader, api._blockReader, api.evmCallTimeout)
+	result, err := transactions.DoCall(ctx, args, tx, blockNrOrHash, block, overrides, blockOverrides, api.GasCap, chainConfig, stateReader, api._blockReader, api.evmCallTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +98,7 @@ func headerByNumberOrHash(ctx context.Context, tx kv.Tx, blockNrOrHash rpc.Block
 }
 
 // EstimateGas implements eth_estimateGas. Returns an estimate of how much gas is necessary to allow the transaction to complete. The transaction will not be added to the blockchain.
-func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides) (hexutil.Uint64, error) {
 	var args ethapi.CallArgs
 	// if we actually get CallArgs here, we use them
 	if argsOrNil != nil {
@@ -167,6 +176,11 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs,
 		if state == nil {
 			return 0, fmt.Errorf("can't get the current state")
 		}
+		if overrides != nil {
+			if err := overrides.Override(state); err != nil {
+				return 0, err
+			}
+		}
 
 		balance := state.GetBalance(*args.From) // from can't be nil
 		available := balance.ToBig()
@@ -224,7 +238,7 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs,
 		if err != nil {
 			return false, nil, err
 		}
-		result, err := transactions.DoCall(ctx, args, dbtx, numOrHash, block, nil,
+		result, err := transactions.DoCall(ctx, args, dbtx, numOrHash, block, overrides, nil,
 			api.GasCap, chainConfig, stateReader, api._blockReader, api.evmCallTimeout)
 		if err != nil {
 			if errors.Is(err, core.ErrIntrinsicGas) {
@@ -274,10 +288,119 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs,
 	return hexutil.Uint64(hi), nil
 }
 
-// GetProof not implemented
-func (api *APIImpl) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*interface{}, error) {
-	var stub interface{}
-	return &stub, fmt.Errorf(NotImplemented, "eth_getProof")
+// maxRewindBlockCount - eth_getProof and debug_getBlockWitness for historical blocks rewind HashedState and
+// IntermediateHashes in memory, so the amount of blocks to rewind has to be limited
+const maxRewindBlockCount = 1_000
+
+// GetProof implements eth_getProof (EIP-1186). Returns the account and storage values of the specified account including the Merkle-proof.
+func (api *APIImpl) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*ethapi.AccountResult, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNr, _, _, err := rpchelper.GetCanonicalBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	header, err := api._blockReader.HeaderByNumber(ctx, tx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header not found for block %d", blockNr)
+	}
+	stateTx, rollback, err := api.hashedStateAt(ctx, tx, header, "eth_getProof")
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	addrHash, err := common.HashData(address[:])
+	if err != nil {
+		return nil, err
+	}
+	var incarnation uint64
+	enc, err := stateTx.GetOne(kv.HashedAccounts, addrHash[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) > 0 {
+		if incarnation, err = accounts.DecodeIncarnationFromStorage(enc); err != nil {
+			return nil, err
+		}
+	}
+
+	rl := trie.NewRetainList(0)
+	rl.AddKey(addrHash[:])
+	keyHashes := make([]common.Hash, len(storageKeys))
+	for i, key := range storageKeys {
+		if keyHashes[i], err = common.HashData(common.HexToHash(key).Bytes()); err != nil {
+			return nil, err
+		}
+		if incarnation > 0 {
+			rl.AddKey(dbutils.GenerateCompositeStorageKey(addrHash, incarnation, keyHashes[i]))
+		}
+	}
+
+	loader := trie.NewFlatDBTrieLoader("eth_getProof")
+	if err = loader.Reset(rl, nil, nil, false); err != nil {
+		return nil, err
+	}
+	subTries, err := loader.CalcSubTrie(stateTx, ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+	if subTries.Hashes[0] != header.Root {
+		return nil, fmt.Errorf("mismatch in expected state root: computed %x, header %x", subTries.Hashes[0], header.Root)
+	}
+	tr := trie.New(header.Root)
+	if header.Root != trie.EmptyRoot {
+		if err = tr.HookSubTries(subTries, [][]byte{nil}); err != nil {
+			return nil, err
+		}
+	}
+
+	accountProof, err := tr.Prove(addrHash[:], 0, false)
+	if err != nil {
+		return nil, err
+	}
+	result := &ethapi.AccountResult{
+		Address:      address,
+		AccountProof: toHexSlice(accountProof),
+		Balance:      (*hexutil.Big)(new(big.Int)),
+		CodeHash:     trie.EmptyCodeHash,
+		StorageHash:  trie.EmptyRoot,
+		StorageProof: make([]ethapi.StorageResult, len(storageKeys)),
+	}
+	if acc, _ := tr.GetAccount(addrHash[:]); acc != nil {
+		result.Balance = (*hexutil.Big)(acc.Balance.ToBig())
+		result.Nonce = hexutil.Uint64(acc.Nonce)
+		result.CodeHash = acc.CodeHash
+		result.StorageHash = acc.Root
+	}
+	for i, key := range storageKeys {
+		trieKey := append(common.CopyBytes(addrHash[:]), keyHashes[i][:]...)
+		proof, err := tr.Prove(trieKey, 2*common.HashLength, true)
+		if err != nil {
+			return nil, err
+		}
+		value := new(big.Int)
+		if v, ok := tr.Get(trieKey); ok {
+			value.SetBytes(v)
+		}
+		result.StorageProof[i] = ethapi.StorageResult{Key: key, Value: (*hexutil.Big)(value), Proof: toHexSlice(proof)}
+	}
+	return result, nil
+}
+
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
 }
 
 // accessListResult returns an optional accesslist
@@ -292,7 +415,7 @@ type accessListResult struct {
 // CreateAccessList implements eth_createAccessList. It creates an access list for the given transaction.
 // If the accesslist creation fails an error is returned.
 // If the transaction itself fails, an vmErr is returned.
-func (api *APIImpl) CreateAccessList(ctx context.Context, args ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, optimizeGas *bool) (*accessListResult, error) {
+func (api *APIImpl) CreateAccessList(ctx context.Context, args ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, optimizeGas *bool, overrides *ethapi.StateOverrides) (*accessListResult, error) {
 	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
 	if blockNrOrHash != nil {
 		bNrOrHash = *blockNrOrHash
@@ -366,6 +489,11 @@ func (api *APIImpl) CreateAccessList(ctx context.Context, args ethapi.CallArgs,
 	}
 	for {
 		state := state.New(stateReader)
+		if overrides != nil {
+			if err := overrides.Override(state); err != nil {
+				return nil, err
+			}
+		}
 		// Retrieve the current access list to expand
 		accessList := prevTracer.AccessList()
 		log.Trace("Creating access list", "input", accessList)
@@ -446,3 +574,35 @@ func optimizeToInAccessList(accessList *accessListResult, to common.Address) {
 func removeIndex(s types.AccessList, index int) types.AccessList {
 	return append(s[:index], s[index+1:]...)
 }
+
+// hashedStateAt returns a transaction in which HashedState and IntermediateHashes correspond to the state after
+// the given block. They are consistent with the progress of IntermediateHashes stage, so for older blocks they
+// are rewound in memory. The returned function has to be called to release the rewound state.
+func (api *BaseAPI) hashedStateAt(ctx context.Context, tx kv.Tx, header *types.Header, logPrefix string) (kv.Tx, func(), error) {
+	blockNr := header.Number.Uint64()
+	latestBlock, err := stages.GetStageProgress(tx, stages.IntermediateHashes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blockNr > latestBlock {
+		return nil, nil, fmt.Errorf("block %d is not yet available for proofs, state root is calculated up to block %d", blockNr, latestBlock)
+	}
+	if blockNr == latestBlock {
+		return tx, func() {}, nil
+	}
+	if latestBlock-blockNr > maxRewindBlockCount {
+		return nil, nil, fmt.Errorf("requested block is too old, block must be within %d blocks of the head block number (currently %d)", maxRewindBlockCount, latestBlock)
+	}
+	if api.historyV2(tx) {
+		return nil, nil, fmt.Errorf(NotImplemented, logPrefix+" for historical blocks with history.v2")
+	}
+	batch := memdb.NewMemoryBatch(tx)
+	dirs := datadir.Dirs{Tmp: os.TempDir()}
+	hashStateCfg := stagedsync.StageHashStateCfg(nil, dirs, false, nil, nil)
+	trieCfg := stagedsync.StageTrieCfg(nil, false, false, false, dirs.Tmp, api._blockReader, nil, false, nil, nil)
+	if err = stagedsync.RewindHashedStateAndIntermediateHashes(logPrefix, batch, latestBlock, blockNr, hashStateCfg, trieCfg, header.Root, ctx.Done()); err != nil {
+		batch.Rollback()
+		return nil, nil, err
+	}
+	return batch, batch.Rollback, nil
+}
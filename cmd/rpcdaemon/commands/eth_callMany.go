@@ -206,13 +206,15 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 	// Setup the gas pool (also for unmetered requests)
 	// and apply the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
-	for _, txn := range replayTransactions {
+	for i, txn := range replayTransactions {
 		msg, err := txn.AsMessage(*signer, nil, rules)
 		if err != nil {
 			return nil, err
 		}
 		txCtx = core.NewEVMTxContext(msg)
 		evm = vm.NewEVM(blockCtx, txCtx, evm.IntraBlockState(), chainConfig, vm.Config{Debug: false})
+		// Prepare clears the access list and the transient storage (EIP-1153) of the previous transaction
+		st.Prepare(txn.Hash(), block.Hash(), i)
 		// Execute the transaction message
 		_, err = core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */)
 		if err != nil {
@@ -234,6 +236,7 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 	}
 
 	ret := make([][]map[string]interface{}, 0)
+	txIndex := len(replayTransactions)
 
 	for _, bundle := range bundles {
 		// first change blockContext
@@ -271,6 +274,8 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 			}
 			txCtx = core.NewEVMTxContext(msg)
 			evm = vm.NewEVM(blockCtx, txCtx, evm.IntraBlockState(), chainConfig, vm.Config{Debug: false})
+			st.Prepare(common.Hash{}, common.Hash{}, txIndex)
+			txIndex++
 			result, err := core.ApplyMessage(evm, msg, gp, true, false)
 			if err != nil {
 				return nil, err
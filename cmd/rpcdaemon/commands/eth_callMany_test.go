@@ -8,8 +8,10 @@ import (
 	"github.com/ledgerwatch/erigon/accounts/abi/bind"
 	"github.com/ledgerwatch/erigon/accounts/abi/bind/backends"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands/contracts"
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
@@ -163,3 +165,46 @@ func TestCallMany(t *testing.T) {
 		t.Errorf("eth_callMany: %s", "balanceUnmatch")
 	}
 }
+
+// TestCallManyTransientStorage checks that transient storage (EIP-1153) is cleared between the calls of a bundle
+func TestCallManyTransientStorage(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		config   = *params.AllEthashProtocolChanges
+	)
+	config.LondonBlock = common.Big0
+	config.ShanghaiBlock = common.Big0
+	config.CancunBlock = common.Big0
+	// returns tload(0), then tstore(0, 1)
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.TLOAD), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.TSTORE),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	alloc := core.GenesisAlloc{
+		address:  {Balance: big.NewInt(params.Ether)},
+		contract: {Code: code, Balance: big.NewInt(0)},
+	}
+	contractBackend := backends.NewSimulatedBackendWithConfig(alloc, &config, 10000000)
+	defer contractBackend.Close()
+	contractBackend.Commit()
+
+	api := NewEthAPI(NewBaseApi(nil, kvcache.New(kvcache.DefaultCoherentConfig), snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), contractBackend.DB(), nil, nil, nil, 5000000)
+	call := ethapi.CallArgs{From: &address, To: &contract,
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1e9)),
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(1e10)),
+	}
+	timeout := int64(50000)
+	res, err := api.CallMany(context.Background(), []Bundle{{Transactions: []ethapi.CallArgs{call, call}}}, StateContext{BlockNumber: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)}, nil, &timeout)
+	if err != nil {
+		t.Fatalf("eth_callMany: %v", err)
+	}
+	zero := hex.EncodeToString(make([]byte, 32))
+	for i, result := range res[0] {
+		if result["value"] != zero {
+			t.Errorf("eth_callMany: call %d saw transient storage of the previous call: %v", i, result)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/ledgerwatch/erigon/rpc/rpccfg"
@@ -22,13 +23,16 @@ import (
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
 	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/ledgerwatch/erigon/turbo/trie"
 )
 
 func TestEstimateGas(t *testing.T) {
@@ -43,11 +47,63 @@ func TestEstimateGas(t *testing.T) {
 	if _, err := api.EstimateGas(context.Background(), &ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, nil); err != nil {
+	}, nil, nil); err != nil {
 		t.Errorf("calling EstimateGas: %v", err)
 	}
 }
 
+func TestEstimateGasWithStateOverrides(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+	// the account has no funds to pay for the gas
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	args := ethapi.CallArgs{From: &from, To: &to, GasPrice: (*hexutil.Big)(big.NewInt(params.GWei))}
+	_, err := api.EstimateGas(context.Background(), &args, &latest, nil)
+	assert.Error(t, err)
+
+	balance := (*hexutil.Big)(big.NewInt(params.Ether))
+	gas, err := api.EstimateGas(context.Background(), &args, &latest, &ethapi.StateOverrides{from: ethapi.Account{Balance: &balance}})
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), gas)
+}
+
+func TestEthCallWithBlockOverrides(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+	from := common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	// NUMBER PUSH1 0 MSTORE TIMESTAMP PUSH1 32 MSTORE COINBASE PUSH1 64 MSTORE PUSH1 96 PUSH1 0 RETURN
+	code := hexutil.Bytes(hexutil.MustDecode("0x43600052426020524160405260606000f3"))
+	overrides := &ethapi.StateOverrides{to: ethapi.Account{Code: &code}}
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	tx, err := db.BeginRo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	header := rawdb.ReadCurrentHeader(tx)
+
+	result, err := api.Call(context.Background(), ethapi.CallArgs{From: &from, To: &to}, latest, overrides, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, header.Number.Uint64(), new(big.Int).SetBytes(result[:32]).Uint64())
+	assert.Equal(t, header.Time, new(big.Int).SetBytes(result[32:64]).Uint64())
+
+	number := (*hexutil.Big)(new(big.Int).Add(header.Number, big.NewInt(1)))
+	timestamp := hexutil.Uint64(header.Time + 12)
+	coinbase := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	result, err = api.Call(context.Background(), ethapi.CallArgs{From: &from, To: &to}, latest, overrides,
+		&ethapi.BlockOverrides{Number: number, Time: &timestamp, Coinbase: &coinbase})
+	assert.NoError(t, err)
+	assert.Equal(t, number.ToInt().Uint64(), new(big.Int).SetBytes(result[:32]).Uint64())
+	assert.Equal(t, uint64(timestamp), new(big.Int).SetBytes(result[32:64]).Uint64())
+	assert.Equal(t, coinbase, common.BytesToAddress(result[64:96]))
+}
+
 func TestEthCallNonCanonical(t *testing.T) {
 	db := rpcdaemontest.CreateTestKV(t)
 	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
@@ -57,7 +113,7 @@ func TestEthCallNonCanonical(t *testing.T) {
 	if _, err := api.Call(context.Background(), ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, rpc.BlockNumberOrHashWithHash(common.HexToHash("0x3fcb7c0d4569fddc89cbea54b42f163e0c789351d98810a513895ab44b47020b"), true), nil); err != nil {
+	}, rpc.BlockNumberOrHashWithHash(common.HexToHash("0x3fcb7c0d4569fddc89cbea54b42f163e0c789351d98810a513895ab44b47020b"), true), nil, nil); err != nil {
 		if fmt.Sprintf("%v", err) != "hash 3fcb7c0d4569fddc89cbea54b42f163e0c789351d98810a513895ab44b47020b is not currently canonical" {
 			t.Errorf("wrong error: %v", err)
 		}
@@ -82,11 +138,225 @@ func TestEthCallToPrunedBlock(t *testing.T) {
 		From: &bankAddress,
 		To:   &contractAddress,
 		Data: &callDataBytes,
-	}, rpc.BlockNumberOrHashWithNumber(ethCallBlockNumber), nil); err != nil {
+	}, rpc.BlockNumberOrHashWithNumber(ethCallBlockNumber), nil, nil); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
 
+func TestGetProof(t *testing.T) {
+	db, bankAddress, contractAddress := chainWithDeployedContract(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+
+	tx, err := db.BeginRo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	for _, blockNr := range []rpc.BlockNumber{1, 2, rpc.LatestBlockNumber} {
+		for _, addr := range []common.Address{bankAddress, contractAddress, common.HexToAddress("0xdeadbeef")} {
+			proof, err := api.GetProof(context.Background(), addr, []string{"0x0"}, rpc.BlockNumberOrHashWithNumber(blockNr))
+			if err != nil {
+				t.Fatalf("block %d, address %x: %v", blockNr, addr, err)
+			}
+			header, err := api.headerByRPCNumber(blockNr, tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertProofChain(t, header.Root, proof.AccountProof)
+			assert.Equal(t, common.HexToHash("0x0"), common.BytesToHash(proof.StorageProof[0].Value.ToInt().Bytes()))
+
+			balance, err := api.GetBalance(context.Background(), addr, rpc.BlockNumberOrHashWithNumber(blockNr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, balance.ToInt().String(), proof.Balance.ToInt().String())
+			if len(proof.StorageProof[0].Proof) > 0 {
+				assertProofChain(t, proof.StorageHash, proof.StorageProof[0].Proof)
+			}
+		}
+	}
+}
+
+func TestGetProofRecreatedContract(t *testing.T) {
+	db, contractAddress := chainWithRecreatedContract(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+
+	tx, err := db.BeginRo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	acc, err := state.NewPlainStateReader(tx).ReadAccountData(contractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), acc.Incarnation)
+
+	storageKeys := make([]string, 18)
+	for i := range storageKeys {
+		storageKeys[i] = hexutil.EncodeUint64(uint64(i))
+	}
+	for blockNr, createdAt := range map[rpc.BlockNumber]int64{1: 1, rpc.LatestBlockNumber: 3} {
+		proof, err := api.GetProof(context.Background(), contractAddress, storageKeys, rpc.BlockNumberOrHashWithNumber(blockNr))
+		if err != nil {
+			t.Fatalf("block %d: %v", blockNr, err)
+		}
+		header, err := api.headerByRPCNumber(blockNr, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrHash := crypto.Keccak256(contractAddress[:])
+		accountRLP := verifyProof(t, header.Root, addrHash, proof.AccountProof)
+		var account struct {
+			Nonce    uint64
+			Balance  *big.Int
+			Root     common.Hash
+			CodeHash common.Hash
+		}
+		if err = rlp.DecodeBytes(accountRLP, &account); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, account.Root, proof.StorageHash)
+		assert.NotEqual(t, trie.EmptyRoot, proof.StorageHash)
+
+		for i, storageProof := range proof.StorageProof {
+			var expected int64
+			switch {
+			case i == 0:
+				expected = createdAt
+			case i <= 16:
+				expected = int64(i) + 6
+			}
+			assert.Equal(t, expected, storageProof.Value.ToInt().Int64(), "block %d, slot %d", blockNr, i)
+			value := verifyProof(t, proof.StorageHash, crypto.Keccak256(common.BigToHash(big.NewInt(int64(i))).Bytes()), storageProof.Proof)
+			if expected == 0 {
+				assert.Nil(t, value)
+				continue
+			}
+			content, _, err := rlp.SplitString(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, expected, new(big.Int).SetBytes(content).Int64())
+		}
+	}
+}
+
+// verifyProof walks the proof from the root along the key and returns the value of the leaf, or nil if the proof shows
+// that the key is absent
+func verifyProof(t *testing.T, root common.Hash, key []byte, proof []string) []byte {
+	t.Helper()
+	nibbles := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		nibbles = append(nibbles, b/16, b%16)
+	}
+	expected := root[:]
+	for i, encoded := range proof {
+		node := hexutil.MustDecode(encoded)
+		if len(expected) == common.HashLength && (i == 0 || len(node) >= common.HashLength) {
+			if !bytes.Equal(crypto.Keccak256(node), expected) {
+				t.Fatalf("proof node %d with hash %x is not referenced by the previous node", i, crypto.Keccak256(node))
+			}
+		} else if !bytes.Equal(node, expected) {
+			t.Fatalf("embedded proof node %d %x doesn't match the previous node", i, node)
+		}
+		elems, _, err := rlp.SplitList(node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, err := rlp.CountValues(elems)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var child []byte
+		switch count {
+		case 17:
+			for j := byte(0); j <= nibbles[0]; j++ {
+				if child, elems, err = splitRaw(elems); err != nil {
+					t.Fatal(err)
+				}
+			}
+			nibbles = nibbles[1:]
+		case 2:
+			compact, rest, err := rlp.SplitString(elems)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if child, _, err = splitRaw(rest); err != nil {
+				t.Fatal(err)
+			}
+			path := make([]byte, 0, 2*len(compact))
+			if compact[0]&0x10 != 0 { // odd length
+				path = append(path, compact[0]&0x0f)
+			}
+			for _, b := range compact[1:] {
+				path = append(path, b/16, b%16)
+			}
+			if !bytes.HasPrefix(nibbles, path) {
+				return nil
+			}
+			nibbles = nibbles[len(path):]
+			if compact[0]&0x20 != 0 { // leaf
+				if len(nibbles) != 0 || i != len(proof)-1 {
+					t.Fatalf("leaf in the middle of the proof, node %d", i)
+				}
+				content, _, err := rlp.SplitString(child)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return content
+			}
+		default:
+			t.Fatalf("unexpected number of elements %d in proof node %d", count, i)
+		}
+		if kind, content, _, _ := rlp.Split(child); kind == rlp.String {
+			if len(content) == 0 {
+				if i != len(proof)-1 {
+					t.Fatalf("empty child in the middle of the proof, node %d", i)
+				}
+				return nil
+			}
+			expected = content
+		} else {
+			expected = child
+		}
+	}
+	t.Fatal("proof ends before the leaf")
+	return nil
+}
+
+// splitRaw returns the first RLP item of b with its header
+func splitRaw(b []byte) (item, rest []byte, err error) {
+	_, _, rest, err = rlp.Split(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b[:len(b)-len(rest)], rest, nil
+}
+
+// assertProofChain checks that the proof starts with the node of the given root and that every next node is referenced by the previous one
+func assertProofChain(t *testing.T, root common.Hash, proof []string) {
+	t.Helper()
+	if len(proof) == 0 {
+		t.Fatal("empty proof")
+	}
+	expected := root[:]
+	for i, encoded := range proof {
+		node := hexutil.MustDecode(encoded)
+		if i > 0 && len(node) < 32 { // embedded node
+			continue
+		}
+		hash := crypto.Keccak256(node)
+		if !bytes.Contains(expected, hash) {
+			t.Fatalf("proof node %d with hash %x is not referenced by the previous node", i, hash)
+		}
+		expected = node
+	}
+}
+
 func TestGetBlockByTimestampLatestTime(t *testing.T) {
 	ctx := context.Background()
 	db := rpcdaemontest.CreateTestKV(t)
@@ -354,6 +624,58 @@ func chainWithDeployedContract(t *testing.T) (kv.RwDB, common.Address, common.Ad
 	return db, bankAddress, contractAddr
 }
 
+// chainWithRecreatedContract deploys a contract by CREATE2 from a factory in block 1, destructs it in block 2 and deploys it
+// again in block 3, so its storage has incarnation 2. Slot 0 of the contract is the number of the block which created it,
+// slots 1..16 are 7..22
+func chainWithRecreatedContract(t *testing.T) (kv.RwDB, common.Address) {
+	// NUMBER PUSH1 0 SSTORE; PUSH1 k+6 PUSH1 k SSTORE...; returns CALLER SELFDESTRUCT
+	initCode := []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.SSTORE)}
+	for k := byte(1); k <= 16; k++ {
+		initCode = append(initCode, byte(vm.PUSH1), k+6, byte(vm.PUSH1), k, byte(vm.SSTORE))
+	}
+	initCode = append(initCode, byte(vm.PUSH2), byte(vm.CALLER), byte(vm.SELFDESTRUCT), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 2, byte(vm.PUSH1), 30, byte(vm.RETURN))
+	// copies initCode, which follows these instructions, to memory and CREATE2 it with salt 0
+	factoryCode := []byte{
+		byte(vm.PUSH1), byte(len(initCode)), byte(vm.PUSH1), 0 /* offset of initCode */, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), byte(len(initCode)), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CREATE2), byte(vm.STOP),
+	}
+	factoryCode[3] = byte(len(factoryCode))
+	factoryCode = append(factoryCode, initCode...)
+	var (
+		signer         = types.LatestSignerForChainID(nil)
+		bankKey, _     = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		bankAddress    = crypto.PubkeyToAddress(bankKey.PublicKey)
+		factoryAddress = common.HexToAddress("0xfac7")
+		gspec          = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: core.GenesisAlloc{
+				bankAddress:    {Balance: big.NewInt(1e9)},
+				factoryAddress: {Code: factoryCode, Balance: new(big.Int)},
+			},
+		}
+	)
+	m := stages.MockWithGenesis(t, gspec, bankKey, false)
+	contractAddr := crypto.CreateAddress2(factoryAddress, common.Hash{}, crypto.Keccak256(initCode))
+
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, block *core.BlockGen) {
+		to := factoryAddress
+		if i == 1 {
+			to = contractAddr
+		}
+		txn, err := types.SignTx(types.NewTransaction(block.TxNonce(bankAddress), to, new(uint256.Int), 1000000, new(uint256.Int), nil), *signer, bankKey)
+		assert.NoError(t, err)
+		block.AddTx(txn)
+	}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatalf("generate blocks: %v", err)
+	}
+	if err = m.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	return m.DB, contractAddr
+}
+
 func prune(t *testing.T, db kv.RwDB, pruneTo uint64) {
 	ctx := context.Background()
 	tx, err := db.BeginRw(ctx)
@@ -2,9 +2,14 @@ package commands
 
 import (
 	"context"
+	"math/big"
+	"time"
 
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/filters"
 	"github.com/ledgerwatch/erigon/rpc"
@@ -13,10 +18,18 @@ import (
 )
 
 // NewPendingTransactionFilter new transaction filter
-func (api *APIImpl) NewPendingTransactionFilter(_ context.Context) (string, error) {
+func (api *APIImpl) NewPendingTransactionFilter(ctx context.Context) (string, error) {
 	if api.filters == nil {
 		return "", rpc.ErrNotificationsUnsupported
 	}
+	if store := api.filters.Store(); store != nil {
+		id, err := store.Add(ctx, &rpchelper.StoredFilter{Kind: rpchelper.PendingTxsFilterKind})
+		if err != nil {
+			return "", err
+		}
+		api.subscribePendingTxsFilter(rpchelper.PendingTxsSubID(id))
+		return "0x" + string(id), nil
+	}
 	txsCh := make(chan []types.Transaction, 1)
 	id := api.filters.SubscribePendingTxs(txsCh)
 	go func() {
@@ -27,11 +40,26 @@ func (api *APIImpl) NewPendingTransactionFilter(_ context.Context) (string, erro
 	return "0x" + string(id), nil
 }
 
+func (api *APIImpl) subscribePendingTxsFilter(id rpchelper.PendingTxsSubID) {
+	txsCh := make(chan []types.Transaction, 1)
+	if !api.filters.SubscribePendingTxsWithID(id, txsCh) {
+		return
+	}
+	go func() {
+		for txs := range txsCh {
+			api.filters.AddPendingTxs(id, txs)
+		}
+	}()
+}
+
 // NewBlockFilter implements eth_newBlockFilter. Creates a filter in the node, to notify when a new block arrives.
-func (api *APIImpl) NewBlockFilter(_ context.Context) (string, error) {
+func (api *APIImpl) NewBlockFilter(ctx context.Context) (string, error) {
 	if api.filters == nil {
 		return "", rpc.ErrNotificationsUnsupported
 	}
+	if api.filters.Store() != nil {
+		return api.newStoredFilter(ctx, &rpchelper.StoredFilter{Kind: rpchelper.BlocksFilterKind})
+	}
 	ch := make(chan *types.Header, 1)
 	id := api.filters.SubscribeNewHeads(ch)
 	go func() {
@@ -43,10 +71,23 @@ func (api *APIImpl) NewBlockFilter(_ context.Context) (string, error) {
 }
 
 // NewFilter implements eth_newFilter. Creates an arbitrary filter object, based on filter options, to notify when the state changes (logs).
-func (api *APIImpl) NewFilter(_ context.Context, crit filters.FilterCriteria) (string, error) {
+func (api *APIImpl) NewFilter(ctx context.Context, crit filters.FilterCriteria) (string, error) {
 	if api.filters == nil {
 		return "", rpc.ErrNotificationsUnsupported
 	}
+	if api.filters.Store() != nil {
+		f := &rpchelper.StoredFilter{Kind: rpchelper.LogsFilterKind, Addresses: crit.Addresses, Topics: crit.Topics, BlockHash: crit.BlockHash}
+		// negative numbers are "latest" and "pending", which don't bound the polled blocks
+		if crit.FromBlock != nil && crit.FromBlock.Sign() >= 0 {
+			from := crit.FromBlock.Uint64()
+			f.FromBlock = &from
+		}
+		if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 {
+			to := crit.ToBlock.Uint64()
+			f.ToBlock = &to
+		}
+		return api.newStoredFilter(ctx, f)
+	}
 	logs := make(chan *types.Log, 1)
 	id := api.filters.SubscribeLogs(logs, crit)
 	go func() {
@@ -57,8 +98,28 @@ func (api *APIImpl) NewFilter(_ context.Context, crit filters.FilterCriteria) (s
 	return hexutil.EncodeUint64(uint64(id)), nil
 }
 
+// newStoredFilter - logs and blocks of stored filters are read from the chain, starting after the latest block
+func (api *APIImpl) newStoredFilter(ctx context.Context, f *rpchelper.StoredFilter) (string, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	if f.Block, err = rpchelper.GetLatestBlockNumber(tx); err != nil {
+		return "", err
+	}
+	if f.Hash, err = rawdb.ReadCanonicalHash(tx, f.Block); err != nil {
+		return "", err
+	}
+	id, err := api.filters.Store().Add(ctx, f)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + string(id), nil
+}
+
 // UninstallFilter new transaction filter
-func (api *APIImpl) UninstallFilter(_ context.Context, index string) (bool, error) {
+func (api *APIImpl) UninstallFilter(ctx context.Context, index string) (bool, error) {
 	if api.filters == nil {
 		return false, rpc.ErrNotificationsUnsupported
 	}
@@ -68,6 +129,16 @@ func (api *APIImpl) UninstallFilter(_ context.Context, index string) (bool, erro
 	if len(index) >= 2 && index[0] == '0' && (index[1] == 'x' || index[1] == 'X') {
 		cutIndex = index[2:]
 	}
+	if store := api.filters.Store(); store != nil {
+		deleted, err := store.Delete(ctx, rpchelper.SubscriptionID(cutIndex))
+		if err != nil {
+			return false, err
+		}
+		if deleted {
+			api.filters.UnsubscribePendingTxs(rpchelper.PendingTxsSubID(cutIndex))
+			return true, nil
+		}
+	}
 	isDeleted = api.filters.UnsubscribeHeads(rpchelper.HeadsSubID(cutIndex)) ||
 		api.filters.UnsubscribePendingTxs(rpchelper.PendingTxsSubID(cutIndex))
 	id, err := hexutil.DecodeUint64(index)
@@ -79,7 +150,7 @@ func (api *APIImpl) UninstallFilter(_ context.Context, index string) (bool, erro
 }
 
 // GetFilterChanges implements eth_getFilterChanges. Polling method for a previously-created filter, which returns an array of logs which occurred since last poll.
-func (api *APIImpl) GetFilterChanges(_ context.Context, index string) ([]interface{}, error) {
+func (api *APIImpl) GetFilterChanges(ctx context.Context, index string) ([]interface{}, error) {
 	if api.filters == nil {
 		return nil, rpc.ErrNotificationsUnsupported
 	}
@@ -90,6 +161,25 @@ func (api *APIImpl) GetFilterChanges(_ context.Context, index string) ([]interfa
 	if len(index) >= 2 && index[0] == '0' && (index[1] == 'x' || index[1] == 'X') {
 		cutIndex = index[2:]
 	}
+	if store := api.filters.Store(); store != nil {
+		unlock := store.Lock(rpchelper.SubscriptionID(cutIndex))
+		defer unlock()
+		f, err := store.Get(ctx, rpchelper.SubscriptionID(cutIndex))
+		if err != nil {
+			return nil, err
+		}
+		if f != nil && f.Kind != rpchelper.PendingTxsFilterKind {
+			return api.storedFilterChanges(ctx, rpchelper.SubscriptionID(cutIndex), f)
+		}
+		if f != nil {
+			// transactions which came while rpcdaemon was down are lost, deliver new ones from now on
+			api.subscribePendingTxsFilter(rpchelper.PendingTxsSubID(cutIndex))
+			f.LastPoll = time.Now().Unix()
+			if err = store.Put(ctx, rpchelper.SubscriptionID(cutIndex), f); err != nil {
+				return nil, err
+			}
+		}
+	}
 	if blocks, ok := api.filters.ReadPendingBlocks(rpchelper.HeadsSubID(cutIndex)); ok {
 		for _, v := range blocks {
 			stub = append(stub, v.Hash())
@@ -118,6 +208,119 @@ func (api *APIImpl) GetFilterChanges(_ context.Context, index string) ([]interfa
 	return stub, nil
 }
 
+// maxFilterChangesBlocks - how many blocks a stored filter goes through per eth_getFilterChanges, the next poll
+// continues after the last of them
+var maxFilterChangesBlocks uint64 = 1000
+
+// storedFilterChanges returns logs or block hashes of up to maxFilterChangesBlocks blocks after the last delivered one,
+// logs are found by the log index like in eth_getLogs. Blocks replaced by a reorg are delivered again.
+func (api *APIImpl) storedFilterChanges(ctx context.Context, id rpchelper.SubscriptionID, f *rpchelper.StoredFilter) ([]interface{}, error) {
+	stub := make([]interface{}, 0)
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	latest, err := rpchelper.GetLatestBlockNumber(tx)
+	if err != nil {
+		return nil, err
+	}
+	from, err := storedFilterForkPoint(tx, f)
+	if err != nil {
+		return nil, err
+	}
+	if from > latest { // the chain was unwound below the last delivered block
+		from = latest
+	}
+	from++
+	last := latest
+	if latest >= from && latest-from >= maxFilterChangesBlocks {
+		last = from + maxFilterChangesBlocks - 1
+	}
+	lastHash, err := rawdb.ReadCanonicalHash(tx, last)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Kind {
+	case rpchelper.BlocksFilterKind:
+		for blockNum := from; blockNum <= last; blockNum++ {
+			hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+			if err != nil {
+				return nil, err
+			}
+			stub = append(stub, hash)
+		}
+	case rpchelper.LogsFilterKind:
+		to := last
+		if f.FromBlock != nil && *f.FromBlock > from {
+			from = *f.FromBlock
+		}
+		if f.ToBlock != nil && *f.ToBlock < to {
+			to = *f.ToBlock
+		}
+		if f.BlockHash != nil {
+			header, err := api._blockReader.HeaderByHash(ctx, tx, *f.BlockHash)
+			if err != nil {
+				return nil, err
+			}
+			var canonical common.Hash
+			if header != nil {
+				if canonical, err = rawdb.ReadCanonicalHash(tx, header.Number.Uint64()); err != nil {
+					return nil, err
+				}
+			}
+			if header == nil || canonical != *f.BlockHash || header.Number.Uint64() < from || header.Number.Uint64() > to {
+				from, to = 1, 0 // the block is not new, or not canonical
+			} else {
+				from, to = header.Number.Uint64(), header.Number.Uint64()
+			}
+		}
+		tx.Rollback()
+		if from <= to {
+			logs, err := api.GetLogs(ctx, filters.FilterCriteria{
+				FromBlock: new(big.Int).SetUint64(from),
+				ToBlock:   new(big.Int).SetUint64(to),
+				Addresses: f.Addresses,
+				Topics:    f.Topics,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range logs {
+				stub = append(stub, v)
+			}
+		}
+	}
+	f.Block, f.Hash = last, lastHash
+	f.LastPoll = time.Now().Unix()
+	if err = api.filters.Store().Put(ctx, id, f); err != nil {
+		return nil, err
+	}
+	return stub, nil
+}
+
+// storedFilterForkPoint returns the last delivered block which is still canonical, following the parents of the
+// delivered block back to the canonical chain
+func storedFilterForkPoint(tx kv.Tx, f *rpchelper.StoredFilter) (uint64, error) {
+	blockNum, hash := f.Block, f.Hash
+	for blockNum > 0 {
+		canonical, err := rawdb.ReadCanonicalHash(tx, blockNum)
+		if err != nil {
+			return 0, err
+		}
+		if canonical == hash {
+			return blockNum, nil
+		}
+		header := rawdb.ReadHeader(tx, hash, blockNum)
+		if header == nil { // the replaced block is unknown, deliver everything from its height again
+			return blockNum - 1, nil
+		}
+		blockNum, hash = blockNum-1, header.ParentHash
+	}
+	return 0, nil
+}
+
 // NewHeads send a notification each time a new (header) block is appended to the chain.
 func (api *APIImpl) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	if api.filters == nil {
@@ -1,18 +1,23 @@
 package commands
 
 import (
+	"context"
 	"github.com/ledgerwatch/erigon/rpc/rpccfg"
+	"math/big"
 	"math/rand"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
+	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/filters"
 	"github.com/ledgerwatch/erigon/turbo/rpchelper"
@@ -51,6 +56,164 @@ func TestNewFilters(t *testing.T) {
 	assert.Equal(ok, true)
 }
 
+func TestStoredFilters(t *testing.T) {
+	require := require.New(t)
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	ctx := context.Background()
+	dir := t.TempDir()
+	newAPI := func() (*APIImpl, *rpchelper.FilterStore) {
+		store, err := rpchelper.OpenFilterStore(dir)
+		require.NoError(err)
+		ff := rpchelper.New(ctx, nil, nil, nil, func() {})
+		ff.SetStore(store)
+		return NewEthAPI(NewBaseApi(ff, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000), store
+	}
+	api, store := newAPI()
+
+	bf, err := api.NewBlockFilter(ctx)
+	require.NoError(err)
+	lf, err := api.NewFilter(ctx, filters.FilterCriteria{})
+	require.NoError(err)
+	ptf, err := api.NewPendingTransactionFilter(ctx)
+	require.NoError(err)
+
+	// nothing after the latest block yet
+	changes, err := api.GetFilterChanges(ctx, bf)
+	require.NoError(err)
+	require.Empty(changes)
+
+	toBlock := big.NewInt(1)
+	lf1, err := api.NewFilter(ctx, filters.FilterCriteria{ToBlock: toBlock})
+	require.NoError(err)
+
+	// pretend filters were polled last time at genesis and rpcdaemon restarted since then
+	var genesisHash common.Hash
+	require.NoError(db.View(ctx, func(tx kv.Tx) (err error) {
+		genesisHash, err = rawdb.ReadCanonicalHash(tx, 0)
+		return err
+	}))
+	for _, id := range []string{bf, lf, lf1} {
+		f, err := store.Get(ctx, rpchelper.SubscriptionID(id[2:]))
+		require.NoError(err)
+		f.Block, f.Hash = 0, genesisHash
+		require.NoError(store.Put(ctx, rpchelper.SubscriptionID(id[2:]), f))
+	}
+	store.Close()
+	api, store = newAPI()
+	defer store.Close()
+
+	latest, err := api.BlockNumber(ctx)
+	require.NoError(err)
+	changes, err = api.GetFilterChanges(ctx, bf)
+	require.NoError(err)
+	require.Equal(int(latest), len(changes))
+
+	expectedLogs, err := api.GetLogs(ctx, filters.FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(int64(latest))})
+	require.NoError(err)
+	require.NotEmpty(expectedLogs)
+	changes, err = api.GetFilterChanges(ctx, lf)
+	require.NoError(err)
+	require.Equal(len(expectedLogs), len(changes))
+
+	expectedLogs, err = api.GetLogs(ctx, filters.FilterCriteria{FromBlock: big.NewInt(1), ToBlock: toBlock})
+	require.NoError(err)
+	changes, err = api.GetFilterChanges(ctx, lf1)
+	require.NoError(err)
+	require.Equal(len(expectedLogs), len(changes))
+
+	for _, id := range []string{bf, lf, lf1, ptf} {
+		changes, err = api.GetFilterChanges(ctx, id)
+		require.NoError(err)
+		require.Empty(changes)
+		ok, err := api.UninstallFilter(ctx, id)
+		require.NoError(err)
+		require.True(ok)
+	}
+	ok, err := api.UninstallFilter(ctx, bf)
+	require.NoError(err)
+	require.False(ok)
+
+	// filters which are not polled anymore are removed
+	bf, err = api.NewBlockFilter(ctx)
+	require.NoError(err)
+	pruned, err := store.Prune(ctx, time.Now().Add(-rpchelper.StoredFilterTimeout))
+	require.NoError(err)
+	require.Empty(pruned)
+	pruned, err = store.Prune(ctx, time.Now().Add(time.Second))
+	require.NoError(err)
+	require.Equal([]rpchelper.SubscriptionID{rpchelper.SubscriptionID(bf[2:])}, pruned)
+	ok, err = api.UninstallFilter(ctx, bf)
+	require.NoError(err)
+	require.False(ok)
+}
+
+func TestStoredFiltersPaging(t *testing.T) {
+	require := require.New(t)
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	ctx := context.Background()
+	store, err := rpchelper.OpenFilterStore(t.TempDir())
+	require.NoError(err)
+	defer store.Close()
+	ff := rpchelper.New(ctx, nil, nil, nil, func() {})
+	ff.SetStore(store)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+
+	defer func(max uint64) { maxFilterChangesBlocks = max }(maxFilterChangesBlocks)
+	maxFilterChangesBlocks = 3
+
+	bf, err := api.NewBlockFilter(ctx)
+	require.NoError(err)
+	lf, err := api.NewFilter(ctx, filters.FilterCriteria{})
+	require.NoError(err)
+	var genesisHash common.Hash
+	require.NoError(db.View(ctx, func(tx kv.Tx) (err error) {
+		genesisHash, err = rawdb.ReadCanonicalHash(tx, 0)
+		return err
+	}))
+	for _, id := range []string{bf, lf} {
+		f, err := store.Get(ctx, rpchelper.SubscriptionID(id[2:]))
+		require.NoError(err)
+		f.Block, f.Hash = 0, genesisHash
+		require.NoError(store.Put(ctx, rpchelper.SubscriptionID(id[2:]), f))
+	}
+
+	latest, err := api.BlockNumber(ctx)
+	require.NoError(err)
+	expectedLogs, err := api.GetLogs(ctx, filters.FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(int64(latest))})
+	require.NoError(err)
+
+	// every poll delivers the next blocks, until the latest one
+	var hashes, logs []interface{}
+	for polls := 1; ; polls++ {
+		require.LessOrEqual(polls, int(latest))
+		changes, err := api.GetFilterChanges(ctx, bf)
+		require.NoError(err)
+		require.LessOrEqual(len(changes), 3)
+		hashes = append(hashes, changes...)
+		changes, err = api.GetFilterChanges(ctx, lf)
+		require.NoError(err)
+		logs = append(logs, changes...)
+		if len(hashes) == int(latest) {
+			require.Equal((int(latest)+2)/3, polls)
+			break
+		}
+	}
+	require.NoError(db.View(ctx, func(tx kv.Tx) error {
+		for i, hash := range hashes {
+			canonical, err := rawdb.ReadCanonicalHash(tx, uint64(i+1))
+			require.NoError(err)
+			require.Equal(canonical, hash)
+		}
+		return nil
+	}))
+	require.Equal(len(expectedLogs), len(logs))
+	changes, err := api.GetFilterChanges(ctx, bf)
+	require.NoError(err)
+	require.Empty(changes)
+}
+
 func TestLogsSubscribeAndUnsubscribe_WithoutConcurrentMapIssue(t *testing.T) {
 	ctx, conn := rpcdaemontest.CreateTestGrpcConn(t, stages.Mock(t))
 	mining := txpool.NewMiningClient(conn)
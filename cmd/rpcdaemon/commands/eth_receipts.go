@@ -18,6 +18,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/misc"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
@@ -33,8 +34,24 @@ import (
 )
 
 func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, chainConfig *params.ChainConfig, block *types.Block, senders []common.Address) (types.Receipts, error) {
-	if cached := rawdb.ReadReceipts(tx, block, senders); cached != nil {
-		return cached, nil
+	return api.getReceiptsWithVMConfig(ctx, tx, chainConfig, block, senders, api.vmConfig())
+}
+
+// getReceiptsWithVMConfig re-executes the block with the given vm config if its receipts are not stored,
+// e.g. to pass the Cairo runner needed by Starknet transactions
+func (api *BaseAPI) getReceiptsWithVMConfig(ctx context.Context, tx kv.Tx, chainConfig *params.ChainConfig, block *types.Block, senders []common.Address, vmConfig vm.Config) (types.Receipts, error) {
+	receipts, err := api._blockReader.RawReceipts(ctx, tx, block.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	if receipts != nil {
+		if len(senders) > 0 {
+			block.SendersToTxs(senders)
+		}
+		if err = receipts.DeriveFields(block.Hash(), block.NumberU64(), block.Transactions(), senders); err == nil {
+			return receipts, nil
+		}
+		log.Error("Failed to derive block receipts fields", "hash", block.Hash(), "number", block.NumberU64(), "err", err)
 	}
 
 	getHeader := func(hash common.Hash, number uint64) *types.Header {
@@ -44,7 +61,7 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, chainConfig *para
 		}
 		return h
 	}
-	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), 0)
+	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), 0, api.vmConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -55,12 +72,12 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, chainConfig *para
 	ethashFaker := ethash.NewFaker()
 	noopWriter := state.NewNoopWriter()
 
-	receipts := make(types.Receipts, len(block.Transactions()))
+	receipts = make(types.Receipts, len(block.Transactions()))
 
 	for i, txn := range block.Transactions() {
 		ibs.Prepare(txn.Hash(), block.Hash(), i)
 		header := block.Header()
-		receipt, _, err := core.ApplyTransaction(chainConfig, core.GetHashFn(header, getHeader), ethashFaker, nil, gp, ibs, noopWriter, header, txn, usedGas, vm.Config{})
+		receipt, _, err := core.ApplyTransaction(chainConfig, core.GetHashFn(header, getHeader), ethashFaker, nil, gp, ibs, noopWriter, header, txn, usedGas, vmConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -71,6 +88,38 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, chainConfig *para
 	return receipts, nil
 }
 
+// forEachTxLogs calls walker with the logs of every transaction of the block which emitted any. Logs pruned from
+// the db are read from receipt snapshots
+func (api *BaseAPI) forEachTxLogs(ctx context.Context, tx kv.Tx, blockNumber uint64, walker func(txIndex uint32, logs types.Logs) error) error {
+	var found bool
+	if err := tx.ForPrefix(kv.Log, dbutils.EncodeBlockNumber(blockNumber), func(k, v []byte) error {
+		found = true
+		var logs types.Logs
+		if err := cbor.Unmarshal(&logs, bytes.NewReader(v)); err != nil {
+			return fmt.Errorf("receipt unmarshal failed:  %w", err)
+		}
+		return walker(binary.BigEndian.Uint32(k[8:]), logs)
+	}); err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+	receipts, err := api._blockReader.RawReceipts(ctx, tx, blockNumber)
+	if err != nil {
+		return err
+	}
+	for i, receipt := range receipts {
+		if len(receipt.Logs) == 0 {
+			continue
+		}
+		if err = walker(uint32(i), receipt.Logs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetLogs implements eth_getLogs. Returns an array of logs matching a given filter object.
 func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (types.Logs, error) {
 	var begin, end uint64
@@ -176,11 +225,7 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (t
 		var logIndex uint
 		var txIndex uint
 		var blockLogs []*types.Log
-		err := tx.ForPrefix(kv.Log, dbutils.EncodeBlockNumber(blockNumber), func(k, v []byte) error {
-			var logs types.Logs
-			if err := cbor.Unmarshal(&logs, bytes.NewReader(v)); err != nil {
-				return fmt.Errorf("receipt unmarshal failed:  %w", err)
-			}
+		err := api.forEachTxLogs(ctx, tx, blockNumber, func(txNum uint32, logs types.Logs) error {
 			for _, log := range logs {
 				log.Index = logIndex
 				logIndex++
@@ -189,7 +234,7 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (t
 			if len(filtered) == 0 {
 				return nil
 			}
-			txIndex = uint(binary.BigEndian.Uint32(k[8:]))
+			txIndex = uint(txNum)
 			for _, log := range filtered {
 				log.TxIndex = txIndex
 			}
@@ -358,10 +403,10 @@ func (api *APIImpl) getLogs22(ctx context.Context, tx kv.Tx, begin, end uint64,
 		}
 		blockCtx, txCtx := transactions.GetEvmContext(msg, lastHeader, true /* requireCanonical */, tx, api._blockReader)
 		stateReader.SetTxNum(txNum)
-		vmConfig := vm.Config{}
+		vmConfig := api.vmConfig()
 		vmConfig.SkipAnalysis = core.SkipAnalysis(chainConfig, blockNum)
 		ibs := state.New(stateReader)
-		evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vmConfig)
+		evm := core.NewVMForTx(blockCtx, txCtx, ibs, chainConfig, vmConfig, txn)
 
 		gp := new(core.GasPool).AddGas(msg.Gas())
 		ibs.Prepare(txHash, lastBlockHash, int(txIndex))
@@ -556,6 +601,8 @@ func marshalReceipt(receipt *types.Receipt, txn types.Transaction, chainConfig *
 		chainId = t.ChainID.ToBig()
 	case *types.DynamicFeeTransaction:
 		chainId = t.ChainID.ToBig()
+	case *types.BlobTx:
+		chainId = t.ChainID.ToBig()
 	}
 
 	var from common.Address
@@ -586,6 +633,12 @@ func marshalReceipt(receipt *types.Receipt, txn types.Transaction, chainConfig *
 		gasPrice := new(big.Int).Add(block.BaseFee(), txn.GetEffectiveGasTip(baseFee).ToBig())
 		fields["effectiveGasPrice"] = hexutil.Uint64(gasPrice.Uint64())
 	}
+	if blobGas := txn.GetBlobGas(); blobGas > 0 && block.Header().ExcessBlobGas != nil {
+		fields["blobGasUsed"] = hexutil.Uint64(blobGas)
+		if blobGasPrice, err := misc.GetBlobGasPrice(*block.Header().ExcessBlobGas); err == nil {
+			fields["blobGasPrice"] = (*hexutil.Big)(blobGasPrice.ToBig())
+		}
+	}
 	// Assign receipt status.
 	fields["status"] = hexutil.Uint64(receipt.Status)
 	if receipt.Logs == nil {
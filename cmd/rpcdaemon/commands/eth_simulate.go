@@ -0,0 +1,372 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/consensus/misc"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/rpc/rpccfg"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/log/v3"
+)
+
+const (
+	// maxSimulateBlocks - the limit of blocks in one eth_simulateV1 request
+	maxSimulateBlocks = 256
+	// maxSimulateCalls - the limit of calls in all blocks of one eth_simulateV1 request
+	maxSimulateCalls = 1000
+	// simulateTimestampIncrement - the default difference between the timestamps of the simulated blocks
+	simulateTimestampIncrement = 12
+)
+
+// SimulatedBlock - the calls of a simulated block, and the overrides applied before them
+type SimulatedBlock struct {
+	BlockOverrides *ethapi.BlockOverrides `json:"blockOverrides"`
+	StateOverrides *ethapi.StateOverrides `json:"stateOverrides"`
+	Calls          []ethapi.CallArgs      `json:"calls"`
+}
+
+// SimulateOptions - the parameters of eth_simulateV1.
+// With Validation the calls are checked like transactions: nonces, balances to pay for the gas, the base fee
+// and the gas limit of the block.
+type SimulateOptions struct {
+	BlockStateCalls []SimulatedBlock `json:"blockStateCalls"`
+	Validation      bool             `json:"validation"`
+}
+
+type SimulatedCallError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    string `json:"data,omitempty"`
+}
+
+type SimulatedCallResult struct {
+	ReturnData hexutil.Bytes       `json:"returnData"`
+	Logs       []*types.Log        `json:"logs"`
+	GasUsed    hexutil.Uint64      `json:"gasUsed"`
+	Status     hexutil.Uint64      `json:"status"`
+	Error      *SimulatedCallError `json:"error,omitempty"`
+}
+
+// SimulatedBlockResult - the header fields of the simulated block and the results of its calls.
+// The hash is calculated from the header, in which the roots are not filled.
+type SimulatedBlockResult struct {
+	Number        hexutil.Uint64        `json:"number"`
+	Hash          common.Hash           `json:"hash"`
+	ParentHash    common.Hash           `json:"parentHash"`
+	Timestamp     hexutil.Uint64        `json:"timestamp"`
+	GasLimit      hexutil.Uint64        `json:"gasLimit"`
+	GasUsed       hexutil.Uint64        `json:"gasUsed"`
+	FeeRecipient  common.Address        `json:"feeRecipient"`
+	BaseFeePerGas *hexutil.Big          `json:"baseFeePerGas,omitempty"`
+	Calls         []SimulatedCallResult `json:"calls"`
+}
+
+// SimulateV1 implements eth_simulateV1. Executes the calls in a chain of blocks built on top of the given block,
+// every next block sees the state changes of the previous ones. The calls are not saved anywhere.
+func (api *APIImpl) SimulateV1(ctx context.Context, opts SimulateOptions, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimulatedBlockResult, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, fmt.Errorf("empty blockStateCalls")
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, fmt.Errorf("too many blocks: %d, the limit is %d", len(opts.BlockStateCalls), maxSimulateBlocks)
+	}
+	var callsCount int
+	for _, block := range opts.BlockStateCalls {
+		callsCount += len(block.Calls)
+	}
+	if callsCount > maxSimulateCalls {
+		return nil, fmt.Errorf("too many calls: %d, the limit is %d", callsCount, maxSimulateCalls)
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	blockNumber, hash, _, err := rpchelper.GetCanonicalBlockNumber(bNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := api._blockReader.Header(ctx, tx, hash, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("block %d(%x) not found", blockNumber, hash)
+	}
+	stateReader, err := rpchelper.CreateStateReader(ctx, tx, bNrOrHash, api.filters, api.stateCache, api.historyV2(tx), api._agg, api._txNums)
+	if err != nil {
+		return nil, err
+	}
+	ibs := state.New(stateReader)
+
+	simulatedHashes := make(map[uint64]common.Hash, len(opts.BlockStateCalls))
+	getHash := func(n uint64) common.Hash {
+		if hash, ok := simulatedHashes[n]; ok {
+			return hash
+		}
+		if n > blockNumber {
+			return common.Hash{}
+		}
+		hash, err := rawdb.ReadCanonicalHash(tx, n)
+		if err != nil {
+			log.Debug("Can't get block hash by number", "number", n, "only-canonical", true)
+		}
+		return hash
+	}
+
+	// All calls of the request share the gas cap and the timeout, so both apply even if they are disabled for eth_call
+	limits := &simulateLimits{gasBudget: api.GasCap, timeout: api.evmCallTimeout}
+	if limits.gasBudget == 0 {
+		limits.gasBudget = ethconfig.Defaults.RPCGasCap
+	}
+	if limits.timeout == 0 {
+		limits.timeout = rpccfg.DefaultEvmCallTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, limits.timeout)
+	// Make sure the context is cancelled when the call has completed
+	// this makes sure resources are cleaned up.
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		limits.cancel()
+	}()
+
+	results := make([]*SimulatedBlockResult, 0, len(opts.BlockStateCalls))
+	for i, block := range opts.BlockStateCalls {
+		header, err := simulatedHeader(chainConfig, parent, block.BlockOverrides, opts.Validation)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if block.StateOverrides != nil {
+			if err = block.StateOverrides.Override(ibs); err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+		}
+		blockResult, err := api.simulateBlock(limits, chainConfig, ibs, header, block.Calls, getHash, opts.Validation)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		simulatedHashes[header.Number.Uint64()] = blockResult.Hash
+		results = append(results, blockResult)
+		parent = header
+	}
+	return results, nil
+}
+
+// simulateLimits - the gas and the time shared by the calls of one eth_simulateV1 request
+type simulateLimits struct {
+	gasBudget uint64 // gas left for the calls
+	timeout   time.Duration
+
+	mu        sync.Mutex
+	evm       *vm.EVM // EVM of the running call
+	cancelled bool
+}
+
+// setEVM sets the EVM of the next call, it's cancelled right away if the request is over
+func (l *simulateLimits) setEVM(evm *vm.EVM) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.evm = evm
+	if l.cancelled {
+		evm.Cancel()
+	}
+}
+
+func (l *simulateLimits) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cancelled = true
+	if l.evm != nil {
+		l.evm.Cancel()
+	}
+}
+
+// simulatedHeader returns the header of the block following the parent, with the overrides applied
+func simulatedHeader(chainConfig *params.ChainConfig, parent *types.Header, overrides *ethapi.BlockOverrides, validation bool) (*types.Header, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + simulateTimestampIncrement,
+		MixDigest:  parent.MixDigest,
+	}
+	if overrides != nil {
+		if err := overrides.OverrideHeader(header); err != nil {
+			return nil, err
+		}
+	}
+	if header.Number.Cmp(parent.Number) <= 0 {
+		return nil, fmt.Errorf("block number %d is not greater than %d of the parent", header.Number, parent.Number)
+	}
+	if header.Time <= parent.Time {
+		return nil, fmt.Errorf("block timestamp %d is not greater than %d of the parent", header.Time, parent.Time)
+	}
+	if header.BaseFee == nil && chainConfig.IsLondon(header.Number.Uint64()) {
+		header.Eip1559 = true
+		if validation {
+			header.BaseFee = misc.CalcBaseFee(chainConfig, parent)
+		} else {
+			// Calls without gas price are allowed
+			header.BaseFee = new(big.Int)
+		}
+	}
+	return header, nil
+}
+
+// simulateBlock executes the calls on top of ibs, and fills in the gas used by the block in the header
+func (api *APIImpl) simulateBlock(limits *simulateLimits, chainConfig *params.ChainConfig, ibs *state.IntraBlockState, header *types.Header,
+	calls []ethapi.CallArgs, getHash vm.GetHashFunc, validation bool) (*SimulatedBlockResult, error) {
+	var baseFee *uint256.Int
+	if header.BaseFee != nil {
+		var overflow bool
+		if baseFee, overflow = uint256.FromBig(header.BaseFee); overflow {
+			return nil, fmt.Errorf("header.BaseFee uint256 overflow")
+		}
+	}
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     getHash,
+		Coinbase:    header.Coinbase,
+		BlockNumber: header.Number.Uint64(),
+		Time:        header.Time,
+		Difficulty:  new(big.Int).Set(header.Difficulty),
+		GasLimit:    header.GasLimit,
+		BaseFee:     new(uint256.Int),
+	}
+	if baseFee != nil {
+		blockCtx.BaseFee.Set(baseFee)
+	}
+	if header.Difficulty.Sign() == 0 {
+		// EIP-4399, same as in core.NewEVMBlockContext
+		blockCtx.PrevRanDao = &header.MixDigest
+	}
+	chainID, _ := uint256.FromBig(chainConfig.ChainID)
+	rules := chainConfig.Rules(blockCtx.BlockNumber)
+
+	gasLimit := limits.gasBudget
+	if validation && header.GasLimit < gasLimit {
+		gasLimit = header.GasLimit
+	}
+	gp := new(core.GasPool).AddGas(gasLimit)
+	// The block takes its gas out of the budget of the request
+	defer func() { limits.gasBudget -= gasLimit - gp.Gas() }()
+	var allLogs []*types.Log
+	callResults := make([]SimulatedCallResult, 0, len(calls))
+	for i, args := range calls {
+		if gp.Gas() == 0 {
+			return nil, fmt.Errorf("call %d: %w", i, core.ErrGasLimitReached)
+		}
+		if args.Gas == nil || uint64(*args.Gas) == 0 {
+			gas := api.GasCap
+			if gas == 0 || gp.Gas() < gas {
+				gas = gp.Gas()
+			}
+			args.Gas = (*hexutil.Uint64)(&gas)
+		}
+		msg, err := args.ToMessage(api.GasCap, baseFee)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		nonce := ibs.GetNonce(msg.From())
+		if args.Nonce != nil {
+			nonce = uint64(*args.Nonce)
+		}
+		msg = types.NewMessage(msg.From(), msg.To(), nonce, msg.Value(), msg.Gas(), msg.GasPrice(), msg.FeeCap(), msg.Tip(), msg.Data(), msg.AccessList(), validation)
+		// The hash of the unsigned transaction, to tell apart the logs of the calls
+		txHash := (&types.DynamicFeeTransaction{
+			CommonTx:   types.CommonTx{ChainID: chainID, Nonce: nonce, To: msg.To(), Gas: msg.Gas(), Value: msg.Value(), Data: msg.Data()},
+			Tip:        msg.Tip(),
+			FeeCap:     msg.FeeCap(),
+			AccessList: msg.AccessList(),
+		}).Hash()
+		ibs.Prepare(txHash, common.Hash{}, i)
+
+		evm := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), ibs, chainConfig, vm.Config{NoBaseFee: !validation})
+		limits.setEVM(evm)
+		result, err := core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		// If the timer caused an abort, return an appropriate error message
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", limits.timeout)
+		}
+		if err = ibs.FinalizeTx(rules, state.NewNoopWriter()); err != nil {
+			return nil, err
+		}
+		header.GasUsed += result.UsedGas
+
+		logs := ibs.GetLogs(txHash)
+		if logs == nil {
+			logs = []*types.Log{}
+		}
+		allLogs = append(allLogs, logs...)
+		callResult := SimulatedCallResult{
+			ReturnData: result.Return(),
+			Logs:       logs,
+			GasUsed:    hexutil.Uint64(result.UsedGas),
+			Status:     hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if result.Failed() {
+			callResult.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			if errors.Is(result.Err, vm.ErrExecutionReverted) {
+				revertErr := ethapi.NewRevertError(result)
+				callResult.Error = &SimulatedCallError{Message: revertErr.Error(), Code: revertErr.ErrorCode(), Data: revertErr.ErrorData().(string)}
+			} else {
+				callResult.Error = &SimulatedCallError{Message: result.Err.Error(), Code: -32015}
+			}
+		}
+		callResults = append(callResults, callResult)
+	}
+
+	blockHash := header.Hash()
+	for _, l := range allLogs {
+		l.BlockNumber = header.Number.Uint64()
+		l.BlockHash = blockHash
+	}
+	blockResult := &SimulatedBlockResult{
+		Number:       hexutil.Uint64(header.Number.Uint64()),
+		Hash:         blockHash,
+		ParentHash:   header.ParentHash,
+		Timestamp:    hexutil.Uint64(header.Time),
+		GasLimit:     hexutil.Uint64(header.GasLimit),
+		GasUsed:      hexutil.Uint64(header.GasUsed),
+		FeeRecipient: header.Coinbase,
+		Calls:        callResults,
+	}
+	if header.BaseFee != nil {
+		blockResult.BaseFeePerGas = (*hexutil.Big)(header.BaseFee)
+	}
+	return blockResult, nil
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rpc/rpccfg"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateV1(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+	ctx := context.Background()
+
+	tx, err := db.BeginRo(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	head := rawdb.ReadCurrentHeader(tx)
+
+	from := common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	counter := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	reverter := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	// Increments the counter in the slot 0, logs it with the block number as a topic and returns it
+	counterCode := hexutil.Bytes(hexutil.MustDecode("0x600054600101806000556000524360206000a160206000f3"))
+	// REVERT(0, 0)
+	reverterCode := hexutil.Bytes(hexutil.MustDecode("0x60006000fd"))
+	number := (*hexutil.Big)(new(big.Int).Add(head.Number, big.NewInt(10)))
+
+	results, err := api.SimulateV1(ctx, SimulateOptions{BlockStateCalls: []SimulatedBlock{
+		{
+			StateOverrides: &ethapi.StateOverrides{counter: ethapi.Account{Code: &counterCode}},
+			Calls:          []ethapi.CallArgs{{From: &from, To: &counter}, {From: &from, To: &counter}},
+		},
+		{
+			BlockOverrides: &ethapi.BlockOverrides{Number: number},
+			StateOverrides: &ethapi.StateOverrides{reverter: ethapi.Account{Code: &reverterCode}},
+			Calls:          []ethapi.CallArgs{{From: &from, To: &counter}, {From: &from, To: &reverter}},
+		},
+	}}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	counterValue := func(call SimulatedCallResult) uint64 {
+		return new(big.Int).SetBytes(call.ReturnData).Uint64()
+	}
+	block1, block2 := results[0], results[1]
+	require.Equal(t, head.Number.Uint64()+1, uint64(block1.Number))
+	require.Equal(t, head.Hash(), block1.ParentHash)
+	require.Equal(t, head.Time+simulateTimestampIncrement, uint64(block1.Timestamp))
+	require.Len(t, block1.Calls, 2)
+	for i, call := range block1.Calls {
+		require.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), call.Status)
+		require.Nil(t, call.Error)
+		require.Equal(t, uint64(i+1), counterValue(call))
+		require.Len(t, call.Logs, 1)
+		require.Equal(t, common.BigToHash(new(big.Int).SetUint64(uint64(block1.Number))), call.Logs[0].Topics[0])
+		require.Equal(t, block1.Hash, call.Logs[0].BlockHash)
+	}
+	require.Equal(t, block1.Calls[0].GasUsed+block1.Calls[1].GasUsed, block1.GasUsed)
+
+	require.Equal(t, number.ToInt().Uint64(), uint64(block2.Number))
+	require.Equal(t, block1.Hash, block2.ParentHash)
+	require.Len(t, block2.Calls, 2)
+	require.Equal(t, uint64(3), counterValue(block2.Calls[0]))
+	require.Equal(t, common.BigToHash(number.ToInt()), block2.Calls[0].Logs[0].Topics[0])
+	require.Equal(t, hexutil.Uint64(types.ReceiptStatusFailed), block2.Calls[1].Status)
+	require.Equal(t, 3, block2.Calls[1].Error.Code)
+	require.Empty(t, block2.Calls[1].Logs)
+
+	// With validation the nonces are checked
+	nonce := hexutil.Uint64(1_000_000)
+	_, err = api.SimulateV1(ctx, SimulateOptions{
+		BlockStateCalls: []SimulatedBlock{{Calls: []ethapi.CallArgs{{From: &from, To: &counter, Nonce: &nonce}}}},
+		Validation:      true,
+	}, nil)
+	require.ErrorIs(t, err, core.ErrNonceTooHigh)
+	_, err = api.SimulateV1(ctx, SimulateOptions{
+		BlockStateCalls: []SimulatedBlock{{Calls: []ethapi.CallArgs{{From: &from, To: &counter, Nonce: &nonce}}}},
+	}, nil)
+	require.NoError(t, err)
+
+	// The calls of a request share the gas cap, the first loop takes all of it
+	looper := common.HexToAddress("0x4000000000000000000000000000000000000004")
+	// JUMPDEST PUSH1 0 JUMP
+	looperCode := hexutil.Bytes(hexutil.MustDecode("0x5b600056"))
+	_, err = api.SimulateV1(ctx, SimulateOptions{BlockStateCalls: []SimulatedBlock{
+		{StateOverrides: &ethapi.StateOverrides{looper: ethapi.Account{Code: &looperCode}}, Calls: []ethapi.CallArgs{{From: &from, To: &looper}}},
+		{Calls: []ethapi.CallArgs{{From: &from, To: &counter}}},
+	}}, nil)
+	require.ErrorIs(t, err, core.ErrGasLimitReached)
+
+	tooManyCalls := make([]ethapi.CallArgs, maxSimulateCalls+1)
+	_, err = api.SimulateV1(ctx, SimulateOptions{BlockStateCalls: []SimulatedBlock{{Calls: tooManyCalls}}}, nil)
+	require.Error(t, err)
+
+	// The blocks have to go in order
+	earlier := (*hexutil.Big)(new(big.Int).Set(head.Number))
+	_, err = api.SimulateV1(ctx, SimulateOptions{BlockStateCalls: []SimulatedBlock{
+		{BlockOverrides: &ethapi.BlockOverrides{Number: earlier}},
+	}}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/erigon/turbo/transactions"
+)
+
+// GraphQLAPI is the data access layer of the GraphQL endpoint (EIP-1767), see ../graphql
+// Next to the eth_* methods it exposes the core types, which the GraphQL resolvers
+// select fields from, instead of their JSON-RPC representations
+type GraphQLAPI interface {
+	EthAPI
+	MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error)
+
+	GetBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error)
+	GetBlockTd(ctx context.Context, hash common.Hash, number uint64) (*big.Int, error)
+	GetReceipts(ctx context.Context, block *types.Block) (types.Receipts, error)
+	GetTransactionBlock(ctx context.Context, txnHash common.Hash) (*types.Block, uint64, error)
+	DoCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (*core.ExecutionResult, error)
+}
+
+// GraphQLAPIImpl implements GraphQLAPI on top of the eth_* implementation
+type GraphQLAPIImpl struct {
+	*APIImpl
+}
+
+// NewGraphQLAPI returns GraphQLAPIImpl instance
+func NewGraphQLAPI(eth *APIImpl) *GraphQLAPIImpl {
+	return &GraphQLAPIImpl{APIImpl: eth}
+}
+
+// GetBlock returns the block with senders, or nil if it is not found
+func (api *GraphQLAPIImpl) GetBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		return api.blockByHashWithSenders(tx, hash)
+	}
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		if block := api.pendingBlock(); block != nil {
+			return block, nil
+		}
+	}
+	blockNum, _, _, err := rpchelper.GetBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	return api.blockByNumberWithSenders(tx, blockNum)
+}
+
+// GetBlockTd returns the total difficulty of the given block
+func (api *GraphQLAPIImpl) GetBlockTd(ctx context.Context, hash common.Hash, number uint64) (*big.Int, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return rawdb.ReadTd(tx, hash, number)
+}
+
+// GetReceipts returns the receipts of all transactions of the given block
+func (api *GraphQLAPIImpl) GetReceipts(ctx context.Context, block *types.Block) (types.Receipts, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := api.getReceipts(ctx, tx, chainConfig, block, block.Body().SendersFromTxs())
+	if err != nil {
+		return nil, fmt.Errorf("getReceipts error: %w", err)
+	}
+	return receipts, nil
+}
+
+// GetTransactionBlock returns the block containing the given transaction and the
+// index of the transaction in it, or a nil block if the transaction is not found
+func (api *GraphQLAPIImpl) GetTransactionBlock(ctx context.Context, txnHash common.Hash) (*types.Block, uint64, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	blockNum, ok, err := api.txnLookup(ctx, tx, txnHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !ok {
+		return nil, 0, nil
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, 0, err
+	}
+	if block == nil {
+		return nil, 0, nil
+	}
+	for idx, txn := range block.Transactions() {
+		if txn.Hash() == txnHash {
+			return block, uint64(idx), nil
+		}
+	}
+	return nil, 0, nil
+}
+
+// DoCall executes the call like eth_call does, but returns the whole execution result,
+// so that failed calls can be reported along with their gas usage
+func (api *GraphQLAPIImpl) DoCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (*core.ExecutionResult, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Gas == nil || uint64(*args.Gas) == 0 {
+		args.Gas = (*hexutil.Uint64)(&api.GasCap)
+	}
+
+	blockNumber, hash, _, err := rpchelper.GetCanonicalBlockNumber(blockNrOrHash, tx, api.filters) // DoCall cannot be executed on non-canonical blocks
+	if err != nil {
+		return nil, err
+	}
+	block, err := api.BaseAPI.blockWithSenders(tx, hash, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d(%x) not found", blockNumber, hash)
+	}
+
+	stateReader, err := rpchelper.CreateStateReader(ctx, tx, blockNrOrHash, api.filters, api.stateCache, api.historyV2(tx), api._agg, api._txNums)
+	if err != nil {
+		return nil, err
+	}
+	return transactions.DoCall(ctx, args, tx, blockNrOrHash, block, nil, nil, api.GasCap, chainConfig, stateReader, api._blockReader, api.evmCallTimeout)
+}
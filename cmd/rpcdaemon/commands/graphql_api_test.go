@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/rpc/rpccfg"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLGetBlockAndTransaction(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	ctx := context.Background()
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewGraphQLAPI(NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000))
+
+	latest, err := api.GetBlock(ctx, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	require.Equal(t, common.HexToHash("0x6804117de2f3e6ee32953e78ced1db7b20214e0d8c745a03b8fecf7cc8ee76ef"), latest.Hash())
+
+	byHash, err := api.GetBlock(ctx, rpc.BlockNumberOrHashWithHash(latest.Hash(), false))
+	require.NoError(t, err)
+	require.Equal(t, latest.NumberU64(), byHash.NumberU64())
+
+	td, err := api.GetBlockTd(ctx, latest.Hash(), latest.NumberU64())
+	require.NoError(t, err)
+	require.NotNil(t, td)
+
+	block, err := api.GetBlock(ctx, rpc.BlockNumberOrHashWithNumber(1))
+	require.NoError(t, err)
+	require.NotEmpty(t, block.Transactions())
+	txn := block.Transactions()[0]
+
+	txBlock, index, err := api.GetTransactionBlock(ctx, txn.Hash())
+	require.NoError(t, err)
+	require.NotNil(t, txBlock)
+	require.Equal(t, block.Hash(), txBlock.Hash())
+	require.Equal(t, uint64(0), index)
+
+	receipts, err := api.GetReceipts(ctx, txBlock)
+	require.NoError(t, err)
+	require.Len(t, receipts, len(txBlock.Transactions()))
+
+	missing, _, err := api.GetTransactionBlock(ctx, common.HexToHash("0xdeadbeef"))
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/erigon/turbo/transactions"
+)
+
+// otsApiLevel is the version of the ots_ namespace which Otterscan checks before using it, all the methods of the
+// level are below
+const otsApiLevel = 8
+
+// OtterscanAPI - the routines used by the Otterscan block explorer, built on top of Erigon indexes
+type OtterscanAPI interface {
+	// Namespace version (see ./otterscan_api.go)
+	GetApiLevel() uint8
+
+	// Block related (see ./otterscan_block_details.go)
+	GetBlockDetails(ctx context.Context, number rpc.BlockNumber) (map[string]interface{}, error)
+	GetBlockDetailsByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error)
+	GetBlockTransactions(ctx context.Context, number rpc.BlockNumber, pageNumber uint8, pageSize uint8) (map[string]interface{}, error)
+
+	// Search related (see ./otterscan_search.go)
+	SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error)
+	SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error)
+
+	// Account history related (see ./otterscan_transaction_by_sender_and_nonce.go and ./otterscan_contract_creator.go)
+	GetTransactionBySenderAndNonce(ctx context.Context, addr common.Address, nonce uint64) (*common.Hash, error)
+	GetContractCreator(ctx context.Context, addr common.Address) (*ContractCreatorData, error)
+
+	// Transaction related (see ./otterscan_api.go)
+	TraceTransaction(ctx context.Context, hash common.Hash) ([]*TraceEntry, error)
+	GetInternalOperations(ctx context.Context, hash common.Hash) ([]*InternalOperation, error)
+	GetTransactionError(ctx context.Context, hash common.Hash) (hexutil.Bytes, error)
+	HasCode(ctx context.Context, addr common.Address, blockNrOrHash rpc.BlockNumberOrHash) (bool, error)
+}
+
+// OtterscanAPIImpl is implementation of the OtterscanAPI interface
+type OtterscanAPIImpl struct {
+	*BaseAPI
+	db kv.RoDB
+}
+
+// NewOtterscanAPI returns OtterscanAPIImpl instance
+func NewOtterscanAPI(base *BaseAPI, db kv.RoDB) *OtterscanAPIImpl {
+	return &OtterscanAPIImpl{
+		BaseAPI: base,
+		db:      db,
+	}
+}
+
+// GetApiLevel implements ots_getApiLevel. Returns the version of the ots_ namespace.
+func (api *OtterscanAPIImpl) GetApiLevel() uint8 {
+	return otsApiLevel
+}
+
+// TraceTransaction implements ots_traceTransaction. Returns the calls, creations and selfdestructs of the transaction
+// in the order of execution.
+func (api *OtterscanAPIImpl) TraceTransaction(ctx context.Context, hash common.Hash) ([]*TraceEntry, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tracer := NewTransactionTracer()
+	if _, err = api.runTracer(ctx, tx, hash, tracer); err != nil {
+		return nil, err
+	}
+	return tracer.Results, nil
+}
+
+// GetInternalOperations implements ots_getInternalOperations. Returns the value transfers, creations and
+// selfdestructs made by the contracts during the transaction.
+func (api *OtterscanAPIImpl) GetInternalOperations(ctx context.Context, hash common.Hash) ([]*InternalOperation, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tracer := NewOperationsTracer()
+	if _, err = api.runTracer(ctx, tx, hash, tracer); err != nil {
+		return nil, err
+	}
+	return tracer.Results, nil
+}
+
+// GetTransactionError implements ots_getTransactionError. Returns the revert data of the transaction, empty if it
+// didn't revert.
+func (api *OtterscanAPIImpl) GetTransactionError(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := api.runTracer(ctx, tx, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	return result.Revert(), nil
+}
+
+// HasCode implements ots_hasCode. Returns whether the address has code at the given block.
+func (api *OtterscanAPIImpl) HasCode(ctx context.Context, addr common.Address, blockNrOrHash rpc.BlockNumberOrHash) (bool, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	reader, err := rpchelper.CreateStateReader(ctx, tx, blockNrOrHash, api.filters, api.stateCache, api.historyV2(tx), api._agg, api._txNums)
+	if err != nil {
+		return false, err
+	}
+	acc, err := reader.ReadAccountData(addr)
+	if err != nil {
+		return false, err
+	}
+	return acc != nil && !acc.IsEmptyCodeHash(), nil
+}
+
+// runTracer re-executes the transaction on top of the state left by the previous transactions of its block
+func (api *OtterscanAPIImpl) runTracer(ctx context.Context, tx kv.Tx, hash common.Hash, tracer vm.Tracer) (*core.ExecutionResult, error) {
+	blockNum, ok, err := api.txnLookup(ctx, tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", blockNum)
+	}
+	txnIndex := -1
+	for i, transaction := range block.Transactions() {
+		if transaction.Hash() == hash {
+			txnIndex = i
+			break
+		}
+	}
+	if txnIndex < 0 {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		return rawdb.ReadHeader(tx, hash, number)
+	}
+	msg, blockCtx, txCtx, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), uint64(txnIndex), api.vmConfig())
+	if err != nil {
+		return nil, err
+	}
+	var vmConfig vm.Config
+	if tracer != nil {
+		vmConfig = vm.Config{Debug: true, Tracer: tracer}
+	}
+	vmenv := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vmConfig)
+	result, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), true /* refunds */, false /* gasBailout */)
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	return result, nil
+}
+
+// replayBlock executes the transactions of the block with the tracer, on top of the state of the previous block
+// prepared by transactions.ComputeTxEnv like for the other traces. afterTx is called with the index of every executed
+// transaction, the replay stops when it returns true.
+func (api *OtterscanAPIImpl) replayBlock(ctx context.Context, tx kv.Tx, chainConfig *params.ChainConfig, block *types.Block, tracer vm.Tracer, afterTx func(txIndex int) bool) error {
+	if len(block.Transactions()) == 0 {
+		return nil
+	}
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		return rawdb.ReadHeader(tx, hash, number)
+	}
+	_, blockCtx, _, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), 0, api.vmConfig())
+	if err != nil {
+		return err
+	}
+	vmenv := vm.NewEVM(blockCtx, vm.TxContext{}, ibs, chainConfig, vm.Config{Debug: true, Tracer: tracer})
+	signer := types.MakeSigner(chainConfig, block.NumberU64())
+	rules := vmenv.ChainRules()
+	noop := state.NewNoopWriter()
+	for idx, txn := range block.Transactions() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		ibs.Prepare(txn.Hash(), block.Hash(), idx)
+		msg, err := txn.AsMessage(*signer, block.BaseFee(), rules)
+		if err != nil {
+			return err
+		}
+		vmenv.Reset(core.NewEVMTxContext(msg), ibs)
+		if _, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), true /* refunds */, false /* gasBailout */); err != nil {
+			return fmt.Errorf("transaction %#x failed: %w", txn.Hash(), err)
+		}
+		if err = ibs.FinalizeTx(rules, noop); err != nil {
+			return err
+		}
+		if afterTx(idx) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// searchAccountHistory returns the first block after which the account satisfies the condition, using the
+// AccountHistory index. The condition has to stay true once it's satisfied. ok is false if the account doesn't
+// satisfy it after the latest executed block.
+func searchAccountHistory(tx kv.Tx, addr common.Address, cond func(acc *accounts.Account) bool) (blockNum uint64, ok bool, err error) {
+	latest, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return 0, false, err
+	}
+	var searchErr error
+	satisfied := func(blockNum uint64) bool {
+		acc, err := state.NewPlainState(tx, blockNum+1).ReadAccountData(addr)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return cond(acc)
+	}
+	if !satisfied(latest) {
+		return 0, false, searchErr
+	}
+	blockNum = uint64(sort.Search(int(latest), func(i int) bool {
+		return satisfied(uint64(i))
+	}))
+	if searchErr != nil {
+		return 0, false, searchErr
+	}
+	return blockNum, true, nil
+}
@@ -0,0 +1,305 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/rpc/rpccfg"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+// The test chain: 0x71562b71999873db5b286df957af199ec94617f7 sends ether to 0x01 in the blocks 1 and 2, deploys
+// Token in the block 3, Poly in the block 9, and calls Poly.deployAndDestruct in the block 10
+var otsTestAddr = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+
+func newTestOtterscanAPI(t *testing.T) (*OtterscanAPIImpl, kv.RoDB) {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	return NewOtterscanAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db), db
+}
+
+func otsTestTxn(t *testing.T, api *OtterscanAPIImpl, db kv.RoDB, blockNum uint64, txIndex int) types.Transaction {
+	tx, err := db.BeginRo(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	return block.Transactions()[txIndex]
+}
+
+func TestOtsGetTransactionBySenderAndNonce(t *testing.T) {
+	api, db := newTestOtterscanAPI(t)
+	ctx := context.Background()
+
+	for _, at := range []struct {
+		blockNum uint64
+		txIndex  int
+	}{{1, 0}, {2, 0}, {3, 0}, {6, 0}, {6, 31}, {10, 0}} {
+		txn := otsTestTxn(t, api, db, at.blockNum, at.txIndex)
+		hash, err := api.GetTransactionBySenderAndNonce(ctx, otsTestAddr, txn.GetNonce())
+		require.NoError(t, err)
+		require.NotNil(t, hash, "nonce %d", txn.GetNonce())
+		require.Equal(t, txn.Hash(), *hash)
+	}
+
+	hash, err := api.GetTransactionBySenderAndNonce(ctx, otsTestAddr, 1000)
+	require.NoError(t, err)
+	require.Nil(t, hash)
+	hash, err = api.GetTransactionBySenderAndNonce(ctx, common.Address{1}, 0)
+	require.NoError(t, err)
+	require.Nil(t, hash)
+}
+
+func TestOtsGetContractCreator(t *testing.T) {
+	api, db := newTestOtterscanAPI(t)
+	ctx := context.Background()
+
+	for _, blockNum := range []uint64{3, 9} {
+		txn := otsTestTxn(t, api, db, blockNum, 0)
+		creator, err := api.GetContractCreator(ctx, crypto.CreateAddress(otsTestAddr, txn.GetNonce()))
+		require.NoError(t, err)
+		require.NotNil(t, creator)
+		require.Equal(t, ContractCreatorData{Tx: txn.Hash(), Creator: otsTestAddr}, *creator)
+	}
+
+	creator, err := api.GetContractCreator(ctx, otsTestAddr)
+	require.NoError(t, err)
+	require.Nil(t, creator)
+}
+
+func TestOtsCreateTracer(t *testing.T) {
+	contract, factory, other := common.HexToAddress("0xc0"), common.HexToAddress("0xfa"), common.HexToAddress("0x0b")
+	errRevert := errors.New("execution reverted")
+	type frame struct {
+		to     common.Address
+		create bool
+		err    error
+		calls  []frame
+	}
+	var run func(tracer *createTracer, f frame, depth int)
+	run = func(tracer *createTracer, f frame, depth int) {
+		tracer.CaptureStart(nil, depth, otsTestAddr, f.to, false, f.create, vm.CALLT, nil, 0, nil, nil)
+		for _, call := range f.calls {
+			run(tracer, call, depth+1)
+		}
+		tracer.CaptureEnd(depth, nil, 0, 0, 0, f.err)
+	}
+
+	for name, tc := range map[string]struct {
+		tx    frame
+		found bool
+	}{
+		"direct": {frame{to: contract, create: true}, true},
+		"factory": {frame{to: factory, calls: []frame{
+			{to: factory, calls: []frame{{to: contract, create: true}}},
+		}}, true},
+		// try factory.deploy() then try other.fail() catch {}
+		"factory then try/catch": {frame{to: factory, calls: []frame{
+			{to: factory, calls: []frame{{to: contract, create: true}}},
+			{to: other, err: errRevert},
+		}}, true},
+		"failed sibling in the factory": {frame{to: factory, calls: []frame{
+			{to: factory, calls: []frame{{to: contract, create: true}, {to: other, err: errRevert}}},
+		}}, true},
+		"reverted creation": {frame{to: factory, calls: []frame{
+			{to: contract, create: true, err: errRevert},
+		}}, false},
+		"reverted factory": {frame{to: factory, calls: []frame{
+			{to: factory, err: errRevert, calls: []frame{{to: contract, create: true}}},
+		}}, false},
+		"reverted transaction": {frame{to: factory, err: errRevert, calls: []frame{
+			{to: factory, calls: []frame{{to: contract, create: true}}},
+		}}, false},
+	} {
+		tracer := &createTracer{addr: contract, depth: -1}
+		run(tracer, tc.tx, 0)
+		require.Equal(t, tc.found, tracer.found, name)
+	}
+}
+
+func TestOtsGetApiLevel(t *testing.T) {
+	api, _ := newTestOtterscanAPI(t)
+	require.Equal(t, uint8(8), api.GetApiLevel())
+}
+
+func TestOtsGetBlockDetails(t *testing.T) {
+	api, _ := newTestOtterscanAPI(t)
+	ctx := context.Background()
+
+	details, err := api.GetBlockDetails(ctx, 6)
+	require.NoError(t, err)
+	block := details["block"].(map[string]interface{})
+	require.Equal(t, 32, block["transactionCount"])
+	require.NotContains(t, block, "transactions")
+	require.Nil(t, block["logsBloom"])
+	require.NotNil(t, block["totalDifficulty"])
+	// The test chain pays the Constantinople block reward and no fees
+	issuance := details["issuance"].(otsIssuance)
+	require.Equal(t, "0x1bc16d674ec80000", issuance.BlockReward.String())
+	require.Equal(t, "0x0", issuance.UncleReward.String())
+	require.Equal(t, "0x1bc16d674ec80000", issuance.Issuance.String())
+	require.Equal(t, "0x0", details["totalFees"].(*hexutil.Big).String())
+
+	byHash, err := api.GetBlockDetailsByHash(ctx, block["hash"].(common.Hash))
+	require.NoError(t, err)
+	require.Equal(t, details, byHash)
+
+	details, err = api.GetBlockDetails(ctx, 1000)
+	require.NoError(t, err)
+	require.Nil(t, details)
+}
+
+func TestOtsGetBlockTransactions(t *testing.T) {
+	api, db := newTestOtterscanAPI(t)
+	ctx := context.Background()
+
+	page := func(number rpc.BlockNumber, pageNumber, pageSize uint8) ([]*RPCTransaction, []map[string]interface{}) {
+		result, err := api.GetBlockTransactions(ctx, number, pageNumber, pageSize)
+		require.NoError(t, err)
+		txs := result["fullblock"].(map[string]interface{})["transactions"].([]*RPCTransaction)
+		receipts := result["receipts"].([]map[string]interface{})
+		require.Equal(t, len(txs), len(receipts))
+		for i, txn := range txs {
+			require.Equal(t, txn.Hash, receipts[i]["transactionHash"])
+			require.Nil(t, receipts[i]["logs"])
+		}
+		return txs, receipts
+	}
+
+	// The pages of the 32 transactions of the block 6 are counted from its end
+	txs, _ := page(6, 0, 10)
+	require.Len(t, txs, 10)
+	require.Equal(t, otsTestTxn(t, api, db, 6, 22).Hash(), txs[0].Hash)
+	require.Equal(t, otsTestTxn(t, api, db, 6, 31).Hash(), txs[9].Hash)
+	txs, _ = page(6, 3, 10)
+	require.Len(t, txs, 2)
+	require.Equal(t, otsTestTxn(t, api, db, 6, 0).Hash(), txs[0].Hash)
+	txs, _ = page(6, 4, 10)
+	require.Empty(t, txs)
+
+	// The input of the mint call of the block 4 is cropped to the method selector
+	txs, receipts := page(4, 0, 10)
+	require.Len(t, txs, 1)
+	require.Equal(t, hexutil.Bytes(otsTestTxn(t, api, db, 4, 0).GetData()[:4]), txs[0].Input)
+	require.Equal(t, hexutil.Uint64(1), receipts[0]["status"])
+}
+
+func TestOtsHasCode(t *testing.T) {
+	api, db := newTestOtterscanAPI(t)
+	ctx := context.Background()
+	token := crypto.CreateAddress(otsTestAddr, otsTestTxn(t, api, db, 3, 0).GetNonce())
+
+	hasCode, err := api.HasCode(ctx, token, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	require.NoError(t, err)
+	require.True(t, hasCode)
+	hasCode, err = api.HasCode(ctx, token, rpc.BlockNumberOrHashWithNumber(2))
+	require.NoError(t, err)
+	require.False(t, hasCode)
+	hasCode, err = api.HasCode(ctx, otsTestAddr, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	require.NoError(t, err)
+	require.False(t, hasCode)
+}
+
+func TestOtsSearchTransactions(t *testing.T) {
+	api, db := newTestOtterscanAPI(t)
+	ctx := context.Background()
+	theAddr := common.Address{1}
+	tx1, tx2 := otsTestTxn(t, api, db, 1, 0), otsTestTxn(t, api, db, 2, 0)
+
+	hashes := func(page *TransactionsWithReceipts) []common.Hash {
+		require.Equal(t, len(page.Txs), len(page.Receipts))
+		var result []common.Hash
+		for i, txn := range page.Txs {
+			require.Equal(t, txn.Hash, page.Receipts[i]["transactionHash"])
+			require.Contains(t, page.Receipts[i], "timestamp")
+			result = append(result, txn.Hash)
+		}
+		return result
+	}
+
+	page, err := api.SearchTransactionsBefore(ctx, theAddr, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{tx2.Hash(), tx1.Hash()}, hashes(page))
+	require.True(t, page.FirstPage)
+	require.True(t, page.LastPage)
+
+	page, err = api.SearchTransactionsBefore(ctx, theAddr, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{tx2.Hash()}, hashes(page))
+	require.True(t, page.FirstPage)
+	require.False(t, page.LastPage)
+
+	page, err = api.SearchTransactionsBefore(ctx, theAddr, 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{tx1.Hash()}, hashes(page))
+	require.False(t, page.FirstPage)
+	require.True(t, page.LastPage)
+
+	page, err = api.SearchTransactionsAfter(ctx, theAddr, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{tx1.Hash()}, hashes(page))
+	require.False(t, page.FirstPage)
+	require.True(t, page.LastPage)
+
+	page, err = api.SearchTransactionsAfter(ctx, theAddr, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{tx2.Hash()}, hashes(page))
+	require.True(t, page.FirstPage)
+	require.False(t, page.LastPage)
+
+	_, err = api.SearchTransactionsBefore(ctx, theAddr, 0, 0)
+	require.Error(t, err)
+}
+
+func TestOtsTraceDeployAndDestruct(t *testing.T) {
+	api, db := newTestOtterscanAPI(t)
+	ctx := context.Background()
+	txn := otsTestTxn(t, api, db, 10, 0)
+	poly := *txn.GetTo()
+	// The deployed contract selfdestructs sending its balance to the address equal to the block number
+	beneficiary := common.HexToAddress("0x0a")
+
+	ops, err := api.GetInternalOperations(ctx, txn.Hash())
+	require.NoError(t, err)
+	require.Len(t, ops, 2)
+	require.Equal(t, OP_CREATE2, ops[0].Type)
+	require.Equal(t, poly, ops[0].From)
+	deployed := ops[0].To
+	require.Equal(t, OP_SELF_DESTRUCT, ops[1].Type)
+	require.Equal(t, deployed, ops[1].From)
+	require.Equal(t, beneficiary, ops[1].To)
+
+	entries, err := api.TraceTransaction(ctx, txn.Hash())
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	for i, expected := range []TraceEntry{
+		{Type: "CALL", Depth: 0, From: otsTestAddr, To: poly},
+		{Type: "CREATE2", Depth: 1, From: poly, To: deployed},
+		{Type: "CALL", Depth: 1, From: poly, To: deployed},
+		{Type: "SELFDESTRUCT", Depth: 2, From: deployed, To: beneficiary},
+	} {
+		require.Equal(t, expected.Type, entries[i].Type, "entry %d", i)
+		require.Equal(t, expected.Depth, entries[i].Depth, "entry %d", i)
+		require.Equal(t, expected.From, entries[i].From, "entry %d", i)
+		require.Equal(t, expected.To, entries[i].To, "entry %d", i)
+	}
+	require.Equal(t, []byte(txn.GetData()), []byte(entries[0].Input))
+
+	revert, err := api.GetTransactionError(ctx, txn.Hash())
+	require.NoError(t, err)
+	require.Empty(t, revert)
+	_, err = api.GetTransactionError(ctx, common.Hash{1})
+	require.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/adapter/ethapi"
+)
+
+// otsIssuance is the ether created in a block, empty on the chains without block rewards
+type otsIssuance struct {
+	BlockReward *hexutil.Big `json:"blockReward,omitempty"`
+	UncleReward *hexutil.Big `json:"uncleReward,omitempty"`
+	Issuance    *hexutil.Big `json:"issuance,omitempty"`
+}
+
+// GetBlockDetails implements ots_getBlockDetails. Returns the block without its transactions, with their count, the
+// issuance and the fees paid in the block.
+func (api *OtterscanAPIImpl) GetBlockDetails(ctx context.Context, number rpc.BlockNumber) (map[string]interface{}, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := api.blockByRPCNumber(number, tx)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+	return api.blockDetails(ctx, tx, b)
+}
+
+// GetBlockDetailsByHash implements ots_getBlockDetailsByHash. Same as ots_getBlockDetails, for the block with the given
+// hash.
+func (api *OtterscanAPIImpl) GetBlockDetailsByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := api.blockByHashWithSenders(tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+	return api.blockDetails(ctx, tx, b)
+}
+
+func (api *OtterscanAPIImpl) blockDetails(ctx context.Context, tx kv.Tx, b *types.Block) (map[string]interface{}, error) {
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := api.marshalOtsBlock(tx, b)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "transactions")
+	receipts, err := api.getReceipts(ctx, tx, chainConfig, b, b.Body().SendersFromTxs())
+	if err != nil {
+		return nil, err
+	}
+
+	var issuance otsIssuance
+	if chainConfig.Ethash != nil {
+		minerReward, uncleRewards := ethash.AccumulateRewards(chainConfig, b.Header(), b.Uncles())
+		uncleReward := new(uint256.Int)
+		for i := range uncleRewards {
+			uncleReward.Add(uncleReward, &uncleRewards[i])
+		}
+		issuance.BlockReward = (*hexutil.Big)(minerReward.ToBig())
+		issuance.UncleReward = (*hexutil.Big)(uncleReward.ToBig())
+		issuance.Issuance = (*hexutil.Big)(new(uint256.Int).Add(&minerReward, uncleReward).ToBig())
+	}
+
+	totalFees := new(uint256.Int)
+	for i, receipt := range receipts {
+		fee := effectiveGasPrice(chainConfig, b, b.Transactions()[i])
+		totalFees.Add(totalFees, fee.Mul(fee, uint256.NewInt(receipt.GasUsed)))
+	}
+
+	return map[string]interface{}{
+		"block":     fields,
+		"issuance":  issuance,
+		"totalFees": (*hexutil.Big)(totalFees.ToBig()),
+	}, nil
+}
+
+// GetBlockTransactions implements ots_getBlockTransactions. Returns a page of the transactions of the block and their
+// receipts, the pages are counted from the end of the block. The inputs are cropped to the method selectors and the
+// receipts come without logs to keep the response small.
+func (api *OtterscanAPIImpl) GetBlockTransactions(ctx context.Context, number rpc.BlockNumber, pageNumber uint8, pageSize uint8) (map[string]interface{}, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b, err := api.blockByRPCNumber(number, tx)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := api.marshalOtsBlock(tx, b)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := api.getReceipts(ctx, tx, chainConfig, b, b.Body().SendersFromTxs())
+	if err != nil {
+		return nil, err
+	}
+
+	txs := b.Transactions()
+	pageEnd := len(txs) - int(pageNumber)*int(pageSize)
+	if pageEnd < 0 {
+		pageEnd = 0
+	}
+	pageStart := pageEnd - int(pageSize)
+	if pageStart < 0 {
+		pageStart = 0
+	}
+
+	pageTxs := make([]*RPCTransaction, 0, pageEnd-pageStart)
+	pageReceipts := make([]map[string]interface{}, 0, pageEnd-pageStart)
+	for i := pageStart; i < pageEnd; i++ {
+		txn := newRPCTransaction(txs[i], b.Hash(), b.NumberU64(), uint64(i), b.BaseFee())
+		if len(txn.Input) > 4 {
+			txn.Input = txn.Input[:4]
+		}
+		pageTxs = append(pageTxs, txn)
+
+		receipt := marshalReceipt(receipts[i], txs[i], chainConfig, b, txs[i].Hash(), true)
+		receipt["logs"] = nil
+		receipt["logsBloom"] = nil
+		pageReceipts = append(pageReceipts, receipt)
+	}
+	fields["transactions"] = pageTxs
+
+	return map[string]interface{}{
+		"fullblock": fields,
+		"receipts":  pageReceipts,
+	}, nil
+}
+
+// marshalOtsBlock marshals the block with an empty list of transactions and without the logs bloom, which Otterscan
+// fetches separately
+func (api *OtterscanAPIImpl) marshalOtsBlock(tx kv.Tx, b *types.Block) (map[string]interface{}, error) {
+	additionalFields := map[string]interface{}{
+		"transactionCount": len(b.Transactions()),
+		"logsBloom":        nil,
+	}
+	td, err := rawdb.ReadTd(tx, b.Hash(), b.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	if td != nil {
+		additionalFields["totalDifficulty"] = (*hexutil.Big)(td)
+	}
+	return ethapi.RPCMarshalBlock(b, false, false, additionalFields)
+}
+
+// effectiveGasPrice is the price per gas paid by the transaction, as the effectiveGasPrice of its receipt
+func effectiveGasPrice(chainConfig *params.ChainConfig, b *types.Block, txn types.Transaction) *uint256.Int {
+	if !chainConfig.IsLondon(b.NumberU64()) {
+		return new(uint256.Int).Set(txn.GetPrice())
+	}
+	baseFee, _ := uint256.FromBig(b.BaseFee())
+	return new(uint256.Int).Add(baseFee, txn.GetEffectiveGasTip(baseFee))
+}
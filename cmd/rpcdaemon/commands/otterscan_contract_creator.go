@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+type ContractCreatorData struct {
+	Tx      common.Hash    `json:"hash"`
+	Creator common.Address `json:"creator"`
+}
+
+// GetContractCreator implements ots_getContractCreator. Returns the transaction which created the current contract at
+// the address and the creator, which is the sender of the transaction or the contract deploying it. Returns nil if
+// there is no contract at the address or it was created in the genesis.
+func (api *OtterscanAPIImpl) GetContractCreator(ctx context.Context, addr common.Address) (*ContractCreatorData, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if api.historyV2(tx) {
+		return nil, fmt.Errorf(NotImplemented, "ots_getContractCreator")
+	}
+	acc, err := state.NewPlainStateReader(tx).ReadAccountData(addr)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil || acc.IsEmptyCodeHash() {
+		return nil, nil
+	}
+	// Recreations of the contract at the address increment the incarnation
+	incarnation := acc.Incarnation
+	blockNum, ok, err := searchAccountHistory(tx, addr, func(acc *accounts.Account) bool {
+		return acc != nil && acc.Incarnation >= incarnation
+	})
+	if err != nil || !ok || blockNum == 0 {
+		return nil, err
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := &createTracer{addr: addr, depth: -1}
+	var creator *ContractCreatorData
+	if err = api.replayBlock(ctx, tx, chainConfig, block, tracer, func(txIndex int) bool {
+		if tracer.found {
+			creator = &ContractCreatorData{Tx: block.Transactions()[txIndex].Hash(), Creator: tracer.creator}
+		}
+		return tracer.found
+	}); err != nil {
+		return nil, err
+	}
+	return creator, nil
+}
+
+// createTracer looks for the successful creation of the contract at the address
+type createTracer struct {
+	addr    common.Address
+	depth   int // depth of the pending creation, -1 if there is none
+	creator common.Address
+	found   bool
+	foundAt int // depth of the innermost open frame enclosing the successful creation, it's reverted if that frame fails
+}
+
+func (t *createTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if create && to == t.addr && !t.found {
+		t.depth = depth
+		t.creator = from
+	}
+}
+
+func (t *createTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *createTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *createTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+	if depth == t.depth {
+		t.depth = -1
+		t.found, t.foundAt = err == nil, depth-1
+	} else if t.found && depth == t.foundAt {
+		// Frames ending deeper than foundAt are calls made after the creation, their failures don't revert it
+		if err != nil {
+			t.found = false
+		} else {
+			t.foundAt--
+		}
+	}
+}
+
+func (t *createTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+}
+
+func (t *createTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *createTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
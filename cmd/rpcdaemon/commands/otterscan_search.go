@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// TransactionsWithReceipts - a page of the transactions touching the address, from the newest to the oldest
+type TransactionsWithReceipts struct {
+	Txs       []*RPCTransaction        `json:"txs"`
+	Receipts  []map[string]interface{} `json:"receipts"`
+	FirstPage bool                     `json:"firstPage"` // there are no newer transactions
+	LastPage  bool                     `json:"lastPage"`  // there are no older transactions
+}
+
+// SearchTransactionsBefore implements ots_searchTransactionsBefore. Returns the transactions touching the address in
+// the blocks before blockNum, 0 means the search starts from the latest block. The blocks are taken whole, so the
+// page may be longer than pageSize.
+func (api *OtterscanAPIImpl) SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if api.historyV2(tx) {
+		return nil, fmt.Errorf(NotImplemented, "ots_searchTransactionsBefore")
+	}
+	if pageSize == 0 {
+		return nil, fmt.Errorf("page size must be greater than 0")
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	page := &TransactionsWithReceipts{FirstPage: blockNum == 0}
+	if blockNum == 0 {
+		blockNum = ^uint64(0)
+	}
+	it, err := newAddressBlockIterator(tx, addr, blockNum, true)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	for len(page.Txs) < int(pageSize) {
+		block, ok, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		txs, receipts, err := api.searchBlock(ctx, tx, chainConfig, addr, block)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(txs) - 1; i >= 0; i-- {
+			page.Txs = append(page.Txs, txs[i])
+			page.Receipts = append(page.Receipts, receipts[i])
+		}
+	}
+	hasNext, err := it.HasNext()
+	if err != nil {
+		return nil, err
+	}
+	page.LastPage = !hasNext
+	return page, nil
+}
+
+// SearchTransactionsAfter implements ots_searchTransactionsAfter. Returns the transactions touching the address in
+// the blocks after blockNum. The blocks are taken whole, so the page may be longer than pageSize.
+func (api *OtterscanAPIImpl) SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if api.historyV2(tx) {
+		return nil, fmt.Errorf(NotImplemented, "ots_searchTransactionsAfter")
+	}
+	if pageSize == 0 {
+		return nil, fmt.Errorf("page size must be greater than 0")
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	page := &TransactionsWithReceipts{LastPage: blockNum == 0}
+	it, err := newAddressBlockIterator(tx, addr, blockNum, false)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var txs []*RPCTransaction
+	var receipts []map[string]interface{}
+	for len(txs) < int(pageSize) {
+		block, ok, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		blockTxs, blockReceipts, err := api.searchBlock(ctx, tx, chainConfig, addr, block)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, blockTxs...)
+		receipts = append(receipts, blockReceipts...)
+	}
+	hasNext, err := it.HasNext()
+	if err != nil {
+		return nil, err
+	}
+	page.FirstPage = !hasNext
+	for i := len(txs) - 1; i >= 0; i-- {
+		page.Txs = append(page.Txs, txs[i])
+		page.Receipts = append(page.Receipts, receipts[i])
+	}
+	return page, nil
+}
+
+// addressBlockIterator walks the blocks where the address calls or is called, according to the CallTraces indexes,
+// from a block forward or backward. The index chunks are read as the iteration reaches them.
+type addressBlockIterator struct {
+	from, to *bitmapdb.Iterator64
+	reverse  bool
+}
+
+// newAddressBlockIterator returns the iterator over the blocks after blockNum, or before it if reverse is set
+func newAddressBlockIterator(tx kv.Tx, addr common.Address, blockNum uint64, reverse bool) (*addressBlockIterator, error) {
+	from, err := bitmapdb.NewIterator64(tx, kv.CallFromIndex, addr.Bytes(), blockNum, reverse)
+	if err != nil {
+		return nil, err
+	}
+	to, err := bitmapdb.NewIterator64(tx, kv.CallToIndex, addr.Bytes(), blockNum, reverse)
+	if err != nil {
+		from.Close()
+		return nil, err
+	}
+	return &addressBlockIterator{from: from, to: to, reverse: reverse}, nil
+}
+
+func (it *addressBlockIterator) HasNext() (bool, error) {
+	if ok, err := it.from.HasNext(); ok || err != nil {
+		return ok, err
+	}
+	return it.to.HasNext()
+}
+
+// Next returns the next block of either index, the blocks in both are returned once
+func (it *addressBlockIterator) Next() (uint64, bool, error) {
+	fromOk, err := it.from.HasNext()
+	if err != nil {
+		return 0, false, err
+	}
+	toOk, err := it.to.HasNext()
+	if err != nil {
+		return 0, false, err
+	}
+	switch {
+	case fromOk && toOk:
+		fromBlock, toBlock := it.from.Peek(), it.to.Peek()
+		if fromBlock == toBlock {
+			it.to.Next()
+			return it.from.Next(), true, nil
+		}
+		if (fromBlock < toBlock) != it.reverse {
+			return it.from.Next(), true, nil
+		}
+		return it.to.Next(), true, nil
+	case fromOk:
+		return it.from.Next(), true, nil
+	case toOk:
+		return it.to.Next(), true, nil
+	}
+	return 0, false, nil
+}
+
+func (it *addressBlockIterator) Close() {
+	it.from.Close()
+	it.to.Close()
+}
+
+// searchBlock replays the block and returns its transactions touching the address, with their receipts
+func (api *OtterscanAPIImpl) searchBlock(ctx context.Context, tx kv.Tx, chainConfig *params.ChainConfig, addr common.Address, blockNum uint64) ([]*RPCTransaction, []map[string]interface{}, error) {
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, nil, err
+	}
+	if block == nil {
+		return nil, nil, nil
+	}
+	tracer := &touchTracer{addr: addr}
+	var indexes []int
+	if err = api.replayBlock(ctx, tx, chainConfig, block, tracer, func(txIndex int) bool {
+		if tracer.touched {
+			indexes = append(indexes, txIndex)
+		}
+		return false
+	}); err != nil {
+		return nil, nil, fmt.Errorf("block %d: %w", blockNum, err)
+	}
+	if len(indexes) == 0 {
+		return nil, nil, nil
+	}
+
+	receipts, err := api.getReceipts(ctx, tx, chainConfig, block, block.Body().SendersFromTxs())
+	if err != nil {
+		return nil, nil, fmt.Errorf("getReceipts error: %w", err)
+	}
+	txs := make([]*RPCTransaction, 0, len(indexes))
+	marshalled := make([]map[string]interface{}, 0, len(indexes))
+	for _, i := range indexes {
+		txn := block.Transactions()[i]
+		txs = append(txs, newRPCTransaction(txn, block.Hash(), blockNum, uint64(i), block.BaseFee()))
+		receipt := marshalReceipt(receipts[i], txn, chainConfig, block, txn.Hash(), true)
+		receipt["timestamp"] = block.Time()
+		marshalled = append(marshalled, receipt)
+	}
+	return txs, marshalled, nil
+}
+
+// touchTracer checks whether the address sends, receives or makes a call, directly or through the contracts,
+// in the transaction being executed
+type touchTracer struct {
+	addr    common.Address
+	touched bool
+}
+
+func (t *touchTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth == 0 {
+		t.touched = false
+	}
+	if from == t.addr || to == t.addr {
+		t.touched = true
+	}
+}
+
+func (t *touchTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *touchTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *touchTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+}
+
+func (t *touchTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	if from == t.addr || to == t.addr {
+		t.touched = true
+	}
+}
+
+func (t *touchTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *touchTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
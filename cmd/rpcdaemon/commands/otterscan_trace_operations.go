@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+// Types of the internal operations
+const (
+	OP_TRANSFER      = 0
+	OP_SELF_DESTRUCT = 1
+	OP_CREATE        = 2
+	OP_CREATE2       = 3
+)
+
+type InternalOperation struct {
+	Type  int            `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+}
+
+// OperationsTracer collects the operations made by the contracts: value transfers, creations and selfdestructs.
+// The top level call of the transaction is not included.
+type OperationsTracer struct {
+	Results []*InternalOperation
+}
+
+func NewOperationsTracer() *OperationsTracer {
+	return &OperationsTracer{
+		Results: make([]*InternalOperation, 0),
+	}
+}
+
+func (t *OperationsTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth == 0 {
+		return
+	}
+	switch callType {
+	case vm.CALLT:
+		if value.Sign() != 0 {
+			t.Results = append(t.Results, &InternalOperation{Type: OP_TRANSFER, From: from, To: to, Value: (*hexutil.Big)(value)})
+		}
+	case vm.CREATET:
+		t.Results = append(t.Results, &InternalOperation{Type: OP_CREATE, From: from, To: to, Value: (*hexutil.Big)(value)})
+	case vm.CREATE2T:
+		t.Results = append(t.Results, &InternalOperation{Type: OP_CREATE2, From: from, To: to, Value: (*hexutil.Big)(value)})
+	}
+}
+
+func (t *OperationsTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *OperationsTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *OperationsTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+}
+
+func (t *OperationsTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	t.Results = append(t.Results, &InternalOperation{Type: OP_SELF_DESTRUCT, From: from, To: to, Value: (*hexutil.Big)(new(big.Int).Set(value))})
+}
+
+func (t *OperationsTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *OperationsTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+type TraceEntry struct {
+	Type  string         `json:"type"`
+	Depth int            `json:"depth"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Input hexutil.Bytes  `json:"input"`
+}
+
+// TransactionTracer collects the compact call tree of the transaction: the calls, creations and selfdestructs with
+// their depth. Calls to precompiles are not included.
+type TransactionTracer struct {
+	Results []*TraceEntry
+	depth   int // depth of the call being executed, selfdestructs happen inside it
+}
+
+func NewTransactionTracer() *TransactionTracer {
+	return &TransactionTracer{
+		Results: make([]*TraceEntry, 0),
+	}
+}
+
+func (t *TransactionTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	t.depth = depth
+	if precompile {
+		return
+	}
+	inputCopy := make([]byte, len(input))
+	copy(inputCopy, input)
+	entry := &TraceEntry{Depth: depth, From: from, To: to, Value: (*hexutil.Big)(value), Input: inputCopy}
+	switch callType {
+	case vm.CALLT:
+		entry.Type = "CALL"
+	case vm.STATICCALLT:
+		entry.Type = "STATICCALL"
+		entry.Value = nil
+	case vm.DELEGATECALLT:
+		entry.Type = "DELEGATECALL"
+		entry.Value = nil
+	case vm.CALLCODET:
+		entry.Type = "CALLCODE"
+	case vm.CREATET:
+		entry.Type = "CREATE"
+	case vm.CREATE2T:
+		entry.Type = "CREATE2"
+	}
+	t.Results = append(t.Results, entry)
+}
+
+func (t *TransactionTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *TransactionTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *TransactionTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, d time.Duration, err error) {
+	t.depth = depth - 1
+}
+
+func (t *TransactionTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	t.Results = append(t.Results, &TraceEntry{Type: "SELFDESTRUCT", Depth: t.depth + 1, From: from, To: to, Value: (*hexutil.Big)(new(big.Int).Set(value))})
+}
+
+func (t *TransactionTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *TransactionTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+)
+
+// GetTransactionBySenderAndNonce implements ots_getTransactionBySenderAndNonce. Returns the hash of the transaction
+// sent by the address with the nonce, nil if there is no such transaction.
+func (api *OtterscanAPIImpl) GetTransactionBySenderAndNonce(ctx context.Context, addr common.Address, nonce uint64) (*common.Hash, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if api.historyV2(tx) {
+		return nil, fmt.Errorf(NotImplemented, "ots_getTransactionBySenderAndNonce")
+	}
+	// The nonce of the sender is incremented by the block which includes the transaction
+	blockNum, ok, err := searchAccountHistory(tx, addr, func(acc *accounts.Account) bool {
+		return acc != nil && acc.Nonce > nonce
+	})
+	if err != nil || !ok {
+		return nil, err
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	senders := block.Body().SendersFromTxs()
+	for i, txn := range block.Transactions() {
+		if senders[i] == addr && txn.GetNonce() == nonce {
+			hash := txn.Hash()
+			return &hash, nil
+		}
+	}
+	return nil, nil
+}
@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/holiman/uint256"
 	txPoolProto "github.com/ledgerwatch/erigon-lib/gointerfaces/txpool"
+	"github.com/ledgerwatch/erigon/accounts"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/rpc"
 	"github.com/ledgerwatch/log/v3"
 )
 
@@ -84,8 +88,181 @@ func (api *APIImpl) SendRawTransaction(ctx context.Context, encodedTx hexutil.By
 }
 
 // SendTransaction implements eth_sendTransaction. Creates new message call transaction or a contract creation if the data field contains code.
-func (api *APIImpl) SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error) {
-	return common.Hash{0}, fmt.Errorf(NotImplemented, "eth_sendTransaction")
+// The transaction is signed with one of the accounts of the node, missing nonce, gas and fees are filled in.
+func (api *APIImpl) SendTransaction(ctx context.Context, args ethapi.CallArgs) (common.Hash, error) {
+	signed, err := api.signTransaction(ctx, args)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var buf bytes.Buffer
+	if err = signed.MarshalBinary(&buf); err != nil {
+		return common.Hash{}, err
+	}
+	return api.SendRawTransaction(ctx, buf.Bytes())
+}
+
+// SignTransactionResult is the result of eth_signTransaction: the signed transaction both RLP encoded and as JSON
+type SignTransactionResult struct {
+	Raw hexutil.Bytes     `json:"raw"`
+	Tx  types.Transaction `json:"tx"`
+}
+
+// SignTransaction implements eth_signTransaction. Signs the transaction with one of the accounts of the node,
+// like eth_sendTransaction, but returns it instead of submitting it to the txpool.
+func (api *APIImpl) SignTransaction(ctx context.Context, args ethapi.CallArgs) (*SignTransactionResult, error) {
+	signed, err := api.signTransaction(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = signed.MarshalBinary(&buf); err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{Raw: buf.Bytes(), Tx: signed}, nil
+}
+
+// Sign implements eth_sign. Calculates an Ethereum specific signature with: sign(keccak256('\\x19Ethereum Signed Message:\\n' + len(message) + message))).
+func (api *APIImpl) Sign(ctx context.Context, address common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if err := api.checkSigningTransport(ctx); err != nil {
+		return nil, err
+	}
+	if api.AccountManager == nil {
+		return nil, fmt.Errorf("%w: %x", accounts.ErrUnknownAccount, address)
+	}
+	signature, err := api.AccountManager.SignText(address, data)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
+// errInsecureSigning is returned when the accounts of the node are asked to sign over http or websocket
+var errInsecureSigning = errors.New("signing with the accounts of the node is forbidden over http and websocket, see --allow-insecure-unlock")
+
+// checkSigningTransport refuses to sign for requests which came in over http or websocket, anyone reaching the
+// eth API there could spend from the accounts, unless the node is started with --allow-insecure-unlock
+func (api *APIImpl) checkSigningTransport(ctx context.Context) error {
+	if rpc.IsPublicTransport(ctx) && !api.InsecureUnlockAllowed {
+		return errInsecureSigning
+	}
+	return nil
+}
+
+func (api *APIImpl) signTransaction(ctx context.Context, args ethapi.CallArgs) (types.Transaction, error) {
+	if err := api.checkSigningTransport(ctx); err != nil {
+		return nil, err
+	}
+	if args.From == nil {
+		return nil, errors.New("missing from address")
+	}
+	if api.AccountManager == nil || !api.AccountManager.Contains(*args.From) {
+		return nil, fmt.Errorf("%w: %x", accounts.ErrUnknownAccount, *args.From)
+	}
+	txn, chainId, err := api.fillTransaction(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return api.AccountManager.SignTx(*args.From, txn, chainId)
+}
+
+// fillTransaction fills in the missing fields of a transaction to be signed by the node: the nonce from the txpool,
+// the fees from the gas price oracle and the gas limit from gas estimation.
+func (api *APIImpl) fillTransaction(ctx context.Context, args ethapi.CallArgs) (types.Transaction, *big.Int, error) {
+	if args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil) {
+		return nil, nil, errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
+	}
+	if args.To == nil && (args.Data == nil || len(*args.Data) == 0) {
+		return nil, nil, errors.New("contract creation without any data provided")
+	}
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	cc, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	head := rawdb.ReadCurrentHeader(tx)
+	if head == nil {
+		return nil, nil, errors.New("current header not found")
+	}
+
+	if args.ChainID != nil && args.ChainID.ToInt().Cmp(cc.ChainID) != 0 {
+		return nil, nil, fmt.Errorf("invalid chain id, expected: %d got: %d", cc.ChainID, args.ChainID.ToInt())
+	}
+	if args.Nonce == nil {
+		if args.Nonce, err = api.GetTransactionCount(ctx, *args.From, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Dynamic fee transactions are the default once London is active
+	if args.GasPrice == nil {
+		if cc.IsLondon(head.Number.Uint64()+1) && head.BaseFee != nil {
+			if args.MaxPriorityFeePerGas == nil {
+				if args.MaxPriorityFeePerGas, err = api.MaxPriorityFeePerGas(ctx); err != nil {
+					return nil, nil, err
+				}
+			}
+			if args.MaxFeePerGas == nil {
+				// Leave room for the base fee to double before the transaction is no longer includable
+				feeCap := new(big.Int).Add(args.MaxPriorityFeePerGas.ToInt(), new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+				args.MaxFeePerGas = (*hexutil.Big)(feeCap)
+			}
+			if args.MaxFeePerGas.ToInt().Cmp(args.MaxPriorityFeePerGas.ToInt()) < 0 {
+				return nil, nil, fmt.Errorf("maxFeePerGas (%v) < maxPriorityFeePerGas (%v)", args.MaxFeePerGas, args.MaxPriorityFeePerGas)
+			}
+		} else {
+			if args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil {
+				return nil, nil, errors.New("maxFeePerGas or maxPriorityFeePerGas specified but london is not active yet")
+			}
+			if args.GasPrice, err = api.GasPrice(ctx); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if args.Gas == nil {
+		gas, err := api.EstimateGas(ctx, &args, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		args.Gas = &gas
+	}
+	return toTransaction(args, cc.ChainID), cc.ChainID, nil
+}
+
+// toTransaction converts the arguments with all fields filled in to a transaction
+func toTransaction(args ethapi.CallArgs, chainId *big.Int) types.Transaction {
+	commonTx := types.CommonTx{
+		Nonce: uint64(*args.Nonce),
+		To:    args.To,
+		Gas:   uint64(*args.Gas),
+		Value: new(uint256.Int),
+	}
+	if args.Value != nil {
+		commonTx.Value, _ = uint256.FromBig(args.Value.ToInt())
+	}
+	if args.Data != nil {
+		commonTx.Data = *args.Data
+	}
+	var accessList types.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+	chainID, _ := uint256.FromBig(chainId)
+	if args.MaxFeePerGas != nil {
+		commonTx.ChainID = chainID
+		tip, _ := uint256.FromBig(args.MaxPriorityFeePerGas.ToInt())
+		feeCap, _ := uint256.FromBig(args.MaxFeePerGas.ToInt())
+		return &types.DynamicFeeTransaction{CommonTx: commonTx, Tip: tip, FeeCap: feeCap, AccessList: accessList}
+	}
+	gasPrice, _ := uint256.FromBig(args.GasPrice.ToInt())
+	legacy := types.LegacyTx{CommonTx: commonTx, GasPrice: gasPrice}
+	if args.AccessList != nil {
+		return &types.AccessListTx{LegacyTx: legacy, ChainID: chainID, AccessList: accessList}
+	}
+	return &legacy
 }
 
 // checkTxFee is an internal function used to check whether the fee of
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+)
+
+// StarknetClass is the contract class of a deployed contract
+type StarknetClass struct {
+	ClassHash common.Hash   `json:"class_hash"`
+	Program   hexutil.Bytes `json:"program"`
+}
+
+// GetCode implements starknet_getCode. Returns the code of the contract at the given address.
+func (api *StarknetImpl) GetCode(ctx context.Context, address common.Address32, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	addr, err := starknetAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	tx, err1 := api.db.BeginRo(ctx)
+	if err1 != nil {
+		return nil, fmt.Errorf("getCode cannot open tx: %w", err1)
+	}
+	defer tx.Rollback()
+	reader, err := rpchelper.CreateStateReader(ctx, tx, blockNrOrHash, api.filters, api.stateCache, api.historyV2(tx), api._agg, api._txNums)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := reader.ReadAccountData(addr)
+	if acc == nil || err != nil {
+		return hexutil.Bytes(""), nil
+	}
+	res, _ := reader.ReadAccountCode(addr, acc.Incarnation, acc.CodeHash)
+	if res == nil {
+		return hexutil.Bytes(""), nil
+	}
+	return res, nil
+}
+
+// GetClassAt implements starknet_getClassAt. Returns the contract class of the contract at the given address,
+// or nil if there is no contract.
+func (api *StarknetImpl) GetClassAt(ctx context.Context, address common.Address32, blockNrOrHash rpc.BlockNumberOrHash) (*StarknetClass, error) {
+	code, err := api.GetCode(ctx, address, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, nil
+	}
+	return &StarknetClass{ClassHash: crypto.Keccak256Hash(code), Program: code}, nil
+}
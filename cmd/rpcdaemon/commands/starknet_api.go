@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// StarknetAPI is the interface for the starknet_ RPC commands
+type StarknetAPI interface {
+	// Sending related (see ./starknet_send_transaction.go)
+	AddDeployTransaction(ctx context.Context, encodedTx hexutil.Bytes) (*StarknetTransactionResult, error)
+	AddInvokeTransaction(ctx context.Context, encodedTx hexutil.Bytes) (*StarknetTransactionResult, error)
+
+	// Contract related (see ./starknet_accounts.go)
+	GetCode(ctx context.Context, address common.Address32, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
+	GetClassAt(ctx context.Context, address common.Address32, blockNrOrHash rpc.BlockNumberOrHash) (*StarknetClass, error)
+
+	// Execution related (see ./starknet_call.go)
+	Call(ctx context.Context, request StarknetCallRequest, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
+
+	// Receipt related (see ./starknet_receipts.go)
+	GetTransactionReceipt(ctx context.Context, txnHash common.Hash) (*StarknetReceipt, error)
+}
+
+// StarknetImpl is implementation of the StarknetAPI interface
+type StarknetImpl struct {
+	*BaseAPI
+	db     kv.RoDB
+	eth    *APIImpl
+	runner cairo.Runner
+}
+
+// NewStarknetAPI returns StarknetImpl instance. Transactions are submitted through the
+// eth_ implementation, contract views are executed by the given Cairo runner.
+func NewStarknetAPI(eth *APIImpl, runner cairo.Runner) *StarknetImpl {
+	return &StarknetImpl{
+		BaseAPI: eth.BaseAPI,
+		db:      eth.db,
+		eth:     eth,
+		runner:  runner,
+	}
+}
+
+// starknetAddress maps a Starknet address to the account holding the contract in the state.
+// Contracts are deployed at 20-byte addresses, so the address must be left-padded with zeros.
+func starknetAddress(address common.Address32) (common.Address, error) {
+	for _, b := range address[:common.Address32Length-common.AddressLength] {
+		if b != 0 {
+			return common.Address{}, fmt.Errorf("starknet address %x is out of the range of contract addresses", address)
+		}
+	}
+	return address.ToCommonAddress(), nil
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/rpc/rpccfg"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStarknetGetCodeAndCall(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	ctx := context.Background()
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	ethImpl := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+	api := NewStarknetAPI(ethImpl, cairo.NewFakeRunner())
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	// the token contract deployed by the test chain
+	contract := crypto.CreateAddress(common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7"), 2)
+	address := common.BytesToAddress32(contract.Bytes())
+
+	code, err := api.GetCode(ctx, address, latest)
+	require.NoError(t, err)
+	ethCode, err := ethImpl.GetCode(ctx, contract, latest)
+	require.NoError(t, err)
+	require.NotEmpty(t, code)
+	require.Equal(t, ethCode, code)
+
+	class, err := api.GetClassAt(ctx, address, latest)
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256Hash(code), class.ClassHash)
+
+	class, err = api.GetClassAt(ctx, common.HexToAddress32("0x1234"), latest)
+	require.NoError(t, err)
+	require.Nil(t, class)
+
+	_, err = api.GetCode(ctx, common.HexToAddress32("0x0100000000000000000000000000000000000000000000000000000000001234"), latest)
+	require.Error(t, err)
+
+	// the fake runner returns the storage slots selected by the calldata
+	calldata := append(common.BigToHash(big.NewInt(cairo.FakeSelectorLoad)).Bytes(), common.Hash{}.Bytes()...)
+	ret, err := api.Call(ctx, StarknetCallRequest{ContractAddress: address, Calldata: calldata}, latest)
+	require.NoError(t, err)
+	slot, err := ethImpl.GetStorageAt(ctx, contract, "0x0", latest)
+	require.NoError(t, err)
+	require.Equal(t, slot, hexutil.Encode(ret))
+}
+
+func TestStarknetRejectsEthereumTransactions(t *testing.T) {
+	db := rpcdaemontest.CreateTestKV(t)
+	ctx := context.Background()
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	ethImpl := NewEthAPI(NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout), db, nil, nil, nil, 5000000)
+	api := NewStarknetAPI(ethImpl, nil)
+
+	block, err := ethImpl.GetBlockByNumber(ctx, 1, false)
+	require.NoError(t, err)
+	txs := block["transactions"].([]interface{})
+	require.NotEmpty(t, txs)
+	_, err = api.GetTransactionReceipt(ctx, txs[0].(common.Hash))
+	require.Error(t, err)
+
+	_, err = api.Call(ctx, StarknetCallRequest{}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	require.Error(t, err)
+}
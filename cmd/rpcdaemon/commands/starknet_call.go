@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/rpc"
+	"github.com/ledgerwatch/erigon/turbo/rpchelper"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// StarknetCallRequest is the view call of starknet_call
+type StarknetCallRequest struct {
+	ContractAddress common.Address32 `json:"contract_address"`
+	Calldata        hexutil.Bytes    `json:"calldata"`
+}
+
+// Call implements starknet_call. Executes a view of a contract on top of the state of the given block,
+// nothing is written to the state.
+func (api *StarknetImpl) Call(ctx context.Context, request StarknetCallRequest, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if api.runner == nil {
+		return nil, errors.New("starknet_call requires a cairo runner, see --starknet.grpc.address")
+	}
+	addr, err := starknetAddress(request.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	blockNumber, hash, _, err := rpchelper.GetCanonicalBlockNumber(blockNrOrHash, tx, api.filters)
+	if err != nil {
+		return nil, err
+	}
+	block, err := api.blockWithSenders(tx, hash, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	stateReader, err := rpchelper.CreateStateReader(ctx, tx, blockNrOrHash, api.filters, api.stateCache, api.historyV2(tx), api._agg, api._txNums)
+	if err != nil {
+		return nil, err
+	}
+
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		h, e := api._blockReader.Header(ctx, tx, hash, number)
+		if e != nil {
+			log.Error("getHeader error", "number", number, "hash", hash, "err", e)
+		}
+		return h
+	}
+	header := block.Header()
+	blockCtx := core.NewEVMBlockContext(header, core.GetHashFn(header, getHeader), ethash.NewFaker(), &header.Coinbase)
+	cvm := vm.NewCVM(blockCtx, state.New(stateReader), chainConfig, vm.Config{CairoRunner: api.runner})
+
+	ret, _, err := cvm.Call(vm.AccountRef(common.Address{}), addr, request.Calldata, api.eth.GasCap, new(uint256.Int), false)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
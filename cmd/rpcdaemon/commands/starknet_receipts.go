@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+const (
+	StarknetStatusSucceeded = "SUCCEEDED"
+	StarknetStatusReverted  = "REVERTED"
+)
+
+// StarknetEvent is an event emitted by a Starknet contract
+type StarknetEvent struct {
+	FromAddress common.Address32 `json:"from_address"`
+	Keys        []common.Hash    `json:"keys"`
+	Data        hexutil.Bytes    `json:"data"`
+}
+
+// StarknetReceipt is the receipt of a Starknet transaction
+type StarknetReceipt struct {
+	TransactionHash  common.Hash       `json:"transaction_hash"`
+	TransactionIndex hexutil.Uint64    `json:"transaction_index"`
+	BlockHash        common.Hash       `json:"block_hash"`
+	BlockNumber      hexutil.Uint64    `json:"block_number"`
+	Status           string            `json:"status"`
+	ActualFee        *hexutil.Big      `json:"actual_fee"`
+	ContractAddress  *common.Address32 `json:"contract_address,omitempty"`
+	Events           []StarknetEvent   `json:"events"`
+}
+
+// GetTransactionReceipt implements starknet_getTransactionReceipt. Returns the receipt of a Starknet transaction.
+func (api *StarknetImpl) GetTransactionReceipt(ctx context.Context, txnHash common.Hash) (*StarknetReceipt, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	blockNum, ok, err := api.txnLookup(ctx, tx, txnHash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	block, err := api.blockByNumberWithSenders(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil // not error, see https://github.com/ledgerwatch/erigon/issues/1645
+	}
+
+	var txnIndex uint64
+	var txn types.Transaction
+	for idx, transaction := range block.Transactions() {
+		if transaction.Hash() == txnHash {
+			txn = transaction
+			txnIndex = uint64(idx)
+			break
+		}
+	}
+	if txn == nil {
+		return nil, nil
+	}
+	if !txn.IsStarkNet() {
+		return nil, fmt.Errorf("transaction %x is not a starknet transaction, use eth_getTransactionReceipt instead", txnHash)
+	}
+
+	cc, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := api.getReceiptsWithVMConfig(ctx, tx, cc, block, block.Body().SendersFromTxs(), vm.Config{CairoRunner: api.runner})
+	if err != nil {
+		return nil, fmt.Errorf("getReceipts error: %w", err)
+	}
+	if len(receipts) <= int(txnIndex) {
+		return nil, fmt.Errorf("block has less receipts than expected: %d <= %d, block: %d", len(receipts), int(txnIndex), blockNum)
+	}
+	return marshalStarknetReceipt(receipts[txnIndex], txn, cc, block), nil
+}
+
+func marshalStarknetReceipt(receipt *types.Receipt, txn types.Transaction, chainConfig *params.ChainConfig, block *types.Block) *StarknetReceipt {
+	var gasPrice *big.Int
+	if !chainConfig.IsLondon(block.NumberU64()) {
+		gasPrice = txn.GetPrice().ToBig()
+	} else {
+		baseFee, _ := uint256.FromBig(block.BaseFee())
+		gasPrice = new(big.Int).Add(block.BaseFee(), txn.GetEffectiveGasTip(baseFee).ToBig())
+	}
+
+	res := &StarknetReceipt{
+		TransactionHash:  txn.Hash(),
+		TransactionIndex: hexutil.Uint64(receipt.TransactionIndex),
+		BlockHash:        block.Hash(),
+		BlockNumber:      hexutil.Uint64(block.NumberU64()),
+		Status:           StarknetStatusSucceeded,
+		ActualFee:        (*hexutil.Big)(new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))),
+		Events:           make([]StarknetEvent, 0, len(receipt.Logs)),
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		res.Status = StarknetStatusReverted
+	}
+	// If the ContractAddress is 20 0x0 bytes, assume it is not a deploy
+	if receipt.ContractAddress != (common.Address{}) {
+		contract := common.BytesToAddress32(receipt.ContractAddress.Bytes())
+		res.ContractAddress = &contract
+	}
+	for _, l := range receipt.Logs {
+		res.Events = append(res.Events, StarknetEvent{
+			FromAddress: common.BytesToAddress32(l.Address.Bytes()),
+			Keys:        l.Topics,
+			Data:        l.Data,
+		})
+	}
+	return res
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// StarknetTransactionResult is the response of starknet_addDeployTransaction and starknet_addInvokeTransaction
+type StarknetTransactionResult struct {
+	TransactionHash common.Hash       `json:"transaction_hash"`
+	ContractAddress *common.Address32 `json:"contract_address,omitempty"`
+}
+
+// AddDeployTransaction implements starknet_addDeployTransaction. Submits a signed Starknet transaction deploying a new contract.
+func (api *StarknetImpl) AddDeployTransaction(ctx context.Context, encodedTx hexutil.Bytes) (*StarknetTransactionResult, error) {
+	txn, err := decodeStarknetTransaction(encodedTx)
+	if err != nil {
+		return nil, err
+	}
+	if txn.GetTo() != nil {
+		return nil, errors.New("deploy transaction must not have a recipient, use starknet_addInvokeTransaction instead")
+	}
+
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	cc, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	from, err := txn.Sender(*types.LatestSigner(cc))
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := api.eth.SendRawTransaction(ctx, encodedTx)
+	if err != nil {
+		return nil, err
+	}
+	contract := common.BytesToAddress32(crypto.CreateAddress(from, txn.GetNonce()).Bytes())
+	return &StarknetTransactionResult{TransactionHash: hash, ContractAddress: &contract}, nil
+}
+
+// AddInvokeTransaction implements starknet_addInvokeTransaction. Submits a signed Starknet transaction invoking a deployed contract.
+func (api *StarknetImpl) AddInvokeTransaction(ctx context.Context, encodedTx hexutil.Bytes) (*StarknetTransactionResult, error) {
+	txn, err := decodeStarknetTransaction(encodedTx)
+	if err != nil {
+		return nil, err
+	}
+	if txn.GetTo() == nil {
+		return nil, errors.New("invoke transaction must have a recipient, use starknet_addDeployTransaction instead")
+	}
+
+	hash, err := api.eth.SendRawTransaction(ctx, encodedTx)
+	if err != nil {
+		return nil, err
+	}
+	return &StarknetTransactionResult{TransactionHash: hash}, nil
+}
+
+func decodeStarknetTransaction(encodedTx hexutil.Bytes) (*types.StarknetTransaction, error) {
+	txn, err := types.DecodeTransaction(rlp.NewStream(bytes.NewReader(encodedTx), uint64(len(encodedTx))))
+	if err != nil {
+		return nil, err
+	}
+	starknetTxn, ok := txn.(*types.StarknetTransaction)
+	if !ok {
+		return nil, fmt.Errorf("expected a starknet transaction, got transaction of type %d", txn.Type())
+	}
+	return starknetTxn, nil
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
-	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/ethdb"
 	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
 	"github.com/ledgerwatch/erigon/params"
@@ -735,7 +734,7 @@ func (api *TraceAPIImpl) filter22(ctx context.Context, dbtx kv.Tx, fromBlock, to
 		stateCache := shards.NewStateCache(32, 0 /* no limit */) // this cache living only during current RPC call, but required to store state writes
 		cachedReader := state.NewCachedReader(stateReader, stateCache)
 		cachedWriter := state.NewCachedWriter(noop, stateCache)
-		vmConfig := vm.Config{}
+		vmConfig := api.vmConfig()
 		vmConfig.SkipAnalysis = core.SkipAnalysis(chainConfig, blockNum)
 		traceResult := &TraceCallResult{Trace: []*ParityTrace{}}
 		var ot OeTracer
@@ -746,7 +745,7 @@ func (api *TraceAPIImpl) filter22(ctx context.Context, dbtx kv.Tx, fromBlock, to
 		vmConfig.Debug = true
 		vmConfig.Tracer = &ot
 		ibs := state.New(cachedReader)
-		evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vmConfig)
+		evm := core.NewVMForTx(blockCtx, txCtx, ibs, chainConfig, vmConfig, txn)
 
 		gp := new(core.GasPool).AddGas(msg.Gas())
 		ibs.Prepare(txHash, lastBlockHash, int(txIndex))
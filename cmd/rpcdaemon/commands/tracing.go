@@ -83,7 +83,7 @@ func (api *PrivateDebugAPIImpl) traceBlock(ctx context.Context, blockNrOrHash rp
 		return h
 	}
 
-	_, blockCtx, _, ibs, reader, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), 0)
+	_, blockCtx, _, ibs, reader, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), 0, api.vmConfig())
 	if err != nil {
 		stream.WriteNil()
 		return err
@@ -180,7 +180,7 @@ func (api *PrivateDebugAPIImpl) TraceTransaction(ctx context.Context, hash commo
 	getHeader := func(hash common.Hash, number uint64) *types.Header {
 		return rawdb.ReadHeader(tx, hash, number)
 	}
-	msg, blockCtx, txCtx, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, blockHash, txnIndex)
+	msg, blockCtx, txCtx, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, blockHash, txnIndex, api.vmConfig())
 	if err != nil {
 		stream.WriteNil()
 		return err
@@ -239,12 +239,24 @@ func (api *PrivateDebugAPIImpl) TraceCall(ctx context.Context, args ethapi.CallA
 			return fmt.Errorf("header.BaseFee uint256 overflow")
 		}
 	}
+	var blockOverrides *ethapi.BlockOverrides
+	if config != nil {
+		blockOverrides = config.BlockOverrides
+	}
+	if baseFee, err = blockOverrides.OverrideBaseFee(baseFee); err != nil {
+		return err
+	}
 	msg, err := args.ToMessage(api.GasCap, baseFee)
 	if err != nil {
 		return err
 	}
 
 	blockCtx, txCtx := transactions.GetEvmContext(msg, header, blockNrOrHash.RequireCanonical, dbtx, api._blockReader)
+	if blockOverrides != nil {
+		if err := blockOverrides.Override(&blockCtx); err != nil {
+			return err
+		}
+	}
 	// Trace the transaction and return
 	return transactions.TraceTx(ctx, msg, blockCtx, txCtx, ibs, config, chainConfig, stream, api.evmCallTimeout)
 }
@@ -0,0 +1,1222 @@
+// Package graphql implements the EIP-1767 GraphQL interface on top of the rpcdaemon APIs
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/filters"
+	"github.com/ledgerwatch/erigon/internal/ethapi"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/rpc"
+)
+
+// Account represents an Ethereum account at a particular block.
+type Account struct {
+	r             *Resolver
+	address       common.Address
+	blockNrOrHash rpc.BlockNumberOrHash
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	balance, err := a.r.backend.GetBalance(ctx, a.address, a.blockNrOrHash)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *balance, nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	nonce, err := a.r.backend.GetTransactionCount(ctx, a.address, a.blockNrOrHash)
+	if err != nil {
+		return 0, err
+	}
+	return *nonce, nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	return a.r.backend.GetCode(ctx, a.address, a.blockNrOrHash)
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	value, err := a.r.backend.GetStorageAt(ctx, a.address, args.Slot.Hex(), a.blockNrOrHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.HexToHash(value), nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	r           *Resolver
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		r:             l.r,
+		address:       l.log.Address,
+		blockNrOrHash: args.NumberOrLatest(),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return l.log.Data
+}
+
+// AccessTuple represents EIP-2930
+type AccessTuple struct {
+	address     common.Address
+	storageKeys []common.Hash
+}
+
+func (at *AccessTuple) Address(ctx context.Context) common.Address {
+	return at.address
+}
+
+func (at *AccessTuple) StorageKeys(ctx context.Context) []common.Hash {
+	return at.storageKeys
+}
+
+// Transaction represents an Ethereum transaction.
+// hash must be provided. All other fields are lazily populated.
+type Transaction struct {
+	r     *Resolver
+	hash  common.Hash
+	mu    sync.Mutex
+	txn   types.Transaction
+	block *Block
+	index uint64
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+// It also returns the block the txn belongs to, or nil if the transaction
+// is not mined yet.
+func (t *Transaction) resolve(ctx context.Context) (types.Transaction, *Block, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.txn != nil {
+		return t.txn, t.block, nil
+	}
+	block, index, err := t.r.backend.GetTransactionBlock(ctx, t.hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if block == nil {
+		return nil, nil, nil
+	}
+	t.txn = block.Transactions()[index]
+	t.index = index
+	t.block = &Block{
+		r:             t.r,
+		blockNrOrHash: rpc.BlockNumberOrHashWithHash(block.Hash(), false),
+		hash:          block.Hash(),
+		block:         block,
+	}
+	return t.txn, t.block, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return hexutil.Bytes{}, err
+	}
+	return txn.GetData(), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(txn.GetGas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	txn, block, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return hexutil.Big{}, err
+	}
+	if txn.Type() == types.DynamicFeeTxType && block != nil {
+		if baseFee := block.block.BaseFee(); baseFee != nil {
+			// price = min(tip, gasFeeCap - baseFee) + baseFee
+			return (hexutil.Big)(*effectiveGasPrice(txn, baseFee)), nil
+		}
+	}
+	return (hexutil.Big)(*txn.GetPrice().ToBig()), nil
+}
+
+func (t *Transaction) EffectiveGasPrice(ctx context.Context) (*hexutil.Big, error) {
+	txn, block, err := t.resolve(ctx)
+	if err != nil || txn == nil || block == nil {
+		return nil, err
+	}
+	baseFee := block.block.BaseFee()
+	if baseFee == nil {
+		return (*hexutil.Big)(txn.GetPrice().ToBig()), nil
+	}
+	return (*hexutil.Big)(effectiveGasPrice(txn, baseFee)), nil
+}
+
+func (t *Transaction) MaxFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return nil, err
+	}
+	if txn.Type() != types.DynamicFeeTxType {
+		return nil, nil
+	}
+	return (*hexutil.Big)(txn.GetFeeCap().ToBig()), nil
+}
+
+func (t *Transaction) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return nil, err
+	}
+	if txn.Type() != types.DynamicFeeTxType {
+		return nil, nil
+	}
+	return (*hexutil.Big)(txn.GetTip().ToBig()), nil
+}
+
+func (t *Transaction) EffectiveTip(ctx context.Context) (*hexutil.Big, error) {
+	txn, block, err := t.resolve(ctx)
+	if err != nil || txn == nil || block == nil {
+		return nil, err
+	}
+	baseFee := block.block.BaseFee()
+	if baseFee == nil {
+		return (*hexutil.Big)(txn.GetPrice().ToBig()), nil
+	}
+	baseFee256, overflow := uint256.FromBig(baseFee)
+	if overflow {
+		return nil, fmt.Errorf("base fee overflow: %v", baseFee)
+	}
+	return (*hexutil.Big)(txn.GetEffectiveGasTip(baseFee256).ToBig()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*txn.GetValue().ToBig()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (hexutil.Uint64, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(txn.GetNonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return nil, err
+	}
+	to := txn.GetTo()
+	if to == nil {
+		return nil, nil
+	}
+	return &Account{
+		r:             t.r,
+		address:       *to,
+		blockNrOrHash: args.NumberOrLatest(),
+	}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return nil, err
+	}
+	from, ok := txn.GetSender()
+	if !ok {
+		return nil, fmt.Errorf("sender of transaction %x is not known", t.hash)
+	}
+	return &Account{
+		r:             t.r,
+		address:       from,
+		blockNrOrHash: args.NumberOrLatest(),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	_, block, err := t.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	_, block, err := t.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+// getReceipt returns the receipt associated with this transaction, if any.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	_, block, err := t.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	receipts, err := block.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.Status)
+	return &ret, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.GasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.CumulativeGasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil || txn.GetTo() != nil {
+		return nil, err
+	}
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	return &Account{
+		r:             t.r,
+		address:       receipt.ContractAddress,
+		blockNrOrHash: args.NumberOrLatest(),
+	}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{
+			r:           t.r,
+			transaction: t,
+			log:         log,
+		})
+	}
+	return &ret, nil
+}
+
+func (t *Transaction) Type(ctx context.Context) (*int32, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return nil, err
+	}
+	txType := int32(txn.Type())
+	return &txType, nil
+}
+
+func (t *Transaction) AccessList(ctx context.Context) (*[]*AccessTuple, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return nil, err
+	}
+	accessList := txn.GetAccessList()
+	ret := make([]*AccessTuple, 0, len(accessList))
+	for _, al := range accessList {
+		ret = append(ret, &AccessTuple{
+			address:     al.Address,
+			storageKeys: al.StorageKeys,
+		})
+	}
+	return &ret, nil
+}
+
+func (t *Transaction) R(ctx context.Context) (hexutil.Big, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return hexutil.Big{}, err
+	}
+	_, r, _ := txn.RawSignatureValues()
+	return hexutil.Big(*r.ToBig()), nil
+}
+
+func (t *Transaction) S(ctx context.Context) (hexutil.Big, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return hexutil.Big{}, err
+	}
+	_, _, s := txn.RawSignatureValues()
+	return hexutil.Big(*s.ToBig()), nil
+}
+
+func (t *Transaction) V(ctx context.Context) (hexutil.Big, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return hexutil.Big{}, err
+	}
+	v, _, _ := txn.RawSignatureValues()
+	return hexutil.Big(*v.ToBig()), nil
+}
+
+func (t *Transaction) Raw(ctx context.Context) (hexutil.Bytes, error) {
+	txn, _, err := t.resolve(ctx)
+	if err != nil || txn == nil {
+		return hexutil.Bytes{}, err
+	}
+	var buf bytes.Buffer
+	if err := txn.MarshalBinary(&buf); err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *Transaction) RawReceipt(ctx context.Context) (hexutil.Bytes, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return hexutil.Bytes{}, err
+	}
+	enc, err := rlp.EncodeToBytes(receipt)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	if receipt.Type != types.LegacyTxType {
+		// typed receipts are RLP-wrapped into a string, the canonical encoding is its content
+		if enc, _, err = rlp.SplitString(enc); err != nil {
+			return hexutil.Bytes{}, err
+		}
+	}
+	return enc, nil
+}
+
+// Block represents an Ethereum block.
+// blockNrOrHash must be set to the block to resolve, and hash is filled in
+// once the block is known. All other fields are lazily populated.
+type Block struct {
+	r             *Resolver
+	blockNrOrHash rpc.BlockNumberOrHash
+	mu            sync.Mutex
+	hash          common.Hash
+	block         *types.Block
+	receipts      types.Receipts
+}
+
+// resolve returns the internal Block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.block != nil {
+		return b.block, nil
+	}
+	block, err := b.r.backend.GetBlock(ctx, b.blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block != nil {
+		b.block = block
+		b.hash = block.Hash()
+	}
+	return b.block, nil
+}
+
+// resolveReceipts returns the list of receipts for this block, fetching them
+// if necessary.
+func (b *Block) resolveReceipts(ctx context.Context) (types.Receipts, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.receipts == nil {
+		receipts, err := b.r.backend.GetReceipts(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = receipts
+	}
+	return b.receipts, nil
+}
+
+// numberOrHash returns the state reference of the block for the account and call
+// resolvers, preferring the hash once the block has been resolved.
+func (b *Block) numberOrHash(ctx context.Context) (rpc.BlockNumberOrHash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return rpc.BlockNumberOrHash{}, err
+	}
+	if block == nil {
+		return b.blockNrOrHash, nil
+	}
+	return rpc.BlockNumberOrHashWithHash(block.Hash(), false), nil
+}
+
+func (b *Block) Number(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.NumberU64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.Hash(), nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.GasLimit()), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.GasUsed()), nil
+}
+
+func (b *Block) BaseFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	if block.BaseFee() == nil {
+		return nil, nil
+	}
+	return (*hexutil.Big)(block.BaseFee()), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil || block.NumberU64() == 0 {
+		return nil, err
+	}
+	return &Block{
+		r:             b.r,
+		blockNrOrHash: rpc.BlockNumberOrHashWithHash(block.ParentHash(), false),
+		hash:          block.ParentHash(),
+	}, nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Difficulty()), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.Time()), nil
+}
+
+func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	nonce := block.Nonce()
+	return nonce[:], nil
+}
+
+func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.MixDigest(), nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.TxHash(), nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.Root(), nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.ReceiptHash(), nil
+}
+
+func (b *Block) OmmerHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.UncleHash(), nil
+}
+
+func (b *Block) OmmerCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	count := int32(len(block.Uncles()))
+	return &count, nil
+}
+
+func (b *Block) Ommers(ctx context.Context) (*[]*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*Block, 0, len(block.Uncles()))
+	for _, uncle := range block.Uncles() {
+		ret = append(ret, b.ommer(uncle))
+	}
+	return &ret, nil
+}
+
+func (b *Block) OmmerAt(ctx context.Context, args struct{ Index int32 }) (*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	uncles := block.Uncles()
+	if args.Index < 0 || int(args.Index) >= len(uncles) {
+		return nil, nil
+	}
+	return b.ommer(uncles[args.Index]), nil
+}
+
+// ommer wraps the ommer header into a Block, which has no transactions and ommers
+func (b *Block) ommer(uncle *types.Header) *Block {
+	block := types.NewBlockWithHeader(uncle)
+	return &Block{
+		r:             b.r,
+		blockNrOrHash: rpc.BlockNumberOrHashWithHash(block.Hash(), false),
+		hash:          block.Hash(),
+		block:         block,
+	}
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	return block.Extra(), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	bloom := block.Bloom()
+	return bloom.Bytes(), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Big{}, err
+	}
+	td, err := b.r.backend.GetBlockTd(ctx, block.Hash(), block.NumberU64())
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	if td == nil {
+		return hexutil.Big{}, fmt.Errorf("total difficulty of block %d(%x) not found", block.NumberU64(), block.Hash())
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) RawHeader(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	return rlp.EncodeToBytes(block.Header())
+}
+
+func (b *Block) Raw(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	return rlp.EncodeToBytes(block)
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	// TODO: Ideally we could use input unions to allow the query to specify the
+	// block parameter by hash, block number, or tag but input unions aren't part of the
+	// standard GraphQL schema SDL yet, see: https://github.com/graphql/graphql-spec/issues/488
+	Block *hexutil.Uint64
+}
+
+// NumberOr returns the provided block number argument, or the "current" block number or hash if none
+// was provided.
+func (a BlockNumberArgs) NumberOr(current rpc.BlockNumberOrHash) rpc.BlockNumberOrHash {
+	if a.Block != nil {
+		blockNr := rpc.BlockNumber(*a.Block)
+		return rpc.BlockNumberOrHashWithNumber(blockNr)
+	}
+	return current
+}
+
+// NumberOrLatest returns the provided block number argument, or the "latest" block number if none
+// was provided.
+func (a BlockNumberArgs) NumberOrLatest() rpc.BlockNumberOrHash {
+	return a.NumberOr(rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return &Account{
+		r:             b.r,
+		address:       block.Coinbase(),
+		blockNrOrHash: args.NumberOrLatest(),
+	}, nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	count := int32(len(block.Transactions()))
+	return &count, nil
+}
+
+func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(block.Transactions()))
+	for i, txn := range block.Transactions() {
+		ret = append(ret, &Transaction{
+			r:     b.r,
+			hash:  txn.Hash(),
+			txn:   txn,
+			block: b,
+			index: uint64(i),
+		})
+	}
+	return &ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	txn := txs[args.Index]
+	return &Transaction{
+		r:     b.r,
+		hash:  txn.Hash(),
+		txn:   txn,
+		block: b,
+		index: uint64(args.Index),
+	}, nil
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
+// a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	//
+	// Examples:
+	// {} or nil          matches any topic list
+	// {{A}}              matches topic A in first position
+	// {{}, {B}}          matches any topic in first position, B in second position
+	// {{A}, {B}}         matches topic A in first position, B in second position
+	// {{A, B}}, {C, D}}  matches topic (A OR B) in first position, (C OR D) in second position
+	Topics *[][]common.Hash
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	txs, err := b.Transactions(ctx)
+	if err != nil || txs == nil {
+		return nil, err
+	}
+	receipts, err := b.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*Log
+	for i, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if !matchLog(log, addresses, topics) {
+				continue
+			}
+			ret = append(ret, &Log{
+				r:           b.r,
+				transaction: (*txs)[i],
+				log:         log,
+			})
+		}
+	}
+	return ret, nil
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	blockNrOrHash, err := b.numberOrHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		r:             b.r,
+		address:       args.Address,
+		blockNrOrHash: blockNrOrHash,
+	}, nil
+}
+
+// CallResult encapsulates the result of an invocation of the `call` accessor.
+type CallResult struct {
+	data    hexutil.Bytes  // The return data from the call
+	gasUsed hexutil.Uint64 // The amount of gas used
+	status  hexutil.Uint64 // The return status of the call - 0 for failure or 1 for success.
+}
+
+func (c *CallResult) Data() hexutil.Bytes {
+	return c.data
+}
+
+func (c *CallResult) GasUsed() hexutil.Uint64 {
+	return c.gasUsed
+}
+
+func (c *CallResult) Status() hexutil.Uint64 {
+	return c.status
+}
+
+func (b *Block) Call(ctx context.Context, args struct{ Data ethapi.CallArgs }) (*CallResult, error) {
+	blockNrOrHash, err := b.numberOrHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b.r.call(ctx, args.Data, blockNrOrHash)
+}
+
+func (b *Block) EstimateGas(ctx context.Context, args struct{ Data ethapi.CallArgs }) (hexutil.Uint64, error) {
+	blockNrOrHash, err := b.numberOrHash(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return b.r.backend.EstimateGas(ctx, &args.Data, &blockNrOrHash, nil)
+}
+
+// Pending represents the pending state of the chain.
+type Pending struct {
+	r *Resolver
+}
+
+func (p *Pending) block() *Block {
+	return &Block{
+		r:             p.r,
+		blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber),
+	}
+}
+
+func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	count, err := p.block().TransactionCount(ctx)
+	if err != nil || count == nil {
+		return 0, err
+	}
+	return *count, nil
+}
+
+func (p *Pending) Transactions(ctx context.Context) (*[]*Transaction, error) {
+	return p.block().Transactions(ctx)
+}
+
+func (p *Pending) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{
+		r:             p.r,
+		address:       args.Address,
+		blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber),
+	}
+}
+
+func (p *Pending) Call(ctx context.Context, args struct{ Data ethapi.CallArgs }) (*CallResult, error) {
+	return p.r.call(ctx, args.Data, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber))
+}
+
+func (p *Pending) EstimateGas(ctx context.Context, args struct{ Data ethapi.CallArgs }) (hexutil.Uint64, error) {
+	blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	return p.r.backend.EstimateGas(ctx, &args.Data, &blockNrOrHash, nil)
+}
+
+// maxBlocksRange is the maximum number of blocks returned by a single `blocks` query, every one of them is
+// resolved from the database
+const maxBlocksRange = 1024
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend commands.GraphQLAPI
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	var blockNrOrHash rpc.BlockNumberOrHash
+	switch {
+	case args.Number != nil && args.Hash != nil:
+		return nil, errors.New("only one of number or hash must be specified")
+	case args.Hash != nil:
+		blockNrOrHash = rpc.BlockNumberOrHashWithHash(*args.Hash, false)
+	case args.Number != nil:
+		blockNrOrHash = rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(*args.Number))
+	default:
+		blockNrOrHash = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	}
+	block := &Block{
+		r:             r,
+		blockNrOrHash: blockNrOrHash,
+	}
+	// Resolve the block, return nil if it doesn't exist.
+	b, err := block.resolve(ctx)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From *hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*Block, error) {
+	if args.From == nil {
+		return nil, errors.New("from block number must be specified")
+	}
+	from := uint64(*args.From)
+	latest, err := r.backend.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	to := uint64(latest)
+	// Blocks after the head don't count towards the range limit
+	if args.To != nil && uint64(*args.To) < to {
+		to = uint64(*args.To)
+	}
+	if to < from {
+		return []*Block{}, nil
+	}
+	if to-from >= maxBlocksRange {
+		return nil, fmt.Errorf("block range %d-%d exceeds the maximum of %d blocks", from, to, maxBlocksRange)
+	}
+	ret := make([]*Block, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		block := &Block{
+			r:             r,
+			blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(i)),
+		}
+		// Resolve the block, stop at the first missing one.
+		b, err := block.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			break
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Pending(ctx context.Context) *Pending {
+	return &Pending{r: r}
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	txn := &Transaction{
+		r:    r,
+		hash: args.Hash,
+	}
+	// Resolve the transaction; if it doesn't exist, return nil.
+	t, _, err := txn.resolve(ctx)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func (r *Resolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	return r.backend.SendRawTransaction(ctx, args.Data)
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *hexutil.Uint64   // beginning of the queried range, nil means latest block
+	ToBlock   *hexutil.Uint64   // end of the range, nil means latest block
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	//
+	// Examples:
+	// {} or nil          matches any topic list
+	// {{A}}              matches topic A in first position
+	// {{}, {B}}          matches any topic in first position, B in second position
+	// {{A}, {B}}         matches topic A in first position, B in second position
+	// {{A, B}}, {C, D}}  matches topic (A OR B) in first position, (C OR D) in second position
+	Topics *[][]common.Hash
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	var crit filters.FilterCriteria
+	if args.Filter.FromBlock != nil {
+		crit.FromBlock = new(big.Int).SetUint64(uint64(*args.Filter.FromBlock))
+	}
+	if args.Filter.ToBlock != nil {
+		crit.ToBlock = new(big.Int).SetUint64(uint64(*args.Filter.ToBlock))
+	}
+	if args.Filter.Addresses != nil {
+		crit.Addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		crit.Topics = *args.Filter.Topics
+	}
+	logs, err := r.backend.GetLogs(ctx, crit)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(logs))
+	for _, log := range logs {
+		ret = append(ret, &Log{
+			r:           r,
+			transaction: &Transaction{r: r, hash: log.TxHash},
+			log:         log,
+		})
+	}
+	return ret, nil
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.GasPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *price, nil
+}
+
+func (r *Resolver) MaxPriorityFeePerGas(ctx context.Context) (hexutil.Big, error) {
+	tip, err := r.backend.MaxPriorityFeePerGas(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *tip, nil
+}
+
+func (r *Resolver) ChainID(ctx context.Context) (hexutil.Big, error) {
+	chainID, err := r.backend.ChainId(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*new(big.Int).SetUint64(uint64(chainID))), nil
+}
+
+// SyncState represents the synchronisation status returned from the `syncing` accessor.
+type SyncState struct {
+	progress map[string]interface{}
+}
+
+func (s *SyncState) StartingBlock() hexutil.Uint64 {
+	return s.get("startingBlock")
+}
+
+func (s *SyncState) CurrentBlock() hexutil.Uint64 {
+	return s.get("currentBlock")
+}
+
+func (s *SyncState) HighestBlock() hexutil.Uint64 {
+	return s.get("highestBlock")
+}
+
+func (s *SyncState) get(field string) hexutil.Uint64 {
+	value, _ := s.progress[field].(hexutil.Uint64)
+	return value
+}
+
+// Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
+// yet received the latest block headers from its pears. In case it is synchronizing:
+// - startingBlock: block number this node started to synchronise from
+// - currentBlock:  block number this node is currently importing
+// - highestBlock:  block number of the highest block header this node has received from peers
+func (r *Resolver) Syncing(ctx context.Context) (*SyncState, error) {
+	syncing, err := r.backend.Syncing(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress, ok := syncing.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	return &SyncState{progress: progress}, nil
+}
+
+// call executes the call at the given state and wraps its result, failed
+// executions are reported by status instead of an error.
+func (r *Resolver) call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (*CallResult, error) {
+	result, err := r.backend.DoCall(ctx, args, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(1)
+	if result.Failed() {
+		status = 0
+	}
+	return &CallResult{
+		data:    result.ReturnData,
+		gasUsed: hexutil.Uint64(result.UsedGas),
+		status:  status,
+	}, nil
+}
+
+// effectiveGasPrice returns the price per gas paid by the dynamic fee transaction
+// included in a block with the given base fee: min(tip, feeCap - baseFee) + baseFee
+func effectiveGasPrice(txn types.Transaction, baseFee *big.Int) *big.Int {
+	tip := txn.GetTip().ToBig()
+	if gap := new(big.Int).Sub(txn.GetFeeCap().ToBig(), baseFee); gap.Cmp(tip) < 0 {
+		tip = gap
+	}
+	return tip.Add(tip, baseFee)
+}
+
+// matchLog reports whether the log matches the given address and topic filters,
+// with the semantics of eth_getLogs
+func matchLog(log *types.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		found := false
+		for _, addr := range addresses {
+			if log.Address == addr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, sub := range topics {
+		if len(sub) == 0 {
+			continue // empty rule set == wildcard
+		}
+		match := false
+		for _, topic := range sub {
+			if log.Topics[i] == topic {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
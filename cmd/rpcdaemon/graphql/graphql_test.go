@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv/kvcache"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/ledgerwatch/erigon/rpc/rpccfg"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newTestHandler(t *testing.T) http.Handler {
+	db := rpcdaemontest.CreateTestKV(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	base := commands.NewBaseApi(nil, stateCache, snapshotsync.NewBlockReader(), nil, nil, false, rpccfg.DefaultEvmCallTimeout)
+	handler, err := CreateHandler(commands.NewGraphQLAPI(commands.NewEthAPI(base, db, nil, nil, nil, 5000000)))
+	require.NoError(t, err)
+	return handler
+}
+
+func query(t *testing.T, handler http.Handler, q string) graphQLResponse {
+	body, err := json.Marshal(map[string]string{"query": q})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp graphQLResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestGraphQLBlock(t *testing.T) {
+	handler := newTestHandler(t)
+
+	resp := query(t, handler, `{ block(number: 1) { number transactionCount parent { number } transactions { index } } }`)
+	require.Empty(t, resp.Errors)
+	var data struct {
+		Block struct {
+			Number           string
+			TransactionCount int
+			Parent           struct{ Number string }
+			Transactions     []struct{ Index int }
+		}
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &data))
+	require.Equal(t, "0x1", data.Block.Number)
+	require.Equal(t, "0x0", data.Block.Parent.Number)
+	require.NotZero(t, data.Block.TransactionCount)
+	require.Len(t, data.Block.Transactions, data.Block.TransactionCount)
+
+	resp = query(t, handler, `{ block(number: 1000) { number } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"block": null}`, string(resp.Data))
+}
+
+func TestGraphQLBlocks(t *testing.T) {
+	handler := newTestHandler(t)
+
+	resp := query(t, handler, `{ blocks(from: 2, to: 4) { number } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"blocks": [{"number": "0x2"}, {"number": "0x3"}, {"number": "0x4"}]}`, string(resp.Data))
+
+	// The range stops at the latest block
+	resp = query(t, handler, `{ blocks(from: 9, to: 20) { number } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"blocks": [{"number": "0x9"}, {"number": "0xa"}]}`, string(resp.Data))
+
+	resp = query(t, handler, `{ blocks(from: 4, to: 2) { number } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"blocks": []}`, string(resp.Data))
+
+	// The range limit applies to the blocks up to the latest one
+	resp = query(t, handler, `{ blocks(from: 9, to: 5000) { number } }`)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"blocks": [{"number": "0x9"}, {"number": "0xa"}]}`, string(resp.Data))
+	resp = query(t, handler, `{ blocks(from: 0) { number } }`)
+	require.Empty(t, resp.Errors)
+}
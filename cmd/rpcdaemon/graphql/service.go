@@ -0,0 +1,20 @@
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
+)
+
+// CreateHandler parses the EIP-1767 schema and returns the http handler serving
+// GraphQL queries against the given backend
+func CreateHandler(backend commands.GraphQLAPI) (http.Handler, error) {
+	s, err := graphql.ParseSchema(schema, &Resolver{backend: backend})
+	if err != nil {
+		return nil, err
+	}
+	return &relay.Handler{Schema: s}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ledgerwatch/erigon-lib/common"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/cli"
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
 	"github.com/ledgerwatch/log/v3"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +27,25 @@ func main() {
 			defer borDb.Close()
 		}
 
-		apiList := commands.APIList(db, borDb, backend, txPool, mining, ff, stateCache, blockReader, agg, txNums, *cfg)
+		accountManager, err := cli.OpenAccounts(*cfg)
+		if err != nil {
+			log.Error("Could not open signing accounts", "err", err)
+			return nil
+		}
+		defer accountManager.Close()
+
+		cairoConn, err := cli.DialCairoRunner(ctx, *cfg)
+		if err != nil {
+			log.Error("Could not connect to the cairo runner", "err", err)
+			return nil
+		}
+		var cairoRunner cairo.Runner
+		if cairoConn != nil {
+			defer cairoConn.Close()
+			cairoRunner = cairo.NewGRPCRunner(cairoConn)
+		}
+
+		apiList := commands.APIList(db, borDb, backend, txPool, mining, ff, stateCache, blockReader, agg, txNums, accountManager, cairoRunner, *cfg)
 		if err := cli.StartRpcServer(ctx, *cfg, apiList, nil); err != nil {
 			log.Error(err.Error())
 			return nil
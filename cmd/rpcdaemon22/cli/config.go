@@ -37,6 +37,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/common/paths"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/ethdb/privateapi"
 	"github.com/ledgerwatch/erigon/internal/debug"
 	"github.com/ledgerwatch/erigon/node"
 	"github.com/ledgerwatch/erigon/node/nodecfg"
@@ -229,7 +230,14 @@ func EmbeddedServices(ctx context.Context, erigonDB kv.RoDB, stateCacheCfg kvcac
 
 	directClient := direct.NewEthBackendClientDirect(ethBackendServer)
 
-	eth = rpcservices.NewRemoteBackend(directClient, erigonDB, blockReader)
+	remoteBackend := rpcservices.NewRemoteBackend(directClient, erigonDB, blockReader)
+	if engineV2, ok := ethBackendServer.(privateapi.EngineV2); ok {
+		remoteBackend.SetEngineV2(engineV2)
+	}
+	if peerAdmin, ok := ethBackendServer.(privateapi.PeerAdmin); ok {
+		remoteBackend.SetPeerAdmin(peerAdmin)
+	}
+	eth = remoteBackend
 	txPool = direct.NewTxPoolClient(txPoolServer)
 	mining = direct.NewMiningClient(miningServer)
 	ff = rpchelper.New(ctx, eth, txPool, mining, func() {})
@@ -371,6 +379,7 @@ func RemoteServices(ctx context.Context, cfg httpcfg.HttpCfg, logger log.Logger,
 		blockReader = snapshotsync.NewRemoteBlockReader(remote.NewETHBACKENDClient(conn))
 	}
 	remoteEth := rpcservices.NewRemoteBackend(remote.NewETHBACKENDClient(conn), db, blockReader)
+	remoteEth.SetPeerAdmin(privateapi.NewPeerAdminClient(conn))
 	blockReader = remoteEth
 
 	txpoolConn := conn
@@ -282,6 +282,8 @@ type RPCTransaction struct {
 	Type             hexutil.Uint64    `json:"type"`
 	Accesses         *types.AccessList `json:"accessList,omitempty"`
 	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	MaxFeePerBlobGas *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes       []common.Hash     `json:"blobVersionedHashes,omitempty"`
 	V                *hexutil.Big      `json:"v"`
 	R                *hexutil.Big      `json:"r"`
 	S                *hexutil.Big      `json:"s"`
@@ -336,6 +338,25 @@ func newRPCTransaction(tx types.Transaction, blockHash common.Hash, blockNumber
 		} else {
 			result.GasPrice = nil
 		}
+	case *types.BlobTx:
+		chainId = t.ChainID.ToBig()
+		result.ChainID = (*hexutil.Big)(chainId)
+		result.Tip = (*hexutil.Big)(t.Tip.ToBig())
+		result.FeeCap = (*hexutil.Big)(t.FeeCap.ToBig())
+		result.MaxFeePerBlobGas = (*hexutil.Big)(t.MaxFeePerBlobGas.ToBig())
+		result.BlobHashes = t.BlobVersionedHashes
+		result.V = (*hexutil.Big)(t.V.ToBig())
+		result.R = (*hexutil.Big)(t.R.ToBig())
+		result.S = (*hexutil.Big)(t.S.ToBig())
+		result.Accesses = &t.AccessList
+		baseFee, overflow := uint256.FromBig(baseFee)
+		if baseFee != nil && !overflow && blockHash != (common.Hash{}) {
+			// price = min(tip + baseFee, gasFeeCap)
+			price := math.Min256(new(uint256.Int).Add(tx.GetTip(), baseFee), tx.GetFeeCap())
+			result.GasPrice = (*hexutil.Big)(price.ToBig())
+		} else {
+			result.GasPrice = nil
+		}
 	}
 	signer := types.LatestSignerForChainID(chainId)
 	result.From, _ = tx.Sender(*signer)
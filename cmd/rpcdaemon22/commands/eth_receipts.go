@@ -17,6 +17,7 @@ import (
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/misc"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
@@ -40,7 +41,7 @@ func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.Tx, chainConfig *para
 		}
 		return h
 	}
-	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), 0)
+	_, _, _, ibs, _, err := transactions.ComputeTxEnv(ctx, block, chainConfig, getHeader, ethash.NewFaker(), tx, block.Hash(), 0, vm.Config{})
 	if err != nil {
 		return nil, err
 	}
@@ -387,6 +388,8 @@ func marshalReceipt(receipt *types.Receipt, txn types.Transaction, chainConfig *
 		chainId = t.ChainID.ToBig()
 	case *types.DynamicFeeTransaction:
 		chainId = t.ChainID.ToBig()
+	case *types.BlobTx:
+		chainId = t.ChainID.ToBig()
 	}
 	signer := types.LatestSignerForChainID(chainId)
 	from, _ := txn.Sender(*signer)
@@ -413,6 +416,12 @@ func marshalReceipt(receipt *types.Receipt, txn types.Transaction, chainConfig *
 		gasPrice := new(big.Int).Add(block.BaseFee(), txn.GetEffectiveGasTip(baseFee).ToBig())
 		fields["effectiveGasPrice"] = hexutil.Uint64(gasPrice.Uint64())
 	}
+	if blobGas := txn.GetBlobGas(); blobGas > 0 && block.Header().ExcessBlobGas != nil {
+		fields["blobGasUsed"] = hexutil.Uint64(blobGas)
+		if blobGasPrice, err := misc.GetBlobGasPrice(*block.Header().ExcessBlobGas); err == nil {
+			fields["blobGasPrice"] = (*hexutil.Big)(blobGasPrice.ToBig())
+		}
+	}
 	// Assign receipt status.
 	fields["status"] = hexutil.Uint64(receipt.Status)
 	if receipt.Logs == nil {
@@ -9,8 +9,10 @@ import (
 	"io"
 	"sync/atomic"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
 	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
@@ -31,8 +33,12 @@ type RemoteBackend struct {
 	version          gointerfaces.Version
 	db               kv.RoDB
 	blockReader      services.FullBlockReader
+	engineV2         privateapi.EngineV2 // nil if Erigon is remote, the gRPC interface has no withdrawals
+	peerAdmin        privateapi.PeerAdmin
 }
 
+var errEngineV2NotSupported = errors.New("engine API V2 with withdrawals is only supported by rpcdaemon embedded into Erigon")
+
 func NewRemoteBackend(client remote.ETHBACKENDClient, db kv.RoDB, blockReader services.FullBlockReader) *RemoteBackend {
 	return &RemoteBackend{
 		remoteEthBackend: client,
@@ -43,6 +49,17 @@ func NewRemoteBackend(client remote.ETHBACKENDClient, db kv.RoDB, blockReader se
 	}
 }
 
+// SetEngineV2 is used by embedded rpcdaemon, which can call the Shanghai engine methods of Erigon directly
+func (back *RemoteBackend) SetEngineV2(engineV2 privateapi.EngineV2) {
+	back.engineV2 = engineV2
+}
+
+// SetPeerAdmin sets the peer management of Erigon: Erigon itself for embedded rpcdaemon, the client of its PeerAdmin
+// gRPC service for standalone one
+func (back *RemoteBackend) SetPeerAdmin(peerAdmin privateapi.PeerAdmin) {
+	back.peerAdmin = peerAdmin
+}
+
 func (back *RemoteBackend) EnsureVersionCompatibility() bool {
 	versionReply, err := back.remoteEthBackend.Version(context.Background(), &emptypb.Empty{}, grpc.WaitForReady(true))
 	if err != nil {
@@ -196,6 +213,9 @@ func (back *RemoteBackend) CanonicalHash(ctx context.Context, tx kv.Getter, bloc
 func (back *RemoteBackend) TxnByIdxInBlock(ctx context.Context, tx kv.Getter, blockNum uint64, i int) (types.Transaction, error) {
 	return back.blockReader.TxnByIdxInBlock(ctx, tx, blockNum, i)
 }
+func (back *RemoteBackend) RawReceipts(ctx context.Context, tx kv.Getter, blockHeight uint64) (types.Receipts, error) {
+	return back.blockReader.RawReceipts(ctx, tx, blockHeight)
+}
 
 func (back *RemoteBackend) EngineNewPayloadV1(ctx context.Context, payload *types2.ExecutionPayload) (res *remote.EnginePayloadStatus, err error) {
 	return back.remoteEthBackend.EngineNewPayloadV1(ctx, payload)
@@ -211,6 +231,36 @@ func (back *RemoteBackend) EngineGetPayloadV1(ctx context.Context, payloadId uin
 	})
 }
 
+func (back *RemoteBackend) EngineNewPayloadV2(ctx context.Context, payload *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error) {
+	if back.engineV2 != nil {
+		return back.engineV2.EngineNewPayloadV2(ctx, payload, withdrawals)
+	}
+	if withdrawals != nil {
+		return nil, errEngineV2NotSupported
+	}
+	return back.remoteEthBackend.EngineNewPayloadV1(ctx, payload)
+}
+
+func (back *RemoteBackend) EngineForkchoiceUpdatedV2(ctx context.Context, request *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error) {
+	if back.engineV2 != nil {
+		return back.engineV2.EngineForkChoiceUpdatedV2(ctx, request, withdrawals)
+	}
+	if withdrawals != nil {
+		return nil, errEngineV2NotSupported
+	}
+	return back.remoteEthBackend.EngineForkChoiceUpdatedV1(ctx, request)
+}
+
+// EngineGetPayloadV2 is not available for remote Erigon, because the block value is not in the gRPC interface
+func (back *RemoteBackend) EngineGetPayloadV2(ctx context.Context, payloadId uint64) (*types2.ExecutionPayload, []*types.Withdrawal, *uint256.Int, error) {
+	if back.engineV2 == nil {
+		return nil, nil, nil, errEngineV2NotSupported
+	}
+	return back.engineV2.EngineGetPayloadV2(ctx, &remote.EngineGetPayloadRequest{
+		PayloadId: payloadId,
+	})
+}
+
 func (back *RemoteBackend) NodeInfo(ctx context.Context, limit uint32) ([]p2p.NodeInfo, error) {
 	nodes, err := back.remoteEthBackend.NodeInfo(ctx, &remote.NodesInfoRequest{Limit: limit})
 	if err != nil {
@@ -291,6 +341,22 @@ func (back *RemoteBackend) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
 	return peers, nil
 }
 
+func (back *RemoteBackend) AddPeer(ctx context.Context, url string) (bool, error) {
+	return back.peerAdmin.AddPeer(ctx, url)
+}
+
+func (back *RemoteBackend) RemovePeer(ctx context.Context, url string) (bool, error) {
+	return back.peerAdmin.RemovePeer(ctx, url)
+}
+
+func (back *RemoteBackend) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return back.peerAdmin.AddTrustedPeer(ctx, url)
+}
+
+func (back *RemoteBackend) PeerEvents(ctx context.Context, cb func(*sentry.PeerEvent)) error {
+	return back.peerAdmin.PeerEvents(ctx, cb)
+}
+
 func (back *RemoteBackend) PendingBlock(ctx context.Context) (*types.Block, error) {
 	blockRlp, err := back.remoteEthBackend.PendingBlock(ctx, &emptypb.Empty{})
 	if err != nil {
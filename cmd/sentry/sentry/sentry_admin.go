@@ -0,0 +1,133 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/p2p/enode"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	peerAdminServiceName = "sentry.PeerAdmin"
+	addPeerMethod        = "/" + peerAdminServiceName + "/AddPeer"
+	removePeerMethod     = "/" + peerAdminServiceName + "/RemovePeer"
+	addTrustedPeerMethod = "/" + peerAdminServiceName + "/AddTrustedPeer"
+)
+
+// PeerAdmin manages the static and trusted peers of a sentry at runtime. It is not part of the Sentry gRPC
+// interface of erigon-lib, so remote sentries serve it as a separate gRPC service next to the Sentry one.
+// The methods take the enode URL of the peer.
+type PeerAdmin interface {
+	AddPeer(ctx context.Context, url string) (bool, error)
+	RemovePeer(ctx context.Context, url string) (bool, error)
+	AddTrustedPeer(ctx context.Context, url string) (bool, error)
+}
+
+func (ss *GrpcServer) parseNode(url string) (*enode.Node, error) {
+	if ss.P2pServer == nil {
+		return nil, errors.New("p2p server was not started")
+	}
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid enode: %w", err)
+	}
+	return node, nil
+}
+
+// AddPeer adds the node to the static peers, the sentry keeps connecting to it
+func (ss *GrpcServer) AddPeer(_ context.Context, url string) (bool, error) {
+	node, err := ss.parseNode(url)
+	if err != nil {
+		return false, err
+	}
+	ss.P2pServer.AddPeer(node)
+	return true, nil
+}
+
+// RemovePeer removes the node from the static peers and disconnects it
+func (ss *GrpcServer) RemovePeer(_ context.Context, url string) (bool, error) {
+	node, err := ss.parseNode(url)
+	if err != nil {
+		return false, err
+	}
+	ss.P2pServer.RemovePeer(node)
+	return true, nil
+}
+
+// AddTrustedPeer allows the node to connect even when the peer slots are full
+func (ss *GrpcServer) AddTrustedPeer(_ context.Context, url string) (bool, error) {
+	node, err := ss.parseNode(url)
+	if err != nil {
+		return false, err
+	}
+	ss.P2pServer.AddTrustedPeer(node)
+	return true, nil
+}
+
+type peerAdminClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewPeerAdminClient returns PeerAdmin of the remote sentry available over the given connection
+func NewPeerAdminClient(conn grpc.ClientConnInterface) PeerAdmin {
+	return &peerAdminClient{conn: conn}
+}
+
+func (c *peerAdminClient) invoke(ctx context.Context, method string, url string) (bool, error) {
+	res := new(wrapperspb.BoolValue)
+	if err := c.conn.Invoke(ctx, method, wrapperspb.String(url), res); err != nil {
+		return false, err
+	}
+	return res.Value, nil
+}
+
+func (c *peerAdminClient) AddPeer(ctx context.Context, url string) (bool, error) {
+	return c.invoke(ctx, addPeerMethod, url)
+}
+
+func (c *peerAdminClient) RemovePeer(ctx context.Context, url string) (bool, error) {
+	return c.invoke(ctx, removePeerMethod, url)
+}
+
+func (c *peerAdminClient) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return c.invoke(ctx, addTrustedPeerMethod, url)
+}
+
+// RegisterPeerAdminServer exposes the given PeerAdmin as a gRPC service
+func RegisterPeerAdminServer(s *grpc.Server, admin PeerAdmin) {
+	s.RegisterService(&peerAdminServiceDesc, admin)
+}
+
+func peerAdminHandler(method string, call func(PeerAdmin, context.Context, string) (bool, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			ok, err := call(srv.(PeerAdmin), ctx, req.(*wrapperspb.StringValue).Value)
+			if err != nil {
+				return nil, err
+			}
+			return wrapperspb.Bool(ok), nil
+		}
+		req := new(wrapperspb.StringValue)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: method}, handler)
+	}
+}
+
+var peerAdminServiceDesc = grpc.ServiceDesc{
+	ServiceName: peerAdminServiceName,
+	HandlerType: (*PeerAdmin)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "AddPeer", Handler: peerAdminHandler(addPeerMethod, PeerAdmin.AddPeer)},
+		{MethodName: "RemovePeer", Handler: peerAdminHandler(removePeerMethod, PeerAdmin.RemovePeer)},
+		{MethodName: "AddTrustedPeer", Handler: peerAdminHandler(addTrustedPeerMethod, PeerAdmin.AddTrustedPeer)},
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,79 @@
+package sentry
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testEnode = "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"
+
+type testPeerAdmin struct {
+	added, removed, trusted []string
+}
+
+func (a *testPeerAdmin) AddPeer(_ context.Context, url string) (bool, error) {
+	a.added = append(a.added, url)
+	return true, nil
+}
+
+func (a *testPeerAdmin) RemovePeer(_ context.Context, url string) (bool, error) {
+	a.removed = append(a.removed, url)
+	return false, nil
+}
+
+func (a *testPeerAdmin) AddTrustedPeer(_ context.Context, url string) (bool, error) {
+	a.trusted = append(a.trusted, url)
+	return true, nil
+}
+
+func TestPeerAdminGrpc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	admin := &testPeerAdmin{}
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterPeerAdminServer(server, admin)
+	go server.Serve(listener) //nolint:errcheck
+	defer server.Stop()
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := NewPeerAdminClient(conn)
+
+	ok, err := client.AddPeer(ctx, testEnode)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = client.RemovePeer(ctx, testEnode)
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = client.AddTrustedPeer(ctx, testEnode)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{testEnode}, admin.added)
+	require.Equal(t, []string{testEnode}, admin.removed)
+	require.Equal(t, []string{testEnode}, admin.trusted)
+}
+
+func TestPeerAdminErrors(t *testing.T) {
+	ctx := context.Background()
+	ss := &GrpcServer{ctx: ctx}
+	_, err := ss.AddPeer(ctx, testEnode)
+	require.EqualError(t, err, "p2p server was not started")
+
+	ss.P2pServer = &p2p.Server{}
+	for _, f := range []func(context.Context, string) (bool, error){ss.AddPeer, ss.RemovePeer, ss.AddTrustedPeer} {
+		ok, err := f(ctx, "enode://invalid")
+		require.ErrorContains(t, err, "invalid enode")
+		require.False(t, ok)
+	}
+}
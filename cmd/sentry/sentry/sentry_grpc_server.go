@@ -28,6 +28,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/core/forkid"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/eth/protocols/snap"
 	"github.com/ledgerwatch/erigon/node/nodecfg/datadir"
 	"github.com/ledgerwatch/erigon/p2p"
 	"github.com/ledgerwatch/erigon/p2p/dnsdisc"
@@ -195,7 +196,7 @@ func ConvertH512ToPeerID(h512 *proto_types.H512) [64]byte {
 func makeP2PServer(
 	p2pConfig p2p.Config,
 	genesisHash common.Hash,
-	protocol p2p.Protocol,
+	protocols []p2p.Protocol,
 ) (*p2p.Server, error) {
 	var urls []string
 	chainConfig := params.ChainConfigByGenesisHash(genesisHash)
@@ -210,7 +211,7 @@ func makeP2PServer(
 		p2pConfig.BootstrapNodes = bootstrapNodes
 		p2pConfig.BootstrapNodesV5 = bootstrapNodes
 	}
-	p2pConfig.Protocols = []p2p.Protocol{protocol}
+	p2pConfig.Protocols = protocols
 	return &p2p.Server{Config: p2pConfig}, nil
 }
 
@@ -390,6 +391,7 @@ func runPeer(
 			if !hasSubscribers(eth.ToProto[protocol][msg.Code]) {
 				continue
 			}
+			givePermit = true
 			b := make([]byte, msg.Size)
 			if _, err := io.ReadFull(msg.Payload, b); err != nil {
 				log.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
@@ -462,6 +464,52 @@ func runPeer(
 	}
 }
 
+// runSnapPeer forwards the snap requests of the peer to the core. Erigon doesn't sync with snap, so it never sends
+// requests to the peers, and the responses are not expected.
+func runSnapPeer(
+	ctx context.Context,
+	peerID [64]byte,
+	rw p2p.MsgReadWriter,
+	peerInfo *PeerInfo,
+	send func(msgId proto_sentry.MessageId, peerID [64]byte, b []byte),
+	hasSubscribers func(msgId proto_sentry.MessageId) bool,
+) error {
+	for {
+		if err := libcommon.Stopped(ctx.Done()); err != nil {
+			return err
+		}
+		if peerInfo.Removed() {
+			return fmt.Errorf("peer removed")
+		}
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return fmt.Errorf("reading message: %w", err)
+		}
+		if msg.Size > snap.ProtocolMaxMsgSize {
+			msg.Discard()
+			return fmt.Errorf("message is too large %d, limit %d", msg.Size, snap.ProtocolMaxMsgSize)
+		}
+		switch msg.Code {
+		case snap.GetAccountRangeMsg, snap.GetStorageRangesMsg, snap.GetByteCodesMsg, snap.GetTrieNodesMsg:
+			msgID := snap.ToProto[snap.SNAP1][msg.Code]
+			if !hasSubscribers(msgID) {
+				break
+			}
+			b := make([]byte, msg.Size)
+			if _, err := io.ReadFull(msg.Payload, b); err != nil {
+				log.Error(fmt.Sprintf("%s: reading msg into bytes: %v", peerID, err))
+			}
+			send(msgID, peerID, b)
+		case snap.AccountRangeMsg, snap.StorageRangesMsg, snap.ByteCodesMsg, snap.TrieNodesMsg:
+			msg.Discard()
+			return fmt.Errorf("unexpected snap response %d from %x", msg.Code, peerID)
+		default:
+			log.Error(fmt.Sprintf("[%s] Unknown snap message code: %d", peerID, msg.Code))
+		}
+		msg.Discard()
+	}
+}
+
 func grpcSentryServer(ctx context.Context, sentryAddr string, ss *GrpcServer, healthCheck bool) (*grpc.Server, error) {
 	// STARTING GRPC SERVER
 	log.Info("Starting Sentry gRPC server", "on", sentryAddr)
@@ -477,6 +525,7 @@ func grpcSentryServer(ctx context.Context, sentryAddr string, ss *GrpcServer, he
 	}
 	grpcServer := grpcutil.NewServer(100, nil)
 	proto_sentry.RegisterSentryServer(grpcServer, ss)
+	RegisterPeerAdminServer(grpcServer, ss)
 	var healthServer *health.Server
 	if healthCheck {
 		healthServer = health.NewServer()
@@ -556,6 +605,37 @@ func NewGrpcServer(ctx context.Context, dialCandidates enode.Iterator, readNodeI
 		//Attributes: []enr.Entry{eth.CurrentENREntry(chainConfig, genesisHash, headHeight)},
 	}
 
+	if cfg.ServeSnap {
+		ss.SnapProtocol = &p2p.Protocol{
+			Name:    snap.ProtocolName,
+			Version: snap.SNAP1,
+			Length:  snap.ProtocolLength,
+			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+				peerID := peer.Pubkey()
+				printablePeerID := hex.EncodeToString(peerID[:])[:20]
+				if ss.getSnapPeer(peerID) != nil {
+					log.Trace(fmt.Sprintf("[%s] Peer already has snap connection", printablePeerID))
+					return nil
+				}
+
+				peerInfo := NewPeerInfo(peer, rw)
+				defer peerInfo.Close()
+				ss.snapPeers.Store(peerID, peerInfo)
+				defer ss.snapPeers.Delete(peerID)
+
+				err := runSnapPeer(ctx, peerID, rw, peerInfo, ss.send, ss.hasSubscribers) // runSnapPeer never returns a nil error
+				log.Trace(fmt.Sprintf("[%s] Error while running snap peer: %v", printablePeerID, err))
+				return nil
+			},
+			NodeInfo: func() interface{} {
+				return nil
+			},
+			PeerInfo: func(peerID [64]byte) interface{} {
+				return nil
+			},
+		}
+	}
+
 	return ss
 }
 
@@ -580,6 +660,8 @@ type GrpcServer struct {
 	proto_sentry.UnimplementedSentryServer
 	ctx                  context.Context
 	Protocol             p2p.Protocol
+	SnapProtocol         *p2p.Protocol // nil unless snap/1 is served
+	snapPeers            sync.Map
 	discoveryDNS         []string
 	GoodPeers            sync.Map
 	statusData           *proto_sentry.StatusData
@@ -614,6 +696,13 @@ func (ss *GrpcServer) getPeer(peerID [64]byte) (peerInfo *PeerInfo) {
 	return nil
 }
 
+func (ss *GrpcServer) getSnapPeer(peerID [64]byte) *PeerInfo {
+	if value, ok := ss.snapPeers.Load(peerID); ok {
+		return value.(*PeerInfo)
+	}
+	return nil
+}
+
 func (ss *GrpcServer) removePeer(peerID [64]byte) {
 	if value, ok := ss.GoodPeers.LoadAndDelete(peerID); ok {
 		peerInfo := value.(*PeerInfo)
@@ -710,6 +799,7 @@ func (ss *GrpcServer) SendMessageByMinBlock(_ context.Context, inreq *proto_sent
 	msgcode := eth.FromProto[ss.Protocol.Version][inreq.Data.Id]
 	if msgcode != eth.GetBlockHeadersMsg &&
 		msgcode != eth.GetBlockBodiesMsg &&
+		msgcode != eth.GetReceiptsMsg &&
 		msgcode != eth.GetPooledTransactionsMsg {
 		return reply, fmt.Errorf("sendMessageByMinBlock not implemented for message Id: %s", inreq.Data.Id)
 	}
@@ -734,6 +824,9 @@ func (ss *GrpcServer) SendMessageByMinBlock(_ context.Context, inreq *proto_sent
 
 func (ss *GrpcServer) SendMessageById(_ context.Context, inreq *proto_sentry.SendMessageByIdRequest) (*proto_sentry.SentPeers, error) {
 	reply := &proto_sentry.SentPeers{}
+	if msgcode, ok := snap.FromProto[snap.SNAP1][inreq.Data.Id]; ok {
+		return ss.sendSnapMessageById(inreq, msgcode)
+	}
 	msgcode := eth.FromProto[ss.Protocol.Version][inreq.Data.Id]
 	if msgcode != eth.GetBlockHeadersMsg &&
 		msgcode != eth.BlockHeadersMsg &&
@@ -759,6 +852,26 @@ func (ss *GrpcServer) SendMessageById(_ context.Context, inreq *proto_sentry.Sen
 	return reply, nil
 }
 
+// sendSnapMessageById sends the responses to the snap requests of the peer
+func (ss *GrpcServer) sendSnapMessageById(inreq *proto_sentry.SendMessageByIdRequest, msgcode uint64) (*proto_sentry.SentPeers, error) {
+	reply := &proto_sentry.SentPeers{}
+	if msgcode != snap.AccountRangeMsg &&
+		msgcode != snap.StorageRangesMsg &&
+		msgcode != snap.ByteCodesMsg &&
+		msgcode != snap.TrieNodesMsg {
+		return reply, fmt.Errorf("sendMessageById not implemented for message Id: %s", inreq.Data.Id)
+	}
+
+	peerInfo := ss.getSnapPeer(ConvertH512ToPeerID(inreq.PeerId))
+	if peerInfo == nil {
+		return reply, nil
+	}
+
+	ss.writePeer("sendMessageById", peerInfo, msgcode, inreq.Data.Data, 0)
+	reply.Peers = []*proto_types.H512{inreq.PeerId}
+	return reply, nil
+}
+
 func (ss *GrpcServer) SendMessageToRandomPeers(ctx context.Context, req *proto_sentry.SendMessageToRandomPeersRequest) (*proto_sentry.SentPeers, error) {
 	reply := &proto_sentry.SentPeers{}
 
@@ -843,7 +956,11 @@ func (ss *GrpcServer) SetStatus(ctx context.Context, statusData *proto_sentry.St
 			}
 		}
 
-		srv, err := makeP2PServer(*ss.p2p, genesisHash, ss.Protocol)
+		protocols := []p2p.Protocol{ss.Protocol}
+		if ss.SnapProtocol != nil {
+			protocols = append(protocols, *ss.SnapProtocol)
+		}
+		srv, err := makeP2PServer(*ss.p2p, genesisHash, protocols)
 		if err != nil {
 			return reply, err
 		}
@@ -16,7 +16,9 @@ import (
 	"github.com/ledgerwatch/erigon/core/forkid"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/eth/protocols/snap"
 	"github.com/ledgerwatch/erigon/p2p"
+	"github.com/ledgerwatch/erigon/p2p/enode"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/stretchr/testify/require"
 )
@@ -176,3 +178,33 @@ func TestSentryServerImpl_SetStatusInitPanic(t *testing.T) {
 		t.Fatalf("error expected")
 	}
 }
+
+// The snap responses are sent by their ids to the snap peers with the snap message codes, the requests are not sent
+func TestSendSnapMessageById(t *testing.T) {
+	var pubkey [64]byte
+	pubkey[0] = 1
+	rw, remote := p2p.MsgPipe()
+	defer rw.Close()
+	peerInfo := NewPeerInfo(p2p.NewPeer(enode.ID{1}, pubkey, "snap", nil), rw)
+	defer peerInfo.Remove()
+	ss := &GrpcServer{}
+	ss.snapPeers.Store(pubkey, peerInfo)
+
+	for code, id := range snap.ToProto[snap.SNAP1] {
+		reply, err := ss.SendMessageById(context.Background(), &proto_sentry.SendMessageByIdRequest{
+			PeerId: gointerfaces.ConvertHashToH512(pubkey),
+			Data:   &proto_sentry.OutboundMessageData{Id: id, Data: []byte{0xc0}},
+		})
+		switch code {
+		case snap.AccountRangeMsg, snap.StorageRangesMsg, snap.ByteCodesMsg, snap.TrieNodesMsg:
+			require.NoError(t, err)
+			require.Len(t, reply.Peers, 1)
+			msg, err := remote.ReadMsg()
+			require.NoError(t, err)
+			require.Equal(t, code, msg.Code)
+			msg.Discard()
+		default:
+			require.Error(t, err)
+		}
+	}
+}
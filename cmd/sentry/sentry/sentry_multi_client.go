@@ -34,12 +34,15 @@ import (
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/eth/protocols/snap"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/engineapi"
 	"github.com/ledgerwatch/erigon/turbo/services"
 	"github.com/ledgerwatch/erigon/turbo/stages/bodydownload"
 	"github.com/ledgerwatch/erigon/turbo/stages/headerdownload"
+	"github.com/ledgerwatch/erigon/turbo/stages/receiptdownload"
 )
 
 type sentryMessageStream grpc.ClientStream
@@ -48,7 +51,7 @@ type sentryMessageStreamFactory func(context.Context, direct.SentryClient) (sent
 // StartStreamLoops starts message processing loops for all sentries.
 // The processing happens in several streams:
 // RecvMessage - processing incoming headers/bodies
-// RecvUploadMessage - sending bodies/receipts - may be heavy, it's ok to not process this messages enough fast, it's also ok to drop some of these messages if we can't process.
+// RecvUploadMessage - sending bodies/receipts/snap state - may be heavy, it's ok to not process this messages enough fast, it's also ok to drop some of these messages if we can't process.
 // RecvUploadHeadersMessage - sending headers - dedicated stream because headers propagation speed important for network health
 // PeerEventsLoop - logging peer connect/disconnect events
 func (cs *MultiClient) StartStreamLoops(ctx context.Context) {
@@ -70,6 +73,10 @@ func (cs *MultiClient) RecvUploadMessageLoop(
 	ids := []proto_sentry.MessageId{
 		eth.ToProto[eth.ETH66][eth.GetBlockBodiesMsg],
 		eth.ToProto[eth.ETH66][eth.GetReceiptsMsg],
+		snap.ToProto[snap.SNAP1][snap.GetAccountRangeMsg],
+		snap.ToProto[snap.SNAP1][snap.GetStorageRangesMsg],
+		snap.ToProto[snap.SNAP1][snap.GetByteCodesMsg],
+		snap.ToProto[snap.SNAP1][snap.GetTrieNodesMsg],
 	}
 	streamFactory := func(streamCtx context.Context, sentry direct.SentryClient) (sentryMessageStream, error) {
 		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: ids}, grpc.WaitForReady(true))
@@ -104,6 +111,10 @@ func (cs *MultiClient) RecvMessageLoop(
 		eth.ToProto[eth.ETH66][eth.NewBlockHashesMsg],
 		eth.ToProto[eth.ETH66][eth.NewBlockMsg],
 	}
+	// Nothing requests the receipts when the Receipts stage is disabled
+	if cs.downloadReceipts {
+		ids = append(ids, eth.ToProto[eth.ETH66][eth.ReceiptsMsg])
+	}
 	streamFactory := func(streamCtx context.Context, sentry direct.SentryClient) (sentryMessageStream, error) {
 		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: ids}, grpc.WaitForReady(true))
 	}
@@ -247,6 +258,7 @@ type MultiClient struct {
 	lock          sync.RWMutex
 	Hd            *headerdownload.HeaderDownload
 	Bd            *bodydownload.BodyDownload
+	Rd            *receiptdownload.ReceiptDownload
 	IsMock        bool
 	forkValidator *engineapi.ForkValidator
 	nodeName      string
@@ -263,7 +275,8 @@ type MultiClient struct {
 	blockReader   services.HeaderAndCanonicalReader
 	logPeerInfo   bool
 
-	historyV2 bool
+	historyV2        bool
+	downloadReceipts bool
 }
 
 func NewMultiClient(
@@ -292,11 +305,13 @@ func NewMultiClient(
 		return nil, fmt.Errorf("recovery from DB failed: %w", err)
 	}
 	bd := bodydownload.NewBodyDownload(syncCfg.BlockDownloaderWindow /* outstandingLimit */, engine)
+	rd := receiptdownload.NewReceiptDownload(syncCfg.BlockDownloaderWindow /* outstandingLimit */)
 
 	cs := &MultiClient{
 		nodeName:      nodeName,
 		Hd:            hd,
 		Bd:            bd,
+		Rd:            rd,
 		sentries:      sentries,
 		db:            db,
 		Engine:        engine,
@@ -304,6 +319,8 @@ func NewMultiClient(
 		logPeerInfo:   logPeerInfo,
 		forkValidator: forkValidator,
 		historyV2:     historyV2,
+		// Receipts stage is disabled for the history v2, see stagedsync.DefaultStages
+		downloadReceipts: syncCfg.DownloadReceipts && !historyV2,
 	}
 	cs.ChainConfig = chainConfig
 	cs.forks = forkid.GatherForks(cs.ChainConfig)
@@ -531,12 +548,21 @@ func (cs *MultiClient) blockBodies66(inreq *proto_sentry.InboundMessage, _ direc
 	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
 		return fmt.Errorf("decode BlockBodiesPacket66: %w", err)
 	}
-	txs, uncles := request.BlockRawBodiesPacket.Unpack()
-	cs.Bd.DeliverBodies(&txs, &uncles, uint64(len(inreq.Data)), ConvertH512ToPeerID(inreq.PeerId))
+	txs, uncles, withdrawals := request.BlockRawBodiesPacket.Unpack()
+	cs.Bd.DeliverBodies(&txs, &uncles, &withdrawals, uint64(len(inreq.Data)), ConvertH512ToPeerID(inreq.PeerId))
 	return nil
 }
 
-func (cs *MultiClient) receipts66(_ context.Context, _ *proto_sentry.InboundMessage, _ direct.SentryClient) error {
+func (cs *MultiClient) receipts66(_ context.Context, inreq *proto_sentry.InboundMessage, _ direct.SentryClient) error {
+	if !cs.downloadReceipts {
+		return nil
+	}
+	var request eth.ReceiptsPacket66
+	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
+		return fmt.Errorf("decode ReceiptsPacket66: %w", err)
+	}
+	receipts := [][]*types.Receipt(request.ReceiptsPacket)
+	cs.Rd.DeliverReceipts(&receipts, uint64(len(inreq.Data)), ConvertH512ToPeerID(inreq.PeerId))
 	return nil
 }
 
@@ -662,6 +688,152 @@ func (cs *MultiClient) getReceipts66(ctx context.Context, inreq *proto_sentry.In
 	return nil
 }
 
+// snapStateRoot returns the state root of the block HashedState and IntermediateHashes are at, it is the only
+// state served over snap
+func (cs *MultiClient) snapStateRoot(ctx context.Context, tx kv.Tx) (common.Hash, error) {
+	progress, err := stages.GetStageProgress(tx, stages.IntermediateHashes)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header, err := cs.blockReader.HeaderByNumber(ctx, tx, progress)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("header of block %d not found", progress)
+	}
+	return header.Root, nil
+}
+
+func (cs *MultiClient) getAccountRange(ctx context.Context, inreq *proto_sentry.InboundMessage, sentry direct.SentryClient) error {
+	if cs.historyV2 { // historyV2 doesn't keep HashedState and IntermediateHashes
+		return nil
+	}
+
+	var query snap.GetAccountRangePacket
+	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
+		return fmt.Errorf("decoding getAccountRange: %w, data: %x", err, inreq.Data)
+	}
+	tx, err := cs.db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	root, err := cs.snapStateRoot(ctx, tx)
+	if err != nil {
+		return err
+	}
+	response, err := snap.AnswerGetAccountRangeQuery(tx, root, &query, ctx.Done())
+	if err != nil {
+		return err
+	}
+	tx.Rollback()
+	b, err := rlp.EncodeToBytes(response)
+	if err != nil {
+		return fmt.Errorf("encode account range response: %w", err)
+	}
+	return cs.sendSnapResponse(ctx, inreq.PeerId, snap.MessageIdAccountRange, b, sentry)
+}
+
+func (cs *MultiClient) getStorageRanges(ctx context.Context, inreq *proto_sentry.InboundMessage, sentry direct.SentryClient) error {
+	if cs.historyV2 { // historyV2 doesn't keep HashedState and IntermediateHashes
+		return nil
+	}
+
+	var query snap.GetStorageRangesPacket
+	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
+		return fmt.Errorf("decoding getStorageRanges: %w, data: %x", err, inreq.Data)
+	}
+	tx, err := cs.db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	root, err := cs.snapStateRoot(ctx, tx)
+	if err != nil {
+		return err
+	}
+	response, err := snap.AnswerGetStorageRangesQuery(tx, root, &query, ctx.Done())
+	if err != nil {
+		return err
+	}
+	tx.Rollback()
+	b, err := rlp.EncodeToBytes(response)
+	if err != nil {
+		return fmt.Errorf("encode storage ranges response: %w", err)
+	}
+	return cs.sendSnapResponse(ctx, inreq.PeerId, snap.MessageIdStorageRanges, b, sentry)
+}
+
+func (cs *MultiClient) getByteCodes(ctx context.Context, inreq *proto_sentry.InboundMessage, sentry direct.SentryClient) error {
+	var query snap.GetByteCodesPacket
+	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
+		return fmt.Errorf("decoding getByteCodes: %w, data: %x", err, inreq.Data)
+	}
+	tx, err := cs.db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	response, err := snap.AnswerGetByteCodesQuery(tx, &query)
+	if err != nil {
+		return err
+	}
+	tx.Rollback()
+	b, err := rlp.EncodeToBytes(response)
+	if err != nil {
+		return fmt.Errorf("encode bytecodes response: %w", err)
+	}
+	return cs.sendSnapResponse(ctx, inreq.PeerId, snap.MessageIdByteCodes, b, sentry)
+}
+
+func (cs *MultiClient) getTrieNodes(ctx context.Context, inreq *proto_sentry.InboundMessage, sentry direct.SentryClient) error {
+	if cs.historyV2 { // historyV2 doesn't keep HashedState and IntermediateHashes
+		return nil
+	}
+
+	var query snap.GetTrieNodesPacket
+	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
+		return fmt.Errorf("decoding getTrieNodes: %w, data: %x", err, inreq.Data)
+	}
+	tx, err := cs.db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	root, err := cs.snapStateRoot(ctx, tx)
+	if err != nil {
+		return err
+	}
+	response, err := snap.AnswerGetTrieNodesQuery(tx, root, &query, ctx.Done())
+	if err != nil {
+		return err
+	}
+	tx.Rollback()
+	b, err := rlp.EncodeToBytes(response)
+	if err != nil {
+		return fmt.Errorf("encode trie nodes response: %w", err)
+	}
+	return cs.sendSnapResponse(ctx, inreq.PeerId, snap.MessageIdTrieNodes, b, sentry)
+}
+
+func (cs *MultiClient) sendSnapResponse(ctx context.Context, peerID *proto_types.H512, id proto_sentry.MessageId, data []byte, sentry direct.SentryClient) error {
+	outreq := proto_sentry.SendMessageByIdRequest{
+		PeerId: peerID,
+		Data: &proto_sentry.OutboundMessageData{
+			Id:   id,
+			Data: data,
+		},
+	}
+	if _, err := sentry.SendMessageById(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
+		if isPeerNotFoundErr(err) {
+			return nil
+		}
+		return fmt.Errorf("send snap response %s: %w", id, err)
+	}
+	return nil
+}
+
 func makeInboundMessage() *proto_sentry.InboundMessage {
 	return new(proto_sentry.InboundMessage)
 }
@@ -709,6 +881,17 @@ func (cs *MultiClient) handleInboundMessage(ctx context.Context, inreq *proto_se
 		return cs.receipts66(ctx, inreq, sentry)
 	case proto_sentry.MessageId_GET_RECEIPTS_66:
 		return cs.getReceipts66(ctx, inreq, sentry)
+
+	// ========= snap 1 ==========
+
+	case snap.MessageIdGetAccountRange:
+		return cs.getAccountRange(ctx, inreq, sentry)
+	case snap.MessageIdGetStorageRanges:
+		return cs.getStorageRanges(ctx, inreq, sentry)
+	case snap.MessageIdGetByteCodes:
+		return cs.getByteCodes(ctx, inreq, sentry)
+	case snap.MessageIdGetTrieNodes:
+		return cs.getTrieNodes(ctx, inreq, sentry)
 	default:
 		return fmt.Errorf("not implemented for message Id: %s", inreq.Id)
 	}
@@ -758,7 +941,8 @@ func (cs *MultiClient) makeStatusData() *proto_sentry.StatusData {
 	}
 }
 
-func GrpcClient(ctx context.Context, sentryAddr string) (*direct.SentryClientRemote, error) {
+// GrpcClient connects to the remote sentry, PeerAdmin shares the connection with the Sentry client
+func GrpcClient(ctx context.Context, sentryAddr string) (*direct.SentryClientRemote, PeerAdmin, error) {
 	// creating grpc client connection
 	var dialOpts []grpc.DialOption
 
@@ -774,7 +958,7 @@ func GrpcClient(ctx context.Context, sentryAddr string) (*direct.SentryClientRem
 	dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	conn, err := grpc.DialContext(ctx, sentryAddr, dialOpts...)
 	if err != nil {
-		return nil, fmt.Errorf("creating client connection to sentry P2P: %w", err)
+		return nil, nil, fmt.Errorf("creating client connection to sentry P2P: %w", err)
 	}
-	return direct.NewSentryClientRemote(proto_sentry.NewSentryClient(conn)), nil
+	return direct.NewSentryClientRemote(proto_sentry.NewSentryClient(conn)), NewPeerAdminClient(conn), nil
 }
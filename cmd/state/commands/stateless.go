@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/serenity"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/stateless"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blockFile   string
+	witnessFile string
+)
+
+func init() {
+	withChain(statelessCmd)
+	statelessCmd.Flags().StringVar(&blockFile, "blockfile", "", "file with the RLP encoded block, binary or hex")
+	must(statelessCmd.MarkFlagRequired("blockfile"))
+	statelessCmd.Flags().StringVar(&witnessFile, "witness", "", "file with the witness of the block (as returned by debug_getBlockWitness), binary or hex")
+	must(statelessCmd.MarkFlagRequired("witness"))
+	rootCmd.AddCommand(statelessCmd)
+}
+
+var statelessCmd = &cobra.Command{
+	Use:   "stateless",
+	Short: "Executes a block with nothing but its witness as the source of the state and checks the resulting state root",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return Stateless(chainConfig, blockFile, witnessFile, log.New())
+	},
+}
+
+func Stateless(chainConfig *params.ChainConfig, blockFile, witnessFile string, logger log.Logger) error {
+	if chainConfig.Consensus != params.EtHashConsensus {
+		return fmt.Errorf("stateless execution is not supported for %s consensus", chainConfig.Consensus)
+	}
+	blockRlp, err := readBinaryOrHex(blockFile)
+	if err != nil {
+		return err
+	}
+	block := new(types.Block)
+	if err = rlp.DecodeBytes(blockRlp, block); err != nil {
+		return fmt.Errorf("decode block: %w", err)
+	}
+	witness, err := readBinaryOrHex(witnessFile)
+	if err != nil {
+		return err
+	}
+
+	preRoot, postRoot, err := stateless.ExecuteBlock(chainConfig, serenity.New(ethash.NewFaker()), block, witness)
+	if err != nil {
+		return fmt.Errorf("block %d: %w", block.NumberU64(), err)
+	}
+	logger.Info("Executed block", "number", block.NumberU64(), "hash", block.Hash(), "witness", common.StorageSize(len(witness)), "parent root", preRoot)
+	if postRoot != block.Root() {
+		return fmt.Errorf("block %d: state root mismatch, computed %x, header %x", block.NumberU64(), postRoot, block.Root())
+	}
+	logger.Info("State root matches the header", "root", postRoot)
+	return nil
+}
+
+// readBinaryOrHex reads the file and decodes it if it contains a hex string
+func readBinaryOrHex(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.Trim(bytes.TrimSpace(data), `"`)
+	if bytes.HasPrefix(trimmed, []byte("0x")) {
+		return common.FromHex(string(trimmed)), nil
+	}
+	return data, nil
+}
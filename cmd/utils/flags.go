@@ -226,6 +226,10 @@ var (
 		Name:  "watch-the-burn",
 		Usage: "Enable WatchTheBurn stage to keep track of ETH issuance",
 	}
+	VerkleTrieFlag = cli.BoolFlag{
+		Name:  "experimental.verkle-trie",
+		Usage: "Enable VerkleTrie stage to maintain a verkle tree commitment to the state alongside the MPT (not supported with history.v2)",
+	}
 	// Miner settings
 	MiningEnabledFlag = cli.BoolFlag{
 		Name:  "mine",
@@ -381,6 +385,30 @@ var (
 		Name:  "http.trace",
 		Usage: "Trace HTTP requests with INFO level",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable GraphQL on the HTTP-RPC server (served at /graphql)",
+	}
+	StarknetGrpcAddressFlag = cli.StringFlag{
+		Name:  "starknet.grpc.address",
+		Usage: "Cairo runner GRPC address used to execute Starknet transactions, for example: 127.0.0.1:6066",
+	}
+	RpcFiltersDBFlag = cli.StringFlag{
+		Name:  "rpc.filters.db",
+		Usage: "Path to a local db keeping filters of eth_newFilter, eth_newBlockFilter and eth_newPendingTransactionFilter, so they survive restarts. Filters are kept in memory if not set",
+	}
+	RpcKeystoreFlag = cli.StringFlag{
+		Name:  "keystore",
+		Usage: "Directory of encrypted JSON key files, the node signs eth_sendTransaction and eth_sign requests of these accounts",
+	}
+	RpcKeystorePasswordFlag = cli.StringFlag{
+		Name:  "keystore.password",
+		Usage: "File with the passwords of the --keystore key files, one per line",
+	}
+	RpcExternalSignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "External signer (URL or path of an IPC socket), the node forwards eth_sendTransaction and eth_sign requests of its accounts to it",
+	}
 	DBReadConcurrencyFlag = cli.IntFlag{
 		Name:  "db.read.concurrency",
 		Usage: "Does limit amount of parallel db reads. Default: equal to GOMAXPROCS (or number of CPU)",
@@ -508,6 +536,10 @@ var (
 		Usage: "Version of eth p2p protocol",
 		Value: int(nodecfg.DefaultConfig.P2P.ProtocolVersion),
 	}
+	P2pServeSnapFlag = cli.BoolFlag{
+		Name:  "p2p.snap",
+		Usage: "Serve snap/1 protocol (account and storage ranges, bytecodes and trie nodes of the head state) to snap-syncing peers",
+	}
 	SentryAddrFlag = cli.StringFlag{
 		Name:  "sentry.api.addr",
 		Usage: "comma separated sentry addresses '<host>:<port>,<host>:<port>'",
@@ -652,6 +684,10 @@ var (
 		Name:  ethconfig.FlagSnapKeepBlocks,
 		Usage: "Keep ancient blocks in db (useful for debug)",
 	}
+	SnapPruneReceiptsFlag = cli.BoolFlag{
+		Name:  ethconfig.FlagSnapPruneReceipts,
+		Usage: "Remove receipts and logs of blocks frozen in snapshots from db. Only eth_getLogs and eth_getTransactionReceipt read them from snapshots",
+	}
 	SnapStopFlag = cli.BoolFlag{
 		Name:  ethconfig.FlagSnapStop,
 		Usage: "Workaround to stop producing new snapshots, if you meet some snapshots-related critical bug",
@@ -689,6 +725,11 @@ var (
 		Value: 42069,
 		Usage: "port to listen and serve BitTorrent protocol",
 	}
+	TorrentWebSeedsFlag = cli.StringFlag{
+		Name:  "torrent.webseeds",
+		Value: "",
+		Usage: "comma separated HTTP(S) base URLs or local directories to download snapshot files and their .torrent files from, when BitTorrent is not available",
+	}
 	TorrentMaxPeersFlag = cli.IntFlag{
 		Name:  "torrent.maxpeers",
 		Value: 100,
@@ -712,7 +753,7 @@ var (
 
 	HeimdallURLFlag = cli.StringFlag{
 		Name:  "bor.heimdall",
-		Usage: "URL of Heimdall service",
+		Usage: "URL of Heimdall service, or file://<dir> to replay the records exported by `integration export_heimdall`",
 		Value: "http://localhost:1317",
 	}
 
@@ -929,6 +970,9 @@ func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.GlobalIsSet(P2pProtocolVersionFlag.Name) {
 		cfg.ProtocolVersion = uint(ctx.GlobalInt(P2pProtocolVersionFlag.Name))
 	}
+	if ctx.GlobalIsSet(P2pServeSnapFlag.Name) {
+		cfg.ServeSnap = ctx.GlobalBool(P2pServeSnapFlag.Name)
+	}
 	if ctx.GlobalIsSet(SentryAddrFlag.Name) {
 		cfg.SentryAddr = SplitAndTrim(ctx.GlobalString(SentryAddrFlag.Name))
 	}
@@ -1432,6 +1476,7 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	cfg.Dirs = nodeConfig.Dirs
 	cfg.MemoryOverlay = ctx.GlobalBool(MemoryOverlayFlag.Name)
 	cfg.Snapshot.KeepBlocks = ctx.GlobalBool(SnapKeepBlocksFlag.Name)
+	cfg.Snapshot.PruneReceipts = ctx.GlobalBool(SnapPruneReceiptsFlag.Name)
 	cfg.Snapshot.Produce = !ctx.GlobalBool(SnapStopFlag.Name)
 	cfg.Snapshot.NoDownloader = ctx.GlobalBool(NoDownloaderFlag.Name)
 	cfg.Snapshot.Verify = ctx.GlobalBool(DownloaderVerifyFlag.Name)
@@ -1451,7 +1496,7 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 			panic(err)
 		}
 		log.Info("torrent verbosity", "level", lvl.LogString())
-		cfg.Downloader, err = downloadercfg.New(cfg.Dirs.Snap, lvl, dbg, nodeConfig.P2P.NAT, downloadRate, uploadRate, ctx.GlobalInt(TorrentPortFlag.Name), ctx.GlobalInt(TorrentConnsPerFileFlag.Name), ctx.GlobalInt(TorrentDownloadSlotsFlag.Name))
+		cfg.Downloader, err = downloadercfg.New(cfg.Dirs.Snap, lvl, dbg, nodeConfig.P2P.NAT, downloadRate, uploadRate, ctx.GlobalInt(TorrentPortFlag.Name), ctx.GlobalInt(TorrentConnsPerFileFlag.Name), ctx.GlobalInt(TorrentDownloadSlotsFlag.Name), SplitAndTrim(ctx.GlobalString(TorrentWebSeedsFlag.Name)))
 		if err != nil {
 			panic(err)
 		}
@@ -1481,6 +1526,7 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	cfg.Ethstats = ctx.GlobalString(EthStatsURLFlag.Name)
 	cfg.P2PEnabled = len(nodeConfig.P2P.SentryAddr) == 0
 	cfg.EnabledIssuance = ctx.GlobalIsSet(EnabledIssuance.Name)
+	cfg.VerkleTrie = ctx.GlobalIsSet(VerkleTrieFlag.Name)
 	cfg.HistoryV2 = ctx.GlobalIsSet(HistoryV2Flag.Name)
 	if ctx.GlobalIsSet(NetworkIdFlag.Name) {
 		cfg.NetworkID = ctx.GlobalUint64(NetworkIdFlag.Name)
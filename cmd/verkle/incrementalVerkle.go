@@ -3,16 +3,13 @@ package main
 import (
 	"time"
 
-	"github.com/ledgerwatch/erigon-lib/etl"
 	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon/cmd/verkle/verkletrie"
+	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/log/v3"
 )
 
-func identityFuncForVerkleTree(k []byte, value []byte, _ etl.CurrentTableReader, next etl.LoadNextFunc) error {
-	return next(k, k, value)
-}
-
 /*func readAccountKey(tx kv.RwTx, address []byte) ([]byte, error) {
 	return tx.GetOne(PedersenHashedAccountsLookup, address)
 }
@@ -56,7 +53,7 @@ func IncrementVerkleTree(cfg optionsCfg) error {
 	}
 	defer tx.Rollback()
 
-	if err := initDB(vTx); err != nil {
+	if err := verkletrie.InitDB(vTx); err != nil {
 		return err
 	}
 
@@ -70,10 +67,23 @@ func IncrementVerkleTree(cfg optionsCfg) error {
 		return err
 	}
 
-	if err := incrementAccount(vTx, tx, cfg, from, to); err != nil {
+	verkleWriter := verkletrie.NewVerkleTreeWriter(vTx, cfg.tmpdir)
+	if err := verkletrie.IncrementAccount(vTx, tx, uint64(cfg.workersCount), verkleWriter, from+1, to); err != nil {
+		return err
+	}
+	if err := verkletrie.IncrementStorage(vTx, tx, uint64(cfg.workersCount), verkleWriter, from+1, to); err != nil {
+		return err
+	}
+	root, err := verkletrie.ReadVerkleRoot(vTx, from)
+	if err != nil {
+		return err
+	}
+	newRoot, err := verkleWriter.CommitVerkleTree(root)
+	if err != nil {
 		return err
 	}
-	if err := incrementStorage(vTx, tx, cfg, from, to); err != nil {
+	log.Info("Computed verkle root", "root", common.Bytes2Hex(newRoot[:]))
+	if err := verkletrie.WriteVerkleRoot(vTx, to, newRoot); err != nil {
 		return err
 	}
 	if err := stages.SaveStageProgress(vTx, stages.VerkleTrie, to); err != nil {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/c2h5oh/datasize"
 	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon/cmd/verkle/verkletrie"
 	"github.com/ledgerwatch/log/v3"
 )
 
@@ -30,7 +31,7 @@ func analyseOut(cfg optionsCfg) error {
 		return err
 	}
 	defer tx.Rollback()
-	if err := initDB(tx); err != nil {
+	if err := verkletrie.InitDB(tx); err != nil {
 		return err
 	}
 	buckets, err := tx.ListBuckets()
@@ -0,0 +1,50 @@
+package verkletrie
+
+import (
+	"encoding/binary"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+)
+
+// ReadVerkleIncarnation returns the incarnation of the account the storage and the code in the tree belong to
+func ReadVerkleIncarnation(tx kv.Tx, address common.Address) (uint64, error) {
+	inc, err := tx.GetOne(VerkleIncarnation, address[:])
+	if err != nil {
+		return 0, err
+	}
+	if len(inc) == 0 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(inc), nil
+}
+
+func WriteVerkleIncarnation(tx kv.RwTx, address common.Address, incarnation uint64) error {
+	if incarnation == 0 {
+		return tx.Delete(VerkleIncarnation, address[:])
+	}
+	var inc [8]byte
+	binary.BigEndian.PutUint64(inc[:], incarnation)
+	return tx.Put(VerkleIncarnation, address[:], inc[:])
+}
+
+func ReadVerkleRoot(tx kv.Tx, blockNum uint64) (common.Hash, error) {
+	root, err := tx.GetOne(VerkleRoots, dbutils.EncodeBlockNumber(blockNum))
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return common.BytesToHash(root), nil
+}
+
+func WriteVerkleRoot(tx kv.RwTx, blockNum uint64, root common.Hash) error {
+	return tx.Put(VerkleRoots, dbutils.EncodeBlockNumber(blockNum), root[:])
+}
+
+// TruncateVerkleRoots removes the roots of the blocks starting from blockFrom
+func TruncateVerkleRoots(tx kv.RwTx, blockFrom uint64) error {
+	return tx.ForEach(VerkleRoots, dbutils.EncodeBlockNumber(blockFrom), func(k, _ []byte) error {
+		return tx.Delete(VerkleRoots, k)
+	})
+}
@@ -0,0 +1,64 @@
+package verkletrie
+
+import "github.com/ledgerwatch/erigon-lib/kv"
+
+const (
+	VerkleIncarnation            = "VerkleIncarnation"
+	VerkleRoots                  = "VerkleRoots"
+	PedersenHashedCodeLookup     = "PedersenHashedCodeLookup"
+	PedersenHashedAccountsLookup = "PedersenHashedAccountsLookup"
+	PedersenHashedStorageLookup  = "PedersenHashedStorageLookup"
+	VerkleTrie                   = "VerkleTrie"
+)
+
+var ExtraBuckets = []string{
+	VerkleIncarnation,
+	PedersenHashedCodeLookup,
+	PedersenHashedAccountsLookup,
+	PedersenHashedStorageLookup,
+	VerkleTrie,
+	VerkleRoots,
+}
+
+// TablesCfg returns the configuration of the verkle tables. They are not part of the default chaindata tables,
+// so they have to be registered when the database is opened
+func TablesCfg() kv.TableCfg {
+	tables := make(kv.TableCfg, len(ExtraBuckets))
+	for _, b := range ExtraBuckets {
+		tables[b] = kv.TableCfgItem{}
+	}
+	return tables
+}
+
+// ReaderTablesCfg returns the configuration of the verkle tables for the readers of a database, e.g. rpcdaemon.
+// The tables exist only if the node has run the verkle trie stage, so they are opened like the deprecated
+// tables: only if they exist, without creating them
+func ReaderTablesCfg() kv.TableCfg {
+	tables := TablesCfg()
+	for name, cfg := range tables {
+		cfg.IsDeprecated = true
+		tables[name] = cfg
+	}
+	return tables
+}
+
+// InitDB creates the verkle tables in a standalone database. It must not run concurrently with other
+// transactions, which read the tables map of the database
+func InitDB(tx kv.RwTx) error {
+	for _, b := range ExtraBuckets {
+		if err := tx.CreateBucket(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearDB removes everything written by the verkle tree generation
+func ClearDB(tx kv.RwTx) error {
+	for _, b := range ExtraBuckets {
+		if err := tx.ClearBucket(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-package main
+package verkletrie
 
 import (
 	"bytes"
@@ -15,10 +15,12 @@ import (
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/errgroup"
 )
 
+// badKeysForAddress returns the tree keys of the storage and the code of the address, and removes their lookups
 func badKeysForAddress(tx kv.RwTx, address common.Address) ([][]byte, error) {
-	var badKeys [][]byte
+	var badKeys, storageLookups, codeLookups [][]byte
 	// Delete also code and storage slots that are connected to that account (iterating over lookups is simpe)
 	storageLookupCursor, err := tx.Cursor(PedersenHashedStorageLookup)
 	if err != nil {
@@ -37,6 +39,7 @@ func badKeysForAddress(tx kv.RwTx, address common.Address) ([][]byte, error) {
 			return nil, err
 		}
 		badKeys = append(badKeys, common.CopyBytes(treeKey))
+		storageLookups = append(storageLookups, common.CopyBytes(k))
 	}
 
 	for k, treeKey, err := codeLookupCursor.Seek(address[:]); len(k) >= 20 && bytes.Equal(k[:20], address[:]); k, treeKey, err = codeLookupCursor.Next() {
@@ -44,56 +47,86 @@ func badKeysForAddress(tx kv.RwTx, address common.Address) ([][]byte, error) {
 			return nil, err
 		}
 		badKeys = append(badKeys, common.CopyBytes(treeKey))
+		codeLookups = append(codeLookups, common.CopyBytes(k))
+	}
+
+	for _, k := range storageLookups {
+		if err := tx.Delete(PedersenHashedStorageLookup, k); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range codeLookups {
+		if err := tx.Delete(PedersenHashedCodeLookup, k); err != nil {
+			return nil, err
+		}
 	}
 	return badKeys, nil
 }
 
-func incrementAccount(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) error {
+// IncrementAccount collects the changes of the accounts and the code modified in the blocks [from, to] into
+// the verkle writer, the accounts are brought to their current values in PlainState.
+func IncrementAccount(vTx kv.RwTx, tx kv.Tx, workers uint64, verkleWriter *VerkleTreeWriter, from, to uint64) error {
+	return incrementAccount(vTx, tx, workers, verkleWriter, from, to, false /* unwind */)
+}
+
+// UnwindAccount collects the changes bringing the accounts modified in the blocks [from, to] back to their
+// values before the block from. The values are taken from the account changesets, so it must be done before
+// the execution is unwound.
+func UnwindAccount(vTx kv.RwTx, tx kv.Tx, workers uint64, verkleWriter *VerkleTreeWriter, from, to uint64) error {
+	return incrementAccount(vTx, tx, workers, verkleWriter, from, to, true /* unwind */)
+}
+
+func incrementAccount(vTx kv.RwTx, tx kv.Tx, workers uint64, verkleWriter *VerkleTreeWriter, from, to uint64, unwind bool) error {
 	logInterval := time.NewTicker(30 * time.Second)
+	defer logInterval.Stop()
 	logPrefix := "IncrementVerkleAccount"
 
-	collectorLookup := etl.NewCollector(PedersenHashedCodeLookup, cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorLookup := etl.NewCollector(PedersenHashedCodeLookup, verkleWriter.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
 	defer collectorLookup.Close()
 
 	jobs := make(chan *regenerateIncrementalPedersenAccountsJob, batchSize)
 	out := make(chan *regenerateIncrementalPedersenAccountsOut, batchSize)
-	wg := new(sync.WaitGroup)
-	wg.Add(int(cfg.workersCount))
+	// The workers and the collector stop at the first error of the collector, g.Wait returns it
 	ctx, cancelWorkers := context.WithCancel(context.Background())
-	for i := 0; i < int(cfg.workersCount); i++ {
-		go func(threadNo int) {
+	defer cancelWorkers()
+	g, ctx := errgroup.WithContext(ctx)
+	wg := new(sync.WaitGroup)
+	wg.Add(int(workers))
+	for i := 0; i < int(workers); i++ {
+		g.Go(func() error {
 			defer debug.LogPanic()
 			defer wg.Done()
 			incrementalAccountWorker(ctx, logPrefix, jobs, out)
-		}(i)
+			return nil
+		})
 	}
-	defer cancelWorkers()
 
 	accountCursor, err := tx.CursorDupSort(kv.AccountChangeSet)
 	if err != nil {
 		return err
 	}
 	defer accountCursor.Close()
-	verkleWriter := NewVerkleTreeWriter(vTx, cfg.tmpdir)
 	// Start Goroutine for collection
-	go func() {
+	g.Go(func() error {
 		defer debug.LogPanic()
-		defer cancelWorkers()
 		for o := range out {
 			// Remove all bad keys
 			for _, badKey := range o.badKeys {
 				if err := verkleWriter.Insert(badKey, nil); err != nil {
-					panic(err)
+					return err
 				}
 			}
 			if o.absentInState {
+				if err := verkleWriter.DeleteAccount(o.versionHash); err != nil {
+					return err
+				}
 				continue
 			}
 			if err := verkleWriter.UpdateAccount(o.versionHash, o.codeSize, o.account); err != nil {
-				panic(err)
+				return err
 			}
 			if err := verkleWriter.WriteContractCodeChunks(o.codeKeys, o.codeChunks); err != nil {
-				panic(err)
+				return err
 			}
 			// Build lookup [address + index]
 			for i := range o.codeChunks {
@@ -101,12 +134,16 @@ func incrementAccount(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 				copy(lookupKey, o.address[:])
 				binary.BigEndian.PutUint32(lookupKey[20:], uint32(i))
 				if err := collectorLookup.Collect(lookupKey, o.codeKeys[i]); err != nil {
-					panic(err)
+					return err
 				}
 			}
 		}
-	}()
-	marker := NewVerkleMarker()
+		return nil
+	})
+	marker, err := NewVerkleMarker()
+	if err != nil {
+		return err
+	}
 	defer marker.Rollback()
 
 	accountProcessed := 0
@@ -114,7 +151,7 @@ func incrementAccount(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 		if err != nil {
 			return err
 		}
-		blockNumber, addressBytes, _, err := changeset.DecodeAccounts(k, v)
+		blockNumber, addressBytes, encodedAccount, err := changeset.DecodeAccounts(k, v)
 		if err != nil {
 			return err
 		}
@@ -124,6 +161,7 @@ func incrementAccount(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 		}
 		address := common.BytesToAddress(addressBytes)
 
+		// Only the first change of the account matters: it holds the value before the block from
 		marked, err := marker.IsMarked(addressBytes)
 		if err != nil {
 			return err
@@ -133,9 +171,10 @@ func incrementAccount(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 			continue
 		}
 
-		encodedAccount, err := tx.GetOne(kv.PlainState, addressBytes)
-		if err != nil {
-			return err
+		if !unwind {
+			if encodedAccount, err = tx.GetOne(kv.PlainState, addressBytes); err != nil {
+				return err
+			}
 		}
 		// Start
 		if len(encodedAccount) == 0 {
@@ -143,43 +182,73 @@ func incrementAccount(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 			if err != nil {
 				return err
 			}
-			jobs <- &regenerateIncrementalPedersenAccountsJob{
+			if err := WriteVerkleIncarnation(vTx, address, 0); err != nil {
+				return err
+			}
+			job := &regenerateIncrementalPedersenAccountsJob{
+				address:       address,
 				absentInState: true,
 				badKeys:       badKeys,
 			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return g.Wait()
+			}
 		} else {
 			var acc accounts.Account
 			if err := acc.DecodeForStorage(encodedAccount); err != nil {
 				return err
 			}
+			if unwind && acc.Incarnation > 0 && acc.IsEmptyCodeHash() {
+				// Code hashes of the contracts are omitted in the changesets
+				codeHash, err := tx.GetOne(kv.PlainContractCode, dbutils.PlainGenerateStoragePrefix(addressBytes, acc.Incarnation))
+				if err != nil {
+					return err
+				}
+				copy(acc.CodeHash[:], codeHash)
+			}
 			verkleIncarnation, err := ReadVerkleIncarnation(vTx, address)
 			if err != nil {
 				return err
 			}
 			var code []byte
+			if !acc.IsEmptyCodeHash() {
+				// The code is needed for its size even if the chunks are already in the tree
+				if code, err = tx.GetOne(kv.Code, acc.CodeHash[:]); err != nil {
+					return err
+				}
+			}
 			var badKeys [][]byte
-			if verkleIncarnation != acc.Incarnation {
+			codeChanged := verkleIncarnation != acc.Incarnation
+			if codeChanged {
 				// We need to update code.
-				code, err = tx.GetOne(kv.Code, acc.CodeHash[:])
+				badKeys, err = badKeysForAddress(vTx, address)
 				if err != nil {
 					return err
 				}
-				badKeys, err = badKeysForAddress(vTx, address)
-				if err != nil {
+				if err := WriteVerkleIncarnation(vTx, address, acc.Incarnation); err != nil {
 					return err
 				}
 			}
-			jobs <- &regenerateIncrementalPedersenAccountsJob{
+			job := &regenerateIncrementalPedersenAccountsJob{
 				address:       address,
 				account:       acc,
-				code:          code,
+				code:          common.CopyBytes(code),
+				codeChanged:   codeChanged,
 				absentInState: false,
 				badKeys:       badKeys,
 			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return g.Wait()
+			}
 		}
 		if err := marker.MarkAsDone(addressBytes); err != nil {
 			return err
 		}
+		accountProcessed++
 		select {
 		case <-logInterval.C:
 			log.Info("Creating Verkle Trie Incrementally", "phase", "account", "blockNum", blockNumber, "accountsProcessed", accountProcessed)
@@ -189,17 +258,11 @@ func incrementAccount(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 	close(jobs)
 	wg.Wait()
 	close(out)
-	if err := collectorLookup.Load(vTx, PedersenHashedCodeLookup, identityFuncForVerkleTree, etl.TransformArgs{Quit: context.Background().Done(),
-		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
-			return []interface{}{"key", common.Bytes2Hex(k)}
-		}}); err != nil {
-		return err
-	}
-	// Get root
-	root, err := ReadVerkleRoot(tx, from)
-	if err != nil {
+	if err := g.Wait(); err != nil {
 		return err
 	}
-	_, err = verkleWriter.CommitVerkleTree(root)
-	return err
+	return collectorLookup.Load(vTx, PedersenHashedCodeLookup, identityFuncForVerkleTree, etl.TransformArgs{Quit: context.Background().Done(),
+		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
+			return []interface{}{"key", common.Bytes2Hex(k)}
+		}})
 }
@@ -1,4 +1,4 @@
-package main
+package verkletrie
 
 import (
 	"context"
@@ -13,60 +13,78 @@ import (
 	"github.com/ledgerwatch/erigon/common/changeset"
 	"github.com/ledgerwatch/erigon/common/dbutils"
 	"github.com/ledgerwatch/erigon/common/debug"
-	"github.com/ledgerwatch/erigon/core/rawdb"
-	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/errgroup"
 )
 
-func incrementStorage(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) error {
+// IncrementStorage collects the changes of the storage modified in the blocks [from, to] into the verkle
+// writer, the slots are brought to their current values in PlainState. It relies on the incarnations
+// updated by IncrementAccount, so it must be called after it.
+func IncrementStorage(vTx kv.RwTx, tx kv.Tx, workers uint64, verkleWriter *VerkleTreeWriter, from, to uint64) error {
+	return incrementStorage(vTx, tx, workers, verkleWriter, from, to, false /* unwind */)
+}
+
+// UnwindStorage collects the changes bringing the storage modified in the blocks [from, to] back to its
+// values before the block from, taken from the storage changesets. It must be called after UnwindAccount.
+func UnwindStorage(vTx kv.RwTx, tx kv.Tx, workers uint64, verkleWriter *VerkleTreeWriter, from, to uint64) error {
+	return incrementStorage(vTx, tx, workers, verkleWriter, from, to, true /* unwind */)
+}
+
+func incrementStorage(vTx kv.RwTx, tx kv.Tx, workers uint64, verkleWriter *VerkleTreeWriter, from, to uint64, unwind bool) error {
 	logInterval := time.NewTicker(30 * time.Second)
+	defer logInterval.Stop()
 	logPrefix := "IncrementVerkleStorage"
 
-	collectorLookup := etl.NewCollector(PedersenHashedStorageLookup, cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorLookup := etl.NewCollector(PedersenHashedStorageLookup, verkleWriter.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
 	defer collectorLookup.Close()
 
 	jobs := make(chan *regeneratePedersenStorageJob, batchSize)
 	out := make(chan *regeneratePedersenStorageJob, batchSize)
-	wg := new(sync.WaitGroup)
-	wg.Add(int(cfg.workersCount))
+	// The workers and the collector stop at the first error of the collector, g.Wait returns it
 	ctx, cancelWorkers := context.WithCancel(context.Background())
-	for i := 0; i < int(cfg.workersCount); i++ {
-		go func(threadNo int) {
+	defer cancelWorkers()
+	g, ctx := errgroup.WithContext(ctx)
+	wg := new(sync.WaitGroup)
+	wg.Add(int(workers))
+	for i := 0; i < int(workers); i++ {
+		g.Go(func() error {
 			defer debug.LogPanic()
 			defer wg.Done()
 			pedersenStorageWorker(ctx, logPrefix, jobs, out)
-		}(i)
+			return nil
+		})
 	}
-	defer cancelWorkers()
 
 	storageCursor, err := tx.CursorDupSort(kv.StorageChangeSet)
 	if err != nil {
 		return err
 	}
 	defer storageCursor.Close()
-	verkleWriter := NewVerkleTreeWriter(vTx, cfg.tmpdir)
 	// Start Goroutine for collection
-	go func() {
+	g.Go(func() error {
 		defer debug.LogPanic()
-		defer cancelWorkers()
 		for o := range out {
 			if err := verkleWriter.Insert(o.storageVerkleKey[:], o.storageValue); err != nil {
-				panic(err)
+				return err
 			}
 
 			if err := collectorLookup.Collect(append(o.address[:], o.storageKey.Bytes()...), o.storageVerkleKey[:]); err != nil {
-				panic(err)
+				return err
 			}
 		}
-	}()
-	marker := NewVerkleMarker()
+		return nil
+	})
+	marker, err := NewVerkleMarker()
+	if err != nil {
+		return err
+	}
 	defer marker.Rollback()
 
 	for k, v, err := storageCursor.Seek(dbutils.EncodeBlockNumber(from)); k != nil; k, v, err = storageCursor.Next() {
 		if err != nil {
 			return err
 		}
-		blockNumber, changesetKey, _, err := changeset.DecodeStorage(k, v)
+		blockNumber, changesetKey, storageValue, err := changeset.DecodeStorage(k, v)
 		if err != nil {
 			return err
 		}
@@ -83,47 +101,46 @@ func incrementStorage(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 		if marked {
 			continue
 		}
+		if err := marker.MarkAsDone(changesetKey); err != nil {
+			return err
+		}
 
 		address := common.BytesToAddress(changesetKey[:20])
 
-		var acc accounts.Account
-		has, err := rawdb.ReadAccount(tx, address, &acc)
+		// The incarnations are already brought to the target state by the account phase
+		verkleIncarnation, err := ReadVerkleIncarnation(vTx, address)
 		if err != nil {
 			return err
 		}
 
 		storageIncarnation := binary.BigEndian.Uint64(changesetKey[20:28])
-		// Storage and code deletion is handled due to self-destruct is handled in accounts
-		if !has {
-			if err := marker.MarkAsDone(changesetKey); err != nil {
-				return err
-			}
+		// Storage and code deletion due to self-destruct is handled in accounts
+		if verkleIncarnation != storageIncarnation {
 			continue
 		}
 
-		if acc.Incarnation != storageIncarnation {
-			continue
-		}
-
-		storageValue, err := tx.GetOne(kv.PlainState, changesetKey)
-		if err != nil {
-			return err
+		if !unwind {
+			if storageValue, err = tx.GetOne(kv.PlainState, changesetKey); err != nil {
+				return err
+			}
 		}
 		storageKey := new(uint256.Int).SetBytes(changesetKey[28:])
 		var storageValueFormatted []byte
 
 		if len(storageValue) > 0 {
-			storageValueFormatted = make([]byte, 32)
-			int256ToVerkleFormat(new(uint256.Int).SetBytes(storageValue), storageValueFormatted)
+			formatted := new(uint256.Int).SetBytes(storageValue).Bytes32()
+			storageValueFormatted = formatted[:]
 		}
 
-		jobs <- &regeneratePedersenStorageJob{
+		job := &regeneratePedersenStorageJob{
 			address:      address,
 			storageKey:   storageKey,
 			storageValue: storageValueFormatted,
 		}
-		if err := marker.MarkAsDone(changesetKey); err != nil {
-			return err
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return g.Wait()
 		}
 		select {
 		case <-logInterval.C:
@@ -134,22 +151,11 @@ func incrementStorage(vTx kv.RwTx, tx kv.Tx, cfg optionsCfg, from, to uint64) er
 	close(jobs)
 	wg.Wait()
 	close(out)
-	if err := collectorLookup.Load(vTx, PedersenHashedStorageLookup, identityFuncForVerkleTree, etl.TransformArgs{Quit: context.Background().Done(),
-		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
-			return []interface{}{"key", common.Bytes2Hex(k)}
-		}}); err != nil {
+	if err := g.Wait(); err != nil {
 		return err
 	}
-	// Get root
-	root, err := ReadVerkleRoot(tx, from)
-	if err != nil {
-		return err
-	}
-	newRoot, err := verkleWriter.CommitVerkleTree(root)
-	if err != nil {
-		return err
-	}
-	log.Info("Computed verkle root", "root", common.Bytes2Hex(newRoot[:]))
-
-	return WriteVerkleRoot(vTx, to, newRoot)
+	return collectorLookup.Load(vTx, PedersenHashedStorageLookup, identityFuncForVerkleTree, etl.TransformArgs{Quit: context.Background().Done(),
+		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
+			return []interface{}{"key", common.Bytes2Hex(k)}
+		}})
 }
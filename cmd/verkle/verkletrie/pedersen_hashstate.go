@@ -1,4 +1,4 @@
-package main
+package verkletrie
 
 import (
 	"bytes"
@@ -10,11 +10,11 @@ import (
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/etl"
 	"github.com/ledgerwatch/erigon-lib/kv"
-	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/log/v3"
+	"golang.org/x/sync/errgroup"
 )
 
 /*func retrieveAccountKeys(address common.Address) (versionKey, balanceKey, codeSizeKey, codeHashKey, noncekey [32]byte) {
@@ -31,13 +31,15 @@ import (
 	return
 }*/
 
-func regeneratePedersenAccounts(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleWriter *VerkleTreeWriter) error {
+func RegeneratePedersenAccounts(outTx kv.RwTx, readTx kv.Tx, workers uint64, verkleWriter *VerkleTreeWriter) error {
 	logPrefix := "PedersenHashedAccounts"
 	start := time.Now()
 	log.Info("Started Generation of Pedersen Hashed Accounts")
 
-	collectorLookup := etl.NewCollector(PedersenHashedAccountsLookup, cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorLookup := etl.NewCollector(PedersenHashedAccountsLookup, verkleWriter.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
 	defer collectorLookup.Close()
+	collectorIncarnation := etl.NewCollector(VerkleIncarnation, verkleWriter.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	defer collectorIncarnation.Close()
 
 	plainStateCursor, err := readTx.Cursor(kv.PlainState)
 	if err != nil {
@@ -49,31 +51,42 @@ func regeneratePedersenAccounts(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, ver
 
 	jobs := make(chan *regeneratePedersenAccountsJob, batchSize)
 	out := make(chan *regeneratePedersenAccountsOut, batchSize)
-	wg := new(sync.WaitGroup)
-	wg.Add(int(cfg.workersCount))
+	// The workers and the collector stop at the first error of the collector, g.Wait returns it
 	ctx, cancelWorkers := context.WithCancel(context.Background())
-	for i := 0; i < int(cfg.workersCount); i++ {
-		go func(threadNo int) {
+	defer cancelWorkers()
+	g, ctx := errgroup.WithContext(ctx)
+	wg := new(sync.WaitGroup)
+	wg.Add(int(workers))
+	for i := 0; i < int(workers); i++ {
+		g.Go(func() error {
 			defer debug.LogPanic()
 			defer wg.Done()
 			pedersenAccountWorker(ctx, logPrefix, jobs, out)
-		}(i)
+			return nil
+		})
 	}
-	defer cancelWorkers()
 	// Start Goroutine for collection
-	go func() {
+	g.Go(func() error {
 		defer debug.LogPanic()
-		defer cancelWorkers()
 		for o := range out {
 			if err := verkleWriter.UpdateAccount(o.versionHash[:], o.codeSize, o.account); err != nil {
-				panic(err)
+				return err
 			}
 
 			if err := collectorLookup.Collect(o.address[:], o.versionHash[:]); err != nil {
-				panic(err)
+				return err
+			}
+			// Incarnations tell the incremental updates which storage and code are in the tree
+			if o.account.Incarnation > 0 {
+				var inc [8]byte
+				binary.BigEndian.PutUint64(inc[:], o.account.Incarnation)
+				if err := collectorIncarnation.Collect(o.address[:], inc[:]); err != nil {
+					return err
+				}
 			}
 		}
-	}()
+		return nil
+	})
 	for k, v, err := plainStateCursor.First(); k != nil; k, v, err = plainStateCursor.Next() {
 		if err != nil {
 			return err
@@ -91,12 +104,17 @@ func regeneratePedersenAccounts(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, ver
 				}
 				codeSize = uint64(len(code))
 			}
-			jobs <- &regeneratePedersenAccountsJob{
+			job := &regeneratePedersenAccountsJob{
 				address:  common.BytesToAddress(k),
 				account:  acc,
 				codeSize: codeSize,
 			}
 			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return g.Wait()
+			}
+			select {
 			case <-logEvery.C:
 				log.Info("[Pedersen Account Hashing] Current progress in Collection Phase", "address", "0x"+common.Bytes2Hex(k))
 			default:
@@ -107,22 +125,30 @@ func regeneratePedersenAccounts(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, ver
 	close(jobs)
 	wg.Wait()
 	close(out)
+	if err := g.Wait(); err != nil {
+		return err
+	}
 
-	collectorLookup.Load(outTx, PedersenHashedAccountsLookup, etl.IdentityLoadFunc, etl.TransformArgs{Quit: context.Background().Done(),
+	if err := collectorLookup.Load(outTx, PedersenHashedAccountsLookup, etl.IdentityLoadFunc, etl.TransformArgs{Quit: context.Background().Done(),
 		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
 			return []interface{}{"key", common.Bytes2Hex(k)}
-		}})
+		}}); err != nil {
+		return err
+	}
+	if err := collectorIncarnation.Load(outTx, VerkleIncarnation, etl.IdentityLoadFunc, etl.TransformArgs{Quit: context.Background().Done()}); err != nil {
+		return err
+	}
 	log.Info("Finished generation of Pedersen Hashed Accounts", "elapsed", time.Since(start))
 
 	return nil
 }
 
-func regeneratePedersenStorage(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleWriter *VerkleTreeWriter) error {
+func RegeneratePedersenStorage(outTx kv.RwTx, readTx kv.Tx, workers uint64, disabledLookups bool, verkleWriter *VerkleTreeWriter) error {
 	logPrefix := "PedersenHashedStorage"
 	start := time.Now()
 	log.Info("Started Generation of Pedersen Hashed Storage")
 
-	collectorLookup := etl.NewCollector(PedersenHashedStorageLookup, cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorLookup := etl.NewCollector(PedersenHashedStorageLookup, verkleWriter.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
 	defer collectorLookup.Close()
 
 	plainStateCursor, err := readTx.Cursor(kv.PlainState)
@@ -136,33 +162,36 @@ func regeneratePedersenStorage(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verk
 
 	jobs := make(chan *regeneratePedersenStorageJob, batchSize)
 	out := make(chan *regeneratePedersenStorageJob, batchSize)
-	wg := new(sync.WaitGroup)
-	wg.Add(int(cfg.workersCount))
+	// The workers and the collector stop at the first error of the collector, g.Wait returns it
 	ctx, cancelWorkers := context.WithCancel(context.Background())
-	for i := 0; i < int(cfg.workersCount); i++ {
-		go func(threadNo int) {
+	defer cancelWorkers()
+	g, ctx := errgroup.WithContext(ctx)
+	wg := new(sync.WaitGroup)
+	wg.Add(int(workers))
+	for i := 0; i < int(workers); i++ {
+		g.Go(func() error {
 			defer debug.LogPanic()
 			defer wg.Done()
 			pedersenStorageWorker(ctx, logPrefix, jobs, out)
-		}(i)
+			return nil
+		})
 	}
-	defer cancelWorkers()
 	// Start Goroutine for collection
-	go func() {
+	g.Go(func() error {
 		defer debug.LogPanic()
-		defer cancelWorkers()
 		for o := range out {
 			if err := verkleWriter.Insert(o.storageVerkleKey[:], o.storageValue); err != nil {
-				panic(err)
+				return err
 			}
-			if cfg.disabledLookups {
+			if disabledLookups {
 				continue
 			}
 			if err := collectorLookup.Collect(append(o.address[:], o.storageKey.Bytes()...), o.storageVerkleKey[:]); err != nil {
-				panic(err)
+				return err
 			}
 		}
-	}()
+		return nil
+	})
 
 	var address common.Address
 	var incarnation uint64
@@ -175,12 +204,17 @@ func regeneratePedersenStorage(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verk
 				continue
 			}
 			storageValue := new(uint256.Int).SetBytes(v).Bytes32()
-			jobs <- &regeneratePedersenStorageJob{
+			job := &regeneratePedersenStorageJob{
 				storageKey:   new(uint256.Int).SetBytes(k[28:]),
 				storageValue: storageValue[:],
 				address:      address,
 			}
 			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return g.Wait()
+			}
+			select {
 			case <-logInterval.C:
 				log.Info("[Pedersen Storage Hashing] Current progress in Collection Phase", "address", "0x"+common.Bytes2Hex(k[:20]))
 			default:
@@ -198,22 +232,27 @@ func regeneratePedersenStorage(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verk
 	close(jobs)
 	wg.Wait()
 	close(out)
+	if err := g.Wait(); err != nil {
+		return err
+	}
 
-	collectorLookup.Load(outTx, PedersenHashedStorageLookup, etl.IdentityLoadFunc, etl.TransformArgs{Quit: context.Background().Done(),
+	if err := collectorLookup.Load(outTx, PedersenHashedStorageLookup, etl.IdentityLoadFunc, etl.TransformArgs{Quit: context.Background().Done(),
 		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
 			return []interface{}{"key", common.Bytes2Hex(k)}
-		}})
+		}}); err != nil {
+		return err
+	}
 	log.Info("Finished generation of Pedersen Hashed Storage", "elapsed", time.Since(start))
 
 	return nil
 }
 
-func regeneratePedersenCode(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleWriter *VerkleTreeWriter) error {
+func RegeneratePedersenCode(outTx kv.RwTx, readTx kv.Tx, workers uint64, disabledLookups bool, verkleWriter *VerkleTreeWriter) error {
 	logPrefix := "PedersenHashedCode"
 	start := time.Now()
 	log.Info("Started Generation of Pedersen Hashed Code")
 
-	collectorLookup := etl.NewCollector(PedersenHashedCodeLookup, cfg.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
+	collectorLookup := etl.NewCollector(PedersenHashedCodeLookup, verkleWriter.tmpdir, etl.NewSortableBuffer(etl.BufferOptimalSize))
 	defer collectorLookup.Close()
 
 	plainStateCursor, err := readTx.Cursor(kv.PlainState)
@@ -227,30 +266,32 @@ func regeneratePedersenCode(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleW
 
 	jobs := make(chan *regeneratePedersenCodeJob, batchSize)
 	out := make(chan *regeneratePedersenCodeOut, batchSize)
-	wg := new(sync.WaitGroup)
-	wg.Add(int(cfg.workersCount))
+	// The workers and the collector stop at the first error of the collector, g.Wait returns it
 	ctx, cancelWorkers := context.WithCancel(context.Background())
-	for i := 0; i < int(cfg.workersCount); i++ {
-		go func(threadNo int) {
+	defer cancelWorkers()
+	g, ctx := errgroup.WithContext(ctx)
+	wg := new(sync.WaitGroup)
+	wg.Add(int(workers))
+	for i := 0; i < int(workers); i++ {
+		g.Go(func() error {
 			defer debug.LogPanic()
 			defer wg.Done()
 			pedersenCodeWorker(ctx, logPrefix, jobs, out)
-		}(i)
+			return nil
+		})
 	}
-	defer cancelWorkers()
 	// Start Goroutine for collection
-	go func() {
+	g.Go(func() error {
 		defer debug.LogPanic()
-		defer cancelWorkers()
 		for o := range out {
 			// Write code chunks
 			if o.codeSize == 0 {
 				continue
 			}
 			if err := verkleWriter.WriteContractCodeChunks(o.chunksKeys, o.chunks); err != nil {
-				panic(err)
+				return err
 			}
-			if cfg.disabledLookups {
+			if disabledLookups {
 				continue
 			}
 			for i := range o.chunks {
@@ -260,11 +301,12 @@ func regeneratePedersenCode(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleW
 				copy(lookupKey, o.address[:])
 				binary.BigEndian.PutUint32(lookupKey[20:], uint32(i))
 				if err := collectorLookup.Collect(lookupKey, o.chunksKeys[i]); err != nil {
-					panic(err)
+					return err
 				}
 			}
 		}
-	}()
+		return nil
+	})
 
 	for k, v, err := plainStateCursor.First(); k != nil; k, v, err = plainStateCursor.Next() {
 		if err != nil {
@@ -286,11 +328,16 @@ func regeneratePedersenCode(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleW
 			return err
 		}
 
-		jobs <- &regeneratePedersenCodeJob{
+		job := &regeneratePedersenCodeJob{
 			address: common.BytesToAddress(k),
 			code:    common.CopyBytes(code),
 		}
 		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return g.Wait()
+		}
+		select {
 		case <-logInterval.C:
 			log.Info("[Pedersen Code Hashing] Current progress in Collection Phase", "address", "0x"+common.Bytes2Hex(k))
 		default:
@@ -300,6 +347,9 @@ func regeneratePedersenCode(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleW
 	close(jobs)
 	wg.Wait()
 	close(out)
+	if err := g.Wait(); err != nil {
+		return err
+	}
 
 	if err := collectorLookup.Load(outTx, PedersenHashedCodeLookup, etl.IdentityLoadFunc, etl.TransformArgs{Quit: context.Background().Done(),
 		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
@@ -312,48 +362,17 @@ func regeneratePedersenCode(outTx kv.RwTx, readTx kv.Tx, cfg optionsCfg, verkleW
 	return nil
 }
 
-func RegeneratePedersenHashstate(cfg optionsCfg) error {
-	db, err := mdbx.Open(cfg.stateDb, log.Root(), true)
-	if err != nil {
-		log.Error("Error while opening database", "err", err.Error())
-		return err
-	}
-	defer db.Close()
-
-	vDb, err := mdbx.Open(cfg.stateDb, log.Root(), false)
-	if err != nil {
-		log.Error("Error while opening db transaction", "err", err.Error())
-		return err
-	}
-	defer vDb.Close()
-
-	vTx, err := vDb.BeginRw(cfg.ctx)
-	if err != nil {
-		return err
-	}
-	defer vTx.Rollback()
-
-	tx, err := db.BeginRo(cfg.ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	if err := initDB(vTx); err != nil {
-		return err
-	}
-
-	verleWriter := NewVerkleTreeWriter(vTx, cfg.tmpdir)
-
-	if err := regeneratePedersenAccounts(vTx, tx, cfg, verleWriter); err != nil {
-		return err
+// RegenerateVerkleTree builds the verkle tree of the state in PlainState from scratch and returns its root
+func RegenerateVerkleTree(outTx kv.RwTx, readTx kv.Tx, workers uint64, disabledLookups bool, verkleWriter *VerkleTreeWriter) (common.Hash, error) {
+	if err := RegeneratePedersenAccounts(outTx, readTx, workers, verkleWriter); err != nil {
+		return common.Hash{}, err
 	}
-	if err := regeneratePedersenCode(vTx, tx, cfg, verleWriter); err != nil {
-		return err
+	if err := RegeneratePedersenCode(outTx, readTx, workers, disabledLookups, verkleWriter); err != nil {
+		return common.Hash{}, err
 	}
-
-	if err := regeneratePedersenStorage(vTx, tx, cfg, verleWriter); err != nil {
-		return err
+	if err := RegeneratePedersenStorage(outTx, readTx, workers, disabledLookups, verkleWriter); err != nil {
+		return common.Hash{}, err
 	}
-	return vTx.Commit()
+	log.Info("Started Verkle Tree creation")
+	return verkleWriter.CommitVerkleTreeFromScratch()
 }
@@ -1,4 +1,4 @@
-package main
+package verkletrie
 
 import (
 	"context"
@@ -12,22 +12,22 @@ type VerkleMarker struct {
 	tx kv.RwTx
 }
 
-//nolint:gocritic
-func NewVerkleMarker() *VerkleMarker {
+func NewVerkleMarker() (*VerkleMarker, error) {
 	markedSlotsDb, err := mdbx.NewTemporaryMdbx()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	tx, err := markedSlotsDb.BeginRw(context.TODO())
 	if err != nil {
-		panic(err)
+		markedSlotsDb.Close()
+		return nil, err
 	}
 
 	return &VerkleMarker{
 		db: markedSlotsDb,
 		tx: tx,
-	}
+	}, nil
 }
 
 func (v *VerkleMarker) MarkAsDone(key []byte) error {
@@ -1,4 +1,4 @@
-package main
+package verkletrie
 
 import (
 	"context"
@@ -7,6 +7,7 @@ import (
 
 	"github.com/anacrolix/sync"
 	"github.com/gballet/go-verkle"
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/etl"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
@@ -15,6 +16,19 @@ import (
 	"github.com/ledgerwatch/log/v3"
 )
 
+func identityFuncForVerkleTree(k []byte, value []byte, _ etl.CurrentTableReader, next etl.LoadNextFunc) error {
+	return next(k, k, value)
+}
+
+func int256ToVerkleFormat(x *uint256.Int, buffer []byte) {
+	bbytes := x.ToBig().Bytes()
+	if len(bbytes) > 0 {
+		for i, b := range bbytes {
+			buffer[len(bbytes)-i-1] = b
+		}
+	}
+}
+
 func flushVerkleNode(db kv.RwTx, node verkle.VerkleNode, logInterval *time.Ticker, key []byte) error {
 	var err error
 	totalInserted := 0
@@ -55,6 +69,11 @@ func NewVerkleTreeWriter(db kv.RwTx, tmpdir string) *VerkleTreeWriter {
 	}
 }
 
+// Close removes the collected changes which were not committed
+func (v *VerkleTreeWriter) Close() {
+	v.collector.Close()
+}
+
 func (v *VerkleTreeWriter) UpdateAccount(versionKey []byte, codeSize uint64, acc accounts.Account) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
@@ -92,6 +111,21 @@ func (v *VerkleTreeWriter) UpdateAccount(versionKey []byte, codeSize uint64, acc
 	return nil
 }
 
+// DeleteAccount deletes the header leaves of the account, its storage and code are deleted separately
+func (v *VerkleTreeWriter) DeleteAccount(versionKey []byte) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, leaf := range []byte{vtree.VersionLeafKey, vtree.NonceLeafKey, vtree.BalanceLeafKey, vtree.CodeKeccakLeafKey, vtree.CodeSizeLeafKey} {
+		var key [32]byte
+		copy(key[:], versionKey[:31])
+		key[31] = leaf
+		if err := v.collector.Collect(key[:], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *VerkleTreeWriter) Insert(key, value []byte) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
@@ -123,17 +157,25 @@ func (v *VerkleTreeWriter) CommitVerkleTreeFromScratch() (common.Hash, error) {
 
 	root := verkle.New()
 
+	// flushErr is the first error of the flush callbacks, which can't return it
+	var flushErr error
+	collectNode := func(node verkle.VerkleNode) {
+		if flushErr != nil {
+			return
+		}
+		rootHash := node.ComputeCommitment().Bytes()
+		encodedNode, err := node.Serialize()
+		if err != nil {
+			flushErr = err
+			return
+		}
+		flushErr = verkleCollector.Collect(rootHash[:], encodedNode)
+	}
+
 	logInterval := time.NewTicker(30 * time.Second)
 	if err := v.collector.Load(v.db, VerkleTrie, func(k []byte, v []byte, _ etl.CurrentTableReader, next etl.LoadNextFunc) error {
 		if err := root.InsertOrdered(common.CopyBytes(k), common.CopyBytes(v), func(node verkle.VerkleNode) {
-			rootHash := node.ComputeCommitment().Bytes()
-			encodedNode, err := node.Serialize()
-			if err != nil {
-				panic(err)
-			}
-			if err := verkleCollector.Collect(rootHash[:], encodedNode); err != nil {
-				panic(err)
-			}
+			collectNode(node)
 			select {
 			case <-logInterval.C:
 				log.Info("[Verkle] Assembling Verkle Tree", "key", common.Bytes2Hex(k))
@@ -142,11 +184,20 @@ func (v *VerkleTreeWriter) CommitVerkleTreeFromScratch() (common.Hash, error) {
 		}); err != nil {
 			return err
 		}
+		if flushErr != nil {
+			return flushErr
+		}
 		return next(k, nil, nil)
 	}, etl.TransformArgs{Quit: context.Background().Done()}); err != nil {
 		return common.Hash{}, err
 	}
 
+	// Nodes of the last inserted path, including the root, are still in memory
+	root.(*verkle.InternalNode).Flush(collectNode)
+	if flushErr != nil {
+		return common.Hash{}, flushErr
+	}
+
 	log.Info("Started Verkle Tree Flushing")
 	return root.ComputeCommitment().Bytes(), verkleCollector.Load(v.db, VerkleTrie, etl.IdentityLoadFunc, etl.TransformArgs{Quit: context.Background().Done(),
 		LogDetailsLoad: func(k, v []byte) (additionalLogArguments []interface{}) {
@@ -185,9 +236,16 @@ func (v *VerkleTreeWriter) CommitVerkleTree(root common.Hash) (common.Hash, erro
 	logInterval := time.NewTicker(30 * time.Second)
 	if err := v.collector.Load(v.db, VerkleTrie, func(key []byte, value []byte, _ etl.CurrentTableReader, next etl.LoadNextFunc) error {
 		if len(value) == 0 {
-			if err := rootNode.Delete(common.CopyBytes(key), resolverFunc); err != nil {
+			// Deleting a key which is not in the tree is an error
+			current, err := rootNode.Get(key, resolverFunc)
+			if err != nil {
 				return err
 			}
+			if current != nil {
+				if err := rootNode.Delete(common.CopyBytes(key), resolverFunc); err != nil {
+					return err
+				}
+			}
 		} else {
 			if err := rootNode.Insert(common.CopyBytes(key), common.CopyBytes(value), resolverFunc); err != nil {
 				return err
@@ -0,0 +1,102 @@
+//go:build integration
+
+// Computing the verkle configuration on the first run takes minutes and stores the precomputed points
+// in the working directory, so the test is not among the unit tests
+
+package verkletrie
+
+import (
+	"context"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// regenerate builds the verkle tree of the state in tx from scratch in a separate database
+func regenerate(t *testing.T, tx kv.Tx) common.Hash {
+	outTx, err := memdb.NewTestDB(t).BeginRw(context.Background())
+	require.NoError(t, err)
+	defer outTx.Rollback()
+	require.NoError(t, InitDB(outTx))
+	root, err := RegenerateVerkleTree(outTx, tx, 2, false, NewVerkleTreeWriter(outTx, t.TempDir()))
+	require.NoError(t, err)
+	return root
+}
+
+func TestIncrementAndUnwind(t *testing.T) {
+	tx, err := memdb.NewTestDB(t).BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, InitDB(tx))
+
+	var (
+		eoa        = common.HexToAddress("0x0000000000000000000000000000000000000001")
+		created    = common.HexToAddress("0x0000000000000000000000000000000000000002")
+		contract   = common.HexToAddress("0x0000000000000000000000000000000000000003")
+		code       = common.FromHex("0x6000600155600060025500")
+		slot1      = common.HexToHash("0x01")
+		slot2      = common.HexToHash("0x02")
+		eoaAcc     = accounts.Account{Initialised: true, Balance: *uint256.NewInt(1), CodeHash: crypto.Keccak256Hash(nil)}
+		contractAc = accounts.Account{Initialised: true, Incarnation: 1, CodeHash: crypto.Keccak256Hash(code)}
+	)
+
+	// Genesis state
+	w := state.NewPlainStateWriterNoHistory(tx)
+	require.NoError(t, w.UpdateAccountData(eoa, &accounts.Account{}, &eoaAcc))
+	require.NoError(t, w.UpdateAccountCode(contract, 1, contractAc.CodeHash, code))
+	require.NoError(t, w.WriteAccountStorage(contract, 1, &slot1, uint256.NewInt(0), uint256.NewInt(5)))
+	require.NoError(t, w.UpdateAccountData(contract, &accounts.Account{}, &contractAc))
+	root0, err := RegenerateVerkleTree(tx, tx, 2, false, NewVerkleTreeWriter(tx, t.TempDir()))
+	require.NoError(t, err)
+	inc, err := ReadVerkleIncarnation(tx, contract)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), inc)
+
+	// applyBlock writes the changes of the block with its changesets and updates the verkle tree incrementally
+	applyBlock := func(blockNum uint64, prevRoot common.Hash, balance, slot1Value uint64, create bool) common.Hash {
+		w := state.NewPlainStateWriter(tx, tx, blockNum)
+		newEoaAcc := eoaAcc
+		newEoaAcc.Balance = *uint256.NewInt(balance)
+		require.NoError(t, w.UpdateAccountData(eoa, &eoaAcc, &newEoaAcc))
+		eoaAcc = newEoaAcc
+		if create {
+			require.NoError(t, w.UpdateAccountData(created, &accounts.Account{}, &accounts.Account{Initialised: true, Balance: *uint256.NewInt(3), CodeHash: crypto.Keccak256Hash(nil)}))
+			require.NoError(t, w.WriteAccountStorage(contract, 1, &slot2, uint256.NewInt(0), uint256.NewInt(7)))
+		}
+		value, err := state.NewPlainStateReader(tx).ReadAccountStorage(contract, 1, &slot1)
+		require.NoError(t, err)
+		require.NoError(t, w.WriteAccountStorage(contract, 1, &slot1, new(uint256.Int).SetBytes(value), uint256.NewInt(slot1Value)))
+		require.NoError(t, w.UpdateAccountData(contract, &contractAc, &contractAc))
+		require.NoError(t, w.WriteChangeSets())
+
+		verkleWriter := NewVerkleTreeWriter(tx, t.TempDir())
+		require.NoError(t, IncrementAccount(tx, tx, 2, verkleWriter, blockNum, blockNum))
+		require.NoError(t, IncrementStorage(tx, tx, 2, verkleWriter, blockNum, blockNum))
+		root, err := verkleWriter.CommitVerkleTree(prevRoot)
+		require.NoError(t, err)
+		return root
+	}
+
+	root1 := applyBlock(1, root0, 2, 6, true)
+	require.NotEqual(t, root0, root1)
+	require.Equal(t, regenerate(t, tx), root1)
+
+	root2 := applyBlock(2, root1, 4, 8, false)
+	require.NotEqual(t, root1, root2)
+	require.Equal(t, regenerate(t, tx), root2)
+
+	// Unwinding takes the values of block 1 from the changesets of block 2, PlainState is still at block 2
+	verkleWriter := NewVerkleTreeWriter(tx, t.TempDir())
+	require.NoError(t, UnwindAccount(tx, tx, 2, verkleWriter, 2, 2))
+	require.NoError(t, UnwindStorage(tx, tx, 2, verkleWriter, 2, 2))
+	unwoundRoot, err := verkleWriter.CommitVerkleTree(root2)
+	require.NoError(t, err)
+	require.Equal(t, root1, unwoundRoot)
+}
@@ -1,4 +1,4 @@
-package main
+package verkletrie
 
 import (
 	"context"
@@ -45,7 +45,8 @@ type regenerateIncrementalPedersenAccountsJob struct {
 	// Update
 	address       common.Address
 	account       accounts.Account
-	code          []byte // New code
+	code          []byte
+	codeChanged   bool // code chunks are written only when the incarnation of the account changes
 	absentInState bool
 	// keys to be deleted
 	badKeys [][]byte
@@ -80,13 +81,18 @@ func pedersenAccountWorker(ctx context.Context, logPrefix string, in chan *regen
 			return
 		}
 
-		// prevent sending to close channel
-		out <- &regeneratePedersenAccountsOut{
+		// the collector stops reading on errors
+		o := &regeneratePedersenAccountsOut{
 			versionHash: common.BytesToHash(vtree.GetTreeKeyVersion(job.address[:])),
 			account:     job.account,
 			address:     job.address,
 			codeSize:    job.codeSize,
 		}
+		select {
+		case out <- o:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
@@ -105,12 +111,17 @@ func pedersenStorageWorker(ctx context.Context, logPrefix string, in, out chan *
 		case <-ctx.Done():
 			return
 		}
-		out <- &regeneratePedersenStorageJob{
+		o := &regeneratePedersenStorageJob{
 			storageVerkleKey: common.BytesToHash(vtree.GetTreeKeyStorageSlot(job.address[:], job.storageKey)),
 			storageKey:       job.storageKey,
 			address:          job.address,
 			storageValue:     job.storageValue,
 		}
+		select {
+		case out <- o:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
@@ -133,12 +144,17 @@ func pedersenCodeWorker(ctx context.Context, logPrefix string, in chan *regenera
 		var chunks [][]byte
 		var chunkKeys [][]byte
 		if job.code == nil || len(job.code) == 0 {
-			out <- &regeneratePedersenCodeOut{
+			o := &regeneratePedersenCodeOut{
 				chunks:     chunks,
 				chunksKeys: chunkKeys,
 				codeSize:   0,
 				address:    job.address,
 			}
+			select {
+			case out <- o:
+			case <-ctx.Done():
+				return
+			}
 		}
 		// Chunkify contract code and build keys for each chunks and insert them in the tree
 		chunkedCode := vtree.ChunkifyCode(job.code)
@@ -162,12 +178,17 @@ func pedersenCodeWorker(ctx context.Context, logPrefix string, in chan *regenera
 				offsetOverflow = offset == 0
 			}
 		}
-		out <- &regeneratePedersenCodeOut{
+		o := &regeneratePedersenCodeOut{
 			chunks:     chunks,
 			chunksKeys: chunkKeys,
 			codeSize:   len(job.code),
 			address:    job.address,
 		}
+		select {
+		case out <- o:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
@@ -186,19 +207,29 @@ func incrementalAccountWorker(ctx context.Context, logPrefix string, in chan *re
 		case <-ctx.Done():
 			return
 		}
+		versionKey := common.BytesToHash(vtree.GetTreeKeyVersion(job.address[:]))
 		if job.absentInState {
-			out <- &regenerateIncrementalPedersenAccountsOut{
+			o := &regenerateIncrementalPedersenAccountsOut{
+				address:       job.address,
+				versionHash:   versionKey[:],
 				absentInState: job.absentInState,
 				badKeys:       job.badKeys,
 			}
+			select {
+			case out <- o:
+			case <-ctx.Done():
+				return
+			}
 			continue
 		}
-		versionKey := common.BytesToHash(vtree.GetTreeKeyVersion(job.address[:]))
 
 		var chunks [][]byte
 		var chunkKeys [][]byte
-		// Chunkify contract code and build keys for each chunks and insert them in the tree
-		chunkedCode := vtree.ChunkifyCode(job.code)
+		var chunkedCode []byte
+		if job.codeChanged {
+			// Chunkify contract code and build keys for each chunks and insert them in the tree
+			chunkedCode = vtree.ChunkifyCode(job.code)
+		}
 		offset := byte(0)
 		offsetOverflow := false
 		currentKey := vtree.GetTreeKeyCodeChunk(job.address[:], uint256.NewInt(0))
@@ -219,7 +250,7 @@ func incrementalAccountWorker(ctx context.Context, logPrefix string, in chan *re
 				offsetOverflow = offset == 0
 			}
 		}
-		out <- &regenerateIncrementalPedersenAccountsOut{
+		o := &regenerateIncrementalPedersenAccountsOut{
 			versionHash:   versionKey[:],
 			account:       job.account,
 			codeSize:      uint64(len(job.code)),
@@ -229,5 +260,10 @@ func incrementalAccountWorker(ctx context.Context, logPrefix string, in chan *re
 			badKeys:       job.badKeys,
 			address:       job.address,
 		}
+		select {
+		case out <- o:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
@@ -0,0 +1,71 @@
+package aura
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+)
+
+// API is a user facing RPC API to allow validators to inspect the step and
+// the validator set of the authority round scheme.
+type API struct {
+	aura *AuRa
+}
+
+// SignerInfo describes the local signer and its permission to seal in the current step
+type SignerInfo struct {
+	Signer     common.Address `json:"signer"`
+	Authorized bool           `json:"authorized"`
+	Step       hexutil.Uint64 `json:"step"`
+	CanPropose bool           `json:"canPropose"`
+}
+
+// GetStep returns the current step.
+func (api *API) GetStep() hexutil.Uint64 {
+	return hexutil.Uint64(api.aura.step.inner.inner.Load())
+}
+
+// GetSigner returns the local signer and whether it may still seal a block in the current step.
+func (api *API) GetSigner() SignerInfo {
+	api.aura.lock.RLock()
+	signer, signFn := api.aura.signer, api.aura.signFn
+	api.aura.lock.RUnlock()
+	return SignerInfo{
+		Signer:     signer,
+		Authorized: signFn != nil,
+		Step:       hexutil.Uint64(api.aura.step.inner.inner.Load()),
+		CanPropose: api.aura.step.canPropose.Load(),
+	}
+}
+
+// GetValidators returns the validators of the epoch of the latest processed block.
+func (api *API) GetValidators() ([]common.Address, error) {
+	return api.validators()
+}
+
+// GetStepProposer returns the validator expected to seal a block in the current step.
+func (api *API) GetStepProposer() (common.Address, error) {
+	validators, err := api.validators()
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(validators) == 0 {
+		return common.Address{}, fmt.Errorf("cannot operate with an empty validator set")
+	}
+	return validators[api.aura.step.inner.inner.Load()%uint64(len(validators))], nil
+}
+
+func (api *API) validators() ([]common.Address, error) {
+	var set ValidatorSet
+	if api.aura.cfg.ImmediateTransitions {
+		set = api.aura.cfg.Validators
+	} else {
+		set, _ = api.aura.EpochManager.currentSigners()
+	}
+	list, ok := set.(*SimpleList)
+	if !ok {
+		return nil, fmt.Errorf("validator set %T can't be listed without state access", set)
+	}
+	return list.validators, nil
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"container/list"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -38,6 +39,7 @@ import (
 	"github.com/ledgerwatch/erigon/consensus/clique"
 	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
 	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
@@ -49,6 +51,27 @@ import (
 
 const DEBUG_LOG_FROM = 999_999_999
 
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when sealing or verifying the seal of a block
+	// which can't have one, i.e. the genesis block
+	errUnknownBlock = errors.New("unknown block")
+
+	// errMissingSignature is returned if a block's seal doesn't contain a
+	// secp256k1 signature.
+	errMissingSignature = errors.New("seal missing signature")
+
+	// errInvalidSigner is returned if the seal of a block is not signed by its author.
+	errInvalidSigner = errors.New("seal not signed by block author")
+
+	// errNotAuthorized is returned when sealing is requested before a signing key
+	// is injected with Authorize.
+	errNotAuthorized = errors.New("no signing key to seal blocks with")
+)
+
 /*
 Not implemented features from OS:
  - two_thirds_majority_transition - because no chains in OE where this is != MaxUint64 - means 1/2 majority used everywhere
@@ -110,7 +133,7 @@ func (s *Step) doCalibrate() {
 
 // optCalibrate Calibrates the AuRa step number according to the current time.
 func (s *Step) optCalibrate() bool {
-	now := time.Now().Second()
+	now := time.Now().Unix()
 	var info StepDurationInfo
 	i := 0
 	for _, d := range s.durations {
@@ -133,6 +156,24 @@ func (s *Step) optCalibrate() bool {
 	return true
 }
 
+// durationRemaining returns the time left until the next step starts
+func (s *Step) durationRemaining() time.Duration {
+	nextStep := s.inner.Load() + 1
+	var info StepDurationInfo
+	for _, d := range s.durations {
+		if d.TransitionStep >= nextStep {
+			break
+		}
+		info = d
+	}
+	nextTime := info.TransitionTimestamp + (nextStep-info.TransitionStep)*info.StepDuration
+	remaining := time.Until(time.Unix(int64(nextTime), 0))
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
 type PermissionedStep struct {
 	inner      *Step
 	canPropose *atomic.Bool
@@ -171,6 +212,7 @@ func (r ReceivedStepHashes) dropAncient(step uint64) {
 
 // nolint
 type EpochManager struct {
+	mu                    sync.Mutex  // Seal reads the epoch concurrently with the block processing
 	epochTransitionHash   common.Hash // H256,
 	epochTransitionNumber uint64      // BlockNumber
 	finalityChecker       *RollingFinality
@@ -184,7 +226,19 @@ func NewEpochManager() *EpochManager {
 	}
 }
 
-func (e *EpochManager) noteNewEpoch() { e.force = true }
+func (e *EpochManager) noteNewEpoch() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.force = true
+}
+
+// currentSigners returns a copy of the validator set the finality checker was zoomed to by the latest Finalize,
+// and the number of its epoch transition
+func (e *EpochManager) currentSigners() (*SimpleList, uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.finalityChecker.signers.copy(), e.epochTransitionNumber
+}
 
 // zoomValidators - Zooms to the epoch after the header with the given hash. Returns true if succeeded, false otherwise.
 // It's analog of zoom_to_after function in OE, but doesn't require external locking
@@ -327,6 +381,9 @@ type AuRa struct {
 	exitCh chan struct{}
 	lock   sync.RWMutex // Protects the signer fields
 
+	signer common.Address  // Ethereum address of the signing key
+	signFn clique.SignerFn // Signer function to authorize hashes with
+
 	step PermissionedStep
 	// History of step hashes recently received from peers.
 	receivedStepHashes ReceivedStepHashes
@@ -478,9 +535,33 @@ func NewAuRa(config *params.AuRaConfig, db kv.RwDB, ourSigningAddress common.Add
 	}
 	_ = config
 
+	// Do not initialize timeouts for tests.
+	if step.calibrate {
+		go c.stepLoop()
+	}
+
 	return c, nil
 }
 
+// stepLoop re-calibrates the step at the start of every step and allows proposing
+// in the new one, analog of the TransitionHandler in OE
+func (c *AuRa) stepLoop() {
+	for {
+		timer := time.NewTimer(c.step.inner.durationRemaining())
+		select {
+		case <-c.exitCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		prev := c.step.inner.inner.Load()
+		c.step.inner.doCalibrate()
+		if c.step.inner.inner.Load() != prev {
+			c.step.canPropose.Store(true)
+		}
+	}
+}
+
 // A helper accumulator function mapping a step duration and a step duration transition timestamp
 // to the corresponding step number and the correct starting second of the step.
 func nextStepTimeDuration(info StepDurationInfo, time uint64) (uint64, uint64, bool) {
@@ -715,81 +796,58 @@ func (c *AuRa) VerifyUncles(chain consensus.ChainReader, header *types.Header, u
 // VerifySeal implements consensus.Engine, checking whether the signature contained
 // in the header satisfies the consensus protocol requirements.
 func (c *AuRa) VerifySeal(chain consensus.ChainHeaderReader, header *types.Header) error {
+	if header.Number.Uint64() == 0 {
+		return errUnknownBlock
+	}
+	signer, err := sealSigner(c.SealHash(header), header)
+	if err != nil {
+		return err
+	}
+	if signer != header.Coinbase {
+		return errInvalidSigner
+	}
 	return nil
-	//snap, err := c.Snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
-	//if err != nil {
-	//	return err
-	//}
-	//return c.verifySeal(chain, header, snap)
+}
+
+// sealSigner recovers the address which signed the seal of the header
+func sealSigner(sealHash common.Hash, header *types.Header) (common.Address, error) {
+	if len(header.Seal) < 2 {
+		return common.Address{}, errMissingSignature
+	}
+	var signature []byte
+	if err := rlp.DecodeBytes(header.Seal[1], &signature); err != nil {
+		return common.Address{}, err
+	}
+	public, err := secp256k1.RecoverPubkey(sealHash.Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ecdsa, err := crypto.UnmarshalPubkeyStd(public)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*ecdsa), nil
 }
 
 // Prepare implements consensus.Engine, preparing all the consensus fields of the
 // header for running the transactions on top.
 func (c *AuRa) Prepare(chain consensus.ChainHeaderReader, header *types.Header, state *state.IntraBlockState) error {
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	parentStep, err := headerStep(parent)
+	if err != nil {
+		return err
+	}
+	// the score is checked again by GenerateSeal: if the step changes in the meantime
+	// the block is not sealed
+	step := c.step.inner.inner.Load()
+	header.Difficulty = calculateScore(parentStep, step, 0).ToBig()
+	// the seal fields are filled in by Seal
+	header.Seal = nil
+	header.WithSeal = true
 	return nil
-	/// If the block isn't a checkpoint, cast a random vote (good enough for now)
-	//header.Coinbase = common.Address{}
-	//header.Nonce = types.BlockNonce{}
-	//
-	//number := header.Number.Uint64()
-	/// Assemble the voting snapshot to check which votes make sense
-	//snap, err := c.Snapshot(chain, number-1, header.ParentHash, nil)
-	//if err != nil {
-	//	return err
-	//}
-	//if number%c.config.Epoch != 0 {
-	//	c.lock.RLock()
-	//
-	//	// Gather all the proposals that make sense voting on
-	//	addresses := make([]common.Address, 0, len(c.proposals))
-	//	for address, authorize := range c.proposals {
-	//		if snap.validVote(address, authorize) {
-	//			addresses = append(addresses, address)
-	//		}
-	//	}
-	//	// If there's pending proposals, cast a vote on them
-	//	if len(addresses) > 0 {
-	//		header.Coinbase = addresses[rand.Intn(len(addresses))]
-	//		if c.proposals[header.Coinbase] {
-	//			copy(header.Nonce[:], NonceAuthVote)
-	//		} else {
-	//			copy(header.Nonce[:], nonceDropVote)
-	//		}
-	//	}
-	//	c.lock.RUnlock()
-	//}
-	/// Set the correct difficulty
-	//header.Difficulty = calcDifficulty(snap, c.signer)
-	//
-	/// Ensure the extra data has all its components
-	//if len(header.Extra) < ExtraVanity {
-	//	header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, ExtraVanity-len(header.Extra))...)
-	//}
-	//header.Extra = header.Extra[:ExtraVanity]
-	//
-	//if number%c.config.Epoch == 0 {
-	//	for _, signer := range snap.GetSigners() {
-	//		header.Extra = append(header.Extra, signer[:]...)
-	//	}
-	//}
-	//header.Extra = append(header.Extra, make([]byte, ExtraSeal)...)
-	//
-	/// Mix digest is reserved for now, set to empty
-	//header.MixDigest = common.Hash{}
-	//
-	/// Ensure the timestamp has the correct delay
-	//parent := chain.GetHeader(header.ParentHash, number-1)
-	//if parent == nil {
-	//	return consensus.ErrUnknownAncestor
-	//}
-	//header.Time = parent.Time + c.config.Period
-	//
-	//now := uint64(time.Now().Unix())
-	//if header.Time < now {
-	//	header.Time = now
-	//}
-	//
-	//return nil
 }
 
 func (c *AuRa) Initialize(config *params.ChainConfig, chain consensus.ChainHeaderReader, e consensus.EpochReader, header *types.Header, txs []types.Transaction, uncles []*types.Header, syscall consensus.SystemCall) {
@@ -832,7 +890,7 @@ func (c *AuRa) Initialize(config *params.ChainConfig, chain consensus.ChainHeade
 
 // word `signal epoch` == word `pending epoch`
 func (c *AuRa) Finalize(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState,
-	txs types.Transactions, uncles []*types.Header, receipts types.Receipts, e consensus.EpochReader,
+	txs types.Transactions, uncles []*types.Header, withdrawals []*types.Withdrawal, receipts types.Receipts, e consensus.EpochReader,
 	chain consensus.ChainHeaderReader, syscall consensus.SystemCall,
 ) (types.Transactions, types.Receipts, error) {
 	// accumulateRewards retrieves rewards for a block and applies them to the coinbase accounts for miner and uncle miners
@@ -863,8 +921,10 @@ func (c *AuRa) Finalize(config *params.ChainConfig, header *types.Header, state
 	}
 	// check_and_lock_block -> check_epoch_end_signal END
 
+	c.EpochManager.mu.Lock()
 	finalized := buildFinality(c.EpochManager, chain, e, c.cfg.Validators, header, syscall)
 	c.EpochManager.finalityChecker.print(header.Number.Uint64())
+	c.EpochManager.mu.Unlock()
 	epochEndProof, err := isEpochEnd(chain, e, finalized, header)
 	if err != nil {
 		return nil, nil, err
@@ -978,10 +1038,10 @@ func allHeadersUntil(chain consensus.ChainHeaderReader, from *types.Header, to c
 
 // FinalizeAndAssemble implements consensus.Engine
 func (c *AuRa) FinalizeAndAssemble(chainConfig *params.ChainConfig, header *types.Header, state *state.IntraBlockState,
-	txs types.Transactions, uncles []*types.Header, receipts types.Receipts, e consensus.EpochReader,
+	txs types.Transactions, uncles []*types.Header, withdrawals []*types.Withdrawal, receipts types.Receipts, e consensus.EpochReader,
 	chain consensus.ChainHeaderReader, syscall consensus.SystemCall, call consensus.Call,
 ) (*types.Block, types.Transactions, types.Receipts, error) {
-	outTxs, outReceipts, err := c.Finalize(chainConfig, header, state, txs, uncles, receipts, e, chain, syscall)
+	outTxs, outReceipts, err := c.Finalize(chainConfig, header, state, txs, uncles, withdrawals, receipts, e, chain, syscall)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -996,8 +1056,8 @@ func (c *AuRa) Authorize(signer common.Address, signFn clique.SignerFn) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	//c.signer = signer
-	//c.signFn = signFn
+	c.signer = signer
+	c.signFn = signFn
 }
 
 func (c *AuRa) GenesisEpochData(header *types.Header, caller consensus.SystemCall) ([]byte, error) {
@@ -1015,75 +1075,51 @@ func (c *AuRa) GenesisEpochData(header *types.Header, caller consensus.SystemCal
 	return res, nil
 }
 
+// Seal implements consensus.Engine, attempting to create a sealed block using
+// the local signing credentials. Blocks are only sealed by the proposer of the
+// current step, once per step; otherwise no result is delivered.
 func (c *AuRa) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	header := block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	c.lock.RLock()
+	signFn := c.signFn
+	c.lock.RUnlock()
+	if signFn == nil {
+		return errNotAuthorized
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+
+	seal := c.GenerateSeal(chain, header, parent, nil)
+	if seal == nil {
+		log.Trace("[aura] Not sealing block", "number", number, "step", c.step.inner.inner.Load())
+		return nil
+	}
+	header.Seal = seal
+	header.WithSeal = true
+
+	go func() {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			log.Warn("Sealing result is not read by miner", "sealhash", c.SealHash(header))
+		}
+	}()
+
 	return nil
-	//header := block.Header()
-	//
-	/// Sealing the genesis block is not supported
-	//number := header.Number.Uint64()
-	//if number == 0 {
-	//	return errUnknownBlock
-	//}
-	/// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
-	//if c.config.Period == 0 && len(block.Transactions()) == 0 {
-	//	log.Info("Sealing paused, waiting for transactions")
-	//	return nil
-	//}
-	/// Don't hold the signer fields for the entire sealing procedure
-	//c.lock.RLock()
-	//signer, signFn := c.signer, c.signFn
-	//c.lock.RUnlock()
-	//
-	/// Bail out if we're unauthorized to sign a block
-	//snap, err := c.Snapshot(chain, number-1, header.ParentHash, nil)
-	//if err != nil {
-	//	return err
-	//}
-	//if _, authorized := snap.Signers[signer]; !authorized {
-	//	return ErrUnauthorizedSigner
-	//}
-	/// If we're amongst the recent signers, wait for the next block
-	//for seen, recent := range snap.Recents {
-	//	if recent == signer {
-	//		// Signer is among RecentsRLP, only wait if the current block doesn't shift it out
-	//		if limit := uint64(len(snap.Signers)/2 + 1); number < limit || seen > number-limit {
-	//			log.Info("Signed recently, must wait for others")
-	//			return nil
-	//		}
-	//	}
-	//}
-	/// Sweet, the protocol permits us to sign the block, wait for our time
-	//delay := time.Unix(int64(header.Time), 0).Sub(time.Now()) // nolint: gosimple
-	//if header.Difficulty.Cmp(diffNoTurn) == 0 {
-	//	// It's not our turn explicitly to sign, delay it a bit
-	//	wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
-	//	delay += time.Duration(rand.Int63n(int64(wiggle)))
-	//
-	//	log.Trace("Out-of-turn signing requested", "wiggle", common.PrettyDuration(wiggle))
-	//}
-	/// Sign all the things!
-	//sighash, err := signFn(signer, accounts.MimetypeClique, CliqueRLP(header))
-	//if err != nil {
-	//	return err
-	//}
-	//copy(header.Extra[len(header.Extra)-ExtraSeal:], sighash)
-	/// Wait until sealing is terminated or delay timeout.
-	//log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
-	//go func() {
-	//	select {
-	//	case <-stop:
-	//		return
-	//	case <-time.After(delay):
-	//	}
-	//
-	//	select {
-	//	case results <- block.WithSeal(header):
-	//	default:
-	//		log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
-	//	}
-	//}()
-	//
-	//return nil
 }
 
 func stepProposer(validators ValidatorSet, blockHash common.Hash, step uint64, call consensus.Call) (common.Address, error) {
@@ -1146,44 +1182,35 @@ func (c *AuRa) GenerateSeal(chain consensus.ChainHeaderReader, current, parent *
 	}
 
 	_ = setNumber
-	/*
-		signature, err := c.sign(current.bareHash())
-			if err != nil {
-				log.Warn("[aura] generate_seal: FAIL: Accounts secret key unavailable.", "err", err)
-				return nil
-			}
-	*/
 
-	/*
-		  // only issue the seal if we were the first to reach the compare_exchange.
-		  if self
-			  .step
-			  .can_propose
-			  .compare_exchange(true, false, AtomicOrdering::SeqCst, AtomicOrdering::SeqCst)
-			  .is_ok()
-		  {
-			  // we can drop all accumulated empty step messages that are
-			  // older than the parent step since we're including them in
-			  // the seal
-			  self.clear_empty_steps(parent_step);
-
-			  // report any skipped primaries between the parent block and
-			  // the block we're sealing, unless we have empty steps enabled
-			  if header.number() < self.empty_steps_transition {
-				  self.report_skipped(header, step, parent_step, &*validators, set_number);
-			  }
-
-			  let mut fields =
-				  vec![encode(&step), encode(&(H520::from(signature).as_bytes()))];
-
-			  if let Some(empty_steps_rlp) = empty_steps_rlp {
-				  fields.push(empty_steps_rlp);
-			  }
-
-			  return Seal::Regular(fields);
-		  }
-	*/
-	return nil
+	c.lock.RLock()
+	signer, signFn := c.signer, c.signFn
+	c.lock.RUnlock()
+	if signFn == nil || signer != current.Coinbase {
+		log.Trace("[aura] Aborting seal generation. Not authorized to sign as step proposer.", "proposer", stepProposerAddr)
+		return nil
+	}
+	signature, err := signFn(signer, accounts.MimetypeAuRa, sealRLP(current))
+	if err != nil {
+		log.Warn("[aura] generate_seal: FAIL: Accounts secret key unavailable.", "err", err)
+		return nil
+	}
+	stepRlp, err := rlp.EncodeToBytes(step)
+	if err != nil {
+		panic(err)
+	}
+	signatureRlp, err := rlp.EncodeToBytes(signature)
+	if err != nil {
+		panic(err)
+	}
+
+	// only issue the seal if we were the first to reach the compare_exchange.
+	if !c.step.canPropose.CompareAndSwap(true, false) {
+		return nil
+	}
+	// TODO: empty steps are not supported yet, so there are no accumulated messages
+	// to clear nor skipped primaries to report
+	return []rlp.RawValue{stepRlp, signatureRlp}
 }
 
 // epochSet fetch correct validator set for epoch at header, taking into account
@@ -1192,12 +1219,20 @@ func (c *AuRa) epochSet(chain consensus.ChainHeaderReader, e consensus.EpochRead
 	if c.cfg.ImmediateTransitions {
 		return c.cfg.Validators, h.Number.Uint64(), nil
 	}
+	if e == nil {
+		// epoch transitions are not accessible while sealing, use the set the
+		// finality checker was zoomed to by the latest Finalize
+		signers, epochTransitionNumber := c.EpochManager.currentSigners()
+		return signers, epochTransitionNumber, nil
+	}
 
+	c.EpochManager.mu.Lock()
+	defer c.EpochManager.mu.Unlock()
 	finalityChecker, epochTransitionNumber, ok := c.EpochManager.zoomToAfter(chain, e, c.cfg.Validators, h.ParentHash, call)
 	if !ok {
 		return nil, 0, fmt.Errorf("unable to zoomToAfter to epoch")
 	}
-	return finalityChecker.signers, epochTransitionNumber, nil
+	return finalityChecker.signers.copy(), epochTransitionNumber, nil
 }
 
 // nolint
@@ -1246,8 +1281,23 @@ func calculateScore(parentStep, currentStep, currentEmptySteps uint64) *uint256.
 	return res
 }
 
+// SealHash returns the hash of a block prior to it being sealed, i.e. the hash
+// of the header without the seal fields - analog of bare_hash in OE
 func (c *AuRa) SealHash(header *types.Header) common.Hash {
-	return clique.SealHash(header)
+	return crypto.Keccak256Hash(sealRLP(header))
+}
+
+// sealRLP returns the rlp bytes of the header without the seal fields, which is
+// the message signed by the step proposer
+func sealRLP(header *types.Header) []byte {
+	bare := types.CopyHeader(header)
+	bare.Seal = nil
+	bare.WithSeal = true
+	b, err := rlp.EncodeToBytes(bare)
+	if err != nil {
+		panic(err)
+	}
+	return b
 }
 
 // Close implements consensus.Engine. It's a noop for clique as there are no background threads.
@@ -1257,15 +1307,15 @@ func (c *AuRa) Close() error {
 }
 
 // APIs implements consensus.Engine, returning the user facing RPC API to allow
-// controlling the signer voting.
+// validators to inspect the step and the validator set.
 func (c *AuRa) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 	return []rpc.API{
-		//{
-		//Namespace: "clique",
-		//Version:   "1.0",
-		//Service:   &API{chain: chain, clique: c},
-		//Public:    false,
-		//}
+		{
+			Namespace: "aura",
+			Version:   "1.0",
+			Service:   &API{aura: c},
+			Public:    false,
+		},
 	}
 }
 
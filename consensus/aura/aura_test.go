@@ -1,7 +1,9 @@
 package aura_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"testing"
 
@@ -12,7 +14,12 @@ import (
 	"github.com/ledgerwatch/erigon/consensus/aura/test"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/stages"
 	"github.com/stretchr/testify/require"
 )
@@ -188,3 +195,79 @@ func TestRewardContract(t *testing.T) {
 	              )
 	*/
 }
+
+// TestSealingMultipleValidators runs a chain of 3 validators, each with its own node: in every
+// step only the step proposer seals a block, and all nodes import it.
+func TestSealingMultipleValidators(t *testing.T) {
+	require := require.New(t)
+	const validatorsCount = 3
+
+	keys := make([]*ecdsa.PrivateKey, validatorsCount)
+	addrs := make([]common.Address, validatorsCount)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		require.NoError(err)
+		keys[i], addrs[i] = key, crypto.PubkeyToAddress(key.PublicKey)
+	}
+	spec := fmt.Sprintf(`{"stepDuration": 5, "startStep": 1, "validators": {"list": [%q, %q, %q]}}`, addrs[0].Hex(), addrs[1].Hex(), addrs[2].Hex())
+	// The sealed headers are decoded from the database with the seal fields
+	types.SetHeaderSealFlag(params.SokolChainConfig.IsHeaderWithSeal())
+	t.Cleanup(func() { types.SetHeaderSealFlag(false) })
+
+	engines := make([]*aura.AuRa, validatorsCount)
+	nodes := make([]*stages.MockSentry, validatorsCount)
+	for i := range nodes {
+		engine, err := aura.NewAuRa(nil, memdb.NewTestDB(t), addrs[i], []byte(spec))
+		require.NoError(err)
+		key := keys[i]
+		engine.Authorize(addrs[i], func(_ common.Address, _ string, message []byte) ([]byte, error) {
+			return crypto.Sign(crypto.Keccak256(message), key)
+		})
+		engines[i] = engine
+		nodes[i] = stages.MockWithEverything(t, core.DefaultSokolGenesisBlock(), key, prune.DefaultMode, engine, true, false)
+	}
+
+	for step := uint64(1); step <= 6; step++ {
+		proposer := int(step % validatorsCount)
+		for i, node := range nodes {
+			if i == proposer {
+				continue
+			}
+			require.NoError(stages.MiningStep(node.Ctx, node.DB, node.MiningSync))
+			<-node.PendingBlocks
+			require.Equal(0, len(node.MinedBlocks), "only the step proposer seals")
+		}
+
+		node := nodes[proposer]
+		require.NoError(stages.MiningStep(node.Ctx, node.DB, node.MiningSync))
+		<-node.PendingBlocks
+		block := <-node.MinedBlocks
+		require.Equal(step, block.NumberU64())
+		require.Equal(addrs[proposer], block.Coinbase())
+		require.NoError(engines[proposer].VerifySeal(nil, block.Header()))
+		var sealStep uint64
+		require.NoError(rlp.Decode(bytes.NewReader(block.Header().Seal[0]), &sealStep))
+		require.Equal(step, sealStep)
+
+		// the proposer can seal only once per step
+		require.NoError(stages.MiningStep(node.Ctx, node.DB, node.MiningSync))
+		<-node.PendingBlocks
+		require.Equal(0, len(node.MinedBlocks))
+
+		chain := &core.ChainPack{Headers: []*types.Header{block.Header()}, Blocks: []*types.Block{block}, TopBlock: block}
+		for _, node := range nodes {
+			require.NoError(node.InsertChain(chain))
+		}
+		for _, engine := range engines {
+			engine.NextStep()
+		}
+	}
+
+	for _, node := range nodes {
+		require.NoError(node.DB.View(node.Ctx, func(tx kv.Tx) error {
+			head := rawdb.ReadCurrentHeader(tx)
+			require.Equal(uint64(6), head.Number.Uint64())
+			return nil
+		}))
+	}
+}
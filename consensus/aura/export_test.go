@@ -0,0 +1,8 @@
+package aura
+
+// NextStep moves the engine to the next step and allows proposing in it, as stepLoop does when the step
+// is calibrated by time. The tests set `startStep` in the spec, so the steps don't depend on the time
+func (c *AuRa) NextStep() {
+	c.step.inner.inner.Inc()
+	c.step.canPropose.Store(true)
+}
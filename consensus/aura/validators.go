@@ -333,6 +333,10 @@ func NewSimpleList(validators []common.Address) *SimpleList {
 	return &SimpleList{validators: validators}
 }
 
+func (s *SimpleList) copy() *SimpleList {
+	return NewSimpleList(append([]common.Address(nil), s.validators...))
+}
+
 // nolint
 type ReportQueueItem struct {
 	addr     common.Address
@@ -252,7 +252,12 @@ func New(
 	signatures, _ := lru.NewARC(inmemorySignatures)
 	vABI, _ := abi.JSON(strings.NewReader(validatorsetABI))
 	sABI, _ := abi.JSON(strings.NewReader(stateReceiverABI))
-	heimdallClient, _ := NewHeimdallClient(heimdallURL)
+	var heimdallClient IHeimdallClient
+	if dir := strings.TrimPrefix(heimdallURL, "file://"); dir != heimdallURL {
+		heimdallClient = NewFileHeimdallClient(dir)
+	} else {
+		heimdallClient, _ = NewHeimdallClient(heimdallURL)
+	}
 	genesisContractsClient := NewGenesisContractsClient(chainConfig, borConfig.ValidatorContract, borConfig.StateReceiverContract)
 	c := &Bor{
 		chainConfig:            chainConfig,
@@ -706,7 +711,7 @@ func (c *Bor) Prepare(chain consensus.ChainHeaderReader, header *types.Header, s
 
 // Finalize implements consensus.Engine, ensuring no uncles are set, nor block
 // rewards given.
-func (c *Bor) Finalize(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs types.Transactions, uncles []*types.Header, r types.Receipts, e consensus.EpochReader, chain consensus.ChainHeaderReader, syscall consensus.SystemCall) (types.Transactions, types.Receipts, error) {
+func (c *Bor) Finalize(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs types.Transactions, uncles []*types.Header, withdrawals []*types.Withdrawal, r types.Receipts, e consensus.EpochReader, chain consensus.ChainHeaderReader, syscall consensus.SystemCall) (types.Transactions, types.Receipts, error) {
 	var err error
 	headerNumber := header.Number.Uint64()
 	if headerNumber%c.config.Sprint == 0 {
@@ -772,7 +777,7 @@ func (c *Bor) changeContractCodeIfNeeded(headerNumber uint64, state *state.Intra
 
 // FinalizeAndAssemble implements consensus.Engine, ensuring no uncles are set,
 // nor block rewards given, and returns the final block.
-func (c *Bor) FinalizeAndAssemble(chainConfig *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs types.Transactions, uncles []*types.Header, receipts types.Receipts,
+func (c *Bor) FinalizeAndAssemble(chainConfig *params.ChainConfig, header *types.Header, state *state.IntraBlockState, txs types.Transactions, uncles []*types.Header, withdrawals []*types.Withdrawal, receipts types.Receipts,
 	e consensus.EpochReader, chain consensus.ChainHeaderReader, syscall consensus.SystemCall, call consensus.Call) (*types.Block, types.Transactions, types.Receipts, error) {
 	// stateSyncData := []*types.StateSyncData{}
 
@@ -1065,16 +1070,12 @@ func (c *Bor) getSpanForBlock(blockNum uint64) (*HeimdallSpan, error) {
 			spanID = c.spanCache.Max().(*HeimdallSpan).ID + 1
 		}
 		for span == nil || span.EndBlock < blockNum {
-			var heimdallSpan HeimdallSpan
 			log.Info("Span with high enough block number is not loaded", "fetching span", spanID)
-			response, err := c.HeimdallClient.FetchWithRetry(c.execCtx, fmt.Sprintf("bor/span/%d", spanID), "")
+			heimdallSpan, err := c.getSpan(spanID)
 			if err != nil {
 				return nil, err
 			}
-			if err := json.Unmarshal(response.Result, &heimdallSpan); err != nil {
-				return nil, err
-			}
-			span = &heimdallSpan
+			span = heimdallSpan
 			c.spanCache.ReplaceOrInsert(span)
 			spanID++
 		}
@@ -1082,16 +1083,12 @@ func (c *Bor) getSpanForBlock(blockNum uint64) (*HeimdallSpan, error) {
 		for span.StartBlock > blockNum {
 			// Span wit low enough block number is not loaded
 			var spanID = span.ID - 1
-			var heimdallSpan HeimdallSpan
 			log.Info("Span with low enough block number is not loaded", "fetching span", spanID)
-			response, err := c.HeimdallClient.FetchWithRetry(c.execCtx, fmt.Sprintf("bor/span/%d", spanID), "")
+			heimdallSpan, err := c.getSpan(spanID)
 			if err != nil {
 				return nil, err
 			}
-			if err := json.Unmarshal(response.Result, &heimdallSpan); err != nil {
-				return nil, err
-			}
-			span = &heimdallSpan
+			span = heimdallSpan
 			c.spanCache.ReplaceOrInsert(span)
 		}
 	}
@@ -1117,14 +1114,11 @@ func (c *Bor) fetchAndCommitSpan(
 		}
 		heimdallSpan = *s
 	} else {
-		response, err := c.HeimdallClient.FetchWithRetry(c.execCtx, fmt.Sprintf("bor/span/%d", newSpanID), "")
+		s, err := c.getSpan(newSpanID)
 		if err != nil {
 			return err
 		}
-
-		if err := json.Unmarshal(response.Result, &heimdallSpan); err != nil {
-			return err
-		}
+		heimdallSpan = *s
 	}
 
 	// check if chain id matches with heimdall span
@@ -1204,8 +1198,7 @@ func (c *Bor) CommitStates(
 		"Fetching state updates from Heimdall",
 		"fromID", lastStateID+1,
 		"to", to.Format(time.RFC3339))
-	eventRecords, err := c.HeimdallClient.FetchStateSyncEvents(c.execCtx, lastStateID+1, to.Unix())
-
+	eventRecords, err := c.getStateSyncEvents(number, lastStateID+1, to.Unix())
 	if err != nil {
 		return nil, err
 	}
@@ -1249,6 +1242,42 @@ func validateEventRecord(eventRecord *EventRecordWithTime, number uint64, to tim
 	return nil
 }
 
+// getSpan returns the span from the bor DB, where it is saved by the BorHeimdall stage, or from Heimdall if it's not there
+func (c *Bor) getSpan(spanID uint64) (*HeimdallSpan, error) {
+	if c.DB != nil {
+		var span *HeimdallSpan
+		if err := c.DB.View(c.execCtx, func(tx kv.Tx) (err error) {
+			span, err = ReadSpan(tx, spanID)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if span != nil {
+			return span, nil
+		}
+	}
+	return fetchSpan(c.execCtx, c.HeimdallClient, spanID)
+}
+
+// getStateSyncEvents returns the events to commit at the block from the bor DB, where they are saved by
+// the BorHeimdall stage, or from Heimdall if they are not there
+func (c *Bor) getStateSyncEvents(blockNum uint64, fromID uint64, to int64) ([]*EventRecordWithTime, error) {
+	if c.DB != nil {
+		var eventRecords []*EventRecordWithTime
+		var ok bool
+		if err := c.DB.View(c.execCtx, func(tx kv.Tx) (err error) {
+			eventRecords, ok, err = ReadStateSyncEvents(tx, blockNum, fromID)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if ok {
+			return eventRecords, nil
+		}
+	}
+	return c.HeimdallClient.FetchStateSyncEvents(c.execCtx, fromID, to)
+}
+
 func (c *Bor) SetHeimdallClient(h IHeimdallClient) {
 	c.HeimdallClient = h
 }
@@ -0,0 +1,99 @@
+package bor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+)
+
+// FileHeimdallClient replays Heimdall responses saved in the directory, in the layout of Heimdall REST API:
+// <dir>/bor/span/<id> and <dir>/clerk/event-record/<id> contain the responses to the requests with these paths.
+// See ExportHeimdallRecords to create such directory from the bor DB.
+type FileHeimdallClient struct {
+	dir string
+}
+
+func NewFileHeimdallClient(dir string) *FileHeimdallClient {
+	return &FileHeimdallClient{dir: dir}
+}
+
+func (h *FileHeimdallClient) Fetch(_ context.Context, rawPath string, _ string) (*ResponseWithHeight, error) {
+	data, err := os.ReadFile(filepath.Join(h.dir, filepath.FromSlash(path.Clean("/"+rawPath))))
+	if err != nil {
+		return nil, err
+	}
+	var response ResponseWithHeight
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("%s: %w", rawPath, err)
+	}
+	return &response, nil
+}
+
+// FetchWithRetry - same as Fetch, the missing files don't appear by themselves
+func (h *FileHeimdallClient) FetchWithRetry(ctx context.Context, rawPath string, rawQuery string) (*ResponseWithHeight, error) {
+	return h.Fetch(ctx, rawPath, rawQuery)
+}
+
+// FetchStateSyncEvents returns the events with sequential ids starting from fromID, until the one which is missing
+// or is not earlier than to
+func (h *FileHeimdallClient) FetchStateSyncEvents(ctx context.Context, fromID uint64, to int64) ([]*EventRecordWithTime, error) {
+	eventRecords := make([]*EventRecordWithTime, 0)
+	for id := fromID; ; id++ {
+		response, err := h.Fetch(ctx, fmt.Sprintf("clerk/event-record/%d", id), "")
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var eventRecord EventRecordWithTime
+		if err := json.Unmarshal(response.Result, &eventRecord); err != nil {
+			return nil, err
+		}
+		if eventRecord.Time.Unix() >= to {
+			break
+		}
+		eventRecords = append(eventRecords, &eventRecord)
+	}
+	return eventRecords, nil
+}
+
+// ExportHeimdallRecords writes the spans and state sync events saved in the bor DB to the directory, so they can be
+// replayed by FileHeimdallClient
+func ExportHeimdallRecords(tx kv.Tx, dir string) (spans int, events int, err error) {
+	for _, export := range []struct {
+		prefix  []byte
+		dir     string
+		written *int
+	}{
+		{spanPrefix, filepath.Join(dir, "bor", "span"), &spans},
+		{eventPrefix, filepath.Join(dir, "clerk", "event-record"), &events},
+	} {
+		if err = os.MkdirAll(export.dir, 0755); err != nil {
+			return spans, events, err
+		}
+		if err = tx.ForPrefix(kv.BorSeparate, export.prefix, func(k, v []byte) error {
+			data, err := json.Marshal(&ResponseWithHeight{Height: "0", Result: v})
+			if err != nil {
+				return err
+			}
+			id := recordID(export.prefix, k)
+			if err = os.WriteFile(filepath.Join(export.dir, strconv.FormatUint(id, 10)), data, 0644); err != nil {
+				return err
+			}
+			*export.written++
+			return nil
+		}); err != nil {
+			return spans, events, err
+		}
+	}
+	return spans, events, nil
+}
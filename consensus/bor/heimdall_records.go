@@ -0,0 +1,255 @@
+package bor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// Spans and state sync events are kept in the bor DB next to the snapshots, so blocks can be executed
+// without querying Heimdall. They are saved by the BorHeimdall stage before Execution reaches the blocks.
+var (
+	spanPrefix      = []byte("heimdall-span-")      // span_id_u64 -> JSON of HeimdallSpan
+	eventPrefix     = []byte("heimdall-event-")     // event_id_u64 -> JSON of EventRecordWithTime
+	lastEventPrefix = []byte("heimdall-lastevent-") // block_num_u64 -> id of the last event which can be committed at the block
+)
+
+func recordKey(prefix []byte, id uint64) []byte {
+	k := make([]byte, len(prefix)+8)
+	copy(k, prefix)
+	binary.BigEndian.PutUint64(k[len(prefix):], id)
+	return k
+}
+
+func recordID(prefix []byte, k []byte) uint64 {
+	return binary.BigEndian.Uint64(k[len(prefix):])
+}
+
+// lastRecord returns the record with the highest id lower than before
+func lastRecord(tx kv.Tx, prefix []byte, before uint64) (uint64, []byte, error) {
+	c, err := tx.Cursor(kv.BorSeparate)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer c.Close()
+	k, _, err := c.Seek(recordKey(prefix, before))
+	if err != nil {
+		return 0, nil, err
+	}
+	if k == nil {
+		k, _, err = c.Last()
+	} else {
+		k, _, err = c.Prev()
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if k == nil || !bytes.HasPrefix(k, prefix) {
+		return 0, nil, nil
+	}
+	v, err := tx.GetOne(kv.BorSeparate, k)
+	if err != nil {
+		return 0, nil, err
+	}
+	return recordID(prefix, k), v, nil
+}
+
+func WriteSpan(tx kv.Putter, span *HeimdallSpan) error {
+	v, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+	return tx.Put(kv.BorSeparate, recordKey(spanPrefix, span.ID), v)
+}
+
+// ReadSpan returns nil if the span is not saved
+func ReadSpan(tx kv.Getter, spanID uint64) (*HeimdallSpan, error) {
+	v, err := tx.GetOne(kv.BorSeparate, recordKey(spanPrefix, spanID))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	var span HeimdallSpan
+	if err := json.Unmarshal(v, &span); err != nil {
+		return nil, fmt.Errorf("span %d: %w", spanID, err)
+	}
+	return &span, nil
+}
+
+// LastSpan returns the span with the highest id, nil if there are no spans saved
+func LastSpan(tx kv.Tx) (*HeimdallSpan, error) {
+	_, v, err := lastRecord(tx, spanPrefix, ^uint64(0))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	var span HeimdallSpan
+	if err := json.Unmarshal(v, &span); err != nil {
+		return nil, err
+	}
+	return &span, nil
+}
+
+func WriteEventRecord(tx kv.Putter, event *EventRecordWithTime) error {
+	v, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return tx.Put(kv.BorSeparate, recordKey(eventPrefix, event.ID), v)
+}
+
+// ReadEventRecord returns nil if the event is not saved
+func ReadEventRecord(tx kv.Getter, eventID uint64) (*EventRecordWithTime, error) {
+	v, err := tx.GetOne(kv.BorSeparate, recordKey(eventPrefix, eventID))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	var event EventRecordWithTime
+	if err := json.Unmarshal(v, &event); err != nil {
+		return nil, fmt.Errorf("state sync event %d: %w", eventID, err)
+	}
+	return &event, nil
+}
+
+// LastEventID returns the highest id of the saved state sync events, 0 if there are none
+func LastEventID(tx kv.Tx) (uint64, error) {
+	id, _, err := lastRecord(tx, eventPrefix, ^uint64(0))
+	return id, err
+}
+
+// ReadStateSyncEvents returns the saved events with ids starting from fromID, which can be committed at the block.
+// ok is false if the events of the block are not saved.
+func ReadStateSyncEvents(tx kv.Getter, blockNum uint64, fromID uint64) (events []*EventRecordWithTime, ok bool, err error) {
+	v, err := tx.GetOne(kv.BorSeparate, recordKey(lastEventPrefix, blockNum))
+	if err != nil || v == nil {
+		return nil, false, err
+	}
+	for id := fromID; id <= binary.BigEndian.Uint64(v); id++ {
+		event, err := ReadEventRecord(tx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		if event == nil {
+			return nil, false, nil
+		}
+		events = append(events, event)
+	}
+	return events, true, nil
+}
+
+// FetchHeimdallRecords saves to the bor DB the records needed to execute the block: its span, the next span
+// if the block is in the last sprint of its span, and state sync events committed at the block if it starts a sprint.
+// headerTime returns the time of the earlier block of the chain.
+func FetchHeimdallRecords(ctx context.Context, tx kv.RwTx, client IHeimdallClient, config *params.BorConfig, blockNum uint64, headerTime func(blockNum uint64) (uint64, error)) error {
+	span, err := LastSpan(tx)
+	if err != nil {
+		return err
+	}
+	for span == nil || span.EndBlock < blockNum+config.Sprint {
+		var spanID uint64
+		if span != nil {
+			spanID = span.ID + 1
+		}
+		if span, err = fetchSpan(ctx, client, spanID); err != nil {
+			return err
+		}
+		if err = WriteSpan(tx, span); err != nil {
+			return err
+		}
+	}
+
+	if blockNum == 0 || blockNum%config.Sprint != 0 {
+		return nil
+	}
+	lastEventKey := recordKey(lastEventPrefix, blockNum)
+	if v, err := tx.GetOne(kv.BorSeparate, lastEventKey); err != nil || v != nil {
+		return err
+	}
+	// Same as in Bor.CommitStates - the events up to the beginning of the previous sprint
+	to, err := headerTime(blockNum - config.Sprint)
+	if err != nil {
+		return err
+	}
+	lastEventID, err := LastEventID(tx)
+	if err != nil {
+		return err
+	}
+	events, err := client.FetchStateSyncEvents(ctx, lastEventID+1, int64(to))
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err = WriteEventRecord(tx, event); err != nil {
+			return err
+		}
+		if event.ID > lastEventID {
+			lastEventID = event.ID
+		}
+	}
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, lastEventID)
+	return tx.Put(kv.BorSeparate, lastEventKey, v)
+}
+
+// UnwindHeimdallRecords removes the records fetched for the blocks after the unwind point
+func UnwindHeimdallRecords(tx kv.RwTx, unwindPoint uint64) error {
+	_, v, err := lastRecord(tx, lastEventPrefix, unwindPoint+1)
+	if err != nil {
+		return err
+	}
+	var lastEventID uint64
+	if v != nil {
+		lastEventID = binary.BigEndian.Uint64(v)
+	}
+	if err = deleteRecords(tx, lastEventPrefix, unwindPoint+1); err != nil {
+		return err
+	}
+	if err = deleteRecords(tx, eventPrefix, lastEventID+1); err != nil {
+		return err
+	}
+	for {
+		span, err := LastSpan(tx)
+		if err != nil {
+			return err
+		}
+		if span == nil || span.StartBlock <= unwindPoint {
+			return nil
+		}
+		if err = tx.Delete(kv.BorSeparate, recordKey(spanPrefix, span.ID)); err != nil {
+			return err
+		}
+	}
+}
+
+// deleteRecords deletes the records with ids starting from fromID
+func deleteRecords(tx kv.RwTx, prefix []byte, fromID uint64) error {
+	c, err := tx.RwCursor(kv.BorSeparate)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	for k, _, err := c.Seek(recordKey(prefix, fromID)); k != nil && bytes.HasPrefix(k, prefix); k, _, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		if err = c.DeleteCurrent(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchSpan(ctx context.Context, client IHeimdallClient, spanID uint64) (*HeimdallSpan, error) {
+	response, err := client.FetchWithRetry(ctx, fmt.Sprintf("bor/span/%d", spanID), "")
+	if err != nil {
+		return nil, err
+	}
+	var span HeimdallSpan
+	if err := json.Unmarshal(response.Result, &span); err != nil {
+		return nil, err
+	}
+	return &span, nil
+}
@@ -0,0 +1,140 @@
+package bor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/stretchr/testify/require"
+)
+
+// testHeimdallClient serves spans of 64 blocks and events with ids 1..events, the event i happened at the time i*10
+type testHeimdallClient struct {
+	events uint64
+}
+
+func (h *testHeimdallClient) Fetch(_ context.Context, path string, _ string) (*ResponseWithHeight, error) {
+	var spanID uint64
+	if _, err := fmt.Sscanf(path, "bor/span/%d", &spanID); err != nil {
+		return nil, err
+	}
+	result, err := json.Marshal(&HeimdallSpan{Span: Span{ID: spanID, StartBlock: spanID * 64, EndBlock: spanID*64 + 63}, ChainID: "80001"})
+	if err != nil {
+		return nil, err
+	}
+	return &ResponseWithHeight{Height: "1", Result: result}, nil
+}
+
+func (h *testHeimdallClient) FetchWithRetry(ctx context.Context, path string, query string) (*ResponseWithHeight, error) {
+	return h.Fetch(ctx, path, query)
+}
+
+func (h *testHeimdallClient) FetchStateSyncEvents(_ context.Context, fromID uint64, to int64) ([]*EventRecordWithTime, error) {
+	var events []*EventRecordWithTime
+	for id := fromID; id <= h.events && int64(id*10) < to; id++ {
+		events = append(events, &EventRecordWithTime{EventRecord: EventRecord{ID: id, Data: []byte{byte(id)}, ChainID: "80001"}, Time: time.Unix(int64(id*10), 0).UTC()})
+	}
+	return events, nil
+}
+
+func eventIDs(events []*EventRecordWithTime) []uint64 {
+	var ids []uint64
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestHeimdallRecords(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	ctx := context.Background()
+	client := &testHeimdallClient{events: 10}
+	config := &params.BorConfig{Sprint: 16}
+	// the block n has the time n
+	headerTime := func(blockNum uint64) (uint64, error) { return blockNum, nil }
+	fetch := func(from, to uint64) {
+		for blockNum := from; blockNum <= to; blockNum++ {
+			require.NoError(t, FetchHeimdallRecords(ctx, tx, client, config, blockNum, headerTime))
+		}
+	}
+	fetch(1, 64)
+
+	span, err := LastSpan(tx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), span.ID)
+	span, err = ReadSpan(tx, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(63), span.EndBlock)
+	lastEventID, err := LastEventID(tx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), lastEventID)
+
+	check := func(blockNum, fromID uint64, expectedOk bool, expected ...uint64) {
+		t.Helper()
+		events, ok, err := ReadStateSyncEvents(tx, blockNum, fromID)
+		require.NoError(t, err)
+		require.Equal(t, expectedOk, ok)
+		if ok {
+			require.Equal(t, expected, eventIDs(events))
+		}
+	}
+	check(16, 1, true)
+	check(32, 1, true, 1)
+	check(48, 2, true, 2, 3)
+	check(64, 4, true, 4)
+	check(33, 2, false)
+	check(80, 5, false)
+
+	require.NoError(t, UnwindHeimdallRecords(tx, 40))
+	span, err = LastSpan(tx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), span.ID)
+	lastEventID, err = LastEventID(tx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), lastEventID)
+	check(32, 1, true, 1)
+	check(48, 2, false)
+
+	// the new fork has later blocks, so more events are committed at the sprint
+	headerTime = func(blockNum uint64) (uint64, error) {
+		if blockNum > 40 {
+			return blockNum + 20, nil
+		}
+		return blockNum, nil
+	}
+	fetch(41, 80)
+	check(48, 2, true, 2, 3)
+	check(64, 4, true, 4, 5, 6)
+	check(80, 7, true, 7, 8)
+
+	dir := t.TempDir()
+	spans, events, err := ExportHeimdallRecords(tx, dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, spans)
+	require.Equal(t, 8, events)
+
+	fileClient := NewFileHeimdallClient(dir)
+	span, err = fetchSpan(ctx, fileClient, 1)
+	require.NoError(t, err)
+	expected, err := fetchSpan(ctx, client, 1)
+	require.NoError(t, err)
+	require.Equal(t, expected, span)
+	_, err = fileClient.Fetch(ctx, "bor/span/2", "")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	fileEvents, err := fileClient.FetchStateSyncEvents(ctx, 2, 60)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3, 4, 5}, eventIDs(fileEvents))
+	expectedEvents, err := client.FetchStateSyncEvents(ctx, 2, 60)
+	require.NoError(t, err)
+	require.Equal(t, expectedEvents, fileEvents)
+	// no more events are exported
+	fileEvents, err = fileClient.FetchStateSyncEvents(ctx, 7, 1000)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{7, 8}, eventIDs(fileEvents))
+}
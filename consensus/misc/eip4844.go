@@ -0,0 +1,79 @@
+package misc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+// VerifyEip4844Header verifies the presence and correctness of the blob gas fields
+// of a header, which were added in EIP-4844.
+func VerifyEip4844Header(config *params.ChainConfig, parent, header *types.Header) error {
+	if header.BlobGasUsed == nil {
+		return fmt.Errorf("header is missing blobGasUsed")
+	}
+	if header.ExcessBlobGas == nil {
+		return fmt.Errorf("header is missing excessBlobGas")
+	}
+	blobGasUsed := *header.BlobGasUsed
+	if blobGasUsed > params.MaxBlobGasPerBlock {
+		return fmt.Errorf("blob gas used %d exceeds maximum allowance %d", blobGasUsed, params.MaxBlobGasPerBlock)
+	}
+	if blobGasUsed%params.BlobTxBlobGasPerBlob != 0 {
+		return fmt.Errorf("blob gas used %d not a multiple of blob gas per blob %d", blobGasUsed, params.BlobTxBlobGasPerBlob)
+	}
+	// Verify the excessBlobGas is correct based on the parent header
+	expectedExcessBlobGas := CalcExcessBlobGas(config, parent)
+	if *header.ExcessBlobGas != expectedExcessBlobGas {
+		return fmt.Errorf("invalid excessBlobGas: have %d, want %d", *header.ExcessBlobGas, expectedExcessBlobGas)
+	}
+	return nil
+}
+
+// CalcExcessBlobGas calculates the excess blob gas of the header, given its parent.
+// The blob gas fields of the parent are taken as zero before Cancun.
+func CalcExcessBlobGas(config *params.ChainConfig, parent *types.Header) uint64 {
+	var parentExcessBlobGas, parentBlobGasUsed uint64
+	if config.IsCancun(parent.Number.Uint64()) {
+		if parent.ExcessBlobGas != nil {
+			parentExcessBlobGas = *parent.ExcessBlobGas
+		}
+		if parent.BlobGasUsed != nil {
+			parentBlobGasUsed = *parent.BlobGasUsed
+		}
+	}
+	if parentExcessBlobGas+parentBlobGasUsed < params.TargetBlobGasPerBlock {
+		return 0
+	}
+	return parentExcessBlobGas + parentBlobGasUsed - params.TargetBlobGasPerBlock
+}
+
+// GetBlobGasPrice calculates the price of a unit of blob gas given the excess blob gas of the block.
+func GetBlobGasPrice(excessBlobGas uint64) (*uint256.Int, error) {
+	price := fakeExponential(big.NewInt(params.BlobTxMinBlobGasprice), new(big.Int).SetUint64(excessBlobGas), big.NewInt(params.BlobTxBlobGaspriceUpdateFraction))
+	blobGasPrice, overflow := uint256.FromBig(price)
+	if overflow {
+		return nil, fmt.Errorf("blobGasPrice higher than 2^256-1")
+	}
+	return blobGasPrice, nil
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using Taylor expansion.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
@@ -0,0 +1,112 @@
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+func TestFakeExponential(t *testing.T) {
+	tests := []struct {
+		factor, numerator, denominator int64
+		want                           int64
+	}{
+		// When numerator == 0 the return value should always equal the value of factor
+		{1, 0, 1, 1},
+		{38493, 0, 1000, 38493},
+		{0, 1234, 2345, 0}, // should be 0
+		{1, 2, 1, 6},       // approximate 7.389
+		{1, 4, 2, 6},
+		{1, 3, 1, 16}, // approximate 20.09
+		{1, 6, 2, 18},
+		{1, 4, 1, 49}, // approximate 54.60
+		{1, 8, 2, 50},
+		{10, 8, 2, 542}, // approximate 540.598
+		{11, 8, 2, 596}, // approximate 600.58
+		{1, 5, 1, 136},  // approximate 148.4
+		{1, 5, 2, 11},   // approximate 12.18
+		{2, 5, 2, 23},   // approximate 24.36
+		{1, 50000000, 2225652, 5709098764},
+	}
+	for i, tt := range tests {
+		f, n, d := big.NewInt(tt.factor), big.NewInt(tt.numerator), big.NewInt(tt.denominator)
+		original := new(big.Int).Set(f)
+		have := fakeExponential(f, n, d)
+		if have.Int64() != tt.want {
+			t.Errorf("test %d: fake exponential mismatch: have %v want %v", i, have, tt.want)
+		}
+		if f.Cmp(original) != 0 {
+			t.Errorf("test %d: pointer reuse detected, factor modified", i)
+		}
+	}
+}
+
+func TestCalcExcessBlobGas(t *testing.T) {
+	config := copyConfig(params.TestChainConfig)
+	config.CancunBlock = big.NewInt(0)
+
+	tests := []struct {
+		excess uint64
+		blobs  uint64
+		want   uint64
+	}{
+		// The excess blob gas should not increase from zero if the used blob
+		// slots are below - or equal - to the target.
+		{0, 0, 0},
+		{0, 1, 0},
+		{0, params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob, 0},
+
+		// If the target blob gas is exceeded, the excessBlobGas should increase
+		// by however much it was overshot
+		{0, (params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob) + 1, params.BlobTxBlobGasPerBlob},
+		{1, (params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob) + 1, params.BlobTxBlobGasPerBlob + 1},
+		{1, (params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob) + 2, 2*params.BlobTxBlobGasPerBlob + 1},
+
+		// The excess blob gas should decrease by however much the target was
+		// under-shot, capped at zero.
+		{params.TargetBlobGasPerBlock, params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob, params.TargetBlobGasPerBlock},
+		{params.TargetBlobGasPerBlock, (params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob) - 1, params.TargetBlobGasPerBlock - params.BlobTxBlobGasPerBlob},
+		{params.TargetBlobGasPerBlock, (params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob) - 2, params.TargetBlobGasPerBlock - (2 * params.BlobTxBlobGasPerBlob)},
+		{params.BlobTxBlobGasPerBlob - 1, (params.TargetBlobGasPerBlock / params.BlobTxBlobGasPerBlob) - 1, 0},
+	}
+	for i, tt := range tests {
+		blobGasUsed := tt.blobs * params.BlobTxBlobGasPerBlob
+		parent := &types.Header{
+			Number:        big.NewInt(1),
+			ExcessBlobGas: &tt.excess,
+			BlobGasUsed:   &blobGasUsed,
+		}
+		if have := CalcExcessBlobGas(config, parent); have != tt.want {
+			t.Errorf("test %d: excess blob gas mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+
+	// Before Cancun the parent has no blob gas fields
+	config.CancunBlock = big.NewInt(2)
+	if have := CalcExcessBlobGas(config, &types.Header{Number: big.NewInt(1)}); have != 0 {
+		t.Errorf("excess blob gas after a pre-Cancun parent: have %v, want 0", have)
+	}
+}
+
+func TestGetBlobGasPrice(t *testing.T) {
+	tests := []struct {
+		excessBlobGas uint64
+		blobGasPrice  uint64
+	}{
+		{0, 1},
+		{2314057, 1},
+		{2314058, 2},
+		{10 * 1024 * 1024, 23},
+	}
+	for i, tt := range tests {
+		have, err := GetBlobGasPrice(tt.excessBlobGas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have.Uint64() != tt.blobGasPrice {
+			t.Errorf("test %d: blob gas price mismatch: have %v want %v", i, have, tt.blobGasPrice)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/consensus"
 	"github.com/ledgerwatch/erigon/consensus/misc"
@@ -34,6 +35,15 @@ var (
 	errInvalidUncleHash = errors.New("non empty uncle hash")
 
 	errOlderBlockTime = errors.New("timestamp older than parent")
+
+	// errMissingWithdrawalsHash is returned if a Shanghai block has no withdrawals hash.
+	errMissingWithdrawalsHash = errors.New("missing withdrawals hash")
+
+	// errUnexpectedWithdrawalsHash is returned if a pre-Shanghai block has a withdrawals hash.
+	errUnexpectedWithdrawalsHash = errors.New("unexpected withdrawals hash")
+
+	// errUnexpectedBlobGas is returned if a pre-Cancun block has blob gas fields.
+	errUnexpectedBlobGas = errors.New("unexpected blobGasUsed or excessBlobGas")
 )
 
 // Serenity Consensus Engine for the Execution Layer.
@@ -119,23 +129,38 @@ func (s *Serenity) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 }
 
 func (s *Serenity) Finalize(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState,
-	txs types.Transactions, uncles []*types.Header, r types.Receipts, e consensus.EpochReader,
+	txs types.Transactions, uncles []*types.Header, withdrawals []*types.Withdrawal, r types.Receipts, e consensus.EpochReader,
 	chain consensus.ChainHeaderReader, syscall consensus.SystemCall,
 ) (types.Transactions, types.Receipts, error) {
 	if !IsPoSHeader(header) {
-		return s.eth1Engine.Finalize(config, header, state, txs, uncles, r, e, chain, syscall)
+		return s.eth1Engine.Finalize(config, header, state, txs, uncles, withdrawals, r, e, chain, syscall)
+	}
+	// Withdrawals are processed after all transactions and unconditionally credit the recipient, see EIP-4895
+	for _, w := range withdrawals {
+		amountInWei := new(uint256.Int).Mul(uint256.NewInt(w.Amount), uint256.NewInt(params.GWei))
+		state.AddBalance(w.Address, amountInWei)
 	}
 	return txs, r, nil
 }
 
 func (s *Serenity) FinalizeAndAssemble(config *params.ChainConfig, header *types.Header, state *state.IntraBlockState,
-	txs types.Transactions, uncles []*types.Header, receipts types.Receipts, e consensus.EpochReader,
+	txs types.Transactions, uncles []*types.Header, withdrawals []*types.Withdrawal, receipts types.Receipts, e consensus.EpochReader,
 	chain consensus.ChainHeaderReader, syscall consensus.SystemCall, call consensus.Call,
 ) (*types.Block, types.Transactions, types.Receipts, error) {
 	if !IsPoSHeader(header) {
-		return s.eth1Engine.FinalizeAndAssemble(config, header, state, txs, uncles, receipts, e, chain, syscall, call)
+		return s.eth1Engine.FinalizeAndAssemble(config, header, state, txs, uncles, withdrawals, receipts, e, chain, syscall, call)
+	}
+	outTxs, outReceipts, err := s.Finalize(config, header, state, txs, uncles, withdrawals, receipts, e, chain, syscall)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	return types.NewBlock(header, txs, uncles, receipts), txs, receipts, nil
+	if config.IsShanghai(header.Number.Uint64()) {
+		if withdrawals == nil {
+			withdrawals = []*types.Withdrawal{}
+		}
+		return types.NewBlockWithWithdrawals(header, outTxs, uncles, outReceipts, withdrawals), outTxs, outReceipts, nil
+	}
+	return types.NewBlock(header, outTxs, uncles, outReceipts), outTxs, outReceipts, nil
 }
 
 func (s *Serenity) SealHash(header *types.Header) (hash common.Hash) {
@@ -191,6 +216,22 @@ func (s *Serenity) verifyHeader(chain consensus.ChainHeaderReader, header, paren
 		return errInvalidUncleHash
 	}
 
+	shanghai := chain.Config().IsShanghai(header.Number.Uint64())
+	if shanghai && header.WithdrawalsHash == nil {
+		return errMissingWithdrawalsHash
+	}
+	if !shanghai && header.WithdrawalsHash != nil {
+		return errUnexpectedWithdrawalsHash
+	}
+
+	if chain.Config().IsCancun(header.Number.Uint64()) {
+		if err := misc.VerifyEip4844Header(chain.Config(), parent, header); err != nil {
+			return err
+		}
+	} else if header.BlobGasUsed != nil || header.ExcessBlobGas != nil {
+		return errUnexpectedBlobGas
+	}
+
 	return misc.VerifyEip1559Header(chain.Config(), parent, header)
 }
 
@@ -1,12 +1,16 @@
 package core
 
-import "github.com/ledgerwatch/erigon/common"
+import (
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+)
 
 // Parameters for PoS block building
-// See also https://github.com/ethereum/execution-apis/blob/v1.0.0-alpha.9/src/engine/specification.md#payloadattributesv1
+// See also https://github.com/ethereum/execution-apis/blob/main/src/engine/shanghai.md#payloadattributesv2
 type BlockBuilderParameters struct {
 	ParentHash            common.Hash
 	Timestamp             uint64
 	PrevRandao            common.Hash
 	SuggestedFeeRecipient common.Address
+	Withdrawals           []*types.Withdrawal // nil before Shanghai
 }
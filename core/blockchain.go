@@ -162,7 +162,7 @@ func ExecuteBlockEphemerallyForBSC(
 		syscall := func(contract common.Address, data []byte) ([]byte, error) {
 			return SysCallContract(contract, data, *chainConfig, ibs, header, engine)
 		}
-		outTxs, outReceipts, err := engine.Finalize(chainConfig, header, ibs, block.Transactions(), block.Uncles(), receipts, epochReader, chainReader, syscall)
+		outTxs, outReceipts, err := engine.Finalize(chainConfig, header, ibs, block.Transactions(), block.Uncles(), block.Withdrawals(), receipts, epochReader, chainReader, syscall)
 		if err != nil {
 			return nil, err
 		}
@@ -300,6 +300,11 @@ func ExecuteBlockEphemerally(
 	if !statelessExec && *usedGas != header.GasUsed {
 		return nil, fmt.Errorf("gas used by execution: %d, in header: %d", *usedGas, header.GasUsed)
 	}
+	if !statelessExec {
+		if err := verifyBlobGasUsed(chainConfig, header, block.Transactions()); err != nil {
+			return nil, err
+		}
+	}
 
 	var bloom types.Bloom
 	if !vmConfig.NoReceipts {
@@ -310,7 +315,7 @@ func ExecuteBlockEphemerally(
 	}
 	if !vmConfig.ReadOnly {
 		txs := block.Transactions()
-		if _, _, _, err := FinalizeBlockExecution(engine, stateReader, block.Header(), txs, block.Uncles(), stateWriter, chainConfig, ibs, receipts, epochReader, chainReader, false); err != nil {
+		if _, _, _, err := FinalizeBlockExecution(engine, stateReader, block.Header(), txs, block.Uncles(), block.Withdrawals(), stateWriter, chainConfig, ibs, receipts, epochReader, chainReader, false); err != nil {
 			return nil, err
 		}
 	}
@@ -422,7 +427,7 @@ func ExecuteBlockEphemerallyBor(
 	}
 	if !vmConfig.ReadOnly {
 		txs := block.Transactions()
-		if _, _, _, err := FinalizeBlockExecution(engine, stateReader, block.Header(), txs, block.Uncles(), stateWriter, chainConfig, ibs, receipts, epochReader, chainReader, false); err != nil {
+		if _, _, _, err := FinalizeBlockExecution(engine, stateReader, block.Header(), txs, block.Uncles(), block.Withdrawals(), stateWriter, chainConfig, ibs, receipts, epochReader, chainReader, false); err != nil {
 			return nil, err
 		}
 	}
@@ -465,6 +470,24 @@ func ExecuteBlockEphemerallyBor(
 	return execRs, nil
 }
 
+// verifyBlobGasUsed checks the blob gas consumed by the transactions of a block against its header (EIP-4844)
+func verifyBlobGasUsed(chainConfig *params.ChainConfig, header *types.Header, txs types.Transactions) error {
+	if !chainConfig.IsCancun(header.Number.Uint64()) {
+		return nil
+	}
+	var blobGasUsed uint64
+	for _, tx := range txs {
+		blobGasUsed += tx.GetBlobGas()
+	}
+	if blobGasUsed > params.MaxBlobGasPerBlock {
+		return fmt.Errorf("blob gas used by transactions: %d, exceeds maximum: %d", blobGasUsed, params.MaxBlobGasPerBlock)
+	}
+	if header.BlobGasUsed == nil || *header.BlobGasUsed != blobGasUsed {
+		return fmt.Errorf("blob gas used by transactions: %d, does not match header", blobGasUsed)
+	}
+	return nil
+}
+
 func rlpHash(x interface{}) (h common.Hash) {
 	hw := sha3.NewLegacyKeccak256()
 	rlp.Encode(hw, x) //nolint:errcheck
@@ -559,16 +582,16 @@ func CallContractTx(contract common.Address, data []byte, ibs *state.IntraBlockS
 }
 
 func FinalizeBlockExecution(engine consensus.Engine, stateReader state.StateReader, header *types.Header,
-	txs types.Transactions, uncles []*types.Header, stateWriter state.WriterWithChangeSets, cc *params.ChainConfig, ibs *state.IntraBlockState,
+	txs types.Transactions, uncles []*types.Header, withdrawals []*types.Withdrawal, stateWriter state.WriterWithChangeSets, cc *params.ChainConfig, ibs *state.IntraBlockState,
 	receipts types.Receipts, e consensus.EpochReader, headerReader consensus.ChainHeaderReader, isMining bool,
 ) (newBlock *types.Block, newTxs types.Transactions, newReceipt types.Receipts, err error) {
 	syscall := func(contract common.Address, data []byte) ([]byte, error) {
 		return SysCallContract(contract, data, *cc, ibs, header, engine)
 	}
 	if isMining {
-		newBlock, newTxs, newReceipt, err = engine.FinalizeAndAssemble(cc, header, ibs, txs, uncles, receipts, e, headerReader, syscall, nil)
+		newBlock, newTxs, newReceipt, err = engine.FinalizeAndAssemble(cc, header, ibs, txs, uncles, withdrawals, receipts, e, headerReader, syscall, nil)
 	} else {
-		_, _, err = engine.Finalize(cc, header, ibs, txs, uncles, receipts, e, headerReader, syscall)
+		_, _, err = engine.Finalize(cc, header, ibs, txs, uncles, withdrawals, receipts, e, headerReader, syscall)
 	}
 	if err != nil {
 		return nil, nil, nil, err
@@ -45,10 +45,11 @@ type BlockGen struct {
 	stateReader state.StateReader
 	ibs         *state.IntraBlockState
 
-	gasPool  *GasPool
-	txs      []types.Transaction
-	receipts []*types.Receipt
-	uncles   []*types.Header
+	gasPool     *GasPool
+	txs         []types.Transaction
+	receipts    []*types.Receipt
+	uncles      []*types.Header
+	withdrawals []*types.Withdrawal
 
 	config *params.ChainConfig
 	engine consensus.Engine
@@ -168,6 +169,12 @@ func (b *BlockGen) AddUncle(h *types.Header) {
 	b.uncles = append(b.uncles, h)
 }
 
+// AddWithdrawal adds a withdrawal to the generated block.
+// Blocks before Shanghai are generated with withdrawals (EIP-4895) only if at least one is added.
+func (b *BlockGen) AddWithdrawal(w *types.Withdrawal) {
+	b.withdrawals = append(b.withdrawals, w)
+}
+
 // PrevBlock returns a previously generated block by number. It panics if
 // num is greater or equal to the number of the block being generated.
 // For index -1, PrevBlock returns the parent block given to GenerateChain.
@@ -329,7 +336,7 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		}
 		if b.engine != nil {
 			// Finalize and seal the block
-			if _, _, _, err := b.engine.FinalizeAndAssemble(config, b.header, ibs, b.txs, b.uncles, b.receipts, nil, nil, nil, nil); err != nil {
+			if _, _, _, err := b.engine.FinalizeAndAssemble(config, b.header, ibs, b.txs, b.uncles, b.withdrawals, b.receipts, nil, nil, nil, nil); err != nil {
 				return nil, nil, fmt.Errorf("call to FinaliseAndAssemble: %w", err)
 			}
 			// Write state changes to db
@@ -412,7 +419,15 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 			}
 
 			// Recreating block to make sure Root makes it into the header
-			block := types.NewBlock(b.header, b.txs, b.uncles, b.receipts)
+			var block *types.Block
+			if b.withdrawals != nil || config.IsShanghai(b.header.Number.Uint64()) {
+				if b.withdrawals == nil {
+					b.withdrawals = []*types.Withdrawal{}
+				}
+				block = types.NewBlockWithWithdrawals(b.header, b.txs, b.uncles, b.receipts, b.withdrawals)
+			} else {
+				block = types.NewBlock(b.header, b.txs, b.uncles, b.receipts)
+			}
 			return block, b.receipts, nil
 		}
 		return nil, nil, fmt.Errorf("no engine to generate blocks")
@@ -461,6 +476,12 @@ func MakeEmptyHeader(parent *types.Header, chainConfig *params.ChainConfig, time
 		header.GasLimit = parentGasLimit
 	}
 
+	if chainConfig.IsCancun(header.Number.Uint64()) {
+		excessBlobGas := misc.CalcExcessBlobGas(chainConfig, parent)
+		header.ExcessBlobGas = &excessBlobGas
+		header.BlobGasUsed = new(uint64)
+	}
+
 	return header
 }
 
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/consensus/misc"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/stretchr/testify/require"
+)
+
+// The mining stage builds the headers with MakeEmptyHeader, they have to pass the EIP-4844 checks of the engine
+func TestMakeEmptyHeaderBlobGas(t *testing.T) {
+	config := *params.AllEthashProtocolChanges
+	config.CancunBlock = big.NewInt(2)
+	gasLimit := uint64(30_000_000)
+
+	parent := &types.Header{Number: big.NewInt(0), GasLimit: gasLimit, BaseFee: big.NewInt(params.InitialBaseFee), Eip1559: true}
+	for blockNum := uint64(1); blockNum <= 4; blockNum++ {
+		header := MakeEmptyHeader(parent, &config, parent.Time+12, &gasLimit)
+		if !config.IsCancun(blockNum) {
+			require.Nil(t, header.BlobGasUsed, "block %d", blockNum)
+			require.Nil(t, header.ExcessBlobGas, "block %d", blockNum)
+			parent = header
+			continue
+		}
+		require.NotNil(t, header.BlobGasUsed, "block %d", blockNum)
+		require.NotNil(t, header.ExcessBlobGas, "block %d", blockNum)
+		require.NoError(t, misc.VerifyEip4844Header(&config, parent, header), "block %d", blockNum)
+		// Blocks full of blobs raise the excess blob gas of the next ones
+		*header.BlobGasUsed = params.MaxBlobGasPerBlock
+		parent = header
+	}
+	require.Equal(t, uint64(2*(params.MaxBlobGasPerBlock-params.TargetBlobGasPerBlock)), *parent.ExcessBlobGas)
+}
@@ -80,6 +80,23 @@ var (
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
 
+	// ErrMaxInitCodeSizeExceeded is returned if creation transaction provides the init code bigger
+	// than init code size limit (EIP-3860).
+	ErrMaxInitCodeSizeExceeded = errors.New("max initcode size exceeded")
+
+	// ErrBlobTxCreate is returned if a blob transaction has no explicit to field.
+	ErrBlobTxCreate = errors.New("blob transaction of type create")
+
+	// ErrMissingBlobHashes is returned if a blob transaction carries no blobs.
+	ErrMissingBlobHashes = errors.New("blob transaction missing blob hashes")
+
+	// ErrInvalidBlobHashVersion is returned if a blob hash has an unsupported version byte.
+	ErrInvalidBlobHashVersion = errors.New("invalid blob hash version")
+
+	// ErrBlobFeeCapTooLow is returned if the transaction fee cap for blob gas is less than the
+	// blob gas price of the block.
+	ErrBlobFeeCapTooLow = errors.New("max fee per blob gas less than block blob gas fee")
+
 	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
 	// current network configuration.
 	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
@@ -70,14 +70,17 @@ func NewEVMBlockContext(header *types.Header, blockHashFunc func(n uint64) commo
 		BaseFee:     &baseFee,
 		GasLimit:    header.GasLimit,
 		PrevRanDao:  prevRandDao,
+
+		ExcessBlobGas: header.ExcessBlobGas,
 	}
 }
 
 // NewEVMTxContext creates a new transaction context for a single transaction.
 func NewEVMTxContext(msg Message) vm.TxContext {
 	return vm.TxContext{
-		Origin:   msg.From(),
-		GasPrice: msg.GasPrice().ToBig(),
+		Origin:     msg.From(),
+		GasPrice:   msg.GasPrice().ToBig(),
+		BlobHashes: msg.BlobHashes(),
 	}
 }
 
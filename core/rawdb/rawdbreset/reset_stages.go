@@ -123,6 +123,20 @@ func ResetExec(tx kv.RwTx, chain string) (err error) {
 	if err = stages.SaveStagePruneProgress(tx, stages.IntermediateHashes, 0); err != nil {
 		return err
 	}
+	// Verkle tree is regenerated from scratch when its stage starts from 0
+	if err = stages.SaveStageProgress(tx, stages.VerkleTrie, 0); err != nil {
+		return err
+	}
+	if err = stages.SaveStagePruneProgress(tx, stages.VerkleTrie, 0); err != nil {
+		return err
+	}
+	// Receipts are cleared below, the downloaded ones too
+	if err = stages.SaveStageProgress(tx, stages.Receipts, 0); err != nil {
+		return err
+	}
+	if err = stages.SaveStagePruneProgress(tx, stages.Receipts, 0); err != nil {
+		return err
+	}
 
 	stateBuckets := []string{
 		kv.PlainState, kv.HashedAccounts, kv.HashedStorage, kv.TrieOfAccounts, kv.TrieOfStorage,
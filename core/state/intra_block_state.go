@@ -89,6 +89,9 @@ type IntraBlockState struct {
 	trace          bool
 	accessList     *accessList
 	balanceInc     map[common.Address]*BalanceIncrease // Map of balance increases (without first reading the account)
+
+	// Transient storage (EIP-1153), cleared at the start of every transaction
+	transientStorage transientStorage
 }
 
 // Create a new state from a given trie
@@ -101,6 +104,7 @@ func New(stateReader StateReader) *IntraBlockState {
 		logs:              map[common.Hash][]*types.Log{},
 		journal:           newJournal(),
 		accessList:        newAccessList(),
+		transientStorage:  newTransientStorage(),
 		balanceInc:        map[common.Address]*BalanceIncrease{},
 	}
 }
@@ -136,6 +140,7 @@ func (sdb *IntraBlockState) Reset() {
 	sdb.logSize = 0
 	sdb.clearJournalAndRefund()
 	sdb.accessList = newAccessList()
+	sdb.transientStorage = newTransientStorage()
 	sdb.balanceInc = make(map[common.Address]*BalanceIncrease)
 }
 
@@ -813,6 +818,7 @@ func (sdb *IntraBlockState) Prepare(thash, bhash common.Hash, ti int) {
 	sdb.bhash = bhash
 	sdb.txIndex = ti
 	sdb.accessList = newAccessList()
+	sdb.transientStorage = newTransientStorage()
 }
 
 // no not lock
@@ -882,3 +888,30 @@ func (sdb *IntraBlockState) AddressInAccessList(addr common.Address) bool {
 func (sdb *IntraBlockState) SlotInAccessList(addr common.Address, slot common.Hash) (addressPresent bool, slotPresent bool) {
 	return sdb.accessList.Contains(addr, slot)
 }
+
+// SetTransientState sets transient storage for a given account. It
+// adds the change to the journal so that it can be rolled back
+// to its previous value if there is a revert.
+func (sdb *IntraBlockState) SetTransientState(addr common.Address, key common.Hash, value uint256.Int) {
+	prev := sdb.GetTransientState(addr, key)
+	if prev == value {
+		return
+	}
+	sdb.journal.append(transientStorageChange{
+		account:  &addr,
+		key:      key,
+		prevalue: prev,
+	})
+	sdb.setTransientState(addr, key, value)
+}
+
+// setTransientState is a lower level setter for transient storage. It
+// is called during a revert to prevent modifications to the journal.
+func (sdb *IntraBlockState) setTransientState(addr common.Address, key common.Hash, value uint256.Int) {
+	sdb.transientStorage.Set(addr, key, value)
+}
+
+// GetTransientState gets transient storage for a given account.
+func (sdb *IntraBlockState) GetTransientState(addr common.Address, key common.Hash) uint256.Int {
+	return sdb.transientStorage.Get(addr, key)
+}
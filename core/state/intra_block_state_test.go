@@ -160,6 +160,15 @@ func newTestAction(addr common.Address, r *rand.Rand) testAction {
 			},
 			args: make([]int64, 1),
 		},
+		{
+			name: "SetTransientState",
+			fn: func(a testAction, s *IntraBlockState) {
+				var key common.Hash
+				binary.BigEndian.PutUint16(key[:], uint16(a.args[0]))
+				s.SetTransientState(addr, key, *uint256.NewInt(uint64(a.args[1])))
+			},
+			args: make([]int64, 2),
+		},
 	}
 	action := actions[r.Intn(len(actions))]
 	var nameargs []string
@@ -302,6 +311,17 @@ func (test *snapshotTest) checkEqual(state, checkstate *IntraBlockState) error {
 				}
 			}
 		}
+		// Check transient storage.
+		for key := range state.transientStorage[addr] {
+			if !checkeq("GetTransientState("+key.Hex()+")", state.GetTransientState(addr, key), checkstate.GetTransientState(addr, key)) {
+				return err
+			}
+		}
+		for key := range checkstate.transientStorage[addr] {
+			if !checkeq("GetTransientState("+key.Hex()+")", state.GetTransientState(addr, key), checkstate.GetTransientState(addr, key)) {
+				return err
+			}
+		}
 	}
 
 	if state.GetRefund() != checkstate.GetRefund() {
@@ -161,6 +161,12 @@ type (
 		address *common.Address
 		slot    *common.Hash
 	}
+
+	transientStorageChange struct {
+		account  *common.Address
+		key      common.Hash
+		prevalue uint256.Int
+	}
 )
 
 func (ch createObjectChange) revert(s *IntraBlockState) {
@@ -308,3 +314,11 @@ func (ch accessListAddSlotChange) revert(s *IntraBlockState) {
 func (ch accessListAddSlotChange) dirtied() *common.Address {
 	return nil
 }
+
+func (ch transientStorageChange) revert(s *IntraBlockState) {
+	s.setTransientState(*ch.account, ch.key, ch.prevalue)
+}
+
+func (ch transientStorageChange) dirtied() *common.Address {
+	return nil
+}
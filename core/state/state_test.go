@@ -317,6 +317,33 @@ func compareStateObjects(so0, so1 *stateObject, t *testing.T) {
 	}
 }
 
+func TestTransientStorage(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	state := New(NewPlainState(tx, 1))
+
+	key := common.Hash{0x01}
+	value := *uint256.NewInt(0x02)
+	addr := common.Address{}
+
+	snapshot := state.Snapshot()
+	state.SetTransientState(addr, key, value)
+	if got := state.GetTransientState(addr, key); got != value {
+		t.Fatalf("transient storage mismatch: have %x, want %x", &got, &value)
+	}
+	// Changes to transient storage are journaled and can be reverted
+	state.RevertToSnapshot(snapshot)
+	if got := state.GetTransientState(addr, key); !got.IsZero() {
+		t.Fatalf("transient storage not reverted: have %x", &got)
+	}
+
+	// Transient storage is cleared at the start of the next transaction
+	state.SetTransientState(addr, key, value)
+	state.Prepare(common.Hash{0x02}, common.Hash{}, 1)
+	if got := state.GetTransientState(addr, key); !got.IsZero() {
+		t.Fatalf("transient storage not cleared: have %x", &got)
+	}
+}
+
 func TestDump(t *testing.T) {
 	_, tx := memdb.NewTestTx(t)
 	w := NewPlainStateWriter(tx, tx, 0)
@@ -0,0 +1,33 @@
+package state
+
+import (
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon/common"
+)
+
+// transientStorage is a representation of EIP-1153 "Transient Storage".
+// It lives for the duration of a single transaction and is never written to the database.
+type transientStorage map[common.Address]Storage
+
+// newTransientStorage creates a new instance of a transientStorage.
+func newTransientStorage() transientStorage {
+	return make(transientStorage)
+}
+
+// Set sets the transient-storage `value` for `key` at the given `addr`.
+func (t transientStorage) Set(addr common.Address, key common.Hash, value uint256.Int) {
+	if _, ok := t[addr]; !ok {
+		t[addr] = make(Storage)
+	}
+	t[addr][key] = value
+}
+
+// Get gets the transient storage for `key` at the given `addr`.
+func (t transientStorage) Get(addr common.Address, key common.Hash) uint256.Int {
+	val, ok := t[addr]
+	if !ok {
+		return uint256.Int{}
+	}
+	return val[key]
+}
@@ -93,14 +93,18 @@ func ApplyTransaction(config *params.ChainConfig, blockHashFunc func(n uint64) c
 	// about the transaction and calling mechanisms.
 	cfg.SkipAnalysis = SkipAnalysis(config, header.Number.Uint64())
 
-	var vmenv vm.VMInterface
+	blockContext := NewEVMBlockContext(header, blockHashFunc, engine, author)
+	vmenv := NewVMForTx(blockContext, vm.TxContext{}, ibs, config, cfg, tx)
 
+	return applyTransaction(config, gp, ibs, stateWriter, header, tx, usedGas, vmenv, cfg)
+}
+
+// NewVMForTx returns the CVM for Starknet transactions, which are executed by cfg.CairoRunner, and the EVM otherwise
+func NewVMForTx(blockContext vm.BlockContext, txContext vm.TxContext, ibs vm.IntraBlockState, config *params.ChainConfig, cfg vm.Config, tx types.Transaction) vm.VMInterface {
 	if tx.IsStarkNet() {
-		vmenv = &vm.CVMAdapter{Cvm: vm.NewCVM(ibs)}
-	} else {
-		blockContext := NewEVMBlockContext(header, blockHashFunc, engine, author)
-		vmenv = vm.NewEVM(blockContext, vm.TxContext{}, ibs, config, cfg)
+		cvm := vm.NewCVM(blockContext, ibs, config, cfg)
+		cvm.Reset(txContext, ibs)
+		return &vm.CVMAdapter{Cvm: cvm}
 	}
-
-	return applyTransaction(config, gp, ibs, stateWriter, header, tx, usedGas, vmenv, cfg)
+	return vm.NewEVM(blockContext, txContext, ibs, config, cfg)
 }
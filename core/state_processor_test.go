@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyStarknetTransactions(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	coinbase := common.HexToAddress("0xc014ba5e")
+	config := params.AllCliqueProtocolChanges
+	signer := types.MakeSigner(config, 1)
+
+	ibs := state.New(state.NewPlainStateReader(tx))
+	ibs.AddBalance(sender, uint256.NewInt(params.Ether))
+
+	header := &types.Header{Number: big.NewInt(1), GasLimit: 10_000_000, Difficulty: big.NewInt(1)}
+	gp := new(GasPool).AddGas(header.GasLimit)
+	cfg := vm.Config{CairoRunner: cairo.NewFakeRunner()}
+	var usedGas uint64
+
+	applyTx := func(nonce uint64, to *common.Address, data []byte) (*types.Receipt, []byte, error) {
+		txn, err := types.SignTx(&types.StarknetTransaction{
+			CommonTx: types.CommonTx{
+				ChainID: uint256.NewInt(config.ChainID.Uint64()),
+				Nonce:   nonce,
+				Value:   uint256.NewInt(0),
+				Gas:     1_000_000,
+				To:      to,
+				Data:    data,
+			},
+			Salt:   []byte("contract_salt"),
+			Tip:    uint256.NewInt(1),
+			FeeCap: uint256.NewInt(1),
+		}, *signer, key)
+		require.NoError(t, err)
+		ibs.Prepare(txn.Hash(), common.Hash{}, int(nonce))
+		return ApplyTransaction(config, func(uint64) common.Hash { return common.Hash{} }, nil, &coinbase, gp, ibs, state.NewNoopWriter(), header, txn, &usedGas, cfg)
+	}
+	apply := func(nonce uint64, to *common.Address, data []byte) (*types.Receipt, []byte) {
+		receipt, ret, err := applyTx(nonce, to, data)
+		require.NoError(t, err)
+		return receipt, ret
+	}
+	calldata := func(words ...uint64) []byte {
+		var data []byte
+		for _, w := range words {
+			data = append(data, common.BigToHash(new(big.Int).SetUint64(w)).Bytes()...)
+		}
+		return data
+	}
+
+	// deploy
+	code := []byte("{\"abi\": []}")
+	receipt, _ := apply(0, nil, code)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	contract := crypto.CreateAddress(sender, 0)
+	require.Equal(t, contract, receipt.ContractAddress)
+	require.Equal(t, code, ibs.GetCode(contract))
+	var value uint256.Int
+	slot := common.Hash{}
+	ibs.GetState(contract, &slot, &value)
+	require.Equal(t, crypto.Keccak256Hash(code), common.Hash(value.Bytes32()))
+
+	// invoke writing a slot
+	receipt, _ = apply(1, &contract, calldata(cairo.FakeSelectorStore, 7, 42))
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Len(t, receipt.Logs, 1)
+	require.Equal(t, contract, receipt.Logs[0].Address)
+	require.Equal(t, common.BigToHash(big.NewInt(7)), receipt.Logs[0].Topics[1])
+	slot = common.BigToHash(big.NewInt(7))
+	ibs.GetState(contract, &slot, &value)
+	require.Equal(t, uint64(42), value.Uint64())
+
+	// invoke reading slots, which are only provided to the runner once it asks for them
+	receipt, ret := apply(2, &contract, calldata(cairo.FakeSelectorLoad, 7, cairo.FakeSelectorLoad, 8))
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Empty(t, receipt.Logs)
+	require.Equal(t, calldata(42, 0), ret)
+
+	// reverted invoke leaves the storage untouched
+	receipt, _ = apply(3, &contract, calldata(cairo.FakeSelectorStore, 7, 1, cairo.FakeSelectorRevert))
+	require.Equal(t, types.ReceiptStatusFailed, receipt.Status)
+	require.Empty(t, receipt.Logs)
+	ibs.GetState(contract, &slot, &value)
+	require.Equal(t, uint64(42), value.Uint64())
+
+	require.Equal(t, uint64(4), ibs.GetNonce(sender))
+	require.Equal(t, usedGas, receipt.CumulativeGasUsed)
+
+	// failures of the runner say nothing about the transaction, it's not applied instead of failed
+	var runnerErr *vm.ErrCairoRunner
+	cfg.CairoRunner = failingRunner{}
+	_, _, err := applyTx(4, &contract, calldata(cairo.FakeSelectorLoad, 7))
+	require.ErrorAs(t, err, &runnerErr)
+	cfg.CairoRunner = nil
+	_, _, err = applyTx(5, &contract, calldata(cairo.FakeSelectorLoad, 7)) // the block is discarded, nonce 4 was taken
+	require.ErrorAs(t, err, &runnerErr)
+	require.ErrorIs(t, err, vm.ErrNoCairoRunner)
+}
+
+type failingRunner struct{}
+
+func (failingRunner) Deploy(ctx context.Context, _ *cairo.DeployRequest) (*cairo.Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingRunner) Invoke(ctx context.Context, _ *cairo.InvokeRequest) (*cairo.Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+// applyLegacyTx signs a legacy transaction and applies it to ibs in a block of the given chain config
+func applyLegacyTx(t *testing.T, config *params.ChainConfig, ibs *state.IntraBlockState, key *ecdsa.PrivateKey, nonce uint64, to *common.Address, gas uint64, data []byte) (*types.Receipt, error) {
+	coinbase := common.HexToAddress("0xc014ba5e")
+	header := &types.Header{Number: big.NewInt(1), GasLimit: 10_000_000, Difficulty: big.NewInt(1), Coinbase: coinbase}
+	var txn types.Transaction
+	if to == nil {
+		txn = types.NewContractCreation(nonce, uint256.NewInt(0), gas, uint256.NewInt(1), data)
+	} else {
+		txn = types.NewTransaction(nonce, *to, uint256.NewInt(0), gas, uint256.NewInt(1), data)
+	}
+	txn, err := types.SignTx(txn, *types.MakeSigner(config, 1), key)
+	require.NoError(t, err)
+	ibs.Prepare(txn.Hash(), common.Hash{}, int(nonce))
+	var usedGas uint64
+	receipt, _, err := ApplyTransaction(config, func(uint64) common.Hash { return common.Hash{} }, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), ibs, state.NewNoopWriter(), header, txn, &usedGas, vm.Config{})
+	return receipt, err
+}
+
+func shanghaiConfig(shanghai bool) *params.ChainConfig {
+	config := *params.AllEthashProtocolChanges
+	if shanghai {
+		config.ShanghaiBlock = common.Big0
+	}
+	return &config
+}
+
+func TestEIP3860CreateTransaction(t *testing.T) {
+	for _, shanghai := range []bool{false, true} {
+		_, tx := memdb.NewTestTx(t)
+		key, _ := crypto.GenerateKey()
+		sender := crypto.PubkeyToAddress(key.PublicKey)
+		ibs := state.New(state.NewPlainStateReader(tx))
+		ibs.AddBalance(sender, uint256.NewInt(params.Ether))
+		config := shanghaiConfig(shanghai)
+
+		// 2 words of init code which stops at once, Shanghai charges 2 gas per word on top of the calldata
+		receipt, err := applyLegacyTx(t, config, ibs, key, 0, nil, 100_000, make([]byte, 64))
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		expected := params.TxGasContractCreation + 64*params.TxDataZeroGas
+		if shanghai {
+			expected += 2 * params.InitCodeWordGas
+		}
+		require.Equal(t, expected, receipt.GasUsed)
+
+		// Init code over the limit makes the transaction invalid from Shanghai on
+		receipt, err = applyLegacyTx(t, config, ibs, key, 1, nil, 300_000, make([]byte, params.MaxInitCodeSize+1))
+		if shanghai {
+			require.ErrorIs(t, err, ErrMaxInitCodeSizeExceeded)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		}
+	}
+}
+
+func TestEIP3651WarmCoinbase(t *testing.T) {
+	for _, shanghai := range []bool{false, true} {
+		_, tx := memdb.NewTestTx(t)
+		key, _ := crypto.GenerateKey()
+		sender := crypto.PubkeyToAddress(key.PublicKey)
+		ibs := state.New(state.NewPlainStateReader(tx))
+		ibs.AddBalance(sender, uint256.NewInt(params.Ether))
+		// COINBASE BALANCE POP
+		aa := common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		ibs.SetCode(aa, []byte{byte(vm.COINBASE), byte(vm.BALANCE), byte(vm.POP)})
+
+		receipt, err := applyLegacyTx(t, shanghaiConfig(shanghai), ibs, key, 0, &aa, 30_000, nil)
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		// The coinbase is warm from the start of the transaction from Shanghai on
+		accessGas := params.ColdAccountAccessCostEIP2929
+		if shanghai {
+			accessGas = params.WarmStorageReadCostEIP2929
+		}
+		require.Equal(t, params.TxGas+vm.GasQuickStep+accessGas+vm.GasQuickStep, receipt.GasUsed)
+	}
+}
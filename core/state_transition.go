@@ -17,10 +17,13 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"math/bits"
 
 	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/consensus/misc"
 
 	"github.com/holiman/uint256"
 
@@ -89,6 +92,10 @@ type Message interface {
 	CheckNonce() bool
 	Data() []byte
 	AccessList() types.AccessList
+
+	MaxFeePerBlobGas() *uint256.Int
+	BlobHashes() []common.Hash
+	BlobGas() uint64
 }
 
 // ExecutionResult includes all output after executing given evm
@@ -127,7 +134,7 @@ func (result *ExecutionResult) Revert() []byte {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool) (uint64, error) {
+func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028, isEIP3860 bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if isContractCreation && isHomestead {
@@ -172,6 +179,17 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 		if overflow != 0 {
 			return 0, ErrGasUintOverflow
 		}
+
+		if isContractCreation && isEIP3860 {
+			overflow, product = bits.Mul64(toWordSize(uint64(len(data))), params.InitCodeWordGas)
+			if overflow != 0 {
+				return 0, ErrGasUintOverflow
+			}
+			gas, overflow = bits.Add64(gas, product, 0)
+			if overflow != 0 {
+				return 0, ErrGasUintOverflow
+			}
+		}
 	}
 	if accessList != nil {
 		overflow, product = bits.Mul64(uint64(len(accessList)), params.TxAccessListAddressGas)
@@ -195,6 +213,14 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 	return gas, nil
 }
 
+// toWordSize returns the ceiled word size required for init code payment calculation.
+func toWordSize(size uint64) uint64 {
+	if size > math.MaxUint64-31 {
+		return math.MaxUint64/32 + 1
+	}
+	return (size + 31) / 32
+}
+
 // NewStateTransition initialises and returns a new state transition object.
 func NewStateTransition(evm vm.VMInterface, msg Message, gp *GasPool) *StateTransition {
 	isParlia := evm.ChainConfig().Parlia != nil
@@ -259,6 +285,27 @@ func (st *StateTransition) buyGas(gasBailout bool) error {
 			return fmt.Errorf("%w: address %v", ErrInsufficientFunds, st.msg.From().Hex())
 		}
 	}
+	// Blob gas is paid upfront and is not refunded (EIP-4844)
+	if blobGas := st.msg.BlobGas(); blobGas > 0 {
+		maxBlobGasVal := new(uint256.Int).SetUint64(blobGas)
+		if _, overflow = maxBlobGasVal.MulOverflow(maxBlobGasVal, st.msg.MaxFeePerBlobGas()); overflow {
+			return fmt.Errorf("%w: address %v", ErrInsufficientFunds, st.msg.From().Hex())
+		}
+		if _, overflow = balanceCheck.AddOverflow(balanceCheck, maxBlobGasVal); overflow {
+			return fmt.Errorf("%w: address %v", ErrInsufficientFunds, st.msg.From().Hex())
+		}
+		blobGasPrice, err := st.blobGasPrice()
+		if err != nil {
+			return err
+		}
+		blobGasVal := new(uint256.Int).SetUint64(blobGas)
+		if _, overflow = blobGasVal.MulOverflow(blobGasVal, blobGasPrice); overflow {
+			return fmt.Errorf("%w: address %v", ErrInsufficientFunds, st.msg.From().Hex())
+		}
+		if _, overflow = mgval.AddOverflow(mgval, blobGasVal); overflow {
+			return fmt.Errorf("%w: address %v", ErrInsufficientFunds, st.msg.From().Hex())
+		}
+	}
 	var subBalance = false
 	if have, want := st.state.GetBalance(st.msg.From()), balanceCheck; have.Cmp(want) < 0 {
 		if !gasBailout {
@@ -328,9 +375,54 @@ func (st *StateTransition) preCheck(gasBailout bool) error {
 			}
 		}
 	}
+	if st.msg.BlobHashes() != nil {
+		if err := st.checkBlobTx(); err != nil {
+			return err
+		}
+	}
 	return st.buyGas(gasBailout)
 }
 
+// blobGasPrice returns the price of a unit of blob gas in the current block
+func (st *StateTransition) blobGasPrice() (*uint256.Int, error) {
+	var excessBlobGas uint64
+	if st.evm.Context().ExcessBlobGas != nil {
+		excessBlobGas = *st.evm.Context().ExcessBlobGas
+	}
+	return misc.GetBlobGasPrice(excessBlobGas)
+}
+
+// checkBlobTx verifies the fields of a blob-carrying transaction (EIP-4844)
+func (st *StateTransition) checkBlobTx() error {
+	if !st.evm.ChainRules().IsCancun {
+		return fmt.Errorf("%w: blob transactions require Cancun", ErrTxTypeNotSupported)
+	}
+	if st.msg.To() == nil {
+		return ErrBlobTxCreate
+	}
+	if len(st.msg.BlobHashes()) == 0 {
+		return ErrMissingBlobHashes
+	}
+	for i, hash := range st.msg.BlobHashes() {
+		if hash[0] != params.BlobTxHashVersion {
+			return fmt.Errorf("%w: blob %d, version %d", ErrInvalidBlobHashVersion, i, hash[0])
+		}
+	}
+	// Skip the fee check if it is zero and baseFee was explicitly disabled (eth_call)
+	if st.evm.Config().NoBaseFee && st.msg.MaxFeePerBlobGas().IsZero() {
+		return nil
+	}
+	blobGasPrice, err := st.blobGasPrice()
+	if err != nil {
+		return err
+	}
+	if st.msg.MaxFeePerBlobGas().Lt(blobGasPrice) {
+		return fmt.Errorf("%w: address %v, maxFeePerBlobGas: %s blobGasPrice: %s", ErrBlobFeeCapTooLow,
+			st.msg.From().Hex(), st.msg.MaxFeePerBlobGas(), blobGasPrice)
+	}
+	return nil
+}
+
 // TransitionDb will transition the state by applying the current message and
 // returning the evm execution result with following fields.
 //
@@ -377,10 +469,11 @@ func (st *StateTransition) TransitionDb(refunds bool, gasBailout bool) (*Executi
 	homestead := st.evm.ChainRules().IsHomestead
 	istanbul := st.evm.ChainRules().IsIstanbul
 	london := st.evm.ChainRules().IsLondon
+	shanghai := st.evm.ChainRules().IsShanghai
 	contractCreation := msg.To() == nil
 
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul)
+	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul, shanghai)
 	if err != nil {
 		return nil, err
 	}
@@ -389,6 +482,11 @@ func (st *StateTransition) TransitionDb(refunds bool, gasBailout bool) (*Executi
 	}
 	st.gas -= gas
 
+	// Check whether the init code size has been exceeded (EIP-3860)
+	if shanghai && contractCreation && len(st.data) > params.MaxInitCodeSize {
+		return nil, fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(st.data), params.MaxInitCodeSize)
+	}
+
 	var bailout bool
 	// Gas bailout (for trace_call) should only be applied if there is not sufficient balance to perform value transfer
 	if gasBailout {
@@ -400,6 +498,10 @@ func (st *StateTransition) TransitionDb(refunds bool, gasBailout bool) (*Executi
 	// Set up the initial access list.
 	if st.evm.ChainRules().IsBerlin {
 		st.state.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(st.evm.ChainRules()), msg.AccessList())
+		// EIP-3651: coinbase is warm from the start of the transaction
+		if shanghai {
+			st.state.AddAddressToAccessList(st.evm.Context().Coinbase)
+		}
 	}
 
 	var (
@@ -417,6 +519,10 @@ func (st *StateTransition) TransitionDb(refunds bool, gasBailout bool) (*Executi
 		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value, bailout)
 	}
+	var runnerErr *vm.ErrCairoRunner
+	if errors.As(vmerr, &runnerErr) { // the outcome of the transaction is unknown
+		return nil, vmerr
+	}
 	if refunds {
 		if london {
 			// After EIP-3529: refunds are capped to gasUsed / 5
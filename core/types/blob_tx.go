@@ -0,0 +1,372 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/u256"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// BlobTx is a blob-carrying transaction (EIP-4844). Only the canonical network form without the blobs,
+// commitments and proofs ("sidecar") is supported, which is what appears in blocks.
+type BlobTx struct {
+	DynamicFeeTransaction
+	MaxFeePerBlobGas    *uint256.Int
+	BlobVersionedHashes []common.Hash
+}
+
+func (tx BlobTx) Type() byte { return BlobTxType }
+
+func (tx BlobTx) GetBlobHashes() []common.Hash { return tx.BlobVersionedHashes }
+
+// GetBlobGas returns the amount of blob gas consumed by the transaction
+func (tx BlobTx) GetBlobGas() uint64 {
+	return params.BlobTxBlobGasPerBlob * uint64(len(tx.BlobVersionedHashes))
+}
+
+func (tx BlobTx) Cost() *uint256.Int {
+	total := tx.DynamicFeeTransaction.Cost()
+	blobCost := new(uint256.Int).SetUint64(tx.GetBlobGas())
+	blobCost.Mul(blobCost, tx.MaxFeePerBlobGas)
+	return total.Add(total, blobCost)
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx BlobTx) copy() *BlobTx {
+	cpy := &BlobTx{
+		DynamicFeeTransaction: *tx.DynamicFeeTransaction.copy(),
+		MaxFeePerBlobGas:      new(uint256.Int),
+		BlobVersionedHashes:   make([]common.Hash, len(tx.BlobVersionedHashes)),
+	}
+	if tx.MaxFeePerBlobGas != nil {
+		cpy.MaxFeePerBlobGas.Set(tx.MaxFeePerBlobGas)
+	}
+	copy(cpy.BlobVersionedHashes, tx.BlobVersionedHashes)
+	return cpy
+}
+
+func (tx *BlobTx) Size() common.StorageSize {
+	if size := tx.size.Load(); size != nil {
+		return size.(common.StorageSize)
+	}
+	c := tx.EncodingSize()
+	tx.size.Store(common.StorageSize(c))
+	return common.StorageSize(c)
+}
+
+func (tx BlobTx) EncodingSize() int {
+	payloadSize, _, _, _, _ := tx.payloadSize()
+	envelopeSize := payloadSize
+	// Add envelope size and type size
+	if payloadSize >= 56 {
+		envelopeSize += (bits.Len(uint(payloadSize)) + 7) / 8
+	}
+	envelopeSize += 2
+	return envelopeSize
+}
+
+func (tx BlobTx) payloadSize() (payloadSize int, nonceLen, gasLen, accessListLen, blobHashesLen int) {
+	payloadSize, nonceLen, gasLen, accessListLen = tx.DynamicFeeTransaction.payloadSize()
+	// size of MaxFeePerBlobGas
+	payloadSize++
+	if tx.MaxFeePerBlobGas.BitLen() >= 8 {
+		payloadSize += (tx.MaxFeePerBlobGas.BitLen() + 7) / 8
+	}
+	// size of BlobVersionedHashes
+	payloadSize++
+	blobHashesLen = 33 * len(tx.BlobVersionedHashes)
+	if blobHashesLen >= 56 {
+		payloadSize += (bits.Len(uint(blobHashesLen)) + 7) / 8
+	}
+	payloadSize += blobHashesLen
+	return payloadSize, nonceLen, gasLen, accessListLen, blobHashesLen
+}
+
+func (tx *BlobTx) WithSignature(signer Signer, sig []byte) (Transaction, error) {
+	cpy := tx.copy()
+	r, s, v, err := signer.SignatureValues(tx, sig)
+	if err != nil {
+		return nil, err
+	}
+	cpy.R.Set(r)
+	cpy.S.Set(s)
+	cpy.V.Set(v)
+	cpy.ChainID = signer.ChainID()
+	return cpy, nil
+}
+
+func (tx *BlobTx) FakeSign(address common.Address) (Transaction, error) {
+	cpy := tx.copy()
+	cpy.R.Set(u256.Num1)
+	cpy.S.Set(u256.Num1)
+	cpy.V.Set(u256.Num4)
+	cpy.from.Store(address)
+	return cpy, nil
+}
+
+// MarshalBinary returns the canonical encoding of the transaction: the type followed by the payload.
+func (tx BlobTx) MarshalBinary(w io.Writer) error {
+	payloadSize, nonceLen, gasLen, accessListLen, blobHashesLen := tx.payloadSize()
+	var b [33]byte
+	// encode TxType
+	b[0] = BlobTxType
+	if _, err := w.Write(b[:1]); err != nil {
+		return err
+	}
+	if err := tx.encodePayload(w, b[:], payloadSize, nonceLen, gasLen, accessListLen, blobHashesLen); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tx BlobTx) encodePayload(w io.Writer, b []byte, payloadSize, nonceLen, gasLen, accessListLen, blobHashesLen int) error {
+	// The fields up to AccessList are the same as in the DynamicFeeTransaction, but the signature goes last
+	if err := EncodeStructSizePrefix(payloadSize, w, b); err != nil {
+		return err
+	}
+	if err := tx.ChainID.EncodeRLP(w); err != nil {
+		return err
+	}
+	if err := encodeUint64(tx.Nonce, w, b); err != nil {
+		return err
+	}
+	if err := tx.Tip.EncodeRLP(w); err != nil {
+		return err
+	}
+	if err := tx.FeeCap.EncodeRLP(w); err != nil {
+		return err
+	}
+	if err := encodeUint64(tx.Gas, w, b); err != nil {
+		return err
+	}
+	// encode To
+	if tx.To == nil {
+		b[0] = 128
+	} else {
+		b[0] = 128 + 20
+	}
+	if _, err := w.Write(b[:1]); err != nil {
+		return err
+	}
+	if tx.To != nil {
+		if _, err := w.Write(tx.To.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := tx.Value.EncodeRLP(w); err != nil {
+		return err
+	}
+	if err := EncodeString(tx.Data, w, b); err != nil {
+		return err
+	}
+	if err := EncodeStructSizePrefix(accessListLen, w, b); err != nil {
+		return err
+	}
+	if err := encodeAccessList(tx.AccessList, w, b); err != nil {
+		return err
+	}
+	// encode MaxFeePerBlobGas
+	if err := tx.MaxFeePerBlobGas.EncodeRLP(w); err != nil {
+		return err
+	}
+	// encode BlobVersionedHashes
+	if err := EncodeStructSizePrefix(blobHashesLen, w, b); err != nil {
+		return err
+	}
+	for _, h := range tx.BlobVersionedHashes {
+		b[0] = 128 + 32
+		if _, err := w.Write(b[:1]); err != nil {
+			return err
+		}
+		if _, err := w.Write(h.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := tx.V.EncodeRLP(w); err != nil {
+		return err
+	}
+	if err := tx.R.EncodeRLP(w); err != nil {
+		return err
+	}
+	if err := tx.S.EncodeRLP(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tx BlobTx) EncodeRLP(w io.Writer) error {
+	payloadSize, nonceLen, gasLen, accessListLen, blobHashesLen := tx.payloadSize()
+	envelopeSize := payloadSize
+	if payloadSize >= 56 {
+		envelopeSize += (bits.Len(uint(payloadSize)) + 7) / 8
+	}
+	// size of struct prefix and TxType
+	envelopeSize += 2
+	var b [33]byte
+	// envelope
+	if err := EncodeStringSizePrefix(envelopeSize, w, b[:]); err != nil {
+		return err
+	}
+	// encode TxType
+	b[0] = BlobTxType
+	if _, err := w.Write(b[:1]); err != nil {
+		return err
+	}
+	if err := tx.encodePayload(w, b[:], payloadSize, nonceLen, gasLen, accessListLen, blobHashesLen); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tx *BlobTx) DecodeRLP(s *rlp.Stream) error {
+	_, err := s.List()
+	if err != nil {
+		return err
+	}
+	var b []byte
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.ChainID = new(uint256.Int).SetBytes(b)
+	if tx.Nonce, err = s.Uint(); err != nil {
+		return err
+	}
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.Tip = new(uint256.Int).SetBytes(b)
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.FeeCap = new(uint256.Int).SetBytes(b)
+	if tx.Gas, err = s.Uint(); err != nil {
+		return err
+	}
+	if b, err = s.Bytes(); err != nil {
+		return err
+	}
+	// blob transactions can not create contracts
+	if len(b) != 20 {
+		return fmt.Errorf("wrong size for To: %d", len(b))
+	}
+	tx.To = &common.Address{}
+	copy((*tx.To)[:], b)
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.Value = new(uint256.Int).SetBytes(b)
+	if tx.Data, err = s.Bytes(); err != nil {
+		return err
+	}
+	tx.AccessList = AccessList{}
+	if err = decodeAccessList(&tx.AccessList, s); err != nil {
+		return err
+	}
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.MaxFeePerBlobGas = new(uint256.Int).SetBytes(b)
+	if _, err = s.List(); err != nil {
+		return fmt.Errorf("open BlobVersionedHashes: %w", err)
+	}
+	tx.BlobVersionedHashes = []common.Hash{}
+	for b, err = s.Bytes(); err == nil; b, err = s.Bytes() {
+		if len(b) != 32 {
+			return fmt.Errorf("wrong size for blobVersionedHash: %d", len(b))
+		}
+		tx.BlobVersionedHashes = append(tx.BlobVersionedHashes, common.BytesToHash(b))
+	}
+	if !errors.Is(err, rlp.EOL) {
+		return fmt.Errorf("read blobVersionedHash: %w", err)
+	}
+	if err = s.ListEnd(); err != nil {
+		return fmt.Errorf("close BlobVersionedHashes: %w", err)
+	}
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.V.SetBytes(b)
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.R.SetBytes(b)
+	if b, err = s.Uint256Bytes(); err != nil {
+		return err
+	}
+	tx.S.SetBytes(b)
+	return s.ListEnd()
+}
+
+// AsMessage returns the transaction as a core.Message.
+func (tx BlobTx) AsMessage(s Signer, baseFee *big.Int, rules *params.Rules) (Message, error) {
+	if !rules.IsCancun {
+		return Message{}, errors.New("blob transactions require Cancun")
+	}
+	msg, err := tx.DynamicFeeTransaction.AsMessage(s, baseFee, rules)
+	if err != nil {
+		return msg, err
+	}
+	msg.maxFeePerBlobGas = *tx.MaxFeePerBlobGas
+	msg.blobHashes = tx.BlobVersionedHashes
+	return msg, err
+}
+
+// Hash computes the hash (but not for signatures!)
+func (tx *BlobTx) Hash() common.Hash {
+	if hash := tx.hash.Load(); hash != nil {
+		return *hash.(*common.Hash)
+	}
+	hash := prefixedRlpHash(BlobTxType, []interface{}{
+		tx.ChainID,
+		tx.Nonce,
+		tx.Tip,
+		tx.FeeCap,
+		tx.Gas,
+		tx.To,
+		tx.Value,
+		tx.Data,
+		tx.AccessList,
+		tx.MaxFeePerBlobGas,
+		tx.BlobVersionedHashes,
+		tx.V, tx.R, tx.S,
+	})
+	tx.hash.Store(&hash)
+	return hash
+}
+
+func (tx BlobTx) SigningHash(chainID *big.Int) common.Hash {
+	return prefixedRlpHash(
+		BlobTxType,
+		[]interface{}{
+			chainID,
+			tx.Nonce,
+			tx.Tip,
+			tx.FeeCap,
+			tx.Gas,
+			tx.To,
+			tx.Value,
+			tx.Data,
+			tx.AccessList,
+			tx.MaxFeePerBlobGas,
+			tx.BlobVersionedHashes,
+		})
+}
+
+func (tx *BlobTx) Sender(signer Signer) (common.Address, error) {
+	if sc := tx.from.Load(); sc != nil {
+		return sc.(common.Address), nil
+	}
+	addr, err := signer.Sender(tx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	tx.from.Store(addr)
+	return addr, nil
+}
@@ -101,6 +101,12 @@ type Header struct {
 	Eip1559     bool           // to avoid relying on BaseFee != nil for that
 	Seal        []rlp.RawValue // AuRa POA network field
 	WithSeal    bool           // to avoid relying on Seal != nil for that
+	// WithdrawalsHash was added by EIP-4895 and is ignored in legacy headers.
+	// It is not supported together with Verkle.
+	WithdrawalsHash *common.Hash `json:"withdrawalsRoot"`
+	// BlobGasUsed and ExcessBlobGas were added by EIP-4844 and are ignored in legacy headers.
+	BlobGasUsed   *uint64 `json:"blobGasUsed"`
+	ExcessBlobGas *uint64 `json:"excessBlobGas"`
 	// The verkle proof is ignored in legacy headers
 	Verkle        bool
 	VerkleProof   []byte                `json:"verkleProof"`
@@ -181,6 +187,16 @@ func (h Header) EncodingSize() int {
 		encodingSize += baseFeeLen
 	}
 
+	if h.WithdrawalsHash != nil {
+		encodingSize += 33
+	}
+	if h.BlobGasUsed != nil {
+		encodingSize += uint64EncodingSize(*h.BlobGasUsed)
+	}
+	if h.ExcessBlobGas != nil {
+		encodingSize += uint64EncodingSize(*h.ExcessBlobGas)
+	}
+
 	if h.Verkle {
 		// Encoding of Verkle Proof
 		encodingSize++
@@ -310,6 +326,16 @@ func (h Header) EncodeRLP(w io.Writer) error {
 		encodingSize += baseFeeLen
 	}
 
+	if h.WithdrawalsHash != nil {
+		encodingSize += 33
+	}
+	if h.BlobGasUsed != nil {
+		encodingSize += uint64EncodingSize(*h.BlobGasUsed)
+	}
+	if h.ExcessBlobGas != nil {
+		encodingSize += uint64EncodingSize(*h.ExcessBlobGas)
+	}
+
 	var b [33]byte
 	// Prefix
 	if err := EncodeStructSizePrefix(encodingSize, w, b[:]); err != nil {
@@ -477,6 +503,26 @@ func (h Header) EncodeRLP(w io.Writer) error {
 		}
 	}
 
+	if h.WithdrawalsHash != nil {
+		b[0] = 128 + 32
+		if _, err := w.Write(b[:1]); err != nil {
+			return err
+		}
+		if _, err := w.Write(h.WithdrawalsHash.Bytes()); err != nil {
+			return err
+		}
+	}
+	if h.BlobGasUsed != nil {
+		if err := encodeUint64(*h.BlobGasUsed, w, b[:]); err != nil {
+			return err
+		}
+	}
+	if h.ExcessBlobGas != nil {
+		if err := encodeUint64(*h.ExcessBlobGas, w, b[:]); err != nil {
+			return err
+		}
+	}
+
 	if h.Verkle {
 		if err := EncodeString(h.VerkleProof, w, b[:]); err != nil {
 			return err
@@ -606,6 +652,42 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 		}
 		h.Eip1559 = true
 		h.BaseFee = new(big.Int).SetBytes(b)
+
+		if !h.Verkle {
+			if b, err = s.Bytes(); err != nil {
+				if errors.Is(err, rlp.EOL) {
+					h.WithdrawalsHash = nil
+					if err := s.ListEnd(); err != nil {
+						return fmt.Errorf("close header struct (no withdrawalsHash): %w", err)
+					}
+					return nil
+				}
+				return fmt.Errorf("read WithdrawalsHash: %w", err)
+			}
+			if len(b) != 32 {
+				return fmt.Errorf("wrong size for WithdrawalsHash: %d", len(b))
+			}
+			h.WithdrawalsHash = new(common.Hash)
+			h.WithdrawalsHash.SetBytes(b)
+
+			var blobGasUsed uint64
+			if blobGasUsed, err = s.Uint(); err != nil {
+				if errors.Is(err, rlp.EOL) {
+					h.BlobGasUsed = nil
+					if err := s.ListEnd(); err != nil {
+						return fmt.Errorf("close header struct (no blobGasUsed): %w", err)
+					}
+					return nil
+				}
+				return fmt.Errorf("read BlobGasUsed: %w", err)
+			}
+			h.BlobGasUsed = &blobGasUsed
+			var excessBlobGas uint64
+			if excessBlobGas, err = s.Uint(); err != nil {
+				return fmt.Errorf("read ExcessBlobGas: %w", err)
+			}
+			h.ExcessBlobGas = &excessBlobGas
+		}
 	}
 
 	if h.Verkle {
@@ -626,14 +708,16 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 
 // field type overrides for gencodec
 type headerMarshaling struct {
-	Difficulty *hexutil.Big
-	Number     *hexutil.Big
-	GasLimit   hexutil.Uint64
-	GasUsed    hexutil.Uint64
-	Time       hexutil.Uint64
-	Extra      hexutil.Bytes
-	BaseFee    *hexutil.Big
-	Hash       common.Hash `json:"hash"` // adds call to Hash() in MarshalJSON
+	Difficulty    *hexutil.Big
+	Number        *hexutil.Big
+	GasLimit      hexutil.Uint64
+	GasUsed       hexutil.Uint64
+	Time          hexutil.Uint64
+	Extra         hexutil.Bytes
+	BaseFee       *hexutil.Big
+	BlobGasUsed   *hexutil.Uint64
+	ExcessBlobGas *hexutil.Uint64
+	Hash          common.Hash `json:"hash"` // adds call to Hash() in MarshalJSON
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
@@ -701,6 +785,7 @@ func (h *Header) copySeal() []rlp.RawValue {
 type Body struct {
 	Transactions []Transaction
 	Uncles       []*Header
+	Withdrawals  []*Withdrawal
 }
 
 // RawBody is semi-parsed variant of Body, where transactions are still unparsed RLP strings
@@ -709,12 +794,14 @@ type Body struct {
 type RawBody struct {
 	Transactions [][]byte
 	Uncles       []*Header
+	Withdrawals  []*Withdrawal
 }
 
 type BodyForStorage struct {
-	BaseTxId uint64
-	TxAmount uint32
-	Uncles   []*Header
+	BaseTxId    uint64
+	TxAmount    uint32
+	Uncles      []*Header
+	Withdrawals []*Withdrawal `rlp:"optional"`
 }
 
 // Block represents an entire block in the Ethereum blockchain.
@@ -722,6 +809,7 @@ type Block struct {
 	header       *Header
 	uncles       []*Header
 	transactions Transactions
+	withdrawals  []*Withdrawal
 
 	// caches
 	hash atomic.Value
@@ -755,11 +843,11 @@ func (b *Body) SendersFromTxs() []common.Address {
 }
 
 func (rb RawBody) EncodingSize() int {
-	payloadSize, _, _ := rb.payloadSize()
+	payloadSize, _, _, _ := rb.payloadSize()
 	return payloadSize
 }
 
-func (rb RawBody) payloadSize() (payloadSize int, txsLen, unclesLen int) {
+func (rb RawBody) payloadSize() (payloadSize int, txsLen, unclesLen, withdrawalsLen int) {
 	// size of Transactions
 	payloadSize++
 	for _, tx := range rb.Transactions {
@@ -788,11 +876,20 @@ func (rb RawBody) payloadSize() (payloadSize int, txsLen, unclesLen int) {
 		payloadSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	payloadSize += unclesLen
-	return payloadSize, txsLen, unclesLen
+	// size of Withdrawals
+	if rb.Withdrawals != nil {
+		payloadSize++
+		withdrawalsLen = Withdrawals(rb.Withdrawals).PayloadSize()
+		if withdrawalsLen >= 56 {
+			payloadSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		payloadSize += withdrawalsLen
+	}
+	return payloadSize, txsLen, unclesLen, withdrawalsLen
 }
 
 func (rb RawBody) EncodeRLP(w io.Writer) error {
-	payloadSize, txsLen, unclesLen := rb.payloadSize()
+	payloadSize, txsLen, unclesLen, withdrawalsLen := rb.payloadSize()
 	var b [33]byte
 	// prefix
 	if err := EncodeStructSizePrefix(payloadSize, w, b[:]); err != nil {
@@ -816,6 +913,12 @@ func (rb RawBody) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if rb.Withdrawals != nil {
+		if err := Withdrawals(rb.Withdrawals).EncodeList(withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -857,15 +960,19 @@ func (rb *RawBody) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if rb.Withdrawals, err = DecodeWithdrawals(s); err != nil {
+		return err
+	}
 	return s.ListEnd()
 }
 
 func (bb Body) EncodingSize() int {
-	payloadSize, _, _ := bb.payloadSize()
+	payloadSize, _, _, _ := bb.payloadSize()
 	return payloadSize
 }
 
-func (bb Body) payloadSize() (payloadSize int, txsLen, unclesLen int) {
+func (bb Body) payloadSize() (payloadSize int, txsLen, unclesLen, withdrawalsLen int) {
 	// size of Transactions
 	payloadSize++
 	for _, tx := range bb.Transactions {
@@ -902,11 +1009,20 @@ func (bb Body) payloadSize() (payloadSize int, txsLen, unclesLen int) {
 		payloadSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	payloadSize += unclesLen
-	return payloadSize, txsLen, unclesLen
+	// size of Withdrawals
+	if bb.Withdrawals != nil {
+		payloadSize++
+		withdrawalsLen = Withdrawals(bb.Withdrawals).PayloadSize()
+		if withdrawalsLen >= 56 {
+			payloadSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		payloadSize += withdrawalsLen
+	}
+	return payloadSize, txsLen, unclesLen, withdrawalsLen
 }
 
 func (bb Body) EncodeRLP(w io.Writer) error {
-	payloadSize, txsLen, unclesLen := bb.payloadSize()
+	payloadSize, txsLen, unclesLen, withdrawalsLen := bb.payloadSize()
 	var b [33]byte
 	// prefix
 	if err := EncodeStructSizePrefix(payloadSize, w, b[:]); err != nil {
@@ -941,6 +1057,12 @@ func (bb Body) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if bb.Withdrawals != nil {
+		if err := Withdrawals(bb.Withdrawals).EncodeList(withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -982,6 +1104,10 @@ func (bb *Body) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if bb.Withdrawals, err = DecodeWithdrawals(s); err != nil {
+		return err
+	}
 	return s.ListEnd()
 }
 
@@ -1025,10 +1151,27 @@ func NewBlock(header *Header, txs []Transaction, uncles []*Header, receipts []*R
 	return b
 }
 
+// NewBlockWithWithdrawals creates a new block with withdrawals. The input data
+// is copied, changes to header and to the field values will not affect the block.
+//
+// The values of TxHash, UncleHash, ReceiptHash, Bloom and WithdrawalsHash in header
+// are ignored and set to values derived from the given txs, uncles, receipts and withdrawals.
+func NewBlockWithWithdrawals(header *Header, txs []Transaction, uncles []*Header, receipts []*Receipt, withdrawals []*Withdrawal) *Block {
+	b := NewBlock(header, txs, uncles, receipts)
+	h := DeriveSha(Withdrawals(withdrawals))
+	b.header.WithdrawalsHash = &h
+	b.withdrawals = make([]*Withdrawal, len(withdrawals))
+	for i, w := range withdrawals {
+		cpy := *w
+		b.withdrawals[i] = &cpy
+	}
+	return b
+}
+
 // NewBlockFromStorage like NewBlock but used to create Block object when read it from DB
 // in this case no reason to copy parts, or re-calculate headers fields - they are all stored in DB
-func NewBlockFromStorage(hash common.Hash, header *Header, txs []Transaction, uncles []*Header) *Block {
-	b := &Block{header: header, transactions: txs, uncles: uncles}
+func NewBlockFromStorage(hash common.Hash, header *Header, txs []Transaction, uncles []*Header, withdrawals []*Withdrawal) *Block {
+	b := &Block{header: header, transactions: txs, uncles: uncles, withdrawals: withdrawals}
 	b.hash.Store(hash)
 	return b
 }
@@ -1054,6 +1197,18 @@ func CopyHeader(h *Header) *Header {
 		cpy.BaseFee = new(big.Int)
 		cpy.BaseFee.Set(h.BaseFee)
 	}
+	if h.WithdrawalsHash != nil {
+		cpy.WithdrawalsHash = new(common.Hash)
+		cpy.WithdrawalsHash.SetBytes(h.WithdrawalsHash.Bytes())
+	}
+	if h.BlobGasUsed != nil {
+		blobGasUsed := *h.BlobGasUsed
+		cpy.BlobGasUsed = &blobGasUsed
+	}
+	if h.ExcessBlobGas != nil {
+		excessBlobGas := *h.ExcessBlobGas
+		cpy.ExcessBlobGas = &excessBlobGas
+	}
 	if len(h.Extra) > 0 {
 		cpy.Extra = make([]byte, len(h.Extra))
 		copy(cpy.Extra, h.Extra)
@@ -1107,6 +1262,10 @@ func (bb *Block) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if bb.withdrawals, err = DecodeWithdrawals(s); err != nil {
+		return err
+	}
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
@@ -1114,7 +1273,7 @@ func (bb *Block) DecodeRLP(s *rlp.Stream) error {
 	return nil
 }
 
-func (bb Block) payloadSize() (payloadSize int, txsLen, unclesLen int) {
+func (bb Block) payloadSize() (payloadSize int, txsLen, unclesLen, withdrawalsLen int) {
 	// size of Header
 	payloadSize++
 	headerLen := bb.header.EncodingSize()
@@ -1160,17 +1319,26 @@ func (bb Block) payloadSize() (payloadSize int, txsLen, unclesLen int) {
 		payloadSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	payloadSize += unclesLen
-	return payloadSize, txsLen, unclesLen
+	// size of Withdrawals
+	if bb.withdrawals != nil {
+		payloadSize++
+		withdrawalsLen = Withdrawals(bb.withdrawals).PayloadSize()
+		if withdrawalsLen >= 56 {
+			payloadSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		payloadSize += withdrawalsLen
+	}
+	return payloadSize, txsLen, unclesLen, withdrawalsLen
 }
 
 func (bb Block) EncodingSize() int {
-	payloadSize, _, _ := bb.payloadSize()
+	payloadSize, _, _, _ := bb.payloadSize()
 	return payloadSize
 }
 
 // EncodeRLP serializes b into the Ethereum RLP block format.
 func (bb Block) EncodeRLP(w io.Writer) error {
-	payloadSize, txsLen, unclesLen := bb.payloadSize()
+	payloadSize, txsLen, unclesLen, withdrawalsLen := bb.payloadSize()
 	var b [33]byte
 	// prefix
 	if err := EncodeStructSizePrefix(payloadSize, w, b[:]); err != nil {
@@ -1213,11 +1381,18 @@ func (bb Block) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if bb.withdrawals != nil {
+		if err := Withdrawals(bb.withdrawals).EncodeList(withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (b *Block) Uncles() []*Header          { return b.uncles }
 func (b *Block) Transactions() Transactions { return b.transactions }
+func (b *Block) Withdrawals() []*Withdrawal { return b.withdrawals }
 
 func (b *Block) Transaction(hash common.Hash) Transaction {
 	for _, transaction := range b.transactions {
@@ -1259,7 +1434,7 @@ func (b *Block) Header() *Header { return CopyHeader(b.header) }
 
 // Body returns the non-header content of the block.
 func (b *Block) Body() *Body {
-	bd := &Body{Transactions: b.transactions, Uncles: b.uncles}
+	bd := &Body{Transactions: b.transactions, Uncles: b.uncles, Withdrawals: b.withdrawals}
 	bd.SendersFromTxs()
 	return bd
 }
@@ -1275,7 +1450,7 @@ func (b *Block) SendersToTxs(senders []common.Address) {
 // RawBody creates a RawBody based on the block. It is not very efficient, so
 // will probably be removed in favour of RawBlock. Also it panics
 func (b *Block) RawBody() *RawBody {
-	br := &RawBody{Transactions: make([][]byte, len(b.transactions)), Uncles: b.uncles}
+	br := &RawBody{Transactions: make([][]byte, len(b.transactions)), Uncles: b.uncles, Withdrawals: b.withdrawals}
 	for i, tx := range b.transactions {
 		var err error
 		br.Transactions[i], err = rlp.EncodeToBytes(tx)
@@ -1325,6 +1500,15 @@ func (b *Block) Copy() *Block {
 		uncles = append(uncles, CopyHeader(uncle))
 	}
 
+	var withdrawals []*Withdrawal
+	if b.withdrawals != nil {
+		withdrawals = make([]*Withdrawal, 0, len(b.withdrawals))
+		for _, withdrawal := range b.withdrawals {
+			cpy := *withdrawal
+			withdrawals = append(withdrawals, &cpy)
+		}
+	}
+
 	transactionsData, err := MarshalTransactionsBinary(b.transactions)
 	if err != nil {
 		panic(fmt.Errorf("MarshalTransactionsBinary failed: %w", err))
@@ -1354,6 +1538,7 @@ func (b *Block) Copy() *Block {
 		header:       CopyHeader(b.header),
 		uncles:       uncles,
 		transactions: transactions,
+		withdrawals:  withdrawals,
 		hash:         hashValue,
 		size:         sizeValue,
 		ReceivedAt:   b.ReceivedAt,
@@ -1370,6 +1555,7 @@ func (b *Block) WithSeal(header *Header) *Block {
 		header:       &cpy,
 		transactions: b.transactions,
 		uncles:       b.uncles,
+		withdrawals:  b.withdrawals,
 	}
 }
 
@@ -1387,6 +1573,20 @@ func (b *Block) WithBody(transactions []Transaction, uncles []*Header) *Block {
 	return block
 }
 
+// WithWithdrawals returns a copy of the block with the given withdrawals.
+func (b *Block) WithWithdrawals(withdrawals []*Withdrawal) *Block {
+	block := &Block{
+		header:       b.header,
+		transactions: b.transactions,
+		uncles:       b.uncles,
+	}
+	if withdrawals != nil {
+		block.withdrawals = make([]*Withdrawal, len(withdrawals))
+		copy(block.withdrawals, withdrawals)
+	}
+	return block
+}
+
 // Hash returns the keccak256 hash of b's header.
 // The hash is computed on the first call and cached thereafter.
 func (b *Block) Hash() common.Hash {
@@ -1400,6 +1600,12 @@ func (b *Block) Hash() common.Hash {
 
 type Blocks []*Block
 
+// BlockWithReceipts is a block together with the receipts of its transactions, e.g. a freshly built PoS payload
+type BlockWithReceipts struct {
+	Block    *Block
+	Receipts Receipts
+}
+
 func DecodeOnlyTxMetadataFromBody(payload []byte) (baseTxId uint64, txAmount uint32, err error) {
 	pos, _, err := rlp2.List(payload, 0)
 	if err != nil {
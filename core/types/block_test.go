@@ -214,6 +214,146 @@ func TestUncleHash(t *testing.T) {
 	}
 }
 
+func TestWithdrawalsEncoding(t *testing.T) {
+	withdrawals := []*Withdrawal{
+		{Index: 0, Validator: 0, Address: common.HexToAddress("0x00"), Amount: 0},
+		{Index: 44555666, Validator: 89, Address: common.HexToAddress("0x690b9a9e9aa1c9db991c7721a92d351db4fac990"), Amount: 2 * params.Ether / params.GWei},
+		{Index: 127, Validator: 128, Address: common.HexToAddress("0xffffffffffffffffffffffffffffffffffffffff"), Amount: math.MaxUint64},
+	}
+
+	// Withdrawal.EncodeRLP must agree with the reflection based encoder
+	type plainWithdrawal struct {
+		Index     uint64
+		Validator uint64
+		Address   common.Address
+		Amount    uint64
+	}
+	for _, w := range withdrawals {
+		enc, err := rlp.EncodeToBytes(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := rlp.EncodeToBytes(&plainWithdrawal{w.Index, w.Validator, w.Address, w.Amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(enc, want) {
+			t.Errorf("withdrawal encoding mismatch:\ngot:  %x\nwant: %x", enc, want)
+		}
+		if w.EncodingSize()+1 != len(enc) {
+			t.Errorf("wrong EncodingSize %d for encoding of length %d", w.EncodingSize(), len(enc))
+		}
+	}
+
+	header := &Header{
+		Difficulty: common.Big0,
+		Number:     big.NewInt(17034870),
+		GasLimit:   30_000_000,
+		Time:       1681338479,
+		Extra:      []byte("withdrawals"),
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Eip1559:    true,
+	}
+	block := NewBlockWithWithdrawals(header, nil, nil, nil, withdrawals)
+	if *block.Header().WithdrawalsHash != DeriveSha(Withdrawals(withdrawals)) {
+		t.Fatalf("wrong withdrawals root %x", *block.Header().WithdrawalsHash)
+	}
+
+	enc, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	var decoded Block
+	if err = rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if decoded.Hash() != block.Hash() {
+		t.Errorf("block hash mismatch: got %x, want %x", decoded.Hash(), block.Hash())
+	}
+	if !reflect.DeepEqual(decoded.Withdrawals(), block.Withdrawals()) {
+		t.Errorf("withdrawals mismatch: got %v, want %v", decoded.Withdrawals(), block.Withdrawals())
+	}
+
+	bodyEnc, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	var body Body
+	if err = rlp.DecodeBytes(bodyEnc, &body); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if !reflect.DeepEqual(body.Withdrawals, block.Withdrawals()) {
+		t.Errorf("body withdrawals mismatch: got %v, want %v", body.Withdrawals, block.Withdrawals())
+	}
+
+	// Empty withdrawals are not the same as no withdrawals
+	empty := NewBlockWithWithdrawals(CopyHeader(header), nil, nil, nil, []*Withdrawal{})
+	if enc, err = rlp.EncodeToBytes(empty); err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	if err = rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if decoded.Withdrawals() == nil || len(decoded.Withdrawals()) != 0 {
+		t.Errorf("expected empty non-nil withdrawals, got %v", decoded.Withdrawals())
+	}
+	if *decoded.Header().WithdrawalsHash != EmptyRootHash {
+		t.Errorf("wrong empty withdrawals root %x", *decoded.Header().WithdrawalsHash)
+	}
+}
+
+func TestBlobGasHeaderEncoding(t *testing.T) {
+	blobGasUsed, excessBlobGas := uint64(2*params.BlobTxBlobGasPerBlob), uint64(10*1024*1024)
+	header := &Header{
+		Difficulty:      common.Big0,
+		Number:          big.NewInt(19426587),
+		GasLimit:        30_000_000,
+		Time:            1710338135,
+		Extra:           []byte("blobs"),
+		BaseFee:         big.NewInt(params.InitialBaseFee),
+		Eip1559:         true,
+		WithdrawalsHash: &EmptyRootHash,
+		BlobGasUsed:     &blobGasUsed,
+		ExcessBlobGas:   &excessBlobGas,
+	}
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	var decoded Header
+	if err = rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if decoded.Hash() != header.Hash() {
+		t.Errorf("header hash mismatch: got %x, want %x", decoded.Hash(), header.Hash())
+	}
+	if decoded.BlobGasUsed == nil || *decoded.BlobGasUsed != blobGasUsed {
+		t.Errorf("blobGasUsed mismatch: got %v, want %d", decoded.BlobGasUsed, blobGasUsed)
+	}
+	if decoded.ExcessBlobGas == nil || *decoded.ExcessBlobGas != excessBlobGas {
+		t.Errorf("excessBlobGas mismatch: got %v, want %d", decoded.ExcessBlobGas, excessBlobGas)
+	}
+
+	copied := CopyHeader(header)
+	*copied.BlobGasUsed = 0
+	if *header.BlobGasUsed != blobGasUsed {
+		t.Errorf("CopyHeader did not copy blobGasUsed")
+	}
+
+	// Headers before Cancun have no blob gas fields
+	header.BlobGasUsed, header.ExcessBlobGas = nil, nil
+	if enc, err = rlp.EncodeToBytes(header); err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	decoded = Header{}
+	if err = rlp.DecodeBytes(enc, &decoded); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if decoded.BlobGasUsed != nil || decoded.ExcessBlobGas != nil {
+		t.Errorf("unexpected blob gas fields: %v %v", decoded.BlobGasUsed, decoded.ExcessBlobGas)
+	}
+}
+
 var benchBuffer = bytes.NewBuffer(make([]byte, 0, 32000))
 
 func BenchmarkEncodeBlock(b *testing.B) {
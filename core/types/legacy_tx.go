@@ -89,6 +89,14 @@ func (ct CommonTx) IsStarkNet() bool {
 	return false
 }
 
+func (ct CommonTx) GetBlobHashes() []common.Hash {
+	return nil
+}
+
+func (ct CommonTx) GetBlobGas() uint64 {
+	return 0
+}
+
 // LegacyTx is the transaction data of regular Ethereum transactions.
 type LegacyTx struct {
 	CommonTx
@@ -101,7 +101,7 @@ func (tx *StarknetTransaction) DecodeRLP(s *rlp.Stream) error {
 }
 
 func (tx StarknetTransaction) GetPrice() *uint256.Int {
-	panic("implement me")
+	return tx.Tip
 }
 
 func (tx StarknetTransaction) GetTip() *uint256.Int {
@@ -109,7 +109,19 @@ func (tx StarknetTransaction) GetTip() *uint256.Int {
 }
 
 func (tx StarknetTransaction) GetEffectiveGasTip(baseFee *uint256.Int) *uint256.Int {
-	panic("implement me")
+	if baseFee == nil {
+		return tx.GetTip()
+	}
+	gasFeeCap := tx.GetFeeCap()
+	// return 0 because effectiveFee cant be < 0
+	if gasFeeCap.Lt(baseFee) {
+		return uint256.NewInt(0)
+	}
+	effectiveFee := new(uint256.Int).Sub(gasFeeCap, baseFee)
+	if tx.GetTip().Lt(effectiveFee) {
+		return tx.GetTip()
+	}
+	return effectiveFee
 }
 
 func (tx StarknetTransaction) GetFeeCap() *uint256.Int {
@@ -117,7 +129,10 @@ func (tx StarknetTransaction) GetFeeCap() *uint256.Int {
 }
 
 func (tx StarknetTransaction) Cost() *uint256.Int {
-	panic("implement me")
+	total := new(uint256.Int).SetUint64(tx.Gas)
+	total.Mul(total, tx.Tip)
+	total.Add(total, tx.Value)
+	return total
 }
 
 func (tx StarknetTransaction) AsMessage(s Signer, baseFee *big.Int, rules *params.Rules) (Message, error) {
@@ -208,11 +223,11 @@ func (tx StarknetTransaction) SigningHash(chainID *big.Int) common.Hash {
 }
 
 func (tx StarknetTransaction) Size() common.StorageSize {
-	panic("implement me")
+	return common.StorageSize(tx.EncodingSize())
 }
 
 func (tx StarknetTransaction) GetAccessList() AccessList {
-	panic("implement me")
+	return tx.AccessList
 }
 
 func (tx StarknetTransaction) RawSignatureValues() (*uint256.Int, *uint256.Int, *uint256.Int) {
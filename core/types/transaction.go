@@ -45,6 +45,7 @@ const (
 	LegacyTxType = iota
 	AccessListTxType
 	DynamicFeeTxType
+	BlobTxType
 	StarknetType
 )
 
@@ -85,6 +86,8 @@ type Transaction interface {
 	SetSender(common.Address)
 	IsContractDeploy() bool
 	IsStarkNet() bool
+	GetBlobHashes() []common.Hash
+	GetBlobGas() uint64
 }
 
 // TransactionMisc is collection of miscelaneous fields for transaction that is supposed to be embedded into concrete
@@ -153,6 +156,12 @@ func DecodeTransaction(s *rlp.Stream) (Transaction, error) {
 			return nil, err
 		}
 		tx = t
+	case BlobTxType:
+		t := &BlobTx{}
+		if err = t.DecodeRLP(s); err != nil {
+			return nil, err
+		}
+		tx = t
 	case StarknetType:
 		t := &StarknetTransaction{}
 		if err = t.DecodeRLP(s); err != nil {
@@ -462,6 +471,9 @@ type Message struct {
 	data       []byte
 	accessList AccessList
 	checkNonce bool
+
+	maxFeePerBlobGas uint256.Int
+	blobHashes       []common.Hash
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *uint256.Int, gasLimit uint64, gasPrice *uint256.Int, feeCap, tip *uint256.Int, data []byte, accessList AccessList, checkNonce bool) Message {
@@ -498,3 +510,15 @@ func (m Message) Nonce() uint64          { return m.nonce }
 func (m Message) Data() []byte           { return m.data }
 func (m Message) AccessList() AccessList { return m.accessList }
 func (m Message) CheckNonce() bool       { return m.checkNonce }
+
+func (m Message) MaxFeePerBlobGas() *uint256.Int { return &m.maxFeePerBlobGas }
+func (m Message) BlobHashes() []common.Hash      { return m.blobHashes }
+func (m Message) BlobGas() uint64                { return params.BlobTxBlobGasPerBlob * uint64(len(m.blobHashes)) }
+
+// SetBlobFields sets the blob fee cap and the versioned blob hashes, as AsMessage does for a blob transaction
+func (m *Message) SetBlobFields(maxFeePerBlobGas *uint256.Int, blobHashes []common.Hash) {
+	if maxFeePerBlobGas != nil {
+		m.maxFeePerBlobGas.Set(maxFeePerBlobGas)
+	}
+	m.blobHashes = blobHashes
+}
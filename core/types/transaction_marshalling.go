@@ -32,6 +32,10 @@ type txJSON struct {
 	ChainID    *hexutil.Big `json:"chainId,omitempty"`
 	AccessList *AccessList  `json:"accessList,omitempty"`
 
+	// Blob transaction fields:
+	MaxFeePerBlobGas    *hexutil.Big  `json:"maxFeePerBlobGas,omitempty"`
+	BlobVersionedHashes []common.Hash `json:"blobVersionedHashes,omitempty"`
+
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
 }
@@ -92,6 +96,28 @@ func (tx DynamicFeeTransaction) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&enc)
 }
 
+func (tx BlobTx) MarshalJSON() ([]byte, error) {
+	var enc txJSON
+	// These are set for all tx types.
+	enc.Hash = tx.Hash()
+	enc.Type = hexutil.Uint64(tx.Type())
+	enc.ChainID = (*hexutil.Big)(tx.ChainID.ToBig())
+	enc.AccessList = &tx.AccessList
+	enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+	enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+	enc.FeeCap = (*hexutil.Big)(tx.FeeCap.ToBig())
+	enc.Tip = (*hexutil.Big)(tx.Tip.ToBig())
+	enc.Value = (*hexutil.Big)(tx.Value.ToBig())
+	enc.Data = (*hexutil.Bytes)(&tx.Data)
+	enc.To = tx.To
+	enc.MaxFeePerBlobGas = (*hexutil.Big)(tx.MaxFeePerBlobGas.ToBig())
+	enc.BlobVersionedHashes = tx.BlobVersionedHashes
+	enc.V = (*hexutil.Big)(tx.V.ToBig())
+	enc.R = (*hexutil.Big)(tx.R.ToBig())
+	enc.S = (*hexutil.Big)(tx.S.ToBig())
+	return json.Marshal(&enc)
+}
+
 func UnmarshalTransactionFromJSON(input []byte) (Transaction, error) {
 	var p fastjson.Parser
 	v, err := p.ParseBytes(input)
@@ -125,6 +151,12 @@ func UnmarshalTransactionFromJSON(input []byte) (Transaction, error) {
 			return nil, err
 		}
 		return tx, nil
+	case BlobTxType:
+		tx := &BlobTx{}
+		if err = tx.UnmarshalJSON(input); err != nil {
+			return nil, err
+		}
+		return tx, nil
 	default:
 		return nil, fmt.Errorf("unknown transaction type: %v", txType)
 	}
@@ -298,13 +330,16 @@ func (tx *DynamicFeeTransaction) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 'nonce' in transaction")
 	}
 	tx.Nonce = uint64(*dec.Nonce)
-	if dec.GasPrice == nil {
-		return errors.New("missing required field 'gasPrice' in transaction")
+	if dec.Tip == nil {
+		return errors.New("missing required field 'maxPriorityFeePerGas' in transaction")
 	}
 	tx.Tip, overflow = uint256.FromBig(dec.Tip.ToInt())
 	if overflow {
 		return errors.New("'tip' in transaction does not fit in 256 bits")
 	}
+	if dec.FeeCap == nil {
+		return errors.New("missing required field 'maxFeePerGas' in transaction")
+	}
 	tx.FeeCap, overflow = uint256.FromBig(dec.FeeCap.ToInt())
 	if overflow {
 		return errors.New("'feeCap' in transaction does not fit in 256 bits")
@@ -356,3 +391,29 @@ func (tx *DynamicFeeTransaction) UnmarshalJSON(input []byte) error {
 	}
 	return nil
 }
+
+func (tx *BlobTx) UnmarshalJSON(input []byte) error {
+	if err := tx.DynamicFeeTransaction.UnmarshalJSON(input); err != nil {
+		return err
+	}
+	var dec txJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if tx.To == nil {
+		return errors.New("missing required field 'to' in blob transaction")
+	}
+	if dec.MaxFeePerBlobGas == nil {
+		return errors.New("missing required field 'maxFeePerBlobGas' in transaction")
+	}
+	var overflow bool
+	tx.MaxFeePerBlobGas, overflow = uint256.FromBig(dec.MaxFeePerBlobGas.ToInt())
+	if overflow {
+		return errors.New("'maxFeePerBlobGas' in transaction does not fit in 256 bits")
+	}
+	if dec.BlobVersionedHashes == nil {
+		return errors.New("missing required field 'blobVersionedHashes' in transaction")
+	}
+	tx.BlobVersionedHashes = dec.BlobVersionedHashes
+	return nil
+}
@@ -44,6 +44,13 @@ func MakeSigner(config *params.ChainConfig, blockNumber uint64) *Signer {
 	}
 	signer.unprotected = true
 	switch {
+	case config.IsCancun(blockNumber):
+		signer.protected = true
+		signer.accesslist = true
+		signer.dynamicfee = true
+		signer.blob = true
+		signer.chainID.Set(&chainId)
+		signer.chainIDMul.Mul(&chainId, u256.Num2)
 	case config.IsLondon(blockNumber):
 		// All transaction types are still supported
 		signer.protected = true
@@ -92,6 +99,9 @@ func LatestSigner(config *params.ChainConfig) *Signer {
 	signer.chainID.Set(chainId)
 	signer.chainIDMul.Mul(chainId, u256.Num2)
 	if config.ChainID != nil {
+		if config.CancunBlock != nil {
+			signer.blob = true
+		}
 		if config.LondonBlock != nil {
 			signer.dynamicfee = true
 		}
@@ -127,6 +137,7 @@ func LatestSignerForChainID(chainID *big.Int) *Signer {
 	signer.protected = true
 	signer.accesslist = true
 	signer.dynamicfee = true
+	signer.blob = true
 	return &signer
 }
 
@@ -173,10 +184,11 @@ type Signer struct {
 	protected           bool // Whether this signer should allow transactions with replay protection via chainId
 	accesslist          bool // Whether this signer should allow transactions with access list, superseeds protected
 	dynamicfee          bool // Whether this signer should allow transactions with basefee and tip (instead of gasprice), superseeds accesslist
+	blob                bool // Whether this signer should allow blob-carrying transactions (EIP-4844), superseeds dynamicfee
 }
 
 func (sg Signer) String() string {
-	return fmt.Sprintf("Signer[chainId=%s,malleable=%t,unprotected=%t,protected=%t,accesslist=%t,dynamicfee=%t", &sg.chainID, sg.maleable, sg.unprotected, sg.protected, sg.accesslist, sg.dynamicfee)
+	return fmt.Sprintf("Signer[chainId=%s,malleable=%t,unprotected=%t,protected=%t,accesslist=%t,dynamicfee=%t,blob=%t", &sg.chainID, sg.maleable, sg.unprotected, sg.protected, sg.accesslist, sg.dynamicfee, sg.blob)
 }
 
 // Sender returns the sender address of the transaction.
@@ -239,6 +251,19 @@ func (sg Signer) SenderWithContext(context *secp256k1.Context, tx Transaction) (
 		// id, add 27 to become equivalent to unprotected Homestead signatures.
 		V.Add(&t.V, u256.Num27)
 		R, S = &t.R, &t.S
+	case *BlobTx:
+		if !sg.blob {
+			return common.Address{}, fmt.Errorf("blob tx is not supported by signer %s", sg)
+		}
+		if t.ChainID == nil {
+			if !sg.chainID.IsZero() {
+				return common.Address{}, ErrInvalidChainId
+			}
+		} else if !t.ChainID.Eq(&sg.chainID) {
+			return common.Address{}, ErrInvalidChainId
+		}
+		V.Add(&t.V, u256.Num27)
+		R, S = &t.R, &t.S
 	case *StarknetTransaction:
 		if !sg.dynamicfee {
 			return common.Address{}, fmt.Errorf("dynamicfee tx is not supported by signer %s", sg)
@@ -286,6 +311,11 @@ func (sg Signer) SignatureValues(tx Transaction, sig []byte) (R, S, V *uint256.I
 			return nil, nil, nil, ErrInvalidChainId
 		}
 		R, S, V = decodeSignature(sig)
+	case *BlobTx:
+		if t.ChainID != nil && !t.ChainID.IsZero() && !t.ChainID.Eq(&sg.chainID) {
+			return nil, nil, nil, ErrInvalidChainId
+		}
+		R, S, V = decodeSignature(sig)
 	case *StarknetTransaction:
 		// Check that chain ID of tx matches the signer. We also accept ID zero here,
 		// because it indicates that the chain ID was not specified in the tx.
@@ -310,7 +340,8 @@ func (sg Signer) Equal(other Signer) bool {
 		sg.unprotected == other.unprotected &&
 		sg.protected == other.protected &&
 		sg.accesslist == other.accesslist &&
-		sg.dynamicfee == other.dynamicfee
+		sg.dynamicfee == other.dynamicfee &&
+		sg.blob == other.blob
 }
 
 func decodeSignature(sig []byte) (r, s, v *uint256.Int) {
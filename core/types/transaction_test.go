@@ -32,6 +32,7 @@ import (
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/u256"
 	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -532,6 +533,85 @@ func TestTransactionCoding(t *testing.T) {
 	}
 }
 
+func TestBlobTxCoding(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	var (
+		signer    = LatestSignerForChainID(common.Big1)
+		recipient = common.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87")
+		hashes    = []common.Hash{
+			common.HexToHash("0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8"),
+			common.HexToHash("0x0100000000000000000000000000000000000000000000000000000000000002"),
+		}
+	)
+	txdata := &BlobTx{
+		DynamicFeeTransaction: DynamicFeeTransaction{
+			CommonTx: CommonTx{
+				ChainID: uint256.NewInt(1),
+				Nonce:   7,
+				To:      &recipient,
+				Value:   uint256.NewInt(10),
+				Gas:     21000,
+				Data:    []byte("abcdef"),
+			},
+			Tip:        uint256.NewInt(2),
+			FeeCap:     uint256.NewInt(100),
+			AccessList: AccessList{{Address: recipient, StorageKeys: []common.Hash{{0}}}},
+		},
+		MaxFeePerBlobGas:    uint256.NewInt(5),
+		BlobVersionedHashes: hashes,
+	}
+	tx, err := SignNewTx(key, *signer, txdata)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if tx.Type() != BlobTxType {
+		t.Fatalf("wrong tx type %d", tx.Type())
+	}
+	if tx.GetBlobGas() != 2*params.BlobTxBlobGasPerBlob {
+		t.Errorf("wrong blob gas %d", tx.GetBlobGas())
+	}
+	sender, err := tx.Sender(*signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := crypto.PubkeyToAddress(key.PublicKey); sender != want {
+		t.Errorf("wrong sender: got %x, want %x", sender, want)
+	}
+	for _, codec := range []func(Transaction) (Transaction, error){encodeDecodeBinary, encodeDecodeJSON} {
+		parsedTx, err := codec(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = assertEqual(parsedTx, tx); err != nil {
+			t.Fatal(err)
+		}
+		blobTx, ok := parsedTx.(*BlobTx)
+		if !ok {
+			t.Fatalf("decoded tx is %T, not *BlobTx", parsedTx)
+		}
+		if !reflect.DeepEqual(blobTx.BlobVersionedHashes, hashes) {
+			t.Errorf("blob hashes mismatch: got %x, want %x", blobTx.BlobVersionedHashes, hashes)
+		}
+		if !blobTx.MaxFeePerBlobGas.Eq(txdata.MaxFeePerBlobGas) {
+			t.Errorf("maxFeePerBlobGas mismatch: got %d, want %d", blobTx.MaxFeePerBlobGas, txdata.MaxFeePerBlobGas)
+		}
+	}
+
+	// Blob transactions can't create contracts
+	var buf bytes.Buffer
+	creation := txdata.copy()
+	creation.To = nil
+	if err = creation.MarshalBinary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = UnmarshalTransactionFromBinary(buf.Bytes()); err == nil {
+		t.Errorf("expected error decoding blob tx without recipient")
+	}
+}
+
 func encodeDecodeJSON(tx Transaction) (Transaction, error) {
 	data, err := json.Marshal(tx)
 	if err != nil {
@@ -0,0 +1,168 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+//go:generate gencodec -type Withdrawal -field-override withdrawalMarshaling -out gen_withdrawal_json.go
+
+// Withdrawal represents a validator withdrawal from the consensus layer (EIP-4895).
+type Withdrawal struct {
+	Index     uint64         `json:"index"`          // monotonically increasing identifier issued by consensus layer
+	Validator uint64         `json:"validatorIndex"` // index of validator associated with withdrawal
+	Address   common.Address `json:"address"`        // target address for withdrawn ether
+	Amount    uint64         `json:"amount"`         // value of withdrawal in Gwei
+}
+
+// field type overrides for gencodec
+type withdrawalMarshaling struct {
+	Index     hexutil.Uint64
+	Validator hexutil.Uint64
+	Amount    hexutil.Uint64
+}
+
+func uint64EncodingSize(i uint64) int {
+	if i < 128 {
+		return 1
+	}
+	return 1 + (bits.Len64(i)+7)/8
+}
+
+func encodeUint64(i uint64, w io.Writer, b []byte) error {
+	if i > 0 && i < 128 {
+		b[0] = byte(i)
+		_, err := w.Write(b[:1])
+		return err
+	}
+	l := (bits.Len64(i) + 7) / 8
+	binary.BigEndian.PutUint64(b[1:], i)
+	b[8-l] = 128 + byte(l)
+	_, err := w.Write(b[8-l : 9])
+	return err
+}
+
+func (obj *Withdrawal) EncodingSize() int {
+	return uint64EncodingSize(obj.Index) + uint64EncodingSize(obj.Validator) + 21 /* Address */ + uint64EncodingSize(obj.Amount)
+}
+
+func (obj *Withdrawal) EncodeRLP(w io.Writer) error {
+	var b [33]byte
+	if err := EncodeStructSizePrefix(obj.EncodingSize(), w, b[:]); err != nil {
+		return err
+	}
+	if err := encodeUint64(obj.Index, w, b[:]); err != nil {
+		return err
+	}
+	if err := encodeUint64(obj.Validator, w, b[:]); err != nil {
+		return err
+	}
+	b[0] = 128 + 20
+	if _, err := w.Write(b[:1]); err != nil {
+		return err
+	}
+	if _, err := w.Write(obj.Address[:]); err != nil {
+		return err
+	}
+	return encodeUint64(obj.Amount, w, b[:])
+}
+
+func (obj *Withdrawal) DecodeRLP(s *rlp.Stream) error {
+	_, err := s.List()
+	if err != nil {
+		return err
+	}
+	if obj.Index, err = s.Uint(); err != nil {
+		return fmt.Errorf("read Index: %w", err)
+	}
+	if obj.Validator, err = s.Uint(); err != nil {
+		return fmt.Errorf("read Validator: %w", err)
+	}
+	var b []byte
+	if b, err = s.Bytes(); err != nil {
+		return fmt.Errorf("read Address: %w", err)
+	}
+	if len(b) != 20 {
+		return fmt.Errorf("wrong size for Address: %d", len(b))
+	}
+	copy(obj.Address[:], b)
+	if obj.Amount, err = s.Uint(); err != nil {
+		return fmt.Errorf("read Amount: %w", err)
+	}
+	return s.ListEnd()
+}
+
+// Withdrawals implements DerivableList for withdrawals.
+type Withdrawals []*Withdrawal
+
+// Len returns the length of s.
+func (s Withdrawals) Len() int { return len(s) }
+
+// EncodeIndex encodes the i'th withdrawal to w.
+func (s Withdrawals) EncodeIndex(i int, w *bytes.Buffer) {
+	if err := s[i].EncodeRLP(w); err != nil {
+		panic(err)
+	}
+}
+
+// PayloadSize returns the size of the RLP list payload of the withdrawals
+func (s Withdrawals) PayloadSize() (withdrawalsLen int) {
+	for _, withdrawal := range s {
+		withdrawalsLen++
+		withdrawalLen := withdrawal.EncodingSize()
+		if withdrawalLen >= 56 {
+			withdrawalsLen += (bits.Len(uint(withdrawalLen)) + 7) / 8
+		}
+		withdrawalsLen += withdrawalLen
+	}
+	return withdrawalsLen
+}
+
+// EncodeList writes the withdrawals as RLP list, withdrawalsLen is the list payload size (see PayloadSize)
+func (s Withdrawals) EncodeList(withdrawalsLen int, w io.Writer, b []byte) error {
+	if err := EncodeStructSizePrefix(withdrawalsLen, w, b); err != nil {
+		return err
+	}
+	for _, withdrawal := range s {
+		if err := withdrawal.EncodeRLP(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeWithdrawals reads the optional trailing withdrawals list of a block or a body.
+// Returns nil if the list is absent (pre-Shanghai), and an empty non-nil slice if it is empty.
+func DecodeWithdrawals(s *rlp.Stream) ([]*Withdrawal, error) {
+	if _, err := s.List(); err != nil {
+		if errors.Is(err, rlp.EOL) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	withdrawals := []*Withdrawal{}
+	var err error
+	for err == nil {
+		var withdrawal Withdrawal
+		if err = withdrawal.DecodeRLP(s); err != nil {
+			break
+		}
+		withdrawals = append(withdrawals, &withdrawal)
+	}
+	if !errors.Is(err, rlp.EOL) {
+		return nil, err
+	}
+	// end of Withdrawals
+	if err = s.ListEnd(); err != nil {
+		return nil, err
+	}
+	return withdrawals, nil
+}
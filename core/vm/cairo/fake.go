@@ -0,0 +1,95 @@
+package cairo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/crypto"
+)
+
+// Entry point selectors understood by the FakeRunner. The calldata of an invoke is a
+// sequence of 32-byte words: a selector followed by its arguments, repeated.
+const (
+	FakeSelectorStore  = 1 // store(key, value) writes a slot and emits an event
+	FakeSelectorLoad   = 2 // load(key) appends the value of the slot to the return data
+	FakeSelectorRevert = 3 // revert() discards every effect of the invoke
+
+	// FakeGasPerWord is the gas charged for each calldata word of an invoke
+	FakeGasPerWord = 1000
+)
+
+// FakeRunner is an in-process Runner which doesn't execute Cairo but interprets
+// calldata as a tiny set of storage operations. It is meant for tests of
+// the Starknet execution path.
+type FakeRunner struct{}
+
+// NewFakeRunner returns an in-process runner for tests
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{}
+}
+
+// Deploy records the hash of the contract code in the slot zero of the contract
+func (r *FakeRunner) Deploy(_ context.Context, req *DeployRequest) (*Result, error) {
+	return &Result{
+		StorageDiff: []StorageEntry{{Key: common.Hash{}, Value: crypto.Keccak256Hash(req.Code)}},
+		GasUsed:     FakeGasPerWord,
+	}, nil
+}
+
+// Invoke executes the storage operations encoded in the calldata
+func (r *FakeRunner) Invoke(_ context.Context, req *InvokeRequest) (*Result, error) {
+	if len(req.Calldata)%common.HashLength != 0 {
+		return nil, fmt.Errorf("calldata length %d is not a multiple of %d", len(req.Calldata), common.HashLength)
+	}
+	words := make([]common.Hash, len(req.Calldata)/common.HashLength)
+	for i := range words {
+		words[i] = common.BytesToHash(req.Calldata[i*common.HashLength : (i+1)*common.HashLength])
+	}
+
+	storage := make(map[common.Hash]common.Hash, len(req.Storage))
+	for _, entry := range req.Storage {
+		storage[entry.Key] = entry.Value
+	}
+	res := &Result{GasUsed: uint64(len(words)) * FakeGasPerWord}
+	dirty := make(map[common.Hash]struct{})
+	for i := 0; i < len(words); {
+		selector := words[i].Big().Uint64()
+		switch selector {
+		case FakeSelectorStore:
+			if i+2 >= len(words) {
+				return nil, fmt.Errorf("store at word %d: missing arguments", i)
+			}
+			key, value := words[i+1], words[i+2]
+			storage[key] = value
+			if _, ok := dirty[key]; !ok {
+				dirty[key] = struct{}{}
+				res.StorageDiff = append(res.StorageDiff, StorageEntry{Key: key})
+			}
+			res.Events = append(res.Events, Event{Keys: []common.Hash{words[i], key}, Data: value.Bytes()})
+			i += 3
+		case FakeSelectorLoad:
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("load at word %d: missing arguments", i)
+			}
+			key := words[i+1]
+			value, ok := storage[key]
+			if !ok {
+				res.MissingStorage = append(res.MissingStorage, key)
+			}
+			res.ReturnData = append(res.ReturnData, value.Bytes()...)
+			i += 2
+		case FakeSelectorRevert:
+			return &Result{Reverted: true, GasUsed: res.GasUsed}, nil
+		default:
+			return nil, fmt.Errorf("unknown selector %d at word %d", selector, i)
+		}
+	}
+	if len(res.MissingStorage) > 0 {
+		return &Result{MissingStorage: res.MissingStorage, GasUsed: res.GasUsed}, nil
+	}
+	for i := range res.StorageDiff {
+		res.StorageDiff[i].Value = storage[res.StorageDiff[i].Key]
+	}
+	return res, nil
+}
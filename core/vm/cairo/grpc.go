@@ -0,0 +1,105 @@
+package cairo
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	serviceName  = "starknet.CairoRunner"
+	deployMethod = "/" + serviceName + "/Deploy"
+	invokeMethod = "/" + serviceName + "/Invoke"
+	codecName    = "json"
+)
+
+// jsonCodec encodes the runner messages as JSON, so that runners written in any
+// language (the reference Cairo runner is in Python) don't need generated stubs
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return codecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type grpcRunner struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewGRPCRunner returns a Runner talking to a remote Cairo runner over the given connection
+func NewGRPCRunner(conn grpc.ClientConnInterface) Runner {
+	return &grpcRunner{conn: conn}
+}
+
+// Dial connects to the Cairo runner listening at addr
+func Dial(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func (r *grpcRunner) Deploy(ctx context.Context, req *DeployRequest) (*Result, error) {
+	res := new(Result)
+	if err := r.conn.Invoke(ctx, deployMethod, req, res, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *grpcRunner) Invoke(ctx context.Context, req *InvokeRequest) (*Result, error) {
+	res := new(Result)
+	if err := r.conn.Invoke(ctx, invokeMethod, req, res, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RegisterRunnerServer exposes the given Runner as a gRPC service, it is used to serve
+// the in-process runner to remote clients
+func RegisterRunnerServer(s *grpc.Server, r Runner) {
+	s.RegisterService(&runnerServiceDesc, r)
+}
+
+var runnerServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Runner)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Deploy",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(DeployRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Runner).Deploy(ctx, req)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: deployMethod}
+				return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Runner).Deploy(ctx, req.(*DeployRequest))
+				})
+			},
+		},
+		{
+			MethodName: "Invoke",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(InvokeRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Runner).Invoke(ctx, req)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: invokeMethod}
+				return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Runner).Invoke(ctx, req.(*InvokeRequest))
+				})
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
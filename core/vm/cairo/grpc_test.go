@@ -0,0 +1,57 @@
+package cairo
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCRunner(t *testing.T) {
+	ctx := context.Background()
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterRunnerServer(server, NewFakeRunner())
+	go server.Serve(lis) //nolint:errcheck
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	runner := NewGRPCRunner(conn)
+
+	code := []byte("code")
+	res, err := runner.Deploy(ctx, &DeployRequest{Code: code, Gas: 1_000_000})
+	require.NoError(t, err)
+	require.Equal(t, []StorageEntry{{Key: common.Hash{}, Value: crypto.Keccak256Hash(code)}}, res.StorageDiff)
+
+	word := func(n int64) []byte { return common.BigToHash(big.NewInt(n)).Bytes() }
+	calldata := append(append(word(FakeSelectorLoad), word(5)...), append(word(FakeSelectorStore), append(word(6), word(7)...)...)...)
+	res, err = runner.Invoke(ctx, &InvokeRequest{Code: code, Calldata: calldata, Gas: 1_000_000})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{common.BytesToHash(word(5))}, res.MissingStorage)
+	require.Empty(t, res.StorageDiff)
+
+	res, err = runner.Invoke(ctx, &InvokeRequest{Code: code, Calldata: calldata, Gas: 1_000_000, Storage: []StorageEntry{
+		{Key: common.BytesToHash(word(5)), Value: common.BytesToHash(word(9))},
+	}})
+	require.NoError(t, err)
+	require.Empty(t, res.MissingStorage)
+	require.Equal(t, word(9), res.ReturnData)
+	require.Equal(t, []StorageEntry{{Key: common.BytesToHash(word(6)), Value: common.BytesToHash(word(7))}}, res.StorageDiff)
+	require.Len(t, res.Events, 1)
+	require.Equal(t, uint64(5*FakeGasPerWord), res.GasUsed)
+
+	_, err = runner.Invoke(ctx, &InvokeRequest{Code: code, Calldata: word(42)})
+	require.Error(t, err)
+}
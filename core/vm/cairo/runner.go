@@ -0,0 +1,65 @@
+// Package cairo defines the interface between the Cairo virtual machine (CVM)
+// and the external Cairo runner which executes Starknet contracts.
+//
+// The runner is stateless: every request carries the contract code and the storage
+// slots known to be accessed, and every result reports the storage diff and events
+// produced by the execution. Applying the results to the state is up to the caller.
+package cairo
+
+import (
+	"context"
+
+	"github.com/ledgerwatch/erigon/common"
+)
+
+// Runner executes Cairo contracts on behalf of the CVM
+type Runner interface {
+	// Deploy runs the constructor of a new contract
+	Deploy(ctx context.Context, req *DeployRequest) (*Result, error)
+	// Invoke runs the entry point selected by the calldata of an already deployed contract
+	Invoke(ctx context.Context, req *InvokeRequest) (*Result, error)
+}
+
+// StorageEntry is a single storage slot of a contract
+type StorageEntry struct {
+	Key   common.Hash `json:"key"`
+	Value common.Hash `json:"value"`
+}
+
+// Event is an event emitted by a contract during execution
+type Event struct {
+	Keys []common.Hash `json:"keys"`
+	Data []byte        `json:"data"`
+}
+
+// DeployRequest asks the runner to deploy the given code at the given address
+type DeployRequest struct {
+	Address  common.Address `json:"address"`
+	Caller   common.Address `json:"caller"`
+	Code     []byte         `json:"code"`
+	Calldata []byte         `json:"calldata,omitempty"`
+	Gas      uint64         `json:"gas"`
+}
+
+// InvokeRequest asks the runner to execute a call into the given contract
+type InvokeRequest struct {
+	Address  common.Address `json:"address"`
+	Caller   common.Address `json:"caller"`
+	Code     []byte         `json:"code"`
+	Calldata []byte         `json:"calldata"`
+	Gas      uint64         `json:"gas"`
+	// Storage holds the contract storage slots made available to the execution
+	Storage []StorageEntry `json:"storage,omitempty"`
+}
+
+// Result is the outcome of a deploy or an invoke
+type Result struct {
+	ReturnData  []byte         `json:"returnData,omitempty"`
+	StorageDiff []StorageEntry `json:"storageDiff,omitempty"`
+	Events      []Event        `json:"events,omitempty"`
+	// MissingStorage lists the slots the execution needs but which were not part of
+	// the request. The caller is expected to retry with those slots added.
+	MissingStorage []common.Hash `json:"missingStorage,omitempty"`
+	Reverted       bool          `json:"reverted"`
+	GasUsed        uint64        `json:"gasUsed"`
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/ledgerwatch/erigon/crypto/blake2b"
 	"github.com/ledgerwatch/erigon/crypto/bls12381"
 	"github.com/ledgerwatch/erigon/crypto/bn256"
+	"github.com/ledgerwatch/erigon/crypto/kzg"
 	"github.com/ledgerwatch/erigon/params"
 
 	//lint:ignore SA1019 Needed for precompile
@@ -109,6 +110,21 @@ var PrecompiledContractsBerlin = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{9}): &blake2F{},
 }
 
+// PrecompiledContractsCancun contains the default set of pre-compiled Ethereum
+// contracts used in the Cancun release.
+var PrecompiledContractsCancun = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{1}):  &ecrecover{},
+	common.BytesToAddress([]byte{2}):  &sha256hash{},
+	common.BytesToAddress([]byte{3}):  &ripemd160hash{},
+	common.BytesToAddress([]byte{4}):  &dataCopy{},
+	common.BytesToAddress([]byte{5}):  &bigModExp{eip2565: true},
+	common.BytesToAddress([]byte{6}):  &bn256AddIstanbul{},
+	common.BytesToAddress([]byte{7}):  &bn256ScalarMulIstanbul{},
+	common.BytesToAddress([]byte{8}):  &bn256PairingIstanbul{},
+	common.BytesToAddress([]byte{9}):  &blake2F{},
+	common.BytesToAddress([]byte{10}): &pointEvaluation{},
+}
+
 // PrecompiledContractsBLS contains the set of pre-compiled Ethereum
 // contracts specified in EIP-2537. These are exported for testing purposes.
 var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
@@ -124,6 +140,7 @@ var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
 }
 
 var (
+	PrecompiledAddressesCancun         []common.Address
 	PrecompiledAddressesBerlin         []common.Address
 	PrecompiledAddressesIstanbul       []common.Address
 	PrecompiledAddressesIstanbulForBSC []common.Address
@@ -147,11 +164,16 @@ func init() {
 	for k := range PrecompiledContractsBerlin {
 		PrecompiledAddressesBerlin = append(PrecompiledAddressesBerlin, k)
 	}
+	for k := range PrecompiledContractsCancun {
+		PrecompiledAddressesCancun = append(PrecompiledAddressesCancun, k)
+	}
 }
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules *params.Rules) []common.Address {
 	switch {
+	case rules.IsCancun:
+		return PrecompiledAddressesCancun
 	case rules.IsBerlin:
 		return PrecompiledAddressesBerlin
 	case rules.IsIstanbul:
@@ -1071,3 +1093,42 @@ func (c *bls12381MapG2) Run(input []byte) ([]byte, error) {
 	// Encode the G2 point to 256 bytes
 	return g.EncodePoint(r), nil
 }
+
+var (
+	errPointEvaluationInputLength = errors.New("invalid input length")
+	errPointEvaluationMismatch    = errors.New("mismatched versioned hash")
+)
+
+// pointEvaluationReturnValue is FIELD_ELEMENTS_PER_BLOB followed by BLS_MODULUS, both as 32-byte big-endian words
+var pointEvaluationReturnValue = append(
+	common.LeftPadBytes(big.NewInt(kzg.FieldElementsPerBlob).Bytes(), 32),
+	common.LeftPadBytes(kzg.BLSModulus.Bytes(), 32)...,
+)
+
+// pointEvaluation implements the KZG point evaluation precompile of EIP-4844
+type pointEvaluation struct{}
+
+func (c *pointEvaluation) RequiredGas(input []byte) uint64 {
+	return params.BlobTxPointEvaluationPrecompileGas
+}
+
+func (c *pointEvaluation) Run(input []byte) ([]byte, error) {
+	// The input is versioned_hash (32) | z (32) | y (32) | commitment (48) | proof (48)
+	if len(input) != 32+32+32+kzg.CommitmentLength+kzg.ProofLength {
+		return nil, errPointEvaluationInputLength
+	}
+	var versionedHash common.Hash
+	copy(versionedHash[:], input[:32])
+	var z, y [32]byte
+	copy(z[:], input[32:64])
+	copy(y[:], input[64:96])
+	commitment := input[96 : 96+kzg.CommitmentLength]
+	if kzg.KZGToVersionedHash(commitment) != versionedHash {
+		return nil, errPointEvaluationMismatch
+	}
+	proof := input[96+kzg.CommitmentLength:]
+	if err := kzg.VerifyKZGProof(commitment, z, y, proof); err != nil {
+		return nil, err
+	}
+	return common.CopyBytes(pointEvaluationReturnValue), nil
+}
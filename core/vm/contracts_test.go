@@ -65,6 +65,8 @@ var allPrecompiles = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{16}):   &bls12381Pairing{},
 	common.BytesToAddress([]byte{17}):   &bls12381MapG1{},
 	common.BytesToAddress([]byte{18}):   &bls12381MapG2{},
+
+	common.BytesToAddress([]byte{0x0f, 0x0a}): &pointEvaluation{},
 }
 
 // EIP-152 test vectors
@@ -322,6 +324,11 @@ func BenchmarkPrecompiledBLS12381Pairing(b *testing.B)    { benchJson("blsPairin
 func BenchmarkPrecompiledBLS12381MapG1(b *testing.B)      { benchJson("blsMapG1", "11", b) }
 func BenchmarkPrecompiledBLS12381MapG2(b *testing.B)      { benchJson("blsMapG2", "12", b) }
 
+// The point evaluation precompile of EIP-4844 is at 0x0a since Cancun, which is taken here by BLS12-381 G1Add
+func TestPrecompiledPointEvaluation(t *testing.T)      { testJson("pointEvaluation", "f0a", t) }
+func TestPrecompiledPointEvaluationFail(t *testing.T)  { testJsonFail("pointEvaluation", "f0a", t) }
+func BenchmarkPrecompiledPointEvaluation(b *testing.B) { benchJson("pointEvaluation", "f0a", b) }
+
 // Failure tests
 func TestPrecompiledBLS12381G1AddFail(t *testing.T)      { testJsonFail("blsG1Add", "0a", t) }
 func TestPrecompiledBLS12381G1MulFail(t *testing.T)      { testJsonFail("blsG1Mul", "0b", t) }
@@ -1,44 +1,227 @@
 package vm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
 	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
 )
 
-func NewCVM(state IntraBlockState) *CVM {
-	cvm := &CVM{
+// maxStorageRounds limits how many times an invoke is retried with the storage
+// slots reported missing by the Cairo runner
+const maxStorageRounds = 16
+
+// runnerTimeout limits a single request to the Cairo runner
+const runnerTimeout = 30 * time.Second
+
+var ErrNoCairoRunner = errors.New("no cairo runner configured")
+
+// ErrCairoRunner wraps the failures of the Cairo runner itself: no runner, transport errors, timeouts. They say nothing
+// about the transaction, so unlike other vm errors they abort the block instead of failing the transaction.
+type ErrCairoRunner struct {
+	Err error
+}
+
+func (e *ErrCairoRunner) Error() string { return fmt.Sprintf("cairo runner: %v", e.Err) }
+
+func (e *ErrCairoRunner) Unwrap() error { return e.Err }
+
+// NewCVM returns a new CVM. The returned CVM is not thread safe and should
+// only ever be used *once*.
+func NewCVM(blockCtx BlockContext, state IntraBlockState, chainConfig *params.ChainConfig, vmConfig Config) *CVM {
+	chainRules := chainConfig.Rules(blockCtx.BlockNumber)
+	chainRules.IsStarknet = true
+
+	return &CVM{
+		context:         blockCtx,
 		intraBlockState: state,
+		config:          vmConfig,
+		chainConfig:     chainConfig,
+		chainRules:      chainRules,
 	}
-
-	return cvm
 }
 
+// CVM is the Cairo virtual machine. It doesn't interpret Cairo itself but delegates the
+// execution of Starknet transactions to the configured cairo.Runner and applies the
+// resulting storage diff and events to the state.
 type CVM struct {
+	context         BlockContext
+	txContext       TxContext
 	config          Config
+	chainConfig     *params.ChainConfig
+	chainRules      *params.Rules
 	intraBlockState IntraBlockState
 }
 
-func (cvm *CVM) Create(caller ContractRef, code []byte) ([]byte, common.Address, error) {
-	address := crypto.CreateAddress(caller.Address(), cvm.intraBlockState.GetNonce(caller.Address()))
-	cvm.intraBlockState.SetCode(address, code)
-	fmt.Println(">>>> Create Starknet Contract", address.Hex())
-	return code, common.Address{}, nil
+// Reset resets the CVM with a new transaction context.
+func (cvm *CVM) Reset(txCtx TxContext, ibs IntraBlockState) {
+	cvm.txContext = txCtx
+	cvm.intraBlockState = ibs
+}
+
+// Create deploys a new Starknet contract. Like EVM.Create it increments the nonce
+// of the caller and derives the contract address from it.
+func (cvm *CVM) Create(caller ContractRef, code []byte, gas uint64, value *uint256.Int) ([]byte, common.Address, uint64, error) {
+	if cvm.config.CairoRunner == nil {
+		return nil, common.Address{}, gas, &ErrCairoRunner{Err: ErrNoCairoRunner}
+	}
+	if !cvm.context.CanTransfer(cvm.intraBlockState, caller.Address(), value) {
+		return nil, common.Address{}, gas, ErrInsufficientBalance
+	}
+	nonce := cvm.intraBlockState.GetNonce(caller.Address())
+	if nonce+1 < nonce {
+		return nil, common.Address{}, gas, ErrNonceUintOverflow
+	}
+	address := crypto.CreateAddress(caller.Address(), nonce)
+	cvm.intraBlockState.SetNonce(caller.Address(), nonce+1)
+
+	// Ensure there's no existing contract already at the designated address
+	contractHash := cvm.intraBlockState.GetCodeHash(address)
+	if cvm.intraBlockState.GetNonce(address) != 0 || (contractHash != (common.Hash{}) && contractHash != emptyCodeHash) {
+		return nil, common.Address{}, 0, ErrContractAddressCollision
+	}
+	snapshot := cvm.intraBlockState.Snapshot()
+	cvm.intraBlockState.CreateAccount(address, true)
+	cvm.intraBlockState.SetNonce(address, 1)
+	cvm.context.Transfer(cvm.intraBlockState, caller.Address(), address, value, false /* bailout */)
+
+	ctx, cancel := context.WithTimeout(context.Background(), runnerTimeout)
+	res, err := cvm.config.CairoRunner.Deploy(ctx, &cairo.DeployRequest{
+		Address: address,
+		Caller:  caller.Address(),
+		Code:    code,
+		Gas:     gas,
+	})
+	cancel()
+	if err != nil {
+		cvm.intraBlockState.RevertToSnapshot(snapshot)
+		return nil, address, 0, &ErrCairoRunner{Err: fmt.Errorf("deploy: %w", err)}
+	}
+	if len(res.MissingStorage) > 0 {
+		cvm.intraBlockState.RevertToSnapshot(snapshot)
+		return nil, address, 0, fmt.Errorf("cairo deploy: constructor of a new contract requested %d storage slots", len(res.MissingStorage))
+	}
+	// The code is only stored once the constructor succeeded
+	ret, leftOverGas, err := cvm.apply(address, snapshot, gas, res)
+	if err == nil {
+		cvm.intraBlockState.SetCode(address, code)
+	}
+	return ret, address, leftOverGas, err
+}
+
+// Call invokes the Starknet contract at the given address, the input is passed
+// to the runner as calldata.
+func (cvm *CVM) Call(caller ContractRef, addr common.Address, input []byte, gas uint64, value *uint256.Int, bailout bool) ([]byte, uint64, error) {
+	if cvm.config.CairoRunner == nil {
+		return nil, gas, &ErrCairoRunner{Err: ErrNoCairoRunner}
+	}
+	if !value.IsZero() && !bailout && !cvm.context.CanTransfer(cvm.intraBlockState, caller.Address(), value) {
+		return nil, gas, ErrInsufficientBalance
+	}
+	snapshot := cvm.intraBlockState.Snapshot()
+	if !cvm.intraBlockState.Exist(addr) {
+		cvm.intraBlockState.CreateAccount(addr, false)
+	}
+	cvm.context.Transfer(cvm.intraBlockState, caller.Address(), addr, value, bailout)
 
-	//TODO:: execute cairo construct
-	//ret, err := cvm.run(code)
+	code := cvm.intraBlockState.GetCode(addr)
+	if len(code) == 0 {
+		return nil, gas, nil
+	}
+
+	req := &cairo.InvokeRequest{
+		Address:  addr,
+		Caller:   caller.Address(),
+		Code:     code,
+		Calldata: input,
+		Gas:      gas,
+	}
+	loaded := make(map[common.Hash]struct{})
+	for round := 0; ; round++ {
+		ctx, cancel := context.WithTimeout(context.Background(), runnerTimeout)
+		res, err := cvm.config.CairoRunner.Invoke(ctx, req)
+		cancel()
+		if err != nil {
+			cvm.intraBlockState.RevertToSnapshot(snapshot)
+			return nil, 0, &ErrCairoRunner{Err: fmt.Errorf("invoke: %w", err)}
+		}
+		if len(res.MissingStorage) == 0 {
+			return cvm.apply(addr, snapshot, gas, res)
+		}
+		if round == maxStorageRounds {
+			cvm.intraBlockState.RevertToSnapshot(snapshot)
+			return nil, 0, fmt.Errorf("cairo invoke: storage still missing after %d rounds", maxStorageRounds)
+		}
+		for i := range res.MissingStorage {
+			key := res.MissingStorage[i]
+			if _, ok := loaded[key]; ok {
+				cvm.intraBlockState.RevertToSnapshot(snapshot)
+				return nil, 0, &ErrCairoRunner{Err: fmt.Errorf("invoke: runner requested storage slot %x twice", key)}
+			}
+			loaded[key] = struct{}{}
+			var slot uint256.Int
+			cvm.intraBlockState.GetState(addr, &key, &slot)
+			req.Storage = append(req.Storage, cairo.StorageEntry{Key: key, Value: slot.Bytes32()})
+		}
+	}
+}
+
+// apply charges the gas used by the runner and writes the storage diff and the events
+// of a successful execution to the state. Any other outcome reverts to the snapshot.
+func (cvm *CVM) apply(address common.Address, snapshot int, gas uint64, res *cairo.Result) ([]byte, uint64, error) {
+	if res.GasUsed > gas {
+		cvm.intraBlockState.RevertToSnapshot(snapshot)
+		return nil, 0, ErrOutOfGas
+	}
+	leftOverGas := gas - res.GasUsed
+	if res.Reverted {
+		cvm.intraBlockState.RevertToSnapshot(snapshot)
+		return res.ReturnData, leftOverGas, ErrExecutionReverted
+	}
+	for i := range res.StorageDiff {
+		key := res.StorageDiff[i].Key
+		var value uint256.Int
+		value.SetBytes(res.StorageDiff[i].Value.Bytes())
+		cvm.intraBlockState.SetState(address, &key, value)
+	}
+	for _, event := range res.Events {
+		cvm.intraBlockState.AddLog(&types.Log{
+			Address:     address,
+			Topics:      event.Keys,
+			Data:        event.Data,
+			BlockNumber: cvm.context.BlockNumber,
+		})
+	}
+	return res.ReturnData, leftOverGas, nil
 }
 
 func (cvm *CVM) Config() Config {
 	return cvm.config
 }
 
+func (cvm *CVM) ChainConfig() *params.ChainConfig {
+	return cvm.chainConfig
+}
+
+func (cvm *CVM) ChainRules() *params.Rules {
+	return cvm.chainRules
+}
+
+func (cvm *CVM) Context() BlockContext {
+	return cvm.context
+}
+
+func (cvm *CVM) TxContext() TxContext {
+	return cvm.txContext
+}
+
 func (cvm *CVM) IntraBlockState() IntraBlockState {
 	return cvm.intraBlockState
 }
-
-//func (cvm *CVM) run(code []byte) ([]byte, error) {
-//	// TODO:: call grpc cairo
-//	return code, nil
-//}
@@ -1,33 +1,27 @@
 package vm
 
 import (
-	"fmt"
-
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/params"
 )
 
-const CairoNotImplemented = "the method is currently not implemented for cvm: %s"
-
+// CVMAdapter exposes the CVM through the VMInterface, so that Starknet transactions
+// go through the same state transition as the Ethereum ones
 type CVMAdapter struct {
 	Cvm *CVM
 }
 
 func (c *CVMAdapter) Reset(txCtx TxContext, ibs IntraBlockState) {
-	c.Cvm.intraBlockState = ibs
+	c.Cvm.Reset(txCtx, ibs)
 }
 
 func (c *CVMAdapter) Create(caller ContractRef, code []byte, gas uint64, value *uint256.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
-	leftOverGas = 0
-
-	ret, contractAddr, err = c.Cvm.Create(caller, code)
-
-	return ret, contractAddr, leftOverGas, err
+	return c.Cvm.Create(caller, code, gas, value)
 }
 
 func (cvm *CVMAdapter) Call(caller ContractRef, addr common.Address, input []byte, gas uint64, value *uint256.Int, bailout bool) (ret []byte, leftOverGas uint64, err error) {
-	return nil, 0, fmt.Errorf(CairoNotImplemented, "Call")
+	return cvm.Cvm.Call(caller, addr, input, gas, value, bailout)
 }
 
 func (cvm *CVMAdapter) Config() Config {
@@ -35,15 +29,15 @@ func (cvm *CVMAdapter) Config() Config {
 }
 
 func (cvm *CVMAdapter) ChainConfig() *params.ChainConfig {
-	return params.FermionChainConfig
+	return cvm.Cvm.ChainConfig()
 }
 
 func (cvm *CVMAdapter) ChainRules() *params.Rules {
-	return &params.Rules{}
+	return cvm.Cvm.ChainRules()
 }
 
 func (cvm *CVMAdapter) Context() BlockContext {
-	return BlockContext{}
+	return cvm.Cvm.Context()
 }
 
 func (cvm *CVMAdapter) IntraBlockState() IntraBlockState {
@@ -51,5 +45,5 @@ func (cvm *CVMAdapter) IntraBlockState() IntraBlockState {
 }
 
 func (cvm *CVMAdapter) TxContext() TxContext {
-	return TxContext{}
+	return cvm.Cvm.TxContext()
 }
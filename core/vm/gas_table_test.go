@@ -114,3 +114,63 @@ func TestEIP2200(t *testing.T) {
 		})
 	}
 }
+
+var createGasTests = []struct {
+	code     string
+	shanghai bool
+	gasUsed  uint64
+	failure  error
+}{
+	// create(0, 0, 0xc000)
+	{"0x61C00060006000f0", false, 41225, nil},
+	// create(0, 0, 0xc000), 1536 words of init code cost 2 gas each
+	{"0x61C00060006000f0", true, 44297, nil},
+	// create2(0, 0, 0xc000, 0)
+	{"0x600061C00060006000f5", false, 50444, nil},
+	// create2(0, 0, 0xc000, 0)
+	{"0x600061C00060006000f5", true, 53516, nil},
+	// create(0, 0, 0xc001), one byte over the init code size limit
+	{"0x61C00160006000f0", false, 41234, nil},
+	{"0x61C00160006000f0", true, 100000, ErrOutOfGas},
+	// create2(0, 0, 0xc001, 0)
+	{"0x600061C00160006000f5", true, 100000, ErrOutOfGas},
+}
+
+func TestCreateGas(t *testing.T) {
+	for i, tt := range createGasTests {
+		tt := tt
+		i := i
+
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			address := common.BytesToAddress([]byte("contract"))
+			_, tx := memdb.NewTestTx(t)
+
+			s := state.New(state.NewPlainStateReader(tx))
+			s.CreateAccount(address, true)
+			s.SetCode(address, hexutil.MustDecode(tt.code))
+
+			_ = s.CommitBlock(params.AllEthashProtocolChanges.Rules(0), state.NewPlainStateWriter(tx, tx, 0))
+			vmctx := BlockContext{
+				CanTransfer: func(IntraBlockState, common.Address, *uint256.Int) bool { return true },
+				Transfer:    func(IntraBlockState, common.Address, common.Address, *uint256.Int, bool) {},
+			}
+			// Shanghai enables EIP-3860, ExtraEips would change the jump table shared by the other tests
+			chainConfig := params.AllEthashProtocolChanges
+			if tt.shanghai {
+				shanghaiConfig := *params.AllEthashProtocolChanges
+				shanghaiConfig.ShanghaiBlock = common.Big0
+				chainConfig = &shanghaiConfig
+			}
+			vmenv := NewEVM(vmctx, TxContext{}, s, chainConfig, Config{})
+
+			var startGas uint64 = 100000
+			_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, startGas, new(uint256.Int), false /* bailout */)
+			if !errors.Is(err, tt.failure) {
+				t.Errorf("test %d: failure mismatch: have %v, want %v", i, err, tt.failure)
+			}
+			if used := startGas - gas; used != tt.gasUsed {
+				t.Errorf("test %d: gas used mismatch: have %v, want %v", i, used, tt.gasUsed)
+			}
+		})
+	}
+}
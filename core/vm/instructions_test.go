@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/holiman/uint256"
@@ -650,3 +651,127 @@ func TestCreate2Addreses(t *testing.T) {
 		}
 	}
 }
+
+func TestOpMCopy(t *testing.T) {
+	// Test cases from https://eips.ethereum.org/EIPS/eip-5656#test-cases
+	for i, tc := range []struct {
+		dst, src, len uint64
+		pre           string
+		want          string
+		wantGas       uint64
+	}{
+		{ // MCOPY 0 32 32 - copy 32 bytes from offset 32 to offset 0.
+			dst: 0, src: 32, len: 32,
+			pre:     "0000000000000000000000000000000000000000000000000000000000000000 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			want:    "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			wantGas: 6,
+		},
+		{ // MCOPY 0 0 32 - copy 32 bytes from offset 0 to offset 0.
+			dst: 0, src: 0, len: 32,
+			pre:     "0101010101010101010101010101010101010101010101010101010101010101",
+			want:    "0101010101010101010101010101010101010101010101010101010101010101",
+			wantGas: 6,
+		},
+		{ // MCOPY 0 1 8 - copy 8 bytes from offset 1 to offset 0 (overlapping).
+			dst: 0, src: 1, len: 8,
+			pre:     "000102030405060708 000000000000000000000000000000000000000000000000",
+			want:    "010203040506070808 000000000000000000000000000000000000000000000000",
+			wantGas: 6,
+		},
+		{ // MCOPY 1 0 8 - copy 8 bytes from offset 0 to offset 1 (overlapping).
+			dst: 1, src: 0, len: 8,
+			pre:     "000102030405060708 000000000000000000000000000000000000000000000000",
+			want:    "000001020304050607 000000000000000000000000000000000000000000000000",
+			wantGas: 6,
+		},
+		{ // MCOPY 0 0 0 - zero length copy is a no-op.
+			dst: 0, src: 0, len: 0,
+			pre:     "0101010101010101010101010101010101010101010101010101010101010101",
+			want:    "0101010101010101010101010101010101010101010101010101010101010101",
+			wantGas: 3,
+		},
+		{ // MCOPY 32 0 32 on empty memory - expands memory to 2 words.
+			dst: 32, src: 0, len: 32,
+			pre:     "",
+			want:    "0000000000000000000000000000000000000000000000000000000000000000 0000000000000000000000000000000000000000000000000000000000000000",
+			wantGas: 12,
+		},
+	} {
+		var (
+			env            = NewEVM(BlockContext{}, TxContext{}, nil, params.TestChainConfig, Config{})
+			stack          = stack.New()
+			mem            = NewMemory()
+			evmInterpreter = NewEVMInterpreter(env, env.Config())
+			pc             = uint64(0)
+		)
+		env.interpreter = evmInterpreter
+		stack.Push(new(uint256.Int).SetUint64(tc.len))
+		stack.Push(new(uint256.Int).SetUint64(tc.src))
+		stack.Push(new(uint256.Int).SetUint64(tc.dst))
+		pre := common.FromHex(strings.ReplaceAll(tc.pre, " ", ""))
+		mem.Resize(uint64(len(pre)))
+		mem.Set(0, uint64(len(pre)), pre)
+
+		memSize, overflow := memoryMcopy(stack)
+		if overflow {
+			t.Fatalf("test %d: memory size overflow", i)
+		}
+		memorySize := toWordSize(memSize) * 32
+		dynamicCost, err := gasMcopy(env, nil, stack, mem, memorySize)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if memorySize > 0 {
+			mem.Resize(memorySize)
+		}
+		opMcopy(&pc, evmInterpreter, &ScopeContext{mem, stack, nil})
+
+		want := common.FromHex(strings.ReplaceAll(tc.want, " ", ""))
+		if have := mem.Data(); !bytes.Equal(want, have) {
+			t.Errorf("test %d: memory mismatch\nhave: %x\nwant: %x", i, have, want)
+		}
+		if haveGas := GasFastestStep + dynamicCost; haveGas != tc.wantGas {
+			t.Errorf("test %d: gas mismatch: have %d, want %d", i, haveGas, tc.wantGas)
+		}
+	}
+}
+
+func TestOpBlobHash(t *testing.T) {
+	blobHashes := []common.Hash{
+		common.HexToHash("0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8"),
+		common.HexToHash("0x0100000000000000000000000000000000000000000000000000000000000000"),
+	}
+	for _, tc := range []struct {
+		name   string
+		index  *uint256.Int
+		hashes []common.Hash
+		want   common.Hash
+	}{
+		{name: "first", index: uint256.NewInt(0), hashes: blobHashes, want: blobHashes[0]},
+		{name: "second", index: uint256.NewInt(1), hashes: blobHashes, want: blobHashes[1]},
+		{name: "out of range", index: uint256.NewInt(2), hashes: blobHashes},
+		{name: "above uint64", index: new(uint256.Int).Lsh(uint256.NewInt(1), 64), hashes: blobHashes},
+		{name: "no blobs", index: uint256.NewInt(0)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				env            = NewEVM(BlockContext{}, TxContext{BlobHashes: tc.hashes}, nil, params.TestChainConfig, Config{})
+				stack          = stack.New()
+				evmInterpreter = NewEVMInterpreter(env, env.Config())
+				pc             = uint64(0)
+			)
+			env.interpreter = evmInterpreter
+			stack.Push(tc.index)
+			if _, err := opBlobHash(&pc, evmInterpreter, &ScopeContext{nil, stack, nil}); err != nil {
+				t.Fatal(err)
+			}
+			if len(stack.Data) != 1 {
+				t.Fatalf("expected one item on stack, got %d", len(stack.Data))
+			}
+			result := stack.Pop()
+			if got := common.Hash(result.Bytes32()); got != tc.want {
+				t.Errorf("expected %x, got %x", tc.want, got)
+			}
+		})
+	}
+}
@@ -51,6 +51,9 @@ type IntraBlockState interface {
 	GetState(address common.Address, slot *common.Hash, outValue *uint256.Int)
 	SetState(common.Address, *common.Hash, uint256.Int)
 
+	GetTransientState(addr common.Address, key common.Hash) uint256.Int
+	SetTransientState(addr common.Address, key common.Hash, value uint256.Int)
+
 	Suicide(common.Address) bool
 	HasSuicided(common.Address) bool
 
@@ -22,6 +22,7 @@ import (
 
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/math"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
 	"github.com/ledgerwatch/erigon/core/vm/stack"
 	"github.com/ledgerwatch/log/v3"
 )
@@ -38,6 +39,8 @@ type Config struct {
 	ReadOnly      bool   // Do no perform any block finalisation
 
 	ExtraEips []int // Additional EIPS that are to be enabled
+
+	CairoRunner cairo.Runner // Executes Starknet transactions in the CVM
 }
 
 // Interpreter is used to run Ethereum based contracts and will utilise the
@@ -77,6 +77,9 @@ type JumpTable [256]*operation
 // and cancun instructions.
 func newCancunInstructionSet() JumpTable {
 	instructionSet := newShanghaiInstructionSet()
+	enable1153(&instructionSet) // Transient storage opcodes https://eips.ethereum.org/EIPS/eip-1153
+	enable4844(&instructionSet) // BLOBHASH opcode https://eips.ethereum.org/EIPS/eip-4844
+	enable5656(&instructionSet) // MCOPY opcode https://eips.ethereum.org/EIPS/eip-5656
 	return instructionSet
 }
 
@@ -85,6 +88,7 @@ func newCancunInstructionSet() JumpTable {
 func newShanghaiInstructionSet() JumpTable {
 	instructionSet := newLondonInstructionSet()
 	enable3855(&instructionSet) // PUSH0 instruction https://eips.ethereum.org/EIPS/eip-3855
+	enable3860(&instructionSet) // Limit and meter initcode https://eips.ethereum.org/EIPS/eip-3860
 	return instructionSet
 }
 
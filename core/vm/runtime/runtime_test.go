@@ -105,7 +105,7 @@ func TestExecute(t *testing.T) {
 func TestCall(t *testing.T) {
 	_, tx := memdb.NewTestTx(t)
 	state := state.New(state.NewDbStateReader(tx))
-	address := common.HexToAddress("0x0a")
+	address := common.HexToAddress("0xaa")
 	state.SetCode(address, []byte{
 		byte(vm.PUSH1), 10,
 		byte(vm.PUSH1), 0,
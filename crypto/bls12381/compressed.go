@@ -0,0 +1,151 @@
+package bls12381
+
+import (
+	"errors"
+)
+
+// Flags of the zcash serialization format, stored in the three most significant bits of the first byte.
+const (
+	compressionFlag = 1 << 7
+	infinityFlag    = 1 << 6
+	signFlag        = 1 << 5
+)
+
+// isLexicographicallyLargest returns true if the canonical form of e is larger than (p-1)/2
+func isLexicographicallyLargest(e *fe) bool {
+	return toBig(e).Cmp(pMinus1Over2) > 0
+}
+
+func isLexicographicallyLargest2(e *fe2) bool {
+	if !e[1].isZero() {
+		return isLexicographicallyLargest(&e[1])
+	}
+	return isLexicographicallyLargest(&e[0])
+}
+
+// checkCompressedFlags validates the flags of a compressed point and returns true if it is the point at infinity.
+func checkCompressedFlags(in []byte) (bool, error) {
+	if in[0]&compressionFlag == 0 {
+		return false, errors.New("point is not compressed")
+	}
+	if in[0]&infinityFlag == 0 {
+		return false, nil
+	}
+	if in[0]&^(compressionFlag|infinityFlag) != 0 {
+		return false, errors.New("invalid point at infinity")
+	}
+	for _, b := range in[1:] {
+		if b != 0 {
+			return false, errors.New("invalid point at infinity")
+		}
+	}
+	return true, nil
+}
+
+// FromCompressed constructs a new point given its 48 bytes compressed form in zcash format.
+// The point is checked to be on the curve and in the correct subgroup.
+func (g *G1) FromCompressed(in []byte) (*PointG1, error) {
+	if len(in) != 48 {
+		return nil, errors.New("compressed g1 point should be 48 bytes")
+	}
+	infinity, err := checkCompressedFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	if infinity {
+		return g.Zero(), nil
+	}
+	xBytes := make([]byte, 48)
+	copy(xBytes, in)
+	xBytes[0] &^= compressionFlag | infinityFlag | signFlag
+	x, err := fromBytes(xBytes)
+	if err != nil {
+		return nil, err
+	}
+	// y^2 = x^3 + b
+	y, y2 := new(fe), new(fe)
+	square(y2, x)
+	mul(y2, y2, x)
+	add(y2, y2, b)
+	if !sqrt(y, y2) {
+		return nil, errors.New("point is not on curve")
+	}
+	if isLexicographicallyLargest(y) != (in[0]&signFlag != 0) {
+		neg(y, y)
+	}
+	p := &PointG1{*x, *y, *new(fe).one()}
+	if !g.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in correct subgroup")
+	}
+	return p, nil
+}
+
+// FromCompressed constructs a new point given its 96 bytes compressed form in zcash format.
+// The point is checked to be on the curve and in the correct subgroup.
+func (g *G2) FromCompressed(in []byte) (*PointG2, error) {
+	if len(in) != 96 {
+		return nil, errors.New("compressed g2 point should be 96 bytes")
+	}
+	infinity, err := checkCompressedFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	if infinity {
+		return g.Zero(), nil
+	}
+	xBytes := make([]byte, 96)
+	copy(xBytes, in)
+	xBytes[0] &^= compressionFlag | infinityFlag | signFlag
+	x, err := g.f.fromBytes(xBytes)
+	if err != nil {
+		return nil, err
+	}
+	// y^2 = x^3 + b
+	y, y2 := new(fe2), new(fe2)
+	g.f.square(y2, x)
+	g.f.mul(y2, y2, x)
+	g.f.add(y2, y2, b2)
+	if !g.f.sqrt(y, y2) {
+		return nil, errors.New("point is not on curve")
+	}
+	if isLexicographicallyLargest2(y) != (in[0]&signFlag != 0) {
+		g.f.neg(y, y)
+	}
+	p := &PointG2{*x, *y, *new(fe2).one()}
+	if !g.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in correct subgroup")
+	}
+	return p, nil
+}
+
+// ToCompressed serializes a point into its 48 bytes compressed form in zcash format.
+func (g *G1) ToCompressed(p *PointG1) []byte {
+	out := make([]byte, 48)
+	if g.IsZero(p) {
+		out[0] = compressionFlag | infinityFlag
+		return out
+	}
+	g.Affine(p)
+	copy(out, toBytes(&p[0]))
+	out[0] |= compressionFlag
+	if isLexicographicallyLargest(&p[1]) {
+		out[0] |= signFlag
+	}
+	return out
+}
+
+// ToCompressed serializes a point into its 96 bytes compressed form in zcash format.
+func (g *G2) ToCompressed(p *PointG2) []byte {
+	out := make([]byte, 96)
+	if g.IsZero(p) {
+		out[0] = compressionFlag | infinityFlag
+		return out
+	}
+	g.Affine(p)
+	copy(out, g.f.toBytes(&p[0]))
+	out[0] |= compressionFlag
+	if isLexicographicallyLargest2(&p[1]) {
+		out[0] |= signFlag
+	}
+	return out
+}
@@ -51,6 +51,31 @@ func TestG1Serialization(t *testing.T) {
 			t.Fatal("bad serialization encode/decode")
 		}
 	}
+	for i := 0; i < fuz; i++ {
+		a := g1.rand()
+		compressed := g1.ToCompressed(a)
+		b, err := g1.FromCompressed(compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g1.Equal(a, b) {
+			t.Fatal("bad serialization compressed")
+		}
+	}
+	one, err := g1.FromCompressed(common.FromHex("97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g1.Equal(one, g1.one()) {
+		t.Fatal("bad compressed generator")
+	}
+	zero, err := g1.FromCompressed(g1.ToCompressed(g1.Zero()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g1.IsZero(zero) {
+		t.Fatal("bad compressed infinity")
+	}
 }
 
 func TestG1IsOnCurve(t *testing.T) {
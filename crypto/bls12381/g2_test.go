@@ -53,6 +53,31 @@ func TestG2Serialization(t *testing.T) {
 			t.Fatal("bad serialization encode/decode")
 		}
 	}
+	for i := 0; i < fuz; i++ {
+		a := g2.rand()
+		compressed := g2.ToCompressed(a)
+		b, err := g2.FromCompressed(compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g2.Equal(a, b) {
+			t.Fatal("bad serialization compressed")
+		}
+	}
+	one, err := g2.FromCompressed(common.FromHex("93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g2.Equal(one, g2.one()) {
+		t.Fatal("bad compressed generator")
+	}
+	zero, err := g2.FromCompressed(g2.ToCompressed(g2.Zero()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g2.IsZero(zero) {
+		t.Fatal("bad compressed infinity")
+	}
 }
 
 func TestG2IsOnCurve(t *testing.T) {
@@ -0,0 +1,182 @@
+// Package kzg implements the verification of KZG proofs over BLS12-381 which is needed for
+// the point evaluation precompile of EIP-4844.
+package kzg
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/crypto/bls12381"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+const (
+	FieldElementsPerBlob = 4096
+	CommitmentLength     = 48
+	ProofLength          = 48
+)
+
+// BLSModulus is the order of the BLS12-381 scalar field
+var BLSModulus, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// mainnetTrustedSetup is the output of the KZG ceremony, copied from src/trusted_setup.txt of the c-kzg-4844 library
+//
+//go:embed trusted_setup.txt
+var mainnetTrustedSetup []byte
+
+// trustedSetup is the part of the setup that is needed to verify proofs: [s]₂, the secret in G2
+type trustedSetup struct {
+	sG2 *bls12381.PointG2
+}
+
+var (
+	setup atomic.Value // the setup loaded by LoadTrustedSetup
+
+	mainnetSetupOnce sync.Once
+	mainnetSetup     *trustedSetup
+	mainnetSetupErr  error
+)
+
+// LoadTrustedSetup replaces the mainnet trusted setup with the one read from the file, see parseTrustedSetup
+// for the format
+func LoadTrustedSetup(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ts, err := parseTrustedSetup(f, path)
+	if err != nil {
+		return err
+	}
+	setup.Store(ts)
+	return nil
+}
+
+// InitTrustedSetup parses the embedded mainnet trusted setup, so the errors are found at start rather than
+// at the first verification
+func InitTrustedSetup() error {
+	_, err := loadedTrustedSetup()
+	return err
+}
+
+// loadedTrustedSetup returns the setup loaded by LoadTrustedSetup, or the mainnet one
+func loadedTrustedSetup() (*trustedSetup, error) {
+	if ts, _ := setup.Load().(*trustedSetup); ts != nil {
+		return ts, nil
+	}
+	mainnetSetupOnce.Do(func() {
+		mainnetSetup, mainnetSetupErr = parseTrustedSetup(bytes.NewReader(mainnetTrustedSetup), "mainnet trusted setup")
+	})
+	return mainnetSetup, mainnetSetupErr
+}
+
+// parseTrustedSetup reads the trusted setup in the text format of the c-kzg library:
+// the number of G1 points, the number of G2 points and then all the points, hex encoded and compressed, one per line.
+func parseTrustedSetup(r io.Reader, name string) (*trustedSetup, error) {
+	scanner := bufio.NewScanner(r)
+	next := func() (string, error) {
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				return line, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("unexpected end of %s", name)
+	}
+	var counts [2]int
+	for i := range counts {
+		line, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if counts[i], err = strconv.Atoi(line); err != nil {
+			return nil, fmt.Errorf("parse trusted setup header: %w", err)
+		}
+	}
+	if counts[0] != FieldElementsPerBlob {
+		return nil, fmt.Errorf("trusted setup has %d G1 points, expected %d", counts[0], FieldElementsPerBlob)
+	}
+	if counts[1] < 2 {
+		return nil, fmt.Errorf("trusted setup has %d G2 points, expected at least 2", counts[1])
+	}
+	for i := 0; i < counts[0]; i++ {
+		line, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if len(line) != 2*CommitmentLength {
+			return nil, fmt.Errorf("wrong size of G1 point %d in trusted setup: %d", i, len(line))
+		}
+	}
+	// skip [1]₂, the generator
+	if _, err := next(); err != nil {
+		return nil, err
+	}
+	line, err := next()
+	if err != nil {
+		return nil, err
+	}
+	sG2, err := bls12381.NewG2().FromCompressed(common.FromHex(line))
+	if err != nil {
+		return nil, fmt.Errorf("decode G2 point of trusted setup: %w", err)
+	}
+	return &trustedSetup{sG2: sG2}, nil
+}
+
+// KZGToVersionedHash computes the versioned hash of a blob commitment
+func KZGToVersionedHash(commitment []byte) common.Hash {
+	h := sha256.Sum256(commitment)
+	h[0] = params.BlobTxHashVersion
+	return h
+}
+
+// VerifyKZGProof checks that the polynomial committed to by commitment evaluates to y at the point z,
+// i.e. that e(commitment - [y]₁, [1]₂) == e(proof, [s]₂ - [z]₂). z and y are big-endian field elements.
+func VerifyKZGProof(commitment []byte, z, y [32]byte, proof []byte) error {
+	ts, err := loadedTrustedSetup()
+	if err != nil {
+		return err
+	}
+	zBig, yBig := new(big.Int).SetBytes(z[:]), new(big.Int).SetBytes(y[:])
+	if zBig.Cmp(BLSModulus) >= 0 || yBig.Cmp(BLSModulus) >= 0 {
+		return errors.New("field element is not canonical")
+	}
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	commitmentPoint, err := g1.FromCompressed(commitment)
+	if err != nil {
+		return fmt.Errorf("invalid commitment: %w", err)
+	}
+	proofPoint, err := g1.FromCompressed(proof)
+	if err != nil {
+		return fmt.Errorf("invalid proof: %w", err)
+	}
+	// commitment - [y]₁
+	yG1 := g1.MulScalar(g1.New(), g1.One(), yBig)
+	g1.Sub(commitmentPoint, commitmentPoint, yG1)
+	// [s]₂ - [z]₂
+	zG2 := g2.MulScalar(g2.New(), g2.One(), zBig)
+	sMinusZ := g2.Sub(g2.New(), ts.sG2, zG2)
+
+	engine := bls12381.NewPairingEngine()
+	engine.AddPair(commitmentPoint, g2.One())
+	engine.AddPairInv(proofPoint, sMinusZ)
+	if !engine.Check() {
+		return errors.New("invalid kzg proof")
+	}
+	return nil
+}
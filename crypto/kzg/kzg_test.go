@@ -0,0 +1,95 @@
+package kzg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/stretchr/testify/require"
+)
+
+// The vectors are from tests/verify_kzg_proof/kzg-mainnet of the c-kzg-4844 library
+var verifyKZGProofTests = []struct {
+	name                    string
+	commitment, z, y, proof string
+	valid                   bool
+}{
+	{
+		name:       "correct proof",
+		commitment: "0x93efc82d2017e9c57834a1246463e64774e56183bb247c8fc9dd98c56817e878d97b05f5c8d900acf1fbbbca6f146556",
+		z:          "0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000000",
+		y:          "0x0000000000000000000000000000000000000000000000000000000000000000",
+		proof:      "0x92c51ff81dd71dab71cefecd79e8274b4b7ba36a0f40e2dc086bc4061c7f63249877db23297212991fd63e07b7ebc348",
+		valid:      true,
+	},
+	{
+		name:       "correct proof at zero",
+		commitment: "0x93efc82d2017e9c57834a1246463e64774e56183bb247c8fc9dd98c56817e878d97b05f5c8d900acf1fbbbca6f146556",
+		z:          "0x0000000000000000000000000000000000000000000000000000000000000000",
+		y:          "0x73e66878b46ae3705eb6a46a89213de7d3686828bfce5c19400fffff00100001",
+		proof:      "0xb82ded761997f2c6f1bb3db1e1dada2ef06d936551667c82f659b75f99d2da2068b81340823ee4e829a93c9fbed7810d",
+		valid:      true,
+	},
+	{
+		name:       "correct proof at infinity",
+		commitment: "0xa572cbea904d67468808c8eb50a9450c9721db309128012543902d0ac358a62ae28f75bb8f1c7c42c39a8c5529bf0f4e",
+		z:          "0x564c0a11a0f704f4fc3e8acfe0f8245f0ad1347b378fbf96e206da11a5d36306",
+		y:          "0x0000000000000000000000000000000000000000000000000000000000000002",
+		proof:      "0xc00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		valid:      true,
+	},
+	{
+		name:       "incorrect proof",
+		commitment: "0xa572cbea904d67468808c8eb50a9450c9721db309128012543902d0ac358a62ae28f75bb8f1c7c42c39a8c5529bf0f4e",
+		z:          "0x564c0a11a0f704f4fc3e8acfe0f8245f0ad1347b378fbf96e206da11a5d36306",
+		y:          "0x0000000000000000000000000000000000000000000000000000000000000002",
+		proof:      "0x97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb",
+	},
+	{
+		name:       "non-canonical z",
+		commitment: "0x8f59a8d2a1a625a17f3fea0fe5eb8c896db3764f3185481bc22f91b4aaffcca25f26936857bc3a7c2539ea8ec3a952b7",
+		z:          "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		y:          "0x60f840641ec0d0c0d2b77b2d5a393b329442721fad05ab78c7b98f2aa3c20ec9",
+		proof:      "0xb30b3d1e4faccc380557792c9a0374d58fa286f5f75fea48870585393f890909cd3c53cfe4897e799fb211b4be531e43",
+	},
+	{
+		name:       "short commitment",
+		commitment: "0x97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6",
+		z:          "0x0000000000000000000000000000000000000000000000000000000000000001",
+		y:          "0x1824b159acc5056f998c4fefecbc4ff55884b7fa0003480200000001fffffffe",
+		proof:      "0xb0c829a8d2d3405304fecbea193e6c67f7c3912a6adc7c3737ad3f8a3b750425c1531a7426f03033a3994bc82a10609f",
+	},
+}
+
+func TestVerifyKZGProof(t *testing.T) {
+	for _, test := range verifyKZGProofTests {
+		t.Run(test.name, func(t *testing.T) {
+			var z, y [32]byte
+			copy(z[:], common.FromHex(test.z))
+			copy(y[:], common.FromHex(test.y))
+			err := VerifyKZGProof(common.FromHex(test.commitment), z, y, common.FromHex(test.proof))
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadTrustedSetup(t *testing.T) {
+	require.NoError(t, InitTrustedSetup())
+
+	dir := t.TempDir()
+	truncated := filepath.Join(dir, "truncated.txt")
+	require.NoError(t, os.WriteFile(truncated, mainnetTrustedSetup[:len(mainnetTrustedSetup)/2], 0644))
+	require.Error(t, LoadTrustedSetup(truncated))
+
+	// Loading the same setup from a file doesn't change the results
+	mainnet := filepath.Join(dir, "trusted_setup.txt")
+	require.NoError(t, os.WriteFile(mainnet, mainnetTrustedSetup, 0644))
+	require.NoError(t, LoadTrustedSetup(mainnet))
+	defer setup.Store((*trustedSetup)(nil))
+	TestVerifyKZGProof(t)
+}
@@ -15,3 +15,28 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 package eth
+
+import (
+	"context"
+
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	stages2 "github.com/ledgerwatch/erigon/turbo/stages"
+)
+
+// PrivateDebugAPI is the collection of Ethereum full-protocol APIs exposed over
+// the private debugging endpoint.
+type PrivateDebugAPI struct {
+	eth *Ethereum
+}
+
+// NewPrivateDebugAPI creates a new API definition for the full-protocol-specific
+// private debugging methods of the Ethereum service.
+func NewPrivateDebugAPI(eth *Ethereum) *PrivateDebugAPI {
+	return &PrivateDebugAPI{eth: eth}
+}
+
+// SetHead rewinds the head of the blockchain to a previous block. All stages are unwound by the
+// running node, block acceptance and the txpool are paused meanwhile.
+func (api *PrivateDebugAPI) SetHead(ctx context.Context, number hexutil.Uint64) ([]stages2.StageUnwind, error) {
+	return api.eth.headSetter.SetHead(ctx, uint64(number))
+}
@@ -53,9 +53,11 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/rpcdaemon/commands"
 	"github.com/ledgerwatch/erigon/cmd/sentry/sentry"
 	"github.com/ledgerwatch/erigon/cmd/state/exec22"
+	"github.com/ledgerwatch/erigon/cmd/verkle/verkletrie"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/debug"
 	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/consensus/aura"
 	"github.com/ledgerwatch/erigon/consensus/bor"
 	"github.com/ledgerwatch/erigon/consensus/clique"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
@@ -65,7 +67,9 @@ import (
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
 	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/core/vm/cairo"
 	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/crypto/kzg"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
 	"github.com/ledgerwatch/erigon/eth/ethconsensusconfig"
 	"github.com/ledgerwatch/erigon/eth/ethutils"
@@ -108,6 +112,11 @@ type Ethereum struct {
 
 	engine consensus.Engine
 
+	// vmConfig is shared by the execution and mining stages, cairoConn is the
+	// connection to the Cairo runner executing Starknet transactions, if any
+	vmConfig  *vm.Config
+	cairoConn *grpc.ClientConn
+
 	gasPrice  *uint256.Int
 	etherbase common.Address
 
@@ -125,6 +134,7 @@ type Ethereum struct {
 	sentryCancel   context.CancelFunc
 	sentriesClient *sentry.MultiClient
 	sentryServers  []*sentry.GrpcServer
+	peerAdmins     []sentry.PeerAdmin
 
 	stagedSync *stagedsync.Sync
 
@@ -144,11 +154,12 @@ type Ethereum struct {
 	notifyMiningAboutNewTxs chan struct{}
 	forkValidator           *engineapi.ForkValidator
 	downloader              *downloader.Downloader
+	headSetter              *stages2.HeadSetter
 }
 
 // New creates a new Ethereum object (including the
-// initialisation of the common Ethereum object)
-func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethereum, error) {
+// initialisation of the common Ethereum object), extended by the given plugins
+func New(stack *node.Node, config *ethconfig.Config, logger log.Logger, plugins ...Plugin) (*Ethereum, error) {
 	if config.Miner.GasPrice == nil || config.Miner.GasPrice.Cmp(common.Big0) <= 0 {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", ethconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(ethconfig.Defaults.Miner.GasPrice)
@@ -161,7 +172,14 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	}
 
 	// Assemble the Ethereum object
-	chainKv, err := node.OpenDatabase(stack.Config(), logger, kv.ChainDB)
+	tables := pluginTables(plugins)
+	if config.VerkleTrie {
+		// Verkle tables are not among the default ones, creating them later would race with the open transactions
+		for name, cfg := range verkletrie.TablesCfg() {
+			tables[name] = cfg
+		}
+	}
+	chainKv, err := node.OpenDatabaseWithTables(stack.Config(), logger, kv.ChainDB, tables)
 	if err != nil {
 		return nil, err
 	}
@@ -194,6 +212,13 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	types.SetHeaderSealFlag(chainConfig.IsHeaderWithSeal())
 	log.Info("Initialised chain configuration", "config", chainConfig, "genesis", genesis.Hash())
 
+	// The point evaluation precompile of Cancun verifies the proofs against the KZG trusted setup
+	if chainConfig.CancunBlock != nil {
+		if err := kzg.InitTrustedSetup(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply special hacks for BSC params
 	if chainConfig.Parlia != nil {
 		params.ApplyBinanceSmartChainParams()
@@ -264,14 +289,25 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 
 	backend.gasPrice, _ = uint256.FromBig(config.Miner.GasPrice)
 
+	backend.vmConfig = &vm.Config{}
+	if addr := stack.Config().Http.StarknetGRPCAddress; addr != "" {
+		conn, err := cairo.Dial(ctx, addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to the cairo runner at %s: %w", addr, err)
+		}
+		backend.cairoConn = conn
+		backend.vmConfig.CairoRunner = cairo.NewGRPCRunner(conn)
+	}
+
 	var sentries []direct.SentryClient
 	if len(stack.Config().P2P.SentryAddr) > 0 {
 		for _, addr := range stack.Config().P2P.SentryAddr {
-			sentryClient, err := sentry.GrpcClient(backend.sentryCtx, addr)
+			sentryClient, peerAdmin, err := sentry.GrpcClient(backend.sentryCtx, addr)
 			if err != nil {
 				return nil, err
 			}
 			sentries = append(sentries, sentryClient)
+			backend.peerAdmins = append(backend.peerAdmins, peerAdmin)
 		}
 	} else {
 		var readNodeInfo = func() *eth.NodeInfo {
@@ -293,6 +329,7 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 		server := sentry.NewGrpcServer(backend.sentryCtx, discovery, readNodeInfo, &cfg, cfg.ProtocolVersion)
 
 		backend.sentryServers = append(backend.sentryServers, server)
+		backend.peerAdmins = append(backend.peerAdmins, server)
 		sentries = []direct.SentryClient{direct.NewSentryClientDirect(cfg.ProtocolVersion, server)}
 
 		go func() {
@@ -325,7 +362,7 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	defer agg.Close()
 
 	inMemoryExecution := func(batch kv.RwTx, header *types.Header, body *types.RawBody, unwindPoint uint64, headersChain []*types.Header, bodiesChain []*types.RawBody) error {
-		stateSync, err := stages2.NewInMemoryExecution(backend.sentryCtx, backend.chainDB, config, backend.sentriesClient, dirs, backend.notifications, allSnapshots, txNums, agg)
+		stateSync, err := stages2.NewInMemoryExecution(backend.sentryCtx, backend.chainDB, config, backend.sentriesClient, dirs, backend.notifications, allSnapshots, txNums, agg, backend.vmConfig)
 		if err != nil {
 			return err
 		}
@@ -407,7 +444,7 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	mining := stagedsync.New(
 		stagedsync.MiningStages(backend.sentryCtx,
 			stagedsync.StageMiningCreateBlockCfg(backend.chainDB, miner, *backend.chainConfig, backend.engine, backend.txPool2, backend.txPool2DB, nil, tmpdir),
-			stagedsync.StageMiningExecCfg(backend.chainDB, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, nil),
+			stagedsync.StageMiningExecCfg(backend.chainDB, miner, backend.notifications.Events, *backend.chainConfig, backend.engine, backend.vmConfig, tmpdir, nil),
 			stagedsync.StageHashStateCfg(backend.chainDB, dirs, config.HistoryV2, txNums, agg),
 			stagedsync.StageTrieCfg(backend.chainDB, false, true, true, tmpdir, blockReader, nil, config.HistoryV2, txNums, agg),
 			stagedsync.StageMiningFinishCfg(backend.chainDB, *backend.chainConfig, backend.engine, miner, backend.miningSealingQuit),
@@ -419,13 +456,13 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	}
 
 	// proof-of-stake mining
-	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32) (*types.Block, error) {
+	assembleBlockPOS := func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error) {
 		miningStatePos := stagedsync.NewProposingState(&config.Miner)
 		miningStatePos.MiningConfig.Etherbase = param.SuggestedFeeRecipient
 		proposingSync := stagedsync.New(
 			stagedsync.MiningStages(backend.sentryCtx,
 				stagedsync.StageMiningCreateBlockCfg(backend.chainDB, miningStatePos, *backend.chainConfig, backend.engine, backend.txPool2, backend.txPool2DB, param, tmpdir),
-				stagedsync.StageMiningExecCfg(backend.chainDB, miningStatePos, backend.notifications.Events, *backend.chainConfig, backend.engine, &vm.Config{}, tmpdir, interrupt),
+				stagedsync.StageMiningExecCfg(backend.chainDB, miningStatePos, backend.notifications.Events, *backend.chainConfig, backend.engine, backend.vmConfig, tmpdir, interrupt),
 				stagedsync.StageHashStateCfg(backend.chainDB, dirs, config.HistoryV2, txNums, agg),
 				stagedsync.StageTrieCfg(backend.chainDB, false, true, true, tmpdir, blockReader, nil, config.HistoryV2, txNums, agg),
 				stagedsync.StageMiningFinishCfg(backend.chainDB, *backend.chainConfig, backend.engine, miningStatePos, backend.miningSealingQuit),
@@ -434,8 +471,8 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 		if err := stages2.MiningStep(ctx, backend.chainDB, proposingSync); err != nil {
 			return nil, err
 		}
-		block := <-miningStatePos.MiningResultPOSCh
-		return block, nil
+		blockWithReceipts := <-miningStatePos.MiningResultPOSCh
+		return blockWithReceipts, nil
 	}
 
 	// Initialize ethbackend
@@ -544,11 +581,13 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 		headCh = make(chan *types.Block, 1)
 	}
 
-	backend.stagedSync, err = stages2.NewStagedSync(backend.sentryCtx, backend.chainDB, stack.Config().P2P, config, backend.sentriesClient, backend.notifications, backend.downloaderClient, allSnapshots, headCh, txNums, agg, backend.forkValidator)
+	backend.stagedSync, err = stages2.NewStagedSync(backend.sentryCtx, backend.chainDB, stack.Config().P2P, config, backend.sentriesClient, backend.notifications, backend.downloaderClient, allSnapshots, headCh, txNums, agg, backend.forkValidator, backend.vmConfig, pluginStages(plugins, backend.chainDB))
 	if err != nil {
 		return nil, err
 	}
 
+	backend.headSetter = stages2.NewHeadSetter(config.Prune, allSnapshots)
+
 	backend.sentriesClient.Hd.StartPoSDownloader(backend.sentryCtx, backend.sentriesClient.SendHeaderRequest, backend.sentriesClient.Penalize)
 
 	emptyBadHash := config.BadBlockHash == common.Hash{}
@@ -581,7 +620,7 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	}
 	// start HTTP API
 	httpRpcCfg := stack.Config().Http
-	ethRpcClient, txPoolRpcClient, miningRpcClient, stateCache, ff, txNums, err := cli.EmbeddedServices(ctx, chainKv, httpRpcCfg.StateCache, blockReader, allSnapshots, ethBackendRPC, backend.txPool2GrpcServer, miningRPC)
+	ethRpcClient, txPoolRpcClient, miningRpcClient, stateCache, ff, txNums, err := cli.EmbeddedServices(ctx, chainKv, httpRpcCfg.StateCache, httpRpcCfg.FiltersDBPath, blockReader, allSnapshots, ethBackendRPC, &pausableTxPool{backend.txPool2GrpcServer, backend.headSetter}, miningRPC)
 	if err != nil {
 		return nil, err
 	}
@@ -590,7 +629,18 @@ func New(stack *node.Node, config *ethconfig.Config, logger log.Logger) (*Ethere
 	if casted, ok := backend.engine.(*bor.Bor); ok {
 		borDb = casted.DB
 	}
-	apiList := commands.APIList(chainKv, borDb, ethRpcClient, txPoolRpcClient, miningRpcClient, ff, stateCache, blockReader, agg, txNums, httpRpcCfg)
+	// signing accounts are only supported by the standalone rpcdaemon
+	if httpRpcCfg.Keystore != "" || httpRpcCfg.ExternalSigner != "" {
+		return nil, errors.New("signing accounts (keystore, external signer) are not supported by the embedded rpcdaemon")
+	}
+	apiList := commands.APIList(chainKv, borDb, ethRpcClient, txPoolRpcClient, miningRpcClient, ff, stateCache, blockReader, agg, txNums, nil, backend.vmConfig.CairoRunner, httpRpcCfg)
+	if casted, ok := backend.engine.(*aura.AuRa); ok && slices.Contains(httpRpcCfg.API, "aura") {
+		apiList = append(apiList, casted.APIs(nil)...)
+	}
+	if slices.Contains(httpRpcCfg.API, "debug") {
+		apiList = append(apiList, rpc.API{Namespace: "debug", Public: false, Service: NewPrivateDebugAPI(backend), Version: "1.0"})
+	}
+	apiList = append(apiList, pluginAPIs(plugins, chainKv, httpRpcCfg.API)...)
 	authApiList := commands.AuthAPIList(chainKv, ethRpcClient, txPoolRpcClient, miningRpcClient, ff, stateCache, blockReader, httpRpcCfg)
 	go func() {
 		if err := cli.StartRpcServer(ctx, httpRpcCfg, apiList, authApiList); err != nil {
@@ -729,6 +779,25 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, mining *stagedsy
 		})
 	}
 
+	var auraEngine *aura.AuRa
+	if a, ok := s.engine.(*aura.AuRa); ok {
+		auraEngine = a
+	} else if cl, ok := s.engine.(*serenity.Serenity); ok {
+		if a, ok := cl.InnerEngine().(*aura.AuRa); ok {
+			auraEngine = a
+		}
+	}
+	if auraEngine != nil {
+		if cfg.SigKey == nil {
+			log.Error("Etherbase account unavailable locally", "err", err)
+			return fmt.Errorf("signer missing: %w", err)
+		}
+
+		auraEngine.Authorize(eb, func(_ common.Address, mimeType string, message []byte) ([]byte, error) {
+			return crypto.Sign(crypto.Keccak256(message), cfg.SigKey)
+		})
+	}
+
 	go func() {
 		defer debug.LogPanic()
 		defer close(s.waitForMiningStop)
@@ -760,7 +829,7 @@ func (s *Ethereum) StartMining(ctx context.Context, db kv.RwDB, mining *stagedsy
 				return
 			}
 
-			if !works && hasWork {
+			if !works && hasWork && !s.headSetter.InProgress() {
 				works = true
 				go func() { errc <- stages2.MiningStep(ctx, db, mining) }()
 			}
@@ -862,6 +931,69 @@ func (s *Ethereum) Peers(ctx context.Context) (*remote.PeersReply, error) {
 	return &reply, nil
 }
 
+// AddPeer makes all sentries connect to the given enode and keep the connection
+func (s *Ethereum) AddPeer(ctx context.Context, url string) (bool, error) {
+	return s.forEachPeerAdmin(func(admin sentry.PeerAdmin) (bool, error) { return admin.AddPeer(ctx, url) })
+}
+
+// RemovePeer makes all sentries disconnect from the given enode
+func (s *Ethereum) RemovePeer(ctx context.Context, url string) (bool, error) {
+	return s.forEachPeerAdmin(func(admin sentry.PeerAdmin) (bool, error) { return admin.RemovePeer(ctx, url) })
+}
+
+// AddTrustedPeer allows the given enode to connect to all sentries even when their peer slots are full
+func (s *Ethereum) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return s.forEachPeerAdmin(func(admin sentry.PeerAdmin) (bool, error) { return admin.AddTrustedPeer(ctx, url) })
+}
+
+func (s *Ethereum) forEachPeerAdmin(f func(admin sentry.PeerAdmin) (bool, error)) (bool, error) {
+	var ok bool
+	for _, admin := range s.peerAdmins {
+		res, err := f(admin)
+		if err != nil {
+			return false, fmt.Errorf("ethereum backend PeerAdmin error: %w", err)
+		}
+		ok = ok || res
+	}
+	return ok, nil
+}
+
+// PeerEvents passes the connect and disconnect events of the peers of all sentries to cb until ctx is done
+func (s *Ethereum) PeerEvents(ctx context.Context, cb func(*proto_sentry.PeerEvent)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sentries := s.sentriesClient.Sentries()
+	errCh := make(chan error, len(sentries))
+	var lock sync.Mutex
+	for _, sentryClient := range sentries {
+		stream, err := sentryClient.PeerEvents(ctx, &proto_sentry.PeerEventsRequest{})
+		if err != nil {
+			return fmt.Errorf("ethereum backend MultiClient.PeerEvents error: %w", err)
+		}
+		go func() {
+			for {
+				event, err := stream.Recv()
+				if err != nil {
+					errCh <- err
+					return
+				}
+				lock.Lock()
+				cb(event)
+				lock.Unlock()
+			}
+		}()
+	}
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("ethereum backend MultiClient.PeerEvents error: %w", err)
+	}
+}
+
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
@@ -878,7 +1010,7 @@ func (s *Ethereum) Start() error {
 	s.sentriesClient.StartStreamLoops(s.sentryCtx)
 	time.Sleep(10 * time.Millisecond) // just to reduce logs order confusion
 
-	go stages2.StageLoop(s.sentryCtx, s.chainDB, s.stagedSync, s.sentriesClient.Hd, s.notifications, s.sentriesClient.UpdateHead, s.waitForStageLoopStop, s.config.Sync.LoopThrottle)
+	go stages2.StageLoop(s.sentryCtx, s.chainDB, s.stagedSync, s.sentriesClient.Hd, s.notifications, s.sentriesClient.UpdateHead, s.waitForStageLoopStop, s.config.Sync.LoopThrottle, s.headSetter)
 
 	return nil
 }
@@ -913,6 +1045,9 @@ func (s *Ethereum) Stop() error {
 	for _, sentryServer := range s.sentryServers {
 		sentryServer.Close()
 	}
+	if s.cairoConn != nil {
+		_ = s.cairoConn.Close()
+	}
 	s.chainDB.Close()
 	if s.txPool2DB != nil {
 		s.txPool2DB.Close()
@@ -940,6 +1075,19 @@ func (s *Ethereum) SentryControlServer() *sentry.MultiClient {
 	return s.sentriesClient
 }
 
+// pausableTxPool rejects local transactions while the chain is being rewound by debug_setHead
+type pausableTxPool struct {
+	txpool_proto.TxpoolServer
+	headSetter *stages2.HeadSetter
+}
+
+func (p *pausableTxPool) Add(ctx context.Context, in *txpool_proto.AddRequest) (*txpool_proto.AddReply, error) {
+	if p.headSetter.InProgress() {
+		return nil, stages2.ErrSetHeadInProgress
+	}
+	return p.TxpoolServer.Add(ctx, in)
+}
+
 // RemoveContents is like os.RemoveAll, but preserve dir itself
 func RemoveContents(dir string) error {
 	d, err := os.Open(dir)
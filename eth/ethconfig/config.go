@@ -127,6 +127,7 @@ func init() {
 type Snapshot struct {
 	Enabled        bool
 	KeepBlocks     bool // produce new snapshots of blocks but don't remove blocks from DB
+	PruneReceipts  bool // remove receipts and logs of frozen blocks from DB, only eth_getLogs and eth_getTransactionReceipt read them from snapshots
 	Produce        bool // produce new snapshots
 	NoDownloader   bool // possible to use snapshots without calling Downloader
 	Verify         bool // verify snapshots on startup
@@ -141,6 +142,9 @@ func (s Snapshot) String() string {
 	if s.KeepBlocks {
 		out = append(out, "--"+FlagSnapKeepBlocks+"=true")
 	}
+	if s.PruneReceipts {
+		out = append(out, "--"+FlagSnapPruneReceipts+"=true")
+	}
 	if !s.Produce {
 		out = append(out, "--"+FlagSnapStop+"=true")
 	}
@@ -148,8 +152,9 @@ func (s Snapshot) String() string {
 }
 
 var (
-	FlagSnapKeepBlocks = "snap.keepblocks"
-	FlagSnapStop       = "snap.stop"
+	FlagSnapKeepBlocks    = "snap.keepblocks"
+	FlagSnapPruneReceipts = "snap.prune.receipts"
+	FlagSnapStop          = "snap.stop"
 )
 
 func NewSnapCfg(enabled, keepBlocks, produce bool) Snapshot {
@@ -224,6 +229,9 @@ type Config struct {
 	// Enable WatchTheBurn stage
 	EnabledIssuance bool
 
+	// Enable VerkleTrie stage
+	VerkleTrie bool
+
 	//  New DB and Snapshots format of history allows: parallel blocks execution, get state as of given transaction without executing whole block.",
 	HistoryV2 bool
 
@@ -249,6 +257,8 @@ type Sync struct {
 
 	BlockDownloaderWindow      int
 	BodyDownloadTimeoutSeconds int // TODO: change to duration
+	// DownloadReceipts enables Receipts stage, which downloads from peers the receipts missing in the database
+	DownloadReceipts bool
 }
 
 // Chains where snapshots are enabled by default
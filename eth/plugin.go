@@ -0,0 +1,54 @@
+package eth
+
+import (
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/rpc"
+	"golang.org/x/exp/slices"
+)
+
+// Plugin - extension of Erigon by the app embedding it: custom tables in the chain database, custom stages of
+// the staged sync, and RPC APIs serving the data of these stages. See cmd/erigoncustom for an example.
+type Plugin struct {
+	// Tables are created in the chain database in addition to the default ones
+	Tables kv.TableCfg
+	// Stages returns the custom stages, they are added to the staged sync in the order they are returned
+	Stages func(db kv.RwDB) []stagedsync.CustomStage
+	// APIs returns the RPC APIs, they are served by the embedded RPC daemon if their namespace is in --http.api
+	APIs func(db kv.RoDB) []rpc.API
+}
+
+func pluginTables(plugins []Plugin) kv.TableCfg {
+	tables := kv.TableCfg{}
+	for _, p := range plugins {
+		for name, cfg := range p.Tables {
+			tables[name] = cfg
+		}
+	}
+	return tables
+}
+
+func pluginStages(plugins []Plugin, db kv.RwDB) []stagedsync.CustomStage {
+	var customStages []stagedsync.CustomStage
+	for _, p := range plugins {
+		if p.Stages != nil {
+			customStages = append(customStages, p.Stages(db)...)
+		}
+	}
+	return customStages
+}
+
+func pluginAPIs(plugins []Plugin, db kv.RoDB, namespaces []string) []rpc.API {
+	var apis []rpc.API
+	for _, p := range plugins {
+		if p.APIs == nil {
+			continue
+		}
+		for _, api := range p.APIs(db) {
+			if slices.Contains(namespaces, api.Namespace) {
+				apis = append(apis, api)
+			}
+		}
+	}
+	return apis
+}
@@ -191,6 +191,8 @@ func (tp TransactionsPacket) EncodeRLP(w io.Writer) error {
 			txLen = t.EncodingSize()
 		case *types.DynamicFeeTransaction:
 			txLen = t.EncodingSize()
+		case *types.BlobTx:
+			txLen = t.EncodingSize()
 		}
 		if txLen >= 56 {
 			txsLen += (bits.Len(uint(txLen)) + 7) / 8
@@ -220,6 +222,10 @@ func (tp TransactionsPacket) EncodeRLP(w io.Writer) error {
 			if err := t.EncodeRLP(w); err != nil {
 				return err
 			}
+		case *types.BlobTx:
+			if err := t.EncodeRLP(w); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -430,12 +436,14 @@ type BlockBodiesRLPPacket66 struct {
 type BlockBody struct {
 	Transactions []types.Transaction // Transactions contained within a block
 	Uncles       []*types.Header     // Uncles contained within a block
+	Withdrawals  []*types.Withdrawal // Withdrawals contained within a block, nil before Shanghai
 }
 
 // BlockRawBody represents the data content of a single block.
 type BlockRawBody struct {
-	Transactions [][]byte        // Transactions contained within a block
-	Uncles       []*types.Header // Uncles contained within a block
+	Transactions [][]byte            // Transactions contained within a block
+	Uncles       []*types.Header     // Uncles contained within a block
+	Withdrawals  []*types.Withdrawal // Withdrawals contained within a block, nil before Shanghai
 }
 
 func (bb BlockBody) EncodeRLP(w io.Writer) error {
@@ -453,6 +461,8 @@ func (bb BlockBody) EncodeRLP(w io.Writer) error {
 			txLen = t.EncodingSize()
 		case *types.DynamicFeeTransaction:
 			txLen = t.EncodingSize()
+		case *types.BlobTx:
+			txLen = t.EncodingSize()
 		}
 		if txLen >= 56 {
 			txsLen += (bits.Len(uint(txLen)) + 7) / 8
@@ -478,6 +488,16 @@ func (bb BlockBody) EncodeRLP(w io.Writer) error {
 		encodingSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	encodingSize += unclesLen
+	// size of Withdrawals
+	var withdrawalsLen int
+	if bb.Withdrawals != nil {
+		encodingSize++
+		withdrawalsLen = types.Withdrawals(bb.Withdrawals).PayloadSize()
+		if withdrawalsLen >= 56 {
+			encodingSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		encodingSize += withdrawalsLen
+	}
 	var b [33]byte
 	// prefix
 	if err := types.EncodeStructSizePrefix(encodingSize, w, b[:]); err != nil {
@@ -501,6 +521,10 @@ func (bb BlockBody) EncodeRLP(w io.Writer) error {
 			if err := t.EncodeRLP(w); err != nil {
 				return err
 			}
+		case *types.BlobTx:
+			if err := t.EncodeRLP(w); err != nil {
+				return err
+			}
 		}
 	}
 	// encode Uncles
@@ -512,6 +536,12 @@ func (bb BlockBody) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if bb.Withdrawals != nil {
+		if err := types.Withdrawals(bb.Withdrawals).EncodeList(withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -553,6 +583,10 @@ func (bb *BlockBody) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if bb.Withdrawals, err = types.DecodeWithdrawals(s); err != nil {
+		return err
+	}
 	return s.ListEnd()
 }
 
@@ -601,6 +635,16 @@ func (rb BlockRawBody) EncodeRLP(w io.Writer) error {
 		encodingSize += (bits.Len(uint(unclesLen)) + 7) / 8
 	}
 	encodingSize += unclesLen
+	// size of Withdrawals
+	var withdrawalsLen int
+	if rb.Withdrawals != nil {
+		encodingSize++
+		withdrawalsLen = types.Withdrawals(rb.Withdrawals).PayloadSize()
+		if withdrawalsLen >= 56 {
+			encodingSize += (bits.Len(uint(withdrawalsLen)) + 7) / 8
+		}
+		encodingSize += withdrawalsLen
+	}
 	var b [33]byte
 	// prefix
 	if err := types.EncodeStructSizePrefix(encodingSize, w, b[:]); err != nil {
@@ -624,6 +668,12 @@ func (rb BlockRawBody) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	// encode Withdrawals
+	if rb.Withdrawals != nil {
+		if err := types.Withdrawals(rb.Withdrawals).EncodeList(withdrawalsLen, w, b[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -668,20 +718,25 @@ func (rb *BlockRawBody) DecodeRLP(s *rlp.Stream) error {
 	if err = s.ListEnd(); err != nil {
 		return err
 	}
+	// decode Withdrawals
+	if rb.Withdrawals, err = types.DecodeWithdrawals(s); err != nil {
+		return err
+	}
 	return s.ListEnd()
 }
 
-// Unpack retrieves the transactions and uncles from the range packet and returns
+// Unpack retrieves the transactions, uncles and withdrawals from the range packet and returns
 // them in a split flat format that's more consistent with the internal data structures.
-func (p *BlockRawBodiesPacket) Unpack() ([][][]byte, [][]*types.Header) {
+func (p *BlockRawBodiesPacket) Unpack() ([][][]byte, [][]*types.Header, [][]*types.Withdrawal) {
 	var (
-		txset    = make([][][]byte, len(*p))
-		uncleset = make([][]*types.Header, len(*p))
+		txset         = make([][][]byte, len(*p))
+		uncleset      = make([][]*types.Header, len(*p))
+		withdrawalset = make([][]*types.Withdrawal, len(*p))
 	)
 	for i, body := range *p {
-		txset[i], uncleset[i] = body.Transactions, body.Uncles
+		txset[i], uncleset[i], withdrawalset[i] = body.Transactions, body.Uncles, body.Withdrawals
 	}
-	return txset, uncleset
+	return txset, uncleset, withdrawalset
 }
 
 // GetNodeDataPacket represents a trie node data query.
@@ -758,6 +813,8 @@ func (ptp PooledTransactionsPacket) EncodeRLP(w io.Writer) error {
 			txLen = t.EncodingSize()
 		case *types.DynamicFeeTransaction:
 			txLen = t.EncodingSize()
+		case *types.BlobTx:
+			txLen = t.EncodingSize()
 		}
 		if txLen >= 56 {
 			txsLen += (bits.Len(uint(txLen)) + 7) / 8
@@ -787,6 +844,10 @@ func (ptp PooledTransactionsPacket) EncodeRLP(w io.Writer) error {
 			if err := t.EncodeRLP(w); err != nil {
 				return err
 			}
+		case *types.BlobTx:
+			if err := t.EncodeRLP(w); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -835,6 +896,8 @@ func (ptp66 PooledTransactionsPacket66) EncodeRLP(w io.Writer) error {
 			txLen = t.EncodingSize()
 		case *types.DynamicFeeTransaction:
 			txLen = t.EncodingSize()
+		case *types.BlobTx:
+			txLen = t.EncodingSize()
 		}
 		if txLen >= 56 {
 			txsLen += (bits.Len(uint(txLen)) + 7) / 8
@@ -881,6 +944,10 @@ func (ptp66 PooledTransactionsPacket66) EncodeRLP(w io.Writer) error {
 			if err := t.EncodeRLP(w); err != nil {
 				return err
 			}
+		case *types.BlobTx:
+			if err := t.EncodeRLP(w); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
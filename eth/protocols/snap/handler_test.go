@@ -0,0 +1,218 @@
+package snap_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/direct"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	"github.com/ledgerwatch/erigon/eth/protocols/snap"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	contractAddr = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	contractCode = common.FromHex("0x600160005500")
+	slots        = map[common.Hash]common.Hash{
+		common.HexToHash("0x01"): common.HexToHash("0x0a"),
+		common.HexToHash("0x02"): common.HexToHash("0x0b"),
+		common.HexToHash("0x03"): common.HexToHash("0x0c"),
+		common.HexToHash("0x04"): common.HexToHash("0x0d"),
+	}
+	maxHash = common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+)
+
+type slimAccount struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     []byte
+	CodeHash []byte
+}
+
+// mockWithState creates a chain of one block on top of the genesis with some accounts and a contract with storage,
+// and returns the state root of its head
+func mockWithState(t *testing.T) (*stages.MockSentry, common.Hash) {
+	alloc := core.GenesisAlloc{
+		testAddr:     {Balance: big.NewInt(params.Ether)},
+		contractAddr: {Balance: new(big.Int), Code: contractCode, Storage: slots},
+	}
+	for i := 0; i < 64; i++ {
+		alloc[common.BigToAddress(big.NewInt(int64(0x10000+i)))] = core.GenesisAccount{Balance: big.NewInt(int64(i + 1))}
+	}
+	m := stages.MockWithGenesis(t, &core.Genesis{Config: params.TestChainConfig, Alloc: alloc}, testKey, false)
+	if m.HistoryV2 {
+		t.Skip("historyV2 doesn't keep HashedState and IntermediateHashes")
+	}
+	signer := types.LatestSignerForChainID(nil)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 1, func(i int, b *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), contractAddr, uint256.NewInt(1), 100000, uint256.NewInt(1), nil), *signer, testKey)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	return m, chain.TopBlock.Root()
+}
+
+// request sends the snap request to the core and decodes the response, which is the sent message number n
+func request(t *testing.T, m *stages.MockSentry, n int, id sentry.MessageId, req interface{}, res interface{}) {
+	b, err := rlp.EncodeToBytes(req)
+	require.NoError(t, err)
+	m.StreamWg.Wait()
+	m.ReceiveWg.Add(1)
+	for _, err = range m.Send(&sentry.InboundMessage{Id: id, Data: b, PeerId: m.PeerId}) {
+		require.NoError(t, err)
+	}
+	m.ReceiveWg.Wait()
+	sent := m.SentMessage(n)
+	// Ids of the responses follow the ids of the requests
+	require.Equal(t, id+1, sent.Id)
+	require.NoError(t, rlp.DecodeBytes(sent.Data, res))
+}
+
+func TestGetAccountRange(t *testing.T) {
+	m, root := mockWithState(t)
+
+	var res snap.AccountRangePacket
+	request(t, m, 0, snap.MessageIdGetAccountRange, &snap.GetAccountRangePacket{ID: 1, Root: root, Limit: maxHash, Bytes: 1 << 20}, &res)
+	require.Equal(t, uint64(1), res.ID)
+	// Genesis allocation, the coinbase of the block
+	require.Equal(t, 64+3, len(res.Accounts))
+	var contract *slimAccount
+	contractHash := crypto.Keccak256Hash(contractAddr[:])
+	for i, acc := range res.Accounts {
+		if i > 0 {
+			require.Equal(t, -1, bytes.Compare(res.Accounts[i-1].Hash[:], acc.Hash[:]))
+		}
+		var slim slimAccount
+		require.NoError(t, rlp.DecodeBytes(acc.Body, &slim))
+		if acc.Hash == contractHash {
+			contract = &slim
+		}
+	}
+	require.NotNil(t, contract)
+	require.Equal(t, crypto.Keccak256(contractCode), contract.CodeHash)
+	require.Equal(t, common.HashLength, len(contract.Root))
+	// The proof of the origin starts with the root node
+	require.NotEmpty(t, res.Proof)
+	require.Equal(t, root, crypto.Keccak256Hash(res.Proof[0]))
+
+	// The response is cut after the soft limit
+	var limited snap.AccountRangePacket
+	request(t, m, 1, snap.MessageIdGetAccountRange, &snap.GetAccountRangePacket{ID: 2, Root: root, Origin: res.Accounts[10].Hash, Limit: maxHash, Bytes: 1}, &limited)
+	require.Equal(t, 1, len(limited.Accounts))
+	require.Equal(t, res.Accounts[10].Hash, limited.Accounts[0].Hash)
+	require.Equal(t, res.Accounts[10].Body, limited.Accounts[0].Body)
+
+	// Only the head state is served
+	var unknown snap.AccountRangePacket
+	request(t, m, 2, snap.MessageIdGetAccountRange, &snap.GetAccountRangePacket{ID: 3, Root: common.Hash{1}, Limit: maxHash, Bytes: 1 << 20}, &unknown)
+	require.Empty(t, unknown.Accounts)
+	require.Empty(t, unknown.Proof)
+}
+
+func TestGetStorageRanges(t *testing.T) {
+	m, root := mockWithState(t)
+	contractHash := crypto.Keccak256Hash(contractAddr[:])
+
+	var accounts snap.AccountRangePacket
+	request(t, m, 0, snap.MessageIdGetAccountRange, &snap.GetAccountRangePacket{ID: 1, Root: root, Origin: contractHash, Limit: contractHash, Bytes: 1 << 20}, &accounts)
+	require.Equal(t, 1, len(accounts.Accounts))
+	var contract slimAccount
+	require.NoError(t, rlp.DecodeBytes(accounts.Accounts[0].Body, &contract))
+
+	var res snap.StorageRangesPacket
+	request(t, m, 1, snap.MessageIdGetStorageRanges, &snap.GetStorageRangesPacket{ID: 1, Root: root, Accounts: []common.Hash{crypto.Keccak256Hash(testAddr[:]), contractHash}, Bytes: 1 << 20}, &res)
+	// The account without storage has no slots, the whole storage of the contract needs no proof
+	require.Equal(t, 1, len(res.Slots))
+	require.Equal(t, len(slots)+1, len(res.Slots[0]))
+	require.Empty(t, res.Proof)
+	expected := map[common.Hash][]byte{}
+	for k, v := range slots {
+		expected[crypto.Keccak256Hash(k[:])] = common.CopyBytes(bytes.TrimLeft(v[:], "\x00"))
+	}
+	// Slot 0 is written by the transaction of the block
+	expected[crypto.Keccak256Hash(common.Hash{}.Bytes())] = []byte{1}
+	for _, slot := range res.Slots[0] {
+		var value []byte
+		require.NoError(t, rlp.DecodeBytes(slot.Body, &value))
+		require.Equal(t, expected[slot.Hash], value)
+	}
+
+	// The range starting in the middle of the storage is proven
+	var partial snap.StorageRangesPacket
+	origin := res.Slots[0][2].Hash
+	request(t, m, 2, snap.MessageIdGetStorageRanges, &snap.GetStorageRangesPacket{ID: 2, Root: root, Accounts: []common.Hash{contractHash}, Origin: origin[:], Bytes: 1 << 20}, &partial)
+	require.Equal(t, 1, len(partial.Slots))
+	require.Equal(t, res.Slots[0][2:], partial.Slots[0])
+	// The proof is relative to the storage root of the account
+	require.NotEmpty(t, partial.Proof)
+	require.Equal(t, contract.Root, crypto.Keccak256(partial.Proof[0]))
+}
+
+func TestGetByteCodes(t *testing.T) {
+	m, _ := mockWithState(t)
+
+	var res snap.ByteCodesPacket
+	request(t, m, 0, snap.MessageIdGetByteCodes, &snap.GetByteCodesPacket{ID: 1, Hashes: []common.Hash{crypto.Keccak256Hash(contractCode), trie.EmptyCodeHash, {1}}, Bytes: 1 << 20}, &res)
+	// The unknown code is skipped
+	require.Equal(t, 2, len(res.Codes))
+	require.Equal(t, contractCode, res.Codes[0])
+	require.Empty(t, res.Codes[1])
+}
+
+func TestGetTrieNodes(t *testing.T) {
+	m, root := mockWithState(t)
+	contractHash := crypto.Keccak256Hash(contractAddr[:])
+
+	var accounts snap.AccountRangePacket
+	request(t, m, 0, snap.MessageIdGetAccountRange, &snap.GetAccountRangePacket{ID: 1, Root: root, Origin: contractHash, Limit: contractHash, Bytes: 1 << 20}, &accounts)
+	require.Equal(t, 1, len(accounts.Accounts))
+	var contract slimAccount
+	require.NoError(t, rlp.DecodeBytes(accounts.Accounts[0].Body, &contract))
+
+	var res snap.TrieNodesPacket
+	// Root of the account trie and root of the storage trie of the contract, compact encoding of empty path
+	request(t, m, 1, snap.MessageIdGetTrieNodes, &snap.GetTrieNodesPacket{ID: 2, Root: root, Paths: []snap.TrieNodePathSet{{{0x00}}, {contractHash[:], {0x00}}}, Bytes: 1 << 20}, &res)
+	require.Equal(t, 2, len(res.Nodes))
+	require.Equal(t, root, crypto.Keccak256Hash(res.Nodes[0]))
+	require.Equal(t, contract.Root, crypto.Keccak256(res.Nodes[1]))
+}
+
+// The snap ids are added to the sentry enum in a later erigon-lib, until then they must stay clear of the eth ones
+func TestMessageIds(t *testing.T) {
+	ids := make(map[sentry.MessageId]struct{})
+	for code := uint64(0); code < snap.ProtocolLength; code++ {
+		id, ok := snap.ToProto[snap.SNAP1][code]
+		require.True(t, ok, "code %d", code)
+		require.Equal(t, code, snap.FromProto[snap.SNAP1][id])
+		ids[id] = struct{}{}
+
+		_, ok = sentry.MessageId_name[int32(id)]
+		require.False(t, ok, "id %d is in the sentry enum of erigon-lib, use the generated constants", id)
+		for version, fromProto := range eth.FromProto {
+			_, ok = fromProto[id]
+			require.False(t, ok, "id %d is an eth/%d id", id, version)
+		}
+		// Sentry clients pass the id to the sentry whatever the eth version
+		for version, protoIds := range direct.ProtoIds {
+			_, ok = protoIds[id]
+			require.True(t, ok, "id %d is filtered out for eth/%d", id, version)
+		}
+	}
+	require.Equal(t, snap.ProtocolLength, len(ids))
+}
@@ -0,0 +1,444 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+const (
+	// softResponseLimit is the target maximum size of replies to data retrievals.
+	softResponseLimit = 2 * 1024 * 1024
+
+	// maxCodeLookups is the maximum number of bytecodes to serve. This number is
+	// there to limit the number of disk lookups.
+	maxCodeLookups = 1024
+
+	// stateLookupSlack defines the ratio by how much a state response can exceed
+	// the requested limit in order to try and avoid breaking up contracts into
+	// multiple packages and proving them.
+	stateLookupSlack = 0.1
+
+	// maxTrieNodeLookups is the maximum number of state trie nodes to serve. This
+	// number is there to limit the number of disk lookups.
+	maxTrieNodeLookups = 1024
+
+	// maxTrieNodeTimeSpent is the maximum time we should spend on looking up trie nodes.
+	// If we spend too much time, then it's a fairly high chance of timing out
+	// at the remote side, which means all the work is in vain.
+	maxTrieNodeTimeSpent = 5 * time.Second
+)
+
+var errBadRequest = errors.New("bad request")
+
+// Only the state of the head block is available in HashedState and IntermediateHashes, the queries for
+// any other root are answered with empty responses, same as the ones for the unavailable state in geth.
+
+// AnswerGetAccountRangeQuery returns the accounts of the range requested by the peer with the proofs of its
+// boundaries. root is the state root of the HashedState and IntermediateHashes in the tx.
+func AnswerGetAccountRangeQuery(tx kv.Tx, root common.Hash, req *GetAccountRangePacket, quit <-chan struct{}) (*AccountRangePacket, error) {
+	response := &AccountRangePacket{ID: req.ID}
+	if req.Root != root {
+		return response, nil
+	}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	c, err := tx.Cursor(kv.HashedAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	// Storage roots of the accounts are not in HashedAccounts, so the accounts are read from the trie,
+	// which has the paths to all of them loaded. Encoding for storage is about the size of the slim one.
+	var hashes []common.Hash
+	var size uint64
+	for k, v, err := c.Seek(req.Origin[:]); k != nil; k, v, err = c.Next() {
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, common.BytesToHash(k))
+		size += uint64(common.HashLength + len(v))
+		if bytes.Compare(k, req.Limit[:]) >= 0 || size > req.Bytes {
+			break
+		}
+	}
+	c.Close()
+
+	rl := trie.NewRetainList(0)
+	rl.AddKey(req.Origin[:])
+	for _, hash := range hashes {
+		rl.AddKey(hash[:])
+	}
+	tr, err := loadTrie(tx, root, rl, quit)
+	if err != nil {
+		return nil, err
+	}
+	for _, hash := range hashes {
+		acc, ok := tr.GetAccount(hash[:])
+		if !ok || acc == nil {
+			return nil, fmt.Errorf("account %x is missing in the trie", hash)
+		}
+		body, err := slimAccountRLP(acc)
+		if err != nil {
+			return nil, err
+		}
+		response.Accounts = append(response.Accounts, &AccountData{Hash: hash, Body: body})
+	}
+
+	// Generate the Merkle proofs for the first and last account
+	proof := newProofSet()
+	if err = proof.add(tr.Prove(req.Origin[:], 0, false)); err != nil {
+		return nil, err
+	}
+	if len(hashes) > 0 {
+		if err = proof.add(tr.Prove(hashes[len(hashes)-1][:], 0, false)); err != nil {
+			return nil, err
+		}
+	}
+	response.Proof = proof.nodes
+	return response, nil
+}
+
+// AnswerGetStorageRangesQuery returns the storage slots of the accounts requested by the peer. The proofs are
+// provided only for the last range if it isn't complete.
+func AnswerGetStorageRangesQuery(tx kv.Tx, root common.Hash, req *GetStorageRangesPacket, quit <-chan struct{}) (*StorageRangesPacket, error) {
+	response := &StorageRangesPacket{ID: req.ID}
+	if req.Root != root {
+		return response, nil
+	}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	// Calculate the hard limit at which to abort, even if mistreating the request
+	hardLimit := uint64(float64(req.Bytes) * (1 + stateLookupSlack))
+
+	c, err := tx.Cursor(kv.HashedStorage)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var size uint64
+	for _, account := range req.Accounts {
+		// If we've exceeded the requested data limit, abort without opening
+		// a new storage range (that we'd need to prove due to exceeded size)
+		if size >= req.Bytes {
+			break
+		}
+		// The first account might start from a different origin and end sooner
+		var origin common.Hash
+		if len(req.Origin) > 0 {
+			origin, req.Origin = common.BytesToHash(req.Origin), nil
+		}
+		var limit = common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+		if len(req.Limit) > 0 {
+			limit, req.Limit = common.BytesToHash(req.Limit), nil
+		}
+		enc, err := tx.GetOne(kv.HashedAccounts, account[:])
+		if err != nil {
+			return nil, err
+		}
+		if len(enc) == 0 {
+			continue
+		}
+		incarnation, err := accounts.DecodeIncarnationFromStorage(enc)
+		if err != nil {
+			return nil, err
+		}
+		prefix := dbutils.GenerateStoragePrefix(account[:], incarnation)
+
+		// Iterate over the requested range and pile slots up
+		var (
+			storage []*StorageData
+			last    common.Hash
+			abort   bool
+		)
+		for k, v, err := c.Seek(append(common.CopyBytes(prefix), origin[:]...)); k != nil && bytes.HasPrefix(k, prefix); k, v, err = c.Next() {
+			if err != nil {
+				return nil, err
+			}
+			if size >= hardLimit {
+				abort = true
+				break
+			}
+			hash := common.BytesToHash(k[len(prefix):])
+			body, err := rlp.EncodeToBytes(v)
+			if err != nil {
+				return nil, err
+			}
+			// Track the returned interval for the Merkle proofs
+			last = hash
+
+			size += uint64(common.HashLength + len(body))
+			storage = append(storage, &StorageData{Hash: hash, Body: body})
+			// If we've exceeded the request threshold, abort
+			if bytes.Compare(hash[:], limit[:]) >= 0 {
+				break
+			}
+		}
+		if len(storage) > 0 {
+			response.Slots = append(response.Slots, storage)
+		}
+		// Generate the Merkle proofs for the first and last storage slot, but
+		// only if the response was capped. If the entire storage trie included
+		// in the response, no need for any proofs.
+		if origin != (common.Hash{}) || (abort && len(storage) > 0) {
+			rl := trie.NewRetainList(0)
+			rl.AddKey(account[:])
+			rl.AddKey(dbutils.GenerateCompositeStorageKey(account, incarnation, origin))
+			if last != (common.Hash{}) {
+				rl.AddKey(dbutils.GenerateCompositeStorageKey(account, incarnation, last))
+			}
+			tr, err := loadTrie(tx, root, rl, quit)
+			if err != nil {
+				return nil, err
+			}
+			proof := newProofSet()
+			if err = proof.add(tr.Prove(append(common.CopyBytes(account[:]), origin[:]...), 2*common.HashLength, true)); err != nil {
+				return nil, err
+			}
+			if last != (common.Hash{}) {
+				if err = proof.add(tr.Prove(append(common.CopyBytes(account[:]), last[:]...), 2*common.HashLength, true)); err != nil {
+					return nil, err
+				}
+			}
+			response.Proof = proof.nodes
+			// Proof terminates the reply as proofs are only added if a node
+			// refuses to serve more data (exception when a contract fetch is
+			// finishing, but that's that).
+			break
+		}
+	}
+	return response, nil
+}
+
+// AnswerGetByteCodesQuery returns the bytecodes requested by the peer. The bytecodes are not bound to
+// any state root, so all of them are served.
+func AnswerGetByteCodesQuery(tx kv.Tx, req *GetByteCodesPacket) (*ByteCodesPacket, error) {
+	response := &ByteCodesPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	if len(req.Hashes) > maxCodeLookups {
+		req.Hashes = req.Hashes[:maxCodeLookups]
+	}
+	var size uint64
+	for _, hash := range req.Hashes {
+		if hash == trie.EmptyCodeHash {
+			// Peers should not request the empty code, but if they do, at
+			// least sent them back a correct response without db lookups
+			response.Codes = append(response.Codes, []byte{})
+		} else {
+			code, err := tx.GetOne(kv.Code, hash[:])
+			if err != nil {
+				return nil, err
+			}
+			if len(code) > 0 {
+				response.Codes = append(response.Codes, common.CopyBytes(code))
+				size += uint64(len(code))
+			}
+		}
+		if size > req.Bytes {
+			break
+		}
+	}
+	return response, nil
+}
+
+// AnswerGetTrieNodesQuery returns the trie nodes requested by the peer. The trie nodes are not stored in the
+// database, so the trie is loaded with all the requested paths first, and the nodes are encoded from it.
+func AnswerGetTrieNodesQuery(tx kv.Tx, root common.Hash, req *GetTrieNodesPacket, quit <-chan struct{}) (*TrieNodesPacket, error) {
+	response := &TrieNodesPacket{ID: req.ID}
+	if req.Root != root {
+		return response, nil
+	}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	start := time.Now()
+
+	// Translate the paths into HEX encoding of the trie, storage ones get account hash and incarnation in front
+	rl := trie.NewRetainList(0)
+	paths := make([][][]byte, 0, len(req.Paths))
+	var loads int
+	for _, pathset := range req.Paths {
+		if loads >= maxTrieNodeLookups {
+			break
+		}
+		switch len(pathset) {
+		case 0:
+			// Ensure we penalize invalid requests
+			return nil, fmt.Errorf("%w: zero-item pathset requested", errBadRequest)
+		case 1:
+			loads++
+			hex := compactToHex(pathset[0])
+			rl.AddHex(hex)
+			paths = append(paths, [][]byte{hex})
+		default:
+			loads++
+			if len(pathset[0]) != common.HashLength {
+				return nil, fmt.Errorf("%w: invalid account hash %x", errBadRequest, pathset[0])
+			}
+			enc, err := tx.GetOne(kv.HashedAccounts, pathset[0])
+			if err != nil {
+				return nil, err
+			}
+			if len(enc) == 0 {
+				paths = append(paths, nil)
+				continue
+			}
+			incarnation, err := accounts.DecodeIncarnationFromStorage(enc)
+			if err != nil {
+				return nil, err
+			}
+			accountHex := keyToHex(pathset[0])
+			retainPrefix := keyToHex(dbutils.GenerateStoragePrefix(pathset[0], incarnation))
+			hexes := make([][]byte, 0, len(pathset)-1)
+			for _, path := range pathset[1:] {
+				loads++
+				hex := compactToHex(path)
+				rl.AddHex(append(common.CopyBytes(retainPrefix), hex...))
+				hexes = append(hexes, append(common.CopyBytes(accountHex), hex...))
+			}
+			paths = append(paths, hexes)
+		}
+	}
+	tr, err := loadTrie(tx, root, rl, quit)
+	if err != nil {
+		return nil, err
+	}
+
+	var size uint64
+	for _, hexes := range paths {
+		for _, hex := range hexes {
+			node, err := tr.NodeRLP(hex)
+			if err != nil {
+				return nil, err
+			}
+			// Nodes shorter than a hash are embedded into their parents, they can't be requested on their own
+			if len(node) < common.HashLength {
+				break
+			}
+			response.Nodes = append(response.Nodes, node)
+			size += uint64(len(node))
+			if size > req.Bytes || time.Since(start) > maxTrieNodeTimeSpent {
+				break
+			}
+		}
+		// Abort request processing if we've exceeded our limits
+		if size > req.Bytes || time.Since(start) > maxTrieNodeTimeSpent {
+			break
+		}
+	}
+	return response, nil
+}
+
+// loadTrie loads the paths of the retain list into the trie from HashedState and IntermediateHashes, all the rest
+// of the trie is hashed
+func loadTrie(tx kv.Tx, root common.Hash, rl *trie.RetainList, quit <-chan struct{}) (*trie.Trie, error) {
+	loader := trie.NewFlatDBTrieLoader("snap")
+	if err := loader.Reset(rl, nil, nil, false); err != nil {
+		return nil, err
+	}
+	subTries, err := loader.CalcSubTrie(tx, quit)
+	if err != nil {
+		return nil, err
+	}
+	if subTries.Hashes[0] != root {
+		return nil, fmt.Errorf("mismatch in expected state root: computed %x, expected %x", subTries.Hashes[0], root)
+	}
+	tr := trie.New(root)
+	if root != trie.EmptyRoot {
+		if err = tr.HookSubTries(subTries, [][]byte{nil}); err != nil {
+			return nil, err
+		}
+	}
+	return tr, nil
+}
+
+// proofSet collects the nodes of several proofs without duplicates
+type proofSet struct {
+	nodes [][]byte
+	seen  map[string]struct{}
+}
+
+func newProofSet() *proofSet {
+	return &proofSet{seen: map[string]struct{}{}}
+}
+
+func (p *proofSet) add(proof [][]byte, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, node := range proof {
+		if _, ok := p.seen[string(node)]; ok {
+			continue
+		}
+		p.seen[string(node)] = struct{}{}
+		p.nodes = append(p.nodes, node)
+	}
+	return nil
+}
+
+// slimAccount is the account encoding of the snap protocol, with empty root and code hash omitted
+type slimAccount struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     []byte
+	CodeHash []byte
+}
+
+func slimAccountRLP(acc *accounts.Account) ([]byte, error) {
+	slim := slimAccount{Nonce: acc.Nonce, Balance: acc.Balance.ToBig()}
+	if acc.Root != trie.EmptyRoot {
+		slim.Root = acc.Root[:]
+	}
+	if acc.CodeHash != trie.EmptyCodeHash {
+		slim.CodeHash = acc.CodeHash[:]
+	}
+	return rlp.EncodeToBytes(slim)
+}
+
+func keyToHex(key []byte) []byte {
+	hex := make([]byte, 2*len(key))
+	for i, b := range key {
+		hex[i*2] = b / 16
+		hex[i*2+1] = b % 16
+	}
+	return hex
+}
+
+func compactToHex(compact []byte) []byte {
+	if len(compact) == 0 {
+		return nil
+	}
+	kb := trie.CompactToKeybytes(compact)
+	return kb.ToHex()
+}
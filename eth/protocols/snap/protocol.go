@@ -0,0 +1,187 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"github.com/ledgerwatch/erigon-lib/direct"
+	proto_sentry "github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	SNAP1 = 1
+)
+
+// ProtocolName is the official short name of the `snap` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "snap"
+
+// ProtocolLength is the number of implemented message corresponding to
+// different protocol versions.
+const ProtocolLength = 8
+
+// maxMessageSize is the maximum cap on the size of a protocol message.
+const maxMessageSize = 10 * 1024 * 1024
+const ProtocolMaxMsgSize = maxMessageSize
+
+const (
+	GetAccountRangeMsg  = 0x00
+	AccountRangeMsg     = 0x01
+	GetStorageRangesMsg = 0x02
+	StorageRangesMsg    = 0x03
+	GetByteCodesMsg     = 0x04
+	ByteCodesMsg        = 0x05
+	GetTrieNodesMsg     = 0x06
+	TrieNodesMsg        = 0x07
+)
+
+// MessageId enum of the sentry interface in the erigon-lib version from go.mod doesn't have the snap messages yet.
+// These are the values they get in the enum, GET_ACCOUNT_RANGE_SNAP1 = 32 to TRIE_NODES_SNAP1 = 39, right after
+// the eth ones: replace them with the generated MessageId_*_SNAP1 constants when erigon-lib is updated. Until then
+// they pass through gRPC as they are, because the enum is open.
+const (
+	MessageIdGetAccountRange  proto_sentry.MessageId = 32
+	MessageIdAccountRange     proto_sentry.MessageId = 33
+	MessageIdGetStorageRanges proto_sentry.MessageId = 34
+	MessageIdStorageRanges    proto_sentry.MessageId = 35
+	MessageIdGetByteCodes     proto_sentry.MessageId = 36
+	MessageIdByteCodes        proto_sentry.MessageId = 37
+	MessageIdGetTrieNodes     proto_sentry.MessageId = 38
+	MessageIdTrieNodes        proto_sentry.MessageId = 39
+)
+
+var ToProto = map[uint]map[uint64]proto_sentry.MessageId{
+	SNAP1: {
+		GetAccountRangeMsg:  MessageIdGetAccountRange,
+		AccountRangeMsg:     MessageIdAccountRange,
+		GetStorageRangesMsg: MessageIdGetStorageRanges,
+		StorageRangesMsg:    MessageIdStorageRanges,
+		GetByteCodesMsg:     MessageIdGetByteCodes,
+		ByteCodesMsg:        MessageIdByteCodes,
+		GetTrieNodesMsg:     MessageIdGetTrieNodes,
+		TrieNodesMsg:        MessageIdTrieNodes,
+	},
+}
+
+var FromProto = map[uint]map[proto_sentry.MessageId]uint64{
+	SNAP1: {
+		MessageIdGetAccountRange:  GetAccountRangeMsg,
+		MessageIdAccountRange:     AccountRangeMsg,
+		MessageIdGetStorageRanges: GetStorageRangesMsg,
+		MessageIdStorageRanges:    StorageRangesMsg,
+		MessageIdGetByteCodes:     GetByteCodesMsg,
+		MessageIdByteCodes:        ByteCodesMsg,
+		MessageIdGetTrieNodes:     GetTrieNodesMsg,
+		MessageIdTrieNodes:        TrieNodesMsg,
+	},
+}
+
+func init() {
+	// Sentry clients drop the ids unknown to their eth protocol from the Messages requests,
+	// snap runs alongside any of them
+	for _, ids := range direct.ProtoIds {
+		for _, id := range ToProto[SNAP1] {
+			ids[id] = struct{}{}
+		}
+	}
+}
+
+// GetAccountRangePacket represents an account query.
+type GetAccountRangePacket struct {
+	ID     uint64      // Request ID to match up responses with
+	Root   common.Hash // Root hash of the account trie to serve
+	Origin common.Hash // Hash of the first account to retrieve
+	Limit  common.Hash // Hash of the last account to retrieve
+	Bytes  uint64      // Soft limit at which to stop returning data
+}
+
+// AccountRangePacket represents an account query response.
+type AccountRangePacket struct {
+	ID       uint64         // ID of the request this is a response for
+	Accounts []*AccountData // List of consecutive accounts from the trie
+	Proof    [][]byte       // List of trie nodes proving the account range
+}
+
+// AccountData represents a single account in a query response.
+type AccountData struct {
+	Hash common.Hash  // Hash of the account
+	Body rlp.RawValue // Account body in slim format
+}
+
+// GetStorageRangesPacket represents an storage slot query.
+type GetStorageRangesPacket struct {
+	ID       uint64        // Request ID to match up responses with
+	Root     common.Hash   // Root hash of the account trie to serve
+	Accounts []common.Hash // Account hashes of the storage tries to serve
+	Origin   []byte        // Hash of the first storage slot to retrieve (large contract mode)
+	Limit    []byte        // Hash of the last storage slot to retrieve (large contract mode)
+	Bytes    uint64        // Soft limit at which to stop returning data
+}
+
+// StorageRangesPacket represents a storage slot query response.
+type StorageRangesPacket struct {
+	ID    uint64           // ID of the request this is a response for
+	Slots [][]*StorageData // Lists of consecutive storage slots for the requested accounts
+	Proof [][]byte         // Merkle proofs for the *last* slot range, if it's incomplete
+}
+
+// StorageData represents a single storage slot in a query response.
+type StorageData struct {
+	Hash common.Hash // Hash of the storage slot
+	Body []byte      // Data content of the slot
+}
+
+// GetByteCodesPacket represents a contract bytecode query.
+type GetByteCodesPacket struct {
+	ID     uint64        // Request ID to match up responses with
+	Hashes []common.Hash // Code hashes to retrieve the code for
+	Bytes  uint64        // Soft limit at which to stop returning data
+}
+
+// ByteCodesPacket represents a contract bytecode query response.
+type ByteCodesPacket struct {
+	ID    uint64   // ID of the request this is a response for
+	Codes [][]byte // Requested contract bytecodes
+}
+
+// GetTrieNodesPacket represents a state trie node query.
+type GetTrieNodesPacket struct {
+	ID    uint64            // Request ID to match up responses with
+	Root  common.Hash       // Root hash of the account trie to serve
+	Paths []TrieNodePathSet // Trie node hashes to retrieve the nodes for
+	Bytes uint64            // Soft limit at which to stop returning data
+}
+
+// TrieNodePathSet is a list of trie node paths to retrieve. A naive way to
+// represent trie nodes would be a simple list of `account || storage` path
+// segments concatenated, but that would be very wasteful on the network.
+//
+// Instead, this array special cases the first element as the path in the
+// account trie and the remaining elements as paths in the storage trie. To
+// address an account node, the slice should have a length of 1 consisting
+// of only the account path. There's no need to be able to address both an
+// account node and a storage node in the same request as it cannot happen
+// that a slot is accessed before the account path is fully expanded.
+type TrieNodePathSet [][]byte
+
+// TrieNodesPacket represents a state trie node query response.
+type TrieNodesPacket struct {
+	ID    uint64   // ID of the request this is a response for
+	Nodes [][]byte // Requested state trie nodes
+}
@@ -0,0 +1,59 @@
+package stagedsync
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+)
+
+// CustomStage - stage added to the staged sync by the app embedding Erigon.
+// It runs right after the stage After, and is unwound and pruned right before it - so the data it reads from
+// the stage After is still there.
+// After can be the ID of any default stage or of a custom stage registered earlier.
+type CustomStage struct {
+	Stage *Stage
+	After stages.SyncStage
+}
+
+// WithCustomStages returns the stages and their unwind and prune orders with the custom stages inserted
+func WithCustomStages(stagesList []*Stage, unwindOrder UnwindOrder, pruneOrder PruneOrder, custom []CustomStage) ([]*Stage, UnwindOrder, PruneOrder, error) {
+	if len(custom) == 0 {
+		return stagesList, unwindOrder, pruneOrder, nil
+	}
+	stagesList = append([]*Stage{}, stagesList...)
+	unwindOrder = append(UnwindOrder{}, unwindOrder...)
+	pruneOrder = append(PruneOrder{}, pruneOrder...)
+	for _, c := range custom {
+		if c.Stage == nil {
+			return nil, nil, nil, fmt.Errorf("custom stage after %s: stage is nil", c.After)
+		}
+		pos := -1
+		for i, s := range stagesList {
+			if s.ID == c.Stage.ID {
+				return nil, nil, nil, fmt.Errorf("custom stage %s: stage with this id already exists", c.Stage.ID)
+			}
+			if s.ID == c.After {
+				pos = i
+			}
+		}
+		if pos < 0 {
+			return nil, nil, nil, fmt.Errorf("custom stage %s: unknown stage %s", c.Stage.ID, c.After)
+		}
+		stagesList = append(stagesList[:pos+1], append([]*Stage{c.Stage}, stagesList[pos+1:]...)...)
+		unwindOrder = insertBefore(unwindOrder, c.After, c.Stage.ID)
+		pruneOrder = insertBefore(pruneOrder, c.After, c.Stage.ID)
+	}
+	return stagesList, unwindOrder, pruneOrder, nil
+}
+
+// insertBefore inserts the stage id before the stage before, or in the beginning if there is no such stage in the order
+func insertBefore(order []stages.SyncStage, before, id stages.SyncStage) []stages.SyncStage {
+	pos := 0
+	for i, s := range order {
+		if s == before {
+			pos = i
+			break
+		}
+	}
+	return append(order[:pos], append([]stages.SyncStage{id}, order[pos:]...)...)
+}
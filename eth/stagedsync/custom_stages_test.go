@@ -0,0 +1,53 @@
+package stagedsync
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomStages(t *testing.T) {
+	var flow []stages.SyncStage
+	stage := func(id stages.SyncStage) *Stage {
+		return &Stage{
+			ID: id,
+			Forward: func(firstCycle bool, badBlockUnwind bool, s *StageState, u Unwinder, tx kv.RwTx) error {
+				flow = append(flow, id)
+				return s.Update(tx, 1)
+			},
+			Unwind: func(firstCycle bool, u *UnwindState, s *StageState, tx kv.RwTx) error {
+				flow = append(flow, "unwind "+id)
+				return u.Done(tx)
+			},
+		}
+	}
+	custom1, custom2 := stages.SyncStage("org.example.Custom1"), stages.SyncStage("org.example.Custom2")
+	stagesList, unwindOrder, pruneOrder, err := WithCustomStages(
+		[]*Stage{stage(stages.Headers), stage(stages.Execution), stage(stages.Finish)},
+		UnwindOrder{stages.Finish, stages.Execution, stages.Headers},
+		PruneOrder{stages.Headers},
+		[]CustomStage{{Stage: stage(custom1), After: stages.Execution}, {Stage: stage(custom2), After: custom1}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, UnwindOrder{stages.Finish, custom2, custom1, stages.Execution, stages.Headers}, unwindOrder)
+	require.Equal(t, PruneOrder{custom2, custom1, stages.Headers}, pruneOrder)
+
+	_, tx := memdb.NewTestTx(t)
+	state := New(stagesList, unwindOrder, pruneOrder)
+	require.NoError(t, state.Run(nil, tx, true))
+	state.UnwindTo(0, common.Hash{})
+	require.NoError(t, state.RunUnwind(nil, tx))
+	require.Equal(t, []stages.SyncStage{
+		stages.Headers, stages.Execution, custom1, custom2, stages.Finish,
+		"unwind " + stages.Finish, "unwind " + custom2, "unwind " + custom1, "unwind " + stages.Execution, "unwind " + stages.Headers,
+	}, flow)
+
+	_, _, _, err = WithCustomStages(stagesList, unwindOrder, pruneOrder, []CustomStage{{Stage: stage("org.example.Custom3"), After: "org.example.Unknown"}})
+	require.ErrorContains(t, err, "unknown stage")
+	_, _, _, err = WithCustomStages(stagesList, unwindOrder, pruneOrder, []CustomStage{{Stage: stage(custom1), After: stages.Headers}})
+	require.ErrorContains(t, err, "already exists")
+}
@@ -8,7 +8,12 @@ import (
 	"github.com/ledgerwatch/erigon/ethdb/prune"
 )
 
-func DefaultStages(ctx context.Context, sm prune.Mode, snapshots SnapshotsCfg, headers HeadersCfg, cumulativeIndex CumulativeIndexCfg, blockHashCfg BlockHashesCfg, bodies BodiesCfg, issuance IssuanceCfg, senders SendersCfg, exec ExecuteBlockCfg, hashState HashStateCfg, trieCfg TrieCfg, history HistoryCfg, logIndex LogIndexCfg, callTraces CallTracesCfg, txLookup TxLookupCfg, finish FinishCfg, test bool) []*Stage {
+func DefaultStages(ctx context.Context, sm prune.Mode, snapshots SnapshotsCfg, headers HeadersCfg, cumulativeIndex CumulativeIndexCfg, blockHashCfg BlockHashesCfg, bodies BodiesCfg, borHeimdall BorHeimdallCfg, issuance IssuanceCfg, senders SendersCfg, exec ExecuteBlockCfg, hashState HashStateCfg, trieCfg TrieCfg, verkleTrie VerkleTrieCfg, receipts ReceiptsCfg, history HistoryCfg, logIndex LogIndexCfg, callTraces CallTracesCfg, txLookup TxLookupCfg, finish FinishCfg, test bool) []*Stage {
+	// Receipts missing in the database, e.g. pruned before, are downloaded instead. They are not pruned anymore
+	downloadReceipts := receipts.enabled && !bodies.historyV2
+	exec.downloadReceipts = downloadReceipts
+	logIndex.downloadReceipts = downloadReceipts
+	receipts.logIndex = logIndex
 	return []*Stage{
 		{
 			ID:          stages.Snapshots,
@@ -81,6 +86,21 @@ func DefaultStages(ctx context.Context, sm prune.Mode, snapshots SnapshotsCfg, h
 				return PruneBodiesStage(p, tx, bodies, ctx)
 			},
 		},
+		{
+			ID:                  stages.BorHeimdall,
+			Description:         "Fetch Heimdall records for Bor blocks",
+			DisabledDescription: "Only for Bor chains with Heimdall",
+			Disabled:            borHeimdall.borDb == nil,
+			Forward: func(firstCycle bool, badBlockUnwind bool, s *StageState, u Unwinder, tx kv.RwTx) error {
+				if badBlockUnwind {
+					return nil
+				}
+				return BorHeimdallForward(s, tx, borHeimdall, ctx)
+			},
+			Unwind: func(firstCycle bool, u *UnwindState, s *StageState, tx kv.RwTx) error {
+				return BorHeimdallUnwind(u, tx, borHeimdall, ctx)
+			},
+		},
 		{
 			ID:          stages.Senders,
 			Description: "Recover senders from tx signatures",
@@ -134,6 +154,39 @@ func DefaultStages(ctx context.Context, sm prune.Mode, snapshots SnapshotsCfg, h
 				return PruneIntermediateHashesStage(p, tx, trieCfg, ctx)
 			},
 		},
+		{
+			ID:                  stages.VerkleTrie,
+			Description:         "Update verkle tree commitment to the state",
+			DisabledDescription: "Experimental, enable with --experimental.verkle-trie",
+			Disabled:            !verkleTrie.enabled || bodies.historyV2,
+			Forward: func(firstCycle bool, badBlockUnwind bool, s *StageState, u Unwinder, tx kv.RwTx) error {
+				return SpawnVerkleTrie(s, tx, verkleTrie, ctx)
+			},
+			Unwind: func(firstCycle bool, u *UnwindState, s *StageState, tx kv.RwTx) error {
+				return UnwindVerkleTrie(u, s, tx, verkleTrie, ctx)
+			},
+			Prune: func(firstCycle bool, p *PruneState, tx kv.RwTx) error {
+				return PruneVerkleTrie(p, tx, verkleTrie, ctx)
+			},
+		},
+		{
+			ID:                  stages.Receipts,
+			Description:         "Download receipts missing in the database",
+			DisabledDescription: "Enable with --sync.receipts",
+			Disabled:            !downloadReceipts,
+			Forward: func(firstCycle bool, badBlockUnwind bool, s *StageState, u Unwinder, tx kv.RwTx) error {
+				if badBlockUnwind {
+					return nil
+				}
+				return SpawnReceiptsStage(s, tx, receipts, ctx, test)
+			},
+			Unwind: func(firstCycle bool, u *UnwindState, s *StageState, tx kv.RwTx) error {
+				return UnwindReceiptsStage(u, tx, receipts, ctx)
+			},
+			Prune: func(firstCycle bool, p *PruneState, tx kv.RwTx) error {
+				return PruneReceiptsStage(p, tx, receipts, ctx)
+			},
+		},
 		{
 			ID:                  stages.CallTraces,
 			Description:         "Generate call traces index",
@@ -315,6 +368,7 @@ var DefaultForwardOrder = UnwindOrder{
 	stages.Headers,
 	stages.BlockHashes,
 	stages.Bodies,
+	stages.BorHeimdall,
 
 	// Stages below don't use Internet
 	stages.Senders,
@@ -322,6 +376,8 @@ var DefaultForwardOrder = UnwindOrder{
 	stages.Translation,
 	stages.HashState,
 	stages.IntermediateHashes,
+	stages.VerkleTrie,
+	stages.Receipts,
 	stages.CallTraces,
 	stages.AccountHistoryIndex,
 	stages.StorageHistoryIndex,
@@ -344,6 +400,9 @@ var DefaultUnwindOrder = UnwindOrder{
 	stages.StorageHistoryIndex,
 	stages.AccountHistoryIndex,
 	stages.CallTraces,
+	stages.Receipts,
+	// Unwinding of VerkleTrie uses the changesets, so it needs to happen before unwinding Execution
+	stages.VerkleTrie,
 
 	// Unwinding of IHashes needs to happen after unwinding HashState
 	stages.HashState,
@@ -352,6 +411,7 @@ var DefaultUnwindOrder = UnwindOrder{
 	stages.Translation,
 	stages.Execution,
 	stages.Senders,
+	stages.BorHeimdall,
 
 	stages.Bodies,
 	stages.BlockHashes,
@@ -377,6 +437,8 @@ var DefaultPruneOrder = PruneOrder{
 	stages.StorageHistoryIndex,
 	stages.AccountHistoryIndex,
 	stages.CallTraces,
+	stages.Receipts,
+	stages.VerkleTrie,
 
 	// Unwinding of IHashes needs to happen after unwinding HashState
 	stages.HashState,
@@ -193,7 +193,7 @@ Loop:
 		for i, header := range headers {
 			rawBody := rawBodies[i]
 			blockHeight := header.Number.Uint64()
-			// Txn & uncle roots are verified via bd.requestedMap
+			// Txn, uncle & withdrawal roots are verified via bd.requestedMap
 			err := cfg.bd.Engine.VerifyUncles(cr, header, rawBody.Uncles)
 			if err != nil {
 				log.Error(fmt.Sprintf("[%s] Uncle verification failed", logPrefix), "number", blockHeight, "hash", header.Hash().String(), "err", err)
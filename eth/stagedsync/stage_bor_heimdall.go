@@ -0,0 +1,162 @@
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/consensus/bor"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/log/v3"
+)
+
+type BorHeimdallCfg struct {
+	db             kv.RwDB
+	borDb          kv.RwDB
+	chainConfig    *params.ChainConfig
+	heimdallClient bor.IHeimdallClient
+	blockReader    services.FullBlockReader
+}
+
+// StageBorHeimdallCfg - the stage is disabled if borDb is nil
+func StageBorHeimdallCfg(
+	db kv.RwDB,
+	borDb kv.RwDB,
+	chainConfig *params.ChainConfig,
+	heimdallClient bor.IHeimdallClient,
+	blockReader services.FullBlockReader,
+) BorHeimdallCfg {
+	return BorHeimdallCfg{db: db, borDb: borDb, chainConfig: chainConfig, heimdallClient: heimdallClient, blockReader: blockReader}
+}
+
+// BorHeimdallForward saves to the bor DB the spans and state sync events needed to execute the downloaded headers,
+// so Execution doesn't wait for Heimdall
+func BorHeimdallForward(s *StageState, tx kv.RwTx, cfg BorHeimdallCfg, ctx context.Context) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		// tx is replaced by the batch commits
+		defer func() { tx.Rollback() }()
+	}
+
+	headNumber, err := stages.GetStageProgress(tx, stages.Headers)
+	if err != nil {
+		return fmt.Errorf("getting headers progress: %w", err)
+	}
+	if s.BlockNumber >= headNumber {
+		return nil
+	}
+	logPrefix := s.LogPrefix()
+	if headNumber > s.BlockNumber+16 {
+		log.Info(fmt.Sprintf("[%s] Fetching Heimdall records", logPrefix), "from", s.BlockNumber+1, "to", headNumber)
+	}
+	logEvery := time.NewTicker(logInterval)
+	defer logEvery.Stop()
+
+	headerTime := func(blockNum uint64) (uint64, error) {
+		header, err := cfg.blockReader.HeaderByNumber(ctx, tx, blockNum)
+		if err != nil {
+			return 0, err
+		}
+		if header == nil {
+			return 0, fmt.Errorf("header %d not found", blockNum)
+		}
+		return header.Time, nil
+	}
+	borTx, err := cfg.borDb.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { borTx.Rollback() }()
+	lastSpan, err := bor.LastSpan(borTx)
+	if err != nil {
+		return err
+	}
+	for blockNum := s.BlockNumber + 1; blockNum <= headNumber; blockNum++ {
+		if err = bor.FetchHeimdallRecords(ctx, borTx, cfg.heimdallClient, cfg.chainConfig.Bor, blockNum, headerTime); err != nil {
+			return fmt.Errorf("block %d: %w", blockNum, err)
+		}
+		span, err := bor.LastSpan(borTx)
+		if err != nil {
+			return err
+		}
+		// The records are committed in batches, each fetched span or on logEvery, so a restart doesn't fetch them again
+		commit := lastSpan == nil || span.ID != lastSpan.ID
+		lastSpan = span
+		select {
+		case <-ctx.Done():
+			return libcommon.ErrStopped
+		case <-logEvery.C:
+			log.Info(fmt.Sprintf("[%s] Progress", logPrefix), "block", blockNum)
+			commit = true
+		default:
+		}
+		if !commit || blockNum == headNumber {
+			continue
+		}
+		if err = borTx.Commit(); err != nil {
+			return err
+		}
+		if err = s.Update(tx, blockNum); err != nil {
+			return err
+		}
+		if !useExternalTx {
+			if err = tx.Commit(); err != nil {
+				return err
+			}
+			if tx, err = cfg.db.BeginRw(ctx); err != nil {
+				return err
+			}
+		}
+		if borTx, err = cfg.borDb.BeginRw(ctx); err != nil {
+			return err
+		}
+	}
+	if err = borTx.Commit(); err != nil {
+		return err
+	}
+	if err = s.Update(tx, headNumber); err != nil {
+		return err
+	}
+
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func BorHeimdallUnwind(u *UnwindState, tx kv.RwTx, cfg BorHeimdallCfg, ctx context.Context) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+
+	if err = cfg.borDb.Update(ctx, func(borTx kv.RwTx) error {
+		return bor.UnwindHeimdallRecords(borTx, u.UnwindPoint)
+	}); err != nil {
+		return err
+	}
+	if err = u.Done(tx); err != nil {
+		return err
+	}
+
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package stagedsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/consensus/bor"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/stretchr/testify/require"
+)
+
+// testHeimdallClient serves spans of 64 blocks up to maxSpan and an event every 10 seconds
+type testHeimdallClient struct {
+	maxSpan    uint64
+	spanCalls  map[uint64]int
+	eventCalls int
+}
+
+func (h *testHeimdallClient) Fetch(_ context.Context, path string, _ string) (*bor.ResponseWithHeight, error) {
+	var spanID uint64
+	if _, err := fmt.Sscanf(path, "bor/span/%d", &spanID); err != nil {
+		return nil, err
+	}
+	if spanID > h.maxSpan {
+		return nil, errors.New("heimdall unavailable")
+	}
+	h.spanCalls[spanID]++
+	result, err := json.Marshal(&bor.HeimdallSpan{Span: bor.Span{ID: spanID, StartBlock: spanID * 64, EndBlock: spanID*64 + 63}, ChainID: "80001"})
+	if err != nil {
+		return nil, err
+	}
+	return &bor.ResponseWithHeight{Height: "1", Result: result}, nil
+}
+
+func (h *testHeimdallClient) FetchWithRetry(ctx context.Context, path string, query string) (*bor.ResponseWithHeight, error) {
+	return h.Fetch(ctx, path, query)
+}
+
+func (h *testHeimdallClient) FetchStateSyncEvents(_ context.Context, fromID uint64, to int64) ([]*bor.EventRecordWithTime, error) {
+	h.eventCalls++
+	var events []*bor.EventRecordWithTime
+	for id := fromID; int64(id*10) < to; id++ {
+		events = append(events, &bor.EventRecordWithTime{EventRecord: bor.EventRecord{ID: id, ChainID: "80001"}, Time: time.Unix(int64(id*10), 0).UTC()})
+	}
+	return events, nil
+}
+
+func TestBorHeimdallForwardResume(t *testing.T) {
+	ctx := context.Background()
+	db, borDb := memdb.New(), memdb.New()
+	defer db.Close()
+	defer borDb.Close()
+
+	// the block n has the time n
+	const headNumber = 300
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		for blockNum := uint64(0); blockNum <= headNumber; blockNum++ {
+			header := &types.Header{Number: new(big.Int).SetUint64(blockNum), Time: blockNum, Difficulty: big.NewInt(1)}
+			rawdb.WriteHeader(tx, header)
+			if err := rawdb.WriteCanonicalHash(tx, header.Hash(), blockNum); err != nil {
+				return err
+			}
+		}
+		return stages.SaveStageProgress(tx, stages.Headers, headNumber)
+	}))
+
+	client := &testHeimdallClient{maxSpan: 2, spanCalls: map[uint64]int{}}
+	chainConfig := &params.ChainConfig{Bor: &params.BorConfig{Sprint: 16}}
+	cfg := StageBorHeimdallCfg(db, borDb, chainConfig, client, snapshotsync.NewBlockReader())
+	progress := func() uint64 {
+		var blockNum uint64
+		require.NoError(t, db.View(ctx, func(tx kv.Tx) (err error) {
+			blockNum, err = stages.GetStageProgress(tx, stages.BorHeimdall)
+			return err
+		}))
+		return blockNum
+	}
+
+	// Heimdall fails when the stage needs the span 3, at the block 176 (the last sprint of the span 2)
+	err := BorHeimdallForward(&StageState{ID: stages.BorHeimdall, BlockNumber: progress()}, nil, cfg, ctx)
+	require.Error(t, err)
+	// the batch of the span 2 is committed
+	require.Equal(t, uint64(112), progress())
+	eventCalls := client.eventCalls
+
+	client.maxSpan = 4
+	require.NoError(t, BorHeimdallForward(&StageState{ID: stages.BorHeimdall, BlockNumber: progress()}, nil, cfg, ctx))
+	require.Equal(t, uint64(headNumber), progress())
+	// the second run continues from the committed block, the events of the sprints 128, 144 and 160 are fetched again
+	require.Equal(t, map[uint64]int{0: 1, 1: 1, 2: 1, 3: 1, 4: 1}, client.spanCalls)
+	require.Equal(t, eventCalls+(288-112)/16, client.eventCalls)
+
+	require.NoError(t, borDb.View(ctx, func(tx kv.Tx) error {
+		span, err := bor.LastSpan(tx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), span.ID)
+		lastEventID, err := bor.LastEventID(tx)
+		require.NoError(t, err)
+		// the block 288 commits the events before the time of the block 272
+		require.Equal(t, uint64(27), lastEventID)
+		return nil
+	}))
+}
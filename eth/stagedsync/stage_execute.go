@@ -84,6 +84,8 @@ type ExecuteBlockCfg struct {
 	genesis      *core.Genesis
 	agg          *libstate.Aggregator22
 	txNums       *exec22.TxNums
+
+	downloadReceipts bool // Receipts stage downloads the receipts not written here, they are not pruned
 }
 
 func StageExecuteBlocksCfg(
@@ -182,7 +184,13 @@ func executeBlock(
 	stateSyncReceipt = execRs.ReceiptForStorage
 
 	if writeReceipts {
-		if err = rawdb.AppendReceipts(tx, blockNum, receipts); err != nil {
+		if cfg.downloadReceipts {
+			// Receipts stage may have downloaded them already, ahead of the execution
+			err = rawdb.WriteReceipts(tx, blockNum, receipts)
+		} else {
+			err = rawdb.AppendReceipts(tx, blockNum, receipts)
+		}
+		if err != nil {
 			return err
 		}
 
@@ -460,9 +468,14 @@ Loop:
 
 		// Incremental move of next stages depend on fully written ChangeSets, Receipts, CallTraceSet
 		writeChangeSets := nextStagesExpectData || blockNum > cfg.prune.History.PruneTo(to)
-		writeReceipts := nextStagesExpectData || blockNum > cfg.prune.Receipts.PruneTo(to)
+		// Receipts are not pruned when they are downloaded, so they are always written
+		writeReceipts := nextStagesExpectData || cfg.downloadReceipts || blockNum > cfg.prune.Receipts.PruneTo(to)
 		writeCallTraces := nextStagesExpectData || blockNum > cfg.prune.CallTraces.PruneTo(to)
 		if err = executeBlock(block, tx, batch, cfg, *cfg.vmConfig, writeChangeSets, writeReceipts, writeCallTraces, initialCycle, effectiveEngine); err != nil {
+			var runnerErr *vm.ErrCairoRunner
+			if errors.As(err, &runnerErr) { // the block is not bad, it's executed again in the next cycle
+				return err
+			}
 			if !errors.Is(err, context.Canceled) {
 				log.Warn(fmt.Sprintf("[%s] Execution failed", logPrefix), "block", blockNum, "hash", block.Hash().String(), "err", err)
 				if cfg.hd != nil {
@@ -771,7 +784,7 @@ func PruneExecutionStage(s *PruneState, tx kv.RwTx, cfg ExecuteBlockCfg, ctx con
 		}
 	}
 
-	if cfg.prune.Receipts.Enabled() {
+	if cfg.prune.Receipts.Enabled() && !cfg.downloadReceipts {
 		if err = rawdb.PruneTable(tx, kv.Receipts, cfg.prune.Receipts.PruneTo(s.ForwardProgress), ctx, math.MaxInt32); err != nil {
 			return err
 		}
@@ -675,6 +675,19 @@ func unwindIntermediateHashesStageImpl(logPrefix string, u *UnwindState, s *Stag
 	return nil
 }
 
+// RewindHashedStateAndIntermediateHashes moves HashedState and IntermediateHashes of `tx` back from block `from` to block `to`
+// by using ChangeSets. Stage progress is not updated - it's intended to be used on throw-away batches,
+// for example to calculate Merkle proofs against historical state.
+func RewindHashedStateAndIntermediateHashes(logPrefix string, tx kv.RwTx, from, to uint64, hashStateCfg HashStateCfg, trieCfg TrieCfg, expectedRootHash common.Hash, quit <-chan struct{}) error {
+	s := &StageState{ID: stages.HashState, BlockNumber: from}
+	u := &UnwindState{ID: stages.HashState, UnwindPoint: to, CurrentBlockNumber: from}
+	if err := unwindHashStateStageImpl(logPrefix, u, s, tx, hashStateCfg, quit); err != nil {
+		return err
+	}
+	s.ID, u.ID = stages.IntermediateHashes, stages.IntermediateHashes
+	return unwindIntermediateHashesStageImpl(logPrefix, u, s, tx, trieCfg, expectedRootHash, quit)
+}
+
 func ResetHashState(tx kv.RwTx) error {
 	if err := tx.ClearBucket(kv.HashedAccounts); err != nil {
 		return err
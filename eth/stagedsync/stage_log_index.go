@@ -33,6 +33,8 @@ type LogIndexCfg struct {
 	prune      prune.Mode
 	bufLimit   datasize.ByteSize
 	flushEvery time.Duration
+
+	downloadReceipts bool // Receipts are not pruned, the logs downloaded below the progress of the stage are indexed by the Receipts stage
 }
 
 func StageLogIndexCfg(db kv.RwDB, prune prune.Mode, tmpDir string) LogIndexCfg {
@@ -75,7 +77,7 @@ func SpawnLogIndex(s *StageState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Conte
 
 	startBlock := s.BlockNumber
 	pruneTo := cfg.prune.Receipts.PruneTo(endBlock)
-	if startBlock < pruneTo {
+	if startBlock < pruneTo && !cfg.downloadReceipts {
 		startBlock = pruneTo
 	}
 	if startBlock > 0 {
@@ -384,7 +386,7 @@ func pruneOldLogChunks(tx kv.RwTx, bucket string, inMem *etl.Collector, pruneTo
 }
 
 func PruneLogIndex(s *PruneState, tx kv.RwTx, cfg LogIndexCfg, ctx context.Context) (err error) {
-	if !cfg.prune.Receipts.Enabled() {
+	if !cfg.prune.Receipts.Enabled() || cfg.downloadReceipts {
 		return nil
 	}
 	logPrefix := s.LogPrefix()
@@ -12,6 +12,7 @@ import (
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
 	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
 	"github.com/ledgerwatch/erigon/ethdb/prune"
 
@@ -188,3 +189,33 @@ func TestUnwindLogIndex(t *testing.T) {
 		require.True(m.Maximum() <= 700)
 	}
 }
+
+func TestSpawnLogIndexDownloadedReceipts(t *testing.T) {
+	require, ctx := require.New(t), context.Background()
+	_, tx := memdb.NewTestTx(t)
+
+	expectAddrs, expectTopics := genReceipts(t, tx, 100)
+	require.NoError(stages.SaveStageProgress(tx, stages.Execution, 100))
+
+	cfg := StageLogIndexCfg(nil, prune.DefaultMode, t.TempDir())
+	cfg.downloadReceipts = true
+	// Logs are indexed up to the executed block, regardless of the backfill progress of the Receipts stage
+	s := &StageState{ID: stages.LogIndex}
+	require.NoError(SpawnLogIndex(s, tx, cfg, ctx, 0))
+	progress, err := stages.GetStageProgress(tx, stages.LogIndex)
+	require.NoError(err)
+	require.Equal(uint64(100), progress)
+
+	// The Receipts stage indexes the logs it downloads below the progress again, the bitmaps are merged
+	require.NoError(promoteLogIndex("logPrefix", tx, 10, 60, cfg, ctx))
+	for addr, expect := range expectAddrs {
+		m, err := bitmapdb.Get(tx, kv.LogAddressIndex, addr[:], 0, 10_000_000)
+		require.NoError(err)
+		require.Equal(expect, m.GetCardinality())
+	}
+	for topic, expect := range expectTopics {
+		m, err := bitmapdb.Get(tx, kv.LogTopicIndex, topic[:], 0, 10_000_000)
+		require.NoError(err)
+		require.Equal(expect, m.GetCardinality())
+	}
+}
@@ -1,6 +1,7 @@
 package stagedsync
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync/atomic"
@@ -59,6 +60,47 @@ func StageMiningExecCfg(
 	}
 }
 
+// miningEpochReader looks up the epochs in the database, because the mining stages run on top of a memory
+// batch, which cursors can't move backwards. The epochs written by the engine while mining the block, like the
+// genesis epoch written when the first block is initialised, go to the batch and are remembered here.
+type miningEpochReader struct {
+	epochReader
+	db      kv.RoDB
+	written map[uint64]miningEpoch
+}
+
+type miningEpoch struct {
+	hash            common.Hash
+	transitionProof []byte
+}
+
+func newMiningEpochReader(tx kv.RwTx, db kv.RoDB) miningEpochReader {
+	return miningEpochReader{epochReader: epochReader{tx: tx}, db: db, written: map[uint64]miningEpoch{}}
+}
+
+func (cr miningEpochReader) PutEpoch(hash common.Hash, number uint64, proof []byte) error {
+	if err := cr.epochReader.PutEpoch(hash, number, proof); err != nil {
+		return err
+	}
+	cr.written[number] = miningEpoch{hash: hash, transitionProof: proof}
+	return nil
+}
+
+func (cr miningEpochReader) FindBeforeOrEqualNumber(number uint64) (blockNum uint64, blockHash common.Hash, transitionProof []byte, err error) {
+	if err = cr.db.View(context.Background(), func(tx kv.Tx) error {
+		blockNum, blockHash, transitionProof, err = rawdb.FindEpochBeforeOrEqualNumber(tx, number)
+		return err
+	}); err != nil {
+		return 0, common.Hash{}, nil, err
+	}
+	for num, epoch := range cr.written {
+		if num <= number && (transitionProof == nil || num >= blockNum) {
+			blockNum, blockHash, transitionProof = num, epoch.hash, epoch.transitionProof
+		}
+	}
+	return blockNum, blockHash, transitionProof, nil
+}
+
 // SpawnMiningExecStage
 // TODO:
 // - resubmitAdjustCh - variable is not implemented
@@ -77,6 +119,10 @@ func SpawnMiningExecStage(s *StageState, tx kv.RwTx, cfg MiningExecCfg, quit <-c
 		misc.ApplyDAOHardFork(ibs)
 	}
 	systemcontracts.UpgradeBuildInSystemContract(&cfg.chainConfig, current.Header.Number, ibs)
+	epochs := newMiningEpochReader(tx, cfg.db)
+	if err := core.InitializeBlockExecution(cfg.engine, chainReader{config: &cfg.chainConfig, tx: tx, blockReader: cfg.blockReader}, epochs, current.Header, nil, current.Uncles, &cfg.chainConfig, ibs); err != nil {
+		return err
+	}
 
 	// Create an empty block based on temporary copied state for
 	// sealing in advance without waiting block execution finished.
@@ -129,8 +175,8 @@ func SpawnMiningExecStage(s *StageState, tx kv.RwTx, cfg MiningExecCfg, quit <-c
 	}
 
 	var err error
-	_, current.Txs, current.Receipts, err = core.FinalizeBlockExecution(cfg.engine, stateReader, current.Header, current.Txs, current.Uncles, stateWriter,
-		&cfg.chainConfig, ibs, current.Receipts, epochReader{tx: tx}, chainReader{config: &cfg.chainConfig, tx: tx, blockReader: cfg.blockReader}, true)
+	_, current.Txs, current.Receipts, err = core.FinalizeBlockExecution(cfg.engine, stateReader, current.Header, current.Txs, current.Uncles, current.Withdrawals, stateWriter,
+		&cfg.chainConfig, ibs, current.Receipts, epochs, chainReader{config: &cfg.chainConfig, tx: tx, blockReader: cfg.blockReader}, true)
 	if err != nil {
 		return err
 	}
@@ -192,6 +238,9 @@ func addTransactionsToMiningBlock(logPrefix string, current *MiningBlock, chainC
 			return nil, err
 		}
 
+		if header.BlobGasUsed != nil {
+			*header.BlobGasUsed += txn.GetBlobGas()
+		}
 		current.Txs = append(current.Txs, txn)
 		current.Receipts = append(current.Receipts, receipt)
 		return receipt.Logs, nil
@@ -234,6 +283,15 @@ func addTransactionsToMiningBlock(logPrefix string, current *MiningBlock, chainC
 			continue
 		}
 
+		// Skip blob transactions which do not fit into the remaining blob gas of the block (EIP-4844)
+		if blobGas := txn.GetBlobGas(); blobGas > 0 {
+			if header.BlobGasUsed == nil || *header.BlobGasUsed+blobGas > params.MaxBlobGasPerBlock {
+				log.Debug(fmt.Sprintf("[%s] Blob gas limit exceeded for env block", logPrefix), "hash", txn.Hash(), "sender", from)
+				txs.Pop()
+				continue
+			}
+		}
+
 		// Start executing the transaction
 		logs, err := miningCommitTx(txn, coinbase, vmConfig, chainConfig, ibs, current)
 
@@ -0,0 +1,253 @@
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/ledgerwatch/erigon/turbo/stages/receiptdownload"
+	"github.com/ledgerwatch/log/v3"
+)
+
+type ReceiptsCfg struct {
+	db             kv.RwDB
+	rd             *receiptdownload.ReceiptDownload
+	receiptReqSend func(context.Context, *receiptdownload.ReceiptRequest) ([64]byte, bool)
+	timeout        int
+	snapshots      *snapshotsync.RoSnapshots
+	blockReader    services.FullBlockReader
+	enabled        bool
+
+	logIndex LogIndexCfg // Logs of the receipts downloaded below the progress of LogIndex stage are indexed here
+}
+
+func StageReceiptsCfg(
+	db kv.RwDB,
+	rd *receiptdownload.ReceiptDownload,
+	receiptReqSend func(context.Context, *receiptdownload.ReceiptRequest) ([64]byte, bool),
+	timeout int,
+	snapshots *snapshotsync.RoSnapshots,
+	blockReader services.FullBlockReader,
+	enabled bool,
+) ReceiptsCfg {
+	return ReceiptsCfg{db: db, rd: rd, receiptReqSend: receiptReqSend, timeout: timeout, snapshots: snapshots, blockReader: blockReader, enabled: enabled}
+}
+
+// SpawnReceiptsStage downloads from peers the receipts of the blocks which don't have them in the database, e.g. the
+// ones executed with the receipts pruned. Root of the received receipts is verified against ReceiptHash of the header,
+// so the blocks don't need to be executed, the download is bounded by the progress of Bodies stage
+func SpawnReceiptsStage(s *StageState, tx kv.RwTx, cfg ReceiptsCfg, ctx context.Context, test bool) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+
+	to, err := stages.GetStageProgress(tx, stages.Bodies)
+	if err != nil {
+		return err
+	}
+	logIndexProgress, err := stages.GetStageProgress(tx, stages.LogIndex)
+	if err != nil {
+		return err
+	}
+	progress := s.BlockNumber
+	// Receipts of the blocks frozen in snapshots are read from there, they are not downloaded
+	if cfg.snapshots != nil && progress < cfg.snapshots.ReceiptsAvailable() {
+		progress = cfg.snapshots.ReceiptsAvailable()
+	}
+	if progress >= to {
+		return nil
+	}
+	if progress > s.BlockNumber {
+		// The download starts after the saved progress
+		if err = s.Update(tx, progress); err != nil {
+			return fmt.Errorf("saving Receipts progress: %w", err)
+		}
+	}
+	if err = cfg.rd.UpdateFromDb(tx); err != nil {
+		return err
+	}
+
+	logPrefix := s.LogPrefix()
+	timeout := cfg.timeout
+	if to <= progress+16 {
+		// When processing small number of blocks, we can afford wasting more bandwidth but get receipts quicker
+		timeout = 1
+	} else {
+		log.Info(fmt.Sprintf("[%s] Processing receipts...", logPrefix), "from", progress, "to", to)
+	}
+	logEvery := time.NewTicker(logInterval)
+	defer logEvery.Stop()
+
+	var prevDeliveredCount, prevWastedCount float64
+	timer := time.NewTimer(1 * time.Second) // Check periodically even in the abseence of incoming messages
+	defer timer.Stop()
+	var blockNum uint64
+	stopped := false
+	prevProgress := progress
+	noProgressCount := 0 // How many time the progress was printed without actual progress
+	// Range of the written receipts which logs have to be indexed, LogIndex stage has passed them already
+	var indexFrom, indexTo uint64
+	for !stopped {
+		for {
+			currentTime := uint64(time.Now().Unix())
+			var req *receiptdownload.ReceiptRequest
+			req, blockNum, err = cfg.rd.RequestMoreReceipts(tx, cfg.blockReader, blockNum, currentTime)
+			if err != nil {
+				return fmt.Errorf("request more receipts: %w", err)
+			}
+			if req == nil {
+				break
+			}
+			peer, sentToPeer := cfg.receiptReqSend(ctx, req)
+			if !sentToPeer {
+				break
+			}
+			cfg.rd.RequestSent(req, currentTime+uint64(timeout), peer)
+		}
+
+		from, receipts := cfg.rd.GetDeliveries()
+		for i, blockReceipts := range receipts {
+			// nil receipts are the ones already present in the database
+			if blockReceipts != nil {
+				if err = rawdb.WriteReceipts(tx, from+uint64(i), blockReceipts); err != nil {
+					return err
+				}
+				if n := from + uint64(i); n <= logIndexProgress {
+					if indexFrom == 0 {
+						indexFrom = n
+					}
+					indexTo = n
+				}
+			}
+			progress = from + uint64(i)
+		}
+		if len(receipts) > 0 {
+			if err = s.Update(tx, progress); err != nil {
+				return fmt.Errorf("saving Receipts progress: %w", err)
+			}
+		}
+		if progress >= to {
+			break
+		}
+		if test {
+			stopped = true
+			break
+		}
+		// Peers are not obliged to keep old receipts, the rest is downloaded in the next cycles
+		if noProgressCount >= 5 {
+			break
+		}
+		timer.Reset(1 * time.Second)
+		select {
+		case <-ctx.Done():
+			stopped = true
+		case <-logEvery.C:
+			deliveredCount, wastedCount := cfg.rd.DeliveryCounts()
+			if prevProgress == progress {
+				noProgressCount++
+			} else {
+				noProgressCount = 0 // Reset, there was progress
+			}
+			logProgressReceipts(logPrefix, progress, prevDeliveredCount, deliveredCount, prevWastedCount, wastedCount)
+			prevProgress = progress
+			prevDeliveredCount = deliveredCount
+			prevWastedCount = wastedCount
+		case <-timer.C:
+			log.Trace("RequestQueueTime (receipts) ticked")
+		case <-cfg.rd.DeliveryNotify:
+			log.Trace("receiptLoop woken up by the incoming request")
+		}
+	}
+	if indexTo > 0 {
+		// Blocks in the range which already had the receipts are indexed again, the index bitmaps are merged
+		if err = promoteLogIndex(logPrefix, tx, indexFrom, indexTo, cfg.logIndex, ctx); err != nil {
+			return err
+		}
+	}
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	if stopped {
+		return libcommon.ErrStopped
+	}
+	if progress > s.BlockNumber+16 {
+		log.Info(fmt.Sprintf("[%s] Processed", logPrefix), "highest", progress)
+	}
+	return nil
+}
+
+func logProgressReceipts(logPrefix string, committed uint64, prevDeliveredCount, deliveredCount, prevWastedCount, wastedCount float64) {
+	speed := (deliveredCount - prevDeliveredCount) / float64(logInterval/time.Second)
+	wastedSpeed := (wastedCount - prevWastedCount) / float64(logInterval/time.Second)
+	if speed == 0 && wastedSpeed == 0 {
+		return
+	}
+
+	var m runtime.MemStats
+	libcommon.ReadMemStats(&m)
+	log.Info(fmt.Sprintf("[%s] Wrote receipts", logPrefix),
+		"block_num", committed,
+		"delivery/sec", libcommon.ByteCount(uint64(speed)),
+		"wasted/sec", libcommon.ByteCount(uint64(wastedSpeed)),
+		"alloc", libcommon.ByteCount(m.Alloc),
+		"sys", libcommon.ByteCount(m.Sys),
+	)
+}
+
+// UnwindReceiptsStage only moves the progress back, receipts of the unwound blocks are truncated by the Execution stage
+func UnwindReceiptsStage(u *UnwindState, tx kv.RwTx, cfg ReceiptsCfg, ctx context.Context) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+	if err = u.Done(tx); err != nil {
+		return err
+	}
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneReceiptsStage has nothing to prune, the receipts are downloaded to be kept: Execution stage doesn't prune them
+// either when the download is enabled
+func PruneReceiptsStage(s *PruneState, tx kv.RwTx, cfg ReceiptsCfg, ctx context.Context) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+
+	if err = s.Done(tx); err != nil {
+		return err
+	}
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/cmd/verkle/verkletrie"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/log/v3"
+)
+
+type VerkleTrieCfg struct {
+	db      kv.RwDB
+	enabled bool
+	tmpDir  string
+	workers uint64
+}
+
+func StageVerkleTrieCfg(db kv.RwDB, enabled bool, tmpDir string) VerkleTrieCfg {
+	return VerkleTrieCfg{
+		db:      db,
+		enabled: enabled,
+		tmpDir:  tmpDir,
+		workers: uint64(runtime.NumCPU()),
+	}
+}
+
+// SpawnVerkleTrie maintains a verkle tree commitment to the state alongside the MPT. The tree is built from
+// PlainState on the first run and then updated with the accounts and storage changed in the executed blocks.
+func SpawnVerkleTrie(s *StageState, tx kv.RwTx, cfg VerkleTrieCfg, ctx context.Context) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+	logPrefix := s.LogPrefix()
+	to, err := s.ExecutionAt(tx)
+	if err != nil {
+		return err
+	}
+	if s.BlockNumber == to {
+		return nil
+	}
+	if s.BlockNumber > to {
+		return fmt.Errorf("verkle trie: update backwards from %d to %d", s.BlockNumber, to)
+	}
+	if to > s.BlockNumber+16 {
+		log.Info(fmt.Sprintf("[%s] Updating verkle tree", logPrefix), "from", s.BlockNumber, "to", to)
+	}
+
+	verkleWriter := verkletrie.NewVerkleTreeWriter(tx, cfg.tmpDir)
+	defer verkleWriter.Close()
+	var root common.Hash
+	if s.BlockNumber == 0 {
+		if err = verkletrie.ClearDB(tx); err != nil {
+			return err
+		}
+		if root, err = verkletrie.RegenerateVerkleTree(tx, tx, cfg.workers, false /* disabledLookups */, verkleWriter); err != nil {
+			return err
+		}
+	} else {
+		if err = verkletrie.IncrementAccount(tx, tx, cfg.workers, verkleWriter, s.BlockNumber+1, to); err != nil {
+			return err
+		}
+		if err = verkletrie.IncrementStorage(tx, tx, cfg.workers, verkleWriter, s.BlockNumber+1, to); err != nil {
+			return err
+		}
+		prevRoot, err := verkletrie.ReadVerkleRoot(tx, s.BlockNumber)
+		if err != nil {
+			return err
+		}
+		if root, err = verkleWriter.CommitVerkleTree(prevRoot); err != nil {
+			return err
+		}
+	}
+	if err = verkletrie.WriteVerkleRoot(tx, to, root); err != nil {
+		return err
+	}
+	if to > s.BlockNumber+16 {
+		log.Info(fmt.Sprintf("[%s] Verkle tree updated", logPrefix), "block", to, "root", root)
+	}
+
+	if err = s.Update(tx, to); err != nil {
+		return err
+	}
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnwindVerkleTrie brings the tree back to the one of the unwind point. The nodes are never deleted, so that
+// tree is still there if its root was written. The accounts and storage changed after the unwind point are
+// taken from the changesets for the incarnations and the lookups, so it has to run before the execution is
+// unwound. If there is no root at the unwind point, the tree is built again from scratch by the next forward.
+func UnwindVerkleTrie(u *UnwindState, s *StageState, tx kv.RwTx, cfg VerkleTrieCfg, ctx context.Context) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+
+	root, err := verkletrie.ReadVerkleRoot(tx, u.UnwindPoint)
+	if err != nil {
+		return err
+	}
+	if root == (common.Hash{}) {
+		log.Info(fmt.Sprintf("[%s] No verkle root at the unwind point, the tree will be built again", s.LogPrefix()), "block", u.UnwindPoint)
+		if err = verkletrie.ClearDB(tx); err != nil {
+			return err
+		}
+		if err = stages.SaveStageProgress(tx, stages.VerkleTrie, 0); err != nil {
+			return err
+		}
+	} else {
+		// Updating the tree with the old values would leave the keys deleted by the unwind as zero values,
+		// only the incarnations and the lookups written on the way are kept
+		verkleWriter := verkletrie.NewVerkleTreeWriter(tx, cfg.tmpDir)
+		defer verkleWriter.Close()
+		if err = verkletrie.UnwindAccount(tx, tx, cfg.workers, verkleWriter, u.UnwindPoint+1, s.BlockNumber); err != nil {
+			return err
+		}
+		if err = verkletrie.UnwindStorage(tx, tx, cfg.workers, verkleWriter, u.UnwindPoint+1, s.BlockNumber); err != nil {
+			return err
+		}
+		if err = verkletrie.TruncateVerkleRoots(tx, u.UnwindPoint+1); err != nil {
+			return err
+		}
+		if err = u.Done(tx); err != nil {
+			return err
+		}
+	}
+
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func PruneVerkleTrie(s *PruneState, tx kv.RwTx, cfg VerkleTrieCfg, ctx context.Context) (err error) {
+	useExternalTx := tx != nil
+	if !useExternalTx {
+		tx, err = cfg.db.BeginRw(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+	if err = s.Done(tx); err != nil {
+		return err
+	}
+	if !useExternalTx {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
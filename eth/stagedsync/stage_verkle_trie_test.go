@@ -0,0 +1,87 @@
+//go:build integration
+
+// Computing the verkle configuration on the first run takes minutes and stores the precomputed points
+// in the working directory, so the test is not among the unit tests
+
+package stagedsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/cmd/verkle/verkletrie"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/stretchr/testify/require"
+)
+
+// regenerateVerkleRoot builds the verkle tree of the state in tx from scratch in a separate database
+func regenerateVerkleRoot(t *testing.T, tx kv.Tx) common.Hash {
+	_, outTx := memdb.NewTestTx(t)
+	require.NoError(t, verkletrie.InitDB(outTx))
+	root, err := verkletrie.RegenerateVerkleTree(outTx, tx, 2, false, verkletrie.NewVerkleTreeWriter(outTx, t.TempDir()))
+	require.NoError(t, err)
+	return root
+}
+
+func TestVerkleTrieForwardAndUnwind(t *testing.T) {
+	ctx := context.Background()
+	_, tx := memdb.NewTestTx(t)
+	_, tx5 := memdb.NewTestTx(t)
+	require.NoError(t, verkletrie.InitDB(tx))
+	cfg := VerkleTrieCfg{enabled: true, tmpDir: t.TempDir(), workers: 2}
+
+	// the first run builds the tree from PlainState
+	generateBlocks(t, 1, 5, plainWriterGen(tx), staticCodeStaticIncarnations)
+	generateBlocks(t, 1, 5, plainWriterGen(tx5), staticCodeStaticIncarnations)
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Execution, 5))
+	require.NoError(t, SpawnVerkleTrie(&StageState{ID: stages.VerkleTrie}, tx, cfg, ctx))
+	root5, err := verkletrie.ReadVerkleRoot(tx, 5)
+	require.NoError(t, err)
+	require.Equal(t, regenerateVerkleRoot(t, tx5), root5)
+
+	// the next runs update it with the changesets of the executed blocks
+	generateBlocks(t, 6, 7, plainWriterGen(tx), staticCodeStaticIncarnations)
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Execution, 12))
+	require.NoError(t, SpawnVerkleTrie(&StageState{ID: stages.VerkleTrie, BlockNumber: 5}, tx, cfg, ctx))
+	progress, err := stages.GetStageProgress(tx, stages.VerkleTrie)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), progress)
+	root12, err := verkletrie.ReadVerkleRoot(tx, 12)
+	require.NoError(t, err)
+	require.NotEqual(t, root5, root12)
+	require.Equal(t, regenerateVerkleRoot(t, tx), root12)
+
+	// unwinding runs before the execution is unwound, the tree goes back to the one of the block 5
+	require.NoError(t, UnwindVerkleTrie(&UnwindState{ID: stages.VerkleTrie, UnwindPoint: 5}, &StageState{ID: stages.VerkleTrie, BlockNumber: 12}, tx, cfg, ctx))
+	progress, err = stages.GetStageProgress(tx, stages.VerkleTrie)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), progress)
+	root, err := verkletrie.ReadVerkleRoot(tx, 5)
+	require.NoError(t, err)
+	require.Equal(t, root5, root)
+	root, err = verkletrie.ReadVerkleRoot(tx, 12)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, root)
+
+	// executing the same blocks again gives the same root
+	require.NoError(t, SpawnVerkleTrie(&StageState{ID: stages.VerkleTrie, BlockNumber: 5}, tx, cfg, ctx))
+	root, err = verkletrie.ReadVerkleRoot(tx, 12)
+	require.NoError(t, err)
+	require.Equal(t, root12, root)
+
+	// there is no root of the block 8, the tree is built again by the next forward
+	require.NoError(t, UnwindVerkleTrie(&UnwindState{ID: stages.VerkleTrie, UnwindPoint: 8}, &StageState{ID: stages.VerkleTrie, BlockNumber: 12}, tx, cfg, ctx))
+	progress, err = stages.GetStageProgress(tx, stages.VerkleTrie)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), progress)
+	root, err = verkletrie.ReadVerkleRoot(tx, 5)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}, root)
+	require.NoError(t, SpawnVerkleTrie(&StageState{ID: stages.VerkleTrie}, tx, cfg, ctx))
+	root, err = verkletrie.ReadVerkleRoot(tx, 12)
+	require.NoError(t, err)
+	require.Equal(t, root12, root)
+}
@@ -29,15 +29,17 @@ import (
 type SyncStage string
 
 var (
-	Snapshots           SyncStage = "Snapshots"       // Snapshots
-	Headers             SyncStage = "Headers"         // Headers are downloaded, their Proof-Of-Work validity and chaining is verified
-	CumulativeIndex     SyncStage = "CumulativeIndex" // Calculate how much gas has been used up to each block.
-	BlockHashes         SyncStage = "BlockHashes"     // Headers Number are written, fills blockHash => number bucket
-	Bodies              SyncStage = "Bodies"          // Block bodies are downloaded, TxHash and UncleHash are getting verified
-	Senders             SyncStage = "Senders"         // "From" recovered from signatures, bodies re-written
-	Execution           SyncStage = "Execution"       // Executing each block w/o buildinf a trie
-	Translation         SyncStage = "Translation"     // Translation each marked for translation contract (from EVM to TEVM)
-	VerkleTrie          SyncStage = "VerkleTrie"
+	Snapshots           SyncStage = "Snapshots"           // Snapshots
+	Headers             SyncStage = "Headers"             // Headers are downloaded, their Proof-Of-Work validity and chaining is verified
+	CumulativeIndex     SyncStage = "CumulativeIndex"     // Calculate how much gas has been used up to each block.
+	BlockHashes         SyncStage = "BlockHashes"         // Headers Number are written, fills blockHash => number bucket
+	Bodies              SyncStage = "Bodies"              // Block bodies are downloaded, TxHash and UncleHash are getting verified
+	BorHeimdall         SyncStage = "BorHeimdall"         // Spans and state sync events needed to execute Bor blocks are fetched from Heimdall
+	Senders             SyncStage = "Senders"             // "From" recovered from signatures, bodies re-written
+	Execution           SyncStage = "Execution"           // Executing each block w/o buildinf a trie
+	Receipts            SyncStage = "Receipts"            // Receipts missing in the database are downloaded, ReceiptHash is getting verified
+	Translation         SyncStage = "Translation"         // Translation each marked for translation contract (from EVM to TEVM)
+	VerkleTrie          SyncStage = "VerkleTrie"          // Maintain verkle tree commitment to the state alongside the MPT
 	IntermediateHashes  SyncStage = "IntermediateHashes"  // Generate intermediate hashes, calculate the state root hash
 	HashState           SyncStage = "HashState"           // Apply Keccak256 to all the keys in the state
 	AccountHistoryIndex SyncStage = "AccountHistoryIndex" // Generating history index for accounts
@@ -58,11 +60,14 @@ var AllStages = []SyncStage{
 	Headers,
 	BlockHashes,
 	Bodies,
+	BorHeimdall,
 	Senders,
 	Execution,
+	Receipts,
 	Translation,
 	HashState,
 	IntermediateHashes,
+	VerkleTrie,
 	AccountHistoryIndex,
 	StorageHistoryIndex,
 	LogIndex,
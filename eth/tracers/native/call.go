@@ -0,0 +1,285 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+)
+
+func init() {
+	register("callTracer", newCallTracer)
+}
+
+type callLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type callFrame struct {
+	Type    string      `json:"type"`
+	From    string      `json:"from"`
+	To      string      `json:"to,omitempty"`
+	Value   string      `json:"value,omitempty"`
+	Gas     string      `json:"gas,omitempty"`
+	GasUsed string      `json:"gasUsed,omitempty"`
+	Input   string      `json:"input,omitempty"`
+	Output  string      `json:"output,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Calls   []callFrame `json:"calls,omitempty"`
+	Logs    []callLog   `json:"logs,omitempty"`
+
+	precompile bool // Precompile invocations are not reported, see call_tracer.js
+}
+
+type callTracer struct {
+	callstack []callFrame
+	config    callTracerConfig
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+type callTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall"` // If true, call tracer won't collect any subcalls
+	WithLog     bool `json:"withLog"`     // If true, call tracer will collect event logs
+}
+
+// newCallTracer returns a native go tracer which tracks
+// call frames of a tx, and implements vm.Tracer.
+func newCallTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	var config callTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	// First callframe contains tx context info
+	// and is populated on start and end.
+	return &callTracer{callstack: make([]callFrame, 1), config: config}, nil
+}
+
+// CaptureStart implements the vm.Tracer interface to initialize the tracing operation
+// of the top-level call as well as each of the inner calls.
+func (t *callTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth == 0 {
+		t.callstack[0] = callFrame{
+			Type:  "CALL",
+			From:  addrToHex(from),
+			To:    addrToHex(to),
+			Input: bytesToHex(input),
+			Gas:   uintToHex(gas),
+			Value: bigToHex(value),
+		}
+		if create {
+			t.callstack[0].Type = "CREATE"
+		}
+		return
+	}
+	if t.config.OnlyTopCall {
+		return
+	}
+	// Keep pushing frames after an interruption, so that CaptureEnd stays balanced
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		env.Cancel()
+	}
+	call := callFrame{
+		Type:       callTypeToString(callType),
+		From:       addrToHex(from),
+		To:         addrToHex(to),
+		Input:      bytesToHex(input),
+		Gas:        uintToHex(gas),
+		precompile: precompile,
+	}
+	if callType != vm.DELEGATECALLT && callType != vm.STATICCALLT {
+		call.Value = bigToHex(value)
+	}
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureState implements the vm.Tracer interface to trace a single step of VM execution.
+// It is only used to collect event logs, when requested.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if !t.config.WithLog || err != nil {
+		return
+	}
+	// Avoid processing nested calls when only caring about top call
+	if t.config.OnlyTopCall && depth > 0 {
+		return
+	}
+	switch op {
+	case vm.LOG0, vm.LOG1, vm.LOG2, vm.LOG3, vm.LOG4:
+		size := int(op - vm.LOG0)
+
+		stack := scope.Stack
+		mStart := stack.Back(0)
+		mSize := stack.Back(1)
+		topics := make([]common.Hash, size)
+		for i := 0; i < size; i++ {
+			topics[i] = common.Hash(stack.Back(2 + i).Bytes32())
+		}
+
+		data := scope.Memory.GetCopy(mStart.Uint64(), mSize.Uint64())
+		l := callLog{Address: scope.Contract.Address(), Topics: topics, Data: hexutil.Bytes(data)}
+		t.callstack[len(t.callstack)-1].Logs = append(t.callstack[len(t.callstack)-1].Logs, l)
+	}
+}
+
+// CaptureFault implements the vm.Tracer interface to trace an execution fault.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnd is called after the top-level call or any of the inner calls finish.
+func (t *callTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, _ time.Duration, err error) {
+	if depth == 0 {
+		t.callstack[0].GasUsed = uintToHex(startGas - endGas)
+		if err != nil {
+			t.callstack[0].Error = err.Error()
+			if errors.Is(err, vm.ErrExecutionReverted) && len(output) > 0 {
+				t.callstack[0].Output = bytesToHex(output)
+			}
+		} else {
+			t.callstack[0].Output = bytesToHex(output)
+		}
+		return
+	}
+	if t.config.OnlyTopCall {
+		return
+	}
+	size := len(t.callstack)
+	if size <= 1 {
+		return
+	}
+	// pop call
+	call := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+	size -= 1
+
+	if call.precompile {
+		return
+	}
+	call.GasUsed = uintToHex(startGas - endGas)
+	if err == nil {
+		call.Output = bytesToHex(output)
+	} else {
+		call.Error = err.Error()
+		if call.Type == "CREATE" || call.Type == "CREATE2" {
+			call.To = ""
+		}
+	}
+	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
+}
+
+// CaptureSelfDestruct records a self-destruct as a subcall of the current frame.
+func (t *callTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+	if t.config.OnlyTopCall {
+		return
+	}
+	size := len(t.callstack)
+	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, callFrame{
+		Type:  "SELFDESTRUCT",
+		From:  addrToHex(from),
+		To:    addrToHex(to),
+		Value: bigToHex(value),
+	})
+}
+
+func (t *callTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *callTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// GetResult returns the json-encoded nested list of call traces, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+	clearFailedLogs(&t.callstack[0], false)
+	res, err := json.Marshal(t.callstack[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// clearFailedLogs clears the logs of a callframe and all its children
+// in case of execution failure.
+func clearFailedLogs(cf *callFrame, parentFailed bool) {
+	failed := cf.Error != "" || parentFailed
+	if failed {
+		cf.Logs = nil
+	}
+	for i := range cf.Calls {
+		clearFailedLogs(&cf.Calls[i], failed)
+	}
+}
+
+func callTypeToString(callType vm.CallType) string {
+	switch callType {
+	case vm.CALLT:
+		return "CALL"
+	case vm.CALLCODET:
+		return "CALLCODE"
+	case vm.DELEGATECALLT:
+		return "DELEGATECALL"
+	case vm.STATICCALLT:
+		return "STATICCALL"
+	case vm.CREATET:
+		return "CREATE"
+	case vm.CREATE2T:
+		return "CREATE2"
+	}
+	return "UNKNOWN"
+}
+
+func bytesToHex(s []byte) string {
+	return "0x" + common.Bytes2Hex(s)
+}
+
+func bigToHex(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return "0x" + n.Text(16)
+}
+
+func uintToHex(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+func addrToHex(a common.Address) string {
+	return hexutil.Encode(a.Bytes())
+}
@@ -0,0 +1,314 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+)
+
+func init() {
+	register("prestateTracer", newPrestateTracer)
+}
+
+type state = map[common.Address]*account
+
+type account struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type prestateTracer struct {
+	env       *vm.EVM
+	pre       state
+	post      state
+	create    bool
+	to        common.Address
+	config    prestateTracerConfig
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+	created   map[common.Address]bool
+	deleted   map[common.Address]bool
+}
+
+type prestateTracerConfig struct {
+	DiffMode bool `json:"diffMode"` // If true, this tracer will return state modifications
+}
+
+// newPrestateTracer returns a native go tracer which collects the state of all
+// accounts touched by a transaction prior to its execution, and optionally the
+// state changes it introduced.
+func newPrestateTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	var config prestateTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &prestateTracer{
+		pre:     state{},
+		post:    state{},
+		config:  config,
+		created: make(map[common.Address]bool),
+		deleted: make(map[common.Address]bool),
+	}, nil
+}
+
+// CaptureStart implements the vm.Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(env *vm.EVM, depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int, code []byte) {
+	if depth != 0 {
+		return
+	}
+	t.env = env
+	t.create = create
+	t.to = to
+
+	if t.config.DiffMode {
+		t.lookupAccount(env.Context().Coinbase)
+	}
+	t.lookupAccount(from)
+	if create {
+		t.created[to] = true
+	} else {
+		t.lookupAccount(to)
+	}
+
+	// The sender has already paid for the whole gas limit up-front,
+	// re-add it to get the pre-tx balance.
+	blockNumber := env.Context().BlockNumber
+	intrinsicGas, err := core.IntrinsicGas(input, nil, create, env.ChainConfig().IsHomestead(blockNumber), env.ChainConfig().IsIstanbul(blockNumber), env.ChainConfig().IsShanghai(blockNumber))
+	if err != nil {
+		return
+	}
+	fromBal := new(big.Int).Set(t.pre[from].Balance.ToInt())
+	consumedGas := new(big.Int).Mul(env.TxContext().GasPrice, new(big.Int).SetUint64(gas+intrinsicGas))
+	fromBal.Add(fromBal, consumedGas)
+	t.pre[from].Balance = (*hexutil.Big)(fromBal)
+	// The nonce of the sender is only bumped ahead of the execution for plain calls,
+	// contract creations do it after the new address has been derived.
+	if !create && t.pre[from].Nonce > 0 {
+		t.pre[from].Nonce--
+	}
+}
+
+// CaptureState implements the vm.Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if err != nil {
+		return
+	}
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		env.Cancel()
+		return
+	}
+	stack := scope.Stack
+	stackLen := stack.Len()
+	caller := scope.Contract.Address()
+	switch {
+	case stackLen >= 1 && (op == vm.SLOAD || op == vm.SSTORE):
+		slot := common.Hash(stack.Back(0).Bytes32())
+		t.lookupStorage(caller, slot)
+	case stackLen >= 1 && (op == vm.EXTCODECOPY || op == vm.EXTCODEHASH || op == vm.EXTCODESIZE || op == vm.BALANCE || op == vm.SELFDESTRUCT):
+		addr := common.Address(stack.Back(0).Bytes20())
+		t.lookupAccount(addr)
+		if op == vm.SELFDESTRUCT {
+			t.deleted[caller] = true
+		}
+	case stackLen >= 5 && (op == vm.DELEGATECALL || op == vm.CALL || op == vm.STATICCALL || op == vm.CALLCODE):
+		addr := common.Address(stack.Back(1).Bytes20())
+		t.lookupAccount(addr)
+	case op == vm.CREATE:
+		nonce := env.IntraBlockState().GetNonce(caller)
+		addr := crypto.CreateAddress(caller, nonce)
+		t.lookupAccount(addr)
+		t.created[addr] = true
+	case stackLen >= 4 && op == vm.CREATE2:
+		offset := stack.Back(1)
+		size := stack.Back(2)
+		init := scope.Memory.GetCopy(offset.Uint64(), size.Uint64())
+		inithash := crypto.Keccak256(init)
+		salt := stack.Back(3)
+		addr := crypto.CreateAddress2(caller, salt.Bytes32(), inithash)
+		t.lookupAccount(addr)
+		t.created[addr] = true
+	}
+}
+
+// CaptureFault implements the vm.Tracer interface to trace an execution fault.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(depth int, output []byte, startGas, endGas uint64, _ time.Duration, err error) {
+}
+
+func (t *prestateTracer) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+}
+
+func (t *prestateTracer) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+func (t *prestateTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+// GetResult returns the json-encoded prestate, or in diff mode the pre and
+// post states of the modified accounts, and any error arising from the
+// encoding or forceful termination (via `Stop`).
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	var res []byte
+	var err error
+	if t.config.DiffMode {
+		t.processDiffState()
+		res, err = json.Marshal(struct {
+			Post state `json:"post"`
+			Pre  state `json:"pre"`
+		}{t.post, t.pre})
+	} else {
+		// The created contract had no prestate, as in prestate_tracer.js
+		if t.create {
+			delete(t.pre, t.to)
+		}
+		res, err = json.Marshal(t.pre)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// processDiffState compares the collected prestate with the state after the
+// execution, keeping only the modified accounts and fields in both.
+func (t *prestateTracer) processDiffState() {
+	if t.env == nil {
+		return
+	}
+	ibs := t.env.IntraBlockState()
+	for addr, acc := range t.pre {
+		// The deleted account's state is pruned from `post` but kept in `pre`
+		if t.deleted[addr] {
+			continue
+		}
+		modified := false
+		postAccount := &account{Storage: make(map[common.Hash]common.Hash)}
+		newBalance := ibs.GetBalance(addr).ToBig()
+		newNonce := ibs.GetNonce(addr)
+		newCode := ibs.GetCode(addr)
+
+		if newBalance.Cmp(acc.Balance.ToInt()) != 0 {
+			modified = true
+			postAccount.Balance = (*hexutil.Big)(newBalance)
+		}
+		if newNonce != acc.Nonce {
+			modified = true
+			postAccount.Nonce = newNonce
+		}
+		if !bytes.Equal(newCode, acc.Code) {
+			modified = true
+			postAccount.Code = newCode
+		}
+
+		var newVal uint256.Int
+		for key, val := range acc.Storage {
+			key := key
+			ibs.GetState(addr, &key, &newVal)
+			if val == common.Hash(newVal.Bytes32()) {
+				// Omit unchanged slots
+				delete(acc.Storage, key)
+				continue
+			}
+			modified = true
+			if !newVal.IsZero() {
+				postAccount.Storage[key] = common.Hash(newVal.Bytes32())
+			}
+			// don't include the empty slot
+			if val == (common.Hash{}) {
+				delete(acc.Storage, key)
+			}
+		}
+
+		if modified {
+			t.post[addr] = postAccount
+		} else {
+			// if state is not modified, then no need to include into the pre state
+			delete(t.pre, addr)
+		}
+	}
+	// the new created contracts' prestate were empty, so delete them
+	for addr := range t.created {
+		if acc, ok := t.pre[addr]; ok && acc.empty() {
+			delete(t.pre, addr)
+		}
+	}
+}
+
+// lookupAccount fetches details of an account and adds it to the prestate
+// if it doesn't exist there.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.pre[addr]; ok {
+		return
+	}
+	ibs := t.env.IntraBlockState()
+	t.pre[addr] = &account{
+		Balance: (*hexutil.Big)(ibs.GetBalance(addr).ToBig()),
+		Nonce:   ibs.GetNonce(addr),
+		Code:    common.CopyBytes(ibs.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage fetches the requested storage slot and adds
+// it to the prestate of the given contract. It assumes `lookupAccount`
+// has been performed on the contract before.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	if _, ok := t.pre[addr]; !ok {
+		t.lookupAccount(addr)
+	}
+	if _, ok := t.pre[addr].Storage[key]; ok {
+		return
+	}
+	var val uint256.Int
+	t.env.IntraBlockState().GetState(addr, &key, &val)
+	t.pre[addr].Storage[key] = common.Hash(val.Bytes32())
+}
+
+// empty reports whether the account had neither balance, nonce nor code
+// before the transaction.
+func (a *account) empty() bool {
+	return a.Nonce == 0 && a.Balance.ToInt().Sign() == 0 && len(a.Code) == 0
+}
@@ -0,0 +1,203 @@
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/common/math"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/eth/tracers"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/tests"
+	"github.com/stretchr/testify/require"
+)
+
+// callTrace is the result of a callTracer run.
+type callTrace struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      common.Address  `json:"to"`
+	Input   hexutil.Bytes   `json:"input"`
+	Output  hexutil.Bytes   `json:"output"`
+	Gas     *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Value   *hexutil.Big    `json:"value,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Calls   []callTrace     `json:"calls,omitempty"`
+}
+
+type callContext struct {
+	Number     math.HexOrDecimal64   `json:"number"`
+	Difficulty *math.HexOrDecimal256 `json:"difficulty"`
+	Time       math.HexOrDecimal64   `json:"timestamp"`
+	GasLimit   math.HexOrDecimal64   `json:"gasLimit"`
+	Miner      common.Address        `json:"miner"`
+}
+
+// callTracerTest defines a single test to check the call tracer against.
+type callTracerTest struct {
+	Genesis *core.Genesis `json:"genesis"`
+	Context *callContext  `json:"context"`
+	Input   string        `json:"input"`
+	Result  *callTrace    `json:"result"`
+}
+
+// runTracerTest executes the transaction of the given callTracer test case
+// with the named tracer and returns the raw result.
+func runTracerTest(t *testing.T, file string, name string, cfg json.RawMessage) (*callTracerTest, json.RawMessage) {
+	blob, err := os.ReadFile(filepath.Join("..", "testdata", file))
+	if err != nil {
+		t.Fatalf("failed to read testcase: %v", err)
+	}
+	test := new(callTracerTest)
+	if err := json.Unmarshal(blob, test); err != nil {
+		t.Fatalf("failed to parse testcase: %v", err)
+	}
+	txn, err := types.DecodeTransaction(rlp.NewStream(bytes.NewReader(common.FromHex(test.Input)), 0))
+	if err != nil {
+		t.Fatalf("failed to parse testcase input: %v", err)
+	}
+	signer := types.MakeSigner(test.Genesis.Config, uint64(test.Context.Number))
+	origin, _ := signer.Sender(txn)
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: big.NewInt(int64(txn.GetPrice().Uint64())),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    test.Context.Miner,
+		BlockNumber: uint64(test.Context.Number),
+		Time:        uint64(test.Context.Time),
+		Difficulty:  (*big.Int)(test.Context.Difficulty),
+		GasLimit:    uint64(test.Context.GasLimit),
+	}
+
+	_, tx := memdb.NewTestTx(t)
+	rules := &params.Rules{}
+	statedb, err := tests.MakePreState(rules, tx, test.Genesis.Alloc, uint64(test.Context.Number))
+	require.NoError(t, err)
+
+	tracer, err := tracers.New(name, new(tracers.Context), cfg)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+
+	msg, err := txn.AsMessage(*signer, nil, rules)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(txn.GetGas()))
+	if _, err = st.TransitionDb(false, false); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	return test, res
+}
+
+// Runs the native call tracer against the datasets of the JavaScript one. The
+// datasets with calls to plain accounts and internal failures are skipped, as
+// call_tracer.js can't observe the gas of those calls and the native tracer
+// doesn't report calls that failed before being entered.
+func TestCallTracer(t *testing.T) {
+	for _, file := range []string{
+		"call_tracer_create.json",
+		"call_tracer_deep_calls.json",
+		"call_tracer_delegatecall.json",
+		"call_tracer_inner_throw_outer_revert.json",
+		"call_tracer_oog.json",
+		"call_tracer_revert.json",
+		"call_tracer_revert_reason.json",
+		"call_tracer_throw.json",
+	} {
+		file := file // capture range variable
+		t.Run(file, func(t *testing.T) {
+			t.Parallel()
+			test, res := runTracerTest(t, file, "callTracer", nil)
+			ret := new(callTrace)
+			if err := json.Unmarshal(res, ret); err != nil {
+				t.Fatalf("failed to unmarshal trace result: %v", err)
+			}
+			if !jsonEqual(ret, test.Result) {
+				t.Fatalf("trace mismatch: \nhave %+v\nwant %+v", ret, test.Result)
+			}
+		})
+	}
+}
+
+func TestCallTracerOnlyTopCall(t *testing.T) {
+	test, res := runTracerTest(t, "call_tracer_deep_calls.json", "callTracer", json.RawMessage(`{"onlyTopCall": true}`))
+	ret := new(callTrace)
+	if err := json.Unmarshal(res, ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	want := *test.Result
+	want.Calls = nil
+	if !jsonEqual(ret, &want) {
+		t.Fatalf("trace mismatch: \nhave %+v\nwant %+v", ret, want)
+	}
+}
+
+func TestFourByteTracer(t *testing.T) {
+	test, res := runTracerTest(t, "call_tracer_delegatecall.json", "4byteTracer", nil)
+	ids := make(map[string]int)
+	if err := json.Unmarshal(res, &ids); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	var calls int
+	var count func(call *callTrace)
+	count = func(call *callTrace) {
+		if len(call.Input) >= 4 {
+			calls++
+		}
+		for i := range call.Calls {
+			count(&call.Calls[i])
+		}
+	}
+	count(test.Result)
+
+	var have int
+	for _, n := range ids {
+		have += n
+	}
+	if have != calls {
+		t.Fatalf("4byte ids mismatch: have %d, want %d (%v)", have, calls, ids)
+	}
+}
+
+// jsonEqual is similar to reflect.DeepEqual, but does a 'bounce' via json prior to
+// comparison
+func jsonEqual(x, y interface{}) bool {
+	xTrace := new(callTrace)
+	yTrace := new(callTrace)
+	if xj, err := json.Marshal(x); err == nil {
+		if err = json.Unmarshal(xj, xTrace); err != nil {
+			panic(err)
+		}
+	} else {
+		return false
+	}
+	if yj, err := json.Marshal(y); err == nil {
+		if err = json.Unmarshal(yj, yTrace); err != nil {
+			panic(err)
+		}
+	} else {
+		return false
+	}
+	return reflect.DeepEqual(xTrace, yTrace)
+}
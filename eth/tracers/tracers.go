@@ -14,16 +14,61 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package tracers is a collection of JavaScript transaction tracers.
+// Package tracers is a collection of JavaScript and native Go transaction tracers.
 package tracers
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
 	"unicode"
 
+	"github.com/ledgerwatch/erigon/core/vm"
 	"github.com/ledgerwatch/erigon/eth/tracers/internal/tracers"
 )
 
+// Tracer interface extends vm.Tracer and additionally
+// allows collecting the tracing result.
+type Tracer interface {
+	vm.Tracer
+	GetResult() (json.RawMessage, error)
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// lookupFunc resolves a tracer by name. It returns a nil tracer and no error
+// if the name is not known to the lookup.
+type lookupFunc func(string, *Context, json.RawMessage) (Tracer, error)
+
+var lookups []lookupFunc
+
+// RegisterLookup registers a method as a lookup for tracers, meaning that
+// users can invoke a named tracer through that lookup. If 'wildcard' is true,
+// then the lookup will be placed last. This is typically meant for interpreted
+// engines (js) which can evaluate dynamic user-supplied code.
+func RegisterLookup(wildcard bool, lookup lookupFunc) {
+	if wildcard {
+		lookups = append(lookups, lookup)
+	} else {
+		lookups = append([]lookupFunc{lookup}, lookups...)
+	}
+}
+
+// New returns a new instance of a tracer, by iterating through the
+// registered lookups.
+func New(code string, ctx *Context, cfg json.RawMessage) (Tracer, error) {
+	for _, lookup := range lookups {
+		tracer, err := lookup(code, ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if tracer != nil {
+			return tracer, nil
+		}
+	}
+	return nil, errors.New("tracer not found")
+}
+
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
@@ -325,3 +325,88 @@ func SeekInBitmap64(m *roaring64.Bitmap, n uint64) (found uint64, ok bool) {
 	found, _ = m.Select(searchRank)
 	return found, true
 }
+
+// Iterator64 walks the values of the chunked bitmap of the key, after `from` or, if reverse is set, before it.
+// The chunks are read from the db as the iteration reaches them.
+type Iterator64 struct {
+	c       kv.Cursor
+	key     []byte
+	from    uint64
+	reverse bool
+	k, v    []byte   // the chunk to read next, nil if there are no more
+	values  []uint64 // values of the current chunk yet to be returned, in the iteration order
+}
+
+func NewIterator64(db kv.Tx, bucket string, key []byte, from uint64, reverse bool) (*Iterator64, error) {
+	c, err := db.Cursor(bucket)
+	if err != nil {
+		return nil, err
+	}
+	it := &Iterator64{c: c, key: key, from: from, reverse: reverse}
+	// chunks are keyed by their max value (the last one by ^uint64(0)), so the first chunk to read holds from+1 or from-1
+	seek := from + 1
+	if reverse {
+		if from == 0 {
+			return it, nil
+		}
+		seek = from - 1
+	}
+	seekKey := make([]byte, len(key)+8)
+	copy(seekKey, key)
+	binary.BigEndian.PutUint64(seekKey[len(key):], seek)
+	if it.k, it.v, err = c.Seek(seekKey); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if it.k != nil && !bytes.HasPrefix(it.k, key) {
+		it.k, it.v = nil, nil
+	}
+	return it, nil
+}
+
+// HasNext reads the chunks until it finds a value, or there are no more
+func (it *Iterator64) HasNext() (bool, error) {
+	for len(it.values) == 0 && it.k != nil {
+		bm := roaring64.New()
+		if _, err := bm.ReadFrom(bytes.NewReader(it.v)); err != nil {
+			return false, err
+		}
+		var err error
+		if it.reverse {
+			bm.RemoveRange(it.from, math.MaxUint64)
+			bm.Remove(math.MaxUint64)
+			it.values = bm.ToArray()
+			for i, j := 0, len(it.values)-1; i < j; i, j = i+1, j-1 {
+				it.values[i], it.values[j] = it.values[j], it.values[i]
+			}
+			it.k, it.v, err = it.c.Prev()
+		} else {
+			bm.RemoveRange(0, it.from+1)
+			it.values = bm.ToArray()
+			it.k, it.v, err = it.c.Next()
+		}
+		if err != nil {
+			return false, err
+		}
+		if it.k != nil && !bytes.HasPrefix(it.k, it.key) {
+			it.k, it.v = nil, nil
+		}
+	}
+	return len(it.values) > 0, nil
+}
+
+// Peek returns the next value without moving to it, it must follow HasNext returning true
+func (it *Iterator64) Peek() uint64 {
+	return it.values[0]
+}
+
+// Next returns the next value, it must follow HasNext returning true
+func (it *Iterator64) Next() uint64 {
+	v := it.values[0]
+	it.values = it.values[1:]
+	return v
+}
+
+func (it *Iterator64) Close() {
+	it.c.Close()
+}
@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	"github.com/RoaringBitmap/roaring"
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
 	"github.com/ledgerwatch/erigon/ethdb/bitmapdb"
 	"github.com/stretchr/testify/require"
 )
@@ -53,3 +56,57 @@ func TestCutLeft(t *testing.T) {
 	require.True(t, lft == nil)
 	require.True(t, bm.GetCardinality() == 0)
 }
+
+func TestIterator64(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	key, other := []byte{1}, []byte{2}
+	var values []uint64
+	bm := roaring64.New()
+	for v := uint64(1); v < 10_000; v += 7 {
+		bm.Add(v)
+		values = append(values, v)
+	}
+	for _, k := range [][]byte{key, other} {
+		require.NoError(t, bitmapdb.WalkChunkWithKeys64(k, bm.Clone(), 256, func(chunkKey []byte, chunk *roaring64.Bitmap) error {
+			buf, err := chunk.ToBytes()
+			if err != nil {
+				return err
+			}
+			return tx.Put(kv.CallFromIndex, chunkKey, buf)
+		}))
+	}
+
+	collect := func(from uint64, reverse bool) []uint64 {
+		it, err := bitmapdb.NewIterator64(tx, kv.CallFromIndex, key, from, reverse)
+		require.NoError(t, err)
+		defer it.Close()
+		var res []uint64
+		for {
+			ok, err := it.HasNext()
+			require.NoError(t, err)
+			if !ok {
+				return res
+			}
+			next := it.Peek()
+			require.Equal(t, next, it.Next())
+			res = append(res, next)
+		}
+	}
+	reversed := func(values []uint64) []uint64 {
+		res := make([]uint64, 0, len(values))
+		for i := len(values) - 1; i >= 0; i-- {
+			res = append(res, values[i])
+		}
+		return res
+	}
+
+	require.Equal(t, values, collect(0, false))
+	require.Equal(t, values[72:], collect(500, false)) // values[i] is 1+7*i
+	require.Equal(t, values[73:], collect(505, false))
+	require.Nil(t, collect(10_000, false))
+	require.Equal(t, reversed(values), collect(^uint64(0), true))
+	require.Equal(t, reversed(values[:72]), collect(505, true))
+	require.Equal(t, reversed(values[:71]), collect(498, true))
+	require.Nil(t, collect(1, true))
+	require.Nil(t, collect(0, true))
+}
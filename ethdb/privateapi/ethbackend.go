@@ -11,6 +11,7 @@ import (
 	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
 	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
@@ -43,6 +44,25 @@ const MaxBuilders = 128
 var UnknownPayloadErr = rpc.CustomError{Code: -38001, Message: "Unknown payload"}
 var InvalidForkchoiceStateErr = rpc.CustomError{Code: -38002, Message: "Invalid forkchoice state"}
 var InvalidPayloadAttributesErr = rpc.CustomError{Code: -38003, Message: "Invalid payload attributes"}
+var InvalidParamsErr = rpc.CustomError{Code: -32602, Message: "Invalid params"}
+
+// EngineV2 are the Shanghai engine API methods, they carry withdrawals and the block value which the messages of the
+// ETHBACKEND gRPC interface don't have. Embedded rpcdaemon calls them on Erigon directly, see
+// RemoteBackend.SetEngineV2, standalone rpcdaemon refuses them. Withdrawals are nil before Shanghai.
+type EngineV2 interface {
+	EngineNewPayloadV2(ctx context.Context, req *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error)
+	EngineForkChoiceUpdatedV2(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error)
+	EngineGetPayloadV2(ctx context.Context, req *remote.EngineGetPayloadRequest) (payload *types2.ExecutionPayload, withdrawals []*types.Withdrawal, blockValue *uint256.Int, err error)
+}
+
+// PeerAdmin are the peer management methods of the sentries, the peers are identified by their enode URLs.
+// Standalone rpcdaemon reaches them through the PeerAdmin gRPC service of Erigon, see NewPeerAdminClient.
+type PeerAdmin interface {
+	AddPeer(ctx context.Context, url string) (bool, error)
+	RemovePeer(ctx context.Context, url string) (bool, error)
+	AddTrustedPeer(ctx context.Context, url string) (bool, error)
+	PeerEvents(ctx context.Context, cb func(*sentry.PeerEvent)) error
+}
 
 type EthBackendServer struct {
 	remote.UnimplementedETHBACKENDServer // must be embedded to have forward compatible implementations.
@@ -70,6 +90,7 @@ type EthBackend interface {
 	NetPeerCount() (uint64, error)
 	NodesInfo(limit int) (*remote.NodesInfoReply, error)
 	Peers(ctx context.Context) (*remote.PeersReply, error)
+	PeerAdmin
 }
 
 func NewEthBackendServer(ctx context.Context, eth EthBackend, db kv.RwDB, events *Events, blockReader services.BlockAndTxnReader,
@@ -280,6 +301,26 @@ func (s *EthBackendServer) stageLoopIsBusy() bool {
 
 // EngineNewPayloadV1 validates and possibly executes payload
 func (s *EthBackendServer) EngineNewPayloadV1(ctx context.Context, req *types2.ExecutionPayload) (*remote.EnginePayloadStatus, error) {
+	return s.EngineNewPayloadV2(ctx, req, nil)
+}
+
+// checkWithdrawals verifies that withdrawals are present exactly from Shanghai on
+func (s *EthBackendServer) checkWithdrawals(blockNumber uint64, withdrawals []*types.Withdrawal) error {
+	shanghai := s.config.IsShanghai(blockNumber)
+	if shanghai && withdrawals == nil {
+		return &rpc.CustomError{Code: InvalidParamsErr.Code, Message: "withdrawals are required after Shanghai"}
+	}
+	if !shanghai && withdrawals != nil {
+		return &rpc.CustomError{Code: InvalidParamsErr.Code, Message: "withdrawals before Shanghai"}
+	}
+	return nil
+}
+
+// EngineNewPayloadV2 is EngineNewPayloadV1 with withdrawals, see EngineV2
+func (s *EthBackendServer) EngineNewPayloadV2(ctx context.Context, req *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error) {
+	if err := s.checkWithdrawals(req.BlockNumber, withdrawals); err != nil {
+		return nil, err
+	}
 	var baseFee *big.Int
 	eip1559 := false
 
@@ -306,6 +347,10 @@ func (s *EthBackendServer) EngineNewPayloadV1(ctx context.Context, req *types2.E
 		ReceiptHash: gointerfaces.ConvertH256ToHash(req.ReceiptRoot),
 		TxHash:      types.DeriveSha(types.BinaryTransactions(req.Transactions)),
 	}
+	if withdrawals != nil {
+		withdrawalsHash := types.DeriveSha(types.Withdrawals(withdrawals))
+		header.WithdrawalsHash = &withdrawalsHash
+	}
 
 	blockHash := gointerfaces.ConvertH256ToHash(req.BlockHash)
 	if header.Hash() != blockHash {
@@ -333,7 +378,7 @@ func (s *EthBackendServer) EngineNewPayloadV1(ctx context.Context, req *types2.E
 			ValidationError: err.Error(),
 		}, nil
 	}
-	block := types.NewBlockFromStorage(blockHash, &header, transactions, nil)
+	block := types.NewBlockFromStorage(blockHash, &header, transactions, nil, withdrawals)
 
 	possibleStatus, err := s.getQuickPayloadStatusIfPossible(blockHash, req.BlockNumber, header.ParentHash, nil, true)
 	if err != nil {
@@ -493,12 +538,19 @@ func (s *EthBackendServer) getQuickPayloadStatusIfPossible(blockHash common.Hash
 
 // EngineGetPayloadV1 retrieves previously assembled payload (Validators only)
 func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.EngineGetPayloadRequest) (*types2.ExecutionPayload, error) {
+	payload, _, _, err := s.EngineGetPayloadV2(ctx, req)
+	return payload, err
+}
+
+// EngineGetPayloadV2 is EngineGetPayloadV1 which also returns withdrawals of the payload and
+// the sum of priority fees the fee recipient gets, see EngineV2
+func (s *EthBackendServer) EngineGetPayloadV2(ctx context.Context, req *remote.EngineGetPayloadRequest) (*types2.ExecutionPayload, []*types.Withdrawal, *uint256.Int, error) {
 	if !s.proposing {
-		return nil, fmt.Errorf("execution layer not running as a proposer. enable proposer by taking out the --proposer.disable flag on startup")
+		return nil, nil, nil, fmt.Errorf("execution layer not running as a proposer. enable proposer by taking out the --proposer.disable flag on startup")
 	}
 
 	if s.config.TerminalTotalDifficulty == nil {
-		return nil, fmt.Errorf("not a proof-of-stake chain")
+		return nil, nil, nil, fmt.Errorf("not a proof-of-stake chain")
 	}
 
 	log.Debug("[GetPayload] acquiring lock")
@@ -509,10 +561,11 @@ func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.E
 	builder, ok := s.builders[req.PayloadId]
 	if !ok {
 		log.Warn("Payload not stored", "payloadId", req.PayloadId)
-		return nil, &UnknownPayloadErr
+		return nil, nil, nil, &UnknownPayloadErr
 	}
 
-	block := builder.Stop()
+	blockWithReceipts := builder.Stop()
+	block := blockWithReceipts.Block
 
 	var baseFeeReply *types2.H256
 	if block.Header().BaseFee != nil {
@@ -523,12 +576,12 @@ func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.E
 
 	encodedTransactions, err := types.MarshalTransactionsBinary(block.Transactions())
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	blockRlp, err := rlp.EncodeToBytes(block)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	log.Info("PoS block built successfully", "hash", block.Header().Hash(),
 		"transactions count", len(encodedTransactions), "number", block.NumberU64(), "rlp", common.Bytes2Hex(blockRlp))
@@ -548,11 +601,34 @@ func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.E
 		BaseFeePerGas: baseFeeReply,
 		BlockHash:     gointerfaces.ConvertHashToH256(block.Header().Hash()),
 		Transactions:  encodedTransactions,
-	}, nil
+	}, block.Withdrawals(), blockValue(blockWithReceipts), nil
+}
+
+// blockValue is the sum of priority fees paid by the transactions of the block
+func blockValue(br *types.BlockWithReceipts) *uint256.Int {
+	var baseFee *uint256.Int
+	if br.Block.BaseFee() != nil {
+		baseFee, _ = uint256.FromBig(br.Block.BaseFee())
+	}
+	blockValue := uint256.NewInt(0)
+	gasUsed := new(uint256.Int)
+	for i, txn := range br.Block.Transactions() {
+		if i >= len(br.Receipts) {
+			break
+		}
+		gasUsed.SetUint64(br.Receipts[i].GasUsed)
+		blockValue.Add(blockValue, gasUsed.Mul(gasUsed, txn.GetEffectiveGasTip(baseFee)))
+	}
+	return blockValue
 }
 
 // EngineForkChoiceUpdatedV1 either states new block head or request the assembling of a new block
 func (s *EthBackendServer) EngineForkChoiceUpdatedV1(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest) (*remote.EngineForkChoiceUpdatedReply, error) {
+	return s.EngineForkChoiceUpdatedV2(ctx, req, nil)
+}
+
+// EngineForkChoiceUpdatedV2 is EngineForkChoiceUpdatedV1 with withdrawals in the payload attributes, see EngineV2
+func (s *EthBackendServer) EngineForkChoiceUpdatedV2(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error) {
 	forkChoice := engineapi.ForkChoiceMessage{
 		HeadBlockHash:      gointerfaces.ConvertH256ToHash(req.ForkchoiceState.HeadBlockHash),
 		SafeBlockHash:      gointerfaces.ConvertH256ToHash(req.ForkchoiceState.SafeBlockHash),
@@ -615,6 +691,9 @@ func (s *EthBackendServer) EngineForkChoiceUpdatedV1(ctx context.Context, req *r
 	if headHeader.Time >= req.PayloadAttributes.Timestamp {
 		return nil, &InvalidPayloadAttributesErr
 	}
+	if err := s.checkWithdrawals(headHeader.Number.Uint64()+1, withdrawals); err != nil {
+		return nil, err
+	}
 
 	// Initiate payload building
 
@@ -632,6 +711,7 @@ func (s *EthBackendServer) EngineForkChoiceUpdatedV1(ctx context.Context, req *r
 		Timestamp:             req.PayloadAttributes.Timestamp,
 		PrevRandao:            emptyHeader.MixDigest,
 		SuggestedFeeRecipient: emptyHeader.Coinbase,
+		Withdrawals:           withdrawals,
 	}
 
 	s.builders[s.payloadId] = builder.NewBlockBuilder(s.builderFunc, &param, emptyHeader)
@@ -671,6 +751,22 @@ func (s *EthBackendServer) Peers(ctx context.Context, _ *emptypb.Empty) (*remote
 	return s.eth.Peers(ctx)
 }
 
+func (s *EthBackendServer) AddPeer(ctx context.Context, url string) (bool, error) {
+	return s.eth.AddPeer(ctx, url)
+}
+
+func (s *EthBackendServer) RemovePeer(ctx context.Context, url string) (bool, error) {
+	return s.eth.RemovePeer(ctx, url)
+}
+
+func (s *EthBackendServer) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return s.eth.AddTrustedPeer(ctx, url)
+}
+
+func (s *EthBackendServer) PeerEvents(ctx context.Context, cb func(*sentry.PeerEvent)) error {
+	return s.eth.PeerEvents(ctx, cb)
+}
+
 func (s *EthBackendServer) SubscribeLogs(server remote.ETHBACKEND_SubscribeLogsServer) (err error) {
 	if s.logsFilter != nil {
 		return s.logsFilter.subscribeLogs(server)
@@ -0,0 +1,122 @@
+package privateapi
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// PeerAdmin is served to standalone rpcdaemon as a separate gRPC service next to ETHBACKEND, which doesn't have
+// these methods
+const (
+	peerAdminServiceName = "remote.PeerAdmin"
+	addPeerMethod        = "/" + peerAdminServiceName + "/AddPeer"
+	removePeerMethod     = "/" + peerAdminServiceName + "/RemovePeer"
+	addTrustedPeerMethod = "/" + peerAdminServiceName + "/AddTrustedPeer"
+	peerEventsMethod     = "/" + peerAdminServiceName + "/PeerEvents"
+)
+
+type peerAdminClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewPeerAdminClient returns PeerAdmin of the remote Erigon available over the given connection
+func NewPeerAdminClient(conn grpc.ClientConnInterface) PeerAdmin {
+	return &peerAdminClient{conn: conn}
+}
+
+func (c *peerAdminClient) invoke(ctx context.Context, method string, url string) (bool, error) {
+	res := new(wrapperspb.BoolValue)
+	if err := c.conn.Invoke(ctx, method, wrapperspb.String(url), res); err != nil {
+		return false, err
+	}
+	return res.Value, nil
+}
+
+func (c *peerAdminClient) AddPeer(ctx context.Context, url string) (bool, error) {
+	return c.invoke(ctx, addPeerMethod, url)
+}
+
+func (c *peerAdminClient) RemovePeer(ctx context.Context, url string) (bool, error) {
+	return c.invoke(ctx, removePeerMethod, url)
+}
+
+func (c *peerAdminClient) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	return c.invoke(ctx, addTrustedPeerMethod, url)
+}
+
+// PeerEvents passes the peer events received from the remote Erigon to cb until ctx is done
+func (c *peerAdminClient) PeerEvents(ctx context.Context, cb func(*sentry.PeerEvent)) error {
+	stream, err := c.conn.NewStream(ctx, &peerAdminServiceDesc.Streams[0], peerEventsMethod)
+	if err != nil {
+		return err
+	}
+	if err = stream.SendMsg(&sentry.PeerEventsRequest{}); err != nil {
+		return err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		event := new(sentry.PeerEvent)
+		if err = stream.RecvMsg(event); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		cb(event)
+	}
+}
+
+// RegisterPeerAdminServer exposes the given PeerAdmin as a gRPC service
+func RegisterPeerAdminServer(s *grpc.Server, admin PeerAdmin) {
+	s.RegisterService(&peerAdminServiceDesc, admin)
+}
+
+func peerAdminHandler(method string, call func(PeerAdmin, context.Context, string) (bool, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			ok, err := call(srv.(PeerAdmin), ctx, req.(*wrapperspb.StringValue).Value)
+			if err != nil {
+				return nil, err
+			}
+			return wrapperspb.Bool(ok), nil
+		}
+		req := new(wrapperspb.StringValue)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: method}, handler)
+	}
+}
+
+// peerEventsHandler streams the peer events until the client goes away, the events which fail to be sent are dropped
+func peerEventsHandler(srv interface{}, stream grpc.ServerStream) error {
+	if err := stream.RecvMsg(new(sentry.PeerEventsRequest)); err != nil {
+		return err
+	}
+	return srv.(PeerAdmin).PeerEvents(stream.Context(), func(event *sentry.PeerEvent) {
+		_ = stream.SendMsg(event)
+	})
+}
+
+var peerAdminServiceDesc = grpc.ServiceDesc{
+	ServiceName: peerAdminServiceName,
+	HandlerType: (*PeerAdmin)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "AddPeer", Handler: peerAdminHandler(addPeerMethod, PeerAdmin.AddPeer)},
+		{MethodName: "RemovePeer", Handler: peerAdminHandler(removePeerMethod, PeerAdmin.RemovePeer)},
+		{MethodName: "AddTrustedPeer", Handler: peerAdminHandler(addTrustedPeerMethod, PeerAdmin.AddTrustedPeer)},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "PeerEvents", Handler: peerEventsHandler, ServerStreams: true},
+	},
+}
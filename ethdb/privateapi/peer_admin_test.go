@@ -0,0 +1,84 @@
+package privateapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testEnode = "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"
+
+type testPeerAdmin struct {
+	added, removed, trusted []string
+	events                  []*sentry.PeerEvent
+}
+
+func (a *testPeerAdmin) AddPeer(_ context.Context, url string) (bool, error) {
+	a.added = append(a.added, url)
+	return true, nil
+}
+
+func (a *testPeerAdmin) RemovePeer(_ context.Context, url string) (bool, error) {
+	a.removed = append(a.removed, url)
+	return false, nil
+}
+
+func (a *testPeerAdmin) AddTrustedPeer(_ context.Context, url string) (bool, error) {
+	a.trusted = append(a.trusted, url)
+	return true, nil
+}
+
+func (a *testPeerAdmin) PeerEvents(_ context.Context, cb func(*sentry.PeerEvent)) error {
+	for _, event := range a.events {
+		cb(event)
+	}
+	return nil
+}
+
+func TestPeerAdminGrpc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	admin := &testPeerAdmin{events: []*sentry.PeerEvent{
+		{PeerId: &types.H512{Hi: &types.H256{}, Lo: &types.H256{}}, EventId: sentry.PeerEvent_Connect},
+		{PeerId: &types.H512{Hi: &types.H256{}, Lo: &types.H256{}}, EventId: sentry.PeerEvent_Disconnect},
+	}}
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterPeerAdminServer(server, admin)
+	go server.Serve(listener) //nolint:errcheck
+	defer server.Stop()
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := NewPeerAdminClient(conn)
+
+	ok, err := client.AddPeer(ctx, testEnode)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = client.RemovePeer(ctx, testEnode)
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = client.AddTrustedPeer(ctx, testEnode)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{testEnode}, admin.added)
+	require.Equal(t, []string{testEnode}, admin.removed)
+	require.Equal(t, []string{testEnode}, admin.trusted)
+
+	// The stream ends when the server stops sending
+	var events []sentry.PeerEvent_PeerEventId
+	require.NoError(t, client.PeerEvents(ctx, func(event *sentry.PeerEvent) {
+		events = append(events, event.EventId)
+	}))
+	require.Equal(t, []sentry.PeerEvent_PeerEventId{sentry.PeerEvent_Connect, sentry.PeerEvent_Disconnect}, events)
+}
@@ -275,6 +275,9 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 	if head.BaseFee != nil {
 		result["baseFeePerGas"] = (*hexutil.Big)(head.BaseFee)
 	}
+	if head.WithdrawalsHash != nil {
+		result["withdrawalsRoot"] = head.WithdrawalsHash
+	}
 
 	return result
 }
@@ -327,6 +330,9 @@ func RPCMarshalBlockEx(block *types.Block, inclTx bool, fullTx bool, borTx types
 		uncleHashes[i] = uncle.Hash()
 	}
 	fields["uncles"] = uncleHashes
+	if block.Withdrawals() != nil {
+		fields["withdrawals"] = block.Withdrawals()
+	}
 
 	return fields, nil
 }
@@ -373,6 +379,8 @@ type RPCTransaction struct {
 	Type             hexutil.Uint64    `json:"type"`
 	Accesses         *types.AccessList `json:"accessList,omitempty"`
 	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	MaxFeePerBlobGas *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes       []common.Hash     `json:"blobVersionedHashes,omitempty"`
 	V                *hexutil.Big      `json:"v"`
 	R                *hexutil.Big      `json:"r"`
 	S                *hexutil.Big      `json:"s"`
@@ -432,6 +440,25 @@ func newRPCTransaction(tx types.Transaction, blockHash common.Hash, blockNumber
 		} else {
 			result.GasPrice = nil
 		}
+	case *types.BlobTx:
+		chainId.Set(t.ChainID)
+		result.ChainID = (*hexutil.Big)(chainId.ToBig())
+		result.Tip = (*hexutil.Big)(t.Tip.ToBig())
+		result.FeeCap = (*hexutil.Big)(t.FeeCap.ToBig())
+		result.MaxFeePerBlobGas = (*hexutil.Big)(t.MaxFeePerBlobGas.ToBig())
+		result.BlobHashes = t.BlobVersionedHashes
+		result.V = (*hexutil.Big)(t.V.ToBig())
+		result.R = (*hexutil.Big)(t.R.ToBig())
+		result.S = (*hexutil.Big)(t.S.ToBig())
+		if len(t.AccessList) > 0 {
+			result.Accesses = &t.AccessList
+		}
+		if baseFee != nil && blockHash != (common.Hash{}) {
+			price := math.BigMin(new(big.Int).Add(t.Tip.ToBig(), baseFee), t.FeeCap.ToBig())
+			result.GasPrice = (*hexutil.Big)(price)
+		} else {
+			result.GasPrice = nil
+		}
 	}
 	signer := types.LatestSignerForChainID(chainId.ToBig())
 	var err error
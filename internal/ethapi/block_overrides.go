@@ -0,0 +1,102 @@
+package ethapi
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+)
+
+// BlockOverrides replaces the fields of the block context, e.g. to run a call as if it was in the next block
+type BlockOverrides struct {
+	Number     *hexutil.Big    `json:"number"`
+	Difficulty *hexutil.Big    `json:"difficulty"`
+	Time       *hexutil.Uint64 `json:"time"`
+	GasLimit   *hexutil.Uint64 `json:"gasLimit"`
+	Coinbase   *common.Address `json:"coinbase"`
+	Random     *common.Hash    `json:"random"`
+	BaseFee    *hexutil.Big    `json:"baseFee"`
+}
+
+func (overrides *BlockOverrides) Override(blockCtx *vm.BlockContext) error {
+	if overrides.Number != nil {
+		number := overrides.Number.ToInt()
+		if !number.IsUint64() {
+			return fmt.Errorf("block number higher than 2^64-1")
+		}
+		blockCtx.BlockNumber = number.Uint64()
+	}
+	if overrides.Difficulty != nil {
+		blockCtx.Difficulty = overrides.Difficulty.ToInt()
+	}
+	if overrides.Time != nil {
+		blockCtx.Time = uint64(*overrides.Time)
+	}
+	if overrides.GasLimit != nil {
+		blockCtx.GasLimit = uint64(*overrides.GasLimit)
+		blockCtx.MaxGasLimit = false
+	}
+	if overrides.Coinbase != nil {
+		blockCtx.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Random != nil {
+		blockCtx.PrevRanDao = overrides.Random
+	}
+	if overrides.BaseFee != nil {
+		baseFee, err := overrides.OverrideBaseFee(blockCtx.BaseFee)
+		if err != nil {
+			return err
+		}
+		blockCtx.BaseFee = baseFee
+	}
+	return nil
+}
+
+// OverrideBaseFee returns the base fee to build the message of the call with
+func (overrides *BlockOverrides) OverrideBaseFee(baseFee *uint256.Int) (*uint256.Int, error) {
+	if overrides == nil || overrides.BaseFee == nil {
+		return baseFee, nil
+	}
+	baseFee, overflow := uint256.FromBig(overrides.BaseFee.ToInt())
+	if overflow {
+		return nil, fmt.Errorf("baseFee higher than 2^256-1")
+	}
+	return baseFee, nil
+}
+
+// OverrideHeader replaces the fields of the header of a block which is not in the chain, e.g. a simulated one
+func (overrides *BlockOverrides) OverrideHeader(header *types.Header) error {
+	if overrides.Number != nil {
+		if !overrides.Number.ToInt().IsUint64() {
+			return fmt.Errorf("block number higher than 2^64-1")
+		}
+		header.Number = new(big.Int).Set(overrides.Number.ToInt())
+	}
+	if overrides.Difficulty != nil {
+		header.Difficulty = new(big.Int).Set(overrides.Difficulty.ToInt())
+	}
+	if overrides.Time != nil {
+		header.Time = uint64(*overrides.Time)
+	}
+	if overrides.GasLimit != nil {
+		header.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.Coinbase != nil {
+		header.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Random != nil {
+		header.MixDigest = *overrides.Random
+	}
+	if overrides.BaseFee != nil {
+		if _, err := overrides.OverrideBaseFee(nil); err != nil {
+			return err
+		}
+		header.BaseFee = new(big.Int).Set(overrides.BaseFee.ToInt())
+		header.Eip1559 = true
+	}
+	return nil
+}
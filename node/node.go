@@ -300,6 +300,12 @@ func (n *Node) DataDir() string {
 }
 
 func OpenDatabase(config *nodecfg.Config, logger log.Logger, label kv.Label) (kv.RwDB, error) {
+	return OpenDatabaseWithTables(config, logger, label, nil)
+}
+
+// OpenDatabaseWithTables - same as OpenDatabase, but the database also has the custom tables, in addition to the default ones.
+// Custom tables are created if they don't exist.
+func OpenDatabaseWithTables(config *nodecfg.Config, logger log.Logger, label kv.Label, customTables kv.TableCfg) (kv.RwDB, error) {
 	var name string
 	switch label {
 	case kv.ChainDB:
@@ -311,6 +317,9 @@ func OpenDatabase(config *nodecfg.Config, logger log.Logger, label kv.Label) (kv
 	}
 	var db kv.RwDB
 	if config.Dirs.DataDir == "" {
+		if len(customTables) > 0 {
+			return mdbx.NewMDBX(logger).InMem().WithTableCfg(withCustomTables(customTables)).Open()
+		}
 		db = memdb.New()
 		return db, nil
 	}
@@ -328,6 +337,9 @@ func OpenDatabase(config *nodecfg.Config, logger log.Logger, label kv.Label) (kv
 		} else {
 			opts = opts.GrowthStep(16 * datasize.MB)
 		}
+		if len(customTables) > 0 {
+			opts = opts.WithTableCfg(withCustomTables(customTables))
+		}
 		return opts.Open()
 	}
 	var err error
@@ -370,6 +382,20 @@ func OpenDatabase(config *nodecfg.Config, logger log.Logger, label kv.Label) (kv
 	return db, nil
 }
 
+// withCustomTables adds the custom tables to the default tables of the database
+func withCustomTables(customTables kv.TableCfg) mdbx.TableCfgFunc {
+	return func(defaultBuckets kv.TableCfg) kv.TableCfg {
+		tables := make(kv.TableCfg, len(defaultBuckets)+len(customTables))
+		for name, cfg := range defaultBuckets {
+			tables[name] = cfg
+		}
+		for name, cfg := range customTables {
+			tables[name] = cfg
+		}
+		return tables
+	}
+}
+
 // ResolvePath returns the absolute path of a resource in the instance directory.
 func (n *Node) ResolvePath(x string) string {
 	return n.config.ResolvePath(x)
@@ -180,6 +180,44 @@ func TestNodeCloseClosesDB(t *testing.T) {
 	//}
 }
 
+// This test checks that custom tables are created and keep their content between restarts.
+func TestOpenDatabaseWithTables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fix me on win please")
+	}
+
+	stack, err := New(testNodeConfig(t))
+	require.NoError(t, err)
+	defer stack.Close()
+
+	const customTable = "org.example.CustomTable"
+	customTables := kv.TableCfg{customTable: {Flags: kv.DupSort}}
+	db, err := OpenDatabaseWithTables(stack.Config(), log.New(), kv.ChainDB, customTables)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(context.Background(), func(tx kv.RwTx) error {
+		if err := tx.Put(customTable, []byte("k"), []byte("v1")); err != nil {
+			return err
+		}
+		return tx.Put(customTable, []byte("k"), []byte("v2"))
+	}))
+	db.Close()
+
+	db, err = OpenDatabaseWithTables(stack.Config(), log.New(), kv.ChainDB, customTables)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.View(context.Background(), func(tx kv.Tx) error {
+		var values []string
+		if err := tx.ForEach(customTable, nil, func(k, v []byte) error {
+			values = append(values, string(v))
+			return nil
+		}); err != nil {
+			return err
+		}
+		require.Equal(t, []string{"v1", "v2"}, values)
+		return nil
+	}))
+}
+
 // This test checks that OpenDatabase can be used from within a Lifecycle Start method.
 func TestNodeOpenDatabaseFromLifecycleStart(t *testing.T) {
 	if runtime.GOOS == "windows" {
@@ -142,6 +142,9 @@ type Config struct {
 	// eth/66, eth/67, etc
 	ProtocolVersion uint
 
+	// If ServeSnap is set, snap/1 protocol runs alongside eth
+	ServeSnap bool
+
 	SentryAddr []string
 
 	// If set to a non-nil value, the given NAT port mapper
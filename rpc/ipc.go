@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"context"
 	"net"
 
 	"github.com/ledgerwatch/erigon/p2p/netutil"
@@ -37,3 +38,15 @@ func (s *Server) ServeListener(l net.Listener) error {
 		go s.ServeCodec(NewCodec(conn), 0)
 	}
 }
+
+// DialIPC creates a new client that connects to the JSON-RPC server listening on the
+// unix domain socket at endpoint.
+func DialIPC(ctx context.Context, endpoint string) (*Client, error) {
+	return newClient(ctx, func(ctx context.Context) (ServerCodec, error) {
+		conn, err := new(net.Dialer).DialContext(ctx, "unix", endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return NewCodec(conn), nil
+	})
+}
@@ -99,6 +99,15 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	c.Close()
 }
 
+type publicTransportKey struct{}
+
+// IsPublicTransport reports whether the request of ctx came in over HTTP or WebSocket. It is false for
+// IPC and in-process calls, which can only be made from the same machine.
+func IsPublicTransport(ctx context.Context) bool {
+	public, _ := ctx.Value(publicTransportKey{}).(bool)
+	return public
+}
+
 // serveSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
 // this mode.
@@ -19,9 +19,11 @@ package rpc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"sort"
@@ -166,3 +168,52 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+type transportService struct{}
+
+func (s *transportService) Public(ctx context.Context) bool {
+	return IsPublicTransport(ctx)
+}
+
+// This test checks that methods can tell HTTP and WebSocket requests apart from in-process calls.
+func TestServerPublicTransport(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("transport", new(transportService)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}, nil, false))
+	defer wssrv.Close()
+
+	httpClient, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal("can't dial:", err)
+	}
+	defer httpClient.Close()
+	wsClient, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(wssrv.URL, "http:"), "")
+	if err != nil {
+		t.Fatal("can't dial:", err)
+	}
+	defer wsClient.Close()
+	inProcClient := DialInProc(server)
+	defer inProcClient.Close()
+
+	for name, tc := range map[string]struct {
+		client *Client
+		public bool
+	}{
+		"http":   {httpClient, true},
+		"ws":     {wsClient, true},
+		"inproc": {inProcClient, false},
+	} {
+		var public bool
+		if err := tc.client.Call(&public, "transport_public"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if public != tc.public {
+			t.Errorf("%s: public transport %t, want %t", name, public, tc.public)
+		}
+	}
+}
@@ -89,6 +89,8 @@ type stTransaction struct {
 	GasLimit             []uint64            `json:"gasLimit"`
 	Value                []string            `json:"value"`
 	PrivateKey           []byte              `json:"secretKey"`
+	BlobVersionedHashes  []common.Hash       `json:"blobVersionedHashes,omitempty"`
+	MaxFeePerBlobGas     *big.Int            `json:"maxFeePerBlobGas,omitempty"`
 }
 
 type stTransactionMarshaling struct {
@@ -102,28 +104,32 @@ type stTransactionMarshaling struct {
 	Data                 []string              `json:"data"`
 	Value                []string              `json:"value"`
 	AccessLists          []*types.AccessList   `json:"accessLists,omitempty"`
+	BlobVersionedHashes  []common.Hash         `json:"blobVersionedHashes,omitempty"`
+	MaxFeePerBlobGas     *math.HexOrDecimal256 `json:"maxFeePerBlobGas,omitempty"`
 }
 
 //go:generate gencodec -type stEnv -field-override stEnvMarshaling -out gen_stenv.go
 
 type stEnv struct {
-	Coinbase   common.Address `json:"currentCoinbase"   gencodec:"required"`
-	Difficulty *big.Int       `json:"currentDifficulty" gencodec:"required"`
-	Random     *big.Int       `json:"currentRandom"     gencodec:"optional"`
-	GasLimit   uint64         `json:"currentGasLimit"   gencodec:"required"`
-	Number     uint64         `json:"currentNumber"     gencodec:"required"`
-	Timestamp  uint64         `json:"currentTimestamp"  gencodec:"required"`
-	BaseFee    *big.Int       `json:"currentBaseFee"    gencodec:"optional"`
+	Coinbase      common.Address `json:"currentCoinbase"      gencodec:"required"`
+	Difficulty    *big.Int       `json:"currentDifficulty"    gencodec:"required"`
+	Random        *big.Int       `json:"currentRandom"        gencodec:"optional"`
+	GasLimit      uint64         `json:"currentGasLimit"      gencodec:"required"`
+	Number        uint64         `json:"currentNumber"        gencodec:"required"`
+	Timestamp     uint64         `json:"currentTimestamp"     gencodec:"required"`
+	BaseFee       *big.Int       `json:"currentBaseFee"       gencodec:"optional"`
+	ExcessBlobGas *uint64        `json:"currentExcessBlobGas" gencodec:"optional"`
 }
 
 type stEnvMarshaling struct {
-	Coinbase   common.UnprefixedAddress
-	Difficulty *math.HexOrDecimal256
-	Random     *math.HexOrDecimal256
-	GasLimit   math.HexOrDecimal64
-	Number     math.HexOrDecimal64
-	Timestamp  math.HexOrDecimal64
-	BaseFee    *math.HexOrDecimal256
+	Coinbase      common.UnprefixedAddress
+	Difficulty    *math.HexOrDecimal256
+	Random        *math.HexOrDecimal256
+	GasLimit      math.HexOrDecimal64
+	Number        math.HexOrDecimal64
+	Timestamp     math.HexOrDecimal64
+	BaseFee       *math.HexOrDecimal256
+	ExcessBlobGas *math.HexOrDecimal64
 }
 
 // GetChainConfig takes a fork definition and returns a chain config.
@@ -241,6 +247,13 @@ func (t *StateTest) RunNoVerify(rules *params.Rules, tx kv.RwTx, subtest StateSu
 		rnd := common.BigToHash(t.json.Env.Random)
 		context.PrevRanDao = &rnd
 	}
+	if config.IsCancun(0) {
+		// The price of blob gas depends on the excess blob gas of the block (EIP-4844)
+		context.ExcessBlobGas = t.json.Env.ExcessBlobGas
+		if context.ExcessBlobGas == nil {
+			context.ExcessBlobGas = new(uint64)
+		}
+	}
 	evm := vm.NewEVM(context, txContext, statedb, config, vmconfig)
 
 	// Execute the message.
@@ -461,6 +474,16 @@ func toMessage(tx stTransactionMarshaling, ps stPostState, baseFee *big.Int) (co
 		data,
 		accessList,
 		false)
+	if tx.BlobVersionedHashes != nil {
+		if tx.MaxFeePerBlobGas == nil {
+			return nil, fmt.Errorf("no max fee per blob gas provided")
+		}
+		maxFeePerBlobGas, overflow := uint256.FromBig((*big.Int)(tx.MaxFeePerBlobGas))
+		if overflow {
+			return nil, fmt.Errorf("invalid max fee per blob gas (overflowed) %v", tx.MaxFeePerBlobGas)
+		}
+		msg.SetBlobFields(maxFeePerBlobGas, tx.BlobVersionedHashes)
+	}
 
 	return msg, nil
 }
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	libcommon "github.com/ledgerwatch/erigon-lib/common"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/erigon/cmd/utils"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/eth/ethconfig"
+	"github.com/ledgerwatch/erigon/node/nodecfg/datadir"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/erigon/turbo/services"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/ledgerwatch/log/v3"
+	"github.com/urfave/cli"
+)
+
+var exportCommand = cli.Command{
+	Action:    MigrateFlags(exportChain),
+	Name:      "export",
+	Usage:     "Export blockchain into file",
+	ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+	},
+	Category: "BLOCKCHAIN COMMANDS",
+	Description: `
+Requires a first argument of the file to write to.
+Optional second and third arguments control the first and
+last block to write. In this mode, the file will be appended
+if already existing. If the file ends with .gz, the output will
+be gzipped.
+
+Blocks are read from the database and from the snapshots, so the
+command also works on nodes which already retired old blocks. The
+output can be read back by the import command.`,
+}
+
+func exportChain(cliCtx *cli.Context) error {
+	if len(cliCtx.Args()) != 1 && len(cliCtx.Args()) != 3 {
+		utils.Fatalf("This command requires one or three arguments.")
+	}
+	ctx, cancel := libcommon.RootContext()
+	defer cancel()
+
+	dirs := datadir.New(cliCtx.GlobalString(utils.DataDirFlag.Name))
+	db := mdbx.NewMDBX(log.New()).Label(kv.ChainDB).Path(dirs.Chaindata).Readonly().MustOpen()
+	defer db.Close()
+
+	snapshots := snapshotsync.NewRoSnapshots(ethconfig.NewSnapCfg(true, false, false), dirs.Snap)
+	if err := snapshots.ReopenWithDB(db); err != nil {
+		return err
+	}
+	defer snapshots.Close()
+	blockReader := snapshotsync.NewBlockReaderWithSnapshots(snapshots)
+
+	var first, last uint64
+	if len(cliCtx.Args()) == 3 {
+		var err error
+		if first, err = strconv.ParseUint(cliCtx.Args().Get(1), 10, 64); err != nil {
+			return fmt.Errorf("invalid first block number: %w", err)
+		}
+		if last, err = strconv.ParseUint(cliCtx.Args().Get(2), 10, 64); err != nil {
+			return fmt.Errorf("invalid last block number: %w", err)
+		}
+	} else if err := db.View(ctx, func(tx kv.Tx) error {
+		head := rawdb.ReadCurrentBlockNumber(tx)
+		if head == nil {
+			return fmt.Errorf("head block not found")
+		}
+		last = *head
+		return nil
+	}); err != nil {
+		return err
+	}
+	if first > last {
+		return fmt.Errorf("first block %d is above last block %d", first, last)
+	}
+
+	start := time.Now()
+	appendFile := len(cliCtx.Args()) == 3
+	if err := ExportChain(ctx, db, blockReader, cliCtx.Args().First(), first, last, appendFile); err != nil {
+		return err
+	}
+	log.Info("Export done", "took", time.Since(start))
+	return nil
+}
+
+// ExportChain writes the canonical blocks [first, last] into the given file as
+// a sequence of RLP-encoded blocks, the format read by ImportChain. The file is
+// gzipped if its name ends with .gz.
+func ExportChain(ctx context.Context, db kv.RoDB, blockReader services.FullBlockReader, fn string, first, last uint64, appendFile bool) error {
+	log.Info("Exporting blockchain", "file", fn, "first", first, "last", last, "append", appendFile)
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendFile {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	fh, err := os.OpenFile(fn, flags, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		gzw := gzip.NewWriter(writer)
+		defer gzw.Close()
+		writer = gzw
+	}
+
+	if err := exportBlocks(ctx, db, blockReader, writer, first, last); err != nil {
+		return err
+	}
+	if gzw, ok := writer.(*gzip.Writer); ok {
+		if err := gzw.Close(); err != nil {
+			return err
+		}
+	}
+	return fh.Close()
+}
+
+func exportBlocks(ctx context.Context, db kv.RoDB, blockReader services.FullBlockReader, writer io.Writer, first, last uint64) error {
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	return db.View(ctx, func(tx kv.Tx) error {
+		for blockNum := first; blockNum <= last; blockNum++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-logEvery.C:
+				log.Info("Exporting blocks", "block", blockNum, "last", last)
+			default:
+			}
+
+			hash, err := blockReader.CanonicalHash(ctx, tx, blockNum)
+			if err != nil {
+				return err
+			}
+			if hash == (common.Hash{}) {
+				return fmt.Errorf("canonical hash of block %d not found", blockNum)
+			}
+			block, _, err := blockReader.BlockWithSenders(ctx, tx, hash, blockNum)
+			if err != nil {
+				return err
+			}
+			if block == nil {
+				return fmt.Errorf("block %d (%x) not found", blockNum, hash)
+			}
+			if err := rlp.Encode(writer, block); err != nil {
+				return fmt.Errorf("encoding block %d: %w", blockNum, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/ledgerwatch/erigon/turbo/stages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportChain(t *testing.T) {
+	ctx := context.Background()
+	m := stages.Mock(t)
+	signer := types.LatestSigner(m.ChainConfig)
+	from := crypto.PubkeyToAddress(m.Key.PublicKey)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 10, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(from), common.Address{2}, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, m.Key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	fn := filepath.Join(t.TempDir(), "chain.gz")
+	require.NoError(t, ExportChain(ctx, m.DB, snapshotsync.NewBlockReader(), fn, 0, chain.TopBlock.NumberU64(), false))
+
+	// the other node has the same genesis, and nothing else
+	m2 := stages.Mock(t)
+	noInterrupt := func() bool { return false }
+	require.NoError(t, importChainFile(m2.DB, fn, noInterrupt, m2.InsertChain))
+	headHash := func(db kv.RoDB) (hash common.Hash) {
+		require.NoError(t, db.View(ctx, func(tx kv.Tx) error {
+			hash = rawdb.ReadHeadBlockHash(tx)
+			return nil
+		}))
+		return hash
+	}
+	require.Equal(t, chain.TopBlock.Hash(), headHash(m.DB))
+	require.Equal(t, headHash(m.DB), headHash(m2.DB))
+
+	// importing the same file again finds all the blocks in the database
+	require.NoError(t, importChainFile(m2.DB, fn, noInterrupt, func(*core.ChainPack) error {
+		t.Fatal("no blocks are missing")
+		return nil
+	}))
+}
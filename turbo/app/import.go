@@ -95,6 +95,13 @@ func ImportChain(ethereum *eth.Ethereum, chainDB kv.RwDB, fn string) error {
 		}
 	}
 
+	return importChainFile(chainDB, fn, checkInterrupt, func(chain *core.ChainPack) error {
+		return InsertChain(ethereum, chain)
+	})
+}
+
+// importChainFile reads the blocks written by ExportChain and inserts the ones missing in chainDB in batches
+func importChainFile(chainDB kv.RwDB, fn string, checkInterrupt func() bool, insertChain func(*core.ChainPack) error) error {
 	log.Info("Importing blockchain", "file", fn)
 
 	// Open the file handle and potentially unwrap the gzip stream
@@ -152,11 +159,15 @@ func ImportChain(ethereum *eth.Ethereum, chainDB kv.RwDB, fn string) error {
 
 		// RLP decoding worked, try to insert into chain:
 		missingChain := &core.ChainPack{
+			Headers:  make([]*types.Header, len(missing)),
 			Blocks:   missing,
 			TopBlock: missing[len(missing)-1],
 		}
+		for i, block := range missing {
+			missingChain.Headers[i] = block.Header()
+		}
 
-		if err := InsertChain(ethereum, missingChain); err != nil {
+		if err := insertChain(missingChain); err != nil {
 			return err
 		}
 	}
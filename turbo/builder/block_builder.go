@@ -9,14 +9,14 @@ import (
 	"github.com/ledgerwatch/log/v3"
 )
 
-type BlockBuilderFunc func(param *core.BlockBuilderParameters, interrupt *int32) (*types.Block, error)
+type BlockBuilderFunc func(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error)
 
 // BlockBuilder wraps a goroutine that builds Proof-of-Stake payloads (PoS "mining")
 type BlockBuilder struct {
 	emptyHeader *types.Header
 	interrupt   int32
 	syncCond    *sync.Cond
-	block       *types.Block
+	block       *types.BlockWithReceipts
 	err         error
 }
 
@@ -38,7 +38,7 @@ func NewBlockBuilder(build BlockBuilderFunc, param *core.BlockBuilderParameters,
 	return b
 }
 
-func (b *BlockBuilder) Stop() *types.Block {
+func (b *BlockBuilder) Stop() *types.BlockWithReceipts {
 	atomic.StoreInt32(&b.interrupt, 1)
 
 	b.syncCond.L.Lock()
@@ -49,13 +49,13 @@ func (b *BlockBuilder) Stop() *types.Block {
 
 	if b.err != nil {
 		log.Error("BlockBuilder", "err", b.err)
-		return types.NewBlock(b.emptyHeader, nil, nil, nil)
+		return &types.BlockWithReceipts{Block: types.NewBlock(b.emptyHeader, nil, nil, nil)}
 	}
 
 	return b.block
 }
 
-func (b *BlockBuilder) Block() *types.Block {
+func (b *BlockBuilder) Block() *types.BlockWithReceipts {
 	b.syncCond.L.Lock()
 	defer b.syncCond.L.Unlock()
 
@@ -34,6 +34,7 @@ var DefaultFlags = []cli.Flag{
 	PruneCallTracesBeforeFlag,
 	BatchSizeFlag,
 	BlockDownloaderWindowFlag,
+	DownloadReceiptsFlag,
 	DatabaseVerbosityFlag,
 	PrivateApiAddr,
 	PrivateApiRateLimit,
@@ -60,6 +61,9 @@ var DefaultFlags = []cli.Flag{
 	utils.WSEnabledFlag,
 	utils.WsCompressionFlag,
 	utils.HTTPTraceFlag,
+	utils.GraphQLEnabledFlag,
+	utils.StarknetGrpcAddressFlag,
+	utils.RpcFiltersDBFlag,
 	utils.StateCacheFlag,
 	utils.RpcBatchConcurrencyFlag,
 	utils.RpcStreamingDisableFlag,
@@ -79,17 +83,20 @@ var DefaultFlags = []cli.Flag{
 	EvmCallTimeoutFlag,
 
 	utils.SnapKeepBlocksFlag,
+	utils.SnapPruneReceiptsFlag,
 	utils.SnapStopFlag,
 	utils.DbPageSizeFlag,
 	utils.TorrentPortFlag,
 	utils.TorrentMaxPeersFlag,
 	utils.TorrentConnsPerFileFlag,
 	utils.TorrentDownloadSlotsFlag,
+	utils.TorrentWebSeedsFlag,
 	utils.TorrentUploadRateFlag,
 	utils.TorrentDownloadRateFlag,
 	utils.TorrentVerbosityFlag,
 	utils.ListenPortFlag,
 	utils.P2pProtocolVersionFlag,
+	utils.P2pServeSnapFlag,
 	utils.NATFlag,
 	utils.NoDiscoverFlag,
 	utils.DiscoveryV5Flag,
@@ -120,6 +127,7 @@ var DefaultFlags = []cli.Flag{
 	utils.CliqueSnapshotInmemorySignaturesFlag,
 	utils.CliqueDataDirFlag,
 	utils.EnabledIssuance,
+	utils.VerkleTrieFlag,
 	utils.MiningEnabledFlag,
 	utils.ProposingDisableFlag,
 	utils.MinerNotifyFlag,
@@ -44,6 +44,10 @@ var (
 		Usage: "Outstanding limit of block bodies being downloaded",
 		Value: ethconfig.Defaults.Sync.BlockDownloaderWindow,
 	}
+	DownloadReceiptsFlag = cli.BoolFlag{
+		Name:  "sync.receipts",
+		Usage: "Download from peers the receipts which are not in the database, e.g. pruned before with --prune=r, and stop pruning them (not supported with history.v2)",
+	}
 
 	PrivateApiAddr = cli.StringFlag{
 		Name:  "private.api.addr",
@@ -231,6 +235,7 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config) {
 
 	cfg.StateStream = !ctx.GlobalBool(StateStreamDisableFlag.Name)
 	cfg.Sync.BlockDownloaderWindow = ctx.GlobalInt(BlockDownloaderWindowFlag.Name)
+	cfg.Sync.DownloadReceipts = ctx.GlobalBool(DownloadReceiptsFlag.Name)
 
 	if ctx.GlobalString(SyncLoopThrottleFlag.Name) != "" {
 		syncLoopThrottle, err := time.ParseDuration(ctx.GlobalString(SyncLoopThrottleFlag.Name))
@@ -342,6 +347,7 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config) {
 		AuthRpcPort:              ctx.GlobalInt(utils.AuthRpcPort.Name),
 		JWTSecretPath:            jwtSecretPath,
 		TraceRequests:            ctx.GlobalBool(utils.HTTPTraceFlag.Name),
+		GraphQLEnabled:           ctx.GlobalBool(utils.GraphQLEnabledFlag.Name),
 		HttpCORSDomain:           strings.Split(ctx.GlobalString(utils.HTTPCORSDomainFlag.Name), ","),
 		HttpVirtualHost:          strings.Split(ctx.GlobalString(utils.HTTPVirtualHostsFlag.Name), ","),
 		AuthRpcVirtualHost:       strings.Split(ctx.GlobalString(utils.AuthRpcVirtualHostsFlag.Name), ","),
@@ -367,7 +373,9 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config) {
 		MaxTraces:            ctx.GlobalUint64(utils.TraceMaxtracesFlag.Name),
 		TraceCompatibility:   ctx.GlobalBool(utils.RpcTraceCompatFlag.Name),
 
-		TxPoolApiAddr: ctx.GlobalString(utils.TxpoolApiAddrFlag.Name),
+		TxPoolApiAddr:       ctx.GlobalString(utils.TxpoolApiAddrFlag.Name),
+		StarknetGRPCAddress: ctx.GlobalString(utils.StarknetGrpcAddressFlag.Name),
+		FiltersDBPath:       ctx.GlobalString(utils.RpcFiltersDBFlag.Name),
 
 		StateCache: kvcache.DefaultCoherentConfig,
 	}
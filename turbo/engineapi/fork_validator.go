@@ -289,6 +289,7 @@ func (fv *ForkValidator) validateAndStorePayload(tx kv.RwTx, header *types.Heade
 		}
 		fv.sideForksBlock[header.Hash()] = forkSegment{header, &types.RawBody{
 			Transactions: encodedTxs,
+			Withdrawals:  bodyWithTxs.Withdrawals,
 		}}
 	} else {
 		fv.sideForksBlock[header.Hash()] = forkSegment{header, body}
@@ -2,7 +2,6 @@
 package node
 
 import (
-	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/cmd/utils"
 	"github.com/ledgerwatch/erigon/eth"
 	"github.com/ledgerwatch/erigon/eth/ethconfig"
@@ -41,15 +40,6 @@ func (eri *ErigonNode) run() {
 	// see cmd/geth/daemon.go#startNode for full implementation
 }
 
-// Params contains optional parameters for creating a node.
-// * GitCommit is a commit from which then node was built.
-// * CustomBuckets is a `map[string]dbutils.TableCfgItem`, that contains bucket name and its properties.
-//
-// NB: You have to declare your custom buckets here to be able to use them in the app.
-type Params struct {
-	CustomBuckets kv.TableCfg
-}
-
 func NewNodConfigUrfave(ctx *cli.Context) *nodecfg.Config {
 	// If we're running a known preset, log it for convenience.
 	chain := ctx.GlobalString(utils.ChainFlag.Name)
@@ -98,21 +88,20 @@ func NewEthConfigUrfave(ctx *cli.Context, nodeConfig *nodecfg.Config) *ethconfig
 }
 
 // New creates a new `ErigonNode`.
-// * ctx - `*cli.Context` from the main function. Necessary to be able to configure the node based on the command-line flags
-// * sync - `stagedsync.StagedSync`, an instance of staged sync, setup just as needed.
-// * optionalParams - additional parameters for running a node.
+// * nodeConfig, ethConfig - configuration of the node, see NewNodConfigUrfave and NewEthConfigUrfave to build it from the command-line flags
+// * plugins - custom tables, stages of the staged sync and RPC APIs of the app embedding Erigon, see `eth.Plugin`.
 func New(
 	nodeConfig *nodecfg.Config,
 	ethConfig *ethconfig.Config,
 	logger log.Logger,
+	plugins ...eth.Plugin,
 ) (*ErigonNode, error) {
-	//prepareBuckets(optionalParams.CustomBuckets)
 	node, err := node.New(nodeConfig)
 	if err != nil {
 		utils.Fatalf("Failed to create Erigon node: %v", err)
 	}
 
-	ethereum, err := eth.New(node, ethConfig, logger)
+	ethereum, err := eth.New(node, ethConfig, logger, plugins...)
 	if err != nil {
 		return nil, err
 	}
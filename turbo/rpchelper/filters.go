@@ -47,6 +47,7 @@ type Filters struct {
 	logsSubs         *LogsFilterAggregator
 	logsRequestor    atomic.Value
 	onNewSnapshot    func()
+	store            *FilterStore
 
 	storeMu            sync.Mutex
 	logsStores         map[LogsSubID][]*types.Log
@@ -191,6 +192,12 @@ func New(ctx context.Context, ethBackend ApiBackend, txPool txpool.TxpoolClient,
 	return ff
 }
 
+// SetStore enables persistence of polling filters
+func (ff *Filters) SetStore(store *FilterStore) { ff.store = store }
+
+// Store returns nil if polling filters are kept only in memory
+func (ff *Filters) Store() *FilterStore { return ff.store }
+
 func (ff *Filters) LastPendingBlock() *types.Block {
 	ff.mu.RLock()
 	defer ff.mu.RUnlock()
@@ -354,11 +361,20 @@ func (ff *Filters) UnsubscribePendingBlock(id PendingBlockSubID) {
 }
 
 func (ff *Filters) SubscribePendingTxs(out chan []types.Transaction) PendingTxsSubID {
+	id := PendingTxsSubID(generateSubscriptionID())
+	ff.SubscribePendingTxsWithID(id, out)
+	return id
+}
+
+// SubscribePendingTxsWithID - to restore a subscription of a stored filter, false if it's already subscribed
+func (ff *Filters) SubscribePendingTxsWithID(id PendingTxsSubID, out chan []types.Transaction) bool {
 	ff.mu.Lock()
 	defer ff.mu.Unlock()
-	id := PendingTxsSubID(generateSubscriptionID())
+	if _, ok := ff.pendingTxsSubs[id]; ok {
+		return false
+	}
 	ff.pendingTxsSubs[id] = out
-	return id
+	return true
 }
 
 func (ff *Filters) UnsubscribePendingTxs(id PendingTxsSubID) bool {
@@ -0,0 +1,180 @@
+package rpchelper
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/mdbx"
+	"github.com/ledgerwatch/log/v3"
+	mdbx1 "github.com/torquem-ch/mdbx-go/mdbx"
+
+	"github.com/ledgerwatch/erigon/common"
+)
+
+const filtersTable = "RpcFilters" // filter id -> json(StoredFilter)
+
+// StoredFilterTimeout - filters which were not polled for this long are removed. It's longer than the in-memory
+// filters timeout of geth, so that filters survive a restart of rpcdaemon.
+const StoredFilterTimeout = time.Hour
+
+type FilterKind string
+
+const (
+	LogsFilterKind       FilterKind = "logs"
+	BlocksFilterKind     FilterKind = "blocks"
+	PendingTxsFilterKind FilterKind = "pendingTxs"
+)
+
+// StoredFilter is a filter created by eth_newFilter, eth_newBlockFilter or eth_newPendingTransactionFilter
+type StoredFilter struct {
+	Kind      FilterKind       `json:"kind"`
+	Addresses []common.Address `json:"addresses,omitempty"`
+	Topics    [][]common.Hash  `json:"topics,omitempty"`
+	FromBlock *uint64          `json:"fromBlock,omitempty"`
+	ToBlock   *uint64          `json:"toBlock,omitempty"`
+	BlockHash *common.Hash     `json:"blockHash,omitempty"` // logs of this block only
+	Block     uint64           `json:"block"`               // last block delivered by eth_getFilterChanges
+	Hash      common.Hash      `json:"hash"`                // hash of the last delivered block, to detect reorgs
+	LastPoll  int64            `json:"lastPoll"`            // unix time of the last eth_getFilterChanges
+}
+
+// FilterStore keeps polling filters in a local db, so they survive rpcdaemon restarts. Logs and blocks
+// are not buffered, eth_getFilterChanges reads everything after the last delivered block from the chain.
+type FilterStore struct {
+	db kv.RwDB
+
+	locksMu sync.Mutex
+	locks   map[SubscriptionID]*filterLock
+}
+
+type filterLock struct {
+	sync.Mutex
+	refs int
+}
+
+func OpenFilterStore(path string) (*FilterStore, error) {
+	db, err := mdbx.NewMDBX(log.New()).
+		Path(path).
+		WithTableCfg(func(_ kv.TableCfg) kv.TableCfg { return kv.TableCfg{filtersTable: {}} }).
+		MapSize(256 * datasize.MB).
+		GrowthStep(16 * datasize.MB).
+		Flags(func(f uint) uint { return f ^ mdbx1.Durable | mdbx1.SafeNoSync }).
+		SyncPeriod(2 * time.Second).
+		Open()
+	if err != nil {
+		return nil, err
+	}
+	return &FilterStore{db: db, locks: map[SubscriptionID]*filterLock{}}, nil
+}
+
+func (s *FilterStore) Close() { s.db.Close() }
+
+// Lock serialises the polls of the filter, which read the filter, deliver the changes and store the new
+// position. Returns the unlock function.
+func (s *FilterStore) Lock(id SubscriptionID) (unlock func()) {
+	s.locksMu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &filterLock{}
+		s.locks[id] = l
+	}
+	l.refs++
+	s.locksMu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.locksMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, id)
+		}
+		s.locksMu.Unlock()
+	}
+}
+
+// Add stores a new filter and returns its id
+func (s *FilterStore) Add(ctx context.Context, f *StoredFilter) (SubscriptionID, error) {
+	id := generateSubscriptionID()
+	f.LastPoll = time.Now().Unix()
+	v, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	if err = s.db.Update(ctx, func(tx kv.RwTx) error {
+		return tx.Put(filtersTable, []byte(id), v)
+	}); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Put replaces the filter, does nothing if it was uninstalled or removed by Prune meanwhile
+func (s *FilterStore) Put(ctx context.Context, id SubscriptionID, f *StoredFilter) error {
+	v, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(ctx, func(tx kv.RwTx) error {
+		if ok, err := tx.Has(filtersTable, []byte(id)); err != nil || !ok {
+			return err
+		}
+		return tx.Put(filtersTable, []byte(id), v)
+	})
+}
+
+// Get returns nil if there is no filter with the given id
+func (s *FilterStore) Get(ctx context.Context, id SubscriptionID) (f *StoredFilter, err error) {
+	err = s.db.View(ctx, func(tx kv.Tx) error {
+		v, err := tx.GetOne(filtersTable, []byte(id))
+		if err != nil || v == nil {
+			return err
+		}
+		f = &StoredFilter{}
+		return json.Unmarshal(v, f)
+	})
+	return f, err
+}
+
+func (s *FilterStore) Delete(ctx context.Context, id SubscriptionID) (deleted bool, err error) {
+	err = s.db.Update(ctx, func(tx kv.RwTx) error {
+		if deleted, err = tx.Has(filtersTable, []byte(id)); err != nil || !deleted {
+			return err
+		}
+		return tx.Delete(filtersTable, []byte(id))
+	})
+	return deleted, err
+}
+
+// Prune removes the filters which were not polled since the given time and returns their ids
+func (s *FilterStore) Prune(ctx context.Context, before time.Time) (ids []SubscriptionID, err error) {
+	err = s.db.Update(ctx, func(tx kv.RwTx) error {
+		ids = ids[:0]
+		c, err := tx.RwCursor(filtersTable)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		for k, v, err := c.First(); k != nil; k, v, err = c.Next() {
+			if err != nil {
+				return err
+			}
+			var f StoredFilter
+			if err = json.Unmarshal(v, &f); err != nil {
+				return err
+			}
+			if f.LastPoll >= before.Unix() {
+				continue
+			}
+			ids = append(ids, SubscriptionID(k))
+			if err = c.DeleteCurrent(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
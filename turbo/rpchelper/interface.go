@@ -4,7 +4,9 @@ import (
 	"context"
 	"sync/atomic"
 
+	"github.com/holiman/uint256"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
 	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
 	"github.com/ledgerwatch/erigon-lib/kv"
 	"github.com/ledgerwatch/erigon/common"
@@ -27,7 +29,14 @@ type ApiBackend interface {
 	EngineNewPayloadV1(ctx context.Context, payload *types2.ExecutionPayload) (*remote.EnginePayloadStatus, error)
 	EngineForkchoiceUpdatedV1(ctx context.Context, request *remote.EngineForkChoiceUpdatedRequest) (*remote.EngineForkChoiceUpdatedReply, error)
 	EngineGetPayloadV1(ctx context.Context, payloadId uint64) (*types2.ExecutionPayload, error)
+	EngineNewPayloadV2(ctx context.Context, payload *types2.ExecutionPayload, withdrawals []*types.Withdrawal) (*remote.EnginePayloadStatus, error)
+	EngineForkchoiceUpdatedV2(ctx context.Context, request *remote.EngineForkChoiceUpdatedRequest, withdrawals []*types.Withdrawal) (*remote.EngineForkChoiceUpdatedReply, error)
+	EngineGetPayloadV2(ctx context.Context, payloadId uint64) (payload *types2.ExecutionPayload, withdrawals []*types.Withdrawal, blockValue *uint256.Int, err error)
 	NodeInfo(ctx context.Context, limit uint32) ([]p2p.NodeInfo, error)
 	Peers(ctx context.Context) ([]*p2p.PeerInfo, error)
+	AddPeer(ctx context.Context, url string) (bool, error)
+	RemovePeer(ctx context.Context, url string) (bool, error)
+	AddTrustedPeer(ctx context.Context, url string) (bool, error)
+	PeerEvents(ctx context.Context, cb func(*sentry.PeerEvent)) error
 	PendingBlock(ctx context.Context) (*types.Block, error)
 }
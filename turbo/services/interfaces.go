@@ -37,6 +37,11 @@ type TxnReader interface {
 	TxnLookup(ctx context.Context, tx kv.Getter, txnHash common.Hash) (uint64, bool, error)
 	TxnByIdxInBlock(ctx context.Context, tx kv.Getter, blockNum uint64, i int) (txn types.Transaction, err error)
 }
+type ReceiptReader interface {
+	// RawReceipts returns receipts of the canonical block without derived fields, nil if they are pruned
+	RawReceipts(ctx context.Context, tx kv.Getter, blockHeight uint64) (types.Receipts, error)
+}
+
 type HeaderAndCanonicalReader interface {
 	HeaderReader
 	CanonicalReader
@@ -54,4 +59,5 @@ type FullBlockReader interface {
 	HeaderReader
 	TxnReader
 	CanonicalReader
+	ReceiptReader
 }
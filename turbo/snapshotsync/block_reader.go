@@ -115,6 +115,10 @@ func (back *BlockReader) TxnByIdxInBlock(ctx context.Context, tx kv.Getter, bloc
 	return txn, nil
 }
 
+func (back *BlockReader) RawReceipts(ctx context.Context, tx kv.Getter, blockHeight uint64) (types.Receipts, error) {
+	return rawdb.ReadRawReceipts(tx, blockHeight), nil
+}
+
 type RemoteBlockReader struct {
 	client remote.ETHBACKENDClient
 }
@@ -232,6 +236,10 @@ func (back *RemoteBlockReader) BodyRlp(ctx context.Context, tx kv.Getter, hash c
 	return bodyRlp, nil
 }
 
+func (back *RemoteBlockReader) RawReceipts(ctx context.Context, tx kv.Getter, blockHeight uint64) (types.Receipts, error) {
+	return rawdb.ReadRawReceipts(tx, blockHeight), nil
+}
+
 // BlockReaderWithSnapshots can read blocks from db and snapshots
 type BlockReaderWithSnapshots struct {
 	sn *RoSnapshots
@@ -454,7 +462,7 @@ func (back *BlockReaderWithSnapshots) BlockWithSenders(ctx context.Context, tx k
 		}
 		if ok && b != nil {
 			if txsAmount == 0 {
-				block = types.NewBlockFromStorage(hash, h, nil, b.Uncles)
+				block = types.NewBlockFromStorage(hash, h, nil, b.Uncles, b.Withdrawals)
 				if len(senders) != block.Transactions().Len() {
 					return block, senders, nil // no senders is fine - will recover them on the fly
 				}
@@ -474,7 +482,7 @@ func (back *BlockReaderWithSnapshots) BlockWithSenders(ctx context.Context, tx k
 				return nil, nil, err
 			}
 			if ok {
-				block = types.NewBlockFromStorage(hash, h, txs, b.Uncles)
+				block = types.NewBlockFromStorage(hash, h, txs, b.Uncles, b.Withdrawals)
 				if len(senders) != block.Transactions().Len() {
 					return block, senders, nil // no senders is fine - will recover them on the fly
 				}
@@ -563,6 +571,7 @@ func (back *BlockReaderWithSnapshots) bodyFromSnapshot(blockHeight uint64, sn *B
 
 	body := new(types.Body)
 	body.Uncles = b.Uncles
+	body.Withdrawals = b.Withdrawals
 	var txsAmount uint32
 	if b.TxAmount >= 2 {
 		txsAmount = b.TxAmount - 2
@@ -761,6 +770,53 @@ func (back *BlockReaderWithSnapshots) TxnByIdxInBlock(ctx context.Context, tx kv
 	return txn, nil
 }
 
+func (back *BlockReaderWithSnapshots) receiptsFromSnapshot(blockHeight uint64, sn *ReceiptSegment, buf []byte) (types.Receipts, []byte, error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panic(fmt.Errorf("%+v, snapshot: %d-%d, trace: %s", rec, sn.ranges.from, sn.ranges.to, dbg.Stack()))
+		}
+	}() // avoid crash because Erigon's core does many things
+
+	if sn.idxReceiptNumber == nil {
+		return nil, buf, nil
+	}
+	receiptsOffset := sn.idxReceiptNumber.OrdinalLookup(blockHeight - sn.idxReceiptNumber.BaseDataID())
+
+	gg := sn.seg.MakeGetter()
+	gg.Reset(receiptsOffset)
+	if !gg.HasNext() {
+		return nil, buf, nil
+	}
+	buf, _ = gg.Next(buf[:0])
+	var stored types.ReceiptsForStorage
+	if err := rlp.DecodeBytes(buf, &stored); err != nil {
+		return nil, buf, err
+	}
+	receipts := make(types.Receipts, len(stored))
+	for i, r := range stored {
+		receipts[i] = (*types.Receipt)(r)
+	}
+	return receipts, buf, nil
+}
+
+// RawReceipts - receipts of blocks frozen in snapshots, falls back to db
+func (back *BlockReaderWithSnapshots) RawReceipts(ctx context.Context, tx kv.Getter, blockHeight uint64) (receipts types.Receipts, err error) {
+	ok, err := back.sn.ViewReceipts(blockHeight, func(segment *ReceiptSegment) error {
+		receipts, _, err = back.receiptsFromSnapshot(blockHeight, segment, nil)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ok && receipts != nil {
+		return receipts, nil
+	}
+	return rawdb.ReadRawReceipts(tx, blockHeight), nil
+}
+
 // TxnLookup - find blockNumber and txnID by txnHash
 func (back *BlockReaderWithSnapshots) TxnLookup(ctx context.Context, tx kv.Getter, txnHash common.Hash) (uint64, bool, error) {
 	n, err := rawdb.ReadTxLookupEntry(tx, txnHash)
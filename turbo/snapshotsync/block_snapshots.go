@@ -69,6 +69,12 @@ type TxnSegment struct {
 	ranges              Range
 }
 
+type ReceiptSegment struct {
+	seg              *compress.Decompressor // value: rlp(types.ReceiptsForStorage)
+	idxReceiptNumber *recsplit.Index        // block_num_u64     -> receipts_segment_offset
+	ranges           Range
+}
+
 func (sn *HeaderSegment) closeIdx() {
 	if sn.idxHeaderHash != nil {
 		sn.idxHeaderHash.Close()
@@ -255,6 +261,59 @@ func (sn *TxnSegment) reopenIdxIfNeed(dir string, optimistic bool) (err error) {
 	return nil
 }
 
+func (sn *ReceiptSegment) closeSeg() {
+	if sn.seg != nil {
+		sn.seg.Close()
+		sn.seg = nil
+	}
+}
+func (sn *ReceiptSegment) closeIdx() {
+	if sn.idxReceiptNumber != nil {
+		sn.idxReceiptNumber.Close()
+		sn.idxReceiptNumber = nil
+	}
+}
+func (sn *ReceiptSegment) close() {
+	sn.closeSeg()
+	sn.closeIdx()
+}
+
+func (sn *ReceiptSegment) reopenSeg(dir string) (err error) {
+	sn.closeSeg()
+	fileName := snap.SegmentFileName(sn.ranges.from, sn.ranges.to, snap.Receipts)
+	sn.seg, err = compress.NewDecompressor(path.Join(dir, fileName))
+	if err != nil {
+		return fmt.Errorf("%w, fileName: %s", err, fileName)
+	}
+	return nil
+}
+func (sn *ReceiptSegment) reopenIdxIfNeed(dir string, optimistic bool) (err error) {
+	if sn.idxReceiptNumber != nil {
+		return nil
+	}
+	err = sn.reopenIdx(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			if optimistic {
+				log.Warn("[snapshots] open index", "err", err)
+			} else {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (sn *ReceiptSegment) reopenIdx(dir string) (err error) {
+	sn.closeIdx()
+	fileName := snap.IdxFileName(sn.ranges.from, sn.ranges.to, snap.Receipts.String())
+	sn.idxReceiptNumber, err = recsplit.OpenIndex(path.Join(dir, fileName))
+	if err != nil {
+		return fmt.Errorf("%w, fileName: %s", err, fileName)
+	}
+	return nil
+}
+
 type headerSegments struct {
 	lock     sync.RWMutex
 	segments []*HeaderSegment
@@ -321,17 +380,41 @@ func (s *txnSegments) ViewSegment(blockNum uint64, f func(*TxnSegment) error) (f
 	return false, nil
 }
 
+type receiptSegments struct {
+	lock     sync.RWMutex
+	segments []*ReceiptSegment
+}
+
+func (s *receiptSegments) View(f func([]*ReceiptSegment) error) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return f(s.segments)
+}
+func (s *receiptSegments) ViewSegment(blockNum uint64, f func(*ReceiptSegment) error) (found bool, err error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, seg := range s.segments {
+		if !(blockNum >= seg.ranges.from && blockNum < seg.ranges.to) {
+			continue
+		}
+		return true, f(seg)
+	}
+	return false, nil
+}
+
 type RoSnapshots struct {
 	indicesReady  atomic.Bool
 	segmentsReady atomic.Bool
 
-	Headers *headerSegments
-	Bodies  *bodySegments
-	Txs     *txnSegments
+	Headers  *headerSegments
+	Bodies   *bodySegments
+	Txs      *txnSegments
+	Receipts *receiptSegments
 
 	dir         string
 	segmentsMax atomic.Uint64 // all types of .seg files are available - up to this number
 	idxMax      atomic.Uint64 // all types of .idx files are available - up to this number
+	receiptsMax atomic.Uint64 // receipts .seg and .idx files are available - up to this number
 	cfg         ethconfig.Snapshot
 }
 
@@ -341,7 +424,7 @@ type RoSnapshots struct {
 //   - gaps are not allowed
 //   - segment have [from:to) semantic
 func NewRoSnapshots(cfg ethconfig.Snapshot, snapDir string) *RoSnapshots {
-	return &RoSnapshots{dir: snapDir, cfg: cfg, Headers: &headerSegments{}, Bodies: &bodySegments{}, Txs: &txnSegments{}}
+	return &RoSnapshots{dir: snapDir, cfg: cfg, Headers: &headerSegments{}, Bodies: &bodySegments{}, Txs: &txnSegments{}, Receipts: &receiptSegments{}}
 }
 
 func (s *RoSnapshots) Cfg() ethconfig.Snapshot { return s.cfg }
@@ -360,6 +443,11 @@ func (s *RoSnapshots) LogStat() {
 		"alloc", common2.ByteCount(m.Alloc), "sys", common2.ByteCount(m.Sys))
 }
 
+// ReceiptsAvailable - receipts of all blocks up to this number are in snapshots, 0 if there are no receipt segments
+func (s *RoSnapshots) ReceiptsAvailable() uint64 {
+	return cmp.Min(s.receiptsMax.Load(), s.BlocksAvailable())
+}
+
 func (s *RoSnapshots) EnsureExpectedBlocksAreAvailable(cfg *snapcfg.Cfg) error {
 	if s.BlocksAvailable() < cfg.ExpectBlocks {
 		return fmt.Errorf("app must wait until all expected snapshots are available. Expected: %d, Available: %d", cfg.ExpectBlocks, s.BlocksAvailable())
@@ -390,6 +478,19 @@ func (s *RoSnapshots) idxAvailability() uint64 {
 	return cmp.Min(headers, cmp.Min(bodies, txs))
 }
 
+// receiptsAvailability - receipt segments are optional, they are taken into account only if they have no gaps from genesis
+func (s *RoSnapshots) receiptsAvailability() uint64 {
+	var receipts, prevTo uint64
+	for _, seg := range s.Receipts.segments {
+		if seg.idxReceiptNumber == nil || seg.ranges.from != prevTo {
+			break
+		}
+		receipts = seg.ranges.to - 1
+		prevTo = seg.ranges.to
+	}
+	return receipts
+}
+
 // OptimisticReopenWithDB - optimistically open snapshots (ignoring error), useful at App startup because:
 // - user must be able: delete any snapshot file and Erigon will self-heal by re-downloading
 // - RPC return Nil for historical blocks if snapshots are not open
@@ -410,6 +511,8 @@ func (s *RoSnapshots) Files() (list []string) {
 	defer s.Bodies.lock.RUnlock()
 	s.Txs.lock.RLock()
 	defer s.Txs.lock.RUnlock()
+	s.Receipts.lock.RLock()
+	defer s.Receipts.lock.RUnlock()
 	max := s.BlocksAvailable()
 	for _, seg := range s.Bodies.segments {
 		if seg.seg == nil {
@@ -441,6 +544,16 @@ func (s *RoSnapshots) Files() (list []string) {
 		_, fName := filepath.Split(seg.Seg.FilePath())
 		list = append(list, fName)
 	}
+	for _, seg := range s.Receipts.segments {
+		if seg.seg == nil {
+			continue
+		}
+		if seg.ranges.from > max {
+			continue
+		}
+		_, fName := filepath.Split(seg.seg.FilePath())
+		list = append(list, fName)
+	}
 	slices.Sort(list)
 	return list
 }
@@ -453,6 +566,8 @@ func (s *RoSnapshots) ReopenList(fileNames []string, optimistic bool) error {
 	defer s.Bodies.lock.Unlock()
 	s.Txs.lock.Lock()
 	defer s.Txs.lock.Unlock()
+	s.Receipts.lock.Lock()
+	defer s.Receipts.lock.Unlock()
 
 	s.closeWhatNotInList(fileNames)
 	var segmentsMax uint64
@@ -571,6 +686,32 @@ Loop:
 			if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
 				return err
 			}
+		case snap.Receipts:
+			for _, sn := range s.Receipts.segments {
+				if sn.seg == nil {
+					continue
+				}
+				_, name := filepath.Split(sn.seg.FilePath())
+				if fName == name {
+					if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
+						return err
+					}
+					continue Loop
+				}
+			}
+
+			sn := &ReceiptSegment{ranges: Range{f.From, f.To}}
+			if err := sn.reopenSeg(s.dir); err != nil {
+				if optimistic || errors.Is(err, os.ErrNotExist) { // receipts are optional, blocks are available without them
+					continue Loop
+				}
+				return err
+			}
+			s.Receipts.segments = append(s.Receipts.segments, sn)
+			if err := sn.reopenIdxIfNeed(s.dir, optimistic); err != nil {
+				return err
+			}
+			continue Loop // don't affect availability of blocks
 		}
 
 		if f.To > 0 {
@@ -585,6 +726,7 @@ Loop:
 	}
 	s.segmentsReady.Store(true)
 	s.idxMax.Store(s.idxAvailability())
+	s.receiptsMax.Store(s.receiptsAvailability())
 	s.indicesReady.Store(true)
 
 	return nil
@@ -634,6 +776,8 @@ func (s *RoSnapshots) Close() {
 	defer s.Bodies.lock.Unlock()
 	s.Txs.lock.Lock()
 	defer s.Txs.lock.Unlock()
+	s.Receipts.lock.Lock()
+	defer s.Receipts.lock.Unlock()
 	s.closeWhatNotInList(nil)
 }
 
@@ -671,6 +815,17 @@ Loop3:
 		sn.close()
 		s.Txs.segments[i] = nil
 	}
+Loop4:
+	for i, sn := range s.Receipts.segments {
+		_, name := filepath.Split(sn.seg.FilePath())
+		for _, fName := range l {
+			if fName == name {
+				continue Loop4
+			}
+		}
+		sn.close()
+		s.Receipts.segments[i] = nil
+	}
 	var i int
 	for i = 0; i < len(s.Headers.segments) && s.Headers.segments[i] != nil && s.Headers.segments[i].seg != nil; i++ {
 	}
@@ -704,6 +859,17 @@ Loop3:
 			tailC[i] = nil
 		}
 	}
+
+	for i = 0; i < len(s.Receipts.segments) && s.Receipts.segments[i] != nil && s.Receipts.segments[i].seg != nil; i++ {
+	}
+	tailD := s.Receipts.segments[i:]
+	s.Receipts.segments = s.Receipts.segments[:i]
+	for i = 0; i < len(tailD); i++ {
+		if tailD[i] != nil {
+			tailD[i].close()
+			tailD[i] = nil
+		}
+	}
 }
 
 func (s *RoSnapshots) PrintDebug() {
@@ -713,6 +879,8 @@ func (s *RoSnapshots) PrintDebug() {
 	defer s.Bodies.lock.RUnlock()
 	s.Txs.lock.RLock()
 	defer s.Txs.lock.RUnlock()
+	s.Receipts.lock.RLock()
+	defer s.Receipts.lock.RUnlock()
 	fmt.Println("    == Snapshots, Header")
 	for _, sn := range s.Headers.segments {
 		fmt.Printf("%d,  %t\n", sn.ranges.from, sn.idxHeaderHash == nil)
@@ -725,6 +893,10 @@ func (s *RoSnapshots) PrintDebug() {
 	for _, sn := range s.Txs.segments {
 		fmt.Printf("%d,  %t, %t\n", sn.ranges.from, sn.IdxTxnHash == nil, sn.IdxTxnHash2BlockNum == nil)
 	}
+	fmt.Println("    == Snapshots, Receipts")
+	for _, sn := range s.Receipts.segments {
+		fmt.Printf("%d,  %t\n", sn.ranges.from, sn.idxReceiptNumber == nil)
+	}
 }
 func (s *RoSnapshots) ViewHeaders(blockNum uint64, f func(sn *HeaderSegment) error) (found bool, err error) {
 	if !s.indicesReady.Load() || blockNum > s.BlocksAvailable() {
@@ -744,6 +916,12 @@ func (s *RoSnapshots) ViewTxs(blockNum uint64, f func(sn *TxnSegment) error) (fo
 	}
 	return s.Txs.ViewSegment(blockNum, f)
 }
+func (s *RoSnapshots) ViewReceipts(blockNum uint64, f func(sn *ReceiptSegment) error) (found bool, err error) {
+	if !s.indicesReady.Load() || blockNum > s.BlocksAvailable() {
+		return false, nil
+	}
+	return s.Receipts.ViewSegment(blockNum, f)
+}
 
 func buildIdx(ctx context.Context, sn snap.FileInfo, chainID uint256.Int, tmpDir string, p *background.Progress, lvl log.Lvl) error {
 	switch sn.T {
@@ -760,6 +938,10 @@ func buildIdx(ctx context.Context, sn snap.FileInfo, chainID uint256.Int, tmpDir
 		if err := TransactionsIdx(ctx, chainID, sn.From, sn.To, dir, tmpDir, p, lvl); err != nil {
 			return err
 		}
+	case snap.Receipts:
+		if err := ReceiptsIdx(ctx, sn.Path, sn.From, tmpDir, p, lvl); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -778,7 +960,7 @@ func BuildMissedIndices(logPrefix string, ctx context.Context, dir string, chain
 	sem := semaphore.NewWeighted(int64(workers))
 	startIndexingTime := time.Now()
 	go func() {
-		for _, t := range snap.AllSnapshotTypes {
+		for _, t := range append(slices.Clone(snap.AllSnapshotTypes), snap.OptionalSnapshotTypes...) {
 			for index := range segments {
 				segment := segments[index]
 				if segment.T != t {
@@ -929,6 +1111,17 @@ func Segments(dir string) (res []snap.FileInfo, missingSnapshots []Range, err er
 		l, _ = noGaps(noOverlaps(allTypeOfSegmentsMustExist(dir, l)))
 		res = append(res, l...)
 	}
+	{
+		var l []snap.FileInfo
+		for _, f := range list {
+			if f.T != snap.Receipts {
+				continue
+			}
+			l = append(l, f)
+		}
+		l, _ = noGaps(noOverlaps(l))
+		res = append(res, l...)
+	}
 
 	return res, missingSnapshots, nil
 }
@@ -1035,6 +1228,18 @@ func (br *BlockRetire) PruneAncientBlocks(tx kv.RwTx) error {
 	if err := rawdb.PruneTable(tx, kv.Senders, canDeleteTo, context.Background(), 100); err != nil {
 		return err
 	}
+	// Readers other than eth_getLogs and eth_getTransactionReceipt don't look into the receipt segments yet.
+	// Receipts of frozen blocks are deleted on request and only if they are frozen too
+	if !br.snapshots.cfg.PruneReceipts {
+		return nil
+	}
+	receiptsDeleteTo := cmp.Min(canDeleteTo, br.snapshots.ReceiptsAvailable())
+	if err := rawdb.PruneTable(tx, kv.Receipts, receiptsDeleteTo, context.Background(), 100); err != nil {
+		return err
+	}
+	if err := rawdb.PruneTable(tx, kv.Log, receiptsDeleteTo, context.Background(), 100); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1078,6 +1283,9 @@ func retireBlocks(ctx context.Context, blockFrom, blockTo uint64, chainID uint25
 	if err := DumpBlocks(ctx, blockFrom, blockTo, snap.DEFAULT_SEGMENT_SIZE, tmpDir, snapshots.Dir(), db, workers, lvl); err != nil {
 		return fmt.Errorf("DumpBlocks: %w", err)
 	}
+	if err := dumpMissedReceipts(ctx, chainID, snapshots, db, tmpDir, workers, lvl); err != nil {
+		return fmt.Errorf("dumpMissedReceipts: %w", err)
+	}
 	if err := snapshots.ReopenFolder(); err != nil {
 		return fmt.Errorf("reopen: %w", err)
 	}
@@ -1156,6 +1364,43 @@ func dumpBlocksRange(ctx context.Context, blockFrom, blockTo uint64, tmpDir, sna
 		return err
 	}
 
+	segName = snap.SegmentFileName(blockFrom, blockTo, snap.Receipts)
+	f, _ = snap.ParseFileName(snapDir, segName)
+	if err := DumpReceipts(ctx, chainDB, f.Path, tmpDir, blockFrom, blockTo, workers, lvl); err != nil {
+		if errors.Is(err, ErrReceiptsPruned) {
+			log.Log(lvl, "[snapshots] Skip receipts", "range", fmt.Sprintf("%dk-%dk", blockFrom/1000, blockTo/1000), "reason", err)
+			return nil
+		}
+		return fmt.Errorf("DumpReceipts: %w", err)
+	}
+	p = &background.Progress{}
+	if err := buildIdx(ctx, f, *chainId, tmpDir, p, lvl); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// dumpMissedReceipts - receipts of blocks which were frozen without them (downloaded or pruned at that time),
+// in ascending order while they are still in db
+func dumpMissedReceipts(ctx context.Context, chainID uint256.Int, snapshots *RoSnapshots, chainDB kv.RoDB, tmpDir string, workers int, lvl log.Lvl) error {
+	for _, r := range snapshots.Ranges() {
+		segName := snap.SegmentFileName(r.from, r.to, snap.Receipts)
+		f, _ := snap.ParseFileName(snapshots.Dir(), segName)
+		if common.FileExist(f.Path) {
+			continue
+		}
+		if err := DumpReceipts(ctx, chainDB, f.Path, tmpDir, r.from, r.to, workers, lvl); err != nil {
+			if errors.Is(err, ErrReceiptsPruned) {
+				return nil
+			}
+			return fmt.Errorf("DumpReceipts: %w", err)
+		}
+		p := &background.Progress{}
+		if err := buildIdx(ctx, f, chainID, tmpDir, p, lvl); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1188,6 +1433,12 @@ func hasIdxFile(sn *snap.FileInfo) bool {
 			return false
 		}
 		_ = idx.Close()
+	case snap.Receipts:
+		idx, err := recsplit.OpenIndex(path.Join(dir, fName))
+		if err != nil {
+			return false
+		}
+		_ = idx.Close()
 	}
 	return true
 }
@@ -1494,6 +1745,95 @@ func DumpBodies(ctx context.Context, db kv.RoDB, segmentFilePath, tmpDir string,
 	return nil
 }
 
+var ErrReceiptsPruned = errors.New("receipts are pruned")
+
+// DumpReceipts - [from, to)
+// Format: rlp(types.ReceiptsForStorage) of each block, logs included
+func DumpReceipts(ctx context.Context, db kv.RoDB, segmentFilePath, tmpDir string, blockFrom, blockTo uint64, workers int, lvl log.Lvl) error {
+	logEvery := time.NewTicker(20 * time.Second)
+	defer logEvery.Stop()
+
+	// don't start compression if receipts of the range are pruned, execution doesn't write receipts of genesis
+	if err := db.View(ctx, func(tx kv.Tx) error {
+		for _, blockNum := range []uint64{cmp.Max(blockFrom, 1), blockTo - 1} {
+			ok, err := tx.Has(kv.Receipts, dbutils.EncodeBlockNumber(blockNum))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("%w: block_num=%d", ErrReceiptsPruned, blockNum)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	f, err := compress.NewCompressor(ctx, "Snapshot Receipts", segmentFilePath, tmpDir, compress.MinPatternScore, workers, lvl)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	addReceipts := func(receipts types.Receipts) error {
+		stored := make(types.ReceiptsForStorage, len(receipts))
+		for i, r := range receipts {
+			stored[i] = (*types.ReceiptForStorage)(r)
+		}
+		buf.Reset()
+		if err := rlp.Encode(&buf, stored); err != nil {
+			return err
+		}
+		return f.AddWord(buf.Bytes())
+	}
+	from := blockFrom
+	if from == 0 {
+		if err := addReceipts(nil); err != nil {
+			return err
+		}
+		from = 1
+	}
+	expectedBlockNum := from
+	if err := kv.BigChunks(db, kv.Receipts, dbutils.EncodeBlockNumber(from), func(tx kv.Tx, k, v []byte) (bool, error) {
+		blockNum := binary.BigEndian.Uint64(k)
+		if blockNum >= blockTo {
+			return false, nil
+		}
+		if blockNum != expectedBlockNum {
+			return false, fmt.Errorf("%w: block_num=%d", ErrReceiptsPruned, expectedBlockNum)
+		}
+		expectedBlockNum++
+		if err := addReceipts(rawdb.ReadRawReceipts(tx, blockNum)); err != nil {
+			return false, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-logEvery.C:
+			var m runtime.MemStats
+			if lvl >= log.LvlInfo {
+				common2.ReadMemStats(&m)
+			}
+			log.Log(lvl, "[snapshots] Dumping receipts", "block num", blockNum,
+				"alloc", common2.ByteCount(m.Alloc), "sys", common2.ByteCount(m.Sys),
+			)
+		default:
+		}
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	if uint64(f.Count()) != blockTo-blockFrom {
+		return fmt.Errorf("incorrect receipts count: %d, expected: %d", f.Count(), blockTo-blockFrom)
+	}
+	if err := f.Compress(); err != nil {
+		return fmt.Errorf("compress: %w", err)
+	}
+	return nil
+}
+
 var EmptyTxHash = common.Hash{}
 
 func expectedTxsAmount(snapDir string, blockFrom, blockTo uint64) (firstTxID, expectedCount uint64, err error) {
@@ -1759,6 +2099,40 @@ func BodiesIdx(ctx context.Context, segmentFilePath string, firstBlockNumInSegme
 	return nil
 }
 
+// ReceiptsIdx - block_num -> offset (analog of kv.Receipts)
+func ReceiptsIdx(ctx context.Context, segmentFilePath string, firstBlockNumInSegment uint64, tmpDir string, p *background.Progress, lvl log.Lvl) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			_, fName := filepath.Split(segmentFilePath)
+			err = fmt.Errorf("ReceiptsIdx: at=%s, %v, %s", fName, rec, dbg.Stack())
+		}
+	}()
+
+	num := make([]byte, 8)
+
+	d, err := compress.NewDecompressor(segmentFilePath)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	_, fname := filepath.Split(segmentFilePath)
+	p.Name.Store(fname)
+	p.Total.Store(uint64(d.Count()))
+
+	if err := Idx(ctx, d, firstBlockNumInSegment, tmpDir, log.LvlDebug, func(idx *recsplit.RecSplit, i, offset uint64, word []byte) error {
+		p.Processed.Inc()
+		n := binary.PutUvarint(num, i)
+		if err := idx.AddKey(num[:n], offset); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("ReceiptsIdx: %w", err)
+	}
+	return nil
+}
+
 type decompressItem struct {
 	i, offset uint64
 	word      []byte
@@ -1940,6 +2314,26 @@ func (m *Merger) filesByRange(snapshots *RoSnapshots, from, to uint64) (map[snap
 			})
 		})
 	})
+	if err != nil {
+		return nil, err
+	}
+	var receipts []string
+	err = snapshots.Receipts.View(func(rSegments []*ReceiptSegment) error {
+		for _, sn := range rSegments {
+			if sn.ranges.from < from {
+				continue
+			}
+			if sn.ranges.to > to {
+				break
+			}
+			receipts = append(receipts, sn.seg.FilePath())
+		}
+		return nil
+	})
+	// receipts are merged only if all of them are present, otherwise they stay as they are
+	if len(receipts) == len(toMerge[snap.Headers]) {
+		toMerge[snap.Receipts] = receipts
+	}
 	return toMerge, err
 }
 
@@ -1956,7 +2350,10 @@ func (m *Merger) Merge(ctx context.Context, snapshots *RoSnapshots, mergeRanges
 		if err != nil {
 			return err
 		}
-		for _, t := range snap.AllSnapshotTypes {
+		for _, t := range append(slices.Clone(snap.AllSnapshotTypes), snap.OptionalSnapshotTypes...) {
+			if len(toMerge[t]) == 0 {
+				continue
+			}
 			segName := snap.SegmentFileName(r.from, r.to, t)
 			f, _ := snap.ParseFileName(snapDir, segName)
 			if err := m.merge(ctx, toMerge[t], f.Path, logEvery); err != nil {
@@ -1977,7 +2374,7 @@ func (m *Merger) Merge(ctx context.Context, snapshots *RoSnapshots, mergeRanges
 			m.notifier.OnNewSnapshot()
 			time.Sleep(1 * time.Second) // i working on blocking API - to ensure client does not use old snapsthos - and then delete them
 		}
-		for _, t := range snap.AllSnapshotTypes {
+		for _, t := range append(slices.Clone(snap.AllSnapshotTypes), snap.OptionalSnapshotTypes...) {
 			m.removeOldFiles(toMerge[t], snapDir)
 		}
 	}
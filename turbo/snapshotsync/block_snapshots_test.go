@@ -200,17 +200,59 @@ func TestOpenAllSnapshot(t *testing.T) {
 	require.NoError(err)
 }
 
+func TestOpenReceiptsSnapshot(t *testing.T) {
+	dir, require := t.TempDir(), require.New(t)
+	cfg := ethconfig.Snapshot{Enabled: true}
+	createFile := func(from, to uint64, name snap.Type) { createTestSegmentFile(t, from, to, name, dir) }
+	for _, from := range []uint64{0, 500_000} {
+		createFile(from, from+500_000, snap.Headers)
+		createFile(from, from+500_000, snap.Bodies)
+		createFile(from, from+500_000, snap.Transactions)
+	}
+	createFile(500_000, 1_000_000, snap.Receipts)
+
+	s := NewRoSnapshots(cfg, dir)
+	defer s.Close()
+	err := s.ReopenFolder()
+	require.NoError(err)
+	require.Equal(2, len(s.Headers.segments))
+	require.Equal(999_999, int(s.BlocksAvailable()))
+	require.Equal(0, int(s.ReceiptsAvailable())) // receipt segments are optional, but must start from genesis
+	s.Close()
+
+	createFile(0, 500_000, snap.Receipts)
+	s = NewRoSnapshots(cfg, dir)
+	defer s.Close()
+	err = s.ReopenFolder()
+	require.NoError(err)
+	require.Equal(999_999, int(s.ReceiptsAvailable()))
+
+	ok, err := s.ViewReceipts(10, func(sn *ReceiptSegment) error {
+		require.Equal(int(sn.ranges.to), 500_000)
+		return nil
+	})
+	require.NoError(err)
+	require.True(ok)
+
+	ok, err = s.ViewReceipts(1_000_000, func(sn *ReceiptSegment) error {
+		return nil
+	})
+	require.NoError(err)
+	require.False(ok)
+}
+
 func TestParseCompressedFileName(t *testing.T) {
 	require := require.New(t)
 	fs := fstest.MapFS{
-		"a":                 &fstest.MapFile{},
-		"1-a":               &fstest.MapFile{},
-		"1-2-a":             &fstest.MapFile{},
-		"1-2-bodies.info":   &fstest.MapFile{},
-		"1-2-bodies.seg":    &fstest.MapFile{},
-		"v2-1-2-bodies.seg": &fstest.MapFile{},
-		"v0-1-2-bodies.seg": &fstest.MapFile{},
-		"v1-1-2-bodies.seg": &fstest.MapFile{},
+		"a":                   &fstest.MapFile{},
+		"1-a":                 &fstest.MapFile{},
+		"1-2-a":               &fstest.MapFile{},
+		"1-2-bodies.info":     &fstest.MapFile{},
+		"1-2-bodies.seg":      &fstest.MapFile{},
+		"v2-1-2-bodies.seg":   &fstest.MapFile{},
+		"v0-1-2-bodies.seg":   &fstest.MapFile{},
+		"v1-1-2-bodies.seg":   &fstest.MapFile{},
+		"v1-1-2-receipts.seg": &fstest.MapFile{},
 	}
 	stat := func(name string) string {
 		s, err := fs.Stat(name)
@@ -237,6 +279,10 @@ func TestParseCompressedFileName(t *testing.T) {
 	require.Equal(f.T, snap.Bodies)
 	require.Equal(1_000, int(f.From))
 	require.Equal(2_000, int(f.To))
+
+	f, err = snap.ParseFileName("", stat("v1-1-2-receipts.seg"))
+	require.NoError(err)
+	require.Equal(f.T, snap.Receipts)
 }
 
 func BenchmarkName(b *testing.B) {
@@ -20,6 +20,7 @@ const (
 	Headers Type = iota
 	Bodies
 	Transactions
+	Receipts
 	NumberOfTypes
 )
 
@@ -31,6 +32,8 @@ func (ft Type) String() string {
 		return "bodies"
 	case Transactions:
 		return "transactions"
+	case Receipts:
+		return "receipts"
 	default:
 		panic(fmt.Sprintf("unknown file type: %d", ft))
 	}
@@ -44,6 +47,8 @@ func ParseFileType(s string) (Type, bool) {
 		return Bodies, true
 	case "transactions":
 		return Transactions, true
+	case "receipts":
+		return Receipts, true
 	default:
 		return NumberOfTypes, false
 	}
@@ -59,6 +64,10 @@ func (it IdxType) String() string { return string(it) }
 
 var AllSnapshotTypes = []Type{Headers, Bodies, Transactions}
 
+// OptionalSnapshotTypes - produced only while their data is still in db (receipts may be pruned),
+// blocks are available without them
+var OptionalSnapshotTypes = []Type{Receipts}
+
 var (
 	ErrInvalidFileName = fmt.Errorf("invalid compressed file name")
 )
@@ -122,6 +131,8 @@ func ParseFileName(dir, fileName string) (res FileInfo, err error) {
 		snapshotType = Bodies
 	case Transactions:
 		snapshotType = Transactions
+	case Receipts:
+		snapshotType = Receipts
 	default:
 		return res, fmt.Errorf("unexpected snapshot suffix: %s,%w", parts[2], ErrInvalidFileName)
 	}
@@ -37,6 +37,7 @@ import (
 	"github.com/ledgerwatch/erigon/common/hexutil"
 	"github.com/ledgerwatch/erigon/common/u256"
 	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/serenity"
 	"github.com/ledgerwatch/erigon/core"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/state"
@@ -1894,6 +1895,72 @@ func TestEIP2718Transition(t *testing.T) {
 	}
 }
 
+// TestWithdrawals tests that withdrawals (EIP-4895) credit amount*GWei to the recipients after the transactions
+// of the block, including to an empty account, and that Shanghai blocks without withdrawals are valid.
+func TestWithdrawals(t *testing.T) {
+	var (
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address   = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.HexToAddress("0x000000000000000000000000000000000000dddd")
+		bb        = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		funds     = big.NewInt(params.Ether)
+		gasPrice  = uint256.NewInt(2 * params.GWei)
+		config    = *params.AllEthashProtocolChanges
+	)
+	config.TerminalTotalDifficulty = common.Big0
+	config.LondonBlock = common.Big0
+	config.ShanghaiBlock = common.Big0
+	// The address 0xBBBB stores the balance of the withdrawal recipient into the slot 0
+	code := append([]byte{byte(vm.PUSH20)}, recipient.Bytes()...)
+	code = append(code, byte(vm.BALANCE), byte(vm.PUSH1), 0, byte(vm.SSTORE))
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			address: {Balance: funds},
+			bb:      {Code: code, Balance: big.NewInt(0)},
+		},
+	}
+	m := stages.MockWithGenesisEngine(t, gspec, serenity.New(ethash.NewFaker()), false)
+
+	signer := types.LatestSigner(gspec.Config)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		b.SetDifficulty(serenity.SerenityDifficulty) // Proof-of-Stake blocks, GenerateChain doesn't know the total difficulty
+		if i > 0 {
+			return
+		}
+		tx, err := types.SignTx(types.NewTransaction(0, bb, u256.Num0, 100000, gasPrice, nil), *signer, key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+		b.AddWithdrawal(&types.Withdrawal{Index: 0, Validator: 1, Address: recipient, Amount: 7})
+		b.AddWithdrawal(&types.Withdrawal{Index: 1, Validator: 2, Address: address, Amount: 3})
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.Len(t, chain.Blocks[0].Withdrawals(), 2)
+	require.NotNil(t, chain.Blocks[1].Withdrawals())
+	require.Empty(t, chain.Blocks[1].Withdrawals())
+
+	require.NoError(t, m.InsertChain(chain))
+
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	block, _, err := rawdb.ReadBlockWithSenders(tx, chain.Blocks[0].Hash(), 1)
+	require.NoError(t, err)
+	require.Equal(t, chain.Blocks[0].Withdrawals(), block.Withdrawals())
+
+	statedb := state.New(state.NewPlainStateReader(tx))
+	gwei := uint256.NewInt(params.GWei)
+	require.Equal(t, new(uint256.Int).Mul(uint256.NewInt(7), gwei), statedb.GetBalance(recipient))
+	expected := new(uint256.Int).Sub(uint256.NewInt(funds.Uint64()), new(uint256.Int).Mul(uint256.NewInt(chain.Blocks[0].GasUsed()), gasPrice))
+	expected.Add(expected, new(uint256.Int).Mul(uint256.NewInt(3), gwei))
+	require.Equal(t, expected, statedb.GetBalance(address))
+	// The transaction saw the recipient before the withdrawal
+	var balance uint256.Int
+	statedb.GetState(bb, &common.Hash{}, &balance)
+	require.True(t, balance.IsZero())
+}
+
 // TestEIP1559Transition tests the following:
 //
 //  1. A tranaction whose feeCap is greater than the baseFee is valid.
@@ -37,7 +37,7 @@ func (bd *BodyDownload) UpdateFromDb(db kv.Tx) (headHeight uint64, headHash comm
 	bd.requestedLow = bodyProgress + 1
 	bd.lowWaitUntil = 0
 	bd.requestHigh = bd.requestedLow + (bd.outstandingLimit / 2)
-	bd.requestedMap = make(map[DoubleHash]uint64)
+	bd.requestedMap = make(map[TripleHash]uint64)
 	bd.delivered.Clear()
 	bd.deliveredCount = 0
 	bd.wastedCount = 0
@@ -131,20 +131,28 @@ func (bd *BodyDownload) RequestMoreBodies(tx kv.RwTx, blockReader services.FullB
 				request = false
 			} else {
 				bd.deliveriesH[blockNum-bd.requestedLow] = header
-				if header.UncleHash != types.EmptyUncleHash || header.TxHash != types.EmptyRootHash {
+				if header.UncleHash != types.EmptyUncleHash || header.TxHash != types.EmptyRootHash ||
+					(header.WithdrawalsHash != nil && *header.WithdrawalsHash != types.EmptyRootHash) {
 					// Perhaps we already have this block
 					block := rawdb.ReadBlock(tx, hash, blockNum)
 					if block == nil {
-						var doubleHash DoubleHash
-						copy(doubleHash[:], header.UncleHash.Bytes())
-						copy(doubleHash[common.HashLength:], header.TxHash.Bytes())
-						bd.requestedMap[doubleHash] = blockNum
+						var tripleHash TripleHash
+						copy(tripleHash[:], header.UncleHash.Bytes())
+						copy(tripleHash[common.HashLength:], header.TxHash.Bytes())
+						if header.WithdrawalsHash != nil {
+							copy(tripleHash[2*common.HashLength:], header.WithdrawalsHash.Bytes())
+						}
+						bd.requestedMap[tripleHash] = blockNum
 					} else {
 						bd.deliveriesB[blockNum-bd.requestedLow] = block.RawBody()
 						request = false
 					}
 				} else {
-					bd.deliveriesB[blockNum-bd.requestedLow] = &types.RawBody{}
+					emptyBody := &types.RawBody{}
+					if header.WithdrawalsHash != nil {
+						emptyBody.Withdrawals = []*types.Withdrawal{}
+					}
+					bd.deliveriesB[blockNum-bd.requestedLow] = emptyBody
 					request = false
 				}
 			}
@@ -207,8 +215,8 @@ func (bd *BodyDownload) RequestSent(bodyReq *BodyRequest, timeWithTimeout uint64
 }
 
 // DeliverBodies takes the block body received from a peer and adds it to the various data structures
-func (bd *BodyDownload) DeliverBodies(txs *[][][]byte, uncles *[][]*types.Header, lenOfP2PMsg uint64, peerID [64]byte) {
-	bd.deliveryCh <- Delivery{txs: txs, uncles: uncles, lenOfP2PMessage: lenOfP2PMsg, peerID: peerID}
+func (bd *BodyDownload) DeliverBodies(txs *[][][]byte, uncles *[][]*types.Header, withdrawals *[][]*types.Withdrawal, lenOfP2PMsg uint64, peerID [64]byte) {
+	bd.deliveryCh <- Delivery{txs: txs, uncles: uncles, withdrawals: withdrawals, lenOfP2PMessage: lenOfP2PMsg, peerID: peerID}
 
 	select {
 	case bd.DeliveryNotify <- struct{}{}:
@@ -265,18 +273,28 @@ Loop:
 
 		reqMap := make(map[uint64]*BodyRequest)
 		txs, uncles, lenOfP2PMessage, _ := *delivery.txs, *delivery.uncles, delivery.lenOfP2PMessage, delivery.peerID
+		var withdrawals [][]*types.Withdrawal
+		if delivery.withdrawals != nil {
+			withdrawals = *delivery.withdrawals
+		}
 		var delivered, undelivered int
 
 		for i := range txs {
 			uncleHash := types.CalcUncleHash(uncles[i])
 			txHash := types.DeriveSha(RawTransactions(txs[i]))
-			var doubleHash DoubleHash
-			copy(doubleHash[:], uncleHash.Bytes())
-			copy(doubleHash[common.HashLength:], txHash.Bytes())
+			var tripleHash TripleHash
+			copy(tripleHash[:], uncleHash.Bytes())
+			copy(tripleHash[common.HashLength:], txHash.Bytes())
+			var bodyWithdrawals []*types.Withdrawal
+			if i < len(withdrawals) && withdrawals[i] != nil {
+				bodyWithdrawals = withdrawals[i]
+				withdrawalsHash := types.DeriveSha(types.Withdrawals(bodyWithdrawals))
+				copy(tripleHash[2*common.HashLength:], withdrawalsHash.Bytes())
+			}
 
 			// Block numbers are added to the bd.delivered bitmap here, only for blocks for which the body has been received, and their double hashes are present in the bd.requestedMap
 			// Also, block numbers can be added to bd.delivered for empty blocks, above
-			blockNum, ok := bd.requestedMap[doubleHash]
+			blockNum, ok := bd.requestedMap[tripleHash]
 			if !ok {
 				undelivered++
 				continue
@@ -287,9 +305,9 @@ Loop:
 					reqMap[req.BlockNums[0]] = req
 				}
 			}
-			delete(bd.requestedMap, doubleHash) // Delivered, cleaning up
+			delete(bd.requestedMap, tripleHash) // Delivered, cleaning up
 
-			bd.deliveriesB[blockNum-bd.requestedLow] = &types.RawBody{Transactions: txs[i], Uncles: uncles[i]}
+			bd.deliveriesB[blockNum-bd.requestedLow] = &types.RawBody{Transactions: txs[i], Uncles: uncles[i], Withdrawals: bodyWithdrawals}
 			bd.delivered.Add(blockNum)
 			delivered++
 		}
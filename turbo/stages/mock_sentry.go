@@ -388,6 +388,7 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 				cfg.HistoryV2,
 				mock.txNums,
 			),
+			stagedsync.StageBorHeimdallCfg(mock.DB, nil, mock.ChainConfig, nil, blockReader),
 			stagedsync.StageIssuanceCfg(mock.DB, mock.ChainConfig, blockReader, true),
 			stagedsync.StageSendersCfg(mock.DB, mock.ChainConfig, false, dirs.Tmp, prune, blockRetire, nil),
 			stagedsync.StageExecuteBlocksCfg(
@@ -412,6 +413,8 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 			),
 			stagedsync.StageHashStateCfg(mock.DB, mock.Dirs, cfg.HistoryV2, mock.txNums, mock.agg),
 			stagedsync.StageTrieCfg(mock.DB, true, true, false, dirs.Tmp, blockReader, nil, cfg.HistoryV2, mock.txNums, mock.agg),
+			stagedsync.StageVerkleTrieCfg(mock.DB, cfg.VerkleTrie, dirs.Tmp),
+			stagedsync.StageReceiptsCfg(mock.DB, mock.sentriesClient.Rd, mock.sentriesClient.SendReceiptRequest, cfg.Sync.BodyDownloadTimeoutSeconds, allSnapshots, blockReader, cfg.Sync.DownloadReceipts),
 			stagedsync.StageHistoryCfg(mock.DB, prune, dirs.Tmp),
 			stagedsync.StageLogIndexCfg(mock.DB, prune, dirs.Tmp),
 			stagedsync.StageCallTracesCfg(mock.DB, prune, 0, dirs.Tmp),
@@ -440,7 +443,7 @@ func MockWithEverything(t *testing.T, gspec *core.Genesis, key *ecdsa.PrivateKey
 	mock.MinedBlocks = miner.MiningResultCh
 	mock.MiningSync = stagedsync.New(
 		stagedsync.MiningStages(mock.Ctx,
-			stagedsync.StageMiningCreateBlockCfg(mock.DB, miner, *mock.ChainConfig, mock.Engine, mock.TxPool, nil, nil, dirs.Tmp),
+			stagedsync.StageMiningCreateBlockCfg(mock.DB, miner, *mock.ChainConfig, mock.Engine, mock.TxPool, mock.txPoolDB, nil, dirs.Tmp),
 			stagedsync.StageMiningExecCfg(mock.DB, miner, nil, *mock.ChainConfig, mock.Engine, &vm.Config{}, dirs.Tmp, nil),
 			stagedsync.StageHashStateCfg(mock.DB, dirs, cfg.HistoryV2, mock.txNums, mock.agg),
 			stagedsync.StageTrieCfg(mock.DB, false, true, false, dirs.Tmp, blockReader, nil, cfg.HistoryV2, mock.txNums, mock.agg),
@@ -497,10 +500,11 @@ func MockWithZeroTTD(t *testing.T, withPosDownloader bool) *MockSentry {
 	funds := big.NewInt(1 * params.Ether)
 	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	address := crypto.PubkeyToAddress(key.PublicKey)
-	chainConfig := params.AllEthashProtocolChanges
+	// A copy, the other mocks use the shared config without the terminal total difficulty
+	chainConfig := *params.AllEthashProtocolChanges
 	chainConfig.TerminalTotalDifficulty = common.Big0
 	gspec := &core.Genesis{
-		Config: chainConfig,
+		Config: &chainConfig,
 		Alloc: core.GenesisAlloc{
 			address: {Balance: funds},
 		},
@@ -669,6 +673,27 @@ func (ms *MockSentry) ReceivePayloadStatus() engineapi.PayloadStatus {
 	return <-ms.sentriesClient.Hd.PayloadStatusCh
 }
 
+// AssembleBlockPOS builds a Proof-of-Stake block on top of the head with the transactions of the pool,
+// the same way as the block builder of the engine API in eth.New. The mock needs to be created with the pool
+func (ms *MockSentry) AssembleBlockPOS(param *core.BlockBuilderParameters, interrupt *int32) (*types.BlockWithReceipts, error) {
+	miningConfig := ethconfig.Defaults.Miner
+	miningStatePos := stagedsync.NewProposingState(&miningConfig)
+	miningStatePos.MiningConfig.Etherbase = param.SuggestedFeeRecipient
+	blockReader := snapshotsync.NewBlockReader()
+	proposingSync := stagedsync.New(
+		stagedsync.MiningStages(ms.Ctx,
+			stagedsync.StageMiningCreateBlockCfg(ms.DB, miningStatePos, *ms.ChainConfig, ms.Engine, ms.TxPool, ms.txPoolDB, param, ms.Dirs.Tmp),
+			stagedsync.StageMiningExecCfg(ms.DB, miningStatePos, nil, *ms.ChainConfig, ms.Engine, &vm.Config{}, ms.Dirs.Tmp, interrupt),
+			stagedsync.StageHashStateCfg(ms.DB, ms.Dirs, ms.HistoryV2, ms.txNums, ms.agg),
+			stagedsync.StageTrieCfg(ms.DB, false, true, true, ms.Dirs.Tmp, blockReader, nil, ms.HistoryV2, ms.txNums, ms.agg),
+			stagedsync.StageMiningFinishCfg(ms.DB, *ms.ChainConfig, ms.Engine, miningStatePos, nil),
+		), stagedsync.MiningUnwindOrder, stagedsync.MiningPruneOrder)
+	if err := MiningStep(ms.Ctx, ms.DB, proposingSync); err != nil {
+		return nil, err
+	}
+	return <-miningStatePos.MiningResultPOSCh, nil
+}
+
 func (ms *MockSentry) HeaderDownload() *headerdownload.HeaderDownload {
 	return ms.sentriesClient.Hd
 }
@@ -0,0 +1,256 @@
+package receiptdownload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/common/dbg"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/log/v3"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/turbo/services"
+)
+
+// UpdateFromDb reads the state of the database and refreshes the state of the receipt download
+func (rd *ReceiptDownload) UpdateFromDb(db kv.Tx) error {
+	// Receipts are verified against the headers, so the blocks don't need to be executed
+	bodiesProgress, err := stages.GetStageProgress(db, stages.Bodies)
+	if err != nil {
+		return err
+	}
+	receiptsProgress, err := stages.GetStageProgress(db, stages.Receipts)
+	if err != nil {
+		return err
+	}
+	rd.maxProgress = bodiesProgress + 1
+	// Resetting for requesting a new range of blocks
+	rd.requestedLow = receiptsProgress + 1
+	rd.requestedMap = make(map[common.Hash][]uint64)
+	rd.delivered.Clear()
+	rd.deliveredCount = 0
+	rd.wastedCount = 0
+	for i := 0; i < len(rd.deliveries); i++ {
+		rd.deliveries[i] = nil
+		rd.requests[i] = nil
+	}
+	rd.peerMap = make(map[[64]byte]int)
+	return nil
+}
+
+// RequestMoreReceipts - returns nil if nothing to request
+func (rd *ReceiptDownload) RequestMoreReceipts(tx kv.Tx, blockReader services.HeaderReader, blockNum uint64, currentTime uint64) (*ReceiptRequest, uint64, error) {
+	if blockNum < rd.requestedLow {
+		blockNum = rd.requestedLow
+	}
+	var receiptReq *ReceiptRequest
+	blockNums := make([]uint64, 0, MaxReceiptsInRequest)
+	hashes := make([]common.Hash, 0, MaxReceiptsInRequest)
+	for ; len(blockNums) < MaxReceiptsInRequest && rd.requestedLow <= rd.maxProgress; blockNum++ {
+		// Check if we reached highest allowed request block number, and turn back
+		if blockNum >= rd.requestedLow+rd.outstandingLimit || blockNum >= rd.maxProgress {
+			blockNum = 0
+			break // Avoid tight loop
+		}
+		if rd.delivered.Contains(blockNum) {
+			// Already delivered, no need to request
+			continue
+		}
+		req := rd.requests[blockNum-rd.requestedLow]
+		if req != nil {
+			if currentTime < req.waitUntil {
+				continue
+			}
+			rd.peerMap[req.peerID]++
+			rd.requests[blockNum-rd.requestedLow] = nil
+		}
+		// Receipts written by the execution or downloaded before are not requested
+		has, err := tx.Has(kv.Receipts, dbutils.EncodeBlockNumber(blockNum))
+		if err != nil {
+			return nil, 0, err
+		}
+		if has {
+			rd.delivered.Add(blockNum)
+			continue
+		}
+		hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not find canonical header: %w, blockNum=%d, trace=%s", err, blockNum, dbg.Stack())
+		}
+		header, err := blockReader.Header(context.Background(), tx, hash, blockNum)
+		if err != nil {
+			return nil, 0, fmt.Errorf("header not found: %w, blockNum=%d, trace=%s", err, blockNum, dbg.Stack())
+		}
+		if header == nil {
+			return nil, 0, fmt.Errorf("header not found: blockNum=%d, hash=%x, trace=%s", blockNum, hash, dbg.Stack())
+		}
+		if header.ReceiptHash == types.EmptyRootHash {
+			// Block without transactions, no need to request
+			rd.deliveries[blockNum-rd.requestedLow] = types.Receipts{}
+			rd.delivered.Add(blockNum)
+			continue
+		}
+		rd.addRequested(header.ReceiptHash, blockNum)
+		blockNums = append(blockNums, blockNum)
+		hashes = append(hashes, hash)
+	}
+	if len(blockNums) > 0 {
+		receiptReq = &ReceiptRequest{BlockNums: blockNums, Hashes: hashes}
+		for _, blockNum := range blockNums {
+			rd.requests[blockNum-rd.requestedLow] = receiptReq
+		}
+	}
+	return receiptReq, blockNum, nil
+}
+
+// addRequested remembers that the receipts of the block are requested, unless they already were by a request
+// which timed out
+func (rd *ReceiptDownload) addRequested(receiptHash common.Hash, blockNum uint64) {
+	for _, n := range rd.requestedMap[receiptHash] {
+		if n == blockNum {
+			return
+		}
+	}
+	rd.requestedMap[receiptHash] = append(rd.requestedMap[receiptHash], blockNum)
+}
+
+// takeRequested returns the lowest requested block with the given receipt root and forgets it, so that identical
+// receipts delivered for another block with the same root are filed under that block
+func (rd *ReceiptDownload) takeRequested(receiptHash common.Hash) (uint64, bool) {
+	blockNums := rd.requestedMap[receiptHash]
+	if len(blockNums) == 0 {
+		return 0, false
+	}
+	lowest := 0
+	for i, n := range blockNums {
+		if n < blockNums[lowest] {
+			lowest = i
+		}
+	}
+	blockNum := blockNums[lowest]
+	if len(blockNums) == 1 {
+		delete(rd.requestedMap, receiptHash) // Delivered, cleaning up
+	} else {
+		rd.requestedMap[receiptHash] = append(blockNums[:lowest:lowest], blockNums[lowest+1:]...)
+	}
+	return blockNum, true
+}
+
+func (rd *ReceiptDownload) RequestSent(receiptReq *ReceiptRequest, timeWithTimeout uint64, peer [64]byte) {
+	for _, blockNum := range receiptReq.BlockNums {
+		if blockNum < rd.requestedLow {
+			continue
+		}
+		req := rd.requests[blockNum-rd.requestedLow]
+		if req != nil {
+			rd.requests[blockNum-rd.requestedLow].waitUntil = timeWithTimeout
+			rd.requests[blockNum-rd.requestedLow].peerID = peer
+		}
+	}
+}
+
+// DeliverReceipts takes the receipts received from a peer and adds them to the various data structures.
+// The delivery is dropped if the channel is full, e.g. when the Receipts stage is not running, instead of blocking
+// the loop handling the inbound messages. The dropped blocks are requested again after the timeout
+func (rd *ReceiptDownload) DeliverReceipts(receipts *[][]*types.Receipt, lenOfP2PMsg uint64, peerID [64]byte) {
+	select {
+	case rd.deliveryCh <- Delivery{receipts: receipts, lenOfP2PMessage: lenOfP2PMsg, peerID: peerID}:
+	default:
+		log.Debug("Receipts delivery dropped, the channel is full", "peer_id", peerID, "p2p_msg_len", lenOfP2PMsg)
+		return
+	}
+
+	select {
+	case rd.DeliveryNotify <- struct{}{}:
+	default:
+	}
+}
+
+func (rd *ReceiptDownload) doDeliverReceipts() {
+Loop:
+	for {
+		var delivery Delivery
+
+		select { // read as much as we can, but don't wait
+		case delivery = <-rd.deliveryCh:
+		default:
+			break Loop
+		}
+
+		if delivery.receipts == nil {
+			log.Warn("nil receipts delivered", "peer_id", delivery.peerID, "p2p_msg_len", delivery.lenOfP2PMessage)
+			continue
+		}
+
+		reqMap := make(map[uint64]*ReceiptRequest)
+		var delivered, undelivered int
+		for _, receipts := range *delivery.receipts {
+			// Block numbers are added to the rd.delivered bitmap here, only for receipts which root matches
+			// the ReceiptHash of one of the requested headers
+			receiptHash := types.DeriveSha(types.Receipts(receipts))
+			blockNum, ok := rd.takeRequested(receiptHash)
+			if !ok {
+				undelivered++
+				continue
+			}
+			req := rd.requests[blockNum-rd.requestedLow]
+			if req != nil {
+				if _, ok := reqMap[req.BlockNums[0]]; !ok {
+					reqMap[req.BlockNums[0]] = req
+				}
+			}
+
+			rd.deliveries[blockNum-rd.requestedLow] = receipts
+			rd.delivered.Add(blockNum)
+			delivered++
+		}
+		// Clean up the requests, the blocks of truncated responses are going to be requested again
+		for _, req := range reqMap {
+			for _, blockNum := range req.BlockNums {
+				if blockNum >= rd.requestedLow {
+					rd.requests[blockNum-rd.requestedLow] = nil
+				}
+			}
+		}
+		if total := delivered + undelivered; total > 0 {
+			// Approximate numbers
+			rd.deliveredCount += float64(delivery.lenOfP2PMessage) * float64(delivered) / float64(total)
+			rd.wastedCount += float64(delivery.lenOfP2PMessage) * float64(undelivered) / float64(total)
+		}
+	}
+}
+
+// GetDeliveries returns the receipts of the consecutive blocks starting from the returned block number.
+// Receipts are nil for the blocks which already have them in the database
+func (rd *ReceiptDownload) GetDeliveries() (uint64, []types.Receipts) {
+	rd.doDeliverReceipts()
+
+	from := rd.requestedLow
+	var i uint64
+	for i = 0; !rd.delivered.IsEmpty() && rd.requestedLow+i == rd.delivered.Minimum(); i++ {
+		rd.delivered.Remove(rd.requestedLow + i)
+	}
+	// Move the deliveries back
+	// rd.requestedLow can only be moved forward if there are consecutive block numbers present in the rd.delivered map
+	var receipts []types.Receipts
+	if i > 0 {
+		receipts = make([]types.Receipts, i)
+		copy(receipts, rd.deliveries[:i])
+		copy(rd.deliveries, rd.deliveries[i:])
+		copy(rd.requests, rd.requests[i:])
+		for j := len(rd.deliveries) - int(i); j < len(rd.deliveries); j++ {
+			rd.deliveries[j] = nil
+			rd.requests[j] = nil
+		}
+		rd.requestedLow += i
+	}
+	return from, receipts
+}
+
+func (rd *ReceiptDownload) DeliveryCounts() (float64, float64) {
+	return rd.deliveredCount, rd.wastedCount
+}
@@ -0,0 +1,57 @@
+package receiptdownload
+
+import (
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+)
+
+// MaxReceiptsInRequest is the number of blocks which receipts are requested in one message.
+// Receipts are much bigger than bodies, responses are usually truncated by the soft limit of the serving side
+const MaxReceiptsInRequest = 64
+
+type Delivery struct {
+	peerID          [64]byte
+	receipts        *[][]*types.Receipt
+	lenOfP2PMessage uint64
+}
+
+// ReceiptDownload represents the state of receipts downloading process
+type ReceiptDownload struct {
+	peerMap          map[[64]byte]int
+	requestedMap     map[common.Hash][]uint64 // ReceiptHash of the header => block numbers, blocks with the same receipts share the root
+	DeliveryNotify   chan struct{}
+	deliveryCh       chan Delivery
+	delivered        *roaring64.Bitmap
+	deliveries       []types.Receipts // nil for the blocks which already have receipts in the database
+	requests         []*ReceiptRequest
+	maxProgress      uint64
+	requestedLow     uint64 // Lower bound of block number for outstanding requests
+	outstandingLimit uint64 // Limit of number of outstanding blocks for receipt requests
+	deliveredCount   float64
+	wastedCount      float64
+}
+
+// ReceiptRequest is a sketch of the request for receipts of the canonical blocks
+type ReceiptRequest struct {
+	BlockNums []uint64
+	Hashes    []common.Hash
+	peerID    [64]byte
+	waitUntil uint64
+}
+
+// NewReceiptDownload create a new receipt download state object
+func NewReceiptDownload(outstandingLimit int) *ReceiptDownload {
+	rd := &ReceiptDownload{
+		requestedMap:     make(map[common.Hash][]uint64),
+		outstandingLimit: uint64(outstandingLimit),
+		delivered:        roaring64.New(),
+		deliveries:       make([]types.Receipts, outstandingLimit+MaxReceiptsInRequest),
+		requests:         make([]*ReceiptRequest, outstandingLimit+MaxReceiptsInRequest),
+		peerMap:          make(map[[64]byte]int),
+		// DeliveryNotify is a mailbox with no more than one letter in it, same as in the body download
+		DeliveryNotify: make(chan struct{}, 1),
+		deliveryCh:     make(chan Delivery, outstandingLimit+MaxReceiptsInRequest),
+	}
+	return rd
+}
@@ -0,0 +1,148 @@
+package receiptdownload
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/stretchr/testify/require"
+)
+
+type headerReader struct{}
+
+func (headerReader) Header(_ context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (*types.Header, error) {
+	return rawdb.ReadHeader(tx, hash, blockHeight), nil
+}
+
+func (headerReader) HeaderByNumber(_ context.Context, tx kv.Getter, blockHeight uint64) (*types.Header, error) {
+	return rawdb.ReadHeaderByNumber(tx, blockHeight), nil
+}
+
+func (headerReader) HeaderByHash(_ context.Context, tx kv.Getter, hash common.Hash) (*types.Header, error) {
+	return rawdb.ReadHeaderByHash(tx, hash)
+}
+
+func TestDeliverReceipts(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+
+	receipts := types.Receipts{types.NewReceipt(false, 21000), types.NewReceipt(true, 42000)}
+	receipts[0].Logs = types.Logs{{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte{3}}}
+	receipts[0].Bloom = types.CreateBloom(types.Receipts{receipts[0]})
+	stored := types.Receipts{types.NewReceipt(false, 21000)}
+	// Block 1 has receipts to download, block 2 has no transactions, receipts of block 3 are in the database
+	headers := []*types.Header{
+		{Number: big.NewInt(1), ReceiptHash: types.DeriveSha(receipts)},
+		{Number: big.NewInt(2), ReceiptHash: types.EmptyRootHash},
+		{Number: big.NewInt(3), ReceiptHash: types.DeriveSha(stored)},
+	}
+	for _, h := range headers {
+		rawdb.WriteHeader(tx, h)
+		require.NoError(t, rawdb.WriteCanonicalHash(tx, h.Hash(), h.Number.Uint64()))
+	}
+	require.NoError(t, rawdb.WriteReceipts(tx, 3, stored))
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Bodies, 3))
+
+	rd := NewReceiptDownload(100)
+	require.NoError(t, rd.UpdateFromDb(tx))
+	req, _, err := rd.RequestMoreReceipts(tx, headerReader{}, 0, 0)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	require.Equal(t, []uint64{1}, req.BlockNums)
+	require.Equal(t, []common.Hash{headers[0].Hash()}, req.Hashes)
+	rd.RequestSent(req, 10, [64]byte{1})
+
+	// Nothing else to request, and nothing is delivered before block 1
+	req, _, err = rd.RequestMoreReceipts(tx, headerReader{}, 0, 0)
+	require.NoError(t, err)
+	require.Nil(t, req)
+	_, delivered := rd.GetDeliveries()
+	require.Empty(t, delivered)
+
+	// Receipts not matching any requested header are wasted
+	rd.DeliverReceipts(&[][]*types.Receipt{stored, receipts}, 100, [64]byte{1})
+	from, delivered := rd.GetDeliveries()
+	require.Equal(t, uint64(1), from)
+	require.Equal(t, []types.Receipts{receipts, {}, nil}, delivered)
+	deliveredCount, wastedCount := rd.DeliveryCounts()
+	require.Equal(t, 50.0, deliveredCount)
+	require.Equal(t, 50.0, wastedCount)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+
+	receipts := types.Receipts{types.NewReceipt(false, 21000)}
+	header := &types.Header{Number: big.NewInt(1), ReceiptHash: types.DeriveSha(receipts)}
+	rawdb.WriteHeader(tx, header)
+	require.NoError(t, rawdb.WriteCanonicalHash(tx, header.Hash(), 1))
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Bodies, 1))
+
+	rd := NewReceiptDownload(100)
+	require.NoError(t, rd.UpdateFromDb(tx))
+	req, _, err := rd.RequestMoreReceipts(tx, headerReader{}, 0, 0)
+	require.NoError(t, err)
+	rd.RequestSent(req, 10, [64]byte{1})
+
+	// The request is repeated after the timeout
+	req, _, err = rd.RequestMoreReceipts(tx, headerReader{}, 0, 5)
+	require.NoError(t, err)
+	require.Nil(t, req)
+	req, _, err = rd.RequestMoreReceipts(tx, headerReader{}, 0, 11)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	require.Equal(t, []uint64{1}, req.BlockNums)
+}
+
+func TestDeliverReceiptsChannelFull(t *testing.T) {
+	rd := NewReceiptDownload(0)
+	receipts := [][]*types.Receipt{{types.NewReceipt(false, 21000)}}
+	// Nobody reads the deliveries, the ones not fitting into the channel are dropped instead of blocking
+	for i := 0; i < cap(rd.deliveryCh)+10; i++ {
+		rd.DeliverReceipts(&receipts, 100, [64]byte{1})
+	}
+	require.Equal(t, cap(rd.deliveryCh), len(rd.deliveryCh))
+}
+
+func TestDeliverReceiptsSameRoot(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+
+	// Both blocks hold one successful transfer without logs, their receipt roots are the same
+	receipts := types.Receipts{types.NewReceipt(false, 21000)}
+	headers := []*types.Header{
+		{Number: big.NewInt(1), ReceiptHash: types.DeriveSha(receipts), Extra: []byte{1}},
+		{Number: big.NewInt(2), ReceiptHash: types.DeriveSha(receipts), Extra: []byte{2}},
+	}
+	for _, h := range headers {
+		rawdb.WriteHeader(tx, h)
+		require.NoError(t, rawdb.WriteCanonicalHash(tx, h.Hash(), h.Number.Uint64()))
+	}
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Bodies, 2))
+
+	rd := NewReceiptDownload(100)
+	require.NoError(t, rd.UpdateFromDb(tx))
+	req, _, err := rd.RequestMoreReceipts(tx, headerReader{}, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, req.BlockNums)
+	rd.RequestSent(req, 10, [64]byte{1})
+
+	// A request repeated after the timeout doesn't register the blocks twice
+	req, _, err = rd.RequestMoreReceipts(tx, headerReader{}, 0, 11)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, req.BlockNums)
+	rd.RequestSent(req, 20, [64]byte{1})
+
+	rd.DeliverReceipts(&[][]*types.Receipt{receipts, receipts, receipts}, 90, [64]byte{1})
+	from, delivered := rd.GetDeliveries()
+	require.Equal(t, uint64(1), from)
+	require.Equal(t, []types.Receipts{receipts, receipts}, delivered)
+	deliveredCount, wastedCount := rd.DeliveryCounts()
+	require.Equal(t, 60.0, deliveredCount)
+	require.Equal(t, 30.0, wastedCount)
+	require.Empty(t, rd.requestedMap)
+}
@@ -5,16 +5,26 @@ import (
 	"testing"
 
 	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/sentry"
 	"github.com/ledgerwatch/erigon/common"
 	"github.com/ledgerwatch/erigon/common/u256"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/consensus/serenity"
 	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/state"
 	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
 	"github.com/ledgerwatch/erigon/eth/protocols/eth"
+	stages2 "github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb/privateapi"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
 	"github.com/ledgerwatch/erigon/params"
 	"github.com/ledgerwatch/erigon/rlp"
 	"github.com/ledgerwatch/erigon/turbo/engineapi"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
 	"github.com/ledgerwatch/erigon/turbo/stages"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -647,7 +657,7 @@ func TestPoSSyncWithInvalidHeader(t *testing.T) {
 	invalidTip.ParentHash = invalidParent.Hash()
 
 	// Send a payload with the parent missing
-	payloadMessage := types.NewBlockFromStorage(invalidTip.Hash(), invalidTip, chain.TopBlock.Transactions(), nil)
+	payloadMessage := types.NewBlockFromStorage(invalidTip.Hash(), invalidTip, chain.TopBlock.Transactions(), nil, nil)
 	m.SendPayloadRequest(payloadMessage)
 
 	initialCycle := false
@@ -688,3 +698,151 @@ func TestPoSSyncWithInvalidHeader(t *testing.T) {
 	assert.True(t, bad)
 	assert.Equal(t, lastValidHash, lastValidHeader.Hash())
 }
+
+func TestSetHead(t *testing.T) {
+	m := stages.Mock(t)
+
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 10, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	}, false /* intermediateHashes */)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	// History of the last 3 blocks only
+	_, err = stages.SetHead(m.Ctx, m.DB, m.Sync, m.Notifications, prune.Mode{History: prune.Distance(3)}, nil, 5)
+	require.Error(t, err)
+	// Not below the head
+	_, err = stages.SetHead(m.Ctx, m.DB, m.Sync, m.Notifications, prune.DefaultMode, nil, 10)
+	require.Error(t, err)
+
+	unwinds, err := stages.SetHead(m.Ctx, m.DB, m.Sync, m.Notifications, prune.DefaultMode, nil, 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, unwinds)
+	for _, u := range unwinds {
+		assert.Equal(t, uint64(10), u.From, u.Stage)
+		assert.Equal(t, uint64(5), u.To, u.Stage)
+	}
+
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, stage := range []stages2.SyncStage{stages2.Headers, stages2.Bodies, stages2.Senders, stages2.Execution, stages2.Finish} {
+		progress, err := stages2.GetStageProgress(tx, stage)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), progress, stage)
+	}
+	assert.Equal(t, chain.Blocks[4].Hash(), rawdb.ReadHeadBlockHash(tx))
+	assert.Equal(t, chain.Blocks[4].Hash(), rawdb.ReadHeadHeaderHash(tx))
+	canonical, err := rawdb.ReadCanonicalHash(tx, 6)
+	require.NoError(t, err)
+	assert.Equal(t, common.Hash{}, canonical)
+}
+
+func TestEngineApiV2Withdrawals(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := common.HexToAddress("0x000000000000000000000000000000000000dddd")
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.LondonBlock = common.Big0
+	config.ShanghaiBlock = common.Big0
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc:  core.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+	}
+	m := stages.MockWithEverything(t, gspec, key, prune.DefaultMode, serenity.New(ethash.NewFaker()), true /* withTxPool */, false)
+	backend := privateapi.NewEthBackendServer(m.Ctx, nil, m.DB, m.Notifications.Events, snapshotsync.NewBlockReader(), m.ChainConfig, m.AssembleBlockPOS, m.HeaderDownload(), true)
+
+	// stageLoopStep waits for the txpool to process the state changes of the cycle, if it has any.
+	// There are state changes at the genesis and when the head moves
+	stageLoopStep := func(stateChanges bool) common.Hash {
+		if stateChanges {
+			m.ReceiveWg.Add(1)
+		}
+		headBlockHash, err := stages.StageLoopStep(m.Ctx, m.DB, m.Sync, 0, m.Notifications, false, m.UpdateHead, nil)
+		require.NoError(t, err)
+		m.ReceiveWg.Wait()
+		return headBlockHash
+	}
+	// A cycle without requests switches the header download to PoS sync, the backend replies SYNCING before
+	stageLoopStep(true)
+
+	// runStageLoop processes the engine API request the backend is waiting a reply for
+	runStageLoop := func(stateChanges bool, call func() error) {
+		done := make(chan error, 1)
+		go func() { done <- call() }()
+		m.HeaderDownload().BeaconRequestList.WaitForRequest(true, false)
+		stages.SendPayloadStatus(m.HeaderDownload(), stageLoopStep(stateChanges), nil)
+		require.NoError(t, <-done)
+	}
+	forkChoiceState := func(hash common.Hash) *remote.EngineForkChoiceState {
+		return &remote.EngineForkChoiceState{
+			HeadBlockHash:      gointerfaces.ConvertHashToH256(hash),
+			SafeBlockHash:      gointerfaces.ConvertHashToH256(hash),
+			FinalizedBlockHash: gointerfaces.ConvertHashToH256(hash),
+		}
+	}
+
+	var fcuReply *remote.EngineForkChoiceUpdatedReply
+	runStageLoop(true, func() (err error) {
+		fcuReply, err = backend.EngineForkChoiceUpdatedV2(m.Ctx, &remote.EngineForkChoiceUpdatedRequest{ForkchoiceState: forkChoiceState(m.Genesis.Hash())}, nil)
+		return err
+	})
+	require.Equal(t, remote.EngineStatus_VALID, fcuReply.PayloadStatus.Status)
+
+	// Build a block with withdrawals on top of the genesis
+	withdrawals := []*types.Withdrawal{
+		{Index: 0, Validator: 1, Address: recipient, Amount: 7},
+		{Index: 1, Validator: 2, Address: address, Amount: 3},
+	}
+	fcuRequest := &remote.EngineForkChoiceUpdatedRequest{
+		ForkchoiceState: forkChoiceState(m.Genesis.Hash()),
+		PayloadAttributes: &remote.EnginePayloadAttributes{
+			Timestamp:             m.Genesis.Time() + 12,
+			PrevRandao:            gointerfaces.ConvertHashToH256(common.Hash{1}),
+			SuggestedFeeRecipient: gointerfaces.ConvertAddressToH160(common.Address{1}),
+		},
+	}
+	_, err := backend.EngineForkChoiceUpdatedV2(m.Ctx, fcuRequest, nil)
+	require.ErrorContains(t, err, "withdrawals are required after Shanghai")
+	fcuReply, err = backend.EngineForkChoiceUpdatedV2(m.Ctx, fcuRequest, withdrawals)
+	require.NoError(t, err)
+	require.Equal(t, remote.EngineStatus_VALID, fcuReply.PayloadStatus.Status)
+	require.NotZero(t, fcuReply.PayloadId)
+
+	payload, payloadWithdrawals, blockValue, err := backend.EngineGetPayloadV2(m.Ctx, &remote.EngineGetPayloadRequest{PayloadId: fcuReply.PayloadId})
+	require.NoError(t, err)
+	require.Equal(t, withdrawals, payloadWithdrawals)
+	require.True(t, blockValue.IsZero())
+	require.Equal(t, uint64(1), payload.BlockNumber)
+
+	// The withdrawals are part of the block hash
+	_, err = backend.EngineNewPayloadV2(m.Ctx, payload, nil)
+	require.ErrorContains(t, err, "withdrawals are required after Shanghai")
+	status, err := backend.EngineNewPayloadV2(m.Ctx, payload, withdrawals[:1])
+	require.NoError(t, err)
+	require.Equal(t, remote.EngineStatus_INVALID_BLOCK_HASH, status.Status)
+
+	runStageLoop(true, func() (err error) {
+		status, err = backend.EngineNewPayloadV2(m.Ctx, payload, payloadWithdrawals)
+		return err
+	})
+	require.Equal(t, remote.EngineStatus_VALID, status.Status)
+	blockHash := common.Hash(gointerfaces.ConvertH256ToHash(payload.BlockHash))
+	runStageLoop(false /* the new payload moved the head */, func() (err error) {
+		fcuReply, err = backend.EngineForkChoiceUpdatedV2(m.Ctx, &remote.EngineForkChoiceUpdatedRequest{ForkchoiceState: forkChoiceState(blockHash)}, nil)
+		return err
+	})
+	require.Equal(t, remote.EngineStatus_VALID, fcuReply.PayloadStatus.Status)
+
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.Equal(t, blockHash, rawdb.ReadHeadBlockHash(tx))
+	block, _, err := rawdb.ReadBlockWithSenders(tx, blockHash, 1)
+	require.NoError(t, err)
+	require.Equal(t, withdrawals, block.Withdrawals())
+	statedb := state.New(state.NewPlainStateReader(tx))
+	require.Equal(t, new(uint256.Int).Mul(uint256.NewInt(7), uint256.NewInt(params.GWei)), statedb.GetBalance(recipient))
+	require.Equal(t, new(uint256.Int).Add(uint256.NewInt(params.Ether), new(uint256.Int).Mul(uint256.NewInt(3), uint256.NewInt(params.GWei))), statedb.GetBalance(address))
+}
@@ -0,0 +1,209 @@
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/misc"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/eth/stagedsync"
+	"github.com/ledgerwatch/erigon/eth/stagedsync/stages"
+	"github.com/ledgerwatch/erigon/ethdb/prune"
+	"github.com/ledgerwatch/erigon/turbo/snapshotsync"
+	"github.com/ledgerwatch/erigon/turbo/stages/headerdownload"
+	"github.com/ledgerwatch/log/v3"
+	"go.uber.org/atomic"
+)
+
+var ErrSetHeadInProgress = errors.New("set head is in progress")
+
+// StageUnwind reports the progress of a stage before and after a set head
+type StageUnwind struct {
+	Stage string `json:"stage"`
+	From  uint64 `json:"from"`
+	To    uint64 `json:"to"`
+}
+
+type setHeadRequest struct {
+	ctx    context.Context
+	block  uint64
+	result chan setHeadResult
+}
+
+type setHeadResult struct {
+	unwinds []StageUnwind
+	err     error
+}
+
+// HeadSetter passes set head requests to the stage loop, which serves them in between the sync cycles,
+// so the chain is never unwound while blocks are being inserted
+type HeadSetter struct {
+	pruneMode  prune.Mode
+	snapshots  *snapshotsync.RoSnapshots
+	requests   chan *setHeadRequest
+	inProgress atomic.Bool
+}
+
+func NewHeadSetter(pruneMode prune.Mode, snapshots *snapshotsync.RoSnapshots) *HeadSetter {
+	return &HeadSetter{
+		pruneMode: pruneMode,
+		snapshots: snapshots,
+		requests:  make(chan *setHeadRequest),
+	}
+}
+
+// SetHead waits for the stage loop to finish its current cycle and unwinds all stages down to the given block
+func (h *HeadSetter) SetHead(ctx context.Context, block uint64) ([]StageUnwind, error) {
+	req := &setHeadRequest{ctx: ctx, block: block, result: make(chan setHeadResult, 1)}
+	select {
+	case h.requests <- req:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	// The result channel is buffered, the stage loop doesn't block if nobody waits for the result anymore
+	select {
+	case res := <-req.result:
+		return res.unwinds, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// InProgress returns true while the stage loop is unwinding for a set head request. Block production and
+// the txpool should not accept anything new meanwhile.
+func (h *HeadSetter) InProgress() bool {
+	return h.inProgress.Load()
+}
+
+func (h *HeadSetter) serve(db kv.RwDB, sync *stagedsync.Sync, hd *headerdownload.HeaderDownload, notifications *stagedsync.Notifications) {
+	var req *setHeadRequest
+	select {
+	case req = <-h.requests:
+	default:
+		return
+	}
+	h.inProgress.Store(true)
+	defer h.inProgress.Store(false)
+
+	unwinds, err := SetHead(req.ctx, db, sync, notifications, h.pruneMode, h.snapshots, req.block)
+	if recoveryErr := hd.RecoverFromDb(db); recoveryErr != nil {
+		log.Error("Failed to recover header sentriesClient", "err", recoveryErr)
+	}
+	req.result <- setHeadResult{unwinds: unwinds, err: err}
+}
+
+// SetHead unwinds all stages of the sync down to the given block in one transaction and returns the stages
+// it has moved. The target must be below the current head and above everything pruned or frozen in snapshots.
+// Must not be called concurrently with a sync cycle.
+func SetHead(
+	ctx context.Context,
+	db kv.RwDB,
+	sync *stagedsync.Sync,
+	notifications *stagedsync.Notifications,
+	pruneMode prune.Mode,
+	snapshots *snapshotsync.RoSnapshots,
+	block uint64,
+) ([]StageUnwind, error) {
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = checkSetHeadTarget(tx, pruneMode, snapshots, block); err != nil {
+		return nil, err
+	}
+	progressBefore := make([]uint64, len(stages.AllStages))
+	for i, stage := range stages.AllStages {
+		if progressBefore[i], err = stages.GetStageProgress(tx, stage); err != nil {
+			return nil, err
+		}
+	}
+
+	if notifications != nil && notifications.Accumulator != nil {
+		notifications.Accumulator.Reset(tx.ViewID())
+	}
+	log.Info("Set head", "block", block)
+	start := time.Now()
+	sync.UnwindTo(block, common.Hash{})
+	if err = sync.RunUnwind(db, tx); err != nil {
+		return nil, err
+	}
+	// Headers stage keeps its progress when nothing is marked bad, so that the next cycle could pick
+	// the heaviest chain. Here the head is set explicitly.
+	headersProgress, err := stages.GetStageProgress(tx, stages.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if headersProgress > block {
+		hash, err := rawdb.ReadCanonicalHash(tx, block)
+		if err != nil {
+			return nil, err
+		}
+		if err = rawdb.WriteHeadHeaderHash(tx, hash); err != nil {
+			return nil, err
+		}
+		rawdb.WriteHeadBlockHash(tx, hash)
+		if err = stages.SaveStageProgress(tx, stages.Headers, block); err != nil {
+			return nil, err
+		}
+	}
+
+	var unwinds []StageUnwind
+	for i, stage := range stages.AllStages {
+		progress, err := stages.GetStageProgress(tx, stage)
+		if err != nil {
+			return nil, err
+		}
+		if progress != progressBefore[i] {
+			log.Info("Set head", "stage", stage, "from", progressBefore[i], "to", progress)
+			unwinds = append(unwinds, StageUnwind{Stage: string(stage), From: progressBefore[i], To: progress})
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	log.Info("Set head done", "block", block, "in", time.Since(start))
+
+	if notifications != nil && notifications.Accumulator != nil {
+		if err = db.View(ctx, func(tx kv.Tx) error {
+			header := rawdb.ReadCurrentHeader(tx)
+			if header == nil {
+				return nil
+			}
+			pendingBaseFee := misc.CalcBaseFee(notifications.Accumulator.ChainConfig(), header)
+			notifications.Accumulator.SendAndReset(ctx, notifications.StateChangesConsumer, pendingBaseFee.Uint64(), header.GasLimit)
+			return nil
+		}); err != nil {
+			return unwinds, err
+		}
+	}
+	return unwinds, nil
+}
+
+func checkSetHeadTarget(tx kv.Tx, pruneMode prune.Mode, snapshots *snapshotsync.RoSnapshots, block uint64) error {
+	head, err := stages.GetStageProgress(tx, stages.Finish)
+	if err != nil {
+		return err
+	}
+	if block >= head {
+		return fmt.Errorf("block %d is not below the current head %d", block, head)
+	}
+	if snapshots != nil && snapshots.Cfg().Enabled && block < snapshots.BlocksAvailable() {
+		return fmt.Errorf("block %d is frozen in snapshots, lowest block to set head to is %d", block, snapshots.BlocksAvailable())
+	}
+	if pruneMode.History.Enabled() {
+		execution, err := stages.GetStageProgress(tx, stages.Execution)
+		if err != nil {
+			return err
+		}
+		if prunedTo := pruneMode.History.PruneTo(execution); block < prunedTo {
+			return fmt.Errorf("history below block %d is pruned, cannot set head to %d", prunedTo, block)
+		}
+	}
+	return nil
+}
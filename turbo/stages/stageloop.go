@@ -18,6 +18,7 @@ import (
 	"github.com/ledgerwatch/erigon/cmd/sentry/sentry"
 	"github.com/ledgerwatch/erigon/cmd/state/exec22"
 	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/bor"
 	"github.com/ledgerwatch/erigon/consensus/misc"
 	"github.com/ledgerwatch/erigon/core/rawdb"
 	"github.com/ledgerwatch/erigon/core/types"
@@ -72,6 +73,7 @@ func StageLoop(
 	updateHead func(ctx context.Context, head uint64, hash common.Hash, td *uint256.Int),
 	waitForDone chan struct{},
 	loopMinTime time.Duration,
+	headSetter *HeadSetter,
 ) {
 	defer close(waitForDone)
 	initialCycle := true
@@ -86,6 +88,10 @@ func StageLoop(
 			// continue
 		}
 
+		if headSetter != nil {
+			headSetter.serve(db, sync, hd, notifications)
+		}
+
 		// Estimate the current top height seen from the peer
 		height := hd.TopSeenHeight()
 		headBlockHash, err := StageLoopStep(ctx, db, sync, height, notifications, initialCycle, updateHead, nil)
@@ -370,6 +376,8 @@ func NewStagedSync(ctx context.Context,
 	headCh chan *types.Block,
 	txNums *exec22.TxNums, agg *state.Aggregator22,
 	forkValidator *engineapi.ForkValidator,
+	vmConfig *vm.Config,
+	customStages []stagedsync.CustomStage,
 ) (*stagedsync.Sync, error) {
 	dirs := cfg.Dirs
 	var blockReader services.FullBlockReader
@@ -388,8 +396,14 @@ func NewStagedSync(ctx context.Context,
 	if isBor {
 		sprint = controlServer.ChainConfig.Bor.Sprint
 	}
+	var borDb kv.RwDB
+	var heimdallClient bor.IHeimdallClient
+	if casted, ok := controlServer.Engine.(*bor.Bor); ok && !cfg.WithoutHeimdall {
+		borDb = casted.DB
+		heimdallClient = casted.HeimdallClient
+	}
 
-	return stagedsync.New(
+	stagesList, unwindOrder, pruneOrder, err := stagedsync.WithCustomStages(
 		stagedsync.DefaultStages(ctx, cfg.Prune,
 			stagedsync.StageSnapshotsCfg(
 				db,
@@ -432,6 +446,7 @@ func NewStagedSync(ctx context.Context,
 				cfg.HistoryV2,
 				txNums,
 			),
+			stagedsync.StageBorHeimdallCfg(db, borDb, controlServer.ChainConfig, heimdallClient, blockReader),
 			stagedsync.StageIssuanceCfg(db, controlServer.ChainConfig, blockReader, cfg.EnabledIssuance),
 			stagedsync.StageSendersCfg(db, controlServer.ChainConfig, false, dirs.Tmp, cfg.Prune, blockRetire, controlServer.Hd),
 			stagedsync.StageExecuteBlocksCfg(
@@ -441,7 +456,7 @@ func NewStagedSync(ctx context.Context,
 				nil,
 				controlServer.ChainConfig,
 				controlServer.Engine,
-				&vm.Config{},
+				vmConfig,
 				notifications.Accumulator,
 				cfg.StateStream,
 				/*stateStream=*/ false,
@@ -456,6 +471,8 @@ func NewStagedSync(ctx context.Context,
 			),
 			stagedsync.StageHashStateCfg(db, dirs, cfg.HistoryV2, txNums, agg),
 			stagedsync.StageTrieCfg(db, true, true, false, dirs.Tmp, blockReader, controlServer.Hd, cfg.HistoryV2, txNums, agg),
+			stagedsync.StageVerkleTrieCfg(db, cfg.VerkleTrie, dirs.Tmp),
+			stagedsync.StageReceiptsCfg(db, controlServer.Rd, controlServer.SendReceiptRequest, cfg.Sync.BodyDownloadTimeoutSeconds, snapshots, blockReader, cfg.Sync.DownloadReceipts),
 			stagedsync.StageHistoryCfg(db, cfg.Prune, dirs.Tmp),
 			stagedsync.StageLogIndexCfg(db, cfg.Prune, dirs.Tmp),
 			stagedsync.StageCallTracesCfg(db, cfg.Prune, 0, dirs.Tmp),
@@ -463,10 +480,15 @@ func NewStagedSync(ctx context.Context,
 			stagedsync.StageFinishCfg(db, dirs.Tmp, headCh, forkValidator), runInTestMode),
 		stagedsync.DefaultUnwindOrder,
 		stagedsync.DefaultPruneOrder,
-	), nil
+		customStages,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stagedsync.New(stagesList, unwindOrder, pruneOrder), nil
 }
 
-func NewInMemoryExecution(ctx context.Context, db kv.RwDB, cfg *ethconfig.Config, controlServer *sentry.MultiClient, dirs datadir.Dirs, notifications *stagedsync.Notifications, snapshots *snapshotsync.RoSnapshots, txNums *exec22.TxNums, agg *state.Aggregator22) (*stagedsync.Sync, error) {
+func NewInMemoryExecution(ctx context.Context, db kv.RwDB, cfg *ethconfig.Config, controlServer *sentry.MultiClient, dirs datadir.Dirs, notifications *stagedsync.Notifications, snapshots *snapshotsync.RoSnapshots, txNums *exec22.TxNums, agg *state.Aggregator22, vmConfig *vm.Config) (*stagedsync.Sync, error) {
 	var blockReader services.FullBlockReader
 	if cfg.Snapshot.Enabled {
 		blockReader = snapshotsync.NewBlockReaderWithSnapshots(snapshots)
@@ -513,7 +535,7 @@ func NewInMemoryExecution(ctx context.Context, db kv.RwDB, cfg *ethconfig.Config
 				nil,
 				controlServer.ChainConfig,
 				controlServer.Engine,
-				&vm.Config{},
+				vmConfig,
 				notifications.Accumulator,
 				cfg.StateStream,
 				true,
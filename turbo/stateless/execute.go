@@ -0,0 +1,48 @@
+package stateless
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/params"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// ExecuteBlock executes the block on top of the state given by the witness and returns the state root
+// before and after the execution. Hashes of the ancestors other than the parent are not known without a
+// database, so the blocks using BLOCKHASH for them can't be executed.
+func ExecuteBlock(chainConfig *params.ChainConfig, engine consensus.Engine, block *types.Block, witness []byte) (preRoot, postRoot common.Hash, err error) {
+	w, err := trie.NewWitnessFromReader(bytes.NewReader(witness), false)
+	if err != nil {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("decode witness: %w", err)
+	}
+	t, err := trie.BuildTrieFromWitness(w, false)
+	if err != nil {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("build trie from witness: %w", err)
+	}
+	preRoot = t.Hash()
+
+	var hashError error
+	getHash := func(n uint64) common.Hash {
+		if n+1 == block.NumberU64() {
+			return block.ParentHash()
+		}
+		hashError = fmt.Errorf("hash of block %d is not available to stateless execution", n)
+		return common.Hash{}
+	}
+	s := NewWitnessState(t)
+	_, err = core.ExecuteBlockEphemerally(chainConfig, &vm.Config{}, getHash, engine, block, s, s, nil, nil, false, nil)
+	if hashError != nil {
+		return preRoot, common.Hash{}, hashError
+	}
+	if err != nil {
+		return preRoot, common.Hash{}, err
+	}
+	postRoot, err = s.Root()
+	return preRoot, postRoot, err
+}
@@ -0,0 +1,107 @@
+// Package stateless builds block witnesses out of the state of the node, and executes blocks
+// with nothing but a witness as the source of the state (stateless execution).
+package stateless
+
+import (
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// Recorder is a state reader which remembers every account, storage slot and contract code
+// read during the execution of a block. As a state writer it remembers the deleted accounts and
+// storage slots, and discards everything else. The result of the recording is used by BuildWitness.
+type Recorder struct {
+	reader  state.StateReader
+	rlb     *trie.RetainListBuilder
+	codes   map[common.Hash][]byte // address hash => contract code
+	deleted [][]byte               // deleted keys in the form of HashedAccounts and HashedStorage
+}
+
+var _ state.StateReader = (*Recorder)(nil)
+var _ state.WriterWithChangeSets = (*Recorder)(nil)
+
+func NewRecorder(reader state.StateReader) *Recorder {
+	return &Recorder{
+		reader: reader,
+		rlb:    trie.NewRetainListBuilder(),
+		codes:  map[common.Hash][]byte{},
+	}
+}
+
+func (r *Recorder) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	addrHash := crypto.Keccak256Hash(address[:])
+	r.rlb.AddTouch(addrHash[:])
+	return r.reader.ReadAccountData(address)
+}
+
+func (r *Recorder) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	r.rlb.AddStorageTouch(storageKey(address, incarnation, key))
+	return r.reader.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *Recorder) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	code, err := r.reader.ReadAccountCode(address, incarnation, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) > 0 {
+		r.rlb.ReadCode(codeHash)
+		r.codes[crypto.Keccak256Hash(address[:])] = code
+	}
+	return code, nil
+}
+
+// ReadAccountCodeSize records the whole code, because the witness has no other way to prove its size
+func (r *Recorder) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	code, err := r.ReadAccountCode(address, incarnation, codeHash)
+	return len(code), err
+}
+
+func (r *Recorder) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return r.reader.ReadAccountIncarnation(address)
+}
+
+func (r *Recorder) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	addrHash := crypto.Keccak256Hash(address[:])
+	r.rlb.AddTouch(addrHash[:])
+	return nil
+}
+
+func (r *Recorder) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	r.rlb.CreateCode(codeHash)
+	return nil
+}
+
+func (r *Recorder) DeleteAccount(address common.Address, original *accounts.Account) error {
+	addrHash := crypto.Keccak256Hash(address[:])
+	r.rlb.AddTouch(addrHash[:])
+	r.deleted = append(r.deleted, addrHash[:])
+	return nil
+}
+
+func (r *Recorder) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	k := storageKey(address, incarnation, key)
+	r.rlb.AddStorageTouch(k)
+	if value.IsZero() {
+		r.deleted = append(r.deleted, k)
+	}
+	return nil
+}
+
+func (r *Recorder) CreateContract(address common.Address) error {
+	return nil
+}
+
+func (r *Recorder) WriteChangeSets() error { return nil }
+
+func (r *Recorder) WriteHistory() error { return nil }
+
+// storageKey returns the key of the storage slot in the form of HashedStorage: {addrHash}{incarnation}{keyHash}
+func storageKey(address common.Address, incarnation uint64, key *common.Hash) []byte {
+	return dbutils.GenerateCompositeStorageKey(crypto.Keccak256Hash(address[:]), incarnation, crypto.Keccak256Hash(key[:]))
+}
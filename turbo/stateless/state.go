@@ -0,0 +1,158 @@
+package stateless
+
+import (
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/dbutils"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types/accounts"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// WitnessState reads the state from the trie built from a witness. The changes written into it are
+// buffered and applied to the trie by Root, because IntraBlockState writes the storage and the code of
+// an account before the account itself.
+// Reading a part of the state which is not in the witness returns an error.
+type WitnessState struct {
+	t *trie.Trie
+
+	accounts map[common.Hash]*accounts.Account // nil for deleted accounts
+	created  map[common.Hash]struct{}
+	storage  map[common.Hash]map[common.Hash][]byte
+	codes    map[common.Hash][]byte
+}
+
+var _ state.StateReader = (*WitnessState)(nil)
+var _ state.WriterWithChangeSets = (*WitnessState)(nil)
+
+func NewWitnessState(t *trie.Trie) *WitnessState {
+	return &WitnessState{
+		t:        t,
+		accounts: map[common.Hash]*accounts.Account{},
+		created:  map[common.Hash]struct{}{},
+		storage:  map[common.Hash]map[common.Hash][]byte{},
+		codes:    map[common.Hash][]byte{},
+	}
+}
+
+func (s *WitnessState) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	addrHash := crypto.Keccak256Hash(address[:])
+	acc, ok := s.t.GetAccount(addrHash[:])
+	if !ok {
+		return nil, fmt.Errorf("account %x is not in the witness", address)
+	}
+	if acc != nil && (acc.Root != trie.EmptyRoot || !acc.IsEmptyCodeHash()) {
+		// Incarnations are not part of the trie
+		acc.Incarnation = state.FirstContractIncarnation
+	}
+	return acc, nil
+}
+
+func (s *WitnessState) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	addrHash, keyHash := crypto.Keccak256Hash(address[:]), crypto.Keccak256Hash(key[:])
+	v, ok := s.t.Get(dbutils.GenerateCompositeTrieKey(addrHash, keyHash))
+	if !ok {
+		return nil, fmt.Errorf("storage %x of account %x is not in the witness", *key, address)
+	}
+	return v, nil
+}
+
+func (s *WitnessState) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	if codeHash == trie.EmptyCodeHash {
+		return nil, nil
+	}
+	addrHash := crypto.Keccak256Hash(address[:])
+	code, ok := s.t.GetAccountCode(addrHash[:])
+	if !ok || code == nil {
+		return nil, fmt.Errorf("code of account %x is not in the witness", address)
+	}
+	return code, nil
+}
+
+func (s *WitnessState) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	code, err := s.ReadAccountCode(address, incarnation, codeHash)
+	return len(code), err
+}
+
+// ReadAccountIncarnation returns 0, because incarnations are not part of the trie
+func (s *WitnessState) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (s *WitnessState) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	acc := new(accounts.Account)
+	acc.Copy(account)
+	s.accounts[crypto.Keccak256Hash(address[:])] = acc
+	return nil
+}
+
+func (s *WitnessState) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	s.codes[crypto.Keccak256Hash(address[:])] = code
+	return nil
+}
+
+func (s *WitnessState) DeleteAccount(address common.Address, original *accounts.Account) error {
+	addrHash := crypto.Keccak256Hash(address[:])
+	s.accounts[addrHash] = nil
+	delete(s.storage, addrHash)
+	delete(s.codes, addrHash)
+	return nil
+}
+
+func (s *WitnessState) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	addrHash := crypto.Keccak256Hash(address[:])
+	m, ok := s.storage[addrHash]
+	if !ok {
+		m = map[common.Hash][]byte{}
+		s.storage[addrHash] = m
+	}
+	m[crypto.Keccak256Hash(key[:])] = value.Bytes()
+	return nil
+}
+
+func (s *WitnessState) CreateContract(address common.Address) error {
+	addrHash := crypto.Keccak256Hash(address[:])
+	s.created[addrHash] = struct{}{}
+	delete(s.storage, addrHash)
+	return nil
+}
+
+func (s *WitnessState) WriteChangeSets() error { return nil }
+
+func (s *WitnessState) WriteHistory() error { return nil }
+
+// Root applies the buffered changes to the trie and returns its new root hash
+func (s *WitnessState) Root() (common.Hash, error) {
+	for addrHash, acc := range s.accounts {
+		if acc == nil {
+			s.t.Delete(addrHash[:])
+			continue
+		}
+		if _, ok := s.created[addrHash]; ok {
+			// The contract is created from scratch, drop the storage of its previous incarnation
+			s.t.Delete(addrHash[:])
+			acc.Root = trie.EmptyRoot
+		}
+		s.t.UpdateAccount(addrHash[:], acc)
+		if code, ok := s.codes[addrHash]; ok {
+			if err := s.t.UpdateAccountCode(addrHash[:], code); err != nil {
+				return common.Hash{}, err
+			}
+		}
+		for keyHash, v := range s.storage[addrHash] {
+			if len(v) == 0 {
+				s.t.Delete(dbutils.GenerateCompositeTrieKey(addrHash, keyHash))
+			} else {
+				s.t.Update(dbutils.GenerateCompositeTrieKey(addrHash, keyHash), v)
+			}
+		}
+	}
+	s.accounts = map[common.Hash]*accounts.Account{}
+	s.created = map[common.Hash]struct{}{}
+	s.storage = map[common.Hash]map[common.Hash][]byte{}
+	s.codes = map[common.Hash][]byte{}
+	return s.t.Hash(), nil
+}
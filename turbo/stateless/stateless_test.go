@@ -0,0 +1,193 @@
+package stateless
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon-lib/kv/memdb"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/consensus/ethash"
+	"github.com/ledgerwatch/erigon/core"
+	"github.com/ledgerwatch/erigon/core/state"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/core/vm"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/params"
+)
+
+func TestWitnessRoundTrip(t *testing.T) {
+	var (
+		db      = memdb.NewTestDB(t)
+		engine  = ethash.NewFaker()
+		config  = params.TestChainConfig
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		// clearer clears slot 1 of its storage, so that the root of its storage trie collapses
+		clearer = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		empty   = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		alloc   = core.GenesisAlloc{
+			address: {Balance: big.NewInt(1000000000)},
+			clearer: {
+				Code: common.FromHex("0x600060015500"),
+				Storage: map[common.Hash]common.Hash{
+					common.HexToHash("0x01"): common.HexToHash("0x05"),
+					// keccak of both slots starts with 0xf, they are in a sub-trie not touched by the block
+					common.HexToHash("0x06"): common.HexToHash("0x07"),
+					common.HexToHash("0x08"): common.HexToHash("0x09"),
+				},
+				Balance: new(big.Int),
+			},
+			empty: {Balance: new(big.Int)},
+		}
+	)
+	// Some more accounts, so that most of the state ends up hashed in the witness
+	for i := 0; i < 100; i++ {
+		alloc[common.BigToAddress(big.NewInt(int64(0x10000+i)))] = core.GenesisAccount{Balance: big.NewInt(1)}
+	}
+	genesis := (&core.Genesis{Config: config, Alloc: alloc}).MustCommit(db)
+	signer := types.LatestSignerForChainID(nil)
+	chain, err := core.GenerateChain(config, genesis, engine, db, 1, func(i int, b *core.BlockGen) {
+		for nonce, to := range []common.Address{clearer, empty, {0x1}} {
+			tx, err := types.SignTx(types.NewTransaction(uint64(nonce), to, uint256.NewInt(0), 100000, uint256.NewInt(1), nil), *signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.AddTx(tx)
+		}
+	}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := chain.TopBlock
+
+	tx, err := db.BeginRw(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	promoteHashedState(t, tx)
+	rec := NewRecorder(state.NewPlainStateReader(tx))
+	getHash := func(n uint64) common.Hash { return genesis.Hash() }
+	if _, err = core.ExecuteBlockEphemerally(config, &vm.Config{}, getHash, engine, block, rec, rec, nil, nil, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.deleted) != 2 {
+		t.Errorf("expected the empty account and a storage slot to be deleted, got %x", rec.deleted)
+	}
+	witness, err := BuildWitness(tx, genesis.Root(), rec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preRoot, postRoot, err := ExecuteBlock(config, engine, block, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preRoot != genesis.Root() {
+		t.Errorf("witness has root %x, expected %x", preRoot, genesis.Root())
+	}
+	if postRoot != block.Root() {
+		t.Errorf("stateless execution computed root %x, expected %x", postRoot, block.Root())
+	}
+
+	// Historical witnesses are built over the state rewound in a memory batch, its cursors can't go backwards
+	batch := memdb.NewMemoryBatch(tx)
+	defer batch.Rollback()
+	rec = NewRecorder(state.NewPlainStateReader(batch))
+	if _, err = core.ExecuteBlockEphemerally(config, &vm.Config{}, getHash, engine, block, rec, rec, nil, nil, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	batchWitness, err := BuildWitness(batch, genesis.Root(), rec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, postRoot, err = ExecuteBlock(config, engine, block, batchWitness); err != nil {
+		t.Fatal(err)
+	}
+	if postRoot != block.Root() {
+		t.Errorf("stateless execution with the witness built over the memory batch computed root %x, expected %x", postRoot, block.Root())
+	}
+
+	// A witness without the state touched by the block can't be used to execute it
+	if witness, err = BuildWitness(tx, genesis.Root(), NewRecorder(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = ExecuteBlock(config, engine, block, witness); err == nil {
+		t.Errorf("expected error when the state is missing from the witness")
+	}
+}
+
+// promoteHashedState fills HashedAccounts and HashedStorage from PlainState
+func promoteHashedState(t *testing.T, tx kv.RwTx) {
+	if err := tx.ForEach(kv.PlainState, nil, func(k, v []byte) error {
+		addrHash := crypto.Keccak256(k[:common.AddressLength])
+		if len(k) == common.AddressLength {
+			return tx.Put(kv.HashedAccounts, addrHash, common.CopyBytes(v))
+		}
+		newK := append(append(addrHash, k[common.AddressLength:common.AddressLength+common.IncarnationLength]...), crypto.Keccak256(k[common.AddressLength+common.IncarnationLength:])...)
+		return tx.Put(kv.HashedStorage, newK, common.CopyBytes(v))
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindNeighbours(t *testing.T) {
+	_, tx := memdb.NewTestTx(t)
+	accounts := [][]byte{
+		common.FromHex("0x1000000000000000000000000000000000000000000000000000000000000000"),
+		common.FromHex("0x10000000000000000000000000000000000000000000000000000000000000ff"),
+		common.FromHex("0x8000000000000000000000000000000000000000000000000000000000000000"),
+		common.FromHex("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+	}
+	for _, k := range accounts {
+		if err := tx.Put(kv.HashedAccounts, k, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prefix := append(common.CopyBytes(accounts[1]), 0, 0, 0, 0, 0, 0, 0, 1)
+	storage := [][]byte{
+		append(common.CopyBytes(prefix), common.FromHex("0x0000000000000000000000000000000000000000000000000000000000000000")...),
+		append(common.CopyBytes(prefix), common.FromHex("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")...),
+		append(common.CopyBytes(prefix), common.FromHex("0x8000000000000000000000000000000000000000000000000000000000000000")...),
+	}
+	for _, k := range storage {
+		if err := tx.Put(kv.HashedStorage, k, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	batch := memdb.NewMemoryBatch(tx)
+	defer batch.Rollback()
+
+	for i, tt := range []struct {
+		key        []byte
+		neighbours [][]byte
+	}{
+		{accounts[0], [][]byte{accounts[1]}},
+		{accounts[1], [][]byte{accounts[2], accounts[0]}},
+		{common.FromHex("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"), [][]byte{accounts[2], accounts[1]}},
+		{accounts[3], [][]byte{accounts[2]}},
+		{storage[0], [][]byte{storage[1]}},
+		{storage[1], [][]byte{storage[2], storage[0]}},
+		// The storage of the other contracts is not included
+		{storage[2], [][]byte{storage[1]}},
+	} {
+		for name, tx := range map[string]kv.Tx{"db": tx, "batch": batch} {
+			neighbours, err := findNeighbours(tx, tt.key)
+			if err != nil {
+				t.Fatalf("%s %d: %v", name, i, err)
+			}
+			if len(neighbours) != len(tt.neighbours) {
+				t.Fatalf("%s %d: expected %x, got %x", name, i, tt.neighbours, neighbours)
+			}
+			for j := range neighbours {
+				if !bytes.Equal(neighbours[j], tt.neighbours[j]) {
+					t.Errorf("%s %d: expected %x, got %x", name, i, tt.neighbours, neighbours)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package stateless
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ledgerwatch/erigon-lib/kv"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/turbo/trie"
+)
+
+// BuildWitness builds the witness of the state read and modified during the execution of a block,
+// which was recorded by rec. The tx must contain HashedState and IntermediateHashes of the parent block,
+// root is the state root of the parent block.
+func BuildWitness(tx kv.Tx, root common.Hash, rec *Recorder, quit <-chan struct{}) ([]byte, error) {
+	rl := rec.rlb.Build()
+	// Deleting a key may collapse a branch node into its only remaining child. If the child is an extension
+	// node, its key gets merged with the parent, so it must not be hashed in the witness. The child is always
+	// on the path to the previous or the next key of the deleted one, so these are retained too.
+	for _, key := range rec.deleted {
+		neighbours, err := findNeighbours(tx, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range neighbours {
+			rl.AddKey(n)
+		}
+	}
+
+	loader := trie.NewFlatDBTrieLoader("stateless")
+	if err := loader.Reset(rl, nil, nil, false); err != nil {
+		return nil, err
+	}
+	subTries, err := loader.CalcSubTrie(tx, quit)
+	if err != nil {
+		return nil, err
+	}
+	if subTries.Hashes[0] != root {
+		return nil, fmt.Errorf("mismatch in expected state root: computed %x, expected %x", subTries.Hashes[0], root)
+	}
+	tr := trie.New(root)
+	// Without retained keys the witness is just the root hash
+	if root != trie.EmptyRoot && rl.Len() > 0 {
+		if err = tr.HookSubTries(subTries, [][]byte{nil}); err != nil {
+			return nil, err
+		}
+	}
+	for addrHash, code := range rec.codes {
+		if err = tr.UpdateAccountCode(addrHash[:], code); err != nil {
+			return nil, fmt.Errorf("attach code to account %x: %w", addrHash, err)
+		}
+	}
+
+	// The sub-trie contains only the retained paths already, everything else is hashed
+	witness, err := tr.ExtractWitness(false, nil)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err = witness.WriteInto(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// findNeighbours returns the previous and the next key of the given one in HashedAccounts (for account
+// keys) or within the storage of the same contract in HashedStorage (for storage keys)
+func findNeighbours(tx kv.Tx, key []byte) ([][]byte, error) {
+	table, prefixLen := kv.HashedAccounts, 0
+	if len(key) > common.HashLength {
+		table, prefixLen = kv.HashedStorage, common.HashLength+common.IncarnationLength
+	}
+	c, err := tx.Cursor(table)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var neighbours [][]byte
+	k, _, err := c.Seek(key)
+	if err != nil {
+		return nil, err
+	}
+	next := k
+	if next != nil && bytes.Equal(next, key) {
+		if next, _, err = c.Next(); err != nil {
+			return nil, err
+		}
+	}
+	if next != nil && bytes.HasPrefix(next, key[:prefixLen]) {
+		neighbours = append(neighbours, common.CopyBytes(next))
+	}
+
+	prev, err := findPrev(c, key, prefixLen)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil {
+		neighbours = append(neighbours, prev)
+	}
+	return neighbours, nil
+}
+
+// findPrev returns the greatest key less than the given one and sharing its prefix of prefixLen bytes, nil if
+// there is none. The cursors of the memory batches, in which the state is rewound for the historical blocks,
+// can't move backwards, so the key space is bisected with Seek: if the key found at mid is less than the given
+// one, the previous key is not less than it, otherwise there are no keys between mid and the given one.
+func findPrev(c kv.Cursor, key []byte, prefixLen int) ([]byte, error) {
+	lo := new(big.Int).SetBytes(key[:prefixLen])
+	lo.Lsh(lo, uint(8*(len(key)-prefixLen)))
+	hi := new(big.Int).SetBytes(key)
+	var prev []byte
+	for lo.Cmp(hi) < 0 {
+		mid := new(big.Int).Add(lo, hi)
+		mid.Rsh(mid, 1)
+		k, _, err := c.Seek(mid.FillBytes(make([]byte, len(key))))
+		if err != nil {
+			return nil, err
+		}
+		if k != nil && bytes.Compare(k, key) < 0 {
+			prev = common.CopyBytes(k)
+			lo.SetBytes(k)
+			lo.Add(lo, common.Big1)
+		} else {
+			hi = mid
+		}
+	}
+	return prev, nil
+}
@@ -24,7 +24,7 @@ func DoCall(
 	ctx context.Context,
 	args ethapi.CallArgs,
 	tx kv.Tx, blockNrOrHash rpc.BlockNumberOrHash,
-	block *types.Block, overrides *ethapi.StateOverrides,
+	block *types.Block, overrides *ethapi.StateOverrides, blockOverrides *ethapi.BlockOverrides,
 	gasCap uint64,
 	chainConfig *params.ChainConfig,
 	stateReader state.StateReader,
@@ -71,11 +71,21 @@ func DoCall(
 			return nil, fmt.Errorf("header.BaseFee uint256 overflow")
 		}
 	}
+	baseFee, err := blockOverrides.OverrideBaseFee(baseFee)
+	if err != nil {
+		return nil, err
+	}
 	msg, err := args.ToMessage(gasCap, baseFee)
 	if err != nil {
 		return nil, err
 	}
 	blockCtx, txCtx := GetEvmContext(msg, header, blockNrOrHash.RequireCanonical, tx, headerReader)
+	// Override the fields of the block context, e.g. to run the call in the next block
+	if blockOverrides != nil {
+		if err := blockOverrides.Override(&blockCtx); err != nil {
+			return nil, err
+		}
+	}
 
 	evm := vm.NewEVM(blockCtx, txCtx, state, chainConfig, vm.Config{NoBaseFee: true})
 
@@ -121,6 +131,10 @@ func getHashGetter(requireCanonical bool, tx kv.Tx, headerReader services.Header
 			log.Error("Can't get block hash by number", "number", n, "only-canonical", requireCanonical)
 			return common.Hash{}
 		}
+		if h == nil {
+			// e.g. the block number of the call is overridden with a future one
+			return common.Hash{}
+		}
 		return h.Hash()
 	}
 }
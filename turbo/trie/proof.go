@@ -95,3 +95,24 @@ func (t *Trie) Prove(key []byte, fromLevel int, storage bool) ([][]byte, error)
 	}
 	return proof, nil
 }
+
+// NodeRLP returns the RLP encoding of the node starting at the given path (in HEX encoding, without terminator).
+// The paths longer than the key of an account continue in the storage trie of that account.
+// Returns nil if no node starts exactly at the path, or if the node is not loaded into the trie.
+func (t *Trie) NodeRLP(hex []byte) ([]byte, error) {
+	nd, _, found, _ := t.getNode(hex, false)
+	if !found {
+		return nil, nil
+	}
+	switch nd.(type) {
+	case nil, hashNode, valueNode:
+		return nil, nil
+	}
+	hasher := newHasher(false)
+	defer returnHasherToPool(hasher)
+	enc, err := hasher.hashChildren(nd, 0)
+	if err != nil {
+		return nil, err
+	}
+	return common.CopyBytes(enc), nil
+}
@@ -122,6 +122,10 @@ type RootHashAggregator struct {
 	a              accounts.Account
 	leafData       GenStructStepLeafData
 	accData        GenStructStepAccountData
+
+	retain    RetainDecider // if set, nodes on the paths to the retained keys are kept in memory (see CalcSubTrie)
+	retainHex []byte        // buffer for the full (account + storage) prefixes passed to `retain`
+	subTrie   node          // root node of the sub-trie built for the retained keys
 }
 
 type StreamReceiver interface {
@@ -173,6 +177,25 @@ func (l *FlatDBTrieLoader) SetStreamReceiver(receiver StreamReceiver) {
 	l.receiver = receiver
 }
 
+// CalcSubTrie works like CalcTrieRoot, but in addition it keeps in memory all the trie nodes on the paths to
+// the keys retained by the RetainDecider passed to Reset. Nodes outside of these paths are replaced by their hashes.
+// The result can be hooked into a Trie (see Trie.HookSubTries) to produce Merkle proofs (see Trie.Prove).
+// If nothing was retained (for example the state is empty), the result only contains the root hash.
+// Storage keys are expected in the same form as they are stored in HashedStorage: {addrHash}{incarnation}{keyHash}
+func (l *FlatDBTrieLoader) CalcSubTrie(tx kv.Tx, quit <-chan struct{}) (SubTries, error) {
+	l.defaultReceiver.retain = l.rd
+	l.defaultReceiver.subTrie = nil
+	defer func() { l.defaultReceiver.retain = nil }()
+	root, err := l.CalcTrieRoot(tx, []byte{}, quit)
+	if err != nil {
+		return SubTries{}, err
+	}
+	if l.defaultReceiver.subTrie == nil {
+		return SubTries{Hashes: []common.Hash{root}}, nil
+	}
+	return SubTries{Hashes: []common.Hash{root}, roots: []node{l.defaultReceiver.subTrie}}, nil
+}
+
 // CalcTrieRoot algo:
 //
 //		for iterateIHOfAccounts {
@@ -493,6 +516,9 @@ func (r *RootHashAggregator) Receive(itemType StreamItem,
 		}
 		if r.hb.hasRoot() {
 			r.root = r.hb.rootHash()
+			if r.retain != nil {
+				r.subTrie = r.hb.root()
+			}
 		} else {
 			r.root = EmptyRoot
 		}
@@ -526,6 +552,25 @@ func (r *RootHashAggregator) Result() SubTries {
 	panic("don't call me")
 }
 
+func (r *RootHashAggregator) retainAccount(prefix []byte) bool {
+	if r.retain == nil {
+		return false
+	}
+	return r.retain.Retain(prefix)
+}
+
+// retainStorage is called with the prefixes of the storage keys (without the account part). Storage keys of the
+// RetainDecider are composite keys {addrHash}{incarnation}{keyHash}, so the prefix is extended by the
+// {addrHash}{incarnation} of the current account, which the loader passes as the account key of storage items
+func (r *RootHashAggregator) retainStorage(prefix []byte) bool {
+	if r.retain == nil {
+		return false
+	}
+	hexutil.DecompressNibbles(r.currAccK[:common.HashLength+common.IncarnationLength], &r.retainHex)
+	r.retainHex = append(r.retainHex, prefix...)
+	return r.retain.Retain(r.retainHex)
+}
+
 func (r *RootHashAggregator) Root() common.Hash {
 	return r.root
 }
@@ -563,7 +608,7 @@ func (r *RootHashAggregator) genStructStorage() error {
 		r.leafData.Value = rlphacks.RlpSerializableBytes(r.valueStorage)
 		data = &r.leafData
 	}
-	r.groupsStorage, r.hasTreeStorage, r.hasHashStorage, err = GenStructStep(r.RetainNothing, r.currStorage.Bytes(), r.succStorage.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
+	r.groupsStorage, r.hasTreeStorage, r.hasHashStorage, err = GenStructStep(r.retainStorage, r.currStorage.Bytes(), r.succStorage.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
 		if r.shc == nil {
 			return nil
 		}
@@ -631,7 +676,7 @@ func (r *RootHashAggregator) genStructAccount() error {
 	r.currStorage.Reset()
 	r.succStorage.Reset()
 	var err error
-	if r.groups, r.hasTree, r.hasHash, err = GenStructStep(r.RetainNothing, r.curr.Bytes(), r.succ.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
+	if r.groups, r.hasTree, r.hasHash, err = GenStructStep(r.retainAccount, r.curr.Bytes(), r.succ.Bytes(), r.hb, func(keyHex []byte, hasState, hasTree, hasHash uint16, hashes, rootHash []byte) error {
 		if r.hc == nil {
 			return nil
 		}